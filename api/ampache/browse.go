@@ -0,0 +1,540 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package ampache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"regexp"
+	"strconv"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// CanCacheSongs returns true, ampache supports paging all songs.
+func (a *Ampache) CanCacheSongs() bool { return true }
+
+// pageTotal returns total number of items. If server did not return total, it is
+// estimated so that next page is allowed as long as pages are full.
+func pageTotal(total number, paging interfaces.Paging, n int) int {
+	if total > 0 {
+		return int(total)
+	}
+	count := paging.Offset() + n
+	if n == paging.PageSize {
+		count += 1
+	}
+	return count
+}
+
+// pagedParams returns params with paging and filter, if filter is not empty.
+func pagedParams(filter string, paging interfaces.Paging) *params {
+	params := &params{}
+	if filter != "" {
+		params.setFilter(filter)
+	}
+	params.setPaging(paging)
+	return params
+}
+
+// statsParams returns params for action 'stats' with given item type and filter,
+// e.g. 'album' and 'newest'.
+func statsParams(itemType, filter string, paging interfaces.Paging) *params {
+	params := pagedParams(filter, paging)
+	(*params)["type"] = itemType
+	return params
+}
+
+func (a *Ampache) getArtists(ctx context.Context, action string, params *params) ([]*models.Artist, number, error) {
+	resp := &artists{}
+	err := a.get(ctx, action, params, resp)
+	if err != nil {
+		return nil, 0, err
+	}
+	artists := make([]*models.Artist, len(resp.Artists))
+	for i, v := range resp.Artists {
+		artists[i] = v.toArtist()
+	}
+	return artists, resp.TotalCount, nil
+}
+
+func (a *Ampache) getAlbums(ctx context.Context, action string, params *params) ([]*models.Album, number, error) {
+	resp := &albums{}
+	err := a.get(ctx, action, params, resp)
+	if err != nil {
+		return nil, 0, err
+	}
+	albums := make([]*models.Album, len(resp.Albums))
+	for i, v := range resp.Albums {
+		albums[i] = v.toAlbum()
+	}
+	return albums, resp.TotalCount, nil
+}
+
+func (a *Ampache) getSongs(ctx context.Context, action string, params *params) ([]*models.Song, number, error) {
+	resp := &songs{}
+	err := a.get(ctx, action, params, resp)
+	if err != nil {
+		return nil, 0, err
+	}
+	songs := make([]*models.Song, len(resp.Songs))
+	for i, v := range resp.Songs {
+		songs[i] = v.toSong()
+	}
+	return songs, resp.TotalCount, nil
+}
+
+func (a *Ampache) queryArtists(ctx context.Context, query *interfaces.QueryOpts, albumArtists bool) ([]*models.Artist, int, error) {
+	if len(query.Filter.Composers) > 0 {
+		return nil, 0, interfaces.ErrInvalidFilter
+	}
+	if query.Filter.Favorite {
+		artists, total, err := a.getArtists(ctx, "stats", statsParams("artist", "flagged", query.Paging))
+		return artists, pageTotal(total, query.Paging, len(artists)), err
+	}
+
+	params := pagedParams("", query.Paging)
+	action := "artists"
+	if len(query.Filter.Genres) > 0 {
+		action = "genre_artists"
+		params.setFilter(query.Filter.Genres[0].Id.String())
+	} else if albumArtists {
+		(*params)["album_artist"] = "1"
+	}
+	if query.Filter.NameStartsWith != "" && query.Filter.NameStartsWith != interfaces.NameStartsOther {
+		// ampache only supports searching by name, filter rest of artists here
+		params.setFilter(query.Filter.NameStartsWith)
+	}
+
+	artists, total, err := a.getArtists(ctx, action, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if query.Filter.NameStartsWith != "" {
+		filtered := make([]*models.Artist, 0, len(artists))
+		for _, v := range artists {
+			if query.Filter.NameMatches(v.Name) {
+				filtered = append(filtered, v)
+			}
+		}
+		artists = filtered
+	} else if total == 0 && action == "artists" && !albumArtists {
+		a.lock.RLock()
+		total = a.counts.Artists
+		a.lock.RUnlock()
+	}
+	return artists, pageTotal(total, query.Paging, len(artists)), nil
+}
+
+func (a *Ampache) GetArtists(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Artist, int, error) {
+	return a.queryArtists(ctx, query, false)
+}
+
+func (a *Ampache) GetAlbumArtists(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Artist, int, error) {
+	return a.queryArtists(ctx, query, true)
+}
+
+func (a *Ampache) GetAlbums(ctx context.Context, opts *interfaces.QueryOpts) ([]*models.Album, int, error) {
+	if len(opts.Filter.Composers) > 0 || opts.Filter.YearRange != [2]int{0, 0} {
+		return nil, 0, interfaces.ErrInvalidFilter
+	}
+
+	// ampache does not support sorting and filtering at the same time
+	var params *params
+	action := "albums"
+	if opts.Filter.Favorite {
+		action = "stats"
+		params = statsParams("album", "flagged", opts.Paging)
+	} else if len(opts.Filter.Genres) > 0 {
+		action = "genre_albums"
+		params = pagedParams(opts.Filter.Genres[0].Id.String(), opts.Paging)
+	} else {
+		stats := ""
+		switch opts.Sort.Field {
+		case interfaces.SortByPlayCount:
+			stats = "frequent"
+		case interfaces.SortByRandom:
+			stats = "random"
+		case interfaces.SortByLastPlayed:
+			stats = "recent"
+		case interfaces.SortByLatest:
+			stats = "newest"
+		case interfaces.SortByRating:
+			stats = "highest"
+		}
+		if stats != "" {
+			action = "stats"
+			params = statsParams("album", stats, opts.Paging)
+		} else {
+			filter := ""
+			if opts.Filter.NameStartsWith != interfaces.NameStartsOther {
+				filter = opts.Filter.NameStartsWith
+			}
+			params = pagedParams(filter, opts.Paging)
+		}
+	}
+
+	albums, total, err := a.getAlbums(ctx, action, params)
+	if err != nil {
+		return nil, 0, err
+	}
+	if opts.Filter.NameStartsWith != "" {
+		filtered := make([]*models.Album, 0, len(albums))
+		for _, v := range albums {
+			if opts.Filter.NameMatches(v.Name) {
+				filtered = append(filtered, v)
+			}
+		}
+		albums = filtered
+	} else if total == 0 && action == "albums" {
+		a.lock.RLock()
+		total = a.counts.Albums
+		a.lock.RUnlock()
+	}
+	return albums, pageTotal(total, opts.Paging, len(albums)), nil
+}
+
+func (a *Ampache) GetArtistAlbums(ctx context.Context, artist models.Id) ([]*models.Album, error) {
+	params := &params{}
+	params.setFilter(artist.String())
+	albums, _, err := a.getAlbums(ctx, "artist_albums", params)
+	return albums, err
+}
+
+// GetArtistAppearsOn is not supported by ampache, it always returns empty list.
+func (a *Ampache) GetArtistAppearsOn(ctx context.Context, artist models.Id) ([]*models.Album, error) {
+	return []*models.Album{}, nil
+}
+
+func (a *Ampache) GetArtistTopSongs(ctx context.Context, artist *models.Artist, limit int) ([]*models.Song, error) {
+	params := &params{}
+	params.setFilter(artist.Id.String())
+	(*params)["top50"] = "1"
+	(*params)["limit"] = strconv.Itoa(limit)
+	songs, _, err := a.getSongs(ctx, "artist_songs", params)
+	if len(songs) > limit {
+		songs = songs[:limit]
+	}
+	return songs, err
+}
+
+// summary may contain html links
+var htmlTagRe = regexp.MustCompile("<[^>]*>")
+
+func (a *Ampache) GetArtistOverview(ctx context.Context, artist *models.Artist) (string, error) {
+	dto, err := a.getArtist(ctx, artist.Id)
+	if err != nil {
+		return "", err
+	}
+	return htmlTagRe.ReplaceAllString(dto.Summary, ""), nil
+}
+
+func (a *Ampache) GetAlbumSongs(ctx context.Context, album models.Id) ([]*models.Song, error) {
+	params := &params{}
+	params.setFilter(album.String())
+	songs, _, err := a.getSongs(ctx, "album_songs", params)
+	return songs, err
+}
+
+func (a *Ampache) GetPlaylists(ctx context.Context) ([]*models.Playlist, error) {
+	resp := &playlists{}
+	err := a.get(ctx, "playlists", nil, resp)
+	if err != nil {
+		return nil, err
+	}
+	playlists := make([]*models.Playlist, len(resp.Playlists))
+	for i, v := range resp.Playlists {
+		playlists[i] = v.toPlaylist()
+	}
+	return playlists, nil
+}
+
+func (a *Ampache) GetPlaylistSongs(ctx context.Context, playlist models.Id) ([]*models.Song, error) {
+	params := &params{}
+	params.setFilter(playlist.String())
+	songs, _, err := a.getSongs(ctx, "playlist_songs", params)
+	return songs, err
+}
+
+func (a *Ampache) GetSimilarArtists(ctx context.Context, artist models.Id) ([]*models.Artist, error) {
+	params := &params{}
+	(*params)["type"] = "artist"
+	params.setFilter(artist.String())
+	(*params)["limit"] = "20"
+	artists, _, err := a.getArtists(ctx, "get_similar", params)
+	return artists, err
+}
+
+func (a *Ampache) GetSimilarAlbums(ctx context.Context, album models.Id) ([]*models.Album, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (a *Ampache) GetRecentlyPlayed(ctx context.Context, paging interfaces.Paging) ([]*models.Song, int, error) {
+	songs, total, err := a.getSongs(ctx, "stats", statsParams("song", "recent", paging))
+	return songs, pageTotal(total, paging, len(songs)), err
+}
+
+func (a *Ampache) GetSongs(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Song, int, error) {
+	var params *params
+	action := "songs"
+	if query.Filter.Favorite {
+		action = "stats"
+		params = statsParams("song", "flagged", query.Paging)
+	} else if len(query.Filter.Genres) > 0 {
+		action = "genre_songs"
+		params = pagedParams(query.Filter.Genres[0].Id.String(), query.Paging)
+	} else {
+		params = pagedParams("", query.Paging)
+	}
+
+	songs, total, err := a.getSongs(ctx, action, params)
+	if err != nil {
+		return nil, 0, err
+	}
+	if total == 0 && action == "songs" {
+		a.lock.RLock()
+		total = a.counts.Songs
+		a.lock.RUnlock()
+	}
+	return songs, pageTotal(total, query.Paging, len(songs)), nil
+}
+
+func (a *Ampache) GetGenres(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
+	params := pagedParams("", paging)
+	resp := &genres{}
+	err := a.get(ctx, "genres", params, resp)
+	if err != nil {
+		return nil, 0, err
+	}
+	genres := make([]*models.IdName, len(resp.Genres))
+	for i, v := range resp.Genres {
+		genres[i] = &models.IdName{Id: models.Id(v.Id), Name: v.Name}
+	}
+
+	total := resp.TotalCount
+	if total == 0 {
+		a.lock.RLock()
+		total = a.counts.Genres
+		a.lock.RUnlock()
+	}
+	return genres, pageTotal(total, paging, len(genres)), nil
+}
+
+func (a *Ampache) GetComposers(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (a *Ampache) GetAlbumArtist(ctx context.Context, album *models.Album) (*models.Artist, error) {
+	return a.GetArtist(ctx, album.Artist)
+}
+
+func (a *Ampache) GetInstantMix(ctx context.Context, item models.Item) ([]*models.Song, error) {
+	params := &params{}
+	(*params)["limit"] = strconv.Itoa(config.InstantMixSize)
+
+	var songs []*models.Song
+	var err error
+	switch item.GetType() {
+	case models.TypeSong:
+		(*params)["type"] = "song"
+		params.setFilter(item.GetId().String())
+		songs, _, err = a.getSongs(ctx, "get_similar", params)
+	case models.TypeArtist, models.TypeAlbum:
+		(*params)["mode"] = "random"
+		(*params)["format"] = "song"
+		if item.GetType() == models.TypeArtist {
+			(*params)["artist"] = item.GetId().String()
+		} else {
+			(*params)["album"] = item.GetId().String()
+		}
+		songs, _, err = a.getSongs(ctx, "playlist_generate", params)
+	case models.TypeGenre:
+		params.setFilter(item.GetId().String())
+		songs, _, err = a.getSongs(ctx, "genre_songs", params)
+		rand.Shuffle(len(songs), func(i, j int) {
+			songs[i], songs[j] = songs[j], songs[i]
+		})
+	default:
+		return nil, fmt.Errorf("instant mix not supported for %s", item.GetType())
+	}
+	return songs, err
+}
+
+// GetLink returns link to item in ampache web interface.
+func (a *Ampache) GetLink(item models.Item) string {
+	id := url.QueryEscape(item.GetId().String())
+	switch item.GetType() {
+	case models.TypeArtist:
+		return a.host + "/artists.php?action=show&artist=" + id
+	case models.TypeAlbum:
+		return a.host + "/albums.php?action=show&album=" + id
+	case models.TypeSong:
+		return a.host + "/song.php?action=show_song&song_id=" + id
+	case models.TypePlaylist:
+		return a.host + "/playlist.php?action=show_playlist&playlist_id=" + id
+	}
+	return ""
+}
+
+func (a *Ampache) Search(ctx context.Context, query string, itemType models.ItemType, maxResults int) ([]models.Item, error) {
+	params := &params{}
+	params.setFilter(query)
+	(*params)["limit"] = strconv.Itoa(maxResults)
+
+	var items []models.Item
+	switch itemType {
+	case models.TypeArtist:
+		artists, _, err := a.getArtists(ctx, "artists", params)
+		if err != nil {
+			return nil, err
+		}
+		items = models.ArtistsToItems(artists)
+	case models.TypeAlbum:
+		albums, _, err := a.getAlbums(ctx, "albums", params)
+		if err != nil {
+			return nil, err
+		}
+		items = models.AlbumsToItems(albums)
+	case models.TypeSong:
+		songs, _, err := a.getSongs(ctx, "search_songs", params)
+		if err != nil {
+			return nil, err
+		}
+		items = models.SongsToItems(songs)
+	case models.TypePlaylist:
+		resp := &playlists{}
+		err := a.get(ctx, "playlists", params, resp)
+		if err != nil {
+			return nil, err
+		}
+		items = make([]models.Item, len(resp.Playlists))
+		for i, v := range resp.Playlists {
+			items[i] = v.toPlaylist()
+		}
+	}
+	return items, nil
+}
+
+// getOne gets single item with given id and decodes it to dto.
+func (a *Ampache) getOne(ctx context.Context, action string, id models.Id, dto interface{}) error {
+	params := &params{}
+	params.setFilter(id.String())
+	var raw json.RawMessage
+	err := a.get(ctx, action, params, &raw)
+	if err != nil {
+		return err
+	}
+	return unmarshalSingle(raw, action, dto)
+}
+
+func (a *Ampache) getArtist(ctx context.Context, id models.Id) (*artist, error) {
+	dto := &artist{}
+	err := a.getOne(ctx, "artist", id, dto)
+	return dto, err
+}
+
+func (a *Ampache) getAlbum(ctx context.Context, id models.Id) (*album, error) {
+	dto := &album{}
+	err := a.getOne(ctx, "album", id, dto)
+	return dto, err
+}
+
+func (a *Ampache) GetAlbum(ctx context.Context, id models.Id) (*models.Album, error) {
+	dto, err := a.getAlbum(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.toAlbum(), nil
+}
+
+func (a *Ampache) GetArtist(ctx context.Context, id models.Id) (*models.Artist, error) {
+	dto, err := a.getArtist(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.toArtist(), nil
+}
+
+func (a *Ampache) GetItemInfo(ctx context.Context, item models.Item) (*models.ItemInfo, error) {
+	switch item.GetType() {
+	case models.TypeSong:
+		dto := &song{}
+		err := a.getOne(ctx, "song", item.GetId(), dto)
+		if err != nil {
+			return nil, err
+		}
+		return dto.toInfo(), nil
+	case models.TypeAlbum:
+		dto, err := a.getAlbum(ctx, item.GetId())
+		if err != nil {
+			return nil, err
+		}
+		return dto.toInfo(), nil
+	default:
+		return nil, fmt.Errorf("no info for item type %s", item.GetType())
+	}
+}
+
+func (a *Ampache) GetImageUrl(item models.Id, itemType models.ItemType) string {
+	var artType string
+	switch itemType {
+	case models.TypeAlbum:
+		artType = "album"
+	case models.TypeArtist:
+		artType = "artist"
+	case models.TypePlaylist:
+		artType = "playlist"
+	default:
+		return ""
+	}
+	query := url.Values{}
+	query.Set("action", "get_art")
+	query.Set("type", artType)
+	query.Set("id", item.String())
+	query.Set("auth", a.getSession())
+	return a.apiUrl() + "?" + query.Encode()
+}
+
+// SetFavorite implements interfaces.FavoriteController. Ampache calls favorites flagged items.
+func (a *Ampache) SetFavorite(item models.Item, favorite bool) error {
+	itemType := "song"
+	switch item.GetType() {
+	case models.TypeAlbum:
+		itemType = "album"
+	case models.TypeArtist:
+		itemType = "artist"
+	case models.TypePlaylist:
+		itemType = "playlist"
+	}
+	flag := "0"
+	if favorite {
+		flag = "1"
+	}
+	params := &params{"type": itemType, "id": item.GetId().String(), "flag": flag}
+	err := a.get(context.Background(), "flag", params, nil)
+	if err != nil {
+		return fmt.Errorf("set favorite: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,364 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+// Package ampache contains remote server implementation for servers implementing Ampache API >= 5.
+// Implemented: api.Browser.
+// Ampache-protocol does not support api.RemoteController.
+package ampache
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+const apiVersion = "5.0.0"
+
+// Ampache implements ampache api.
+type Ampache struct {
+	host       string
+	apiKey     string
+	httpClient *http.Client
+	// requests makes api requests, httpClient is used directly for streams.
+	requests   *api.Client
+	connection *api.ConnectionMonitor
+
+	// lock guards session and counts, which are refreshed on handshake.
+	lock    *sync.RWMutex
+	session string
+	counts  handshake
+
+	connectionError error
+}
+
+func NewAmpache(conf *config.Ampache, provider config.KeyValueProvider) (*Ampache, error) {
+	a := &Ampache{
+		host:   strings.TrimSuffix(conf.Url, "/"),
+		apiKey: conf.ApiKey,
+		lock:   &sync.RWMutex{},
+	}
+	httpClient, err := api.NewHttpClient(0)
+	if err != nil {
+		return a, err
+	}
+	a.httpClient = httpClient
+	a.requests = api.NewClient(httpClient)
+	a.connection = api.NewConnectionMonitor(a.handshake)
+
+	if a.host == "" {
+		host, err := provider.Get("ampache.url", false, "Ampache host")
+		if err != nil {
+			return a, err
+		}
+		if host != "" {
+			a.host = strings.TrimSuffix(host, "/")
+		} else {
+			return a, errors.New("ampache host cannot be empty")
+		}
+	}
+
+	if a.apiKey == "" {
+		err = a.login(provider)
+		if err != nil {
+			return a, err
+		}
+	}
+
+	err = a.handshake()
+	if err != nil {
+		var ampErr *ampError
+		if !errors.As(err, &ampErr) {
+			return a, err
+		}
+		err = a.login(provider)
+		if err != nil {
+			return a, err
+		}
+		err = a.handshake()
+		if err != nil {
+			return a, err
+		}
+	}
+	a.connectionError = nil
+	return a, nil
+}
+
+func (a *Ampache) login(provider config.KeyValueProvider) error {
+	logrus.Warning("Authentication required for Ampache")
+
+	key, err := provider.Get("ampache.api_key", true, "Ampache API key")
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return errors.New("ampache api key cannot be empty")
+	}
+	a.apiKey = key
+	return nil
+}
+
+// handshake creates new session with api key.
+func (a *Ampache) handshake() error {
+	params := &params{}
+	(*params)["auth"] = a.apiKey
+	(*params)["version"] = apiVersion
+
+	data, err := a.request(context.Background(), "handshake", params)
+	if err != nil {
+		a.connectionError = err
+		return err
+	}
+	resp := handshake{}
+	err = json.Unmarshal(data, &resp)
+	if err != nil {
+		return fmt.Errorf("parse handshake: %v", err)
+	}
+	if resp.Auth == "" {
+		return errors.New("ampache: no session in handshake response")
+	}
+
+	logrus.Debugf("Ampache session valid until %s", resp.SessionExpire)
+	a.lock.Lock()
+	a.session = resp.Auth
+	a.counts = resp
+	a.lock.Unlock()
+	a.connectionError = nil
+	return nil
+}
+
+func (a *Ampache) getSession() string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.session
+}
+
+// get makes api request with current session and decodes response to dto.
+// If session has expired, new session is created and request is retried.
+func (a *Ampache) get(ctx context.Context, action string, query *params, dto interface{}) error {
+	if query == nil {
+		query = &params{}
+	}
+	(*query)["auth"] = a.getSession()
+
+	data, err := a.request(ctx, action, query)
+	var ampErr *ampError
+	if errors.As(err, &ampErr) && ampErr.Code == errSession {
+		logrus.Info("Ampache session expired, renewing session")
+		err = a.handshake()
+		if err != nil {
+			return err
+		}
+		(*query)["auth"] = a.getSession()
+		data, err = a.request(ctx, action, query)
+	}
+	if err != nil {
+		return err
+	}
+	if dto == nil {
+		return nil
+	}
+	return json.Unmarshal(data, dto)
+}
+
+func (a *Ampache) apiUrl() string {
+	return a.host + "/server/json.server.php"
+}
+
+// request makes request to api and returns response body. Authentication must be in query.
+// Request is cancelled when ctx is cancelled.
+func (a *Ampache) request(ctx context.Context, action string, query *params) ([]byte, error) {
+	start := time.Now()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, a.apiUrl(), nil)
+
+	q := req.URL.Query()
+	q.Add("action", action)
+	if query != nil {
+		for key, value := range *query {
+			q.Add(key, value)
+		}
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := a.requests.Do(req)
+	took := time.Now().Sub(start)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		a.connection.RequestFailed(err)
+		logrus.Warningf("Get ampache %s failed", action)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// don't log query, it contains api key or session
+	logrus.Debugf("Get ampache %s status: %d, took: %d ms", action, resp.StatusCode, took.Milliseconds())
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ampache: invalid status code: %d", resp.StatusCode)
+	}
+
+	errResp := &errorResponse{}
+	if json.Unmarshal(data, errResp) == nil && errResp.Error != nil {
+		return nil, errResp.Error
+	}
+	return data, nil
+}
+
+func (a *Ampache) stream(song *models.Song, format string) (io.ReadCloser, interfaces.AudioFormat, error) {
+	params := a.streamParams(song, format)
+	stream, err := api.NewStreamDownload(a.apiUrl(), nil, *params, a.httpClient, song.Duration)
+	if err != nil {
+		return nil, interfaces.AudioFormatNil, err
+	}
+
+	audioFormat, err := stream.AudioFormat()
+	return stream, audioFormat, err
+}
+
+// streamParams returns parameters for streaming song with current session. If format is empty,
+// server may transcode song, e.g. to configured max bitrate.
+func (a *Ampache) streamParams(song *models.Song, format string) *params {
+	params := &params{}
+	(*params)["action"] = "stream"
+	(*params)["type"] = "song"
+	(*params)["id"] = song.Id.String()
+	(*params)["auth"] = a.getSession()
+	if format != "" {
+		(*params)["format"] = format
+	} else if bitrate := api.MaxBitrate(); bitrate > 0 {
+		(*params)["bitrate"] = strconv.Itoa(bitrate)
+	}
+	return params
+}
+
+// GetStreamUrl implements api.StreamLinker. Url is valid as long as current session.
+func (a *Ampache) GetStreamUrl(song *models.Song) string {
+	return api.EncodeUrl(a.apiUrl(), *a.streamParams(song, ""))
+}
+
+// Stream streams song, transcoded if server is configured to do so.
+func (a *Ampache) Stream(Song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
+	return a.stream(Song, "")
+}
+
+// Download streams original audio file.
+func (a *Ampache) Download(Song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
+	return a.stream(Song, "raw")
+}
+
+func (a *Ampache) GetInfo() (*models.ServerInfo, error) {
+	resp := &ping{}
+	err := a.get(context.Background(), "ping", nil, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	a.lock.RLock()
+	counts := a.counts
+	a.lock.RUnlock()
+
+	info := &models.ServerInfo{
+		ServerType: "Ampache",
+		Name:       a.host,
+		Id:         a.GetId(),
+		Version:    resp.Server,
+		Misc: map[string]string{
+			"Api version":     counts.Api,
+			"Session expires": resp.SessionExpire,
+			"Songs":           strconv.Itoa(int(counts.Songs)),
+			"Albums":          strconv.Itoa(int(counts.Albums)),
+			"Artists":         strconv.Itoa(int(counts.Artists)),
+		},
+	}
+	return info, nil
+}
+
+// GetRequestStats implements api.RequestStatistics.
+func (a *Ampache) GetRequestStats() models.RequestStats {
+	return a.requests.Stats()
+}
+
+// AddConnectionCallback implements interfaces.ConnectionNotifier.
+func (a *Ampache) AddConnectionCallback(cb func(online bool)) {
+	a.connection.AddConnectionCallback(cb)
+}
+
+func (a *Ampache) ConnectionOk() error {
+	return a.connectionError
+}
+
+func (a *Ampache) GetConfig() config.Backend {
+	return &config.Ampache{
+		Url:    a.host,
+		ApiKey: a.apiKey,
+	}
+}
+
+// ReportProgress does nothing, Ampache records plays itself when song is streamed.
+func (a *Ampache) ReportProgress(state *interfaces.ApiPlaybackState) error {
+	return nil
+}
+
+func (a *Ampache) Start() error {
+	return nil
+}
+
+func (a *Ampache) Stop() error {
+	return nil
+}
+
+func (a *Ampache) GetId() string {
+	return serverId(a.host, a.apiKey)
+}
+
+func serverId(host, apiKey string) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(host+apiKey)))
+}
+
+// NewOffline returns offline server that shares id with Ampache server in conf.
+func NewOffline(conf *config.Ampache) *api.Offline {
+	return api.NewOffline(serverId(strings.TrimSuffix(conf.Url, "/"), conf.ApiKey), conf)
+}
+
+type params map[string]string
+
+func (p *params) setFilter(filter string) {
+	(*p)["filter"] = filter
+}
+
+func (p *params) setPaging(paging interfaces.Paging) {
+	(*p)["offset"] = strconv.Itoa(paging.Offset())
+	(*p)["limit"] = strconv.Itoa(paging.PageSize)
+}
@@ -0,0 +1,315 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package ampache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"tryffel.net/go/jellycli/models"
+)
+
+// errSession means session has expired or is invalid, see https://ampache.org/api/api-errors.
+const errSession = "4701"
+
+type ampError struct {
+	Code    string `json:"errorCode"`
+	Action  string `json:"errorAction"`
+	Type    string `json:"errorType"`
+	Message string `json:"errorMessage"`
+}
+
+func (e *ampError) Error() string {
+	return fmt.Sprintf("ampache: (%s - %s) %s", e.Code, e.Action, e.Message)
+}
+
+type errorResponse struct {
+	Error *ampError `json:"error"`
+}
+
+// number is an integer that some Ampache versions encode as string.
+type number int
+
+func (n *number) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, "\"")
+	if len(data) == 0 || string(data) == "null" {
+		*n = 0
+		return nil
+	}
+	i, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("parse number: %v", err)
+	}
+	*n = number(i)
+	return nil
+}
+
+// flag is a boolean that some Ampache versions encode as number or string.
+type flag bool
+
+func (f *flag) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), "\"")
+	*f = value == "true" || value == "1"
+	return nil
+}
+
+type handshake struct {
+	Auth          string `json:"auth"`
+	Api           string `json:"api"`
+	SessionExpire string `json:"session_expire"`
+	Songs         number `json:"songs"`
+	Albums        number `json:"albums"`
+	Artists       number `json:"artists"`
+	Playlists     number `json:"playlists"`
+	Genres        number `json:"genres"`
+}
+
+type ping struct {
+	SessionExpire string `json:"session_expire"`
+	Server        string `json:"server"`
+	Version       string `json:"version"`
+	Compatible    string `json:"compatible"`
+}
+
+type idName struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (i idName) toIdName() models.IdName {
+	return models.IdName{Id: models.Id(i.Id), Name: i.Name}
+}
+
+func toIdNames(items []idName) []models.IdName {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]models.IdName, len(items))
+	for i, v := range items {
+		out[i] = v.toIdName()
+	}
+	return out
+}
+
+type artist struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	AlbumCount number `json:"albumcount"`
+	SongCount  number `json:"songcount"`
+	Time       number `json:"time"`
+	Flag       flag   `json:"flag"`
+	Summary    string `json:"summary"`
+}
+
+func (a *artist) toArtist() *models.Artist {
+	return &models.Artist{
+		Id:            models.Id(a.Id),
+		Name:          a.Name,
+		TotalDuration: int(a.Time),
+		AlbumCount:    int(a.AlbumCount),
+		Favorite:      bool(a.Flag),
+	}
+}
+
+type artists struct {
+	TotalCount number   `json:"total_count"`
+	Artists    []artist `json:"artist"`
+}
+
+type album struct {
+	Id        string   `json:"id"`
+	Name      string   `json:"name"`
+	Artist    idName   `json:"artist"`
+	Time      number   `json:"time"`
+	Year      number   `json:"year"`
+	SongCount number   `json:"songcount"`
+	DiskCount number   `json:"diskcount"`
+	Genre     []idName `json:"genre"`
+	Flag      flag     `json:"flag"`
+	PlayCount number   `json:"playcount"`
+}
+
+func (a *album) toAlbum() *models.Album {
+	discs := int(a.DiskCount)
+	if discs == 0 {
+		discs = 1
+	}
+	return &models.Album{
+		Id:                models.Id(a.Id),
+		Name:              a.Name,
+		Year:              int(a.Year),
+		Duration:          int(a.Time),
+		Artist:            models.Id(a.Artist.Id),
+		AdditionalArtists: []models.IdName{a.Artist.toIdName()},
+		SongCount:         int(a.SongCount),
+		ImageId:           a.Id,
+		DiscCount:         discs,
+		Favorite:          bool(a.Flag),
+		Genres:            toIdNames(a.Genre),
+	}
+}
+
+func (a *album) toInfo() *models.ItemInfo {
+	return &models.ItemInfo{
+		Id:        models.Id(a.Id),
+		Type:      models.TypeAlbum,
+		Name:      a.Name,
+		Artists:   []models.IdName{a.Artist.toIdName()},
+		Genres:    toIdNames(a.Genre),
+		PlayCount: int(a.PlayCount),
+	}
+}
+
+type albums struct {
+	TotalCount number  `json:"total_count"`
+	Albums     []album `json:"album"`
+}
+
+type song struct {
+	Id          string   `json:"id"`
+	Title       string   `json:"title"`
+	Artist      idName   `json:"artist"`
+	Album       idName   `json:"album"`
+	AlbumArtist idName   `json:"albumartist"`
+	Disk        number   `json:"disk"`
+	Track       number   `json:"track"`
+	Filename    string   `json:"filename"`
+	Genre       []idName `json:"genre"`
+	Time        number   `json:"time"`
+	Year        number   `json:"year"`
+	// Bitrate in bps
+	Bitrate   number `json:"bitrate"`
+	Rate      number `json:"rate"`
+	Mime      string `json:"mime"`
+	Size      number `json:"size"`
+	Composer  string `json:"composer"`
+	Flag      flag   `json:"flag"`
+	PlayCount number `json:"playcount"`
+	Rating    number `json:"rating"`
+}
+
+// codec returns file format from mime type, e.g. 'audio/flac' -> 'flac'.
+func (s *song) codec() string {
+	i := strings.LastIndex(s.Mime, "/")
+	if i < 0 {
+		return s.Mime
+	}
+	return strings.TrimPrefix(s.Mime[i+1:], "x-")
+}
+
+func (s *song) toSong() *models.Song {
+	albumArtist := s.AlbumArtist.Id
+	if albumArtist == "" {
+		albumArtist = s.Artist.Id
+	}
+	song := &models.Song{
+		Id:          models.Id(s.Id),
+		Name:        s.Title,
+		Duration:    int(s.Time),
+		Index:       int(s.Track),
+		Album:       models.Id(s.Album.Id),
+		DiscNumber:  int(s.Disk),
+		Artists:     []models.IdName{s.Artist.toIdName()},
+		AlbumArtist: models.Id(albumArtist),
+		Favorite:    bool(s.Flag),
+		Size:        int64(s.Size),
+		Codec:       s.codec(),
+		Bitrate:     int(s.Bitrate) / 1000,
+		AlbumName:   s.Album.Name,
+		Year:        int(s.Year),
+		PlayCount:   int(s.PlayCount),
+		Rating:      int(s.Rating),
+	}
+	if s.Composer != "" {
+		song.Composers = []models.IdName{{Name: s.Composer}}
+	}
+	return song
+}
+
+func (s *song) toInfo() *models.ItemInfo {
+	return &models.ItemInfo{
+		Id:         models.Id(s.Id),
+		Type:       models.TypeSong,
+		Name:       s.Title,
+		Album:      s.Album.toIdName(),
+		Artists:    []models.IdName{s.Artist.toIdName()},
+		Genres:     toIdNames(s.Genre),
+		Path:       s.Filename,
+		Codec:      s.codec(),
+		Bitrate:    int(s.Bitrate) / 1000,
+		SampleRate: int(s.Rate),
+		Size:       int64(s.Size),
+		PlayCount:  int(s.PlayCount),
+	}
+}
+
+type songs struct {
+	TotalCount number `json:"total_count"`
+	Songs      []song `json:"song"`
+}
+
+type playlist struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Items number `json:"items"`
+}
+
+func (p *playlist) toPlaylist() *models.Playlist {
+	return &models.Playlist{
+		Id:        models.Id(p.Id),
+		Name:      p.Name,
+		SongCount: int(p.Items),
+	}
+}
+
+type playlists struct {
+	Playlists []playlist `json:"playlist"`
+}
+
+type genre struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Albums number `json:"albums"`
+	Songs  number `json:"songs"`
+}
+
+type genres struct {
+	TotalCount number  `json:"total_count"`
+	Genres     []genre `json:"genre"`
+}
+
+// unmarshalSingle decodes single object. Ampache 4 wraps single objects in a list
+// with given key, while newer versions return the object itself.
+func unmarshalSingle(data []byte, key string, v interface{}) error {
+	wrapped := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &wrapped); err == nil {
+		if list, ok := wrapped[key]; ok {
+			items := []json.RawMessage{}
+			if err := json.Unmarshal(list, &items); err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				return fmt.Errorf("no %s in response", key)
+			}
+			return json.Unmarshal(items[0], v)
+		}
+	}
+	return json.Unmarshal(data, v)
+}
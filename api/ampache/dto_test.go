@@ -0,0 +1,145 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package ampache
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestNumberUnmarshal(t *testing.T) {
+	tests := []struct {
+		data string
+		want number
+	}{
+		{data: `12`, want: 12},
+		{data: `"12"`, want: 12},
+		{data: `""`, want: 0},
+		{data: `null`, want: 0},
+		{data: `4.0`, want: 4},
+	}
+	for _, tt := range tests {
+		var n number
+		if err := json.Unmarshal([]byte(tt.data), &n); err != nil {
+			t.Errorf("unmarshal %s: %v", tt.data, err)
+			continue
+		}
+		if n != tt.want {
+			t.Errorf("unmarshal %s: got %d, want %d", tt.data, n, tt.want)
+		}
+	}
+
+	var n number
+	if err := json.Unmarshal([]byte(`"abc"`), &n); err == nil {
+		t.Errorf("unmarshal invalid number: expected error")
+	}
+}
+
+func TestFlagUnmarshal(t *testing.T) {
+	tests := map[string]flag{
+		`true`:  true,
+		`false`: false,
+		`1`:     true,
+		`"1"`:   true,
+		`0`:     false,
+	}
+	for data, want := range tests {
+		var f flag
+		if err := json.Unmarshal([]byte(data), &f); err != nil {
+			t.Errorf("unmarshal %s: %v", data, err)
+			continue
+		}
+		if f != want {
+			t.Errorf("unmarshal %s: got %t, want %t", data, f, want)
+		}
+	}
+}
+
+func TestUnmarshalSingle(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "object",
+			data: `{"id":"1","name":"artist"}`,
+			want: "artist",
+		},
+		{
+			name: "wrapped",
+			data: `{"artist":[{"id":"1","name":"artist"}]}`,
+			want: "artist",
+		},
+		{
+			name:    "empty list",
+			data:    `{"artist":[]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dto := &artist{}
+			err := unmarshalSingle([]byte(tt.data), "artist", dto)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unmarshalSingle() error = %v, wantErr %t", err, tt.wantErr)
+				return
+			}
+			if dto.Name != tt.want {
+				t.Errorf("unmarshalSingle() name = %s, want %s", dto.Name, tt.want)
+			}
+		})
+	}
+}
+
+func Test_songToSong(t *testing.T) {
+	data := `{"id":"10","title":"song","artist":{"id":"2","name":"artist"},"album":{"id":"3","name":"album"},
+"albumartist":{"id":"4","name":"album artist"},"disk":"1","track":5,"time":180,"bitrate":320000,
+"mime":"audio/x-flac","size":"1000","composer":"composer","flag":1,"year":2001,"playcount":"7","rating":4}`
+
+	dto := &song{}
+	if err := json.Unmarshal([]byte(data), dto); err != nil {
+		t.Fatalf("unmarshal song: %v", err)
+	}
+
+	want := &models.Song{
+		Id:          "10",
+		Name:        "song",
+		Duration:    180,
+		Index:       5,
+		Album:       "3",
+		DiscNumber:  1,
+		Artists:     []models.IdName{{Id: "2", Name: "artist"}},
+		AlbumArtist: "4",
+		Composers:   []models.IdName{{Name: "composer"}},
+		Favorite:    true,
+		Size:        1000,
+		Codec:       "flac",
+		Bitrate:     320,
+		AlbumName:   "album",
+		Year:        2001,
+		PlayCount:   7,
+		Rating:      4,
+	}
+	if got := dto.toSong(); !reflect.DeepEqual(got, want) {
+		t.Errorf("toSong() = %v, want %v", got, want)
+	}
+}
@@ -20,7 +20,10 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"io"
+	"time"
 	"tryffel.net/go/jellycli/config"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
@@ -46,46 +49,59 @@ type Streamer interface {
 }
 
 // Browser implements item-based viewing for music artists,albums,playlists etc.
+// Requests to server are cancelled when ctx is cancelled.
 type Browser interface {
 
 	// GetArtists returns all artists
-	GetArtists(query *interfaces.QueryOpts) ([]*models.Artist, int, error)
+	GetArtists(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Artist, int, error)
 
 	// GetAlbumArtists returns artists that are marked as album artists. See GetArtists.
-	GetAlbumArtists(query *interfaces.QueryOpts) ([]*models.Artist, int, error)
+	GetAlbumArtists(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Artist, int, error)
 	// GetAlbums gets albums with given paging. Only PageSize and CurrentPage are used. Total count is returned
-	GetAlbums(query *interfaces.QueryOpts) ([]*models.Album, int, error)
+	GetAlbums(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Album, int, error)
 
 	// GetArtistAlbums returns albums that artist takes part in.
-	GetArtistAlbums(artist models.Id) ([]*models.Album, error)
+	GetArtistAlbums(ctx context.Context, artist models.Id) ([]*models.Album, error)
+
+	// GetArtistAppearsOn returns albums that artist contributes to, excluding artist's own albums.
+	GetArtistAppearsOn(ctx context.Context, artist models.Id) ([]*models.Album, error)
+
+	// GetArtistTopSongs returns artist's most played songs, max limit songs.
+	GetArtistTopSongs(ctx context.Context, artist *models.Artist, limit int) ([]*models.Song, error)
+
+	// GetArtistOverview returns artist biography, or empty string if there is none.
+	GetArtistOverview(ctx context.Context, artist *models.Artist) (string, error)
 
 	// GetAlbumSongs returns songs for given album id.
-	GetAlbumSongs(album models.Id) ([]*models.Song, error)
+	GetAlbumSongs(ctx context.Context, album models.Id) ([]*models.Song, error)
 	// GetPlaylists returns all playlists.
-	GetPlaylists() ([]*models.Playlist, error)
+	GetPlaylists(ctx context.Context) ([]*models.Playlist, error)
 	// GetPlaylistSongs fills songs array for playlist. If there's error, songs will not be filled
-	GetPlaylistSongs(playlist models.Id) ([]*models.Song, error)
+	GetPlaylistSongs(ctx context.Context, playlist models.Id) ([]*models.Song, error)
 
 	// GetSimilarArtists returns similar artists for artist id
-	GetSimilarArtists(artist models.Id) ([]*models.Artist, error)
+	GetSimilarArtists(ctx context.Context, artist models.Id) ([]*models.Artist, error)
 
 	// GetsimilarAlbums returns list of similar albums.
-	GetSimilarAlbums(album models.Id) ([]*models.Album, error)
+	GetSimilarAlbums(ctx context.Context, album models.Id) ([]*models.Album, error)
 
 	// GetRecentlyPlayed returns songs that have been played last.
-	GetRecentlyPlayed(paging interfaces.Paging) ([]*models.Song, int, error)
+	GetRecentlyPlayed(ctx context.Context, paging interfaces.Paging) ([]*models.Song, int, error)
 
 	// GetSongs returns songs by paging. It also returns total number of songs.
-	GetSongs(query *interfaces.QueryOpts) ([]*models.Song, int, error)
+	GetSongs(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Song, int, error)
 
 	// GetGenres returns music genres with paging. Return genres, total genres and possible error
-	GetGenres(paging interfaces.Paging) ([]*models.IdName, int, error)
+	GetGenres(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error)
+
+	// GetComposers returns composers with paging. Return composers, total composers and possible error
+	GetComposers(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error)
 
 	// GetAlbumArtist returns main artist for album.
-	GetAlbumArtist(album *models.Album) (*models.Artist, error)
+	GetAlbumArtist(ctx context.Context, album *models.Album) (*models.Artist, error)
 
 	// GetInstantMix returns instant mix based on given item.
-	GetInstantMix(item models.Item) ([]*models.Song, error)
+	GetInstantMix(ctx context.Context, item models.Item) ([]*models.Song, error)
 
 	// GetLink returns a link to item that can be opened with browser.
 	// If there is no link or item is invalid, empty link is returned.
@@ -93,11 +109,14 @@ type Browser interface {
 
 	// Search returns values matching query and itemType, limited by number of maxResults,
 	// Only items of itemType should ne returned.
-	Search(query string, itemType models.ItemType, maxResults int) ([]models.Item, error)
+	Search(ctx context.Context, query string, itemType models.ItemType, maxResults int) ([]models.Item, error)
 
-	GetAlbum(id models.Id) (*models.Album, error)
+	GetAlbum(ctx context.Context, id models.Id) (*models.Album, error)
 
-	GetArtist(id models.Id) (*models.Artist, error)
+	GetArtist(ctx context.Context, id models.Id) (*models.Artist, error)
+
+	// GetItemInfo returns detailed info of song or album, such as file path and codec.
+	GetItemInfo(ctx context.Context, item models.Item) (*models.ItemInfo, error)
 
 	GetImageUrl(item models.Id, itemType models.ItemType) string
 }
@@ -139,6 +158,35 @@ type RemoteServer interface {
 	GetId() string
 }
 
+// PlaylistCreator creates playlists on server.
+type PlaylistCreator interface {
+	// CreatePlaylist creates playlist with songs and returns its id.
+	CreatePlaylist(name string, songs []models.Id) (models.Id, error)
+}
+
+// StreamLinker provides urls that other players can stream songs from.
+type StreamLinker interface {
+	// GetStreamUrl returns url for streaming song, including credentials,
+	// or empty string if song cannot be streamed without client.
+	GetStreamUrl(song *models.Song) string
+}
+
+// ErrNotScrobbled is returned by RemoteServer.ReportProgress when song stopped before it could be scrobbled.
+// It is not a failure of the report itself, player queues the song to be scrobbled later with Scrobbler.
+var ErrNotScrobbled = errors.New("song was not scrobbled")
+
+// Scrobbler marks songs played afterwards, e.g. plays that could not be reported while server was unreachable.
+type Scrobbler interface {
+	// Scrobble marks song as played at given time.
+	Scrobble(song models.Id, played time.Time) error
+}
+
+// RequestStatistics provides statistics of api requests made to server.
+type RequestStatistics interface {
+	// GetRequestStats returns statistics of requests since start.
+	GetRequestStats() models.RequestStats
+}
+
 // Cacher describes how data may be pulled from remote server
 // and might override some Browser methods.
 type Cacher interface {
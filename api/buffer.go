@@ -20,16 +20,19 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 	"sync"
 	"time"
 	"tryffel.net/go/jellycli/config"
 	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/metrics"
 )
 
 func MimeToAudioFormat(mimeType string) (format interfaces.AudioFormat, err error) {
@@ -50,6 +53,19 @@ func MimeToAudioFormat(mimeType string) (format interfaces.AudioFormat, err erro
 	return
 }
 
+const (
+	// trackResumeTimeout is how long interrupted whole track download is retried.
+	trackResumeTimeout = time.Minute
+	// trackResumeInterval is delay between retries.
+	trackResumeInterval = time.Second * 2
+)
+
+// streamStore holds downloaded stream until it is read.
+type streamStore interface {
+	io.ReadWriter
+	Len() int
+}
+
 // StreamBuffer is a buffer that reads whole http body in the background and copies it to local buffer.
 type StreamBuffer struct {
 	lock           *sync.Mutex
@@ -57,23 +73,58 @@ type StreamBuffer struct {
 	headers        map[string]string
 	params         map[string]string
 	client         *http.Client
-	buff           *bytes.Buffer
+	buff           streamStore
 	bitrate        int
 	req            *http.Request
 	resp           *http.Response
 	cancelDownload chan bool
+	// downloaded is set once whole stream is downloaded or download fails.
+	downloaded bool
+	// underrun is set when buffer runs empty before stream is downloaded.
+	underrun bool
+	// wholeTrack is set when whole track is buffered to temporary file without memory limit,
+	// and interrupted download is resumed. See config.Player.BufferWholeTrack.
+	wholeTrack bool
+	// total is size of the stream in bytes, or 0 if not known.
+	total int64
+	// received is number of bytes downloaded so far.
+	received int64
+	// closed is set once stream is closed.
+	closed bool
 }
 
 func (s *StreamBuffer) Read(p []byte) (n int, err error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	n, err = s.buff.Read(p)
+	if n == 0 && len(p) > 0 && !s.downloaded {
+		if !s.underrun {
+			metrics.BufferUnderruns.Inc()
+			logrus.Warning("Stream buffer underrun")
+		}
+		s.underrun = true
+	} else if n > 0 {
+		s.underrun = false
+	}
 	return
 }
 
 func (s *StreamBuffer) Close() error {
 	logrus.Debug("Close stream download")
-	return s.resp.Body.Close()
+	s.lock.Lock()
+	s.closed = true
+	resp := s.resp
+	s.lock.Unlock()
+	err := resp.Body.Close()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if closer, ok := s.buff.(io.Closer); ok {
+		if closeErr := closer.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
 }
 
 func (s *StreamBuffer) Len() int {
@@ -96,6 +147,22 @@ func (s *StreamBuffer) AudioFormat() (format interfaces.AudioFormat, err error)
 	return interfaces.AudioFormatNil, errors.New("no http response")
 }
 
+// ContentLength returns size of the stream in bytes, or 0 if size is not known,
+// which is usually the case when server is transcoding the stream.
+func (s *StreamBuffer) ContentLength() int64 {
+	return s.total
+}
+
+// Progress implements interfaces.DownloadProgress. Progress is only reported when buffering whole track.
+func (s *StreamBuffer) Progress() (downloaded, total int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.wholeTrack {
+		return 0, 0
+	}
+	return s.received, s.total
+}
+
 func NewStreamDownload(url string, headers map[string]string, params map[string]string,
 	client *http.Client, duration int) (*StreamBuffer, error) {
 	stream := &StreamBuffer{
@@ -113,6 +180,16 @@ func NewStreamDownload(url string, headers map[string]string, params map[string]
 	stream.client = client
 
 	var err error
+	if config.AppConfig.Player.BufferWholeTrack {
+		buff, err := newFileBuffer()
+		if err != nil {
+			logrus.Errorf("create file for buffering whole track, buffer to memory instead: %v", err)
+		} else {
+			stream.buff = buff
+			stream.wholeTrack = true
+		}
+	}
+
 	stream.req, err = http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return stream, fmt.Errorf("init http request: %v", err)
@@ -139,6 +216,9 @@ func NewStreamDownload(url string, headers map[string]string, params map[string]
 		return stream, fmt.Errorf("http request error, statuscode: %d", stream.resp.StatusCode)
 
 	}
+	if stream.resp.ContentLength > 0 {
+		stream.total = stream.resp.ContentLength
+	}
 
 	sLength := stream.resp.Header.Get("Content-Length")
 	length, err := strconv.Atoi(sLength)
@@ -165,7 +245,7 @@ loop:
 	for {
 		select {
 		case <-timer.C:
-			if s.buff.Len()/1024/1024 > config.AppConfig.Player.HttpBufferingLimitMem {
+			if !s.wholeTrack && s.Len()/1024/1024 > config.AppConfig.Player.HttpBufferingLimitMem {
 				logrus.Tracef("Buffer is full")
 				timer.Reset(time.Second)
 			} else {
@@ -186,42 +266,49 @@ loop:
 }
 
 func (s *StreamBuffer) readData() bool {
-	var nHttp int
-	var nBuff int
-	var err error
 	buf := make([]byte, s.bitrate*5)
 
+	// only this goroutine replaces response, and reading body must not block reading buffer,
+	// which would stall playback whenever network stalls.
+	nHttp, err := s.resp.Body.Read(buf)
+
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	nHttp, err = s.resp.Body.Read(buf)
+	if nHttp > 0 && !s.closed {
+		nBuff, err := s.buff.Write(buf[0:nHttp])
+		if err != nil {
+			logrus.Warningf("Copy buffer: %v", err)
+		}
+		if nBuff != nHttp {
+			logrus.Warningf("incomplete buffer read: have %d B, want %d B", nBuff, nHttp)
+		}
+		s.received += int64(nBuff)
+	}
+
 	stop := false
 	if err != nil {
 		if err == io.EOF {
 			if nHttp == 0 {
 				logrus.Debugf("buffer download complete")
 				stop = true
-			} else {
-				// pass
 			}
-		} else {
-			logrus.Errorf("buffer read bytes from body: %v", err)
+		} else if s.closed {
 			stop = true
+		} else {
+			s.lock.Unlock()
+			resumed := s.resume(err)
+			s.lock.Lock()
+			if !resumed {
+				logrus.Errorf("buffer read bytes from body: %v", err)
+				stop = true
+			}
 		}
 	}
 
-	buf = buf[0:nHttp]
-	if nHttp > 0 {
-		nBuff, err = s.buff.Write(buf)
-		if err != nil {
-			if err == io.EOF {
-			} else {
-				logrus.Warningf("Copy buffer: %v", err)
-			}
-		}
-		if nBuff != nHttp {
-			logrus.Warningf("incomplete buffer read: have %d B, want %d B", nBuff, nHttp)
-		}
+	if stop {
+		s.downloaded = true
 	}
+
 	size := s.buff.Len()
 	if size > 0 && s.bitrate > 0 {
 		logrus.Tracef("Buffer: %d KiB, %d sec, bitrate %d bit/s", size/1024, size/s.bitrate, s.bitrate)
@@ -231,3 +318,68 @@ func (s *StreamBuffer) readData() bool {
 	}
 	return stop
 }
+
+// resume continues interrupted whole track download from where it stopped. Request is retried
+// until trackResumeTimeout, while playback continues from data that is already downloaded.
+// Resuming requires stream size to be known, so transcoded streams are not resumed.
+func (s *StreamBuffer) resume(cause error) bool {
+	if !s.wholeTrack || s.total == 0 {
+		return false
+	}
+	logrus.Warningf("track download interrupted, resume: %v", cause)
+	deadline := time.Now().Add(trackResumeTimeout)
+	for {
+		s.lock.Lock()
+		closed := s.closed
+		offset := s.received
+		s.lock.Unlock()
+		if closed {
+			return false
+		}
+
+		resp, err := s.rangeRequest(offset)
+		if err == nil {
+			s.lock.Lock()
+			if s.closed {
+				s.lock.Unlock()
+				resp.Body.Close()
+				return false
+			}
+			old := s.resp
+			s.resp = resp
+			s.lock.Unlock()
+			old.Body.Close()
+			logrus.Infof("resumed track download at %d KiB", offset/1024)
+			return true
+		}
+		logrus.Debugf("resume track download: %v", err)
+		if time.Now().Add(trackResumeInterval).After(deadline) {
+			return false
+		}
+		time.Sleep(trackResumeInterval)
+	}
+}
+
+// rangeRequest requests stream starting from offset. If server does not support ranges,
+// beginning of the stream is discarded.
+func (s *StreamBuffer) rangeRequest(offset int64) (*http.Response, error) {
+	req := s.req.Clone(context.Background())
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp, nil
+	case http.StatusOK:
+		_, err = io.CopyN(ioutil.Discard, resp.Body, offset)
+		if err == nil {
+			return resp, nil
+		}
+	default:
+		err = fmt.Errorf("http request error, statuscode: %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+	return nil, err
+}
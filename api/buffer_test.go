@@ -0,0 +1,157 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// failingReader returns error after data has been read.
+type failingReader struct {
+	data io.Reader
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	n, err := f.data.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func TestFileBuffer(t *testing.T) {
+	buff, err := newFileBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := buff.file.Name()
+
+	buff.Write([]byte("abcd"))
+	buff.Write([]byte("ef"))
+	if buff.Len() != 6 {
+		t.Errorf("Len() = %d, want 6", buff.Len())
+	}
+
+	p := make([]byte, 4)
+	n, err := buff.Read(p)
+	if err != nil || string(p[:n]) != "abcd" {
+		t.Errorf("Read() = %q, %v, want abcd", p[:n], err)
+	}
+	buff.Write([]byte("g"))
+	n, err = buff.Read(p)
+	if err != nil || string(p[:n]) != "efg" {
+		t.Errorf("Read() = %q, %v, want efg", p[:n], err)
+	}
+	if n, err = buff.Read(p); n != 0 || err != io.EOF {
+		t.Errorf("Read() on empty buffer = %d, %v, want 0, EOF", n, err)
+	}
+
+	if err := buff.Close(); err != nil {
+		t.Errorf("Close(): %v", err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("temporary file not removed: %v", err)
+	}
+}
+
+func TestStreamBuffer_Resume(t *testing.T) {
+	track := strings.Repeat("0123456789", 100)
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "range supported", status: http.StatusPartialContent},
+		{name: "range ignored", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rangeHeader string
+			client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				rangeHeader = req.Header.Get("Range")
+				body := track
+				if tt.status == http.StatusPartialContent {
+					body = track[400:]
+				}
+				return response(tt.status, body), nil
+			})}
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost/Audio/1/stream", nil)
+			buff, err := newFileBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream := &StreamBuffer{
+				lock:    &sync.Mutex{},
+				client:  client,
+				req:     req,
+				buff:    buff,
+				bitrate: 20,
+				resp: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(&failingReader{data: strings.NewReader(track[:400])}),
+				},
+				wholeTrack: true,
+				total:      int64(len(track)),
+			}
+			defer stream.Close()
+
+			for !stream.readData() {
+			}
+			if rangeHeader != "bytes=400-" {
+				t.Errorf("Range = %q, want bytes=400-", rangeHeader)
+			}
+			downloaded, total := stream.Progress()
+			if downloaded != total || total != int64(len(track)) {
+				t.Errorf("Progress() = %d, %d, want %d", downloaded, total, len(track))
+			}
+			got := &bytes.Buffer{}
+			io.Copy(got, stream)
+			if got.String() != track {
+				t.Errorf("stream content does not match track, got %d B", got.Len())
+			}
+		})
+	}
+}
+
+func TestStreamBuffer_NoResumeWithoutWholeTrack(t *testing.T) {
+	stream := &StreamBuffer{
+		lock:    &sync.Mutex{},
+		buff:    &bytes.Buffer{},
+		bitrate: 20,
+		resp: &http.Response{
+			Body: ioutil.NopCloser(&failingReader{data: strings.NewReader("data")}),
+		},
+		total: 100,
+	}
+	for !stream.readData() {
+	}
+	if !stream.downloaded {
+		t.Error("download not stopped")
+	}
+	if downloaded, total := stream.Progress(); downloaded != 0 || total != 0 {
+		t.Errorf("Progress() = %d, %d, want no progress", downloaded, total)
+	}
+}
@@ -0,0 +1,389 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"github.com/sirupsen/logrus"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/metrics"
+	"tryffel.net/go/jellycli/models"
+)
+
+const (
+	// maxRetries is the number of retries after first failed request.
+	maxRetries = 3
+	// retryDelay is the initial delay before retry. It is doubled on every retry.
+	retryDelay = time.Millisecond * 500
+	// maxRetryAfter is the longest Retry-After that is waited. Response is returned as is if server
+	// asks to wait longer.
+	maxRetryAfter = time.Second * 30
+)
+
+// Client makes api requests with http client. It retries transient errors with jittered backoff
+// and shares response of identical concurrent GET requests. Responses are requested gzip-compressed,
+// and if response cache is set, GET responses with ETag are cached and revalidated with If-None-Match.
+// Responses are read to memory, so Client must not be used for streaming media.
+type Client struct {
+	client     *http.Client
+	maxRetries int
+	retryDelay time.Duration
+	cache      ResponseCache
+
+	lock  sync.Mutex
+	calls map[string]*call
+	stats models.RequestStats
+}
+
+// ResponseCache stores responses of GET requests for conditional requests.
+type ResponseCache interface {
+	// GetResponse returns ETag and body of cached response.
+	GetResponse(key string) (etag string, body []byte, found bool)
+	// PutResponse stores response body with its ETag.
+	PutResponse(key string, etag string, body []byte)
+}
+
+// call is a request in progress. Identical requests wait for it to complete.
+type call struct {
+	done chan struct{}
+	ctx  context.Context
+	resp *http.Response
+	body []byte
+	err  error
+}
+
+// NewClient returns client that makes requests with given http client.
+func NewClient(client *http.Client) *Client {
+	return &Client{
+		client:     client,
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+		calls:      map[string]*call{},
+	}
+}
+
+// SetResponseCache sets cache for conditional requests. Nil disables conditional requests.
+func (c *Client) SetResponseCache(cache ResponseCache) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.cache = cache
+}
+
+// Stats returns statistics of requests.
+func (c *Client) Stats() models.RequestStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.stats
+}
+
+// count updates stats.
+func (c *Client) count(f func(stats *models.RequestStats)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	f(&c.stats)
+}
+
+// Do makes request. Caller must close response body.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Body != nil {
+		return c.retry(req)
+	}
+
+	key := requestKey(req)
+	c.lock.Lock()
+	for {
+		existing, ok := c.calls[key]
+		if !ok {
+			break
+		}
+		c.stats.Shared += 1
+		c.lock.Unlock()
+		select {
+		case <-existing.done:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		if existing.err == nil || existing.ctx.Err() == nil {
+			return existing.response()
+		}
+		// caller that made the request cancelled it, make it again
+		c.lock.Lock()
+	}
+	current := &call{done: make(chan struct{}), ctx: req.Context()}
+	c.calls[key] = current
+	c.lock.Unlock()
+
+	current.resp, current.body, current.err = c.get(req)
+
+	c.lock.Lock()
+	delete(c.calls, key)
+	c.lock.Unlock()
+	close(current.done)
+	return current.response()
+}
+
+// response returns copy of response with its own body.
+func (c *call) response() (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	resp := *c.resp
+	resp.Body = ioutil.NopCloser(bytes.NewReader(c.body))
+	return &resp, nil
+}
+
+// get makes GET request and reads response body. If response is cached, request is made
+// with If-None-Match and cached body is returned if server responds with 304 Not Modified.
+func (c *Client) get(req *http.Request) (*http.Response, []byte, error) {
+	c.lock.Lock()
+	cache := c.cache
+	c.lock.Unlock()
+
+	key := req.URL.String()
+	var etag string
+	var cached []byte
+	found := false
+	if cache != nil {
+		etag, cached, found = cache.GetResponse(key)
+	}
+	if found {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", etag)
+		c.count(func(stats *models.RequestStats) { stats.Revalidated += 1 })
+	}
+
+	resp, err := c.retry(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if found && resp.StatusCode == http.StatusNotModified {
+		c.count(func(stats *models.RequestStats) {
+			stats.NotModified += 1
+			stats.BytesCached += len(cached)
+		})
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK"
+		return resp, cached, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	etag = resp.Header.Get("ETag")
+	if cache != nil && resp.StatusCode == http.StatusOK && etag != "" && cacheable(resp) {
+		cache.PutResponse(key, etag, body)
+	}
+	return resp, body, nil
+}
+
+// cacheable returns true if response is json or xml.
+func cacheable(resp *http.Response) bool {
+	contentType := resp.Header.Get("Content-Type")
+	return strings.HasPrefix(contentType, "application/json") || strings.Contains(contentType, "xml")
+}
+
+// retry makes request and retries it on transient errors. Only GET and HEAD requests are retried.
+func (c *Client) retry(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(req)
+		if attempt >= c.maxRetries || !idempotent(req) || !transient(resp, err) {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				if after > maxRetryAfter {
+					return resp, err
+				}
+				delay = after
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+			logrus.Debugf("%s %s: %d, retry in %d ms", req.Method, req.URL.Path, resp.StatusCode,
+				delay.Milliseconds())
+		} else {
+			logrus.Debugf("%s %s: %v, retry in %d ms", req.Method, req.URL.Path, err, delay.Milliseconds())
+		}
+
+		c.count(func(stats *models.RequestStats) { stats.Retries += 1 })
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// send makes single request. Response is requested gzip-compressed, which http.Transport does too,
+// but decompressing it here allows counting received bytes.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	c.count(func(stats *models.RequestStats) { stats.Requests += 1 })
+	if req.Header.Get("Accept-Encoding") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	metrics.ApiLatency.Observe(time.Since(start).Seconds())
+	if err != nil || resp.StatusCode >= 500 {
+		metrics.ApiErrors.Inc()
+	}
+	if err != nil {
+		return resp, err
+	}
+
+	body := io.ReadCloser(&countingBody{body: resp.Body, count: c.received})
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		body = &gzipBody{body: body}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+	resp.Body = &countingBody{body: body, count: c.decoded}
+	return resp, nil
+}
+
+func (c *Client) received(n int) {
+	c.count(func(stats *models.RequestStats) { stats.BytesReceived += n })
+}
+
+func (c *Client) decoded(n int) {
+	c.count(func(stats *models.RequestStats) { stats.BytesDecoded += n })
+}
+
+// countingBody counts bytes read from body.
+type countingBody struct {
+	body  io.ReadCloser
+	count func(n int)
+}
+
+func (c *countingBody) Read(p []byte) (int, error) {
+	n, err := c.body.Read(p)
+	if n > 0 {
+		c.count(n)
+	}
+	return n, err
+}
+
+func (c *countingBody) Close() error {
+	return c.body.Close()
+}
+
+// gzipBody decompresses body. Reader is created on first read, as empty body has no gzip header.
+type gzipBody struct {
+	body   io.ReadCloser
+	reader *gzip.Reader
+	err    error
+}
+
+func (g *gzipBody) Read(p []byte) (int, error) {
+	if g.reader == nil && g.err == nil {
+		g.reader, g.err = gzip.NewReader(g.body)
+	}
+	if g.err != nil {
+		return 0, g.err
+	}
+	return g.reader.Read(p)
+}
+
+func (g *gzipBody) Close() error {
+	return g.body.Close()
+}
+
+// backoff returns delay before retry, with random jitter of up to half the delay.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.retryDelay << uint(attempt)
+	if delay <= 1 {
+		return delay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+func idempotent(req *http.Request) bool {
+	return req.Method == http.MethodGet || req.Method == http.MethodHead
+}
+
+// transient returns true if request failed with timeout or server is temporarily unavailable.
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		netErr, ok := err.(net.Error)
+		return ok && netErr.Timeout()
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses Retry-After header, which is either seconds or http date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	delay := time.Until(date)
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
+
+// requestKey identifies identical requests by method, url and headers.
+func requestKey(req *http.Request) string {
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	b := strings.Builder{}
+	b.WriteString(req.Method + " " + req.URL.String())
+	for _, key := range keys {
+		b.WriteString("\n" + key + ": " + strings.Join(req.Header[key], ","))
+	}
+	return b.String()
+}
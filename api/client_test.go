@@ -0,0 +1,298 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testClient(transport http.RoundTripper) *Client {
+	client := NewClient(&http.Client{Transport: transport})
+	client.retryDelay = time.Millisecond
+	return client
+}
+
+func response(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	var requests int32
+	client := testClient(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			resp := response(http.StatusServiceUnavailable, "")
+			resp.Header.Set("Retry-After", "0")
+			return resp, nil
+		}
+		return response(http.StatusOK, "ok"), nil
+	}))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/Items", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("got %d: %s", resp.StatusCode, body)
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+}
+
+func TestClient_RetryLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		status int
+		want   int32
+	}{
+		{name: "server error", method: http.MethodGet, status: http.StatusBadGateway, want: maxRetries + 1},
+		{name: "not found", method: http.MethodGet, status: http.StatusNotFound, want: 1},
+		{name: "post", method: http.MethodPost, status: http.StatusServiceUnavailable, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			client := testClient(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&requests, 1)
+				return response(tt.status, ""), nil
+			}))
+			req, _ := http.NewRequest(tt.method, "http://localhost/Items", nil)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.status)
+			}
+			if requests != tt.want {
+				t.Errorf("got %d requests, want %d", requests, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Coalesce(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	client := testClient(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		return response(http.StatusOK, req.URL.Path), nil
+	}))
+
+	wg := sync.WaitGroup{}
+	bodies := make([]string, 5)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost/Artists", nil)
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+			bodies[i] = string(body)
+		}(i)
+	}
+	// wait for all requests to be waiting
+	for {
+		client.lock.Lock()
+		started := len(client.calls)
+		client.lock.Unlock()
+		if started == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(time.Millisecond * 10)
+	close(release)
+	wg.Wait()
+
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+	for i, body := range bodies {
+		if body != "/Artists" {
+			t.Errorf("request %d got body '%s'", i, body)
+		}
+	}
+}
+
+func TestClient_CoalesceCancelled(t *testing.T) {
+	var requests int32
+	client := testClient(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}
+		return response(http.StatusOK, "ok"), nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/Artists", nil)
+		_, err := client.Do(req)
+		cancelled <- err
+	}()
+	for atomic.LoadInt32(&requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan string)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost/Artists", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Error(err)
+			done <- ""
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		done <- string(body)
+	}()
+	for client.Stats().Shared == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	if err := <-cancelled; err == nil {
+		t.Errorf("cancelled request did not fail")
+	}
+	if body := <-done; body != "ok" {
+		t.Errorf("waiting request got body '%s'", body)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+}
+
+type testCache map[string][2]string
+
+func (c testCache) GetResponse(key string) (string, []byte, bool) {
+	resp, ok := c[key]
+	return resp[0], []byte(resp[1]), ok
+}
+
+func (c testCache) PutResponse(key string, etag string, body []byte) {
+	c[key] = [2]string{etag, string(body)}
+}
+
+func TestClient_NotModified(t *testing.T) {
+	client := testClient(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("If-None-Match") == "etag-1" {
+			return response(http.StatusNotModified, ""), nil
+		}
+		resp := response(http.StatusOK, "[1,2,3]")
+		resp.Header.Set("ETag", "etag-1")
+		resp.Header.Set("Content-Type", "application/json; charset=utf-8")
+		return resp, nil
+	}))
+	client.SetResponseCache(testCache{})
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost/Items", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || string(body) != "[1,2,3]" {
+			t.Errorf("request %d got %d: %s", i, resp.StatusCode, body)
+		}
+	}
+
+	stats := client.Stats()
+	if stats.Requests != 2 || stats.Revalidated != 1 || stats.NotModified != 1 || stats.BytesCached != 7 {
+		t.Errorf("got stats %+v", stats)
+	}
+}
+
+func TestClient_Gzip(t *testing.T) {
+	compressed := &bytes.Buffer{}
+	writer := gzip.NewWriter(compressed)
+	writer.Write([]byte(strings.Repeat("artist", 100)))
+	writer.Close()
+	size := compressed.Len()
+
+	client := testClient(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Accept-Encoding") != "gzip" {
+			return response(http.StatusOK, strings.Repeat("artist", 100)), nil
+		}
+		resp := response(http.StatusOK, compressed.String())
+		resp.Header.Set("Content-Encoding", "gzip")
+		return resp, nil
+	}))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost/Artists", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != strings.Repeat("artist", 100) {
+		t.Errorf("got body %s", body)
+	}
+
+	stats := client.Stats()
+	if stats.BytesReceived != size || stats.BytesDecoded != 600 {
+		t.Errorf("got %d bytes received, %d decoded, want %d, %d", stats.BytesReceived, stats.BytesDecoded,
+			size, 600)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{value: "", ok: false},
+		{value: "120", want: time.Minute * 2, ok: true},
+		{value: "-1", ok: false},
+		{value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, ok: true},
+		{value: "soon", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%s) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"errors"
+	"github.com/sirupsen/logrus"
+	"net"
+	"sync"
+	"time"
+)
+
+// Reconnect backoff limits
+const (
+	reconnectMinBackoff = time.Second
+	reconnectMaxBackoff = time.Minute
+)
+
+// reconnectBackoff returns delay before reconnect attempt, starting from attempt 0.
+// Delay doubles on every attempt until it reaches reconnectMaxBackoff.
+func reconnectBackoff(attempt int) time.Duration {
+	backoff := reconnectMinBackoff
+	for i := 0; i < attempt && backoff < reconnectMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > reconnectMaxBackoff {
+		backoff = reconnectMaxBackoff
+	}
+	return backoff
+}
+
+// ConnectionMonitor tracks whether server is reachable. Once a request fails due to network error,
+// server is considered offline and monitor tries to reconnect with exponential backoff until
+// server responds again.
+type ConnectionMonitor struct {
+	lock      sync.Mutex
+	offline   bool
+	callbacks []func(online bool)
+	// reconnect returns nil once server is reachable and session is restored.
+	reconnect func() error
+	// sleep waits before next reconnect attempt, replaceable for testing.
+	sleep func(duration time.Duration)
+}
+
+// NewConnectionMonitor creates new monitor. Reconnect is called while server is offline,
+// and it should return nil once connection is restored.
+func NewConnectionMonitor(reconnect func() error) *ConnectionMonitor {
+	return &ConnectionMonitor{
+		reconnect: reconnect,
+		sleep:     time.Sleep,
+	}
+}
+
+// AddConnectionCallback implements interfaces.ConnectionNotifier.
+func (c *ConnectionMonitor) AddConnectionCallback(cb func(online bool)) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.callbacks = append(c.callbacks, cb)
+}
+
+// Online returns true unless server is known to be unreachable.
+func (c *ConnectionMonitor) Online() bool {
+	if c == nil {
+		return true
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return !c.offline
+}
+
+// RequestFailed marks server offline if err is a network error, and starts reconnecting in background.
+// Other errors, e.g. invalid responses, are ignored.
+func (c *ConnectionMonitor) RequestFailed(err error) {
+	var netErr net.Error
+	if c == nil || err == nil || !errors.As(err, &netErr) {
+		return
+	}
+
+	c.lock.Lock()
+	if c.offline {
+		c.lock.Unlock()
+		return
+	}
+	c.offline = true
+	c.lock.Unlock()
+
+	logrus.Warningf("Server is offline: %v", err)
+	go c.reconnectLoop()
+}
+
+func (c *ConnectionMonitor) reconnectLoop() {
+	c.notify(false)
+	for attempt := 0; ; attempt++ {
+		backoff := reconnectBackoff(attempt)
+		logrus.Debugf("Reconnecting to server in %s", backoff)
+		c.sleep(backoff)
+		err := c.reconnect()
+		if err == nil {
+			break
+		}
+		logrus.Debugf("reconnect to server: %v", err)
+	}
+
+	c.lock.Lock()
+	c.offline = false
+	c.lock.Unlock()
+	logrus.Info("Connection to server restored")
+	c.notify(true)
+}
+
+func (c *ConnectionMonitor) notify(online bool) {
+	c.lock.Lock()
+	callbacks := make([]func(online bool), len(c.callbacks))
+	copy(callbacks, c.callbacks)
+	c.lock.Unlock()
+
+	for _, cb := range callbacks {
+		cb(online)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_reconnectBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Second},
+		{attempt: 1, want: time.Second * 2},
+		{attempt: 3, want: time.Second * 8},
+		{attempt: 6, want: time.Minute},
+		{attempt: 100, want: time.Minute},
+	}
+	for _, tt := range tests {
+		if got := reconnectBackoff(tt.attempt); got != tt.want {
+			t.Errorf("reconnectBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestConnectionMonitor_RequestFailed(t *testing.T) {
+	attempts := 0
+	monitor := NewConnectionMonitor(func() error {
+		attempts += 1
+		if attempts < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	var backoffs []time.Duration
+	monitor.sleep = func(duration time.Duration) {
+		backoffs = append(backoffs, duration)
+	}
+
+	states := make(chan bool, 2)
+	monitor.AddConnectionCallback(func(online bool) {
+		states <- online
+	})
+
+	monitor.RequestFailed(errors.New("invalid json"))
+	if !monitor.Online() {
+		t.Fatalf("monitor offline after non-network error")
+	}
+
+	monitor.RequestFailed(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+	for _, want := range []bool{false, true} {
+		select {
+		case got := <-states:
+			if got != want {
+				t.Errorf("callback online = %v, want %v", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("callback not called")
+		}
+	}
+
+	if !monitor.Online() {
+		t.Errorf("monitor offline after reconnecting")
+	}
+	if attempts != 3 {
+		t.Errorf("reconnect attempts = %d, want 3", attempts)
+	}
+	want := []time.Duration{time.Second, time.Second * 2, time.Second * 4}
+	if len(backoffs) != len(want) {
+		t.Fatalf("backoffs = %v, want %v", backoffs, want)
+	}
+	for i := range want {
+		if backoffs[i] != want[i] {
+			t.Errorf("backoffs = %v, want %v", backoffs, want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// fileBuffer stores stream in temporary file. Unlike bytes.Buffer, data that has been read is kept,
+// which allows buffering whole track regardless of its size. File is removed on Close.
+type fileBuffer struct {
+	file    *os.File
+	written int64
+	read    int64
+}
+
+func newFileBuffer() (*fileBuffer, error) {
+	file, err := ioutil.TempFile("", "jellycli-track-*")
+	if err != nil {
+		return nil, err
+	}
+	return &fileBuffer{file: file}, nil
+}
+
+func (f *fileBuffer) Write(p []byte) (int, error) {
+	n, err := f.file.WriteAt(p, f.written)
+	f.written += int64(n)
+	return n, err
+}
+
+func (f *fileBuffer) Read(p []byte) (int, error) {
+	if f.read >= f.written {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	if remaining := f.written - f.read; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := f.file.ReadAt(p, f.read)
+	f.read += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Len returns number of bytes not yet read.
+func (f *fileBuffer) Len() int {
+	return int(f.written - f.read)
+}
+
+func (f *fileBuffer) Close() error {
+	err := f.file.Close()
+	if removeErr := os.Remove(f.file.Name()); removeErr != nil && err == nil {
+		err = removeErr
+	}
+	return err
+}
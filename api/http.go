@@ -0,0 +1,113 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+	"tryffel.net/go/jellycli/config"
+)
+
+// NewHttpClient returns http client that uses tls settings and custom headers from config.
+// Timeout 0 means no timeout.
+func NewHttpClient(timeout time.Duration) (*http.Client, error) {
+	client := &http.Client{Timeout: timeout}
+	if config.AppConfig == nil {
+		return client, nil
+	}
+	tlsConfig, err := config.AppConfig.Player.TlsConfig()
+	if err != nil {
+		return client, fmt.Errorf("tls config: %v", err)
+	}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+	if len(config.AppConfig.Player.HttpHeaders) > 0 {
+		client.Transport = &headerTransport{
+			headers:   CustomHeaders(),
+			transport: client.Transport,
+		}
+	}
+	return client, nil
+}
+
+// MaxBitrate returns streaming bitrate limit in kbps, or 0 if streams are not limited.
+func MaxBitrate() int {
+	if config.AppConfig == nil {
+		return 0
+	}
+	return config.AppConfig.Player.MaxBitrateKbps
+}
+
+// EncodeUrl returns url with params as query.
+func EncodeUrl(base string, params map[string]string) string {
+	query := url.Values{}
+	for k, v := range params {
+		query.Set(k, v)
+	}
+	return base + "?" + query.Encode()
+}
+
+// CustomHeaders returns headers from config that are added to every request.
+func CustomHeaders() http.Header {
+	headers := http.Header{}
+	if config.AppConfig == nil {
+		return headers
+	}
+	for key, value := range config.AppConfig.Player.HttpHeaders {
+		headers.Set(key, value)
+	}
+	return headers
+}
+
+// TlsConfig returns tls config of client, or nil if client uses default settings.
+func TlsConfig(client *http.Client) *tls.Config {
+	transport := client.Transport
+	if ht, ok := transport.(*headerTransport); ok {
+		transport = ht.transport
+	}
+	if t, ok := transport.(*http.Transport); ok {
+		return t.TLSClientConfig
+	}
+	return nil
+}
+
+// headerTransport adds headers to every request, e.g. for authenticating to reverse proxy.
+type headerTransport struct {
+	headers   http.Header
+	transport http.RoundTripper
+}
+
+func (h *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper must not modify original request
+	req = req.Clone(req.Context())
+	for key, values := range h.headers {
+		req.Header[key] = values
+	}
+	transport := h.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(req)
+}
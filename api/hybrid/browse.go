@@ -0,0 +1,496 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package hybrid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// CanCacheSongs returns false, merged song pages are not exact.
+func (h *Hybrid) CanCacheSongs() bool { return false }
+
+// sourceQuery returns query for source with genre and composer ids of that source.
+// If query is filtered by genre or composer that source does not have, ok is false.
+func sourceQuery(query *interfaces.QueryOpts, source int) (*interfaces.QueryOpts, bool) {
+	q := *query
+	filterIds := func(items []models.IdName) ([]models.IdName, bool) {
+		if len(items) == 0 {
+			return items, true
+		}
+		out := make([]models.IdName, 0, len(items))
+		for _, v := range items {
+			if id, found := sourceIdFor(v.Id, source); found {
+				out = append(out, models.IdName{Id: id, Name: v.Name})
+			}
+		}
+		return out, len(out) > 0
+	}
+
+	var ok bool
+	if q.Filter.Genres, ok = filterIds(query.Filter.Genres); !ok {
+		return nil, false
+	}
+	if q.Filter.Composers, ok = filterIds(query.Filter.Composers); !ok {
+		return nil, false
+	}
+	return &q, true
+}
+
+func maxTotal(total, sourceTotal int) int {
+	if sourceTotal > total {
+		return sourceTotal
+	}
+	return total
+}
+
+// Paged lists are merged page by page: page n contains items from page n of each source.
+// Items are only merged with matching items on the same page.
+
+func (h *Hybrid) GetArtists(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Artist, int, error) {
+	lists := make([][]*models.Artist, len(h.sources))
+	total := 0
+	err := h.each("get artists", func(s *source) error {
+		q, ok := sourceQuery(query, s.index)
+		if !ok {
+			return nil
+		}
+		artists, n, err := s.server.GetArtists(ctx, q)
+		lists[s.index] = s.wrapArtists(artists)
+		total = maxTotal(total, n)
+		return err
+	})
+	return mergeArtists(lists...), total, err
+}
+
+func (h *Hybrid) GetAlbumArtists(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Artist, int, error) {
+	lists := make([][]*models.Artist, len(h.sources))
+	total := 0
+	err := h.each("get album artists", func(s *source) error {
+		q, ok := sourceQuery(query, s.index)
+		if !ok {
+			return nil
+		}
+		artists, n, err := s.server.GetAlbumArtists(ctx, q)
+		lists[s.index] = s.wrapArtists(artists)
+		total = maxTotal(total, n)
+		return err
+	})
+	return mergeArtists(lists...), total, err
+}
+
+func (h *Hybrid) GetAlbums(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Album, int, error) {
+	lists := make([][]*models.Album, len(h.sources))
+	total := 0
+	err := h.each("get albums", func(s *source) error {
+		q, ok := sourceQuery(query, s.index)
+		if !ok {
+			return nil
+		}
+		albums, n, err := s.server.GetAlbums(ctx, q)
+		lists[s.index] = s.wrapAlbums(albums)
+		total = maxTotal(total, n)
+		return err
+	})
+	return mergeAlbums(lists...), total, err
+}
+
+func (h *Hybrid) GetSongs(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Song, int, error) {
+	lists := make([][]*models.Song, len(h.sources))
+	total := 0
+	err := h.each("get songs", func(s *source) error {
+		q, ok := sourceQuery(query, s.index)
+		if !ok {
+			return nil
+		}
+		songs, n, err := s.server.GetSongs(ctx, q)
+		lists[s.index] = h.wrapSongs(s, songs)
+		total = maxTotal(total, n)
+		return err
+	})
+	return mergeSongs(lists...), total, err
+}
+
+// albumsById returns albums of each source for item id.
+func (h *Hybrid) albumsById(action string, id models.Id,
+	get func(s *source, id models.Id) ([]*models.Album, error)) ([]*models.Album, error) {
+	parts := h.parts(id)
+	lists := make([][]*models.Album, len(h.sources))
+	errs := 0
+	var err error
+	for _, v := range parts {
+		s := h.sources[v.source]
+		albums, sourceErr := get(s, v.id)
+		if sourceErr != nil {
+			err = fmt.Errorf("%s from %s: %v", action, s.name, sourceErr)
+			errs += 1
+			continue
+		}
+		lists[v.source] = s.wrapAlbums(albums)
+	}
+	if errs > 0 && errs == len(parts) {
+		return nil, err
+	}
+	return mergeAlbums(lists...), nil
+}
+
+// songsById returns songs of each source for item id.
+func (h *Hybrid) songsById(action string, id models.Id,
+	get func(s *source, id models.Id) ([]*models.Song, error)) ([]*models.Song, error) {
+	parts := h.parts(id)
+	lists := make([][]*models.Song, len(h.sources))
+	errs := 0
+	var err error
+	for _, v := range parts {
+		s := h.sources[v.source]
+		songs, sourceErr := get(s, v.id)
+		if sourceErr != nil {
+			err = fmt.Errorf("%s from %s: %v", action, s.name, sourceErr)
+			errs += 1
+			continue
+		}
+		lists[v.source] = h.wrapSongs(s, songs)
+	}
+	if errs > 0 && errs == len(parts) {
+		return nil, err
+	}
+	return mergeSongs(lists...), nil
+}
+
+// artistsById returns artists of each source for item id.
+func (h *Hybrid) artistsById(action string, id models.Id,
+	get func(s *source, id models.Id) ([]*models.Artist, error)) ([]*models.Artist, error) {
+	parts := h.parts(id)
+	lists := make([][]*models.Artist, len(h.sources))
+	errs := 0
+	var err error
+	for _, v := range parts {
+		s := h.sources[v.source]
+		artists, sourceErr := get(s, v.id)
+		if sourceErr != nil {
+			err = fmt.Errorf("%s from %s: %v", action, s.name, sourceErr)
+			errs += 1
+			continue
+		}
+		lists[v.source] = s.wrapArtists(artists)
+	}
+	if errs > 0 && errs == len(parts) {
+		return nil, err
+	}
+	return mergeArtists(lists...), nil
+}
+
+func (h *Hybrid) GetArtistAlbums(ctx context.Context, artist models.Id) ([]*models.Album, error) {
+	return h.albumsById("get artist albums", artist, func(s *source, id models.Id) ([]*models.Album, error) {
+		return s.server.GetArtistAlbums(ctx, id)
+	})
+}
+
+func (h *Hybrid) GetArtistAppearsOn(ctx context.Context, artist models.Id) ([]*models.Album, error) {
+	return h.albumsById("get artist appears on", artist, func(s *source, id models.Id) ([]*models.Album, error) {
+		return s.server.GetArtistAppearsOn(ctx, id)
+	})
+}
+
+func (h *Hybrid) GetArtistTopSongs(ctx context.Context, artist *models.Artist, limit int) ([]*models.Song, error) {
+	songs, err := h.songsById("get artist top songs", artist.Id, func(s *source, id models.Id) ([]*models.Song, error) {
+		sourceArtist := *artist
+		sourceArtist.Id = id
+		return s.server.GetArtistTopSongs(ctx, &sourceArtist, limit)
+	})
+	if len(songs) > limit {
+		songs = songs[:limit]
+	}
+	return songs, err
+}
+
+func (h *Hybrid) GetArtistOverview(ctx context.Context, artist *models.Artist) (string, error) {
+	var err error
+	for _, v := range h.parts(artist.Id) {
+		sourceArtist := *artist
+		sourceArtist.Id = v.id
+		var overview string
+		overview, err = h.sources[v.source].server.GetArtistOverview(ctx, &sourceArtist)
+		if err == nil && overview != "" {
+			return overview, nil
+		}
+	}
+	return "", err
+}
+
+func (h *Hybrid) GetAlbumSongs(ctx context.Context, album models.Id) ([]*models.Song, error) {
+	songs, err := h.songsById("get album songs", album, func(s *source, id models.Id) ([]*models.Song, error) {
+		return s.server.GetAlbumSongs(ctx, id)
+	})
+	sort.SliceStable(songs, func(i, j int) bool {
+		if songs[i].DiscNumber != songs[j].DiscNumber {
+			return songs[i].DiscNumber < songs[j].DiscNumber
+		}
+		return songs[i].Index < songs[j].Index
+	})
+	return songs, err
+}
+
+// GetPlaylists returns playlists of every server. Playlists are never merged.
+func (h *Hybrid) GetPlaylists(ctx context.Context) ([]*models.Playlist, error) {
+	var playlists []*models.Playlist
+	err := h.each("get playlists", func(s *source) error {
+		sourcePlaylists, err := s.server.GetPlaylists(ctx)
+		for _, v := range sourcePlaylists {
+			playlists = append(playlists, s.wrapPlaylist(v))
+		}
+		return err
+	})
+	return playlists, err
+}
+
+func (h *Hybrid) GetPlaylistSongs(ctx context.Context, playlist models.Id) ([]*models.Song, error) {
+	parts := h.parts(playlist)
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("invalid playlist id: %s", playlist)
+	}
+	s := h.sources[parts[0].source]
+	songs, err := s.server.GetPlaylistSongs(ctx, parts[0].id)
+	return h.wrapSongs(s, songs), err
+}
+
+func (h *Hybrid) GetSimilarArtists(ctx context.Context, artist models.Id) ([]*models.Artist, error) {
+	return h.artistsById("get similar artists", artist, func(s *source, id models.Id) ([]*models.Artist, error) {
+		return s.server.GetSimilarArtists(ctx, id)
+	})
+}
+
+func (h *Hybrid) GetSimilarAlbums(ctx context.Context, album models.Id) ([]*models.Album, error) {
+	return h.albumsById("get similar albums", album, func(s *source, id models.Id) ([]*models.Album, error) {
+		return s.server.GetSimilarAlbums(ctx, id)
+	})
+}
+
+func (h *Hybrid) GetRecentlyPlayed(ctx context.Context, paging interfaces.Paging) ([]*models.Song, int, error) {
+	var songs []*models.Song
+	total := 0
+	err := h.each("get recently played", func(s *source) error {
+		sourceSongs, n, err := s.server.GetRecentlyPlayed(ctx, paging)
+		songs = append(songs, h.wrapSongs(s, sourceSongs)...)
+		total = maxTotal(total, n)
+		return err
+	})
+	return songs, total, err
+}
+
+func (h *Hybrid) GetGenres(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
+	lists := make([][]*models.IdName, len(h.sources))
+	total := 0
+	err := h.each("get genres", func(s *source) error {
+		genres, n, err := s.server.GetGenres(ctx, paging)
+		lists[s.index] = s.wrapIdNames(genres)
+		total = maxTotal(total, n)
+		return err
+	})
+	return mergeIdNames(lists...), total, err
+}
+
+func (h *Hybrid) GetComposers(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
+	lists := make([][]*models.IdName, len(h.sources))
+	total := 0
+	err := h.each("get composers", func(s *source) error {
+		composers, n, err := s.server.GetComposers(ctx, paging)
+		lists[s.index] = s.wrapIdNames(composers)
+		total = maxTotal(total, n)
+		return err
+	})
+	return mergeIdNames(lists...), total, err
+}
+
+func (h *Hybrid) GetAlbumArtist(ctx context.Context, album *models.Album) (*models.Artist, error) {
+	return h.GetArtist(ctx, album.Artist)
+}
+
+// GetInstantMix returns instant mix from first server that has item.
+func (h *Hybrid) GetInstantMix(ctx context.Context, item models.Item) ([]*models.Song, error) {
+	err := fmt.Errorf("invalid item id: %s", item.GetId())
+	for _, v := range h.parts(item.GetId()) {
+		sourceItem := unwrapItem(item, v.id)
+		if sourceItem == nil {
+			return nil, fmt.Errorf("instant mix not supported for %s", item.GetType())
+		}
+		s := h.sources[v.source]
+		var songs []*models.Song
+		songs, err = s.server.GetInstantMix(ctx, sourceItem)
+		if err == nil {
+			return h.wrapSongs(s, songs), nil
+		}
+	}
+	return nil, err
+}
+
+func (h *Hybrid) GetLink(item models.Item) string {
+	for _, v := range h.parts(item.GetId()) {
+		sourceItem := unwrapItem(item, v.id)
+		if sourceItem == nil {
+			return ""
+		}
+		if link := h.sources[v.source].server.GetLink(sourceItem); link != "" {
+			return link
+		}
+	}
+	return ""
+}
+
+func (h *Hybrid) Search(ctx context.Context, query string, itemType models.ItemType, maxResults int) ([]models.Item, error) {
+	lists := make([][]models.Item, len(h.sources))
+	err := h.each("search", func(s *source) error {
+		items, err := s.server.Search(ctx, query, itemType, maxResults)
+		for _, v := range items {
+			lists[s.index] = append(lists[s.index], s.wrapItem(v))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch itemType {
+	case models.TypeArtist:
+		artists := make([][]*models.Artist, len(lists))
+		for i, list := range lists {
+			for _, v := range list {
+				if artist, ok := v.(*models.Artist); ok {
+					artists[i] = append(artists[i], artist)
+				}
+			}
+		}
+		return models.ArtistsToItems(mergeArtists(artists...)), nil
+	case models.TypeAlbum:
+		albums := make([][]*models.Album, len(lists))
+		for i, list := range lists {
+			for _, v := range list {
+				if album, ok := v.(*models.Album); ok {
+					albums[i] = append(albums[i], album)
+				}
+			}
+		}
+		return models.AlbumsToItems(mergeAlbums(albums...)), nil
+	case models.TypeSong:
+		songs := make([][]*models.Song, len(lists))
+		for i, list := range lists {
+			for _, v := range list {
+				if song, ok := v.(*models.Song); ok {
+					songs[i] = append(songs[i], song)
+				}
+			}
+			h.recordCodecs(songs[i])
+		}
+		return models.SongsToItems(mergeSongs(songs...)), nil
+	}
+
+	var items []models.Item
+	for _, list := range lists {
+		items = append(items, list...)
+	}
+	return items, nil
+}
+
+// GetAlbum returns album, merged from every server that has it.
+func (h *Hybrid) GetAlbum(ctx context.Context, id models.Id) (*models.Album, error) {
+	var album *models.Album
+	var err error
+	for _, v := range h.parts(id) {
+		s := h.sources[v.source]
+		sourceAlbum, sourceErr := s.server.GetAlbum(ctx, v.id)
+		if sourceErr != nil {
+			err = fmt.Errorf("get album from %s: %v", s.name, sourceErr)
+			continue
+		}
+		s.wrapAlbum(sourceAlbum)
+		if album == nil {
+			album = sourceAlbum
+		} else {
+			mergeAlbum(album, sourceAlbum)
+		}
+	}
+	if album == nil {
+		if err == nil {
+			err = fmt.Errorf("invalid album id: %s", id)
+		}
+		return nil, err
+	}
+	return album, nil
+}
+
+// GetArtist returns artist, merged from every server that has it.
+func (h *Hybrid) GetArtist(ctx context.Context, id models.Id) (*models.Artist, error) {
+	var artist *models.Artist
+	var err error
+	for _, v := range h.parts(id) {
+		s := h.sources[v.source]
+		sourceArtist, sourceErr := s.server.GetArtist(ctx, v.id)
+		if sourceErr != nil {
+			err = fmt.Errorf("get artist from %s: %v", s.name, sourceErr)
+			continue
+		}
+		s.wrapArtist(sourceArtist)
+		if artist == nil {
+			artist = sourceArtist
+		} else {
+			mergeArtist(artist, sourceArtist)
+		}
+	}
+	if artist == nil {
+		if err == nil {
+			err = fmt.Errorf("invalid artist id: %s", id)
+		}
+		return nil, err
+	}
+	return artist, nil
+}
+
+// GetItemInfo returns info from server that item would be streamed from.
+func (h *Hybrid) GetItemInfo(ctx context.Context, item models.Item) (*models.ItemInfo, error) {
+	parts := h.preferred(item.GetId())
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("invalid item id: %s", item.GetId())
+	}
+	sourceItem := unwrapItem(item, parts[0].id)
+	if sourceItem == nil {
+		return nil, errors.New("no info for item")
+	}
+	s := h.sources[parts[0].source]
+	info, err := s.server.GetItemInfo(ctx, sourceItem)
+	if err != nil {
+		return nil, err
+	}
+	info.Id = item.GetId()
+	info.Album.Id = encodeId(s.index, info.Album.Id)
+	info.Artists = encodeIdNames(s.index, info.Artists)
+	info.Genres = encodeIdNames(s.index, info.Genres)
+	return info, nil
+}
+
+func (h *Hybrid) GetImageUrl(item models.Id, itemType models.ItemType) string {
+	for _, v := range h.parts(item) {
+		if url := h.sources[v.source].server.GetImageUrl(v.id, itemType); url != "" {
+			return url
+		}
+	}
+	return ""
+}
@@ -0,0 +1,432 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+// Package hybrid contains remote server implementation that merges libraries of multiple servers.
+// Implemented: api.Browser.
+// Remote control and other server-specific features are not supported in hybrid mode.
+package hybrid
+
+import (
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// source is single server in hybrid library.
+type source struct {
+	index  int
+	name   string
+	server api.MediaServer
+}
+
+// Hybrid merges multiple servers into one library. First server is the primary server.
+type Hybrid struct {
+	sources    []*source
+	preference string
+
+	lock *sync.Mutex
+	// codecs contains codec for each source song id, for selecting lossless source.
+	codecs map[models.Id]string
+	// streamed contains source that each song was last streamed from.
+	streamed map[models.Id]int
+}
+
+// NewHybrid creates new hybrid library from servers. Preference is one of config.StreamPrefer*.
+func NewHybrid(servers []api.MediaServer, preference string) (*Hybrid, error) {
+	if len(servers) == 0 {
+		return nil, errors.New("no servers")
+	}
+	h := &Hybrid{
+		sources:    make([]*source, len(servers)),
+		preference: preference,
+		lock:       &sync.Mutex{},
+		codecs:     map[models.Id]string{},
+		streamed:   map[models.Id]int{},
+	}
+	for i, v := range servers {
+		h.sources[i] = &source{
+			index:  i,
+			name:   v.GetConfig().GetType(),
+			server: v,
+		}
+	}
+	return h, nil
+}
+
+// each calls f for each source. Error is returned only if every source fails,
+// failures of single sources are logged.
+func (h *Hybrid) each(action string, f func(s *source) error) error {
+	var firstErr error
+	failed := 0
+	for _, s := range h.sources {
+		err := f(s)
+		if err != nil {
+			logrus.Warningf("%s from %s: %v", action, s.name, err)
+			failed += 1
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if failed == len(h.sources) {
+		return firstErr
+	}
+	return nil
+}
+
+// parts returns sources and source ids for id, in source order.
+func (h *Hybrid) parts(id models.Id) []sourceId {
+	parts := decodeId(id)
+	valid := parts[:0]
+	for _, v := range parts {
+		if v.source >= 0 && v.source < len(h.sources) {
+			valid = append(valid, v)
+		}
+	}
+	return valid
+}
+
+// recordCodecs stores codecs of songs from source.
+func (h *Hybrid) recordCodecs(songs []*models.Song) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, v := range songs {
+		if v.Codec != "" {
+			h.codecs[v.Id] = v.Codec
+		}
+	}
+}
+
+// wrapSongs converts songs from source to hybrid songs.
+func (h *Hybrid) wrapSongs(s *source, songs []*models.Song) []*models.Song {
+	s.wrapSongs(songs)
+	h.recordCodecs(songs)
+	return songs
+}
+
+var losslessCodecs = []string{"flac", "alac", "wav", "ape", "wv", "aiff"}
+
+func isLossless(codec string) bool {
+	codec = strings.ToLower(codec)
+	for _, v := range losslessCodecs {
+		if v == codec {
+			return true
+		}
+	}
+	return false
+}
+
+// streamOrder sorts song parts by preference. Codecs contains codec for each part,
+// keyed by encoded id.
+func streamOrder(parts []sourceId, preference string, codecs map[models.Id]string) []sourceId {
+	ordered := make([]sourceId, len(parts))
+	copy(ordered, parts)
+	switch preference {
+	case config.StreamPreferSecondary:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].source > ordered[j].source
+		})
+	case config.StreamPreferLossless:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			losslessI := isLossless(codecs[encodeId(ordered[i].source, ordered[i].id)])
+			losslessJ := isLossless(codecs[encodeId(ordered[j].source, ordered[j].id)])
+			if losslessI != losslessJ {
+				return losslessI
+			}
+			return ordered[i].source < ordered[j].source
+		})
+	default:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].source < ordered[j].source
+		})
+	}
+	return ordered
+}
+
+// preferred returns parts of song in preferred streaming order.
+func (h *Hybrid) preferred(id models.Id) []sourceId {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return streamOrder(h.parts(id), h.preference, h.codecs)
+}
+
+func (h *Hybrid) stream(song *models.Song, download bool) (io.ReadCloser, interfaces.AudioFormat, error) {
+	parts := h.preferred(song.Id)
+	if len(parts) == 0 {
+		return nil, interfaces.AudioFormatNil, fmt.Errorf("invalid song id: %s", song.Id)
+	}
+
+	// fall back to next source if preferred source is unavailable
+	var err error
+	for _, v := range parts {
+		s := h.sources[v.source]
+		sourceSong := *song
+		sourceSong.Id = v.id
+
+		var reader io.ReadCloser
+		var format interfaces.AudioFormat
+		if download {
+			reader, format, err = s.server.Download(&sourceSong)
+		} else {
+			reader, format, err = s.server.Stream(&sourceSong)
+		}
+		if err == nil {
+			h.lock.Lock()
+			h.streamed[song.Id] = v.source
+			h.lock.Unlock()
+			logrus.Debugf("Stream song %s from %s", song.Name, s.name)
+			return reader, format, nil
+		}
+		logrus.Warningf("stream song from %s: %v", s.name, err)
+	}
+	return nil, interfaces.AudioFormatNil, err
+}
+
+func (h *Hybrid) Stream(Song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
+	return h.stream(Song, false)
+}
+
+func (h *Hybrid) Download(Song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
+	return h.stream(Song, true)
+}
+
+// GetChapters implements interfaces.ChapterProvider. Chapters are from preferred source that provides them.
+func (h *Hybrid) GetChapters(Song *models.Song) ([]models.Chapter, error) {
+	var firstErr error
+	for _, v := range h.preferred(Song.Id) {
+		provider, ok := h.sources[v.source].server.(interfaces.ChapterProvider)
+		if !ok {
+			continue
+		}
+		sourceSong := *Song
+		sourceSong.Id = v.id
+		chapters, err := provider.GetChapters(&sourceSong)
+		if err == nil {
+			return chapters, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = interfaces.ErrChaptersNotSupported
+	}
+	return nil, firstErr
+}
+
+// GetStreamUrl implements api.StreamLinker. Url is from preferred source that provides stream urls.
+func (h *Hybrid) GetStreamUrl(Song *models.Song) string {
+	for _, v := range h.preferred(Song.Id) {
+		linker, ok := h.sources[v.source].server.(api.StreamLinker)
+		if !ok {
+			continue
+		}
+		sourceSong := *Song
+		sourceSong.Id = v.id
+		if url := linker.GetStreamUrl(&sourceSong); url != "" {
+			return url
+		}
+	}
+	return ""
+}
+
+func (h *Hybrid) primary() *source {
+	return h.sources[0]
+}
+
+func (h *Hybrid) GetInfo() (*models.ServerInfo, error) {
+	info, err := h.primary().server.GetInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(h.sources))
+	for i, v := range h.sources {
+		names[i] = v.name
+	}
+	info.ServerType = strings.Join(names, " + ")
+	info.Id = h.GetId()
+	if info.Misc == nil {
+		info.Misc = map[string]string{}
+	}
+	for _, v := range h.sources[1:] {
+		other, err := v.server.GetInfo()
+		if err != nil {
+			info.Misc[v.name] = err.Error()
+		} else {
+			info.Misc[v.name] = strings.TrimSpace(other.Name + " " + other.Version)
+		}
+	}
+	return info, nil
+}
+
+// GetRequestStats implements api.RequestStatistics. Stats of all sources are summed.
+func (h *Hybrid) GetRequestStats() models.RequestStats {
+	stats := models.RequestStats{}
+	for _, v := range h.sources {
+		if server, ok := v.server.(api.RequestStatistics); ok {
+			stats.Add(server.GetRequestStats())
+		}
+	}
+	return stats
+}
+
+// ConnectionOk returns connection status of primary server. Failing secondary servers
+// are logged and skipped while browsing.
+func (h *Hybrid) ConnectionOk() error {
+	for _, v := range h.sources[1:] {
+		if err := v.server.ConnectionOk(); err != nil {
+			logrus.Warningf("no connection to %s: %v", v.name, err)
+		}
+	}
+	return h.primary().server.ConnectionOk()
+}
+
+// GetConfig returns primary server config. Config of secondary servers needs to be
+// saved separately.
+func (h *Hybrid) GetConfig() config.Backend {
+	return h.primary().server.GetConfig()
+}
+
+// ReportProgress reports progress to the server that song is being streamed from.
+func (h *Hybrid) ReportProgress(state *interfaces.ApiPlaybackState) error {
+	if state == nil {
+		return nil
+	}
+	h.lock.Lock()
+	streamed, ok := h.streamed[models.Id(state.ItemId)]
+	h.lock.Unlock()
+
+	return h.each("report progress", func(s *source) error {
+		if ok && s.index != streamed {
+			return nil
+		}
+		id, found := sourceIdFor(models.Id(state.ItemId), s.index)
+		if !found && state.ItemId != "" {
+			return nil
+		}
+
+		sourceState := *state
+		sourceState.ItemId = id.String()
+		sourceState.Queue = make([]models.Id, 0, len(state.Queue))
+		for _, v := range state.Queue {
+			if queueId, found := sourceIdFor(v, s.index); found {
+				sourceState.Queue = append(sourceState.Queue, queueId)
+			}
+		}
+		return s.server.ReportProgress(&sourceState)
+	})
+}
+
+// Scrobble implements api.Scrobbler for every server that song is found from and that supports scrobbling.
+// Error is returned only if song could not be scrobbled to any server, so that retrying does not
+// scrobble it twice to others.
+func (h *Hybrid) Scrobble(song models.Id, played time.Time) error {
+	var firstErr error
+	scrobbled := false
+	for _, s := range h.sources {
+		id, found := sourceIdFor(song, s.index)
+		scrobbler, ok := s.server.(api.Scrobbler)
+		if !found || !ok {
+			continue
+		}
+		err := scrobbler.Scrobble(id, played)
+		if err != nil {
+			logrus.Warningf("scrobble to %s: %v", s.name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else {
+			scrobbled = true
+		}
+	}
+	if scrobbled {
+		return nil
+	}
+	return firstErr
+}
+
+func (h *Hybrid) Start() error {
+	return h.each("start", func(s *source) error {
+		return s.server.Start()
+	})
+}
+
+func (h *Hybrid) Stop() error {
+	return h.each("stop", func(s *source) error {
+		return s.server.Stop()
+	})
+}
+
+func (h *Hybrid) GetId() string {
+	ids := ""
+	for _, v := range h.sources {
+		ids += v.server.GetId()
+	}
+	return fmt.Sprintf("%x", md5.Sum([]byte(ids)))
+}
+
+// AddConnectionCallback implements interfaces.ConnectionNotifier for primary server.
+func (h *Hybrid) AddConnectionCallback(cb func(online bool)) {
+	if notifier, ok := h.primary().server.(interfaces.ConnectionNotifier); ok {
+		notifier.AddConnectionCallback(cb)
+	}
+}
+
+// AddLibraryChangedCallback implements interfaces.LibraryChangeNotifier for every server.
+func (h *Hybrid) AddLibraryChangedCallback(cb func()) {
+	for _, v := range h.sources {
+		if notifier, ok := v.server.(interfaces.LibraryChangeNotifier); ok {
+			notifier.AddLibraryChangedCallback(cb)
+		}
+	}
+}
+
+// sourceItem is item with id of single server.
+type sourceItem struct {
+	models.Item
+	id models.Id
+}
+
+func (s sourceItem) GetId() models.Id {
+	return s.id
+}
+
+// SetFavorite implements interfaces.FavoriteController for every server that item is found from.
+func (h *Hybrid) SetFavorite(item models.Item, favorite bool) error {
+	return h.each("set favorite", func(s *source) error {
+		id, found := sourceIdFor(item.GetId(), s.index)
+		if !found {
+			return nil
+		}
+		controller, ok := s.server.(interfaces.FavoriteController)
+		if !ok {
+			return interfaces.ErrFavoritesNotSupported
+		}
+		return controller.SetFavorite(sourceItem{Item: item, id: id}, favorite)
+	})
+}
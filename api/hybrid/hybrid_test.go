@@ -0,0 +1,83 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package hybrid
+
+import (
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestStreamOrder(t *testing.T) {
+	parts := []sourceId{{source: 0, id: "a"}, {source: 1, id: "b"}}
+	codecs := map[models.Id]string{
+		"0:a": "mp3",
+		"1:b": "FLAC",
+	}
+
+	tests := []struct {
+		name       string
+		preference string
+		codecs     map[models.Id]string
+		want       []int
+	}{
+		{
+			name:       "default",
+			preference: "",
+			codecs:     codecs,
+			want:       []int{0, 1},
+		},
+		{
+			name:       "primary",
+			preference: config.StreamPreferPrimary,
+			codecs:     codecs,
+			want:       []int{0, 1},
+		},
+		{
+			name:       "secondary",
+			preference: config.StreamPreferSecondary,
+			codecs:     codecs,
+			want:       []int{1, 0},
+		},
+		{
+			name:       "lossless",
+			preference: config.StreamPreferLossless,
+			codecs:     codecs,
+			want:       []int{1, 0},
+		},
+		{
+			name:       "lossless, codecs unknown",
+			preference: config.StreamPreferLossless,
+			codecs:     map[models.Id]string{},
+			want:       []int{0, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered := streamOrder(parts, tt.preference, tt.codecs)
+			got := make([]int, len(ordered))
+			for i, v := range ordered {
+				got[i] = v.source
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("streamOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
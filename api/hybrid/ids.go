@@ -0,0 +1,249 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package hybrid
+
+import (
+	"strconv"
+	"strings"
+	"tryffel.net/go/jellycli/models"
+)
+
+// Item ids are prefixed with source index, e.g. '0:abc'. Items that are found from
+// multiple sources have ids of each source joined, e.g. '0:abc|1:def'.
+const (
+	sourceSeparator = ":"
+	idSeparator     = "|"
+)
+
+// sourceId is item id in single source.
+type sourceId struct {
+	source int
+	id     models.Id
+}
+
+// encodeId returns id for item in given source. Empty id is kept empty.
+func encodeId(source int, id models.Id) models.Id {
+	if id == "" {
+		return ""
+	}
+	return models.Id(strconv.Itoa(source) + sourceSeparator + id.String())
+}
+
+// decodeId returns ids of each source in id. Invalid parts are skipped.
+func decodeId(id models.Id) []sourceId {
+	if id == "" {
+		return nil
+	}
+	parts := strings.Split(id.String(), idSeparator)
+	ids := make([]sourceId, 0, len(parts))
+	for _, v := range parts {
+		i := strings.Index(v, sourceSeparator)
+		if i < 1 {
+			continue
+		}
+		source, err := strconv.Atoi(v[:i])
+		if err != nil {
+			continue
+		}
+		ids = append(ids, sourceId{source: source, id: models.Id(v[i+1:])})
+	}
+	return ids
+}
+
+// joinIds returns id that contains all unique source ids in given ids.
+func joinIds(ids ...models.Id) models.Id {
+	parts := make([]string, 0, len(ids))
+	found := map[string]bool{}
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		for _, v := range strings.Split(id.String(), idSeparator) {
+			if !found[v] {
+				found[v] = true
+				parts = append(parts, v)
+			}
+		}
+	}
+	return models.Id(strings.Join(parts, idSeparator))
+}
+
+// sourceIdFor returns item id in source, if item is found from source.
+func sourceIdFor(id models.Id, source int) (models.Id, bool) {
+	for _, v := range decodeId(id) {
+		if v.source == source {
+			return v.id, true
+		}
+	}
+	return "", false
+}
+
+func encodeIds(source int, ids []models.Id) []models.Id {
+	if ids == nil {
+		return nil
+	}
+	out := make([]models.Id, len(ids))
+	for i, v := range ids {
+		out[i] = encodeId(source, v)
+	}
+	return out
+}
+
+func encodeIdNames(source int, items []models.IdName) []models.IdName {
+	if items == nil {
+		return nil
+	}
+	out := make([]models.IdName, len(items))
+	for i, v := range items {
+		out[i] = models.IdName{Id: encodeId(source, v.Id), Name: v.Name}
+	}
+	return out
+}
+
+// wrapArtist converts artist from source to hybrid artist. Artist is modified in place.
+func (s *source) wrapArtist(artist *models.Artist) *models.Artist {
+	if artist == nil {
+		return nil
+	}
+	artist.Id = encodeId(s.index, artist.Id)
+	artist.Albums = encodeIds(s.index, artist.Albums)
+	artist.Sources = []string{s.name}
+	return artist
+}
+
+func (s *source) wrapAlbum(album *models.Album) *models.Album {
+	if album == nil {
+		return nil
+	}
+	album.Id = encodeId(s.index, album.Id)
+	album.Artist = encodeId(s.index, album.Artist)
+	album.AdditionalArtists = encodeIdNames(s.index, album.AdditionalArtists)
+	album.Songs = encodeIds(s.index, album.Songs)
+	album.Genres = encodeIdNames(s.index, album.Genres)
+	album.Sources = []string{s.name}
+	return album
+}
+
+func (s *source) wrapSong(song *models.Song) *models.Song {
+	if song == nil {
+		return nil
+	}
+	song.Id = encodeId(s.index, song.Id)
+	song.Album = encodeId(s.index, song.Album)
+	song.Artists = encodeIdNames(s.index, song.Artists)
+	song.AlbumArtist = encodeId(s.index, song.AlbumArtist)
+	song.Composers = encodeIdNames(s.index, song.Composers)
+	song.Sources = []string{s.name}
+	return song
+}
+
+func (s *source) wrapPlaylist(playlist *models.Playlist) *models.Playlist {
+	if playlist == nil {
+		return nil
+	}
+	playlist.Id = encodeId(s.index, playlist.Id)
+	for _, v := range playlist.Songs {
+		s.wrapSong(v)
+	}
+	return playlist
+}
+
+func (s *source) wrapArtists(artists []*models.Artist) []*models.Artist {
+	for _, v := range artists {
+		s.wrapArtist(v)
+	}
+	return artists
+}
+
+func (s *source) wrapAlbums(albums []*models.Album) []*models.Album {
+	for _, v := range albums {
+		s.wrapAlbum(v)
+	}
+	return albums
+}
+
+func (s *source) wrapSongs(songs []*models.Song) []*models.Song {
+	for _, v := range songs {
+		s.wrapSong(v)
+	}
+	return songs
+}
+
+func (s *source) wrapIdNames(items []*models.IdName) []*models.IdName {
+	for _, v := range items {
+		v.Id = encodeId(s.index, v.Id)
+	}
+	return items
+}
+
+// wrapItem converts search result or other generic item from source to hybrid item.
+func (s *source) wrapItem(item models.Item) models.Item {
+	switch v := item.(type) {
+	case *models.Artist:
+		return s.wrapArtist(v)
+	case *models.Album:
+		return s.wrapAlbum(v)
+	case *models.Song:
+		return s.wrapSong(v)
+	case *models.Playlist:
+		return s.wrapPlaylist(v)
+	case models.Playlist:
+		return *s.wrapPlaylist(&v)
+	case models.Genre:
+		v.Id = encodeId(s.index, v.Id)
+		return v
+	case *models.Genre:
+		v.Id = encodeId(s.index, v.Id)
+		return v
+	}
+	return item
+}
+
+// unwrapItem returns copy of item with id of given source. Only id is converted,
+// which is enough for passing item to source. Nil is returned for unknown items.
+func unwrapItem(item models.Item, id models.Id) models.Item {
+	switch v := item.(type) {
+	case *models.Artist:
+		artist := *v
+		artist.Id = id
+		return &artist
+	case *models.Album:
+		album := *v
+		album.Id = id
+		return &album
+	case *models.Song:
+		song := *v
+		song.Id = id
+		return &song
+	case *models.Playlist:
+		playlist := *v
+		playlist.Id = id
+		return &playlist
+	case models.Playlist:
+		v.Id = id
+		return v
+	case models.Genre:
+		v.Id = id
+		return v
+	case *models.Genre:
+		genre := *v
+		genre.Id = id
+		return &genre
+	}
+	return nil
+}
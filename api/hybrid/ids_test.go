@@ -0,0 +1,108 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package hybrid
+
+import (
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestDecodeId(t *testing.T) {
+	tests := []struct {
+		name string
+		id   models.Id
+		want []sourceId
+	}{
+		{
+			name: "empty",
+			id:   "",
+			want: nil,
+		},
+		{
+			name: "single source",
+			id:   "0:abc",
+			want: []sourceId{{source: 0, id: "abc"}},
+		},
+		{
+			name: "multiple sources",
+			id:   "0:abc|1:al-12",
+			want: []sourceId{{source: 0, id: "abc"}, {source: 1, id: "al-12"}},
+		},
+		{
+			name: "separator in id",
+			id:   "1:a:b",
+			want: []sourceId{{source: 1, id: "a:b"}},
+		},
+		{
+			name: "invalid parts",
+			id:   "abc|x:def|:ghi|1:jkl",
+			want: []sourceId{{source: 1, id: "jkl"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeId(tt.id); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeId() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinIds(t *testing.T) {
+	id := joinIds(encodeId(0, "abc"), "", encodeId(1, "def"), "0:abc")
+	if id != "0:abc|1:def" {
+		t.Errorf("joinIds() = %s, want 0:abc|1:def", id)
+	}
+
+	sourceId, ok := sourceIdFor(id, 1)
+	if !ok || sourceId != "def" {
+		t.Errorf("sourceIdFor() = %s, %t, want def, true", sourceId, ok)
+	}
+	if _, ok := sourceIdFor(id, 2); ok {
+		t.Errorf("sourceIdFor() found id for unknown source")
+	}
+}
+
+func TestSourceWrapSong(t *testing.T) {
+	s := &source{index: 1, name: "subsonic"}
+	song := s.wrapSong(&models.Song{
+		Id:          "song",
+		Album:       "album",
+		Artists:     []models.IdName{{Id: "artist", Name: "artist"}},
+		AlbumArtist: "",
+		Composers:   []models.IdName{{Name: "composer"}},
+	})
+
+	want := &models.Song{
+		Id:          "1:song",
+		Album:       "1:album",
+		Artists:     []models.IdName{{Id: "1:artist", Name: "artist"}},
+		AlbumArtist: "",
+		Composers:   []models.IdName{{Name: "composer"}},
+		Sources:     []string{"subsonic"},
+	}
+	if !reflect.DeepEqual(song, want) {
+		t.Errorf("wrapSong() = %v, want %v", song, want)
+	}
+
+	item := unwrapItem(song, "song")
+	if item.GetId() != "song" || song.Id != "1:song" {
+		t.Errorf("unwrapItem() modified original item or returned invalid id: %s", item.GetId())
+	}
+}
@@ -0,0 +1,222 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package hybrid
+
+import (
+	"strings"
+	"tryffel.net/go/jellycli/models"
+)
+
+// maxDurationDiff is max difference in seconds between songs that are considered same song.
+const maxDurationDiff = 2
+
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func appendSources(sources []string, added []string) []string {
+	for _, v := range added {
+		found := false
+		for _, s := range sources {
+			if s == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			sources = append(sources, v)
+		}
+	}
+	return sources
+}
+
+func artistMatches(a, b *models.Artist) bool {
+	return normalize(a.Name) == normalize(b.Name)
+}
+
+// mergeArtist merges b into a.
+func mergeArtist(a, b *models.Artist) {
+	a.Id = joinIds(a.Id, b.Id)
+	a.Albums = append(a.Albums, b.Albums...)
+	if b.AlbumCount > a.AlbumCount {
+		a.AlbumCount = b.AlbumCount
+	}
+	if b.TotalDuration > a.TotalDuration {
+		a.TotalDuration = b.TotalDuration
+	}
+	a.Favorite = a.Favorite || b.Favorite
+	a.Sources = appendSources(a.Sources, b.Sources)
+}
+
+// mergeArtists merges lists so that artists found from multiple lists are merged into one.
+// Order of artists is preserved, first list being first. Each list is expected to be from different source.
+func mergeArtists(lists ...[]*models.Artist) []*models.Artist {
+	var artists []*models.Artist
+	for _, list := range lists {
+		// only merge with items from previous lists
+		previous := len(artists)
+		for _, v := range list {
+			merged := false
+			for _, artist := range artists[:previous] {
+				if artistMatches(artist, v) {
+					mergeArtist(artist, v)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				artists = append(artists, v)
+			}
+		}
+	}
+	return artists
+}
+
+func albumArtistName(album *models.Album) string {
+	if len(album.AdditionalArtists) == 0 {
+		return ""
+	}
+	return normalize(album.AdditionalArtists[0].Name)
+}
+
+// albumMatches returns true if albums have same name and artist. If artist is unknown,
+// only name is compared.
+func albumMatches(a, b *models.Album) bool {
+	if normalize(a.Name) != normalize(b.Name) {
+		return false
+	}
+	artistA := albumArtistName(a)
+	artistB := albumArtistName(b)
+	return artistA == "" || artistB == "" || artistA == artistB
+}
+
+// mergeAlbum merges b into a.
+func mergeAlbum(a, b *models.Album) {
+	a.Id = joinIds(a.Id, b.Id)
+	a.Artist = joinIds(a.Artist, b.Artist)
+	if len(a.AdditionalArtists) == 0 {
+		a.AdditionalArtists = b.AdditionalArtists
+	}
+	if b.SongCount > a.SongCount {
+		a.SongCount = b.SongCount
+	}
+	if a.Year == 0 {
+		a.Year = b.Year
+	}
+	if a.Overview == "" {
+		a.Overview = b.Overview
+	}
+	if len(a.Genres) == 0 {
+		a.Genres = b.Genres
+	}
+	a.Favorite = a.Favorite || b.Favorite
+	a.Sources = appendSources(a.Sources, b.Sources)
+}
+
+// mergeAlbums merges lists so that albums found from multiple lists are merged into one.
+func mergeAlbums(lists ...[]*models.Album) []*models.Album {
+	var albums []*models.Album
+	for _, list := range lists {
+		// only merge with items from previous lists
+		previous := len(albums)
+		for _, v := range list {
+			merged := false
+			for _, album := range albums[:previous] {
+				if albumMatches(album, v) {
+					mergeAlbum(album, v)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				albums = append(albums, v)
+			}
+		}
+	}
+	return albums
+}
+
+// songMatches returns true if songs have same name, track number and roughly same duration.
+func songMatches(a, b *models.Song) bool {
+	if normalize(a.Name) != normalize(b.Name) || a.Index != b.Index {
+		return false
+	}
+	diff := a.Duration - b.Duration
+	return diff <= maxDurationDiff && diff >= -maxDurationDiff
+}
+
+// mergeSong merges b into a.
+func mergeSong(a, b *models.Song) {
+	a.Id = joinIds(a.Id, b.Id)
+	a.Album = joinIds(a.Album, b.Album)
+	a.AlbumArtist = joinIds(a.AlbumArtist, b.AlbumArtist)
+	if len(a.Artists) == 0 {
+		a.Artists = b.Artists
+	}
+	if len(a.Composers) == 0 {
+		a.Composers = b.Composers
+	}
+	a.Favorite = a.Favorite || b.Favorite
+	a.Sources = appendSources(a.Sources, b.Sources)
+}
+
+// mergeSongs merges lists so that songs found from multiple lists are merged into one.
+func mergeSongs(lists ...[]*models.Song) []*models.Song {
+	var songs []*models.Song
+	for _, list := range lists {
+		// only merge with items from previous lists
+		previous := len(songs)
+		for _, v := range list {
+			merged := false
+			for _, song := range songs[:previous] {
+				if songMatches(song, v) {
+					mergeSong(song, v)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				songs = append(songs, v)
+			}
+		}
+	}
+	return songs
+}
+
+// mergeIdNames merges lists of e.g. genres by name.
+func mergeIdNames(lists ...[]*models.IdName) []*models.IdName {
+	var items []*models.IdName
+	for _, list := range lists {
+		// only merge with items from previous lists
+		previous := len(items)
+		for _, v := range list {
+			merged := false
+			for _, item := range items[:previous] {
+				if normalize(item.Name) == normalize(v.Name) {
+					item.Id = joinIds(item.Id, v.Id)
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				items = append(items, v)
+			}
+		}
+	}
+	return items
+}
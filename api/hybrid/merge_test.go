@@ -0,0 +1,103 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package hybrid
+
+import (
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestMergeArtists(t *testing.T) {
+	primary := []*models.Artist{
+		{Id: "0:a", Name: "Artist A", AlbumCount: 2, Sources: []string{"jellyfin"}},
+		{Id: "0:b", Name: "Artist B", AlbumCount: 1, Sources: []string{"jellyfin"}},
+	}
+	secondary := []*models.Artist{
+		{Id: "1:c", Name: "artist a ", AlbumCount: 3, Favorite: true, Sources: []string{"subsonic"}},
+		{Id: "1:d", Name: "Artist D", AlbumCount: 1, Sources: []string{"subsonic"}},
+	}
+
+	want := []*models.Artist{
+		{Id: "0:a|1:c", Name: "Artist A", AlbumCount: 3, Favorite: true, Sources: []string{"jellyfin", "subsonic"}},
+		{Id: "0:b", Name: "Artist B", AlbumCount: 1, Sources: []string{"jellyfin"}},
+		{Id: "1:d", Name: "Artist D", AlbumCount: 1, Sources: []string{"subsonic"}},
+	}
+
+	if got := mergeArtists(primary, secondary); !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeArtists() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeAlbums(t *testing.T) {
+	primary := []*models.Album{
+		{Id: "0:a", Name: "Album", AdditionalArtists: []models.IdName{{Id: "0:x", Name: "Artist X"}}},
+		{Id: "0:b", Name: "Album", AdditionalArtists: []models.IdName{{Id: "0:y", Name: "Artist Y"}}},
+	}
+	secondary := []*models.Album{
+		{Id: "1:c", Name: "Album", AdditionalArtists: []models.IdName{{Id: "1:y", Name: "artist y"}}},
+		{Id: "1:d", Name: "Album"},
+	}
+
+	got := mergeAlbums(primary, secondary)
+	if len(got) != 2 {
+		t.Fatalf("mergeAlbums() returned %d albums, want 2", len(got))
+	}
+	// album with unknown artist merges to first album with same name
+	if got[0].Id != "0:a|1:d" {
+		t.Errorf("mergeAlbums() first id = %s, want 0:a|1:d", got[0].Id)
+	}
+	if got[1].Id != "0:b|1:c" {
+		t.Errorf("mergeAlbums() second id = %s, want 0:b|1:c", got[1].Id)
+	}
+}
+
+func TestMergeSongs(t *testing.T) {
+	primary := []*models.Song{
+		{Id: "0:a", Name: "Song", Index: 1, Duration: 180},
+		{Id: "0:b", Name: "Song", Index: 1, Duration: 181},
+	}
+	secondary := []*models.Song{
+		{Id: "1:c", Name: "song", Index: 1, Duration: 182},
+		{Id: "1:d", Name: "Song", Index: 2, Duration: 180},
+		{Id: "1:e", Name: "Song", Index: 1, Duration: 200},
+	}
+
+	got := mergeSongs(primary, secondary)
+	ids := make([]models.Id, len(got))
+	for i, v := range got {
+		ids[i] = v.Id
+	}
+
+	// songs from same list are never merged
+	want := []models.Id{"0:a|1:c", "0:b", "1:d", "1:e"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("mergeSongs() = %v, want %v", ids, want)
+	}
+}
+
+func TestMergeIdNames(t *testing.T) {
+	got := mergeIdNames(
+		[]*models.IdName{{Id: "0:rock", Name: "Rock"}},
+		[]*models.IdName{{Id: "1:1", Name: "rock"}, {Id: "1:2", Name: "Jazz"}},
+	)
+	want := []*models.IdName{{Id: "0:rock|1:1", Name: "Rock"}, {Id: "1:2", Name: "Jazz"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeIdNames() = %v, want %v", got, want)
+	}
+}
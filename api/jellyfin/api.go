@@ -21,6 +21,7 @@
 package jellyfin
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -31,10 +32,12 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"tryffel.net/go/jellycli/api"
 	"tryffel.net/go/jellycli/config"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
@@ -61,8 +64,12 @@ type Jellyfin struct {
 	DeviceId  string
 	SessionId string
 	client    *http.Client
+	// requests makes api requests, client is used directly for streams.
+	requests  *api.Client
 	loggedIn  bool
 	musicView string
+	// podcastView is id or name of podcast library, set in config.
+	podcastView string
 
 	player interfaces.Player
 	queue  interfaces.QueueController
@@ -72,6 +79,21 @@ type Jellyfin struct {
 	socketState socketState
 
 	remoteControlEnabled bool
+
+	// statusLock guards status and statusAt, which contain latest player status.
+	statusLock sync.Mutex
+	status     interfaces.AudioStatus
+	statusAt   time.Time
+
+	syncPlay syncPlay
+
+	connection *api.ConnectionMonitor
+
+	// playSessions contain play session for each song that has been streamed
+	playSessionLock sync.Mutex
+	playSessions    map[models.Id]playSession
+
+	library libraryEvents
 }
 
 func (jf *Jellyfin) AuthOk() error {
@@ -82,6 +104,11 @@ func (jf *Jellyfin) GetId() string {
 	return jf.serverId
 }
 
+// NewOffline returns offline server that shares id with Jellyfin server in conf.
+func NewOffline(conf *config.Jellyfin) *api.Offline {
+	return api.NewOffline(conf.ServerId, conf)
+}
+
 func (jf *Jellyfin) GetInfo() (*models.ServerInfo, error) {
 	info := &models.ServerInfo{
 		ServerType: "Jellyfin",
@@ -138,30 +165,9 @@ func (jf *Jellyfin) RemoteControlEnabled() error {
 }
 
 func NewJellyfin(conf *config.Jellyfin, provider config.KeyValueProvider) (*Jellyfin, error) {
-	jf := &Jellyfin{
-		client: &http.Client{},
-	}
-
-	if conf != nil {
-		jf.host = conf.Url
-		jf.token = conf.Token
-		jf.userId = conf.UserId
-		jf.serverId = conf.ServerId
-		jf.musicView = conf.MusicView
-	}
-
-	id, err := machineid.ProtectedID(config.AppName)
+	jf, err := newJellyfin(conf)
 	if err != nil {
-		return jf, fmt.Errorf("failed to get unique host id: %v", err)
-	}
-	jf.DeviceId = id
-	jf.SessionId = util.RandomKey(15)
-	jf.Name = "api"
-	jf.SetLoop(jf.loop)
-
-	jf.cache, err = NewCache()
-	if err != nil {
-		return jf, fmt.Errorf("create cache: %v", err)
+		return jf, err
 	}
 
 	if jf.host == "" {
@@ -219,12 +225,95 @@ func NewJellyfin(conf *config.Jellyfin, provider config.KeyValueProvider) (*Jell
 		return jf, err
 	}
 
+	jf.usePersistentCache()
 	return jf, err
 }
 
+// newJellyfin creates client without connecting to server.
+func newJellyfin(conf *config.Jellyfin) (*Jellyfin, error) {
+	jf := &Jellyfin{}
+	client, err := api.NewHttpClient(0)
+	if err != nil {
+		return jf, err
+	}
+	jf.client = client
+	jf.requests = api.NewClient(client)
+	jf.connection = api.NewConnectionMonitor(jf.reconnect)
+
+	if conf != nil {
+		jf.host = conf.Url
+		jf.token = conf.Token
+		jf.userId = conf.UserId
+		jf.serverId = conf.ServerId
+		jf.musicView = conf.MusicView
+		jf.podcastView = conf.PodcastView
+	}
+
+	id, err := machineid.ProtectedID(config.AppName)
+	if err != nil {
+		return jf, fmt.Errorf("failed to get unique host id: %v", err)
+	}
+	jf.DeviceId = id
+	jf.SessionId = util.RandomKey(15)
+	jf.Name = "api"
+	jf.SetLoop(jf.loop)
+
+	jf.cache, err = NewCache()
+	if err != nil {
+		return jf, fmt.Errorf("create cache: %v", err)
+	}
+	jf.requests.SetResponseCache(jf.cache)
+	return jf, nil
+}
+
+// usePersistentCache replaces in-memory cache with one stored to local cache dir, so that
+// items need not be fetched again after restart. Each server and user has separate cache.
+func (jf *Jellyfin) usePersistentCache() {
+	if config.AppConfig == nil || jf.serverId == "" || jf.userId == "" {
+		return
+	}
+	player := config.AppConfig.Player
+	file := path.Join(player.LocalCacheDir, fmt.Sprintf("jellyfin-%s-%s.cache", jf.serverId, jf.userId))
+	c, err := NewPersistentCache(file, time.Duration(player.MetadataCacheTtlMin)*time.Minute)
+	if err != nil {
+		logrus.Warningf("load metadata cache: %v", err)
+	}
+	jf.cache = c
+	jf.requests.SetResponseCache(c)
+}
+
+// GetRequestStats implements api.RequestStatistics.
+func (jf *Jellyfin) GetRequestStats() models.RequestStats {
+	return jf.requests.Stats()
+}
+
 func (jf *Jellyfin) SetPlayer(p interfaces.Player) {
 	jf.remoteControlEnabled = true
 	jf.player = p
+	jf.player.AddStatusCallback(jf.playerStatusChanged)
+}
+
+func (jf *Jellyfin) playerStatusChanged(status interfaces.AudioStatus) {
+	jf.statusLock.Lock()
+	jf.status = status
+	jf.statusAt = time.Now()
+	jf.statusLock.Unlock()
+	jf.syncPlayStatusChanged(status)
+}
+
+// playerStatus returns latest player status and estimated position in current song.
+func (jf *Jellyfin) playerStatus() (interfaces.AudioStatus, interfaces.AudioTick) {
+	jf.statusLock.Lock()
+	defer jf.statusLock.Unlock()
+	return jf.status, estimatePosition(jf.status, time.Since(jf.statusAt))
+}
+
+// estimatePosition returns song position, given the status was received elapsed time ago.
+func estimatePosition(status interfaces.AudioStatus, elapsed time.Duration) interfaces.AudioTick {
+	if status.State != interfaces.AudioStatePlaying || status.Paused {
+		return status.SongPast
+	}
+	return status.SongPast + interfaces.AudioTick(elapsed.Milliseconds())
 }
 
 func (jf *Jellyfin) SetQueue(q interfaces.QueueController) {
@@ -256,7 +345,7 @@ func (jf *Jellyfin) TokenOk() error {
 	}
 
 	// check token validity
-	body, err := jf.get("/System/Info", nil)
+	body, err := jf.get(context.Background(), "/System/Info", nil)
 	if body != nil {
 		defer body.Close()
 	}
@@ -286,6 +375,42 @@ func (jf *Jellyfin) SetDefaultMusicview(id string) {
 	jf.musicView = id
 }
 
+// musicViewAll is a music view that uses all music libraries.
+const musicViewAll = "all"
+
+// GetLibraries implements interfaces.LibraryController.
+func (jf *Jellyfin) GetLibraries() ([]*models.View, error) {
+	views, err := jf.GetViews()
+	if err != nil {
+		return nil, err
+	}
+	libraries := make([]*models.View, 0, len(views))
+	for _, v := range views {
+		if v.CollectionType == "music" {
+			libraries = append(libraries, v)
+		}
+	}
+	return libraries, nil
+}
+
+// GetLibrary implements interfaces.LibraryController.
+func (jf *Jellyfin) GetLibrary() models.Id {
+	if jf.musicView == musicViewAll {
+		return ""
+	}
+	return models.Id(jf.musicView)
+}
+
+// SetLibrary implements interfaces.LibraryController.
+func (jf *Jellyfin) SetLibrary(id models.Id) error {
+	if id == "" {
+		jf.musicView = musicViewAll
+	} else {
+		jf.musicView = id.String()
+	}
+	return nil
+}
+
 func (jf *Jellyfin) selectDefaultMusicView(provider config.KeyValueProvider) error {
 	if jf.musicView != "" {
 		return nil
@@ -305,7 +430,8 @@ func (jf *Jellyfin) selectDefaultMusicView(provider config.KeyValueProvider) err
 
 	// Loop for as long as user gives valid input for default view
 	for {
-		number, err := provider.Get("jellyfin.music_view", false, "Default music view (enter number)")
+		number, err := provider.Get("jellyfin.music_view", false,
+			"Default music view (enter number, 0 for all music libraries)")
 		if err != nil {
 			fmt.Println("Must be a valid number")
 		} else {
@@ -314,7 +440,10 @@ func (jf *Jellyfin) selectDefaultMusicView(provider config.KeyValueProvider) err
 				fmt.Println("Must be a valid number")
 			} else {
 				id := ""
-				if num < len(views)+1 && num > 0 {
+				if num == 0 {
+					jf.musicView = musicViewAll
+					return nil
+				} else if num < len(views)+1 && num > 0 {
 					id = views[num-1].Id.String()
 					jf.musicView = id
 					if err != nil {
@@ -330,7 +459,7 @@ func (jf *Jellyfin) selectDefaultMusicView(provider config.KeyValueProvider) err
 }
 
 func (jf *Jellyfin) ping() error {
-	body, err := jf.get("/System/Info/Public", nil)
+	body, err := jf.get(context.Background(), "/System/Info/Public", nil)
 	if err != nil {
 		return err
 	}
@@ -385,6 +514,15 @@ func (jf *Jellyfin) Start() error {
 	return jf.Task.Start()
 }
 
+// Stop saves metadata cache and stops background task.
+func (jf *Jellyfin) Stop() error {
+	err := jf.cache.Save()
+	if err != nil {
+		logrus.Errorf("save metadata cache: %v", err)
+	}
+	return jf.Task.Stop()
+}
+
 func (jf *Jellyfin) loop() {
 	if jf.socket == nil {
 		return
@@ -20,6 +20,7 @@ package jellyfin
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -73,10 +74,7 @@ func (jf *Jellyfin) login(username, password string) error {
 			return fmt.Errorf("invalid login response: %v", err)
 		}
 
-		jf.token = dto.Token
-		jf.serverId = dto.ServerId
-		jf.userId = dto.User.UserId
-		jf.loggedIn = true
+		jf.loginOk(&dto)
 		break
 	case http.StatusBadRequest:
 		reason, err := ioutil.ReadAll(resp.Body)
@@ -96,13 +94,43 @@ func (jf *Jellyfin) login(username, password string) error {
 	return nil
 }
 
+// quickConnectLogin logs in with authorized quick connect secret.
+func (jf *Jellyfin) quickConnectLogin(secret string) error {
+	body, err := json.Marshal(map[string]string{"Secret": secret})
+	if err != nil {
+		return fmt.Errorf("encode quick connect secret: %v", err)
+	}
+	headers := map[string]string{"X-Emby-Authorization": jf.authHeader()}
+	resp, err := jf.makeRequest(context.Background(), "POST", "/Users/AuthenticateWithQuickConnect", &body, nil, headers)
+	if err != nil {
+		return fmt.Errorf("quick connect login: %v", err)
+	}
+	defer resp.Body.Close()
+
+	dto := loginResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&dto)
+	if err != nil {
+		return fmt.Errorf("invalid login response: %v", err)
+	}
+	jf.loginOk(&dto)
+	return nil
+}
+
+func (jf *Jellyfin) loginOk(dto *loginResponse) {
+	jf.token = dto.Token
+	jf.serverId = dto.ServerId
+	jf.userId = dto.User.UserId
+	jf.loggedIn = true
+}
+
 func (jf *Jellyfin) GetConfig() config.Backend {
 	return &config.Jellyfin{
-		Url:       jf.host,
-		Token:     jf.token,
-		UserId:    jf.userId,
-		DeviceId:  jf.DeviceId,
-		ServerId:  jf.ServerId(),
-		MusicView: jf.musicView,
+		Url:         jf.host,
+		Token:       jf.token,
+		UserId:      jf.userId,
+		DeviceId:    jf.DeviceId,
+		ServerId:    jf.ServerId(),
+		MusicView:   jf.musicView,
+		PodcastView: jf.podcastView,
 	}
 }
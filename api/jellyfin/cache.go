@@ -19,25 +19,129 @@
 package jellyfin
 
 import (
+	"encoding/gob"
+	"errors"
 	"fmt"
 	"github.com/patrickmn/go-cache"
 	"github.com/sirupsen/logrus"
+	"os"
+	"path"
 	"time"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/metrics"
 	"tryffel.net/go/jellycli/models"
 )
 
+// cacheFileVersion is increased whenever stored types change, older files are discarded.
+const cacheFileVersion = 1
+
+// responseTimeout is how long http responses are kept for revalidating with ETag.
+const responseTimeout = time.Hour * 24 * 7
+
+func init() {
+	// register every type that is stored to cache, so that they can be saved to disk
+	gob.Register(&models.Artist{})
+	gob.Register(&models.Album{})
+	gob.Register(&models.Song{})
+	gob.Register(&models.Playlist{})
+	gob.Register([]models.Id{})
+	gob.Register(&response{})
+}
+
 type Cache struct {
 	cache *cache.Cache
+	ttl   time.Duration
+	// file is where cache is persisted, empty for in-memory cache.
+	file string
+}
+
+// response is http response body that can be revalidated with its ETag.
+type response struct {
+	ETag string
+	Body []byte
+}
+
+// cacheFile is the on-disk format of Cache.
+type cacheFile struct {
+	Version int
+	Items   map[string]cache.Item
 }
 
 //NewCache creates new cache that's ready to use.
 func NewCache() (*Cache, error) {
-	c := &Cache{}
-	c.cache = cache.New(config.CacheTimeout, config.CacheTimeout*2)
+	c := &Cache{ttl: config.CacheTimeout}
+	c.cache = cache.New(c.ttl, c.ttl*2)
+	return c, nil
+}
+
+// NewPersistentCache creates cache that is loaded from file, if it exists. Call Save to store cache to file.
+// Items expire after ttl, also when stored on disk.
+func NewPersistentCache(file string, ttl time.Duration) (*Cache, error) {
+	c := &Cache{ttl: ttl, file: file}
+	c.cache = cache.New(c.ttl, c.ttl*2)
+
+	fd, err := os.Open(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return c, nil
+		}
+		return c, fmt.Errorf("open cache file: %v", err)
+	}
+	defer fd.Close()
+
+	data := &cacheFile{}
+	err = gob.NewDecoder(fd).Decode(data)
+	if err != nil {
+		return c, fmt.Errorf("decode cache file: %v", err)
+	}
+	if data.Version != cacheFileVersion {
+		logrus.Infof("discard cache file with version %d", data.Version)
+		return c, nil
+	}
+
+	items := make(map[string]cache.Item, len(data.Items))
+	for k, v := range data.Items {
+		if !v.Expired() {
+			items[k] = v
+		}
+	}
+	c.cache = cache.NewFrom(c.ttl, c.ttl*2, items)
+	logrus.Debugf("loaded %d items from cache file %s", len(items), file)
 	return c, nil
 }
 
+// Save stores unexpired items to cache file. In-memory cache is not saved.
+func (c *Cache) Save() error {
+	if c.file == "" {
+		return nil
+	}
+	err := os.MkdirAll(path.Dir(c.file), 0760)
+	if err != nil {
+		return fmt.Errorf("create cache dir: %v", err)
+	}
+
+	// write to temporary file first to not corrupt existing cache
+	tmpFile := c.file + ".tmp"
+	fd, err := os.OpenFile(tmpFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0660)
+	if err != nil {
+		return fmt.Errorf("open cache file: %v", err)
+	}
+
+	data := &cacheFile{
+		Version: cacheFileVersion,
+		Items:   c.cache.Items(),
+	}
+	err = gob.NewEncoder(fd).Encode(data)
+	closeErr := fd.Close()
+	if err != nil {
+		return fmt.Errorf("encode cache: %v", err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("close cache file: %v", closeErr)
+	}
+	return os.Rename(tmpFile, c.file)
+}
+
 //Count returns total count of stored items
 func (c *Cache) Count() int {
 	return c.cache.ItemCount()
@@ -47,7 +151,7 @@ func (c *Cache) Count() int {
 func (c *Cache) Put(id models.Id, item models.Item, expire bool) {
 	var timeout time.Duration
 	if expire {
-		timeout = config.CacheTimeout
+		timeout = c.ttl
 	} else {
 		timeout = cache.NoExpiration
 	}
@@ -58,14 +162,17 @@ func (c *Cache) Put(id models.Id, item models.Item, expire bool) {
 func (c *Cache) Get(id models.Id) (models.Item, bool) {
 	data, found := c.cache.Get(string(id))
 	if !found {
+		metrics.ItemCache.Lookup(false)
 		return nil, false
 	}
 	item, ok := data.(models.Item)
 	if !ok {
 		logrus.Errorf("Cached item not models.Item: %v", data)
 		c.Delete(id)
+		metrics.ItemCache.Lookup(false)
 		return nil, false
 	}
+	metrics.ItemCache.Lookup(true)
 	return item, true
 }
 
@@ -74,6 +181,11 @@ func (c *Cache) Delete(id models.Id) {
 	c.cache.Delete(string(id))
 }
 
+//Flush deletes all items.
+func (c *Cache) Flush() {
+	c.cache.Flush()
+}
+
 //PutBatch put's multiple items with expiration. Each item must have a valid id
 //or operation fails returning error.
 func (c *Cache) PutBatch(items []models.Item, expire bool) error {
@@ -113,7 +225,7 @@ func (c *Cache) GetBatch(ids []models.Id) ([]models.Item, bool) {
 
 //PutList puts a list of ids under key
 func (c *Cache) PutList(id string, data []models.Id) {
-	c.cache.Set(id, data, config.CacheTimeout)
+	c.cache.Set(id, data, c.ttl)
 }
 
 //GetList gets list of Ids with given id
@@ -173,3 +285,22 @@ func (c *Cache) GetPlaylist(id models.Id) *models.Playlist {
 	}
 	return nil
 }
+
+// PutResponse stores http response body with its ETag, keyed by request. Implements api.ResponseCache.
+func (c *Cache) PutResponse(key string, etag string, body []byte) {
+	c.cache.Set("response:"+key, &response{ETag: etag, Body: body}, responseTimeout)
+}
+
+// GetResponse returns http response for request, if there is one. Implements api.ResponseCache.
+func (c *Cache) GetResponse(key string) (string, []byte, bool) {
+	data, found := c.cache.Get("response:" + key)
+	if !found {
+		return "", nil, false
+	}
+	resp, ok := data.(*response)
+	if !ok {
+		c.cache.Delete("response:" + key)
+		return "", nil, false
+	}
+	return resp.ETag, resp.Body, true
+}
@@ -19,8 +19,10 @@
 package jellyfin
 
 import (
+	"path"
 	"reflect"
 	"testing"
+	"time"
 	"tryffel.net/go/jellycli/models"
 )
 
@@ -267,3 +269,70 @@ func TestCache_GetBatch(t *testing.T) {
 		})
 	}
 }
+
+func TestPersistentCache_SaveLoad(t *testing.T) {
+	file := path.Join(t.TempDir(), "cache", "jellyfin.cache")
+
+	c, err := NewPersistentCache(file, time.Hour)
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+	items := cacheTestData()
+	if err = c.PutBatch(items, true); err != nil {
+		t.Fatalf("put items: %v", err)
+	}
+	c.PutList("latest_music", []models.Id{"a1"})
+	c.PutResponse("http://localhost/Items", "etag-1", []byte("[]"))
+	if err = c.Save(); err != nil {
+		t.Fatalf("save cache: %v", err)
+	}
+
+	loaded, err := NewPersistentCache(file, time.Hour)
+	if err != nil {
+		t.Fatalf("load cache: %v", err)
+	}
+	if loaded.Count() != c.Count() {
+		t.Errorf("loaded cache count: %d, want %d", loaded.Count(), c.Count())
+	}
+	for _, v := range items {
+		got, found := loaded.Get(v.GetId())
+		if !found {
+			t.Errorf("item %s not found from loaded cache", v.GetId())
+			continue
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Errorf("loaded item differs: want: %v, got: %v", v, got)
+		}
+	}
+	if ids, found := loaded.GetList("latest_music"); !found || len(ids) != 1 {
+		t.Errorf("loaded list: %v, found: %t", ids, found)
+	}
+	etag, body, found := loaded.GetResponse("http://localhost/Items")
+	if !found || etag != "etag-1" || string(body) != "[]" {
+		t.Errorf("loaded response: %s, %s, found: %t", etag, body, found)
+	}
+}
+
+func TestPersistentCache_Expired(t *testing.T) {
+	file := path.Join(t.TempDir(), "jellyfin.cache")
+
+	c, err := NewPersistentCache(file, time.Millisecond)
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+	if err = c.PutBatch(cacheTestData(), true); err != nil {
+		t.Fatalf("put items: %v", err)
+	}
+	if err = c.Save(); err != nil {
+		t.Fatalf("save cache: %v", err)
+	}
+	time.Sleep(time.Millisecond * 5)
+
+	loaded, err := NewPersistentCache(file, time.Millisecond)
+	if err != nil {
+		t.Fatalf("load cache: %v", err)
+	}
+	if loaded.Count() != 0 {
+		t.Errorf("expired items were loaded: %d", loaded.Count())
+	}
+}
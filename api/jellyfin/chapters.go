@@ -0,0 +1,75 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"tryffel.net/go/jellycli/models"
+)
+
+// chapter is chapter of item, e.g. extracted from embedded metadata of audio file.
+type chapter struct {
+	Name               string `json:"Name"`
+	StartPositionTicks int64  `json:"StartPositionTicks"`
+}
+
+// GetChapters implements interfaces.ChapterProvider.
+func (jf *Jellyfin) GetChapters(song *models.Song) ([]models.Chapter, error) {
+	params := jf.defaultParams()
+	(*params)["Fields"] = "Chapters"
+	resp, err := jf.get(context.Background(), fmt.Sprintf("/Users/%s/Items/%s", jf.userId, song.Id), params)
+	if resp != nil {
+		defer resp.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get chapters: %v", err)
+	}
+
+	dto := struct {
+		Chapters []chapter `json:"Chapters"`
+	}{}
+	err = json.NewDecoder(resp).Decode(&dto)
+	if err != nil {
+		return nil, fmt.Errorf("decode json: %v", err)
+	}
+	return toChapters(dto.Chapters), nil
+}
+
+// toChapters converts chapters ordered by start. Unnamed chapters are numbered.
+func toChapters(dtos []chapter) []models.Chapter {
+	chapters := make([]models.Chapter, len(dtos))
+	for i, v := range dtos {
+		chapters[i] = models.Chapter{
+			Name:  v.Name,
+			Start: int(v.StartPositionTicks / ticksToMillisecond),
+		}
+	}
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return chapters[i].Start < chapters[j].Start
+	})
+	for i := range chapters {
+		if chapters[i].Name == "" {
+			chapters[i].Name = fmt.Sprintf("Chapter %d", i+1)
+		}
+	}
+	return chapters
+}
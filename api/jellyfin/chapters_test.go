@@ -0,0 +1,44 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_toChapters(t *testing.T) {
+	dtos := []chapter{
+		{Name: "Outro", StartPositionTicks: 30 * ticksToSecond},
+		{Name: "Intro", StartPositionTicks: 0},
+		{Name: "", StartPositionTicks: 12345 * ticksToMillisecond},
+	}
+	want := []models.Chapter{
+		{Name: "Intro", Start: 0},
+		{Name: "Chapter 2", Start: 12345},
+		{Name: "Outro", Start: 30000},
+	}
+	if got := toChapters(dtos); !reflect.DeepEqual(got, want) {
+		t.Errorf("toChapters() = %v, want %v", got, want)
+	}
+	if got := toChapters(nil); len(got) != 0 {
+		t.Errorf("toChapters(nil) = %v, want empty", got)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strings"
+)
+
+// AddConnectionCallback implements interfaces.ConnectionNotifier.
+func (jf *Jellyfin) AddConnectionCallback(cb func(online bool)) {
+	jf.connection.AddConnectionCallback(cb)
+}
+
+// reconnect checks whether server is reachable again and restores session. Server might have been
+// restarted meanwhile, so token is verified and capabilities are reported again.
+// Websocket is reconnected by main loop.
+func (jf *Jellyfin) reconnect() error {
+	err := jf.ping()
+	if err != nil {
+		return err
+	}
+	err = jf.TokenOk()
+	if err != nil {
+		if !strings.Contains(err.Error(), "invalid token") {
+			return err
+		}
+		// user has to login again, which is only possible on startup
+		logrus.Errorf("Server connection restored but token is no longer valid, restart jellycli to login")
+		return nil
+	}
+	err = jf.ReportCapabilities()
+	if err != nil {
+		return fmt.Errorf("report capabilities: %v", err)
+	}
+	return nil
+}
@@ -28,15 +28,36 @@ import (
 	"tryffel.net/go/jellycli/util"
 )
 
+// Download streams original file.
 func (jf *Jellyfin) Download(song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
-	return jf.Stream(song)
+	return jf.stream(song, 0)
 }
 
-func (jf *Jellyfin) Stream(song *models.Song) (rc io.ReadCloser, format interfaces.AudioFormat, err error) {
-	format = interfaces.AudioFormatNil
+// Stream streams song, transcoded if it exceeds configured max bitrate.
+func (jf *Jellyfin) Stream(song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
+	return jf.stream(song, api.MaxBitrate())
+}
+
+// GetStreamUrl implements api.StreamLinker.
+func (jf *Jellyfin) GetStreamUrl(song *models.Song) string {
+	params := jf.streamParams(api.MaxBitrate())
+	params.ptr()["api_key"] = jf.token
+	return api.EncodeUrl(jf.streamUrl(song), *params)
+}
+
+func (jf *Jellyfin) streamUrl(song *models.Song) string {
+	return jf.host + "/Audio/" + song.Id.String() + "/universal"
+}
+
+// streamParams returns parameters for streaming song in supported format.
+// If maxBitrate (kbps) is set, server transcodes songs with higher bitrate.
+func (jf *Jellyfin) streamParams(maxBitrate int) *params {
 	params := jf.defaultParams()
 	ptr := params.ptr()
 	ptr["MaxStreamingBitrate"] = "140000000"
+	if maxBitrate > 0 {
+		ptr["MaxStreamingBitrate"] = fmt.Sprint(maxBitrate * 1000)
+	}
 	ptr["AudioSamplingRate"] = fmt.Sprint(config.AudioSamplingRate)
 	formats := ""
 	for i, v := range interfaces.SupportedAudioFormats {
@@ -46,13 +67,30 @@ func (jf *Jellyfin) Stream(song *models.Song) (rc io.ReadCloser, format interfac
 		formats += v.String()
 	}
 	ptr["Container"] = formats
+	return params
+}
+
+// stream streams song. If maxBitrate (kbps) is set, server transcodes songs with higher bitrate.
+func (jf *Jellyfin) stream(song *models.Song, maxBitrate int) (rc io.ReadCloser,
+	format interfaces.AudioFormat, err error) {
+	format = interfaces.AudioFormatNil
+	params := jf.streamParams(maxBitrate)
 	// Every new request requires new playsession
-	jf.SessionId = util.RandomKey(20)
-	ptr["PlaySessionId"] = jf.SessionId
-	url := jf.host + "/Audio/" + song.Id.String() + "/universal"
+	session := playSession{id: util.RandomKey(20)}
+	params.ptr()["PlaySessionId"] = session.id
+	url := jf.streamUrl(song)
 	var stream *api.StreamBuffer
 	stream, err = api.NewStreamDownload(url, map[string]string{"X-Emby-Token": jf.token}, *params, jf.client, song.Duration)
 	rc = stream
+	if err != nil {
+		return
+	}
+	// server sends original file as is, but transcoded stream has no known length
+	session.method = playMethodDirectPlay
+	if stream.ContentLength() == 0 {
+		session.method = playMethodTranscode
+	}
+	jf.setPlaySession(song.Id, session)
 	format, err = stream.AudioFormat()
 	return
 }
@@ -21,6 +21,8 @@ package jellyfin
 import (
 	"fmt"
 	"github.com/sirupsen/logrus"
+	"math"
+	"time"
 	"tryffel.net/go/jellycli/models"
 )
 
@@ -93,6 +95,12 @@ type userData struct {
 	PlayCount  int  `json:"PlayCount"`
 	IsFavorite bool `json:"IsFavorite"`
 	Played     bool `json:"Played"`
+	// Rating is user rating from 0 to 10.
+	Rating float64 `json:"Rating"`
+	// PlaybackPositionTicks is saved playback position, used for resuming podcast episodes.
+	PlaybackPositionTicks int64 `json:"PlaybackPositionTicks"`
+	// LastPlayedDate is empty if item has not been played.
+	LastPlayedDate string `json:"LastPlayedDate"`
 }
 
 type nameId struct {
@@ -160,16 +168,17 @@ func (a *albums) Items() []models.Item {
 }
 
 type album struct {
-	Name      string   `json:"Name"`
-	Id        string   `json:"Id"`
-	Duration  int64    `json:"RunTimeTicks"`
-	Year      int      `json:"ProductionYear"`
-	Type      string   `json:"Type"`
-	Artists   []nameId `json:"AlbumArtists"`
-	Overview  string   `json:"Overview"`
-	Genres    []string `json:"Genres"`
-	ImageTags images   `json:"ImageTags"`
-	UserData  userData `json:"UserData"`
+	Name       string   `json:"Name"`
+	Id         string   `json:"Id"`
+	Duration   int64    `json:"RunTimeTicks"`
+	Year       int      `json:"ProductionYear"`
+	Type       string   `json:"Type"`
+	Artists    []nameId `json:"AlbumArtists"`
+	Overview   string   `json:"Overview"`
+	Genres     []string `json:"Genres"`
+	GenreItems []nameId `json:"GenreItems"`
+	ImageTags  images   `json:"ImageTags"`
+	UserData   userData `json:"UserData"`
 }
 
 func (a *album) ExpectType() mediaItemType {
@@ -196,6 +205,12 @@ func (a *album) toAlbum() *models.Album {
 		artists[i].Id = models.Id(v.Id)
 	}
 
+	genres := make([]models.IdName, len(a.GenreItems))
+	for i, v := range a.GenreItems {
+		genres[i].Name = v.Name
+		genres[i].Id = models.Id(v.Id)
+	}
+
 	return &models.Album{
 		Id:                models.Id(a.Id),
 		Name:              a.Name,
@@ -208,6 +223,8 @@ func (a *album) toAlbum() *models.Album {
 		DiscCount:         0,
 		AdditionalArtists: artists,
 		Favorite:          a.UserData.IsFavorite,
+		Overview:          a.Overview,
+		Genres:            genres,
 	}
 }
 
@@ -235,10 +252,115 @@ type song struct {
 	Album          string   `json:"Album"`
 	DiscNumber     int      `json:"ParentIndexNumber"`
 	Artists        []nameId `json:"ArtistItems"`
+	Container      string   `json:"Container"`
+	// MediaSources are only returned if requested with fields
+	MediaSources []mediaSource `json:"MediaSources"`
+	// People are only returned if requested with fields
+	People []person `json:"People"`
+	// PremiereDate is publish date of podcast episode.
+	PremiereDate string `json:"PremiereDate"`
 
 	UserData userData `json:"UserData"`
 }
 
+const personTypeComposer = "Composer"
+
+type person struct {
+	Name string `json:"Name"`
+	Id   string `json:"Id"`
+	Type string `json:"Type"`
+}
+
+type mediaSource struct {
+	Container    string        `json:"Container"`
+	Size         int64         `json:"Size"`
+	Bitrate      int           `json:"Bitrate"`
+	MediaStreams []mediaStream `json:"MediaStreams"`
+}
+
+type mediaStream struct {
+	Type       string `json:"Type"`
+	Codec      string `json:"Codec"`
+	SampleRate int    `json:"SampleRate"`
+}
+
+// return codec of first audio stream. If there is none, return container.
+func (m *mediaSource) codec() string {
+	for _, v := range m.MediaStreams {
+		if v.Type == "Audio" && v.Codec != "" {
+			return v.Codec
+		}
+	}
+	return m.Container
+}
+
+// return sample rate of first audio stream, 0 if unknown.
+func (m *mediaSource) sampleRate() int {
+	for _, v := range m.MediaStreams {
+		if v.Type == "Audio" {
+			return v.SampleRate
+		}
+	}
+	return 0
+}
+
+// itemInfo contains detailed fields of song or album. Path, MediaSources, Genres and DateCreated
+// must be requested with fields.
+type itemInfo struct {
+	Name         string        `json:"Name"`
+	Id           string        `json:"Id"`
+	Path         string        `json:"Path"`
+	AlbumId      string        `json:"AlbumId"`
+	Album        string        `json:"Album"`
+	Artists      []nameId      `json:"ArtistItems"`
+	AlbumArtists []nameId      `json:"AlbumArtists"`
+	GenreItems   []nameId      `json:"GenreItems"`
+	DateCreated  string        `json:"DateCreated"`
+	MediaSources []mediaSource `json:"MediaSources"`
+	UserData     userData      `json:"UserData"`
+}
+
+func (i *itemInfo) toInfo() *models.ItemInfo {
+	info := &models.ItemInfo{
+		Id:        models.Id(i.Id),
+		Name:      i.Name,
+		Album:     models.IdName{Id: models.Id(i.AlbumId), Name: i.Album},
+		Path:      i.Path,
+		PlayCount: i.UserData.PlayCount,
+	}
+
+	artists := i.Artists
+	if len(artists) == 0 {
+		artists = i.AlbumArtists
+	}
+	info.Artists = make([]models.IdName, len(artists))
+	for j, v := range artists {
+		info.Artists[j] = models.IdName{Id: models.Id(v.Id), Name: v.Name}
+	}
+	info.Genres = make([]models.IdName, len(i.GenreItems))
+	for j, v := range i.GenreItems {
+		info.Genres[j] = models.IdName{Id: models.Id(v.Id), Name: v.Name}
+	}
+
+	if len(i.MediaSources) > 0 {
+		source := i.MediaSources[0]
+		info.Codec = source.codec()
+		info.Bitrate = source.Bitrate / 1000
+		info.SampleRate = source.sampleRate()
+		info.Size = source.Size
+	}
+
+	if i.DateCreated != "" {
+		created, err := time.Parse(time.RFC3339Nano, i.DateCreated)
+		if err != nil {
+			logrus.Warningf("parse item %s date created: %v", i.Id, err)
+		} else {
+			info.DateAdded = created
+		}
+	}
+	return info
+}
+
 func (s *song) ExpectType() mediaItemType {
 	return mediaTypeSong
 }
@@ -258,7 +380,7 @@ func (s *song) toSong() *models.Song {
 		artists[i].Id = models.Id(v.Id)
 	}
 
-	return &models.Song{
+	song := &models.Song{
 		Id:         models.Id(s.Id),
 		Name:       s.Name,
 		Duration:   int(s.Duration / ticksToSecond),
@@ -267,7 +389,44 @@ func (s *song) toSong() *models.Song {
 		DiscNumber: s.DiscNumber,
 		Artists:    artists,
 		Favorite:   s.UserData.IsFavorite,
+		Codec:      s.Container,
+		Played:     s.UserData.Played,
+		AlbumName:  s.Album,
+		Year:       s.ProductionYear,
+		PlayCount:  s.UserData.PlayCount,
+		Rating:     int(math.Round(s.UserData.Rating / 2)),
+	}
+
+	if len(s.MediaSources) > 0 {
+		source := s.MediaSources[0]
+		song.Size = source.Size
+		song.Bitrate = source.Bitrate / 1000
+		song.Codec = source.codec()
+	}
+
+	for _, v := range s.People {
+		if v.Type == personTypeComposer {
+			song.Composers = append(song.Composers, models.IdName{Id: models.Id(v.Id), Name: v.Name})
+		}
+	}
+
+	if s.PremiereDate != "" {
+		published, err := time.Parse(time.RFC3339Nano, s.PremiereDate)
+		if err != nil {
+			logrus.Warningf("parse song %s premiere date: %v", s.Id, err)
+		} else {
+			song.Published = published
+		}
 	}
+	if s.UserData.LastPlayedDate != "" {
+		played, err := time.Parse(time.RFC3339Nano, s.UserData.LastPlayedDate)
+		if err != nil {
+			logrus.Warningf("parse song %s last played date: %v", s.Id, err)
+		} else {
+			song.LastPlayed = played
+		}
+	}
+	return song
 }
 
 type collections struct {
@@ -326,14 +485,16 @@ func (p *playlist) toPlaylist() *models.Playlist {
 
 type view struct {
 	nameId
-	Type string `json:"Type"`
+	Type           string `json:"Type"`
+	CollectionType string `json:"CollectionType"`
 }
 
 func (v *view) toView() *models.View {
 	return &models.View{
-		Name: v.Name,
-		Id:   models.Id(v.Id),
-		Type: v.Type,
+		Name:           v.Name,
+		Id:             models.Id(v.Id),
+		Type:           v.Type,
+		CollectionType: v.CollectionType,
 	}
 }
 
@@ -0,0 +1,42 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"tryffel.net/go/jellycli/models"
+)
+
+// SetFavorite implements interfaces.FavoriteController.
+func (jf *Jellyfin) SetFavorite(item models.Item, favorite bool) error {
+	method := http.MethodPost
+	if !favorite {
+		method = http.MethodDelete
+	}
+	params := *jf.defaultParams()
+	url := fmt.Sprintf("/Users/%s/FavoriteItems/%s", jf.userId, item.GetId())
+	resp, err := jf.makeRequest(context.Background(), method, url, nil, &params, nil)
+	if err != nil {
+		return fmt.Errorf("set favorite: %v", err)
+	}
+	resp.Body.Close()
+	return nil
+}
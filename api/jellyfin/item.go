@@ -20,6 +20,7 @@ package jellyfin
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/sirupsen/logrus"
@@ -60,7 +61,7 @@ func (jf *Jellyfin) GetItem(id models.Id) (models.Item, error) {
 	}
 	params := jf.defaultParams()
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items/%s", jf.userId, id), params)
+	resp, err := jf.get(context.Background(), fmt.Sprintf("/Users/%s/Items/%s", jf.userId, id), params)
 	if err != nil {
 		return nil, fmt.Errorf("get item by id: %v", err)
 	}
@@ -131,7 +132,7 @@ func (jf *Jellyfin) GetParentItem(id models.Id) (models.Item, error) {
 	return nil, nil
 }
 
-func (jf *Jellyfin) GetArtist(id models.Id) (*models.Artist, error) {
+func (jf *Jellyfin) GetArtist(ctx context.Context, id models.Id) (*models.Artist, error) {
 	item, found := jf.cache.Get(id)
 	// Return cached value if both artist and albums exist
 	if found && item != nil {
@@ -152,7 +153,7 @@ func (jf *Jellyfin) GetArtist(id models.Id) (*models.Artist, error) {
 
 	params := jf.defaultParams()
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items/%s", jf.userId, id), params)
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items/%s", jf.userId, id), params)
 	if err != nil {
 		return ar, fmt.Errorf("get artist: %v", err)
 	}
@@ -164,7 +165,7 @@ func (jf *Jellyfin) GetArtist(id models.Id) (*models.Artist, error) {
 
 	ar = dto.toArtist()
 
-	albums, err := jf.GetArtistAlbums(id)
+	albums, err := jf.GetArtistAlbums(ctx, id)
 	if err != nil {
 		return ar, fmt.Errorf("get artist albums: %v", err)
 	}
@@ -188,7 +189,7 @@ func (jf *Jellyfin) GetArtist(id models.Id) (*models.Artist, error) {
 }
 
 //GetArtistAlbums retrieves albums for given artist.
-func (jf *Jellyfin) GetArtistAlbums(id models.Id) ([]*models.Album, error) {
+func (jf *Jellyfin) GetArtistAlbums(ctx context.Context, id models.Id) ([]*models.Album, error) {
 	params := *jf.defaultParams()
 	params.setIncludeTypes(mediaTypeAlbum)
 	params.enableRecursive()
@@ -197,7 +198,7 @@ func (jf *Jellyfin) GetArtistAlbums(id models.Id) ([]*models.Album, error) {
 	params["Limit"] = defaultLimit
 	params.setSorting("ProductionYear", "Ascending")
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
 	if err != nil {
 		return nil, fmt.Errorf("get artist albums: %v", err)
 	}
@@ -206,7 +207,112 @@ func (jf *Jellyfin) GetArtistAlbums(id models.Id) ([]*models.Album, error) {
 	return albums, nil
 }
 
-func (jf *Jellyfin) GetAlbum(id models.Id) (*models.Album, error) {
+// GetArtistAppearsOn returns albums that artist contributes to, but is not album artist of.
+func (jf *Jellyfin) GetArtistAppearsOn(ctx context.Context, id models.Id) ([]*models.Album, error) {
+	params := *jf.defaultParams()
+	params.setIncludeTypes(mediaTypeAlbum)
+	params.enableRecursive()
+	params["ContributingArtistIds"] = id.String()
+	params["Limit"] = defaultLimit
+	params.setSorting("ProductionYear", "Ascending")
+
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	if resp != nil {
+		defer resp.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get artist appears on: %v", err)
+	}
+
+	albums, _, err := jf.parseAlbums(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	appearsOn := make([]*models.Album, 0, len(albums))
+	for _, v := range albums {
+		if v.Artist != id {
+			appearsOn = append(appearsOn, v)
+		}
+	}
+	return appearsOn, nil
+}
+
+// GetArtistTopSongs returns most played songs of artist.
+func (jf *Jellyfin) GetArtistTopSongs(ctx context.Context, artist *models.Artist, limit int) ([]*models.Song, error) {
+	params := *jf.defaultParams()
+	params.setIncludeTypes(mediaTypeSong)
+	params.enableRecursive()
+	params["ArtistIds"] = artist.Id.String()
+	params.setLimit(limit)
+	params.setSorting("PlayCount,SortName", "Descending")
+
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	if resp != nil {
+		defer resp.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get artist top songs: %v", err)
+	}
+
+	dto := songs{}
+	err = json.NewDecoder(resp).Decode(&dto)
+	if err != nil {
+		return nil, fmt.Errorf("decode json: %v", err)
+	}
+
+	songs := make([]*models.Song, len(dto.Songs))
+	for i, v := range dto.Songs {
+		logInvalidType(&v, "get artist top songs")
+		songs[i] = v.toSong()
+	}
+	return songs, nil
+}
+
+// GetArtistOverview returns artist biography.
+func (jf *Jellyfin) GetArtistOverview(ctx context.Context, artist *models.Artist) (string, error) {
+	params := jf.defaultParams()
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items/%s", jf.userId, artist.Id), params)
+	if resp != nil {
+		defer resp.Close()
+	}
+	if err != nil {
+		return "", fmt.Errorf("get artist overview: %v", err)
+	}
+
+	dto := struct {
+		Overview string `json:"Overview"`
+	}{}
+	err = json.NewDecoder(resp).Decode(&dto)
+	if err != nil {
+		return "", fmt.Errorf("decode json: %v", err)
+	}
+	return dto.Overview, nil
+}
+
+// GetItemInfo returns detailed info of song or album.
+func (jf *Jellyfin) GetItemInfo(ctx context.Context, item models.Item) (*models.ItemInfo, error) {
+	params := jf.defaultParams()
+	(*params)["Fields"] = "Path,MediaSources,Genres,DateCreated"
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items/%s", jf.userId, item.GetId()), params)
+	if resp != nil {
+		defer resp.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get item info: %v", err)
+	}
+
+	dto := itemInfo{}
+	err = json.NewDecoder(resp).Decode(&dto)
+	if err != nil {
+		return nil, fmt.Errorf("decode json: %v", err)
+	}
+	info := dto.toInfo()
+	info.Type = item.GetType()
+	return info, nil
+}
+
+func (jf *Jellyfin) GetAlbum(ctx context.Context, id models.Id) (*models.Album, error) {
 	item, found := jf.cache.Get(id)
 	// Return cached value if both artist and albums exist
 	if found && item != nil {
@@ -226,7 +332,7 @@ func (jf *Jellyfin) GetAlbum(id models.Id) (*models.Album, error) {
 	al := &models.Album{}
 	params := *jf.defaultParams()
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items/%s", jf.userId, id), &params)
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items/%s", jf.userId, id), &params)
 	if err != nil {
 		return al, fmt.Errorf("get album: %v", err)
 	}
@@ -238,7 +344,7 @@ func (jf *Jellyfin) GetAlbum(id models.Id) (*models.Album, error) {
 
 	al = dto.toAlbum()
 
-	songs, err := jf.GetAlbumSongs(id)
+	songs, err := jf.GetAlbumSongs(ctx, id)
 	if err != nil {
 		return al, fmt.Errorf("get albums songs: %v", err)
 	}
@@ -266,15 +372,16 @@ func (jf *Jellyfin) GetAlbum(id models.Id) (*models.Album, error) {
 }
 
 //GetAlbumSongs gets songs for given album.
-func (jf *Jellyfin) GetAlbumSongs(album models.Id) ([]*models.Song, error) {
+func (jf *Jellyfin) GetAlbumSongs(ctx context.Context, album models.Id) ([]*models.Song, error) {
 	params := *jf.defaultParams()
 	params.enableRecursive()
 	params.setParentId(album.String())
 	params.setSorting("SortName", "Ascending")
+	params["Fields"] = "MediaSources,People"
 
 	params["Limit"] = defaultLimit
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
 	if err != nil {
 		return nil, fmt.Errorf("get album Songs; %v", err)
 	}
@@ -299,7 +406,7 @@ func (jf *Jellyfin) GetFavoriteArtists() ([]*models.Artist, error) {
 	params := *jf.defaultParams()
 	params["IsFavorite"] = "true"
 
-	resp, err := jf.get("/Artists", &params)
+	resp, err := jf.get(context.Background(), "/Artists", &params)
 	if err != nil {
 		return nil, fmt.Errorf("get favorite artists: %v", err)
 	}
@@ -331,7 +438,7 @@ func (jf *Jellyfin) GetFavoriteAlbums(paging interfaces.Paging) ([]*models.Album
 	ptr := params.ptr()
 	ptr["Filters"] = "IsFavorite"
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items", jf.userId), params)
+	resp, err := jf.get(context.Background(), fmt.Sprintf("/Users/%s/Items", jf.userId), params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -344,7 +451,7 @@ func (jf *Jellyfin) GetFavoriteAlbums(paging interfaces.Paging) ([]*models.Album
 
 // GetPlaylists retrieves all playlists. Each playlists song count is known, but songs must be
 // retrieved separately
-func (jf *Jellyfin) GetPlaylists() ([]*models.Playlist, error) {
+func (jf *Jellyfin) GetPlaylists(ctx context.Context) ([]*models.Playlist, error) {
 	params := *jf.defaultParams()
 	params.setParentId(jf.musicView)
 	params.setIncludeTypes(mediaTypePlaylist)
@@ -353,7 +460,7 @@ func (jf *Jellyfin) GetPlaylists() ([]*models.Playlist, error) {
 
 	data := make([]*models.Playlist, 0)
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -368,20 +475,22 @@ func (jf *Jellyfin) GetPlaylists() ([]*models.Playlist, error) {
 	data = make([]*models.Playlist, len(dto.Playlists))
 	for i, v := range dto.Playlists {
 		logInvalidType(&v, "get playlists")
-		pl := v.toPlaylist()
-		data[i] = pl
-		jf.cache.Put(pl.Id, pl, true)
+		data[i] = v.toPlaylist()
+	}
+	jf.setPlaylistShares(ctx, data)
+	for _, v := range data {
+		jf.cache.Put(v.Id, v, true)
 	}
 
 	return data, nil
 }
 
 // GetPlaylistSongs returns songs for playlist id
-func (jf *Jellyfin) GetPlaylistSongs(playlist models.Id) ([]*models.Song, error) {
+func (jf *Jellyfin) GetPlaylistSongs(ctx context.Context, playlist models.Id) ([]*models.Song, error) {
 	params := *jf.defaultParams()
 	params.setParentId(playlist.String())
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -404,15 +513,50 @@ func (jf *Jellyfin) GetPlaylistSongs(playlist models.Id) ([]*models.Song, error)
 	return songs, nil
 }
 
+// CreatePlaylist implements api.PlaylistCreator.
+func (jf *Jellyfin) CreatePlaylist(name string, songs []models.Id) (models.Id, error) {
+	ids := make([]string, len(songs))
+	for i, v := range songs {
+		ids[i] = v.String()
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"Name":      name,
+		"Ids":       ids,
+		"UserId":    jf.userId,
+		"MediaType": "Audio",
+	})
+	if err != nil {
+		return "", fmt.Errorf("encode json: %v", err)
+	}
+
+	resp, err := jf.post(context.Background(), "/Playlists", &body, nil)
+	if resp != nil {
+		defer resp.Close()
+	}
+	if err != nil {
+		return "", fmt.Errorf("create playlist: %v", err)
+	}
+	dto := struct {
+		Id string
+	}{}
+	err = json.NewDecoder(resp).Decode(&dto)
+	if err != nil {
+		return "", fmt.Errorf("decode json: %v", err)
+	}
+	return models.Id(dto.Id), nil
+}
+
 // GetSongs returns songs by paging, and returns total number of songs
-func (jf *Jellyfin) GetSongs(query *interfaces.QueryOpts) ([]*models.Song, int, error) {
+func (jf *Jellyfin) GetSongs(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Song, int, error) {
 	params := *jf.defaultParams()
 	params.setIncludeTypes(mediaTypeSong)
 	params.enableRecursive()
 	params.setPaging(query.Paging)
 	params.setFilter(models.TypeSong, query.Filter)
+	params.setSortingByType(models.TypeSong, query.Sort)
+	params["Fields"] = "People"
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -456,7 +600,7 @@ func (jf *Jellyfin) GetSongsById(ids []models.Id) ([]*models.Song, error) {
 
 	params["Ids"] = idList
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	resp, err := jf.get(context.Background(), fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -483,13 +627,13 @@ func (jf *Jellyfin) GetSongsById(ids []models.Id) ([]*models.Song, error) {
 }
 
 // getArtists return artists defined by paging and total number of artists
-func (jf *Jellyfin) GetArtists(query *interfaces.QueryOpts) (artistList []*models.Artist, numRecords int, err error) {
+func (jf *Jellyfin) GetArtists(ctx context.Context, query *interfaces.QueryOpts) (artistList []*models.Artist, numRecords int, err error) {
 	params := *jf.defaultParams()
 	params.enableRecursive()
 	params.setPaging(query.Paging)
 	params.setSortingByType(models.TypeArtist, query.Sort)
 	params.setFilter(models.TypeArtist, query.Filter)
-	resp, err := jf.get("/Artists", &params)
+	resp, err := jf.get(ctx, "/Artists", &params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -505,7 +649,7 @@ func (jf *Jellyfin) getArtists(paging interfaces.Paging) (artistList []*models.A
 	params.enableRecursive()
 	params.setSorting("SortName", "Ascending")
 	params.setPaging(paging)
-	resp, err := jf.get("/Artists", &params)
+	resp, err := jf.get(context.Background(), "/Artists", &params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -516,13 +660,13 @@ func (jf *Jellyfin) getArtists(paging interfaces.Paging) (artistList []*models.A
 	return jf.parseArtists(resp)
 }
 
-func (jf *Jellyfin) GetAlbumArtists(query *interfaces.QueryOpts) (artistList []*models.Artist, numRecords int, err error) {
+func (jf *Jellyfin) GetAlbumArtists(ctx context.Context, query *interfaces.QueryOpts) (artistList []*models.Artist, numRecords int, err error) {
 	params := *jf.defaultParams()
 	params.enableRecursive()
 	params.setFilter(models.TypeArtist, query.Filter)
 	params.setPaging(query.Paging)
 	params.setSortingByType(models.TypeArtist, query.Sort)
-	resp, err := jf.get("/Artists/AlbumArtists", &params)
+	resp, err := jf.get(ctx, "/Artists/AlbumArtists", &params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -568,14 +712,14 @@ func (jf *Jellyfin) parseAlbums(resp io.Reader) (albumList []*models.Album, numR
 }
 
 // GetAlbums returns albums with given paging. It also returns number of all albums
-func (jf *Jellyfin) GetAlbums(opts *interfaces.QueryOpts) (albumList []*models.Album, numRecords int, err error) {
+func (jf *Jellyfin) GetAlbums(ctx context.Context, opts *interfaces.QueryOpts) (albumList []*models.Album, numRecords int, err error) {
 	params := *jf.defaultParams()
 	params.enableRecursive()
 	params.setPaging(opts.Paging)
 	params.setSortingByType(models.TypeAlbum, opts.Sort)
 	params.setFilter(models.TypeAlbum, opts.Filter)
 	params.setIncludeTypes(mediaTypeAlbum)
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -586,12 +730,12 @@ func (jf *Jellyfin) GetAlbums(opts *interfaces.QueryOpts) (albumList []*models.A
 	return jf.parseAlbums(resp)
 }
 
-func (jf *Jellyfin) GetSimilarArtists(artist models.Id) ([]*models.Artist, error) {
+func (jf *Jellyfin) GetSimilarArtists(ctx context.Context, artist models.Id) ([]*models.Artist, error) {
 	params := *jf.defaultParams()
 	params.enableRecursive()
 	params.setSorting("SortName", "Ascending")
 	params.setLimit(50)
-	resp, err := jf.get(fmt.Sprintf("/Items/%s/Similar", artist.String()), &params)
+	resp, err := jf.get(ctx, fmt.Sprintf("/Items/%s/Similar", artist.String()), &params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -603,12 +747,12 @@ func (jf *Jellyfin) GetSimilarArtists(artist models.Id) ([]*models.Artist, error
 	return artists, err
 }
 
-func (jf *Jellyfin) GetSimilarAlbums(album models.Id) ([]*models.Album, error) {
+func (jf *Jellyfin) GetSimilarAlbums(ctx context.Context, album models.Id) ([]*models.Album, error) {
 	params := *jf.defaultParams()
 	params.enableRecursive()
 	params.setSorting("SortName", "Ascending")
 	params.setLimit(50)
-	resp, err := jf.get(fmt.Sprintf("/Items/%s/Similar", album.String()), &params)
+	resp, err := jf.get(ctx, fmt.Sprintf("/Items/%s/Similar", album.String()), &params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -621,14 +765,50 @@ func (jf *Jellyfin) GetSimilarAlbums(album models.Id) ([]*models.Album, error) {
 
 }
 
-func (jf *Jellyfin) GetGenres(paging interfaces.Paging) ([]*models.IdName, int, error) {
+func (jf *Jellyfin) GetGenres(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
+	params := jf.defaultParams()
+	params.enableRecursive()
+	params.setSorting("SortName", "Ascending")
+	params.setPaging(paging)
+	params.setParentId(jf.musicView)
+
+	resp, err := jf.get(ctx, "/Genres", params)
+	if resp != nil {
+		defer resp.Close()
+	}
+
+	if err != nil {
+		return []*models.IdName{}, 0, err
+	}
+
+	body := struct {
+		Items []nameId
+		Count int `json:"TotalRecordCount"`
+	}{}
+
+	ids := make([]*models.IdName, 0)
+	err = json.NewDecoder(resp).Decode(&body)
+	if err != nil {
+		return ids, 0, fmt.Errorf("decode json: %v", err)
+	}
+
+	ids = make([]*models.IdName, len(body.Items))
+	for i, v := range body.Items {
+		ids[i] = &models.IdName{Id: models.Id(v.Id), Name: v.Name}
+	}
+
+	return ids, body.Count, nil
+}
+
+func (jf *Jellyfin) GetComposers(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
 	params := jf.defaultParams()
 	params.enableRecursive()
 	params.setSorting("SortName", "Ascending")
 	params.setPaging(paging)
 	params.setParentId(jf.musicView)
+	(*params)["PersonTypes"] = personTypeComposer
 
-	resp, err := jf.get("/Genres", params)
+	resp, err := jf.get(ctx, "/Persons", params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -664,7 +844,7 @@ func (jf *Jellyfin) GetGenreAlbums(genre models.IdName) ([]*models.Album, error)
 	(*params)["GenreIds"] = genre.Id.String()
 	params.setIncludeTypes(mediaTypeAlbum)
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items", jf.userId), params)
+	resp, err := jf.get(context.Background(), fmt.Sprintf("/Users/%s/Items", jf.userId), params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -677,10 +857,10 @@ func (jf *Jellyfin) GetGenreAlbums(genre models.IdName) ([]*models.Album, error)
 	return albums, err
 }
 
-func (jf *Jellyfin) GetAlbumArtist(album *models.Album) (*models.Artist, error) {
+func (jf *Jellyfin) GetAlbumArtist(ctx context.Context, album *models.Album) (*models.Artist, error) {
 	artist := jf.cache.GetArtist(album.Id)
 	if artist == nil {
-		artist, err := jf.GetArtist(album.Artist)
+		artist, err := jf.GetArtist(ctx, album.Artist)
 		if err != nil {
 			return nil, fmt.Errorf("get artist: %v", err)
 		}
@@ -19,6 +19,7 @@
 package jellyfin
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -34,7 +35,7 @@ type MediaLibrary struct {
 }
 
 func (jf *Jellyfin) GetUserViews() {
-	body, err := jf.get("/Users/"+jf.userId+"/Views", nil)
+	body, err := jf.get(context.Background(), "/Users/"+jf.userId+"/Views", nil)
 	if err != nil {
 		println(fmt.Errorf("failed to get views: %v", err))
 	}
@@ -0,0 +1,97 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+// Server sends multiple LibraryChanged events during library scan, so wait until events
+// have settled before refreshing.
+const libraryChangeDelay = time.Second * 5
+
+// libraryEvents contains callbacks for library changes.
+type libraryEvents struct {
+	lock      sync.Mutex
+	timer     *time.Timer
+	callbacks []func()
+}
+
+type libraryUpdateInfo struct {
+	ItemsAdded   []string `json:"ItemsAdded"`
+	ItemsRemoved []string `json:"ItemsRemoved"`
+	ItemsUpdated []string `json:"ItemsUpdated"`
+}
+
+// itemCount returns number of changed items.
+func (l *libraryUpdateInfo) itemCount() int {
+	return len(l.ItemsAdded) + len(l.ItemsRemoved) + len(l.ItemsUpdated)
+}
+
+// AddLibraryChangedCallback implements interfaces.LibraryChangeNotifier.
+func (jf *Jellyfin) AddLibraryChangedCallback(cb func()) {
+	jf.library.lock.Lock()
+	defer jf.library.lock.Unlock()
+	jf.library.callbacks = append(jf.library.callbacks, cb)
+}
+
+// parseLibraryChanged handles LibraryChanged websocket message.
+func (jf *Jellyfin) parseLibraryChanged(buff []byte) error {
+	msg := struct {
+		Data libraryUpdateInfo `json:"Data"`
+	}{}
+	err := json.Unmarshal(buff, &msg)
+	if err != nil {
+		return fmt.Errorf("parse library changed: %v", err)
+	}
+	if msg.Data.itemCount() == 0 {
+		return nil
+	}
+
+	logrus.Debugf("Library changed: %d added, %d removed, %d updated", len(msg.Data.ItemsAdded),
+		len(msg.Data.ItemsRemoved), len(msg.Data.ItemsUpdated))
+
+	jf.library.lock.Lock()
+	defer jf.library.lock.Unlock()
+	if jf.library.timer != nil {
+		jf.library.timer.Stop()
+	}
+	jf.library.timer = time.AfterFunc(libraryChangeDelay, jf.libraryChanged)
+	return nil
+}
+
+// libraryChanged flushes cached items and notifies callbacks.
+func (jf *Jellyfin) libraryChanged() {
+	jf.cache.Flush()
+
+	jf.library.lock.Lock()
+	jf.library.timer = nil
+	callbacks := make([]func(), len(jf.library.callbacks))
+	copy(callbacks, jf.library.callbacks)
+	jf.library.lock.Unlock()
+
+	logrus.Info("Library has changed, refreshing")
+	for _, cb := range callbacks {
+		cb()
+	}
+}
@@ -0,0 +1,67 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import "testing"
+
+func TestJellyfin_parseLibraryChanged(t *testing.T) {
+	tests := []struct {
+		name        string
+		msg         string
+		wantRefresh bool
+		wantErr     bool
+	}{
+		{
+			name: "items added",
+			msg: `{"MessageType":"LibraryChanged","Data":{"CollectionFolders":["f1"],"FoldersAddedTo":[],
+"FoldersRemovedFrom":[],"ItemsAdded":["a1","a2"],"ItemsRemoved":[],"ItemsUpdated":[],"IsEmpty":false}}`,
+			wantRefresh: true,
+		},
+		{
+			name:        "items removed",
+			msg:         `{"MessageType":"LibraryChanged","Data":{"ItemsRemoved":["a1"]}}`,
+			wantRefresh: true,
+		},
+		{
+			name:        "no items",
+			msg:         `{"MessageType":"LibraryChanged","Data":{"FoldersAddedTo":["f1"],"IsEmpty":true}}`,
+			wantRefresh: false,
+		},
+		{
+			name:    "invalid",
+			msg:     `{"MessageType":"LibraryChanged","Data":[]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jf := &Jellyfin{}
+			err := jf.parseLibraryChanged([]byte(tt.msg))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseLibraryChanged() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if jf.library.timer != nil {
+				jf.library.timer.Stop()
+			}
+			if (jf.library.timer != nil) != tt.wantRefresh {
+				t.Errorf("parseLibraryChanged() refresh = %v, want %v", jf.library.timer != nil, tt.wantRefresh)
+			}
+		})
+	}
+}
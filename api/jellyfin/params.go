@@ -50,7 +50,11 @@ func (p *params) enableRecursive() {
 	(*p)["Recursive"] = "true"
 }
 
+// setParentId limits results to given parent. Empty id or musicViewAll does not limit results.
 func (p *params) setParentId(id string) {
+	if id == "" || id == musicViewAll {
+		return
+	}
 	(*p)["ParentId"] = id
 }
 
@@ -88,6 +92,10 @@ func (p *params) setSortingByType(itemType models.ItemType, sort interfaces.Sort
 		field = "DateCreated,SortName"
 	case interfaces.SortByLastPlayed:
 		field = "DatePlayed,SortName"
+	case interfaces.SortByRating:
+		field = "CommunityRating,SortName"
+	case interfaces.SortByReleaseDate:
+		field = "PremiereDate,ProductionYear,SortName"
 	}
 
 	p.setSorting(field, order)
@@ -133,6 +141,22 @@ func (p *params) setFilter(tItem models.ItemType, filter interfaces.Filter) {
 		(*p)["Genres"] = genres
 	}
 
+	if len(filter.Composers) > 0 {
+		composers := ""
+		for _, v := range filter.Composers {
+			composers = appendFilter(composers, v.Id.String(), ",")
+		}
+		(*p)["PersonIds"] = composers
+		(*p)["PersonTypes"] = personTypeComposer
+	}
+
+	if filter.NameStartsWith == interfaces.NameStartsOther {
+		// compared to lowercase sort name, see interfaces.NameStartsOther
+		(*p)["NameLessThan"] = "a"
+	} else if filter.NameStartsWith != "" {
+		(*p)["NameStartsWith"] = filter.NameStartsWith
+	}
+
 	if f != "" {
 		(*p)["Filters"] = f
 	}
@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"testing"
 	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
 )
 
 func Test_params_setPaging(t *testing.T) {
@@ -53,3 +54,39 @@ func Test_params_setPaging(t *testing.T) {
 		})
 	}
 }
+
+func Test_params_setFilter_composers(t *testing.T) {
+	p := params{}
+	p.setFilter(models.TypeAlbum, interfaces.Filter{
+		Composers: []models.IdName{{Id: "a", Name: "Bach"}, {Id: "b", Name: "Handel"}},
+	})
+
+	want := params{"PersonIds": "a,b", "PersonTypes": "Composer"}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("setFilter() = %v, want %v", p, want)
+	}
+}
+
+func Test_params_setParentId(t *testing.T) {
+	p := params{}
+	p.setParentId("abc")
+	if p["ParentId"] != "abc" {
+		t.Errorf("setParentId() = %v, want abc", p["ParentId"])
+	}
+
+	for _, id := range []string{"", musicViewAll} {
+		p := params{}
+		p.setParentId(id)
+		if _, ok := p["ParentId"]; ok {
+			t.Errorf("setParentId(%q) set ParentId", id)
+		}
+	}
+}
+
+func Test_params_setFilter_nameStartsOther(t *testing.T) {
+	p := params{}
+	p.setFilter(models.TypeArtist, interfaces.Filter{NameStartsWith: interfaces.NameStartsOther})
+	if p["NameLessThan"] != "a" || p["NameStartsWith"] != "" {
+		t.Errorf("setFilter() = %v, want NameLessThan=a", p)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"tryffel.net/go/jellycli/models"
+)
+
+// playlistShares is playlist's sharing info. Servers before 10.9 don't have it.
+type playlistShares struct {
+	OpenAccess bool            `json:"OpenAccess"`
+	Shares     []playlistShare `json:"Shares"`
+}
+
+// playlistShare is user playlist is shared with.
+type playlistShare struct {
+	UserId  string `json:"UserId"`
+	CanEdit bool   `json:"CanEdit"`
+}
+
+// access returns whether playlist is shared with user and whether user can only play it.
+// Owner is not listed in shares, thus open access playlists of other users without share
+// are considered user's own.
+func (p *playlistShares) access(userId string) (shared bool, readOnly bool) {
+	for _, v := range p.Shares {
+		if normalizeUserId(v.UserId) == normalizeUserId(userId) {
+			return true, !v.CanEdit
+		}
+	}
+	return false, false
+}
+
+// normalizeUserId removes formatting differences of guids.
+func normalizeUserId(id string) string {
+	return strings.ToLower(strings.Replace(id, "-", "", -1))
+}
+
+// getPlaylistShares retrieves sharing info for playlist.
+func (jf *Jellyfin) getPlaylistShares(ctx context.Context, id models.Id) (*playlistShares, error) {
+	resp, err := jf.get(ctx, "/Playlists/"+id.String(), nil)
+	if resp != nil {
+		defer resp.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	dto := &playlistShares{}
+	err = json.NewDecoder(resp).Decode(dto)
+	if err != nil {
+		return nil, fmt.Errorf("decode json: %v", err)
+	}
+	return dto, nil
+}
+
+// setPlaylistShares marks playlists shared with user. If server does not support sharing,
+// playlists are left as user's own.
+func (jf *Jellyfin) setPlaylistShares(ctx context.Context, playlists []*models.Playlist) {
+	for _, v := range playlists {
+		shares, err := jf.getPlaylistShares(ctx, v.Id)
+		if err != nil {
+			logrus.Debugf("get playlist shares, server might not support sharing: %v", err)
+			return
+		}
+		v.Shared, v.ReadOnly = shares.access(jf.userId)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import "testing"
+
+func Test_playlistShares_access(t *testing.T) {
+	shares := &playlistShares{
+		Shares: []playlistShare{
+			{UserId: "6a3e0a5c-7d2b-4b4e-9c1f-2f3c4d5e6f70", CanEdit: false},
+			{UserId: "0123456789abcdef0123456789abcdef", CanEdit: true},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		userId       string
+		wantShared   bool
+		wantReadOnly bool
+	}{
+		{name: "read only", userId: "6A3E0A5C7D2B4B4E9C1F2F3C4D5E6F70", wantShared: true, wantReadOnly: true},
+		{name: "editable", userId: "0123456789abcdef0123456789abcdef", wantShared: true, wantReadOnly: false},
+		{name: "own", userId: "ffffffffffffffffffffffffffffffff", wantShared: false, wantReadOnly: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shared, readOnly := shares.access(tt.userId)
+			if shared != tt.wantShared || readOnly != tt.wantReadOnly {
+				t.Errorf("access() = %v, %v, want %v, %v", shared, readOnly, tt.wantShared, tt.wantReadOnly)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"tryffel.net/go/jellycli/models"
+)
+
+// Play methods reported to server
+const (
+	playMethodDirectPlay = "DirectPlay"
+	playMethodTranscode  = "Transcode"
+)
+
+// playSession is a single playback of a song. Songs are downloaded before they are played,
+// so sessions are stored per song until playback is reported stopped.
+type playSession struct {
+	id     string
+	method string
+}
+
+func (jf *Jellyfin) setPlaySession(song models.Id, session playSession) {
+	jf.playSessionLock.Lock()
+	defer jf.playSessionLock.Unlock()
+	if jf.playSessions == nil {
+		jf.playSessions = map[models.Id]playSession{}
+	}
+	jf.playSessions[song] = session
+}
+
+// getPlaySession returns session for song. If song has no session, default session is returned.
+func (jf *Jellyfin) getPlaySession(song models.Id) playSession {
+	jf.playSessionLock.Lock()
+	defer jf.playSessionLock.Unlock()
+	if session, ok := jf.playSessions[song]; ok {
+		return session
+	}
+	return playSession{id: jf.SessionId, method: playMethodDirectPlay}
+}
+
+// endPlaySession removes session after playback has stopped.
+func (jf *Jellyfin) endPlaySession(song models.Id) {
+	jf.playSessionLock.Lock()
+	defer jf.playSessionLock.Unlock()
+	delete(jf.playSessions, song)
+}
@@ -0,0 +1,124 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// defaultPodcastView is name of library that is used for podcasts if podcast view is not configured.
+const defaultPodcastView = "podcasts"
+
+// podcastLibrary returns id of podcast library. Configured podcast view can be either library id or name.
+func (jf *Jellyfin) podcastLibrary() (string, error) {
+	name := strings.ToLower(jf.podcastView)
+	if name == "" {
+		name = defaultPodcastView
+	}
+	views, err := jf.GetViews()
+	if err != nil {
+		return "", err
+	}
+	for _, v := range views {
+		if v.Id.String() == jf.podcastView || strings.ToLower(v.Name) == name {
+			return v.Id.String(), nil
+		}
+	}
+	return "", interfaces.ErrPodcastsNotSupported
+}
+
+// GetPodcasts implements interfaces.PodcastController.
+func (jf *Jellyfin) GetPodcasts() ([]*models.Album, error) {
+	library, err := jf.podcastLibrary()
+	if err != nil {
+		return nil, err
+	}
+	params := *jf.defaultParams()
+	params.setIncludeTypes(mediaTypeAlbum)
+	params.enableRecursive()
+	params.setParentId(library)
+	params.setSorting("SortName", "Ascending")
+	params["Limit"] = defaultLimit
+
+	resp, err := jf.get(context.Background(), fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	if resp != nil {
+		defer resp.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get podcasts: %v", err)
+	}
+
+	podcasts, _, err := jf.parseAlbums(resp)
+	return podcasts, err
+}
+
+// GetEpisodes implements interfaces.PodcastController.
+func (jf *Jellyfin) GetEpisodes(podcast models.Id) ([]*models.Song, error) {
+	params := *jf.defaultParams()
+	params.setIncludeTypes(mediaTypeSong)
+	params.enableRecursive()
+	params.setParentId(podcast.String())
+	params.setSorting("PremiereDate,DateCreated", "Descending")
+	params["Fields"] = "MediaSources"
+	params["Limit"] = defaultLimit
+
+	resp, err := jf.get(context.Background(), fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	if resp != nil {
+		defer resp.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get podcast episodes: %v", err)
+	}
+
+	dto := songs{}
+	err = json.NewDecoder(resp).Decode(&dto)
+	if err != nil {
+		return nil, fmt.Errorf("parse episodes: %v", err)
+	}
+
+	episodes := make([]*models.Song, len(dto.Songs))
+	for i, v := range dto.Songs {
+		episodes[i] = v.toSong()
+		// only episodes are resumed, music always starts from beginning
+		episodes[i].Position = int(v.UserData.PlaybackPositionTicks / ticksToMillisecond)
+	}
+	return episodes, nil
+}
+
+// SetPlayed implements interfaces.PodcastController.
+func (jf *Jellyfin) SetPlayed(episode models.Id, played bool) error {
+	method := http.MethodPost
+	if !played {
+		method = http.MethodDelete
+	}
+	params := *jf.defaultParams()
+	url := fmt.Sprintf("/Users/%s/PlayedItems/%s", jf.userId, episode)
+	resp, err := jf.makeRequest(context.Background(), method, url, nil, &params, nil)
+	if err != nil {
+		return fmt.Errorf("set played: %v", err)
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// playlistItemPrefix is prefix of playlist item ids in queue reported to server. Id is prefix followed by
+// index of song in queue, and other clients refer to songs in queue with it.
+const playlistItemPrefix = "playlistItem"
+
+// playlistItemIndex returns index of song in queue from playlist item id.
+func playlistItemIndex(id string) (int, bool) {
+	if !strings.HasPrefix(id, playlistItemPrefix) {
+		return 0, false
+	}
+	index, err := strconv.Atoi(strings.TrimPrefix(id, playlistItemPrefix))
+	if err != nil || index < 0 {
+		return 0, false
+	}
+	return index, true
+}
+
+// removeFromQueue removes songs other clients have removed from session's queue. Ids are comma separated
+// playlist item ids. Playing song is not removed.
+func (jf *Jellyfin) removeFromQueue(ids string) {
+	indices := []int{}
+	for _, v := range strings.Split(ids, ",") {
+		if index, ok := playlistItemIndex(strings.TrimSpace(v)); ok && index > 0 {
+			indices = append(indices, index)
+		}
+	}
+	// remove last songs first, so that indices of remaining songs don't change
+	sort.Sort(sort.Reverse(sort.IntSlice(indices)))
+	for _, v := range indices {
+		jf.queue.RemoveSong(v)
+	}
+}
+
+// moveInQueue moves song other clients have moved in session's queue to new index.
+// Playing song cannot be moved, and nothing can be moved before it.
+func (jf *Jellyfin) moveInQueue(id string, newIndex int) {
+	index, ok := playlistItemIndex(id)
+	if !ok || index == 0 {
+		return
+	}
+	if newIndex < 1 {
+		newIndex = 1
+	}
+	for index > newIndex && jf.queue.Reorder(index, true) {
+		index--
+	}
+	for index < newIndex && jf.queue.Reorder(index, false) {
+		index++
+	}
+}
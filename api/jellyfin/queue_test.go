@@ -0,0 +1,101 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/interfaces"
+)
+
+// testQueue records songs removed from queue and moves songs in a queue of given size.
+type testQueue struct {
+	interfaces.QueueController
+	size    int
+	removed []int
+	moves   int
+}
+
+func (t *testQueue) RemoveSong(index int) {
+	t.removed = append(t.removed, index)
+}
+
+func (t *testQueue) Reorder(index int, down bool) bool {
+	if index < 1 || index >= t.size || (down && index == 1) || (!down && index == t.size-1) {
+		return false
+	}
+	t.moves++
+	return true
+}
+
+func Test_playlistItemIndex(t *testing.T) {
+	tests := []struct {
+		id     string
+		want   int
+		wantOk bool
+	}{
+		{id: "playlistItem0", want: 0, wantOk: true},
+		{id: "playlistItem12", want: 12, wantOk: true},
+		{id: "playlistItem", want: 0, wantOk: false},
+		{id: "item3", want: 0, wantOk: false},
+		{id: "playlistItem-1", want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got, ok := playlistItemIndex(tt.id)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("playlistItemIndex() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestJellyfin_removeFromQueue(t *testing.T) {
+	queue := &testQueue{size: 5}
+	jf := &Jellyfin{queue: queue}
+	jf.removeFromQueue("playlistItem1,playlistItem3, playlistItem0,invalid")
+	if !reflect.DeepEqual(queue.removed, []int{3, 1}) {
+		t.Errorf("removed songs %v, want [3 1]", queue.removed)
+	}
+}
+
+func TestJellyfin_moveInQueue(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        string
+		newIndex  int
+		wantMoves int
+	}{
+		{name: "later", id: "playlistItem1", newIndex: 3, wantMoves: 2},
+		{name: "earlier", id: "playlistItem4", newIndex: 2, wantMoves: 2},
+		{name: "before playing song", id: "playlistItem2", newIndex: 0, wantMoves: 1},
+		{name: "past end", id: "playlistItem3", newIndex: 10, wantMoves: 1},
+		{name: "playing song", id: "playlistItem0", newIndex: 2, wantMoves: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &testQueue{size: 5}
+			jf := &Jellyfin{queue: queue}
+			jf.moveInQueue(tt.id, tt.newIndex)
+			if queue.moves != tt.wantMoves {
+				t.Errorf("moves = %d, want %d", queue.moves, tt.wantMoves)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ package jellyfin
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"io"
@@ -44,16 +45,18 @@ func (jf *Jellyfin) defaultParams() *params {
 	return &params
 }
 
-func (jf *Jellyfin) get(url string, params *params) (io.ReadCloser, error) {
-	resp, err := jf.makeRequest("GET", url, nil, params, nil)
+// get makes GET request. Responses with ETag are cached and revalidated by api client,
+// so unchanged response is not transferred again.
+func (jf *Jellyfin) get(ctx context.Context, url string, params *params) (io.ReadCloser, error) {
+	resp, err := jf.makeRequest(ctx, "GET", url, nil, params, nil)
 	if resp != nil {
 		return resp.Body, err
 	}
 	return nil, err
 }
 
-func (jf *Jellyfin) post(url string, body *[]byte, params *params) (io.ReadCloser, error) {
-	resp, err := jf.makeRequest("POST", url, body, params, nil)
+func (jf *Jellyfin) post(ctx context.Context, url string, body *[]byte, params *params) (io.ReadCloser, error) {
+	resp, err := jf.makeRequest(ctx, "POST", url, body, params, nil)
 	if resp != nil {
 		return resp.Body, err
 	}
@@ -62,17 +65,18 @@ func (jf *Jellyfin) post(url string, body *[]byte, params *params) (io.ReadClose
 
 //Construct request
 // Set authorization header and build url query
-// Make request, parse response code and raise error if needed. Else return response body
-func (jf *Jellyfin) makeRequest(method, url string, body *[]byte, params *params,
+// Make request, parse response code and raise error if needed. Else return response body.
+// Request is cancelled when ctx is cancelled.
+func (jf *Jellyfin) makeRequest(ctx context.Context, method, url string, body *[]byte, params *params,
 	headers map[string]string) (*http.Response, error) {
 	var reader *bytes.Buffer
 	var req *http.Request
 	var err error
 	if body != nil {
 		reader = bytes.NewBuffer(*body)
-		req, err = http.NewRequest(method, jf.host+url, reader)
+		req, err = http.NewRequestWithContext(ctx, method, jf.host+url, reader)
 	} else {
-		req, err = http.NewRequest(method, jf.host+url, nil)
+		req, err = http.NewRequestWithContext(ctx, method, jf.host+url, nil)
 	}
 
 	if err != nil {
@@ -97,8 +101,12 @@ func (jf *Jellyfin) makeRequest(method, url string, body *[]byte, params *params
 		req.URL.RawQuery = q.Encode()
 	}
 	start := time.Now()
-	resp, err := jf.client.Do(req)
+	resp, err := jf.requests.Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		jf.connection.RequestFailed(err)
 		return nil, fmt.Errorf("failed make request: %v", err)
 	}
 	took := time.Since(start)
@@ -19,6 +19,7 @@
 package jellyfin
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -67,7 +68,7 @@ func searchDtoToItems(rc io.ReadCloser, target mediaItemType) ([]models.Item, er
 }
 
 //Search searches audio items
-func (jf *Jellyfin) Search(query string, itemType models.ItemType, limit int) ([]models.Item, error) {
+func (jf *Jellyfin) Search(ctx context.Context, query string, itemType models.ItemType, limit int) ([]models.Item, error) {
 	if limit == 0 {
 		limit = 40
 	}
@@ -97,7 +98,7 @@ func (jf *Jellyfin) Search(query string, itemType models.ItemType, limit int) ([
 		return nil, errors.New("genres not supported")
 	}
 
-	body, err := jf.get(url, &params)
+	body, err := jf.get(ctx, url, &params)
 	if err != nil {
 		msg := getBodyMsg(body)
 		return nil, fmt.Errorf("query failed: %v: %s", err, msg)
@@ -0,0 +1,126 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+type sessionDto struct {
+	Id                    string `json:"Id"`
+	Client                string `json:"Client"`
+	DeviceName            string `json:"DeviceName"`
+	DeviceId              string `json:"DeviceId"`
+	UserName              string `json:"UserName"`
+	SupportsRemoteControl bool   `json:"SupportsRemoteControl"`
+	NowPlayingItem        *struct {
+		Name string `json:"Name"`
+	} `json:"NowPlayingItem"`
+	PlayState struct {
+		IsPaused bool `json:"IsPaused"`
+	} `json:"PlayState"`
+}
+
+func (s *sessionDto) toSession() *models.Session {
+	session := &models.Session{
+		Id:         models.Id(s.Id),
+		Client:     s.Client,
+		DeviceName: s.DeviceName,
+		UserName:   s.UserName,
+		Paused:     s.PlayState.IsPaused,
+	}
+	if s.NowPlayingItem != nil {
+		session.NowPlaying = s.NowPlayingItem.Name
+	}
+	return session
+}
+
+// GetSessions implements interfaces.SessionController. Own session is not included.
+func (jf *Jellyfin) GetSessions() ([]*models.Session, error) {
+	params := *jf.defaultParams()
+	params["ControllableByUserId"] = jf.userId
+	resp, err := jf.get(context.Background(), "/Sessions", &params)
+	if resp != nil {
+		defer resp.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dto := []sessionDto{}
+	err = json.NewDecoder(resp).Decode(&dto)
+	if err != nil {
+		return nil, fmt.Errorf("decode json: %v", err)
+	}
+
+	sessions := make([]*models.Session, 0, len(dto))
+	for _, v := range dto {
+		if v.DeviceId == jf.DeviceId || !v.SupportsRemoteControl {
+			continue
+		}
+		sessions = append(sessions, v.toSession())
+	}
+	return sessions, nil
+}
+
+// PlaySessionSongs implements interfaces.SessionController.
+func (jf *Jellyfin) PlaySessionSongs(session models.Id, songs []models.Id, playNow bool) error {
+	if len(songs) == 0 {
+		return fmt.Errorf("no songs")
+	}
+	ids := make([]string, len(songs))
+	for i, v := range songs {
+		ids[i] = v.String()
+	}
+
+	params := *jf.defaultParams()
+	params["ItemIds"] = strings.Join(ids, ",")
+	params["PlayCommand"] = "PlayLast"
+	if playNow {
+		params["PlayCommand"] = "PlayNow"
+	}
+	resp, err := jf.post(context.Background(), fmt.Sprintf("/Sessions/%s/Playing", session), nil, &params)
+	if resp != nil {
+		resp.Close()
+	}
+	return err
+}
+
+// SendSessionCommand implements interfaces.SessionController.
+func (jf *Jellyfin) SendSessionCommand(session models.Id, command interfaces.SessionCommand) error {
+	var url string
+	switch command {
+	case interfaces.SessionVolumeUp, interfaces.SessionVolumeDown, interfaces.SessionToggleMute:
+		url = fmt.Sprintf("/Sessions/%s/Command/%s", session, command)
+	default:
+		url = fmt.Sprintf("/Sessions/%s/Playing/%s", session, command)
+	}
+
+	params := *jf.defaultParams()
+	resp, err := jf.post(context.Background(), url, nil, &params)
+	if resp != nil {
+		resp.Close()
+	}
+	return err
+}
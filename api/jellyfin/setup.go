@@ -0,0 +1,182 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/models"
+)
+
+// quickConnectInterval is the interval to check whether quick connect has been authorized.
+const quickConnectInterval = time.Second * 3
+
+// Setup is a connection to jellyfin server for configuring new user: validating server url,
+// authenticating and selecting music library. Once done, Config returns config for NewJellyfin.
+type Setup struct {
+	jf *Jellyfin
+}
+
+// NewSetup validates url and checks that it points to a jellyfin server.
+func NewSetup(serverUrl string) (*Setup, error) {
+	host, err := normalizeUrl(serverUrl)
+	if err != nil {
+		return nil, err
+	}
+	jf, err := newJellyfin(nil)
+	if err != nil {
+		return nil, err
+	}
+	jf.host = host
+	err = jf.ping()
+	if err != nil {
+		return nil, fmt.Errorf("connect jellyfin server at %s: %v", host, err)
+	}
+	return &Setup{jf: jf}, nil
+}
+
+// normalizeUrl validates server url and removes trailing slash. Url without scheme defaults to http.
+func normalizeUrl(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errors.New("url cannot be empty")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("parse url: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme '%s', use http or https", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", errors.New("url has no host")
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+// Url returns validated server url.
+func (s *Setup) Url() string {
+	return s.jf.host
+}
+
+// Login authenticates with username and password.
+func (s *Setup) Login(username, password string) error {
+	return s.jf.login(username, password)
+}
+
+// QuickConnectEnabled returns true if server allows logging in with quick connect.
+func (s *Setup) QuickConnectEnabled() bool {
+	body, err := s.jf.get(context.Background(), "/QuickConnect/Enabled", nil)
+	if err != nil {
+		return false
+	}
+	defer body.Close()
+	enabled := false
+	err = json.NewDecoder(body).Decode(&enabled)
+	return err == nil && enabled
+}
+
+type quickConnectResponse struct {
+	Authenticated bool   `json:"Authenticated"`
+	Secret        string `json:"Secret"`
+	Code          string `json:"Code"`
+}
+
+// QuickConnect is a pending quick connect request.
+type QuickConnect struct {
+	// Code is shown to user, who authorizes it in another client that is already logged in.
+	Code   string
+	secret string
+}
+
+// InitiateQuickConnect starts new quick connect request.
+func (s *Setup) InitiateQuickConnect() (*QuickConnect, error) {
+	headers := map[string]string{"X-Emby-Authorization": s.jf.authHeader()}
+	resp, err := s.jf.makeRequest(context.Background(), "POST", "/QuickConnect/Initiate", nil, nil, headers)
+	if err != nil && resp != nil &&
+		(resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed) {
+		// servers before 10.9 initiate with GET
+		resp, err = s.jf.makeRequest(context.Background(), "GET", "/QuickConnect/Initiate", nil, nil, headers)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("initiate quick connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	dto := quickConnectResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&dto)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quick connect response: %v", err)
+	}
+	if dto.Code == "" || dto.Secret == "" {
+		return nil, errors.New("server did not return quick connect code")
+	}
+	return &QuickConnect{Code: dto.Code, secret: dto.Secret}, nil
+}
+
+// WaitQuickConnect waits until quick connect has been authorized and logs in.
+// Closing stop cancels waiting.
+func (s *Setup) WaitQuickConnect(qc *QuickConnect, stop <-chan bool) error {
+	ticker := time.NewTicker(quickConnectInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return errors.New("quick connect cancelled")
+		case <-ticker.C:
+		}
+
+		body, err := s.jf.get(context.Background(), "/QuickConnect/Connect", &params{"secret": qc.secret})
+		if err != nil {
+			return fmt.Errorf("quick connect: %v", err)
+		}
+		dto := quickConnectResponse{}
+		err = json.NewDecoder(body).Decode(&dto)
+		body.Close()
+		if err != nil {
+			return fmt.Errorf("invalid quick connect response: %v", err)
+		}
+		if dto.Authenticated {
+			return s.jf.quickConnectLogin(qc.secret)
+		}
+	}
+}
+
+// GetLibraries returns music libraries of logged in user.
+func (s *Setup) GetLibraries() ([]*models.View, error) {
+	return s.jf.GetLibraries()
+}
+
+// Config returns configuration for logged in user. Empty library uses all music libraries.
+func (s *Setup) Config(library models.Id) config.Backend {
+	s.jf.SetLibrary(library)
+	return s.jf.GetConfig()
+}
@@ -0,0 +1,51 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import "testing"
+
+func Test_normalizeUrl(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{name: "valid", url: "https://jellyfin.example.com", want: "https://jellyfin.example.com"},
+		{name: "trailing slash", url: " http://localhost:8096/jellyfin/ ", want: "http://localhost:8096/jellyfin"},
+		{name: "no scheme", url: "192.168.1.10:8096", want: "http://192.168.1.10:8096"},
+		{name: "query", url: "https://example.com/web/index.html?x=1#!/home", want: "https://example.com/web/index.html"},
+		{name: "empty", url: "  ", wantErr: true},
+		{name: "invalid scheme", url: "ftp://example.com", wantErr: true},
+		{name: "no host", url: "http://", wantErr: true},
+		{name: "invalid", url: "http://exa mple.com:port", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeUrl(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("normalizeUrl() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("normalizeUrl() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -19,17 +19,21 @@
 package jellyfin
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
 	"math"
+	"math/rand"
 	"net/url"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/config"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 )
@@ -37,6 +41,9 @@ import (
 const (
 	pongTimeout = 10 * time.Second
 	pingPeriod  = (pongTimeout * 9) / 10
+
+	// remoteSeekStep is how much rewind and fast forward commands seek
+	remoteSeekStep = interfaces.AudioTick(10000)
 )
 
 func (jf *Jellyfin) connectSocket() error {
@@ -53,10 +60,11 @@ func (jf *Jellyfin) connectSocket() error {
 	dialer := websocket.Dialer{
 		Proxy:            nil,
 		HandshakeTimeout: time.Second * 10,
+		TLSClientConfig:  api.TlsConfig(jf.client),
 	}
 	logrus.Debug("connecting websocket to ", host)
 	socket, _, err := dialer.Dial(
-		fmt.Sprintf("%s://%s/socket?api_key=%s&deviceId=%s", scheme, host, jf.token, jf.DeviceId), nil)
+		fmt.Sprintf("%s://%s/socket?api_key=%s&deviceId=%s", scheme, host, jf.token, jf.DeviceId), api.CustomHeaders())
 	if err != nil {
 		jf.socketState = socketDisconnected
 		return fmt.Errorf("websocket connection failed: %v", err)
@@ -122,6 +130,13 @@ func (jf *Jellyfin) parseInboudMessage(buff *[]byte) error {
 		return fmt.Errorf("parse json: %v, body: %s", err, str)
 	}
 
+	if strings.HasPrefix(msg.MessageType, "SyncPlay") {
+		return jf.parseSyncPlayMessage(msg.MessageType, *buff)
+	}
+	if msg.MessageType == "LibraryChanged" {
+		return jf.parseLibraryChanged(*buff)
+	}
+
 	dataMap, ok := msg.Data.(map[string]interface{})
 	if !ok {
 		if msg.MessageType != "ForceKeepAlive" {
@@ -133,32 +148,54 @@ func (jf *Jellyfin) parseInboudMessage(buff *[]byte) error {
 	cmd := strings.ToLower(msg.MessageType)
 	if cmd == "generalcommand" {
 		name := dataMap["Name"]
-		ar := dataMap["Arguments"]
-		args, ok := ar.(map[string]interface{})
-		if ok {
-			switch name {
-			case "SetVolume":
-				vol := args["Volume"]
-				volume, err := strconv.Atoi(vol.(string))
-				if err != nil {
-					logrus.Error("Invalid volume parameter")
-				} else {
-					volume := interfaces.AudioVolume(volume)
-					jf.player.SetVolume(volume)
-				}
-			case "ToggleMute":
-				jf.player.ToggleMute()
-			default:
-				logrus.Warning("unknown socket command: ", name)
+		args, _ := dataMap["Arguments"].(map[string]interface{})
+		switch name {
+		case "SetVolume":
+			vol, _ := args["Volume"].(string)
+			volume, err := strconv.Atoi(vol)
+			if err != nil {
+				logrus.Error("Invalid volume parameter")
+			} else {
+				volume := interfaces.AudioVolume(volume)
+				jf.player.SetVolume(volume)
 			}
-		} else {
-			logrus.Error("unexpected command format from websocket, expected general command args map[string]interface, got", jf)
+		case "VolumeUp", "VolumeDown":
+			status, _ := jf.playerStatus()
+			step := config.VolumeStepSize
+			if name == "VolumeDown" {
+				step = -step
+			}
+			jf.player.SetVolume(status.Volume.Add(step))
+		case "ToggleMute":
+			jf.player.ToggleMute()
+		case "Mute":
+			jf.player.SetMute(true)
+		case "Unmute":
+			jf.player.SetMute(false)
+		case "SetShuffleQueue":
+			mode, _ := args["ShuffleMode"].(string)
+			jf.player.SetShuffle(mode == "Shuffle")
+		case "RemoveFromPlaylist":
+			ids, _ := args["PlaylistItemIds"].(string)
+			jf.removeFromQueue(ids)
+		case "MovePlaylistItem":
+			id, _ := args["PlaylistItemId"].(string)
+			index, _ := args["NewIndex"].(string)
+			newIndex, err := strconv.Atoi(index)
+			if err != nil {
+				logrus.Error("Invalid playlist item index")
+			} else {
+				jf.moveInQueue(id, newIndex)
+			}
+		default:
+			logrus.Warning("unknown socket command: ", name)
 		}
 	} else if cmd == "playstate" {
 		rawCmd := dataMap["Command"]
 		cmd, ok := rawCmd.(string)
 		if ok {
-			err = jf.pushCommand(cmd)
+			ticks, _ := dataMap["SeekPositionTicks"].(float64)
+			err = jf.pushCommand(cmd, int64(ticks))
 		}
 	} else if cmd == "play" {
 		var items []string
@@ -189,7 +226,8 @@ func (jf *Jellyfin) parseInboudMessage(buff *[]byte) error {
 	return err
 }
 
-func (jf *Jellyfin) pushCommand(cmd string) error {
+// pushCommand pushes playstate command to player. SeekPositionTicks is only used with seek command.
+func (jf *Jellyfin) pushCommand(cmd string, seekPositionTicks int64) error {
 	if jf.player == nil {
 		return nil
 	}
@@ -211,6 +249,12 @@ func (jf *Jellyfin) pushCommand(cmd string) error {
 	case "Stop":
 		jf.player.StopMedia()
 		jf.queue.ClearQueue(true)
+	case "Seek":
+		jf.player.SeekTo(interfaces.AudioTick(seekPositionTicks / ticksToMillisecond))
+	case "Rewind":
+		jf.player.Seek(-remoteSeekStep)
+	case "FastForward":
+		jf.player.Seek(remoteSeekStep)
 	default:
 		logrus.Info("Unknown websocket playstate command: ", cmd)
 	}
@@ -276,6 +320,7 @@ func (jf *Jellyfin) reconnectSocket() bool {
 	err = jf.connectSocket()
 	if err != nil {
 		logrus.Debugf("reconnect socket: %v", err)
+		jf.connection.RequestFailed(err)
 		return false
 	}
 	logrus.Warning("Websocket reconnected")
@@ -284,6 +329,46 @@ func (jf *Jellyfin) reconnectSocket() bool {
 
 // push songs to queue.
 func (jf *Jellyfin) pushSongsToQueue(items []string, mode string) {
+	songs, err := jf.getSongsBatched(items)
+	if err != nil {
+		logrus.Errorf("remote control: add songs to queue: get songs from ids: %v", err)
+		return
+	}
+	logrus.Debug("received play event: ", mode)
+
+	// some modes are swapped in other clients, use those for consistency
+	if mode == "PlayNow" || mode == "PlayShuffle" {
+		if mode == "PlayShuffle" {
+			rand.Shuffle(len(songs), func(i, j int) { songs[i], songs[j] = songs[j], songs[i] })
+		}
+		jf.player.StopMedia()
+		jf.queue.ClearQueue(true)
+		jf.queue.PlayNext(songs)
+	} else if mode == "PlayLast" {
+		//} else if mode == "PlayNext" {
+		jf.queue.PlayNext(songs)
+	} else if mode == "PlayNext" {
+		//} else if mode == "PlayLast" {
+		jf.queue.AddSongs(songs)
+	} else if mode == "PlayInstantMix" {
+		if len(songs) == 0 {
+			return
+		}
+		mix, err := jf.GetInstantMix(context.Background(), songs[0])
+		if err != nil {
+			logrus.Errorf("remote control: get instant mix: %v", err)
+			return
+		}
+		jf.player.StopMedia()
+		jf.queue.ClearQueue(true)
+		jf.queue.AddSongs(mix)
+	} else {
+		logrus.Errorf("unknown remote play mode: %s", mode)
+	}
+}
+
+// getSongsBatched returns songs for given ids. Server does not guarantee songs are in same order as ids.
+func (jf *Jellyfin) getSongsBatched(items []string) ([]*models.Song, error) {
 	ids := []models.Id{}
 	for _, v := range items {
 		ids = append(ids, models.Id(v))
@@ -315,25 +400,5 @@ func (jf *Jellyfin) pushSongsToQueue(items []string, mode string) {
 	} else {
 		songs, err = jf.GetSongsById(ids)
 	}
-
-	if err != nil {
-		logrus.Errorf("remote control: add songs to queue: get songs from ids: %v", err)
-		return
-	}
-	logrus.Debug("received play event: ", mode)
-
-	// some modes are swapped in other clients, use those for consistency
-	if mode == "PlayNow" {
-		jf.player.StopMedia()
-		jf.queue.ClearQueue(true)
-		jf.queue.PlayNext(songs)
-	} else if mode == "PlayLast" {
-		//} else if mode == "PlayNext" {
-		jf.queue.PlayNext(songs)
-	} else if mode == "PlayNext" {
-		//} else if mode == "PlayLast" {
-		jf.queue.AddSongs(songs)
-	} else {
-		logrus.Errorf("unknown remote play mode: %s", mode)
-	}
+	return songs, err
 }
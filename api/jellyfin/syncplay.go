@@ -0,0 +1,464 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+const (
+	// syncPlayTolerance is maximum difference to group position that is not corrected with seeking.
+	syncPlayTolerance = interfaces.AudioTick(500)
+)
+
+// syncPlay contains SyncPlay group state.
+type syncPlay struct {
+	lock      sync.Mutex
+	group     *models.SyncPlayGroup
+	callbacks []func(group *models.SyncPlayGroup)
+	// song is the song that was playing on last status update.
+	song *models.Song
+
+	// playlistItemId identifies current item in group's queue.
+	playlistItemId string
+	// pending is set when group queue has been loaded, but song has not started yet.
+	pending *syncPlayPending
+}
+
+// syncPlayPending contains position to start playing song from when it has been loaded.
+type syncPlayPending struct {
+	songId        models.Id
+	positionTicks int64
+}
+
+type syncPlayGroupDto struct {
+	GroupId      string   `json:"GroupId"`
+	GroupName    string   `json:"GroupName"`
+	State        string   `json:"State"`
+	Participants []string `json:"Participants"`
+}
+
+func (s *syncPlayGroupDto) toGroup() *models.SyncPlayGroup {
+	return &models.SyncPlayGroup{
+		Id:           models.Id(s.GroupId),
+		Name:         s.GroupName,
+		State:        s.State,
+		Participants: s.Participants,
+	}
+}
+
+type syncPlayCommandDto struct {
+	GroupId        string    `json:"GroupId"`
+	PlaylistItemId string    `json:"PlaylistItemId"`
+	When           time.Time `json:"When"`
+	PositionTicks  int64     `json:"PositionTicks"`
+	Command        string    `json:"Command"`
+}
+
+type syncPlayGroupUpdateDto struct {
+	GroupId string          `json:"GroupId"`
+	Type    string          `json:"Type"`
+	Data    json.RawMessage `json:"Data"`
+}
+
+type syncPlayStateDto struct {
+	State  string `json:"State"`
+	Reason string `json:"Reason"`
+}
+
+type syncPlayQueueItemDto struct {
+	ItemId         string `json:"ItemId"`
+	PlaylistItemId string `json:"PlaylistItemId"`
+}
+
+type syncPlayQueueDto struct {
+	Reason             string                 `json:"Reason"`
+	Playlist           []syncPlayQueueItemDto `json:"Playlist"`
+	PlayingItemIndex   int                    `json:"PlayingItemIndex"`
+	StartPositionTicks int64                  `json:"StartPositionTicks"`
+	IsPlaying          bool                   `json:"IsPlaying"`
+}
+
+type syncPlayReadyDto struct {
+	When           time.Time `json:"When"`
+	PositionTicks  int64     `json:"PositionTicks"`
+	IsPlaying      bool      `json:"IsPlaying"`
+	PlaylistItemId string    `json:"PlaylistItemId"`
+}
+
+// GetSyncPlayGroups implements interfaces.SyncPlayController.
+func (jf *Jellyfin) GetSyncPlayGroups() ([]*models.SyncPlayGroup, error) {
+	params := *jf.defaultParams()
+	resp, err := jf.get(context.Background(), "/SyncPlay/List", &params)
+	if resp != nil {
+		defer resp.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dto := []syncPlayGroupDto{}
+	err = json.NewDecoder(resp).Decode(&dto)
+	if err != nil {
+		return nil, fmt.Errorf("decode json: %v", err)
+	}
+
+	groups := make([]*models.SyncPlayGroup, len(dto))
+	for i, v := range dto {
+		groups[i] = v.toGroup()
+	}
+	return groups, nil
+}
+
+// NewSyncPlayGroup implements interfaces.SyncPlayController.
+func (jf *Jellyfin) NewSyncPlayGroup(name string) error {
+	err := jf.initSyncPlay()
+	if err != nil {
+		return err
+	}
+	return jf.postSyncPlay("/SyncPlay/New", map[string]string{"GroupName": name})
+}
+
+// JoinSyncPlayGroup implements interfaces.SyncPlayController.
+func (jf *Jellyfin) JoinSyncPlayGroup(id models.Id) error {
+	err := jf.initSyncPlay()
+	if err != nil {
+		return err
+	}
+	return jf.postSyncPlay("/SyncPlay/Join", map[string]string{"GroupId": id.String()})
+}
+
+// LeaveSyncPlayGroup implements interfaces.SyncPlayController.
+func (jf *Jellyfin) LeaveSyncPlayGroup() error {
+	return jf.postSyncPlay("/SyncPlay/Leave", nil)
+}
+
+// AddSyncPlayCallback implements interfaces.SyncPlayController.
+func (jf *Jellyfin) AddSyncPlayCallback(cb func(group *models.SyncPlayGroup)) {
+	jf.syncPlay.lock.Lock()
+	defer jf.syncPlay.lock.Unlock()
+	jf.syncPlay.callbacks = append(jf.syncPlay.callbacks, cb)
+}
+
+// initSyncPlay ensures group commands can be received.
+func (jf *Jellyfin) initSyncPlay() error {
+	if jf.player == nil || jf.queue == nil {
+		return fmt.Errorf("player not set")
+	}
+	if !jf.WebsocketOk() {
+		return fmt.Errorf("websocket is not connected")
+	}
+	return nil
+}
+
+func (jf *Jellyfin) postSyncPlay(url string, body interface{}) error {
+	params := *jf.defaultParams()
+	var data []byte
+	var err error
+	if body != nil {
+		data, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("json marshaling failed: %v", err)
+		}
+	}
+	resp, err := jf.post(context.Background(), url, &data, &params)
+	if resp != nil {
+		resp.Close()
+	}
+	return err
+}
+
+// notifySyncPlay calls callbacks with copy of current group. Caller must hold lock.
+func (jf *Jellyfin) notifySyncPlay() {
+	var group *models.SyncPlayGroup
+	if jf.syncPlay.group != nil {
+		g := *jf.syncPlay.group
+		g.Participants = append([]string{}, g.Participants...)
+		group = &g
+	}
+	for _, v := range jf.syncPlay.callbacks {
+		go v(group)
+	}
+}
+
+// parseSyncPlayMessage handles SyncPlay websocket messages.
+func (jf *Jellyfin) parseSyncPlayMessage(msgType string, buff []byte) error {
+	if jf.player == nil {
+		return nil
+	}
+	switch msgType {
+	case "SyncPlayCommand":
+		msg := struct {
+			Data syncPlayCommandDto `json:"Data"`
+		}{}
+		err := json.Unmarshal(buff, &msg)
+		if err != nil {
+			return fmt.Errorf("parse syncplay command: %v", err)
+		}
+		jf.scheduleSyncPlayCommand(msg.Data)
+	case "SyncPlayGroupUpdate":
+		msg := struct {
+			Data syncPlayGroupUpdateDto `json:"Data"`
+		}{}
+		err := json.Unmarshal(buff, &msg)
+		if err != nil {
+			return fmt.Errorf("parse syncplay group update: %v", err)
+		}
+		return jf.handleSyncPlayGroupUpdate(msg.Data)
+	}
+	return nil
+}
+
+func (jf *Jellyfin) handleSyncPlayGroupUpdate(update syncPlayGroupUpdateDto) error {
+	logrus.Debugf("syncplay group update: %s", update.Type)
+	switch update.Type {
+	case "GroupJoined":
+		dto := syncPlayGroupDto{}
+		err := json.Unmarshal(update.Data, &dto)
+		if err != nil {
+			return fmt.Errorf("parse syncplay group: %v", err)
+		}
+		jf.syncPlay.lock.Lock()
+		jf.syncPlay.group = dto.toGroup()
+		jf.notifySyncPlay()
+		jf.syncPlay.lock.Unlock()
+	case "GroupLeft", "NotInGroup":
+		jf.syncPlay.lock.Lock()
+		jf.syncPlay.group = nil
+		jf.syncPlay.pending = nil
+		jf.syncPlay.playlistItemId = ""
+		jf.notifySyncPlay()
+		jf.syncPlay.lock.Unlock()
+	case "UserJoined", "UserLeft":
+		var user string
+		err := json.Unmarshal(update.Data, &user)
+		if err != nil {
+			return fmt.Errorf("parse syncplay user: %v", err)
+		}
+		jf.syncPlay.lock.Lock()
+		if jf.syncPlay.group != nil {
+			if update.Type == "UserJoined" {
+				jf.syncPlay.group.Participants = append(jf.syncPlay.group.Participants, user)
+			} else {
+				jf.syncPlay.group.Participants = removeParticipant(jf.syncPlay.group.Participants, user)
+			}
+			jf.notifySyncPlay()
+		}
+		jf.syncPlay.lock.Unlock()
+	case "StateUpdate":
+		dto := syncPlayStateDto{}
+		err := json.Unmarshal(update.Data, &dto)
+		if err != nil {
+			return fmt.Errorf("parse syncplay state: %v", err)
+		}
+		jf.syncPlay.lock.Lock()
+		if jf.syncPlay.group != nil {
+			jf.syncPlay.group.State = dto.State
+			jf.notifySyncPlay()
+		}
+		jf.syncPlay.lock.Unlock()
+	case "PlayQueue":
+		dto := syncPlayQueueDto{}
+		err := json.Unmarshal(update.Data, &dto)
+		if err != nil {
+			return fmt.Errorf("parse syncplay queue: %v", err)
+		}
+		go jf.syncPlayQueue(dto)
+	case "GroupDoesNotExist", "CreateGroupDenied", "JoinGroupDenied", "LibraryAccessDenied":
+		logrus.Errorf("syncplay: %s", update.Type)
+	default:
+		logrus.Debugf("unknown syncplay group update: %s", update.Type)
+	}
+	return nil
+}
+
+// syncPlayQueue replaces local queue with group queue. If group is already playing same song,
+// only upcoming songs are updated.
+func (jf *Jellyfin) syncPlayQueue(queue syncPlayQueueDto) {
+	if queue.PlayingItemIndex < 0 || queue.PlayingItemIndex >= len(queue.Playlist) {
+		jf.syncPlay.lock.Lock()
+		jf.syncPlay.playlistItemId = ""
+		jf.syncPlay.pending = nil
+		jf.syncPlay.lock.Unlock()
+		jf.player.StopMedia()
+		jf.queue.ClearQueue(true)
+		return
+	}
+
+	items := queue.Playlist[queue.PlayingItemIndex:]
+	ids := make([]string, len(items))
+	for i, v := range items {
+		ids[i] = v.ItemId
+	}
+	songs, err := jf.getSongsBatched(ids)
+	if err != nil {
+		logrus.Errorf("syncplay: get queue songs: %v", err)
+		return
+	}
+	songs = sortSongsByIds(songs, ids)
+	if len(songs) == 0 {
+		logrus.Errorf("syncplay: no songs in group queue")
+		return
+	}
+
+	current := items[0]
+	status, _ := jf.playerStatus()
+	jf.syncPlay.lock.Lock()
+	jf.syncPlay.playlistItemId = current.PlaylistItemId
+	samePlaying := status.State == interfaces.AudioStatePlaying && status.Song != nil &&
+		status.Song.Id.String() == current.ItemId
+	if !samePlaying {
+		jf.syncPlay.pending = &syncPlayPending{
+			songId:        songs[0].Id,
+			positionTicks: queue.StartPositionTicks,
+		}
+	}
+	jf.syncPlay.lock.Unlock()
+
+	if samePlaying {
+		jf.queue.ClearQueue(false)
+		jf.queue.AddSongs(songs[1:])
+		return
+	}
+
+	jf.player.StopMedia()
+	jf.queue.ClearQueue(true)
+	jf.queue.AddSongs(songs)
+}
+
+// syncPlayStatusChanged keeps track of player position. Once group's song has started, it is moved to group's
+// position and paused until group continues playing.
+func (jf *Jellyfin) syncPlayStatusChanged(status interfaces.AudioStatus) {
+	jf.syncPlay.lock.Lock()
+	previous := jf.syncPlay.song
+	jf.syncPlay.song = status.Song
+	if jf.syncPlay.group == nil {
+		jf.syncPlay.lock.Unlock()
+		return
+	}
+
+	pending := jf.syncPlay.pending
+	itemId := jf.syncPlay.playlistItemId
+	started := pending != nil && status.State == interfaces.AudioStatePlaying && status.Song != nil &&
+		status.Song.Id == pending.songId
+	if started {
+		jf.syncPlay.pending = nil
+	}
+	// song ended locally, group moves to next song
+	songChanged := pending == nil && previous != nil && status.Song != nil && previous.Id != status.Song.Id
+	jf.syncPlay.lock.Unlock()
+
+	if started {
+		go func() {
+			jf.player.Pause()
+			jf.syncPlaySeek(pending.positionTicks)
+			jf.syncPlayReady(pending.positionTicks, itemId)
+		}()
+	} else if songChanged && itemId != "" {
+		go func() {
+			err := jf.postSyncPlay("/SyncPlay/NextItem", map[string]string{"PlaylistItemId": itemId})
+			if err != nil {
+				logrus.Errorf("syncplay: next item: %v", err)
+			}
+		}()
+	}
+}
+
+// syncPlayReady tells group this client is ready to play from given position.
+func (jf *Jellyfin) syncPlayReady(positionTicks int64, playlistItemId string) {
+	ready := syncPlayReadyDto{
+		When:           time.Now().UTC(),
+		PositionTicks:  positionTicks,
+		IsPlaying:      false,
+		PlaylistItemId: playlistItemId,
+	}
+	err := jf.postSyncPlay("/SyncPlay/Ready", ready)
+	if err != nil {
+		logrus.Errorf("syncplay: send ready: %v", err)
+	}
+}
+
+// scheduleSyncPlayCommand executes command at time group has defined.
+func (jf *Jellyfin) scheduleSyncPlayCommand(cmd syncPlayCommandDto) {
+	delay := time.Until(cmd.When)
+	if delay < 0 {
+		delay = 0
+	}
+	logrus.Debugf("syncplay command %s in %s", cmd.Command, delay.String())
+	time.AfterFunc(delay, func() {
+		switch cmd.Command {
+		case "Unpause":
+			jf.syncPlaySeek(cmd.PositionTicks)
+			jf.player.Continue()
+		case "Pause":
+			jf.player.Pause()
+			jf.syncPlaySeek(cmd.PositionTicks)
+		case "Seek":
+			jf.player.Pause()
+			jf.syncPlaySeek(cmd.PositionTicks)
+			jf.syncPlayReady(cmd.PositionTicks, cmd.PlaylistItemId)
+		case "Stop":
+			jf.player.StopMedia()
+		default:
+			logrus.Warningf("unknown syncplay command: %s", cmd.Command)
+		}
+	})
+}
+
+// syncPlaySeek seeks player to given position, if it differs from current position.
+func (jf *Jellyfin) syncPlaySeek(positionTicks int64) {
+	_, current := jf.playerStatus()
+	target := interfaces.AudioTick(positionTicks / ticksToMillisecond)
+	diff := target - current
+	if diff > -syncPlayTolerance && diff < syncPlayTolerance {
+		return
+	}
+	jf.player.SeekTo(target)
+}
+
+// sortSongsByIds orders songs in same order as ids. Missing songs are skipped.
+func sortSongsByIds(songs []*models.Song, ids []string) []*models.Song {
+	byId := make(map[string]*models.Song, len(songs))
+	for _, v := range songs {
+		byId[v.Id.String()] = v
+	}
+	sorted := make([]*models.Song, 0, len(ids))
+	for _, v := range ids {
+		if song, ok := byId[v]; ok {
+			sorted = append(sorted, song)
+		}
+	}
+	return sorted
+}
+
+func removeParticipant(participants []string, user string) []string {
+	for i, v := range participants {
+		if v == user {
+			return append(participants[:i], participants[i+1:]...)
+		}
+	}
+	return participants
+}
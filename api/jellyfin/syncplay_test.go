@@ -0,0 +1,82 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_sortSongsByIds(t *testing.T) {
+	songs := []*models.Song{{Id: "c"}, {Id: "a"}, {Id: "b"}}
+	got := sortSongsByIds(songs, []string{"a", "b", "d", "c"})
+	want := []*models.Song{songs[1], songs[2], songs[0]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortSongsByIds() = %v, want %v", got, want)
+	}
+}
+
+func Test_estimatePosition(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  interfaces.AudioStatus
+		elapsed time.Duration
+		want    interfaces.AudioTick
+	}{
+		{
+			name:    "playing",
+			status:  interfaces.AudioStatus{State: interfaces.AudioStatePlaying, SongPast: 10000},
+			elapsed: time.Millisecond * 800,
+			want:    10800,
+		},
+		{
+			name:    "paused",
+			status:  interfaces.AudioStatus{State: interfaces.AudioStatePlaying, SongPast: 10000, Paused: true},
+			elapsed: time.Millisecond * 800,
+			want:    10000,
+		},
+		{
+			name:    "stopped",
+			status:  interfaces.AudioStatus{State: interfaces.AudioStateStopped},
+			elapsed: time.Second,
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimatePosition(tt.status, tt.elapsed); got != tt.want {
+				t.Errorf("estimatePosition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_removeParticipant(t *testing.T) {
+	got := removeParticipant([]string{"alice", "bob", "carol"}, "bob")
+	if !reflect.DeepEqual(got, []string{"alice", "carol"}) {
+		t.Errorf("removeParticipant() = %v", got)
+	}
+	got = removeParticipant([]string{"alice"}, "bob")
+	if !reflect.DeepEqual(got, []string{"alice"}) {
+		t.Errorf("removeParticipant() = %v", got)
+	}
+}
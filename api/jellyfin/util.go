@@ -19,6 +19,7 @@
 package jellyfin
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/denisbrodbeck/machineid"
@@ -28,6 +29,7 @@ import (
 	"os"
 	"runtime"
 	"strconv"
+	"time"
 	"tryffel.net/go/jellycli/config"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
@@ -35,7 +37,8 @@ import (
 )
 
 const (
-	ticksToSecond = int64(10000000)
+	ticksToSecond      = int64(10000000)
+	ticksToMillisecond = ticksToSecond / 1000
 )
 
 type infoResponse struct {
@@ -47,7 +50,7 @@ type infoResponse struct {
 }
 
 func (jf *Jellyfin) getserverInfo() (*infoResponse, error) {
-	body, err := jf.get("/System/Info/Public", nil)
+	body, err := jf.get(context.Background(), "/System/Info/Public", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +103,7 @@ func idsToQueue(ids []models.Id) []queueItem {
 	for i, v := range ids {
 		out = append(out, queueItem{
 			Id:    v.String(),
-			Index: "playlistItem" + strconv.Itoa(i),
+			Index: playlistItemPrefix + strconv.Itoa(i),
 		})
 	}
 	return out
@@ -117,17 +120,18 @@ func (jf *Jellyfin) ReportProgress(state *interfaces.ApiPlaybackState) error {
 	var report interface{}
 	var url string
 
+	session := jf.getPlaySession(models.Id(state.ItemId))
 	started := playbackStarted{
 		QueueableMediaTypes: []string{"Audio"},
-		CanSeek:             false,
+		CanSeek:             true,
 		ItemId:              state.ItemId,
 		MediaSourceId:       state.ItemId,
-		PositionTicks:       int64(state.Position) * ticksToSecond,
+		PositionTicks:       int64(state.Position) * ticksToMillisecond,
 		VolumeLevel:         state.Volume,
 		IsPaused:            state.IsPaused,
 		IsMuted:             state.IsMuted,
-		PlayMethod:          "DirectPlay",
-		PlaySessionId:       jf.SessionId,
+		PlayMethod:          session.method,
+		PlaySessionId:       session.id,
 		LiveStreamId:        "",
 		PlaylistLength:      int64(state.PlaylistLength) * ticksToSecond,
 		Queue:               idsToQueue(state.Queue),
@@ -145,6 +149,7 @@ func (jf *Jellyfin) ReportProgress(state *interfaces.ApiPlaybackState) error {
 	} else if state.Event == interfaces.EventStop {
 		url = "/Sessions/Playing/Stopped"
 		report = started
+		defer jf.endPlaySession(models.Id(state.ItemId))
 	} else {
 		url = "/Sessions/Playing/Progress"
 		report = playbackProgress{
@@ -161,7 +166,7 @@ func (jf *Jellyfin) ReportProgress(state *interfaces.ApiPlaybackState) error {
 		return fmt.Errorf("json marshaling failed: %v", err)
 	}
 	var resp io.ReadCloser
-	resp, err = jf.post(url, &body, &params)
+	resp, err = jf.post(context.Background(), url, &body, &params)
 	if resp != nil {
 		resp.Close()
 	}
@@ -191,14 +196,26 @@ func (jf *Jellyfin) ReportProgress(state *interfaces.ApiPlaybackState) error {
 	}
 }
 
+// Scrobble implements api.Scrobbler by marking song played at given time.
+func (jf *Jellyfin) Scrobble(song models.Id, played time.Time) error {
+	params := *jf.defaultParams()
+	params["datePlayed"] = played.UTC().Format("20060102150405")
+	url := fmt.Sprintf("/Users/%s/PlayedItems/%s", jf.userId, song)
+	body, err := jf.post(context.Background(), url, nil, &params)
+	if err != nil {
+		return fmt.Errorf("mark played: %v", err)
+	}
+	body.Close()
+	return nil
+}
+
 func (jf *Jellyfin) GetCacheItems() int {
 	return jf.cache.Count()
 }
 
-//ImageUrl returns primary image url for item, if there is one. Otherwise return empty
+//ImageUrl returns primary image url for item. Jellyfin serves images without authentication.
 func (jf *Jellyfin) GetImageUrl(item models.Id, itemType models.ItemType) string {
-	return ""
-	//return fmt.Sprintf("%s/Items/%s/Images/Primary?maxHeight=500&tag=%s&quality=90", jf.host, item, imageTag)
+	return fmt.Sprintf("%s/Items/%s/Images/Primary?maxHeight=500&quality=90", jf.host, item)
 }
 
 func (jf *Jellyfin) ReportCapabilities() error {
@@ -231,7 +248,7 @@ func (jf *Jellyfin) ReportCapabilities() error {
 
 	url := "/Sessions/Capabilities/Full"
 
-	resp, err := jf.makeRequest(http.MethodPost, url, &body, &params,
+	resp, err := jf.makeRequest(context.Background(), http.MethodPost, url, &body, &params,
 		map[string]string{"X-Emby-Authorization": jf.authHeader()})
 	if err != nil {
 		return err
@@ -266,9 +283,15 @@ func (jf *Jellyfin) deviceName() string {
 	return hostname
 }
 
+// GetLink returns link to item in Jellyfin web client.
 func (jf *Jellyfin) GetLink(item models.Item) string {
 	// http://host/jellyfin/web/index.html#!/details.html?id=id&serverId=serverId
-	url := fmt.Sprintf("%s/web/index.html#!/details?id=%s", jf.host, item.GetId())
+	page := "details?id="
+	if item.GetType() == models.TypeGenre {
+		// genres have no details page, list their items instead
+		page = "list.html?musicGenreId="
+	}
+	url := fmt.Sprintf("%s/web/index.html#!/%s%s", jf.host, page, item.GetId())
 	if jf.serverId != "" {
 		url += "&serverId=" + jf.serverId
 	}
@@ -19,18 +19,24 @@
 package jellyfin
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 	"tryffel.net/go/jellycli/config"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 )
 
+// instantMixPlayedAge is how long songs are left out of instant mix after playing them, if enabled.
+const instantMixPlayedAge = time.Hour * 24
+
 func (jf *Jellyfin) GetViews() ([]*models.View, error) {
 	params := *jf.defaultParams()
 
 	url := fmt.Sprintf("/Users/%s/Views", jf.userId)
-	resp, err := jf.get(url, &params)
+	resp, err := jf.get(context.Background(), url, &params)
 	if err != nil {
 		return nil, fmt.Errorf("get views: %v", err)
 	}
@@ -53,7 +59,7 @@ func (jf *Jellyfin) GetLatestAlbums() ([]*models.Album, error) {
 	params["UserId"] = jf.userId
 	params.setParentId(jf.musicView)
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items/Latest", jf.userId), &params)
+	resp, err := jf.get(context.Background(), fmt.Sprintf("/Users/%s/Items/Latest", jf.userId), &params)
 	if err != nil {
 		return nil, fmt.Errorf("request latest albums: %v", err)
 	}
@@ -74,7 +80,7 @@ func (jf *Jellyfin) GetLatestAlbums() ([]*models.Album, error) {
 	return albums, nil
 }
 
-func (jf *Jellyfin) GetRecentlyPlayed(paging interfaces.Paging) ([]*models.Song, int, error) {
+func (jf *Jellyfin) GetRecentlyPlayed(ctx context.Context, paging interfaces.Paging) ([]*models.Song, int, error) {
 	params := *jf.defaultParams()
 
 	params.setIncludeTypes(mediaTypeSong)
@@ -91,7 +97,7 @@ func (jf *Jellyfin) GetRecentlyPlayed(paging interfaces.Paging) ([]*models.Song,
 	}
 	params.setPaging(paging)
 
-	resp, err := jf.get(fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
+	resp, err := jf.get(ctx, fmt.Sprintf("/Users/%s/Items", jf.userId), &params)
 	if err != nil {
 		return nil, 0, fmt.Errorf("request latest albums: %v", err)
 	}
@@ -117,14 +123,20 @@ func (jf *Jellyfin) GetRecentlyPlayed(paging interfaces.Paging) ([]*models.Song,
 }
 
 // GetInstantMix returns instant mix for given item.
-func (jf *Jellyfin) GetInstantMix(item models.Item) ([]*models.Song, error) {
+func (jf *Jellyfin) GetInstantMix(ctx context.Context, item models.Item) ([]*models.Song, error) {
 	params := *jf.defaultParams()
 	params.setIncludeTypes(mediaTypeSong)
 	params["UserId"] = jf.userId
 	params.setParentId(jf.musicView)
+	limit := config.InstantMixSize
+	if config.InstantMixSkipPlayed {
+		// request more songs so that mix is full after skipping played ones
+		limit *= 2
+	}
+	params["Limit"] = strconv.Itoa(limit)
 
 	url := fmt.Sprintf("/Items/%s/InstantMix", item.GetId().String())
-	resp, err := jf.get(url, &params)
+	resp, err := jf.get(ctx, url, &params)
 	if resp != nil {
 		defer resp.Close()
 	}
@@ -145,6 +157,22 @@ func (jf *Jellyfin) GetInstantMix(item models.Item) ([]*models.Song, error) {
 		logInvalidType(&v, "get songs")
 		songs[i] = v.toSong()
 	}
-
+	if config.InstantMixSkipPlayed {
+		songs = skipPlayedSongs(songs, time.Now().Add(-instantMixPlayedAge))
+	}
+	if len(songs) > config.InstantMixSize {
+		songs = songs[:config.InstantMixSize]
+	}
 	return songs, nil
 }
+
+// skipPlayedSongs returns songs that have not been played after given time.
+func skipPlayedSongs(songs []*models.Song, after time.Time) []*models.Song {
+	filtered := make([]*models.Song, 0, len(songs))
+	for _, v := range songs {
+		if !v.LastPlayed.After(after) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
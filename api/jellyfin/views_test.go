@@ -0,0 +1,45 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package jellyfin
+
+import (
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestSkipPlayedSongs(t *testing.T) {
+	now := time.Date(2021, 4, 10, 12, 0, 0, 0, time.UTC)
+	songs := []*models.Song{
+		{Id: "never-played"},
+		{Id: "played-hour-ago", LastPlayed: now.Add(-time.Hour)},
+		{Id: "played-week-ago", LastPlayed: now.Add(-time.Hour * 24 * 7)},
+	}
+
+	got := skipPlayedSongs(songs, now.Add(-instantMixPlayedAge))
+	want := []models.Id{"never-played", "played-week-ago"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d songs, want %d", len(got), len(want))
+	}
+	for i, v := range got {
+		if v.Id != want[i] {
+			t.Errorf("song %d: got %s, want %s", i, v.Id, want[i])
+		}
+	}
+}
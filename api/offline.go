@@ -0,0 +1,184 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// ErrOffline is returned by every request to Offline server.
+var ErrOffline = errors.New("server is offline")
+
+// Offline is a MediaServer that stands in for a server that cannot be reached.
+// It keeps the id and config of the real server so that local cache for it can be used,
+// every request fails with ErrOffline.
+type Offline struct {
+	id   string
+	conf config.Backend
+}
+
+// NewOffline creates offline server with given id and config of the real server.
+func NewOffline(id string, conf config.Backend) *Offline {
+	return &Offline{
+		id:   id,
+		conf: conf,
+	}
+}
+
+func (o *Offline) Stream(Song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
+	return nil, interfaces.AudioFormatNil, ErrOffline
+}
+
+func (o *Offline) Download(Song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
+	return nil, interfaces.AudioFormatNil, ErrOffline
+}
+
+func (o *Offline) GetArtists(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Artist, int, error) {
+	return nil, 0, ErrOffline
+}
+
+func (o *Offline) GetAlbumArtists(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Artist, int, error) {
+	return nil, 0, ErrOffline
+}
+
+func (o *Offline) GetAlbums(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Album, int, error) {
+	return nil, 0, ErrOffline
+}
+
+func (o *Offline) GetArtistAlbums(ctx context.Context, artist models.Id) ([]*models.Album, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetArtistAppearsOn(ctx context.Context, artist models.Id) ([]*models.Album, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetArtistTopSongs(ctx context.Context, artist *models.Artist, limit int) ([]*models.Song, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetArtistOverview(ctx context.Context, artist *models.Artist) (string, error) {
+	return "", ErrOffline
+}
+
+func (o *Offline) GetAlbumSongs(ctx context.Context, album models.Id) ([]*models.Song, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetPlaylists(ctx context.Context) ([]*models.Playlist, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetPlaylistSongs(ctx context.Context, playlist models.Id) ([]*models.Song, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetSimilarArtists(ctx context.Context, artist models.Id) ([]*models.Artist, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetSimilarAlbums(ctx context.Context, album models.Id) ([]*models.Album, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetRecentlyPlayed(ctx context.Context, paging interfaces.Paging) ([]*models.Song, int, error) {
+	return nil, 0, ErrOffline
+}
+
+func (o *Offline) GetSongs(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Song, int, error) {
+	return nil, 0, ErrOffline
+}
+
+func (o *Offline) GetGenres(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
+	return nil, 0, ErrOffline
+}
+
+func (o *Offline) GetComposers(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
+	return nil, 0, ErrOffline
+}
+
+func (o *Offline) GetAlbumArtist(ctx context.Context, album *models.Album) (*models.Artist, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetInstantMix(ctx context.Context, item models.Item) ([]*models.Song, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetLink(item models.Item) string {
+	return ""
+}
+
+func (o *Offline) Search(ctx context.Context, query string, itemType models.ItemType, maxResults int) ([]models.Item, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetAlbum(ctx context.Context, id models.Id) (*models.Album, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetArtist(ctx context.Context, id models.Id) (*models.Artist, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetItemInfo(ctx context.Context, item models.Item) (*models.ItemInfo, error) {
+	return nil, ErrOffline
+}
+
+func (o *Offline) GetImageUrl(item models.Id, itemType models.ItemType) string {
+	return ""
+}
+
+func (o *Offline) GetInfo() (*models.ServerInfo, error) {
+	info := &models.ServerInfo{
+		ServerType: o.conf.GetType(),
+		Name:       "offline",
+		Id:         o.id,
+	}
+	return info, nil
+}
+
+func (o *Offline) ConnectionOk() error {
+	return ErrOffline
+}
+
+func (o *Offline) GetConfig() config.Backend {
+	return o.conf
+}
+
+func (o *Offline) ReportProgress(state *interfaces.ApiPlaybackState) error {
+	return nil
+}
+
+func (o *Offline) Start() error {
+	return nil
+}
+
+func (o *Offline) Stop() error {
+	return nil
+}
+
+func (o *Offline) GetId() string {
+	return o.id
+}
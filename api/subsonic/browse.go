@@ -19,17 +19,25 @@
 package subsonic
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
+	"tryffel.net/go/jellycli/config"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 )
 
 func (s *Subsonic) CanCacheSongs() bool { return false }
 
-func (s *Subsonic) getFavorites() error {
+func (s *Subsonic) getFavorites(ctx context.Context) error {
 	if len(s.favoriteAlbums) == 0 || len(s.favoriteArtists) == 0 {
-		resp, err := s.get("/getStarred2", nil)
+		resp, err := s.get(ctx, "/getStarred2", nil)
 		if err != nil {
 			return err
 		}
@@ -46,14 +54,42 @@ func (s *Subsonic) getFavorites() error {
 	return nil
 }
 
-func (s *Subsonic) GetArtists(query *interfaces.QueryOpts) (artists []*models.Artist, n int, err error) {
+// SetFavorite implements interfaces.FavoriteController. Subsonic calls favorites starred items.
+func (s *Subsonic) SetFavorite(item models.Item, favorite bool) error {
+	params := &params{}
+	switch item.GetType() {
+	case models.TypeAlbum:
+		(*params)["albumId"] = item.GetId().String()
+	case models.TypeArtist:
+		(*params)["artistId"] = item.GetId().String()
+	default:
+		params.setId(item.GetId().String())
+	}
+	url := "/star"
+	if !favorite {
+		url = "/unstar"
+	}
+	_, err := s.get(context.Background(), url, params)
+	if err != nil {
+		return fmt.Errorf("set favorite: %v", err)
+	}
+	// reload cached favorites on next request
+	s.favoriteAlbums = nil
+	s.favoriteArtists = nil
+	return nil
+}
+
+func (s *Subsonic) GetArtists(ctx context.Context, query *interfaces.QueryOpts) (artists []*models.Artist, n int, err error) {
 	if query.Filter.Favorite {
-		err := s.getFavorites()
+		err := s.getFavorites(ctx)
 		return s.favoriteArtists, len(s.favoriteArtists), err
 	}
+	if len(query.Filter.Genres) > 0 {
+		return s.getGenreArtists(ctx, query)
+	}
 
 	var resp *response
-	resp, err = s.get("/getArtists", nil)
+	resp, err = s.get(ctx, "/getArtists", nil)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -68,15 +104,62 @@ func (s *Subsonic) GetArtists(query *interfaces.QueryOpts) (artists []*models.Ar
 		}
 	}
 
+	// subsonic does not support filtering by name, filter artists here
+	if query.Filter.NameStartsWith != "" {
+		filtered := make([]*models.Artist, 0, len(artists))
+		for _, v := range artists {
+			if query.Filter.NameMatches(v.Name) {
+				filtered = append(filtered, v)
+			}
+		}
+		artists = filtered
+	}
+
 	return artists, len(artists), nil
 }
 
-func (s *Subsonic) GetAlbumArtists(query *interfaces.QueryOpts) ([]*models.Artist, int, error) {
-	return s.GetArtists(query)
+// getGenreArtists returns artists of genre albums, since subsonic does not support filtering
+// artists by genre. Only first genre is used.
+func (s *Subsonic) getGenreArtists(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Artist, int, error) {
+	params := &params{}
+	(*params)["type"] = "byGenre"
+	(*params)["genre"] = query.Filter.Genres[0].Name
+	albums, err := s.getAllAlbums(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	artists := []*models.Artist{}
+	ids := map[models.Id]*models.Artist{}
+	for _, album := range albums {
+		for _, v := range album.AdditionalArtists {
+			if v.Id == "" || !query.Filter.NameMatches(v.Name) {
+				continue
+			}
+			artist := ids[v.Id]
+			if artist == nil {
+				artist = &models.Artist{Id: v.Id, Name: v.Name}
+				ids[v.Id] = artist
+				artists = append(artists, artist)
+			}
+			artist.Albums = append(artist.Albums, album.Id)
+			artist.AlbumCount += 1
+		}
+	}
+	sort.Slice(artists, func(i, j int) bool {
+		return strings.ToLower(artists[i].Name) < strings.ToLower(artists[j].Name)
+	})
+
+	start, end := pageBounds(&query.Paging, len(artists))
+	return artists[start:end], len(artists), nil
+}
+
+func (s *Subsonic) GetAlbumArtists(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Artist, int, error) {
+	return s.GetArtists(ctx, query)
 }
 
-func (s *Subsonic) getAlbums(params *params) ([]*models.Album, error) {
-	resp, err := s.get("/getAlbumList2", params)
+func (s *Subsonic) getAlbums(ctx context.Context, params *params) ([]*models.Album, error) {
+	resp, err := s.get(ctx, "/getAlbumList2", params)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +172,10 @@ func (s *Subsonic) getAlbums(params *params) ([]*models.Album, error) {
 	return albums, nil
 }
 
-func (s *Subsonic) GetAlbums(opts *interfaces.QueryOpts) ([]*models.Album, int, error) {
+func (s *Subsonic) GetAlbums(ctx context.Context, opts *interfaces.QueryOpts) ([]*models.Album, int, error) {
+	if len(opts.Filter.Composers) > 0 {
+		return nil, 0, interfaces.ErrInvalidFilter
+	}
 	// subsonic does not support sorting and filtering at the same time
 	params := &params{}
 	(*params)["type"] = "alphabeticalByName"
@@ -120,17 +206,79 @@ func (s *Subsonic) GetAlbums(opts *interfaces.QueryOpts) ([]*models.Album, int,
 				(*params)["type"] = "recent"
 			case interfaces.SortByLatest:
 				(*params)["type"] = "newest"
+			case interfaces.SortByRating:
+				(*params)["type"] = "highest"
+			case interfaces.SortByReleaseDate:
+				// from newer to older year returns newest first
+				(*params)["type"] = "byYear"
+				(*params)["fromYear"] = strconv.Itoa(time.Now().Year())
+				(*params)["toYear"] = "0"
 			}
 		}
 	}
-	albums, err := s.getAlbums(params)
+	if opts.Filter.NameStartsWith != "" {
+		return s.getAlbumsByName(ctx, params, opts)
+	}
+	albums, err := s.getAlbums(ctx, params)
 	return albums, len(albums), err
 }
 
-func (s *Subsonic) GetArtistAlbums(artist models.Id) (albums []*models.Album, err error) {
+// maxAlbumListSize is the largest page getAlbumList2 returns.
+const maxAlbumListSize = 500
+
+// getAllAlbums gets all pages of albums matching params.
+func (s *Subsonic) getAllAlbums(ctx context.Context, params *params) ([]*models.Album, error) {
+	all := []*models.Album{}
+	page := interfaces.Paging{PageSize: maxAlbumListSize}
+	for {
+		params.setPaging(page)
+		albums, err := s.getAlbums(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, albums...)
+		// random list has no pages
+		if len(albums) < page.PageSize || (*params)["type"] == "random" {
+			return all, nil
+		}
+		page.CurrentPage++
+	}
+}
+
+// getAlbumsByName gets all albums matching params and filters them by name, since subsonic does not
+// support filtering albums by name. Paging is applied after filtering.
+func (s *Subsonic) getAlbumsByName(ctx context.Context, params *params, opts *interfaces.QueryOpts) ([]*models.Album, int, error) {
+	albums, err := s.getAllAlbums(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+	filtered := []*models.Album{}
+	for _, v := range albums {
+		if opts.Filter.NameMatches(v.Name) {
+			filtered = append(filtered, v)
+		}
+	}
+	start, end := pageBounds(&opts.Paging, len(filtered))
+	return filtered[start:end], len(filtered), nil
+}
+
+// pageBounds returns slice bounds of page for items filtered locally.
+func pageBounds(paging *interfaces.Paging, total int) (int, int) {
+	start := paging.Offset()
+	if start > total {
+		start = total
+	}
+	end := start + paging.PageSize
+	if paging.PageSize == 0 || end > total {
+		end = total
+	}
+	return start, end
+}
+
+func (s *Subsonic) GetArtistAlbums(ctx context.Context, artist models.Id) (albums []*models.Album, err error) {
 	params := &params{}
 	params.setId(artist.String())
-	resp, err := s.get("/getArtist", params)
+	resp, err := s.get(ctx, "/getArtist", params)
 	if err != nil {
 		return nil, err
 	}
@@ -143,12 +291,12 @@ func (s *Subsonic) GetArtistAlbums(artist models.Id) (albums []*models.Album, er
 
 }
 
-func (s *Subsonic) GetAlbumSongs(album models.Id) ([]*models.Song, error) {
+func (s *Subsonic) GetAlbumSongs(ctx context.Context, album models.Id) ([]*models.Song, error) {
 
 	params := &params{}
 	params.setId(album.String())
 
-	resp, err := s.get("/getAlbum", params)
+	resp, err := s.get(ctx, "/getAlbum", params)
 	if err != nil {
 		return nil, err
 	}
@@ -163,8 +311,8 @@ func (s *Subsonic) GetAlbumSongs(album models.Id) ([]*models.Song, error) {
 
 }
 
-func (s *Subsonic) GetPlaylists() ([]*models.Playlist, error) {
-	resp, err := s.get("/getPlaylists", nil)
+func (s *Subsonic) GetPlaylists(ctx context.Context) ([]*models.Playlist, error) {
+	resp, err := s.get(ctx, "/getPlaylists", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -175,10 +323,10 @@ func (s *Subsonic) GetPlaylists() ([]*models.Playlist, error) {
 	return playlists, nil
 }
 
-func (s *Subsonic) GetPlaylistSongs(playlist models.Id) ([]*models.Song, error) {
+func (s *Subsonic) GetPlaylistSongs(ctx context.Context, playlist models.Id) ([]*models.Song, error) {
 	params := &params{}
 	params.setId(playlist.String())
-	resp, err := s.get("/getPlaylist", params)
+	resp, err := s.get(ctx, "/getPlaylist", params)
 	if err != nil {
 		return nil, err
 	}
@@ -189,25 +337,84 @@ func (s *Subsonic) GetPlaylistSongs(playlist models.Id) ([]*models.Song, error)
 	return songs, nil
 }
 
-func (s *Subsonic) GetSimilarArtists(artist models.Id) ([]*models.Artist, error) {
+func (s *Subsonic) GetSimilarArtists(ctx context.Context, artist models.Id) ([]*models.Artist, error) {
+	params := &params{}
+	params.setId(artist.String())
+	(*params)["count"] = "20"
+	// only artists that exist in library
+	(*params)["includeNotPresent"] = "false"
 
-	return nil, errors.New("not implemented")
+	resp, err := s.get(ctx, "/getArtistInfo2", params)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ArtistInfo == nil {
+		return []*models.Artist{}, nil
+	}
+	artists := make([]*models.Artist, len(resp.ArtistInfo.SimilarArtists))
+	for i, v := range resp.ArtistInfo.SimilarArtists {
+		artists[i] = v.toArtist()
+	}
+	return artists, nil
 }
 
-func (s *Subsonic) GetSimilarAlbums(album models.Id) ([]*models.Album, error) {
+func (s *Subsonic) GetSimilarAlbums(ctx context.Context, album models.Id) ([]*models.Album, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (s *Subsonic) GetRecentlyPlayed(paging interfaces.Paging) ([]*models.Song, int, error) {
+func (s *Subsonic) GetRecentlyPlayed(ctx context.Context, paging interfaces.Paging) ([]*models.Song, int, error) {
 	return nil, 0, errors.New("not implemented")
 }
 
-func (s *Subsonic) GetSongs(query *interfaces.QueryOpts) ([]*models.Song, int, error) {
-	return nil, 0, errors.New("not implemented")
+func (s *Subsonic) GetSongs(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Song, int, error) {
+	var results []child
+	if len(query.Filter.Genres) > 0 {
+		params := &params{}
+		(*params)["genre"] = query.Filter.Genres[0].Name
+		(*params)["count"] = strconv.Itoa(query.Paging.PageSize)
+		(*params)["offset"] = strconv.Itoa(query.Paging.Offset())
+
+		resp, err := s.get(ctx, "/getSongsByGenre", params)
+		if err != nil {
+			return nil, 0, err
+		}
+		if resp.SongsByGenre != nil {
+			results = resp.SongsByGenre.Songs
+		}
+	} else {
+		// subsonic has no endpoint for listing songs, but empty search returns all songs
+		// with e.g. Navidrome and Gonic.
+		params := &params{}
+		(*params)["query"] = ""
+		(*params)["artistCount"] = "0"
+		(*params)["albumCount"] = "0"
+		(*params)["songCount"] = strconv.Itoa(query.Paging.PageSize)
+		(*params)["songOffset"] = strconv.Itoa(query.Paging.Offset())
+
+		resp, err := s.get(ctx, "/search3", params)
+		if err != nil {
+			return nil, 0, err
+		}
+		if resp.Search != nil {
+			results = resp.Search.Songs
+		}
+	}
+
+	songs := make([]*models.Song, len(results))
+	for i, v := range results {
+		songs[i] = v.toSong()
+	}
+
+	// total is not known, allow next page as long as pages are full
+	total := query.Paging.Offset() + len(songs)
+	if len(songs) == query.Paging.PageSize {
+		total += 1
+	}
+	return songs, total, nil
 }
 
-func (s *Subsonic) GetGenres(paging interfaces.Paging) ([]*models.IdName, int, error) {
-	resp, err := s.get("/getGenres", nil)
+func (s *Subsonic) GetGenres(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
+	resp, err := s.get(ctx, "/getGenres", nil)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -218,18 +425,22 @@ func (s *Subsonic) GetGenres(paging interfaces.Paging) ([]*models.IdName, int, e
 	return genres, len(genres), nil
 }
 
+func (s *Subsonic) GetComposers(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
 func (s *Subsonic) GetGenreAlbums(genre models.IdName) ([]*models.Album, error) {
 	params := &params{}
 	(*params)["type"] = "byGenre"
 	(*params)["genre"] = genre.Name
-	albums, err := s.getAlbums(params)
+	albums, err := s.getAlbums(context.Background(), params)
 	return albums, err
 }
 
-func (s *Subsonic) GetAlbumArtist(album *models.Album) (*models.Artist, error) {
+func (s *Subsonic) GetAlbumArtist(ctx context.Context, album *models.Album) (*models.Artist, error) {
 	params := &params{}
 	params.setId(album.Artist.String())
-	resp, err := s.get("/getArtist", params)
+	resp, err := s.get(ctx, "/getArtist", params)
 	if err != nil {
 		return nil, err
 	}
@@ -242,12 +453,16 @@ func (s *Subsonic) GetAlbumArtist(album *models.Album) (*models.Artist, error) {
 	return artist, nil
 }
 
-func (s *Subsonic) GetInstantMix(item models.Item) ([]*models.Song, error) {
+func (s *Subsonic) GetInstantMix(ctx context.Context, item models.Item) ([]*models.Song, error) {
+	if item.GetType() == models.TypeGenre {
+		return s.getGenreMix(ctx, item.GetName())
+	}
+
 	params := &params{}
 	params.setId(item.GetId().String())
-	(*params)["count"] = "200"
+	(*params)["count"] = strconv.Itoa(config.InstantMixSize)
 
-	resp, err := s.get("/getSimilarSongs", params)
+	resp, err := s.get(ctx, "/getSimilarSongs", params)
 	if err != nil {
 		return nil, err
 	}
@@ -261,11 +476,71 @@ func (s *Subsonic) GetInstantMix(item models.Item) ([]*models.Song, error) {
 	return songs, nil
 }
 
+// getGenreMix returns random songs from genre.
+func (s *Subsonic) getGenreMix(ctx context.Context, genre string) ([]*models.Song, error) {
+	params := &params{}
+	(*params)["genre"] = genre
+	(*params)["size"] = "200"
+
+	resp, err := s.get(ctx, "/getRandomSongs", params)
+	if err != nil {
+		return nil, err
+	}
+
+	songs := make([]*models.Song, len(resp.RandomSongs.Songs))
+	for i, v := range resp.RandomSongs.Songs {
+		songs[i] = v.toSong()
+	}
+	return songs, nil
+}
+
+// GetArtistAppearsOn is not supported by subsonic, it always returns empty list.
+func (s *Subsonic) GetArtistAppearsOn(ctx context.Context, artist models.Id) ([]*models.Album, error) {
+	return []*models.Album{}, nil
+}
+
+func (s *Subsonic) GetArtistTopSongs(ctx context.Context, artist *models.Artist, limit int) ([]*models.Song, error) {
+	params := &params{}
+	(*params)["artist"] = artist.Name
+	(*params)["count"] = strconv.Itoa(limit)
+
+	resp, err := s.get(ctx, "/getTopSongs", params)
+	if err != nil {
+		return nil, err
+	}
+	if resp.TopSongs == nil {
+		return []*models.Song{}, nil
+	}
+
+	songs := make([]*models.Song, len(resp.TopSongs.Songs))
+	for i, v := range resp.TopSongs.Songs {
+		songs[i] = v.toSong()
+	}
+	return songs, nil
+}
+
+// biography may contain html links
+var htmlTagRe = regexp.MustCompile("<[^>]*>")
+
+func (s *Subsonic) GetArtistOverview(ctx context.Context, artist *models.Artist) (string, error) {
+	params := &params{}
+	params.setId(artist.Id.String())
+
+	resp, err := s.get(ctx, "/getArtistInfo2", params)
+	if err != nil {
+		return "", err
+	}
+	if resp.ArtistInfo == nil {
+		return "", nil
+	}
+	return htmlTagRe.ReplaceAllString(resp.ArtistInfo.Biography, ""), nil
+}
+
 func (s *Subsonic) GetLink(item models.Item) string {
 	return ""
 }
 
-func (s *Subsonic) Search(query string, itemType models.ItemType, maxResults int) ([]models.Item, error) {
+func (s *Subsonic) Search(ctx context.Context, query string, itemType models.ItemType, maxResults int) ([]models.Item, error) {
 	params := &params{}
 	(*params)["query"] = query
 	(*params)["artistCount"] = "0"
@@ -283,7 +558,7 @@ func (s *Subsonic) Search(query string, itemType models.ItemType, maxResults int
 		(*params)["songCount"] = limit
 	}
 
-	resp, err := s.get("/search3", params)
+	resp, err := s.get(ctx, "/search3", params)
 	if err != nil {
 		return nil, err
 	}
@@ -314,24 +589,60 @@ func (s *Subsonic) Search(query string, itemType models.ItemType, maxResults int
 	return items, nil
 }
 
-func (s *Subsonic) GetAlbum(id models.Id) (*models.Album, error) {
+func (s *Subsonic) GetAlbum(ctx context.Context, id models.Id) (*models.Album, error) {
 	params := &params{}
 	params.setId(id.String())
 
-	resp, err := s.get("/getAlbum", params)
+	resp, err := s.get(ctx, "/getAlbum", params)
 	if err != nil {
 		return nil, err
 	}
 
 	album := resp.Albums.toAlbum()
+
+	// album notes are optional
+	info, err := s.get(ctx, "/getAlbumInfo2", params)
+	if err != nil {
+		logrus.Debugf("get album info: %v", err)
+	} else if info.AlbumInfo != nil {
+		album.Overview = htmlTagRe.ReplaceAllString(info.AlbumInfo.Notes, "")
+	}
 	return album, nil
 }
 
-func (s *Subsonic) GetArtist(id models.Id) (*models.Artist, error) {
+func (s *Subsonic) GetItemInfo(ctx context.Context, item models.Item) (*models.ItemInfo, error) {
+	params := &params{}
+	params.setId(item.GetId().String())
+
+	switch item.GetType() {
+	case models.TypeSong:
+		resp, err := s.get(ctx, "/getSong", params)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Song == nil {
+			return nil, errors.New("no song in response")
+		}
+		return resp.Song.toInfo(), nil
+	case models.TypeAlbum:
+		resp, err := s.get(ctx, "/getAlbum", params)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Albums == nil {
+			return nil, errors.New("no album in response")
+		}
+		return resp.Albums.toInfo(), nil
+	default:
+		return nil, fmt.Errorf("no info for item type %s", item.GetType())
+	}
+}
+
+func (s *Subsonic) GetArtist(ctx context.Context, id models.Id) (*models.Artist, error) {
 	params := &params{}
 	params.setId(id.String())
 
-	resp, err := s.get("/getArtist", params)
+	resp, err := s.get(ctx, "/getArtist", params)
 	if err != nil {
 		return nil, err
 	}
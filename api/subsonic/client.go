@@ -22,6 +22,7 @@
 package subsonic
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/json"
 	"errors"
@@ -46,6 +47,10 @@ type Subsonic struct {
 	user       string
 	apiversion string
 	client     string
+	httpClient *http.Client
+	// requests makes api requests, httpClient is used directly for streams.
+	requests   *api.Client
+	connection *api.ConnectionMonitor
 
 	connectionStatus string
 	connectionError  *subError
@@ -60,18 +65,38 @@ type Subsonic struct {
 }
 
 func (s *Subsonic) Stream(Song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
+	return s.stream(Song, api.MaxBitrate())
+}
+
+// GetStreamUrl implements api.StreamLinker.
+func (s *Subsonic) GetStreamUrl(Song *models.Song) string {
+	return api.EncodeUrl(s.host+"/rest/stream", *s.streamParams(Song, api.MaxBitrate()))
+}
+
+// streamParams returns authenticated parameters for streaming song.
+// If maxBitrate (kbps) is set, server transcodes songs with higher bitrate.
+func (s *Subsonic) streamParams(Song *models.Song, maxBitrate int) *params {
 	params := &params{}
 	params.setId(Song.Id.String())
-	(*params)["estimateContentLength"] = "true"
+	if maxBitrate > 0 {
+		(*params)["maxBitRate"] = strconv.Itoa(maxBitrate)
+	}
 	(*params)["s"] = s.salt
 	(*params)["t"] = s.token
 	(*params)["u"] = s.user
 	(*params)["c"] = s.client
 	(*params)["v"] = s.apiversion
+	return params
+}
+
+// stream streams song. If maxBitrate (kbps) is set, server transcodes songs with higher bitrate.
+func (s *Subsonic) stream(Song *models.Song, maxBitrate int) (io.ReadCloser, interfaces.AudioFormat, error) {
+	params := s.streamParams(Song, maxBitrate)
+	(*params)["estimateContentLength"] = "true"
 
 	url := s.host + "/rest/stream"
 
-	stream, err := api.NewStreamDownload(url, nil, *params, http.DefaultClient, Song.Duration)
+	stream, err := api.NewStreamDownload(url, nil, *params, s.httpClient, Song.Duration)
 	if err != nil {
 		return nil, interfaces.AudioFormatNil, err
 	}
@@ -81,7 +106,7 @@ func (s *Subsonic) Stream(Song *models.Song) (io.ReadCloser, interfaces.AudioFor
 }
 
 func (s *Subsonic) Download(Song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
-	return s.Stream(Song)
+	return s.stream(Song, 0)
 }
 
 func (s *Subsonic) GetInfo() (*models.ServerInfo, error) {
@@ -89,7 +114,7 @@ func (s *Subsonic) GetInfo() (*models.ServerInfo, error) {
 		ServerType: "Subsonic",
 	}
 
-	resp, err := s.get("/ping", nil)
+	resp, err := s.get(context.Background(), "/ping", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -100,6 +125,16 @@ func (s *Subsonic) GetInfo() (*models.ServerInfo, error) {
 	return info, nil
 }
 
+// GetRequestStats implements api.RequestStatistics.
+func (s *Subsonic) GetRequestStats() models.RequestStats {
+	return s.requests.Stats()
+}
+
+// AddConnectionCallback implements interfaces.ConnectionNotifier.
+func (s *Subsonic) AddConnectionCallback(cb func(online bool)) {
+	s.connection.AddConnectionCallback(cb)
+}
+
 func (s *Subsonic) ConnectionOk() error {
 	if s.connectionError != nil {
 		return fmt.Errorf("subsonic error: (%d): %s", s.connectionError.Code, s.connectionError.Message)
@@ -116,6 +151,13 @@ func NewSubsonic(conf *config.Subsonic, provider config.KeyValueProvider) (*Subs
 		apiversion: "1.16.1",
 		client:     "Jellycli",
 	}
+	httpClient, err := api.NewHttpClient(0)
+	if err != nil {
+		return s, err
+	}
+	s.httpClient = httpClient
+	s.requests = api.NewClient(httpClient)
+	s.connection = api.NewConnectionMonitor(s.checkConnection)
 
 	if s.host == "" {
 		host, err := provider.Get("subsonic.url", false, "Subsonic host")
@@ -129,7 +171,7 @@ func NewSubsonic(conf *config.Subsonic, provider config.KeyValueProvider) (*Subs
 		}
 	}
 
-	err := s.checkConnection()
+	err = s.checkConnection()
 	if err != nil {
 		loginErr := s.login(provider)
 		if loginErr != nil {
@@ -143,7 +185,7 @@ func NewSubsonic(conf *config.Subsonic, provider config.KeyValueProvider) (*Subs
 		}
 	}
 
-	resp, err := s.get("/getMusicFolders", nil)
+	resp, err := s.get(context.Background(), "/getMusicFolders", nil)
 	if err != nil {
 		return s, fmt.Errorf("get music folders: %v", err)
 	}
@@ -161,10 +203,11 @@ func NewSubsonic(conf *config.Subsonic, provider config.KeyValueProvider) (*Subs
 	return s, nil
 }
 
-func (s *Subsonic) get(url string, params *params) (*response, error) {
+// get makes GET request to subsonic api. Request is cancelled when ctx is cancelled.
+func (s *Subsonic) get(ctx context.Context, url string, params *params) (*response, error) {
 	fullUrl := s.host + "/rest" + url
 	start := time.Now()
-	req, _ := http.NewRequest(http.MethodGet, fullUrl, nil)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, fullUrl, nil)
 
 	q := req.URL.Query()
 	q.Add("s", s.salt)
@@ -182,9 +225,13 @@ func (s *Subsonic) get(url string, params *params) (*response, error) {
 
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := s.requests.Do(req)
 	took := time.Now().Sub(start)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		s.connection.RequestFailed(err)
 		logrus.Warningf("Get %s failed", "/rest"+url)
 		return nil, err
 	}
@@ -206,7 +253,7 @@ func (s *Subsonic) get(url string, params *params) (*response, error) {
 }
 
 func (s *Subsonic) checkConnection() error {
-	resp, err := s.get("/ping", nil)
+	resp, err := s.get(context.Background(), "/ping", nil)
 	if err != nil {
 		if resp != nil {
 			s.connectionError = resp.Error
@@ -269,21 +316,39 @@ func (s *Subsonic) ReportProgress(state *interfaces.ApiPlaybackState) (err error
 		s.songScrobbled = false
 	}
 
-	if state.Event == interfaces.EventTimeUpdate && models.Id(state.ItemId) == s.currentSong {
-		if state.Position > 5 && !s.songScrobbled {
-			params := &params{}
-			params.setId(s.currentSong.String())
-			_, err := s.get("/scrobble", params)
+	// song might stop before next time update, e.g. if it's short
+	playing := state.Event == interfaces.EventTimeUpdate || state.Event == interfaces.EventStop
+	if playing && models.Id(state.ItemId) == s.currentSong {
+		if state.Position.Seconds() > 5 && !s.songScrobbled {
+			_, err := s.get(context.Background(), "/scrobble", &params{"id": s.currentSong.String()})
 			if err != nil {
 				logrus.Errorf("Scrobble song: %v", err)
 			} else {
 				s.songScrobbled = true
 			}
 		}
+		// failed scrobble is retried on next time update, but song that stops unscrobbled is left for player
+		// to queue
+		if state.Event == interfaces.EventStop && state.Position.Seconds() > 5 && !s.songScrobbled {
+			return api.ErrNotScrobbled
+		}
 	}
 	return
 }
 
+// Scrobble implements api.Scrobbler.
+func (s *Subsonic) Scrobble(song models.Id, played time.Time) error {
+	params := &params{
+		"id":   song.String(),
+		"time": strconv.FormatInt(played.UnixNano()/int64(time.Millisecond), 10),
+	}
+	_, err := s.get(context.Background(), "/scrobble", params)
+	if err != nil {
+		return fmt.Errorf("scrobble song: %v", err)
+	}
+	return nil
+}
+
 func (s *Subsonic) Start() error {
 	return nil
 }
@@ -293,5 +358,14 @@ func (s *Subsonic) Stop() error {
 }
 
 func (s *Subsonic) GetId() string {
-	return fmt.Sprintf("%x", md5.Sum([]byte(s.host+s.user)))
+	return serverId(s.host, s.user)
+}
+
+func serverId(host, user string) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(host+user)))
+}
+
+// NewOffline returns offline server that shares id with Subsonic server in conf.
+func NewOffline(conf *config.Subsonic) *api.Offline {
+	return api.NewOffline(serverId(conf.Url, conf.Username), conf)
 }
@@ -20,6 +20,8 @@ package subsonic
 
 import (
 	"fmt"
+	"github.com/sirupsen/logrus"
+	"time"
 	"tryffel.net/go/jellycli/models"
 )
 
@@ -88,6 +90,12 @@ type response struct {
 	Playlist      *playlistSongs `json:"playlist,omitempty"`
 	Genres        *genres        `json:"genres"`
 	SimilarSongs  *similarSongs  `json:"similarSongs,omitempty"`
+	RandomSongs   *similarSongs  `json:"randomSongs,omitempty"`
+	SongsByGenre  *similarSongs  `json:"songsByGenre,omitempty"`
+	TopSongs      *similarSongs  `json:"topSongs,omitempty"`
+	ArtistInfo    *artistInfo    `json:"artistInfo2,omitempty"`
+	AlbumInfo     *albumInfo     `json:"albumInfo,omitempty"`
+	Song          *child         `json:"song,omitempty"`
 }
 
 type musicFolder struct {
@@ -138,10 +146,25 @@ type album struct {
 	Year      int    `json:"year"`
 	Duration  int    `json:"duration"`
 	Starred   string `json:"starred"`
+	Genre     string `json:"genre"`
+	PlayCount int    `json:"playCount"`
+	Created   string `json:"created"`
+	// OpenSubsonic extensions
+	Genres     []itemGenre `json:"genres"`
+	DiscTitles []discTitle `json:"discTitles"`
+}
+
+type itemGenre struct {
+	Name string `json:"name"`
+}
+
+type discTitle struct {
+	Disc  int    `json:"disc"`
+	Title string `json:"title"`
 }
 
 func (a *album) toAlbum() *models.Album {
-	return &models.Album{
+	album := &models.Album{
 		Id:                models.Id(a.Id),
 		Name:              a.Name,
 		Year:              a.Year,
@@ -154,6 +177,50 @@ func (a *album) toAlbum() *models.Album {
 		DiscCount:         1,
 		Favorite:          a.Starred != "",
 	}
+
+	// genre id is its name
+	if len(a.Genres) > 0 {
+		album.Genres = make([]models.IdName, len(a.Genres))
+		for i, v := range a.Genres {
+			album.Genres[i] = models.IdName{Id: models.Id(v.Name), Name: v.Name}
+		}
+	} else if a.Genre != "" {
+		album.Genres = []models.IdName{{Id: models.Id(a.Genre), Name: a.Genre}}
+	}
+
+	if len(a.DiscTitles) > 0 {
+		album.DiscTitles = make(map[int]string, len(a.DiscTitles))
+		for _, v := range a.DiscTitles {
+			album.DiscTitles[v.Disc] = v.Title
+		}
+	}
+	return album
+}
+
+func (a *album) toInfo() *models.ItemInfo {
+	info := &models.ItemInfo{
+		Id:        models.Id(a.Id),
+		Type:      models.TypeAlbum,
+		Name:      a.Name,
+		Artists:   []models.IdName{{Id: models.Id(a.ArtistId), Name: a.Artist}},
+		Genres:    a.toAlbum().Genres,
+		PlayCount: a.PlayCount,
+		DateAdded: parseTime(a.Created),
+	}
+	return info
+}
+
+// parse subsonic timestamp. Return zero time if timestamp is empty or invalid.
+func parseTime(timestamp string) time.Time {
+	if timestamp == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		logrus.Warningf("parse timestamp '%s': %v", timestamp, err)
+		return time.Time{}
+	}
+	return t
 }
 
 type artistAlbums struct {
@@ -185,6 +252,42 @@ type child struct {
 	ArtistId   string `json:"artistId"`
 	Type       string `json:"type"`
 	SongCount  int    `json:"songCount"`
+	Size       int64  `json:"size"`
+	Suffix     string `json:"suffix"`
+	BitRate    int    `json:"bitRate"`
+	Path       string `json:"path"`
+	PlayCount  int    `json:"playCount"`
+	UserRating int    `json:"userRating"`
+	Created    string `json:"created"`
+	Genre      string `json:"genre"`
+	// OpenSubsonic extension
+	Played          string        `json:"played"`
+	SamplingRate    int           `json:"samplingRate"`
+	DisplayComposer string        `json:"displayComposer"`
+	Contributors    []contributor `json:"contributors"`
+}
+
+// contributor is an OpenSubsonic extension that describes artist's role in a song.
+type contributor struct {
+	Role   string `json:"role"`
+	Artist struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"artist"`
+}
+
+// composers returns song composers from contributors, or from display composer if there are none.
+func (c *child) composers() []models.IdName {
+	var composers []models.IdName
+	for _, v := range c.Contributors {
+		if v.Role == "composer" {
+			composers = append(composers, models.IdName{Id: models.Id(v.Artist.Id), Name: v.Artist.Name})
+		}
+	}
+	if len(composers) == 0 && c.DisplayComposer != "" {
+		composers = []models.IdName{{Name: c.DisplayComposer}}
+	}
+	return composers
 }
 
 func (c *child) toAlbum() *models.Album {
@@ -213,9 +316,40 @@ func (c *child) toSong() *models.Song {
 		Artists:     nil,
 		AlbumArtist: models.Id(c.ArtistId),
 		Favorite:    false,
+		Size:        c.Size,
+		Codec:       c.Suffix,
+		Bitrate:     c.BitRate,
+		Composers:   c.composers(),
+		AlbumName:   c.Album,
+		Year:        c.Year,
+		PlayCount:   c.PlayCount,
+		Rating:      c.UserRating,
+		LastPlayed:  parseTime(c.Played),
 	}
 }
 
+func (c *child) toInfo() *models.ItemInfo {
+	info := &models.ItemInfo{
+		Id:         models.Id(c.Id),
+		Type:       models.TypeSong,
+		Name:       c.Title,
+		Album:      models.IdName{Id: models.Id(c.AlbumId), Name: c.Album},
+		Artists:    []models.IdName{{Id: models.Id(c.ArtistId), Name: c.Artist}},
+		Path:       c.Path,
+		Codec:      c.Suffix,
+		Bitrate:    c.BitRate,
+		SampleRate: c.SamplingRate,
+		Size:       c.Size,
+		PlayCount:  c.PlayCount,
+		DateAdded:  parseTime(c.Created),
+	}
+	// genre id is its name
+	if c.Genre != "" {
+		info.Genres = []models.IdName{{Id: models.Id(c.Genre), Name: c.Genre}}
+	}
+	return info
+}
+
 type searchResp struct {
 	Artists []artist `json:"artist,omitempty"`
 	Albums  []album  `json:"album,omitempty"`
@@ -271,3 +405,12 @@ func (g *genre) toGenre() *models.IdName {
 type similarSongs struct {
 	Songs []child `json:"song"`
 }
+
+type artistInfo struct {
+	Biography      string   `json:"biography"`
+	SimilarArtists []artist `json:"similarArtist"`
+}
+
+type albumInfo struct {
+	Notes string `json:"notes"`
+}
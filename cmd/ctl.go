@@ -0,0 +1,275 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"strings"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/control"
+	"tryffel.net/go/jellycli/mpris"
+)
+
+var (
+	ctlSocket  string
+	ctlJson    bool
+	ctlNext    bool
+	ctlReplace bool
+	ctlType    string
+	ctlId      string
+)
+
+var ctlCmd = &cobra.Command{
+	Use:   "ctl",
+	Short: "Control running jellycli",
+	Long: `Control jellycli that is running headless (--no-gui) or has control socket enabled,
+e.g. from window manager keybindings and scripts.
+
+Commands talk to control socket, which speaks json-rpc 1.0 with methods such as 'Player.PlayPause'.
+If socket is not available, playback commands fall back to MPRIS on session bus.`,
+}
+
+// ctlActions are playback commands without arguments. Mpris is method used when control socket is not available.
+var ctlActions = []struct {
+	use    string
+	short  string
+	method string
+	mpris  string
+}{
+	{use: "play-pause", short: "Toggle pause", method: "Player.PlayPause", mpris: "PlayPause"},
+	{use: "play", short: "Continue paused song", method: "Player.Continue", mpris: "Play"},
+	{use: "pause", short: "Pause song", method: "Player.Pause", mpris: "Pause"},
+	{use: "stop", short: "Stop playback", method: "Player.Stop", mpris: "Stop"},
+	{use: "next", short: "Play next song", method: "Player.Next", mpris: "Next"},
+	{use: "previous", short: "Play previous song", method: "Player.Previous", mpris: "Previous"},
+	{use: "mute", short: "Toggle mute", method: "Player.ToggleMute"},
+}
+
+var ctlStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print current song and player state",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		status := &control.Status{}
+		ctlRun(ctlCall("Player.Status", control.Empty{}, status), status)
+	},
+}
+
+var ctlSeekCmd = &cobra.Command{
+	Use:   "seek <seconds>",
+	Short: "Seek forward, or backwards with e.g. 'seek -- -10'",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		seconds := ctlNumber(args[0])
+		ctlRun(ctlCall("Player.Seek", control.SeekArgs{Seconds: seconds}, &control.Empty{}), nil)
+	},
+}
+
+var ctlVolumeCmd = &cobra.Command{
+	Use:   "volume <0-100>",
+	Short: "Set volume",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		volume := ctlNumber(args[0])
+		ctlRun(ctlCall("Player.SetVolume", control.VolumeArgs{Volume: volume}, &control.Empty{}), nil)
+	},
+}
+
+var ctlShuffleCmd = &cobra.Command{
+	Use:       "shuffle <on|off>",
+	Short:     "Enable or disable shuffle",
+	Args:      cobra.ExactValidArgs(1),
+	ValidArgs: []string{"on", "off"},
+	Run: func(cmd *cobra.Command, args []string) {
+		enabled := control.ShuffleArgs{Enabled: args[0] == "on"}
+		ctlRun(ctlCall("Player.SetShuffle", enabled, &control.Empty{}), nil)
+	},
+}
+
+var ctlSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search library. Prints type, id and name of each item",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		items := &[]control.Item{}
+		query := control.SearchArgs{Type: ctlType, Query: strings.Join(args, " ")}
+		ctlRun(ctlCall("Library.Search", query, items), items)
+	},
+}
+
+var ctlQueueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Print queue, first song is current song",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		songs := &[]control.Song{}
+		ctlRun(ctlCall("Queue.Get", control.Empty{}, songs), songs)
+	},
+}
+
+var ctlQueueAddCmd = &cobra.Command{
+	Use:   "add <query>",
+	Short: "Search item and add first result to queue",
+	Long: `Search item and add first result to queue. Artists and albums are added with all their songs.
+Limit search with --type, or add item directly with --type and --id.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if ctlId != "" {
+			if ctlType == "" {
+				return fmt.Errorf("--id requires --type")
+			}
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		item := control.Item{Type: ctlType, Id: ctlId}
+		if item.Id == "" {
+			items := []control.Item{}
+			query := control.SearchArgs{Type: ctlType, Query: strings.Join(args, " ")}
+			ctlRun(ctlCall("Library.Search", query, &items), nil)
+			if len(items) == 0 {
+				ctlRun(fmt.Errorf("no results for '%s'", query.Query), nil)
+			}
+			item = items[0]
+		}
+
+		added := 0
+		queue := control.QueueArgs{Type: item.Type, Id: item.Id, Next: ctlNext, Replace: ctlReplace}
+		err := ctlCall("Queue.Add", queue, &added)
+		name := item.Name
+		if name == "" {
+			name = item.Id
+		}
+		ctlRun(err, fmt.Sprintf("Added %d songs from %s '%s'", added, strings.ToLower(item.Type), name))
+	},
+}
+
+var ctlQueueClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove upcoming songs from queue",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctlRun(ctlCall("Queue.Clear", control.Empty{}, &control.Empty{}), nil)
+	},
+}
+
+var ctlQueueRemoveCmd = &cobra.Command{
+	Use:   "remove <index>",
+	Short: "Remove song from queue, current song is 0",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		index := control.IndexArgs{Index: ctlNumber(args[0])}
+		ctlRun(ctlCall("Queue.Remove", index, &control.Empty{}), nil)
+	},
+}
+
+func init() {
+	ctlCmd.PersistentFlags().StringVar(&ctlSocket, "socket", "", "control socket, defaults to player.control_socket")
+	ctlCmd.PersistentFlags().BoolVar(&ctlJson, "json", false, "print reply as json")
+	ctlSearchCmd.Flags().StringVar(&ctlType, "type", "", "item type: artist, album, song or playlist")
+	ctlQueueAddCmd.Flags().StringVar(&ctlType, "type", "", "item type: artist, album, song or playlist")
+	ctlQueueAddCmd.Flags().StringVar(&ctlId, "id", "", "add item with id instead of searching")
+	ctlQueueAddCmd.Flags().BoolVar(&ctlNext, "next", false, "play songs next")
+	ctlQueueAddCmd.Flags().BoolVar(&ctlReplace, "replace", false, "replace queue and start playing")
+
+	for _, v := range ctlActions {
+		action := v
+		ctlCmd.AddCommand(&cobra.Command{
+			Use:   action.use,
+			Short: action.short,
+			Args:  cobra.NoArgs,
+			Run: func(cmd *cobra.Command, args []string) {
+				err := ctlCall(action.method, control.Empty{}, &control.Empty{})
+				if err == control.ErrNotRunning && action.mpris != "" {
+					err = mpris.CallPlayer(action.mpris)
+				}
+				ctlRun(err, nil)
+			},
+		})
+	}
+
+	ctlQueueCmd.AddCommand(ctlQueueAddCmd, ctlQueueClearCmd, ctlQueueRemoveCmd)
+	ctlCmd.AddCommand(ctlStatusCmd, ctlSeekCmd, ctlVolumeCmd, ctlShuffleCmd, ctlSearchCmd, ctlQueueCmd)
+	rootCmd.AddCommand(ctlCmd)
+}
+
+// ctlCall calls method in control socket. Socket is read from config, if not set with flag.
+func ctlCall(method string, args interface{}, reply interface{}) error {
+	if ctlSocket == "" {
+		initConfig()
+		ctlSocket = config.AppConfig.Player.ControlSocket
+	}
+	return control.Call(ctlSocket, method, args, reply)
+}
+
+// ctlRun prints reply, or exits if there is error.
+func ctlRun(err error, reply interface{}) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if reply == nil {
+		return
+	}
+	if ctlJson {
+		data, _ := json.MarshalIndent(reply, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	switch v := reply.(type) {
+	case string:
+		fmt.Println(v)
+	case *control.Status:
+		fmt.Printf("State: %s\n", v.State)
+		if v.Song != nil {
+			fmt.Printf("Song: %s (%s)\n", v.Song.Name, strings.Join(v.Song.Artists, ", "))
+			fmt.Printf("Album: %s\n", v.Album)
+			fmt.Printf("Position: %s / %s\n", formatSeconds(v.Position), formatSeconds(v.Song.Duration))
+		}
+		fmt.Printf("Volume: %d%%, muted: %t, shuffle: %t\n", v.Volume, v.Muted, v.Shuffle)
+		fmt.Printf("Queue: %d songs\n", v.Queue)
+	case *[]control.Song:
+		for i, song := range *v {
+			fmt.Printf("%d\t%s\t%s\t%s\t%s\n", i, song.Id, formatSeconds(song.Duration), song.Name,
+				strings.Join(song.Artists, ", "))
+		}
+	case *[]control.Item:
+		for _, item := range *v {
+			fmt.Printf("%s\t%s\t%s\n", strings.ToLower(item.Type), item.Id, item.Name)
+		}
+	}
+}
+
+// ctlNumber parses integer argument, or exits if it's invalid.
+func ctlNumber(arg string) int {
+	value, err := strconv.Atoi(arg)
+	if err != nil {
+		ctlRun(fmt.Errorf("invalid number '%s'", arg), nil)
+	}
+	return value
+}
+
+func formatSeconds(seconds int) string {
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
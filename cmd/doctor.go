@@ -0,0 +1,160 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"strings"
+	"time"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/player"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect config file",
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check config for problems and suggest fixes",
+	Long: `Check config file for unknown keys, invalid values, conflicting key bindings,
+unreachable servers and missing audio output. Config file is not modified.
+Exit code is 1 if any errors are found.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		problems := doctor()
+		if printProblems(problems) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// doctor reads config without saving it and returns found problems.
+func doctor() []config.Problem {
+	setupViper()
+	fmt.Printf("Config file: %s\n", viper.ConfigFileUsed())
+	if err := viper.ReadInConfig(); err != nil {
+		return []config.Problem{{
+			Error:   true,
+			Message: fmt.Sprintf("read config file: %v", err),
+			Fix:     "run jellycli to create config file, or fix yaml syntax",
+		}}
+	}
+
+	problems := config.UnknownKeys(viper.AllKeys())
+	// reading empty config would write defaults to file
+	if viper.GetString("jellyfin.url") == "" && viper.GetString("subsonic.url") == "" &&
+		viper.GetString("ampache.url") == "" {
+		return append(problems, config.Problem{
+			Error:   true,
+			Message: "no server configured",
+			Fix:     "run jellycli to set up server",
+		})
+	}
+
+	err := config.ConfigFromViper()
+	if err != nil {
+		return append(problems, config.Problem{Error: true, Message: err.Error()})
+	}
+	problems = append(problems, config.InvalidValues()...)
+	checks := config.AppConfig.Check()
+	problems = append(problems, checks...)
+	for _, v := range checks {
+		if v.Error {
+			// server checks need valid config
+			return problems
+		}
+	}
+
+	for _, backend := range []string{config.AppConfig.Player.Server, config.AppConfig.Player.SecondaryServer} {
+		if backend == "" {
+			continue
+		}
+		fmt.Printf("Connecting to %s...\n", backend)
+		if problem := checkServerReachable(backend); problem != nil {
+			problems = append(problems, *problem)
+		}
+	}
+
+	if err := player.CheckAudio(); err != nil {
+		problems = append(problems, config.Problem{
+			Error:   true,
+			Message: fmt.Sprintf("no audio output: %v", err),
+			Fix: "make sure sound device is available (e.g. docker run --device /dev/snd) " +
+				"and on linux, alsa libraries (libasound2) are installed",
+		})
+	}
+	return problems
+}
+
+func checkServerReachable(backend string) *config.Problem {
+	client, err := api.NewHttpClient(time.Second * 10)
+	if err != nil {
+		// tls errors are reported by config check
+		return nil
+	}
+	resp, err := client.Get(config.AppConfig.ServerUrl(backend))
+	if err != nil {
+		return &config.Problem{
+			Error:   true,
+			Key:     strings.ToLower(backend) + ".url",
+			Message: fmt.Sprintf("cannot reach server: %v", err),
+			Fix: "check that server is running and url is correct. " +
+				"For self-signed certificate, set player.tls_ca_file",
+		}
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// printProblems prints problems and returns number of errors.
+func printProblems(problems []config.Problem) int {
+	errors := 0
+	for _, v := range problems {
+		level := "warning"
+		if v.Error {
+			level = "error"
+			errors++
+		}
+		key := ""
+		if v.Key != "" {
+			key = v.Key + ": "
+		}
+		fmt.Printf("%-8s %s%s\n", level, key, v.Message)
+		if v.Fix != "" {
+			fmt.Printf("%-8s fix: %s\n", "", v.Fix)
+		}
+	}
+	if len(problems) == 0 {
+		fmt.Println("No problems found")
+	} else {
+		fmt.Printf("Found %d problems, %d errors\n", len(problems), errors)
+	}
+	return errors
+}
@@ -36,34 +36,99 @@ JELLYCLI_JELLYFIN_USERID
 JELLYCLI_JELLYFIN_DEVICE_ID
 JELLYCLI_JELLYFIN_SERVER_ID
 JELLYCLI_JELLYFIN_MUSIC_VIEW
+JELLYCLI_JELLYFIN_PODCAST_VIEW
 
 JELLYCLI_SUBSONIC_URL
 JELLYCLI_SUBSONIC_USERNAME
 JELLYCLI_SUBSONIC_SALT
 JELLYCLI_SUBSONIC_TOKEN
 
+JELLYCLI_AMPACHE_URL
+JELLYCLI_AMPACHE_API_KEY
+
 JELLYCLI_PLAYER_SERVER
+JELLYCLI_PLAYER_SECONDARY_SERVER
+JELLYCLI_PLAYER_STREAM_PREFERENCE
 JELLYCLI_PLAYER_LOGFILE
 JELLYCLI_PLAYER_LOGLEVEL
 JELLYCLI_PLAYER_HTTP_BUFFERING_S
 JELLYCLI_PLAYER_HTTP_BUFFERING_LIMIT_MEM
+JELLYCLI_PLAYER_BUFFER_WHOLE_TRACK
 JELLYCLI_PLAYER_AUDIO_BUFFERING_MS
 JELLYCLI_PLAYER_ENABLE_REMOTE_CONTROL
+JELLYCLI_PLAYER_ENABLE_MEDIA_KEYS
+JELLYCLI_PLAYER_ENABLE_CONTROL_SOCKET
+JELLYCLI_PLAYER_CONTROL_SOCKET
+JELLYCLI_PLAYER_HTTP_REMOTE_ADDRESS
+JELLYCLI_PLAYER_HTTP_REMOTE_TOKEN
+JELLYCLI_PLAYER_METRICS_ADDRESS
+JELLYCLI_PLAYER_NOW_PLAYING_FILE
+JELLYCLI_PLAYER_NOW_PLAYING_FORMAT
+JELLYCLI_PLAYER_ENABLE_HISTORY_DB
 JELLYCLI_PLAYER_ENABLE_LOCAL_CACHE
 JELLYCLI_PLAYER_ENABLE_LOCAL_CACHE_DIR
+JELLYCLI_PLAYER_DOWNLOAD_QUOTA_MB
+JELLYCLI_PLAYER_COVER_CACHE_MB
+JELLYCLI_PLAYER_MAX_BITRATE_KBPS
+JELLYCLI_PLAYER_PREVIOUS_RESTART_S
+JELLYCLI_PLAYER_METADATA_CACHE_TTL_MIN
+JELLYCLI_PLAYER_PREFETCH_LIBRARY
+JELLYCLI_PLAYER_USE_KEYRING
+JELLYCLI_PLAYER_TLS_CA_FILE
+JELLYCLI_PLAYER_TLS_SKIP_VERIFY
+JELLYCLI_PLAYER_TLS_CLIENT_CERT
+JELLYCLI_PLAYER_TLS_CLIENT_KEY
+JELLYCLI_PLAYER_INSTANT_MIX_SIZE
+JELLYCLI_PLAYER_INSTANT_MIX_SKIP_PLAYED
 
 JELLYCLI_GUI_PAGESIZE
+JELLYCLI_GUI_INFINITE_SCROLL
 JELLYCLI_GUI_DEBUG_MODE
 JELLYCLI_GUI_LIMIT_RECENTLY_PLAYED
 JELLYCLI_GUI_MOUSE_ENABLED
+JELLYCLI_GUI_MOUSE_SINGLE_CLICK_PLAY
+JELLYCLI_GUI_MOUSE_RIGHT_CLICK
+JELLYCLI_GUI_MOUSE_SCROLL_LINES
+JELLYCLI_GUI_MOUSE_IGNORE_WIDGETS
 JELLYCLI_GUI_DOUBLE_CLICK_MS
 JELLYCLI_GUI_SEARCH_RESULTS_LIMIT
 JELLYCLI_GUI_SEARCH_TYPES
 JELLYCLI_GUI_VOLUME_STEPS
+JELLYCLI_GUI_VOLUME_STEP
+JELLYCLI_GUI_SEEK_STEP_S
+JELLYCLI_GUI_SEEK_REPEAT_MS
+JELLYCLI_GUI_SEEK_ACCEL_AFTER_MS
+JELLYCLI_GUI_SEEK_ACCEL_STEPS_S
 
 JELLYCLI_GUI_ENABLE_SORTING
 JELLYCLI_GUI_ENABLE_FILTERING
 JELLYCLI_GUI_ENABLE_RESULTS_FILTERING
+JELLYCLI_GUI_SORT_ARTISTS
+JELLYCLI_GUI_SORT_ALBUMS
+JELLYCLI_GUI_SORT_SONGS
+JELLYCLI_GUI_STARTUP_VIEW
+JELLYCLI_GUI_LAST_VIEW
+JELLYCLI_GUI_NAVIGATION_WIDTH
+JELLYCLI_GUI_NAVIGATION_HIDDEN
+JELLYCLI_GUI_QUEUE_DOCKED
+JELLYCLI_GUI_ALBUM_GRID
+JELLYCLI_GUI_ART_PLACEHOLDERS
+JELLYCLI_GUI_STATUS_FORMAT
+JELLYCLI_GUI_SHOW_REMAINING_TIME
+JELLYCLI_GUI_SHOW_END_CLOCK
+JELLYCLI_GUI_ENABLE_VISUALIZER
+JELLYCLI_GUI_ENABLE_TERMINAL_TITLE
+JELLYCLI_GUI_TERMINAL_TITLE_FORMAT
+JELLYCLI_GUI_COPY_FORMAT
+JELLYCLI_GUI_THEME
+JELLYCLI_GUI_COLOR_MODE
+JELLYCLI_GUI_LANGUAGE
+JELLYCLI_GUI_ASCII_ONLY
+JELLYCLI_GUI_ACCESSIBLE_MODE
+
+JELLYCLI_HOOKS_ON_SONG_CHANGE
+JELLYCLI_HOOKS_ON_PAUSE
+JELLYCLI_HOOKS_ON_QUEUE_EMPTY
 
 # Additional environment variables
 JELLYCLI_JELLYFIN_PASSWORD
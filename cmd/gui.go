@@ -23,18 +23,29 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"io"
+	"net/http"
 	"os"
 	"os/signal"
+	"path"
 	"strings"
 	"syscall"
 	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/api/ampache"
+	"tryffel.net/go/jellycli/api/hybrid"
 	"tryffel.net/go/jellycli/api/jellyfin"
 	"tryffel.net/go/jellycli/api/subsonic"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/control"
+	"tryffel.net/go/jellycli/hooks"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/mediakeys"
+	"tryffel.net/go/jellycli/metrics"
 	"tryffel.net/go/jellycli/mpris"
+	"tryffel.net/go/jellycli/nowplaying"
 	"tryffel.net/go/jellycli/player"
 	"tryffel.net/go/jellycli/task"
 	"tryffel.net/go/jellycli/ui"
+	"tryffel.net/go/jellycli/util"
 )
 
 type app struct {
@@ -43,6 +54,12 @@ type app struct {
 	player      *player.Player
 	mpris       *mpris.MediaController
 	mprisPlayer *mpris.Player
+	mediaKeys   *mediakeys.MediaKeys
+	control     *control.Server
+	metrics     *http.Server
+	hooks       *hooks.Hooks
+	nowPlaying  *nowplaying.Writer
+	title       *nowplaying.TerminalTitle
 	logfile     *os.File
 }
 
@@ -67,7 +84,17 @@ func initApplication() (*app, error) {
 
 	logrus.Infof("############# %s v%s ############", config.AppName, config.Version)
 
-	err = a.initServerConnection()
+	if config.IsNewConfig() && !disableGui && strings.ToLower(config.AppConfig.Player.Server) == "jellyfin" {
+		// keep log messages from drawing over setup
+		logrus.SetOutput(a.logfile)
+		err = ui.RunSetup()
+		if err != nil {
+			logrus.Fatalf("first-run setup: %v", err)
+		}
+		logrus.SetOutput(writer)
+	}
+
+	err = a.initServerConnection(true)
 	if err != nil {
 		logrus.Fatalf("connect to server: %v", err)
 	}
@@ -96,41 +123,98 @@ func initApplication() (*app, error) {
 	return a, nil
 }
 
-func (a *app) initServerConnection() error {
-	var err error
-	switch strings.ToLower(config.AppConfig.Player.Server) {
+func newServer(backend string) (api.MediaServer, error) {
+	switch strings.ToLower(backend) {
 	case "jellyfin":
-		a.server, err = jellyfin.NewJellyfin(&config.AppConfig.Jellyfin, &config.ViperStdConfigProvider{})
+		return jellyfin.NewJellyfin(&config.AppConfig.Jellyfin, &config.ViperStdConfigProvider{})
 	case "subsonic":
-		a.server, err = subsonic.NewSubsonic(&config.AppConfig.Subsonic, &config.ViperStdConfigProvider{})
+		return subsonic.NewSubsonic(&config.AppConfig.Subsonic, &config.ViperStdConfigProvider{})
+	case "ampache":
+		return ampache.NewAmpache(&config.AppConfig.Ampache, &config.ViperStdConfigProvider{})
 	default:
-		return fmt.Errorf("unsupported backend: '%s'", config.AppConfig.Player.Server)
+		return nil, fmt.Errorf("unsupported backend: '%s'", backend)
 	}
+}
+
+// initServerConnection connects to configured servers. If allowOffline is set and local cache is enabled,
+// servers that cannot be reached are replaced with offline servers and application runs on local cache.
+func (a *app) initServerConnection(allowOffline bool) error {
+	var err error
+	a.server, err = connectServer(config.AppConfig.Player.Server, allowOffline)
 	if err != nil {
-		return fmt.Errorf("api init: %v", err)
+		return err
+	}
+
+	secondaryBackend := config.AppConfig.Player.SecondaryServer
+	if secondaryBackend == "" {
+		return nil
 	}
-	if err := a.server.ConnectionOk(); err != nil {
-		return fmt.Errorf("no connection to server: %v", err)
+
+	// secondary server is optional, continue without it if it's not available
+	secondary, err := connectServer(secondaryBackend, allowOffline)
+	if err != nil {
+		logrus.Errorf("connect to secondary server %s: %v", secondaryBackend, err)
+		return nil
 	}
 
-	conf := a.server.GetConfig()
-	if config.AppConfig.Player.Server == "jellyfin" {
-		jfConfig, ok := conf.(*config.Jellyfin)
-		if ok {
-			config.AppConfig.Jellyfin = *jfConfig
-		}
-	} else if config.AppConfig.Player.Server == "subsonic" {
-		subConfig, ok := conf.(*config.Subsonic)
-		if ok {
-			config.AppConfig.Subsonic = *subConfig
-		}
+	a.server, err = hybrid.NewHybrid([]api.MediaServer{a.server, secondary}, config.AppConfig.Player.StreamPreference)
+	if err != nil {
+		return fmt.Errorf("hybrid library: %v", err)
 	}
 	return nil
+}
 
+// connectServer connects to backend. If that fails and allowOffline is set,
+// offline server is returned for browsing previously synced local cache.
+func connectServer(backend string, allowOffline bool) (api.MediaServer, error) {
+	server, err := newServer(backend)
+	if err != nil {
+		err = fmt.Errorf("api init: %v", err)
+	} else if connErr := server.ConnectionOk(); connErr != nil {
+		err = fmt.Errorf("no connection to server: %v", connErr)
+	}
+	if err == nil {
+		config.SetBackendConfig(server.GetConfig())
+		return server, nil
+	}
+
+	if !allowOffline || !config.AppConfig.Player.EnableLocalCache {
+		return nil, err
+	}
+	offline, offlineErr := newOfflineServer(backend)
+	if offlineErr != nil {
+		logrus.Errorf("offline mode: %v", offlineErr)
+		return nil, err
+	}
+	logrus.Warningf("%s: %v. Continue in offline mode", backend, err)
+	return offline, nil
+}
+
+// newOfflineServer returns offline server for backend, which must have been connected before.
+func newOfflineServer(backend string) (api.MediaServer, error) {
+	var server *api.Offline
+	switch strings.ToLower(backend) {
+	case "jellyfin":
+		server = jellyfin.NewOffline(&config.AppConfig.Jellyfin)
+	case "subsonic":
+		server = subsonic.NewOffline(&config.AppConfig.Subsonic)
+	case "ampache":
+		server = ampache.NewOffline(&config.AppConfig.Ampache)
+	default:
+		return nil, fmt.Errorf("unsupported backend: '%s'", backend)
+	}
+	if server.GetId() == "" {
+		return nil, fmt.Errorf("%s has not been connected yet", backend)
+	}
+	return server, nil
 }
 
 func (a *app) initGui() {
 	if !disableGui {
+		err := i18n.Load(config.AppConfig.Gui.Language, path.Join(path.Dir(config.ConfigFile), "locales"))
+		if err != nil {
+			logrus.Errorf("load translations: %v", err)
+		}
 		a.gui = ui.NewUi(a.player)
 	}
 }
@@ -149,11 +233,16 @@ func (a *app) initApp() error {
 			return fmt.Errorf("initialize dbus connection: %v", err)
 		}
 	} else {
-		a.mprisPlayer = &mpris.Player{
-			MediaController: a.mpris,
-		}
+		a.mprisPlayer = a.mpris.Player()
 		a.player.AddStatusCallback(a.mprisPlayer.UpdateStatus)
 	}
+
+	if config.AppConfig.Player.EnableMediaKeys {
+		a.mediaKeys, err = mediakeys.NewMediaKeys(a.player)
+		if err != nil {
+			logrus.Errorf("enable media keys: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -176,16 +265,92 @@ func (a *app) run() {
 		}
 	}
 
+	a.initControl()
+	a.initMetrics()
+	if conf := config.AppConfig.Hooks; conf != (config.Hooks{}) {
+		a.hooks = hooks.NewHooks(conf, a.player, a.player)
+	}
+	if file := config.AppConfig.Player.NowPlayingFile; file != "" {
+		a.nowPlaying = nowplaying.NewWriter(file, config.AppConfig.Player.NowPlayingFormat, a.player)
+	}
+
 	if !disableGui {
 		logrus.SetOutput(a.logfile)
+		if config.AppConfig.Gui.EnableTerminalTitle {
+			a.title = nowplaying.NewTerminalTitle(config.AppConfig.Gui.TerminalTitleFormat, os.Stdout, a.player)
+		}
 		go a.stopOnSignal()
 		err = a.gui.Start()
 		if err != nil {
 			logrus.Errorf("start gui: %v", err)
 		}
 	} else {
-		logrus.Info("Waiting for commands from server")
-		a.stopOnSignal()
+		// keep stdout for command responses
+		logrus.SetOutput(io.MultiWriter(a.logfile, os.Stderr))
+		logrus.Info("Waiting for commands from server, control socket and stdin")
+		quit := make(chan bool, 1)
+		go a.readCommands(quit)
+		select {
+		case <-catchSignals():
+		case <-quit:
+		}
+	}
+}
+
+// readCommands serves commands from stdin until stdin is closed. If user quits, notify quit.
+func (a *app) readCommands(quit chan bool) {
+	if a.control == nil {
+		return
+	}
+	stop, err := a.control.ServeCommands(os.Stdin, os.Stdout)
+	if err != nil {
+		logrus.Errorf("read commands from stdin: %v", err)
+	}
+	if stop {
+		quit <- true
+	}
+}
+
+// initControl serves control api in socket and http, if enabled.
+func (a *app) initControl() {
+	conf := &config.AppConfig.Player
+	socket := disableGui || conf.EnableControlSocket
+	if !socket && conf.HttpRemoteAddress == "" {
+		return
+	}
+	a.control = control.NewServer(a.player, a.player, a.player)
+	if socket {
+		if err := a.control.ListenSocket(conf.ControlSocket); err != nil {
+			logrus.Errorf("control api: %v", err)
+		}
+	}
+	if conf.HttpRemoteAddress == "" {
+		return
+	}
+	if conf.HttpRemoteToken == "" {
+		conf.HttpRemoteToken = util.RandomKey(32)
+		if err := config.SaveConfig(); err != nil {
+			logrus.Errorf("save http remote token: %v", err)
+		}
+	}
+	if err := a.control.ListenHttp(conf.HttpRemoteAddress, conf.HttpRemoteToken); err != nil {
+		logrus.Errorf("http remote: %v", err)
+	}
+}
+
+// initMetrics serves metrics, if enabled.
+func (a *app) initMetrics() {
+	if config.AppConfig.Player.MetricsAddress == "" {
+		return
+	}
+	metrics.WatchPlayer(a.player)
+	if stats, ok := a.server.(api.RequestStatistics); ok {
+		metrics.WatchRequests(stats.GetRequestStats)
+	}
+	var err error
+	a.metrics, err = metrics.Listen(config.AppConfig.Player.MetricsAddress)
+	if err != nil {
+		logrus.Errorf("metrics: %v", err)
 	}
 }
 
@@ -199,6 +364,12 @@ func (a *app) stopOnSignal() {
 
 func (a *app) stop() error {
 	logrus.Info("Stopping application")
+	if a.player != nil {
+		a.player.RememberVolume()
+		if err := config.SaveConfig(); err != nil {
+			logrus.Errorf("save volume: %v", err)
+		}
+	}
 	tasks := []task.Tasker{a.player, a.server}
 	var err error
 	var hasError bool
@@ -212,6 +383,31 @@ func (a *app) stop() error {
 	if !disableGui {
 		a.gui.Stop()
 	}
+	if a.control != nil {
+		if err := a.control.Close(); err != nil {
+			logrus.Errorf("close control socket: %v", err)
+		}
+	}
+	if a.hooks != nil {
+		a.hooks.Close()
+	}
+	if a.nowPlaying != nil {
+		a.nowPlaying.Close()
+	}
+	if a.title != nil {
+		a.title.Close()
+	}
+	if a.metrics != nil {
+		if err := a.metrics.Close(); err != nil {
+			logrus.Errorf("close metrics: %v", err)
+		}
+		a.metrics = nil
+	}
+	if a.mediaKeys != nil {
+		if err := a.mediaKeys.Close(); err != nil {
+			logrus.Errorf("close media keys: %v", err)
+		}
+	}
 
 	if err != nil || hasError {
 		logrus.Errorf("stop application: %v", err)
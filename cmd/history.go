@@ -0,0 +1,114 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"time"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/history"
+	"tryffel.net/go/jellycli/storage"
+)
+
+var (
+	historyFormat string
+	historyOutput string
+	historySince  string
+	historyStats  bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Export listening history or stats",
+	Long: `Export listening history recorded to local database with player.enable_history_db as csv or json.
+Each play has start time, song, song duration, seconds listened and share of song listened. With --stats, export play count and total listening time for each song,
+most played first.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		initConfig()
+		err := exportHistory()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export history: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func exportHistory() error {
+	if historyFormat != "csv" && historyFormat != "json" {
+		return fmt.Errorf("unknown format '%s', supported: csv, json", historyFormat)
+	}
+	since := time.Time{}
+	if historySince != "" {
+		var err error
+		since, err = time.ParseInLocation("2006-01-02", historySince, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid date, use format YYYY-MM-DD: %v", err)
+		}
+	}
+	plays, err := readHistory(since)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if historyOutput != "" {
+		fd, err := os.OpenFile(historyOutput, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer fd.Close()
+		out = fd
+	}
+
+	switch {
+	case historyStats && historyFormat == "json":
+		return history.WriteJSON(out, history.SongStats(plays))
+	case historyStats:
+		return history.WriteStatsCSV(out, history.SongStats(plays))
+	case historyFormat == "json":
+		return history.WriteJSON(out, plays)
+	default:
+		return history.WritePlaysCSV(out, plays)
+	}
+}
+
+// readHistory reads plays since given time from local database.
+func readHistory(since time.Time) ([]*history.Play, error) {
+	if !config.AppConfig.Player.EnableHistoryDb {
+		return nil, errors.New("player.enable_history_db is disabled")
+	}
+	db, err := storage.NewHistory()
+	if err != nil {
+		return nil, fmt.Errorf("open play history: %v", err)
+	}
+	defer db.Close()
+	return db.GetPlays(since)
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyFormat, "format", "csv", "output format: csv or json")
+	historyCmd.Flags().StringVarP(&historyOutput, "output", "o", "", "output file, defaults to stdout")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "only include plays since date, YYYY-MM-DD")
+	historyCmd.Flags().BoolVar(&historyStats, "stats", false, "export play count and listening time per song")
+	rootCmd.AddCommand(historyCmd)
+}
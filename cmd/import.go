@@ -0,0 +1,74 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"os"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/player"
+)
+
+var importName string
+
+var importCmd = &cobra.Command{
+	Use:   "import <playlist.m3u>",
+	Short: "Create server playlist from m3u file",
+	Long: `Create server playlist from m3u or m3u8 file. Songs are matched against library by artist and title
+in #EXTINF lines ('Artist - Title'), or by file name when there is no #EXTINF.
+Songs that are not found are listed. Currently Jellyfin supports creating playlists.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		initConfig()
+		logFile, err := initLogging()
+		if err != nil {
+			logrus.Fatalf("init logging: %v", err)
+		}
+		a := &app{logfile: logFile}
+		defer a.logfile.Close()
+
+		err = a.initServerConnection(false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "connect to server: %v\n", err)
+			os.Exit(1)
+		}
+		result, err := player.ImportM3U(a.server, args[0], importName)
+		a.server.Stop()
+		if result != nil {
+			for _, v := range result.Unmatched {
+				fmt.Printf("Not found: %s\n", v)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "import playlist: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Created playlist '%s' with %d songs, %d not found\n", result.Playlist.Name,
+			result.Playlist.SongCount, len(result.Unmatched))
+		if config.AppConfig.Player.EnableLocalCache {
+			fmt.Println("Run 'jellycli sync' to update local cache")
+		}
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importName, "name", "", "playlist name, defaults to name in file or file name")
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,123 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"io"
+	"os"
+	"strings"
+	"tryffel.net/go/jellycli/config"
+)
+
+var manCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Print man page",
+	Long: `Print jellycli(1) man page in roff format to stdout, e.g.
+
+  jellycli man > /usr/share/man/man1/jellycli.1`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		writeManPage(os.Stdout, rootCmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(manCmd)
+}
+
+// writeManPage writes man page for root command, including all subcommands and their flags.
+func writeManPage(w io.Writer, root *cobra.Command) {
+	name := root.Name()
+	fmt.Fprintf(w, ".TH %s 1 \"\" \"%s %s\" \"%s Manual\"\n", strings.ToUpper(name), name, config.Version,
+		config.AppName)
+	fmt.Fprintf(w, ".SH NAME\n%s \\- %s\n", name, manEscape(root.Short))
+	fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n[flags]\n.br\n.B %s\n<command> [flags]\n", name, name)
+	fmt.Fprintf(w, ".SH DESCRIPTION\n%s", manText(root.Long))
+	fmt.Fprintf(w, ".SH OPTIONS\n%s", manFlags(root.LocalFlags()))
+	fmt.Fprintln(w, ".SH COMMANDS")
+	writeManCommands(w, root)
+	fmt.Fprintf(w, ".SH ENVIRONMENT\nConfig options can be overridden with environment variables, "+
+		"see \\fB%s %s\\fR.\n", name, envCmd.Name())
+	fmt.Fprintf(w, ".SH FILES\n.TP\n.I ~/.config/%s/%s.yaml\nConfig file.\n", name, name)
+}
+
+// writeManCommands writes entry for each available subcommand of parent, recursively.
+func writeManCommands(w io.Writer, parent *cobra.Command) {
+	for _, cmd := range parent.Commands() {
+		if !cmd.IsAvailableCommand() || cmd.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		description := cmd.Long
+		if description == "" {
+			description = cmd.Short
+		}
+		fmt.Fprintf(w, ".TP\n.B %s\n%s", manEscape(cmd.CommandPath()+strings.TrimPrefix(cmd.Use, cmd.Name())),
+			manText(description))
+		if flags := manFlags(cmd.LocalFlags()); flags != "" {
+			fmt.Fprintf(w, ".sp\n%s", flags)
+		}
+		writeManCommands(w, cmd)
+	}
+}
+
+// manFlags formats visible flags as no-fill block, or returns empty string if there are none.
+func manFlags(flags *pflag.FlagSet) string {
+	text := ""
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden || flag.Name == "help" {
+			return
+		}
+		name := "    --" + flag.Name
+		if flag.Shorthand != "" {
+			name = "-" + flag.Shorthand + ", --" + flag.Name
+		}
+		if flag.Value.Type() != "bool" {
+			name += " " + flag.Value.Type()
+		}
+		text += fmt.Sprintf("%-30s %s\n", name, flag.Usage)
+	})
+	if text == "" {
+		return ""
+	}
+	return manText(text)
+}
+
+// manText formats text in no-fill mode to keep line breaks as they are in help texts.
+func manText(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+	return ".nf\n" + manEscape(text) + "\n.fi\n"
+}
+
+// manEscape escapes roff control characters.
+func manEscape(text string) string {
+	text = strings.ReplaceAll(text, "\\", "\\e")
+	text = strings.ReplaceAll(text, "-", "\\-")
+	lines := strings.Split(text, "\n")
+	for i, v := range lines {
+		if strings.HasPrefix(v, ".") || strings.HasPrefix(v, "'") {
+			lines[i] = "\\&" + v
+		}
+	}
+	return strings.Join(lines, "\n")
+}
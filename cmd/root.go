@@ -35,8 +35,10 @@ import (
 var cfgFile string
 
 var rootCmd = &cobra.Command{
+	Use:   "jellycli",
+	Short: "Terminal music player for Jellyfin, Subsonic and Ampache",
 	Long: `Jellycli is a terminal music player for
-Jellyfin and Subsonic-compatible servers.
+Jellyfin, Subsonic and Ampache-compatible servers.
 
 `,
 
@@ -59,28 +61,7 @@ func init() {
 }
 
 func initConfig() {
-	// default config dir is ~/.config/jellycli
-	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
-	} else {
-		configDir, err := os.UserConfigDir()
-		if err != nil {
-			logrus.Errorf("cannot determine config directory: %v", err)
-			configDir = ""
-		} else {
-			configDir = path.Join(configDir, "jellycli")
-		}
-
-		viper.AddConfigPath(configDir)
-		viper.SetConfigFile(path.Join(configDir, "jellycli.yaml"))
-	}
-
-	// env variables
-	replacer := strings.NewReplacer(".", "_")
-	viper.SetEnvPrefix("jellycli")
-	viper.SetEnvKeyReplacer(replacer)
-	viper.AutomaticEnv()
-
+	setupViper()
 	if err := viper.ReadInConfig(); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			err = config.NewConfigFile(cfgFile)
@@ -107,6 +88,31 @@ func initConfig() {
 	config.ConfigFile = file
 }
 
+// setupViper sets config file location and environment variables.
+func setupViper() {
+	// default config dir is ~/.config/jellycli
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+	} else {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			logrus.Errorf("cannot determine config directory: %v", err)
+			configDir = ""
+		} else {
+			configDir = path.Join(configDir, "jellycli")
+		}
+
+		viper.AddConfigPath(configDir)
+		viper.SetConfigFile(path.Join(configDir, "jellycli.yaml"))
+	}
+
+	// env variables
+	replacer := strings.NewReplacer(".", "_")
+	viper.SetEnvPrefix("jellycli")
+	viper.SetEnvKeyReplacer(replacer)
+	viper.AutomaticEnv()
+}
+
 func initLogging() (*os.File, error) {
 	level, err := logrus.ParseLevel(config.AppConfig.Player.LogLevel)
 	if err != nil {
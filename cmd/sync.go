@@ -26,9 +26,12 @@ import (
 	"tryffel.net/go/jellycli/config"
 )
 
-var refreshCmd = &cobra.Command{
-	Use:   "refresh",
-	Short: "Pull latest data from remote server and store to local cache",
+var syncCmd = &cobra.Command{
+	Use:     "sync",
+	Aliases: []string{"refresh"},
+	Short:   "Pull latest data from remote server and store to local cache",
+	Long: `Pull artists, albums, songs and playlists from remote server and store them to local cache.
+When server cannot be reached, jellycli browses synced cache and plays downloaded songs.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		disableGui = true
 		initConfig()
@@ -46,10 +49,10 @@ var refreshCmd = &cobra.Command{
 		logrus.Infof("############# %s v%s ############", config.AppName, config.Version)
 
 		if !config.AppConfig.Player.EnableLocalCache {
-			logrus.Fatalf("Local cache is disabled")
+			logrus.Fatalf("Local cache is disabled, set player.enable_local_cache = true")
 		}
 
-		err = a.initServerConnection()
+		err = a.initServerConnection(false)
 		if err != nil {
 			logrus.Fatalf("connect to server: %v", err)
 		}
@@ -99,5 +102,5 @@ var refreshCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.AddCommand(refreshCmd)
+	rootCmd.AddCommand(syncCmd)
 }
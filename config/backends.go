@@ -32,6 +32,8 @@ type Jellyfin struct {
 	DeviceId  string `yaml:"device_id"`
 	ServerId  string `yaml:"server_id"`
 	MusicView string `yaml:"music_view"`
+	// PodcastView is id or name of library that contains podcasts.
+	PodcastView string `yaml:"podcast_view"`
 }
 
 func (j *Jellyfin) DumpConfig() interface{} {
@@ -57,6 +59,31 @@ func (s *Subsonic) GetType() string {
 	return "subsonic"
 }
 
+type Ampache struct {
+	Url    string `yaml:"server_url"`
+	ApiKey string `yaml:"api_key"`
+}
+
+func (a *Ampache) DumpConfig() interface{} {
+	return a
+}
+
+func (a *Ampache) GetType() string {
+	return "ampache"
+}
+
+// SetBackendConfig updates backend config in AppConfig, so that it gets saved to config file.
+func SetBackendConfig(backend Backend) {
+	switch conf := backend.(type) {
+	case *Jellyfin:
+		AppConfig.Jellyfin = *conf
+	case *Subsonic:
+		AppConfig.Subsonic = *conf
+	case *Ampache:
+		AppConfig.Ampache = *conf
+	}
+}
+
 // KeyValueProvider provides means to request new values for outdated values,
 // to request new password or url.
 type KeyValueProvider interface {
@@ -20,6 +20,9 @@ package config
 
 import (
 	"github.com/gdamore/tcell"
+	"os"
+	"runtime"
+	"strings"
 	"tryffel.net/go/twidgets"
 )
 
@@ -35,6 +38,98 @@ const (
 
 var Color = defaultColors()
 
+// Color themes
+const (
+	ThemeDefault = "default"
+	ThemeLight   = "light"
+	// ThemeTerminal uses default colors with terminal background.
+	ThemeTerminal = "terminal"
+)
+
+// Themes lists available color themes.
+var Themes = []string{ThemeDefault, ThemeLight, ThemeTerminal}
+
+func isTheme(name string) bool {
+	for _, v := range Themes {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Color modes. Empty mode detects colors from terminal.
+const (
+	ColorModeAuto = ""
+	ColorMode256  = "256"
+	// ColorMode16 uses only basic 16 colors that all terminals support.
+	ColorMode16 = "16"
+	// ColorModeNone uses terminal default colors. Selected items are still highlighted with black and white.
+	ColorModeNone = "none"
+)
+
+// ColorModes lists available color modes.
+var ColorModes = []string{ColorModeAuto, ColorMode256, ColorMode16, ColorModeNone}
+
+func isColorMode(mode string) bool {
+	for _, v := range ColorModes {
+		if v == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectColorMode returns color mode supported by terminal. NO_COLOR disables colors,
+// see https://no-color.org. Windows console and basic terminals only support 16 colors.
+func DetectColorMode() string {
+	return detectColorMode(os.Getenv, runtime.GOOS)
+}
+
+func detectColorMode(getenv func(key string) string, goos string) string {
+	term := getenv("TERM")
+	if getenv("NO_COLOR") != "" || term == "dumb" {
+		return ColorModeNone
+	}
+	if getenv("COLORTERM") != "" || strings.Contains(term, "256color") {
+		return ColorMode256
+	}
+	// Windows terminal supports 256 colors, console doesn't
+	if goos == "windows" && getenv("WT_SESSION") == "" {
+		return ColorMode16
+	}
+	switch term {
+	case "linux", "ansi", "cygwin", "vt100", "vt102", "vt220":
+		return ColorMode16
+	}
+	return ColorMode256
+}
+
+// SetTheme sets Color to given theme with color mode. Unknown theme sets default colors
+// and empty color mode detects colors from terminal.
+// Theme must be set before creating widgets.
+func SetTheme(name string, mode string) {
+	if mode == ColorModeAuto {
+		mode = DetectColorMode()
+	}
+	switch {
+	case mode == ColorModeNone:
+		Color = noColors()
+	case mode == ColorMode16 && name == ThemeLight:
+		Color = basicLightColors()
+	case mode == ColorMode16 && name == ThemeTerminal:
+		Color = basicTerminalColors()
+	case mode == ColorMode16:
+		Color = basicColors()
+	case name == ThemeLight:
+		Color = lightColors()
+	case name == ThemeTerminal:
+		Color = terminalColors()
+	default:
+		Color = defaultColors()
+	}
+}
+
 type AppColor struct {
 	Background               tcell.Color
 	Border                   tcell.Color
@@ -53,6 +148,7 @@ type AppColor struct {
 	NavBar                   ColorNavBar
 	Status                   ColorStatus
 	Modal                    ColorModal
+	Notification             ColorNotification
 }
 
 func defaultColors() AppColor {
@@ -74,6 +170,7 @@ func defaultColors() AppColor {
 		NavBar:                   defaultColorNavBar(),
 		Status:                   defaultColorStatus(),
 		Modal:                    defaultColorModal(),
+		Notification:             defaultColorNotification(),
 	}
 }
 
@@ -147,3 +244,230 @@ func defaultColorStatus() ColorStatus {
 		VolumeMuted:      tcell.Color238,
 	}
 }
+
+type ColorNotification struct {
+	Background tcell.Color
+	Info       tcell.Color
+	Error      tcell.Color
+}
+
+func defaultColorNotification() ColorNotification {
+	return ColorNotification{
+		Background: colorBackground,
+		Info:       tcell.Color114,
+		Error:      tcell.Color203,
+	}
+}
+
+func lightColors() AppColor {
+	return AppColor{
+		Background:               tcell.Color255,
+		Border:                   tcell.Color246,
+		BorderFocus:              tcell.Color236,
+		ButtonBackground:         tcell.Color250,
+		ButtonBackgroundSelected: tcell.Color110,
+		ButtonLabel:              tcell.Color235,
+		ButtonLabelSelected:      tcell.Color232,
+		Text:                     tcell.Color235,
+		TextSecondary:            tcell.Color130,
+		TextDisabled:             tcell.Color245,
+		TextDisabled2:            tcell.Color243,
+		BackgroundSelected:       tcell.Color153,
+		TextSelected:             tcell.Color232,
+		TextSongPlaying:          tcell.Color166,
+		NavBar: ColorNavBar{
+			Background:       tcell.Color255,
+			Text:             tcell.Color235,
+			ButtonBackground: tcell.Color255,
+			Shortcut:         tcell.Color166,
+		},
+		Status: ColorStatus{
+			Background:       tcell.Color255,
+			Border:           tcell.Color246,
+			ProgressBar:      tcell.Color246,
+			Text:             tcell.Color235,
+			ButtonBackground: tcell.Color250,
+			ButtonLabel:      tcell.Color235,
+			Shortcuts:        tcell.Color166,
+			TextPrimary:      tcell.Color235,
+			TextSecondary:    tcell.Color25,
+			VolumeMuted:      tcell.Color250,
+		},
+		Modal: ColorModal{
+			Background: tcell.Color254,
+			Text:       tcell.Color235,
+			Headers:    tcell.Color130,
+		},
+		Notification: ColorNotification{
+			Background: tcell.Color255,
+			Info:       tcell.Color28,
+			Error:      tcell.Color160,
+		},
+	}
+}
+
+func terminalColors() AppColor {
+	c := defaultColors()
+	c.Background = tcell.ColorDefault
+	c.Text = tcell.ColorDefault
+	c.NavBar.Background = tcell.ColorDefault
+	c.NavBar.ButtonBackground = tcell.ColorDefault
+	c.NavBar.Text = tcell.ColorDefault
+	c.Status.Background = tcell.ColorDefault
+	c.Status.Text = tcell.ColorDefault
+	c.Status.TextPrimary = tcell.ColorDefault
+	c.Modal.Background = tcell.ColorDefault
+	c.Modal.Text = tcell.ColorDefault
+	c.Notification.Background = tcell.ColorDefault
+	return c
+}
+
+// basicColors is default theme with 16 colors.
+func basicColors() AppColor {
+	return AppColor{
+		Background:               tcell.ColorBlack,
+		Border:                   tcell.ColorGray,
+		BorderFocus:              tcell.ColorWhite,
+		ButtonBackground:         tcell.ColorGray,
+		ButtonBackgroundSelected: tcell.ColorTeal,
+		ButtonLabel:              tcell.ColorWhite,
+		ButtonLabelSelected:      tcell.ColorWhite,
+		Text:                     tcell.ColorSilver,
+		TextSecondary:            tcell.ColorOlive,
+		TextDisabled:             tcell.ColorGray,
+		TextDisabled2:            tcell.ColorGray,
+		BackgroundSelected:       tcell.ColorTeal,
+		TextSelected:             tcell.ColorWhite,
+		TextSongPlaying:          tcell.ColorYellow,
+		NavBar: ColorNavBar{
+			Background:       tcell.ColorBlack,
+			Text:             tcell.ColorSilver,
+			ButtonBackground: tcell.ColorBlack,
+			Shortcut:         tcell.ColorYellow,
+		},
+		Status: ColorStatus{
+			Background:       tcell.ColorBlack,
+			Border:           tcell.ColorGray,
+			ProgressBar:      tcell.ColorSilver,
+			Text:             tcell.ColorSilver,
+			ButtonBackground: tcell.ColorGray,
+			ButtonLabel:      tcell.ColorWhite,
+			Shortcuts:        tcell.ColorYellow,
+			TextPrimary:      tcell.ColorSilver,
+			TextSecondary:    tcell.ColorAqua,
+			VolumeMuted:      tcell.ColorGray,
+		},
+		Modal: ColorModal{
+			Background: tcell.ColorNavy,
+			Text:       tcell.ColorWhite,
+			Headers:    tcell.ColorYellow,
+		},
+		Notification: ColorNotification{
+			Background: tcell.ColorBlack,
+			Info:       tcell.ColorLime,
+			Error:      tcell.ColorRed,
+		},
+	}
+}
+
+// basicLightColors is light theme with 16 colors.
+func basicLightColors() AppColor {
+	return AppColor{
+		Background:               tcell.ColorWhite,
+		Border:                   tcell.ColorGray,
+		BorderFocus:              tcell.ColorBlack,
+		ButtonBackground:         tcell.ColorSilver,
+		ButtonBackgroundSelected: tcell.ColorTeal,
+		ButtonLabel:              tcell.ColorBlack,
+		ButtonLabelSelected:      tcell.ColorWhite,
+		Text:                     tcell.ColorBlack,
+		TextSecondary:            tcell.ColorMaroon,
+		TextDisabled:             tcell.ColorGray,
+		TextDisabled2:            tcell.ColorGray,
+		BackgroundSelected:       tcell.ColorTeal,
+		TextSelected:             tcell.ColorWhite,
+		TextSongPlaying:          tcell.ColorPurple,
+		NavBar: ColorNavBar{
+			Background:       tcell.ColorWhite,
+			Text:             tcell.ColorBlack,
+			ButtonBackground: tcell.ColorWhite,
+			Shortcut:         tcell.ColorMaroon,
+		},
+		Status: ColorStatus{
+			Background:       tcell.ColorWhite,
+			Border:           tcell.ColorGray,
+			ProgressBar:      tcell.ColorGray,
+			Text:             tcell.ColorBlack,
+			ButtonBackground: tcell.ColorSilver,
+			ButtonLabel:      tcell.ColorBlack,
+			Shortcuts:        tcell.ColorMaroon,
+			TextPrimary:      tcell.ColorBlack,
+			TextSecondary:    tcell.ColorNavy,
+			VolumeMuted:      tcell.ColorSilver,
+		},
+		Modal: ColorModal{
+			Background: tcell.ColorSilver,
+			Text:       tcell.ColorBlack,
+			Headers:    tcell.ColorMaroon,
+		},
+		Notification: ColorNotification{
+			Background: tcell.ColorWhite,
+			Info:       tcell.ColorGreen,
+			Error:      tcell.ColorRed,
+		},
+	}
+}
+
+// basicTerminalColors is terminal theme with 16 colors.
+func basicTerminalColors() AppColor {
+	c := basicColors()
+	c.Background = tcell.ColorDefault
+	c.Text = tcell.ColorDefault
+	c.NavBar.Background = tcell.ColorDefault
+	c.NavBar.ButtonBackground = tcell.ColorDefault
+	c.NavBar.Text = tcell.ColorDefault
+	c.Status.Background = tcell.ColorDefault
+	c.Status.Text = tcell.ColorDefault
+	c.Status.TextPrimary = tcell.ColorDefault
+	c.Modal.Background = tcell.ColorDefault
+	c.Modal.Text = tcell.ColorDefault
+	c.Notification.Background = tcell.ColorDefault
+	return c
+}
+
+// noColors uses terminal default colors, except selected items and buttons are
+// drawn in black and white to keep them visible.
+func noColors() AppColor {
+	d := tcell.ColorDefault
+	return AppColor{
+		Background:               d,
+		Border:                   d,
+		BorderFocus:              d,
+		ButtonBackground:         d,
+		ButtonBackgroundSelected: tcell.ColorWhite,
+		ButtonLabel:              d,
+		ButtonLabelSelected:      tcell.ColorBlack,
+		Text:                     d,
+		TextSecondary:            d,
+		TextDisabled:             d,
+		TextDisabled2:            d,
+		BackgroundSelected:       tcell.ColorWhite,
+		TextSelected:             tcell.ColorBlack,
+		TextSongPlaying:          d,
+		NavBar:                   ColorNavBar{Background: d, Text: d, ButtonBackground: d, Shortcut: d},
+		Status: ColorStatus{
+			Background:       d,
+			Border:           d,
+			ProgressBar:      d,
+			Text:             d,
+			ButtonBackground: d,
+			ButtonLabel:      d,
+			Shortcuts:        d,
+			TextPrimary:      d,
+			TextSecondary:    d,
+			VolumeMuted:      d,
+		},
+		Modal:        ColorModal{Background: d, Text: d, Headers: d},
+		Notification: ColorNotification{Background: d, Info: d, Error: d},
+	}
+}
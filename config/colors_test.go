@@ -0,0 +1,64 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/gdamore/tcell"
+	"testing"
+)
+
+func Test_detectColorMode(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		goos string
+		want string
+	}{
+		{name: "xterm", env: map[string]string{"TERM": "xterm-256color"}, goos: "linux", want: ColorMode256},
+		{name: "truecolor", env: map[string]string{"TERM": "xterm", "COLORTERM": "truecolor"}, goos: "linux",
+			want: ColorMode256},
+		{name: "linux console", env: map[string]string{"TERM": "linux"}, goos: "linux", want: ColorMode16},
+		{name: "windows console", env: map[string]string{}, goos: "windows", want: ColorMode16},
+		{name: "windows terminal", env: map[string]string{"WT_SESSION": "1"}, goos: "windows", want: ColorMode256},
+		{name: "no color", env: map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1"}, goos: "linux",
+			want: ColorModeNone},
+		{name: "dumb", env: map[string]string{"TERM": "dumb"}, goos: "linux", want: ColorModeNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := detectColorMode(getenv, tt.goos); got != tt.want {
+				t.Errorf("detectColorMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	defer SetTheme(ThemeDefault, ColorMode256)
+
+	SetTheme(ThemeDefault, ColorMode16)
+	if Color.Background != tcell.ColorBlack || Color.Text != tcell.ColorSilver {
+		t.Errorf("16 color theme not set")
+	}
+	SetTheme(ThemeLight, ColorModeNone)
+	if Color.Text != tcell.ColorDefault || Color.BackgroundSelected == Color.Background {
+		t.Errorf("selected items must be visible without colors")
+	}
+}
@@ -43,8 +43,18 @@ var configIsEmpty bool
 type Config struct {
 	Jellyfin Jellyfin `yaml:"jellyfin"`
 	Subsonic Subsonic `yaml:"subsonic"`
+	Ampache  Ampache  `yaml:"ampache"`
 	Player   Player   `yaml:"player"`
 	Gui      Gui      `yaml:"gui"`
+	Hooks    Hooks    `yaml:"hooks"`
+}
+
+// Hooks are run on player events. Each hook is either a shell command, or a http(s) url that
+// song metadata is posted to as json. Empty hook is disabled.
+type Hooks struct {
+	OnSongChange string `yaml:"on_song_change"`
+	OnPause      string `yaml:"on_pause"`
+	OnQueueEmpty string `yaml:"on_queue_empty"`
 }
 
 type Gui struct {
@@ -53,11 +63,31 @@ type Gui struct {
 	LimitRecentlyPlayed bool `yaml:"limit_recent_songs"`
 	MouseEnabled        bool `yaml:"enable_mouse"`
 	DoubleClickMs       int  `yaml:"mouse_double_click_interval_ms"`
+	// MouseSingleClickPlay plays or opens list item with single click instead of double click.
+	MouseSingleClickPlay bool `yaml:"mouse_single_click_play"`
+	// MouseRightClick is the action of right click in lists, one of MouseRightClickActions.
+	MouseRightClick string `yaml:"mouse_right_click"`
+	// MouseScrollLines is the number of list items to move with one scroll step.
+	MouseScrollLines int `yaml:"mouse_scroll_lines"`
+	// MouseIgnoreWidgets don't react to mouse, one of MouseWidgets.
+	MouseIgnoreWidgets []string `yaml:"mouse_ignore_widgets"`
 	// valid types: artist,album,song,playlist,genre
 	SearchTypes        []models.ItemType `yaml:"search_types"`
 	SearchResultsLimit int               `yaml:"search_results_limit"`
 
 	VolumeSteps int `yaml:"volume_steps"`
+	// VolumeStep is how many percents volume keys change volume. 0 divides volume to VolumeSteps steps.
+	VolumeStep int `yaml:"volume_step"`
+	// SeekStepS is how many seconds seek forward and backward keys seek.
+	SeekStepS int `yaml:"seek_step_s"`
+	// SeekRepeatMs is max interval between seek key presses for seek key to be considered held down.
+	// Negative value disables seek acceleration.
+	SeekRepeatMs int `yaml:"seek_repeat_ms"`
+	// SeekAccelAfterMs are times seek key has to be held down before seeking with corresponding
+	// step of SeekAccelStepsS.
+	SeekAccelAfterMs []int `yaml:"seek_accel_after_ms"`
+	// SeekAccelStepsS are steps in seconds to seek with while seek key is held down.
+	SeekAccelStepsS []int `yaml:"seek_accel_steps_s"`
 
 	// EnableSorting enables sorting on remote server
 	EnableSorting bool `yaml:"enable_sorting"`
@@ -65,30 +95,290 @@ type Gui struct {
 	EnableFiltering bool `yaml:"enable_filtering"`
 	// EnableResultsFiltering enables filtering existing results, 'search inside results'.
 	EnableResultsFiltering bool `yaml:"enable_results_filtering"`
+
+	// Last selected sorting per view, e.g. 'Name ASC'. Requires EnableSorting.
+	SortArtists string `yaml:"sort_artists"`
+	SortAlbums  string `yaml:"sort_albums"`
+	SortSongs   string `yaml:"sort_songs"`
+
+	// StartupView is the view to open on startup: albums, artists, playlists or last.
+	// Empty value opens navigation panel.
+	StartupView string `yaml:"startup_view"`
+	// LastView is the last opened view. It is only updated if StartupView is last.
+	LastView string `yaml:"last_view"`
+
+	// NavigationWidth is the width of navigation pane in columns. 0 uses default width.
+	NavigationWidth int `yaml:"navigation_width"`
+	// NavigationHidden hides navigation pane.
+	NavigationHidden bool `yaml:"navigation_hidden"`
+	// QueueDocked shows queue permanently on the right side of media view.
+	QueueDocked bool `yaml:"queue_docked"`
+	// AlbumGrid shows albums as cards in multiple columns instead of a single column list.
+	AlbumGrid bool `yaml:"album_grid"`
+	// ArtPlaceholders shows a block colored with dominant color of album or artist image next to them in lists.
+	ArtPlaceholders bool `yaml:"art_placeholders"`
+
+	// StatusFormat is the layout of song details in status bar, see config.sample.yaml for tokens.
+	// Empty value uses default layout.
+	StatusFormat string `yaml:"status_format"`
+	// SongColumns are the columns and their order in song lists per view. Keys are one of SongColumnViews
+	// and values are one of SongColumns. View without columns uses default layout.
+	SongColumns map[string][]string `yaml:"song_columns"`
+	// ShowRemainingTime shows remaining song time instead of elapsed time.
+	ShowRemainingTime bool `yaml:"show_remaining_time"`
+	// ShowEndClock shows wall-clock time when current song ends.
+	ShowEndClock bool `yaml:"show_end_clock"`
+	// EnableVisualizer shows audio spectrum in status bar.
+	EnableVisualizer bool `yaml:"enable_visualizer"`
+	// EnableTerminalTitle sets terminal window title to current song with TerminalTitleFormat.
+	EnableTerminalTitle bool   `yaml:"enable_terminal_title"`
+	TerminalTitleFormat string `yaml:"terminal_title_format"`
+	// CopyFormat is format of song or album info copied to clipboard.
+	CopyFormat string `yaml:"copy_format"`
+
+	// InfiniteScroll loads and appends next page when scrolling down from last item of list.
+	InfiniteScroll bool `yaml:"infinite_scroll"`
+
+	// Theme is the name of color scheme, one of Themes.
+	Theme string `yaml:"theme"`
+	// ColorMode limits colors to terminal capabilities, one of ColorModes. Empty value detects colors.
+	ColorMode string `yaml:"color_mode"`
+	// Language of user interface, e.g. 'fi'. Translations are read from locales/<language>.yaml
+	// in config directory. Empty value uses English.
+	Language string `yaml:"language"`
+	// AsciiOnly replaces unicode symbols and borders with ascii characters
+	// for terminals and fonts that don't render them well, e.g. Windows console.
+	AsciiOnly bool `yaml:"ascii_only"`
+	// AccessibleMode linearizes user interface for screen readers: only focused view is shown,
+	// borders are not drawn and state changes are announced on a dedicated line.
+	AccessibleMode bool `yaml:"accessible_mode"`
+}
+
+// Mouse right click actions
+const (
+	MouseRightClickMenu = "menu"
+	MouseRightClickPlay = "play"
+	MouseRightClickNone = "none"
+)
+
+// MouseRightClickActions lists available right click actions.
+var MouseRightClickActions = []string{MouseRightClickMenu, MouseRightClickPlay, MouseRightClickNone}
+
+// Widgets that can ignore mouse
+const (
+	MouseWidgetNavigation  = "navigation"
+	MouseWidgetMedia       = "media"
+	MouseWidgetQueue       = "queue"
+	MouseWidgetBreadcrumbs = "breadcrumbs"
+	MouseWidgetStatus      = "status"
+)
+
+// MouseWidgets lists widgets that can ignore mouse.
+var MouseWidgets = []string{MouseWidgetNavigation, MouseWidgetMedia, MouseWidgetQueue, MouseWidgetBreadcrumbs,
+	MouseWidgetStatus}
+
+// MouseIgnored returns true if widget ignores mouse.
+func (g *Gui) MouseIgnored(widget string) bool {
+	for _, v := range g.MouseIgnoreWidgets {
+		if v == widget {
+			return true
+		}
+	}
+	return false
+}
+
+// Song list columns
+const (
+	SongColumnTrack      = "track"
+	SongColumnTitle      = "title"
+	SongColumnArtist     = "artist"
+	SongColumnAlbum      = "album"
+	SongColumnDuration   = "duration"
+	SongColumnPlays      = "plays"
+	SongColumnYear       = "year"
+	SongColumnRating     = "rating"
+	SongColumnLastPlayed = "last_played"
+)
+
+// SongColumns lists available song list columns.
+var SongColumns = []string{SongColumnTrack, SongColumnTitle, SongColumnArtist, SongColumnAlbum, SongColumnDuration,
+	SongColumnPlays, SongColumnYear, SongColumnRating, SongColumnLastPlayed}
+
+// Views that song columns can be set for
+const (
+	SongColumnViewAlbum    = "album"
+	SongColumnViewPlaylist = "playlist"
+	SongColumnViewQueue    = "queue"
+	SongColumnViewSongs    = "songs"
+)
+
+// SongColumnViews lists views that song columns can be set for.
+var SongColumnViews = []string{SongColumnViewAlbum, SongColumnViewPlaylist, SongColumnViewQueue, SongColumnViewSongs}
+
+// VolumeStepSize returns how many percents volume keys change volume.
+func (g *Gui) VolumeStepSize() int {
+	if g.VolumeStep > 0 {
+		return g.VolumeStep
+	}
+	return (AudioMinVolume + AudioMaxVolume) / g.VolumeSteps
+}
+
+// SongColumnsFor returns song columns of view, or nil if view uses default layout.
+func (g *Gui) SongColumnsFor(view string) []string {
+	return g.SongColumns[view]
+}
+
+// SeekStep returns how many seconds seek keys seek when seek key has been held down for held.
+func (g *Gui) SeekStep(held time.Duration) int {
+	step := g.SeekStepS
+	for i, v := range g.SeekAccelAfterMs {
+		if i < len(g.SeekAccelStepsS) && held >= time.Duration(v)*time.Millisecond {
+			step = g.SeekAccelStepsS[i]
+		}
+	}
+	return step
+}
+
+// validSeekAcceleration returns true if there is a positive step for each hold time,
+// and hold times are positive and increasing.
+func validSeekAcceleration(afterMs []int, stepsS []int) bool {
+	if len(afterMs) == 0 || len(afterMs) != len(stepsS) {
+		return false
+	}
+	for i := range afterMs {
+		if afterMs[i] <= 0 || stepsS[i] <= 0 || (i > 0 && afterMs[i] <= afterMs[i-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
 
+// Limits for navigation pane width
+const (
+	MinNavigationWidth = 12
+	MaxNavigationWidth = 80
+)
+
+// Startup views
+const (
+	StartupViewAlbums    = "albums"
+	StartupViewArtists   = "artists"
+	StartupViewPlaylists = "playlists"
+	StartupViewLast      = "last"
+)
+
+// Stream preferences, when song is available from both servers
+const (
+	StreamPreferPrimary   = "primary"
+	StreamPreferSecondary = "secondary"
+	StreamPreferLossless  = "lossless"
+)
+
 type Player struct {
-	Server           string `yaml:"server"`
+	Server string `yaml:"server"`
+	// SecondaryServer is optional second server, whose library is merged with primary server.
+	SecondaryServer string `yaml:"secondary_server"`
+	// StreamPreference selects server to stream from, when song is found from both servers.
+	StreamPreference string `yaml:"stream_preference"`
 	LogFile          string `yaml:"log_file"`
 	LogLevel         string `yaml:"log_level"`
 	AudioBufferingMs int    `yaml:"audio_buffering_ms"`
 	HttpBufferingS   int    `yaml:"http_buffering_s"`
 	// memory limit in MiB
-	HttpBufferingLimitMem int  `yaml:"http_buffering_limit_mem"`
-	EnableRemoteControl   bool `yaml:"enable_remote_control"`
+	HttpBufferingLimitMem int `yaml:"http_buffering_limit_mem"`
+	// BufferWholeTrack downloads whole track to temporary file regardless of memory limit,
+	// and resumes download if connection is interrupted.
+	BufferWholeTrack    bool `yaml:"buffer_whole_track"`
+	EnableRemoteControl bool `yaml:"enable_remote_control"`
+	// EnableMediaKeys reads media keys directly from operating system. Use only if
+	// desktop environment does not handle media keys through MPRIS.
+	EnableMediaKeys bool `yaml:"enable_media_keys"`
+	// EnableControlSocket serves control api in ControlSocket also when gui is enabled.
+	// Headless mode always serves it.
+	EnableControlSocket bool `yaml:"enable_control_socket"`
+	// ControlSocket is unix socket path for controlling player with json-rpc.
+	ControlSocket string `yaml:"control_socket"`
+	// HttpRemoteAddress is listen address for http remote control, e.g. ':8096'. Empty disables http remote.
+	HttpRemoteAddress string `yaml:"http_remote_address"`
+	// HttpRemoteToken authenticates http remote clients. Generated on startup if empty.
+	HttpRemoteToken string `yaml:"http_remote_token"`
+	// MetricsAddress is listen address for Prometheus metrics, e.g. ':9590'. Empty disables metrics.
+	MetricsAddress string `yaml:"metrics_address"`
+	// NowPlayingFile is a file or named pipe that current song is written to with NowPlayingFormat.
+	NowPlayingFile   string `yaml:"now_playing_file"`
+	NowPlayingFormat string `yaml:"now_playing_format"`
+	// EnableHistoryDb records played songs to local database in LocalCacheDir. Recorded plays are shown
+	// in recently played songs and statistics, and scrobbles that fail while offline are retried from it.
+	EnableHistoryDb bool `yaml:"enable_history_db"`
+	// MaxBitrateKbps limits streaming bitrate, songs with higher bitrate are transcoded by server.
+	// 0 streams original files. Downloads always use original files.
+	MaxBitrateKbps int `yaml:"max_bitrate_kbps"`
+	// PreviousRestartS: previous restarts playing song if more than this many seconds of it have been played,
+	// and only second press plays previous song. Negative value always plays previous song.
+	PreviousRestartS int `yaml:"previous_restart_s"`
 
 	EnableLocalCache bool   `yaml:"enable_local_cache"`
 	LocalCacheDir    string `yaml:"local_cache_dir"`
+	// DownloadQuotaMb is max size of songs downloaded for offline playback in MiB.
+	// Least recently played songs are removed when quota is exceeded.
+	DownloadQuotaMb int `yaml:"download_quota_mb"`
+	// CoverCacheMb is max size of album covers cached to local cache dir in MiB.
+	CoverCacheMb int `yaml:"cover_cache_mb"`
+	// MetadataCacheTtlMin is how long cached server items are valid in minutes.
+	// Cache is stored to local cache dir and survives restarts.
+	MetadataCacheTtlMin int `yaml:"metadata_cache_ttl_min"`
+	// PrefetchLibrary loads all artists and albums in background after startup.
+	PrefetchLibrary bool `yaml:"prefetch_library"`
+
+	// UseKeyring stores server tokens in operating system keyring instead of config file.
+	UseKeyring bool `yaml:"use_keyring"`
+
+	// TlsCaFile is a PEM file containing additional certificate authorities to trust.
+	TlsCaFile string `yaml:"tls_ca_file"`
+	// TlsSkipVerify disables verifying server certificate. Use only for testing.
+	TlsSkipVerify bool `yaml:"tls_skip_verify"`
+	// TlsClientCert and TlsClientKey are PEM files for client certificate authentication.
+	TlsClientCert string `yaml:"tls_client_cert"`
+	TlsClientKey  string `yaml:"tls_client_key"`
+
+	// HttpHeaders are added to every request, e.g. for authenticating to reverse proxy.
+	HttpHeaders map[string]string `yaml:"http_headers"`
+
+	// Volumes is last volume in range [0,100] per audio output. Volume is restored on startup.
+	Volumes map[string]int `yaml:"volumes"`
+
+	// InstantMixSize is how many songs are requested for instant mix.
+	InstantMixSize int `yaml:"instant_mix_size"`
+	// InstantMixSkipPlayed leaves songs played during last day out of instant mix. Jellyfin only.
+	InstantMixSkipPlayed bool `yaml:"instant_mix_skip_played"`
 }
 
 func (g *Gui) sanitize() {
 	if g.PageSize <= 0 || g.PageSize > 500 {
 		g.PageSize = 100
-		PageSize = g.PageSize
 	}
+	PageSize = g.PageSize
 	if g.DoubleClickMs <= 0 {
 		g.DoubleClickMs = 220
 	}
+	g.MouseRightClick = strings.ToLower(g.MouseRightClick)
+	switch g.MouseRightClick {
+	case MouseRightClickMenu, MouseRightClickPlay, MouseRightClickNone:
+	default:
+		g.MouseRightClick = MouseRightClickMenu
+	}
+	if g.MouseScrollLines <= 0 {
+		g.MouseScrollLines = 1
+	}
 	if len(g.SearchTypes) == 0 {
 		g.SearchTypes = []models.ItemType{models.TypeArtist, models.TypeAlbum, models.TypeSong, models.TypePlaylist}
 	}
@@ -98,6 +388,65 @@ func (g *Gui) sanitize() {
 	if g.VolumeSteps < 2 || g.VolumeSteps > 50 {
 		g.VolumeSteps = 20
 	}
+	if g.VolumeStep < 0 || g.VolumeStep > 50 {
+		g.VolumeStep = 0
+	}
+	if g.SeekStepS <= 0 {
+		g.SeekStepS = 3
+	}
+	if g.SeekRepeatMs == 0 {
+		g.SeekRepeatMs = 500
+	} else if g.SeekRepeatMs < 0 {
+		g.SeekRepeatMs = -1
+	}
+	if !validSeekAcceleration(g.SeekAccelAfterMs, g.SeekAccelStepsS) {
+		g.SeekAccelAfterMs = []int{1000, 3000}
+		g.SeekAccelStepsS = []int{30, 120}
+	}
+	if g.TerminalTitleFormat == "" {
+		g.TerminalTitleFormat = "{artist} – {title} [Jellycli]"
+	}
+	if g.CopyFormat == "" {
+		g.CopyFormat = "{artist} – {title}"
+	}
+
+	g.StartupView = strings.ToLower(g.StartupView)
+	switch g.StartupView {
+	case StartupViewAlbums, StartupViewArtists, StartupViewPlaylists, StartupViewLast:
+	default:
+		g.StartupView = ""
+	}
+
+	if g.NavigationWidth != 0 && (g.NavigationWidth < MinNavigationWidth || g.NavigationWidth > MaxNavigationWidth) {
+		g.NavigationWidth = 0
+	}
+
+	g.Theme = strings.ToLower(g.Theme)
+	if !isTheme(g.Theme) {
+		g.Theme = ThemeDefault
+	}
+	g.ColorMode = strings.ToLower(g.ColorMode)
+	if !isColorMode(g.ColorMode) {
+		g.ColorMode = ColorModeAuto
+	}
+
+	for view, columns := range g.SongColumns {
+		valid := make([]string, 0, len(columns))
+		for _, v := range columns {
+			v = strings.ToLower(v)
+			if containsString(SongColumns, v) && !containsString(valid, v) {
+				valid = append(valid, v)
+			}
+		}
+		if !containsString(SongColumnViews, view) || len(valid) == 0 {
+			delete(g.SongColumns, view)
+		} else {
+			g.SongColumns[view] = valid
+		}
+	}
+	if len(g.SongColumns) == 0 {
+		g.SongColumns = nil
+	}
 }
 
 func (p *Player) sanitize() {
@@ -120,6 +469,35 @@ func (p *Player) sanitize() {
 		p.HttpBufferingLimitMem = 20
 	}
 
+	if p.DownloadQuotaMb <= 0 {
+		p.DownloadQuotaMb = 2048
+	}
+	if p.CoverCacheMb <= 0 {
+		p.CoverCacheMb = 100
+	}
+	if p.MetadataCacheTtlMin <= 0 {
+		p.MetadataCacheTtlMin = 60
+	}
+
+	if p.InstantMixSize <= 0 {
+		p.InstantMixSize = 200
+	} else if p.InstantMixSize > 1000 {
+		p.InstantMixSize = 1000
+	}
+
+	if p.MaxBitrateKbps < 0 {
+		p.MaxBitrateKbps = 0
+	}
+	if p.PreviousRestartS < 0 {
+		p.PreviousRestartS = -1
+	}
+	if p.NowPlayingFormat == "" {
+		p.NowPlayingFormat = "{artist} - {title}"
+	}
+	if p.ControlSocket == "" {
+		p.ControlSocket = DefaultControlSocket()
+	}
+
 	if p.LocalCacheDir == "" {
 		baseCacheDir, err := os.UserCacheDir()
 		if err != nil {
@@ -128,6 +506,36 @@ func (p *Player) sanitize() {
 		p.LocalCacheDir = path.Join(baseCacheDir, AppNameLower)
 	}
 
+	p.SecondaryServer = strings.ToLower(p.SecondaryServer)
+	if p.SecondaryServer == strings.ToLower(p.Server) {
+		p.SecondaryServer = ""
+	}
+
+	p.StreamPreference = strings.ToLower(p.StreamPreference)
+	switch p.StreamPreference {
+	case StreamPreferPrimary, StreamPreferSecondary, StreamPreferLossless:
+	default:
+		p.StreamPreference = ""
+	}
+
+	for output, volume := range p.Volumes {
+		if volume < AudioMinVolume || volume > AudioMaxVolume {
+			delete(p.Volumes, output)
+		}
+	}
+	if len(p.Volumes) == 0 {
+		p.Volumes = nil
+	}
+}
+
+// DefaultControlSocket returns control socket path in user runtime directory, if there is one,
+// else in temp directory.
+func DefaultControlSocket() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return path.Join(dir, AppNameLower+".sock")
 }
 
 // initialize new config with some sensible values
@@ -149,15 +557,23 @@ func (c *Config) initNewConfig() {
 
 	c.Gui.EnableResultsFiltering = true
 	c.Player.EnableLocalCache = false
+	c.Player.UseKeyring = true
+	c.Player.PreviousRestartS = 3
 }
 
 // can config file be considered empty / not configured
 func (c *Config) isEmptyConfig() bool {
 	return c.Jellyfin.UserId == "" &&
 		c.Subsonic.Url == "" &&
+		c.Ampache.Url == "" &&
 		c.Player.Server == ""
 }
 
+// IsNewConfig returns true if config file was empty on startup, i.e. application has not been set up yet.
+func IsNewConfig() bool {
+	return configIsEmpty
+}
+
 // ReadUserInput reads value from stdin. Name is printed like 'Enter <name>. If mask is true, input is masked.
 func ReadUserInput(name string, mask bool) (string, error) {
 	fmt.Print("Enter ", name, ": ")
@@ -187,12 +603,13 @@ func ConfigFromViper() error {
 
 	AppConfig = &Config{
 		Jellyfin: Jellyfin{
-			Url:       viper.GetString("jellyfin.url"),
-			Token:     viper.GetString("jellyfin.token"),
-			UserId:    viper.GetString("jellyfin.userid"),
-			DeviceId:  viper.GetString("jellyfin.device_id"),
-			ServerId:  viper.GetString("jellyfin.server_id"),
-			MusicView: viper.GetString("jellyfin.music_view"),
+			Url:         viper.GetString("jellyfin.url"),
+			Token:       viper.GetString("jellyfin.token"),
+			UserId:      viper.GetString("jellyfin.userid"),
+			DeviceId:    viper.GetString("jellyfin.device_id"),
+			ServerId:    viper.GetString("jellyfin.server_id"),
+			MusicView:   viper.GetString("jellyfin.music_view"),
+			PodcastView: viper.GetString("jellyfin.podcast_view"),
 		},
 		Subsonic: Subsonic{
 			Url:      viper.GetString("subsonic.url"),
@@ -200,16 +617,45 @@ func ConfigFromViper() error {
 			Salt:     viper.GetString("subsonic.salt"),
 			Token:    viper.GetString("subsonic.token"),
 		},
+		Ampache: Ampache{
+			Url:    viper.GetString("ampache.url"),
+			ApiKey: viper.GetString("ampache.api_key"),
+		},
 		Player: Player{
 			Server:                viper.GetString("player.server"),
+			SecondaryServer:       viper.GetString("player.secondary_server"),
+			StreamPreference:      viper.GetString("player.stream_preference"),
 			LogFile:               viper.GetString("player.logfile"),
 			LogLevel:              viper.GetString("player.loglevel"),
 			AudioBufferingMs:      viper.GetInt("player.audio_buffering_ms"),
 			HttpBufferingS:        viper.GetInt("player.http_buffering_s"),
 			HttpBufferingLimitMem: viper.GetInt("player.http_buffering_limit_mem"),
+			BufferWholeTrack:      viper.GetBool("player.buffer_whole_track"),
 			EnableRemoteControl:   viper.GetBool("player.enable_remote_control"),
+			EnableMediaKeys:       viper.GetBool("player.enable_media_keys"),
+			EnableControlSocket:   viper.GetBool("player.enable_control_socket"),
+			ControlSocket:         viper.GetString("player.control_socket"),
+			HttpRemoteAddress:     viper.GetString("player.http_remote_address"),
+			HttpRemoteToken:       viper.GetString("player.http_remote_token"),
+			MetricsAddress:        viper.GetString("player.metrics_address"),
+			NowPlayingFile:        viper.GetString("player.now_playing_file"),
+			NowPlayingFormat:      viper.GetString("player.now_playing_format"),
+			EnableHistoryDb:       viper.GetBool("player.enable_history_db"),
+			MaxBitrateKbps:        viper.GetInt("player.max_bitrate_kbps"),
+			PreviousRestartS:      viper.GetInt("player.previous_restart_s"),
 			LocalCacheDir:         viper.GetString("player.local_cache_dir"),
 			EnableLocalCache:      viper.GetBool("player.enable_local_cache"),
+			DownloadQuotaMb:       viper.GetInt("player.download_quota_mb"),
+			CoverCacheMb:          viper.GetInt("player.cover_cache_mb"),
+			MetadataCacheTtlMin:   viper.GetInt("player.metadata_cache_ttl_min"),
+			PrefetchLibrary:       viper.GetBool("player.prefetch_library"),
+			UseKeyring:            viper.GetBool("player.use_keyring"),
+			TlsCaFile:             viper.GetString("player.tls_ca_file"),
+			TlsSkipVerify:         viper.GetBool("player.tls_skip_verify"),
+			TlsClientCert:         viper.GetString("player.tls_client_cert"),
+			TlsClientKey:          viper.GetString("player.tls_client_key"),
+			InstantMixSize:        viper.GetInt("player.instant_mix_size"),
+			InstantMixSkipPlayed:  viper.GetBool("player.instant_mix_skip_played"),
 		},
 		Gui: Gui{
 			PageSize:            viper.GetInt("gui.pagesize"),
@@ -219,13 +665,90 @@ func ConfigFromViper() error {
 			DoubleClickMs:       viper.GetInt("gui.double_click_ms"),
 			SearchResultsLimit:  viper.GetInt("gui.search_results_limit"),
 			VolumeSteps:         viper.GetInt("gui.volume_steps"),
+			VolumeStep:          viper.GetInt("gui.volume_step"),
+			SeekStepS:           viper.GetInt("gui.seek_step_s"),
+			SeekRepeatMs:        viper.GetInt("gui.seek_repeat_ms"),
+			SeekAccelAfterMs:    viper.GetIntSlice("gui.seek_accel_after_ms"),
+			SeekAccelStepsS:     viper.GetIntSlice("gui.seek_accel_steps_s"),
+
+			MouseSingleClickPlay: viper.GetBool("gui.mouse_single_click_play"),
+			MouseRightClick:      viper.GetString("gui.mouse_right_click"),
+			MouseScrollLines:     viper.GetInt("gui.mouse_scroll_lines"),
 
 			EnableSorting:          viper.GetBool("gui.enable_sorting"),
 			EnableFiltering:        viper.GetBool("gui.enable_filtering"),
 			EnableResultsFiltering: viper.GetBool("gui.enable_results_filtering"),
+
+			SortArtists: viper.GetString("gui.sort_artists"),
+			SortAlbums:  viper.GetString("gui.sort_albums"),
+			SortSongs:   viper.GetString("gui.sort_songs"),
+
+			StartupView: viper.GetString("gui.startup_view"),
+			LastView:    viper.GetString("gui.last_view"),
+
+			NavigationWidth:  viper.GetInt("gui.navigation_width"),
+			NavigationHidden: viper.GetBool("gui.navigation_hidden"),
+			QueueDocked:      viper.GetBool("gui.queue_docked"),
+			AlbumGrid:        viper.GetBool("gui.album_grid"),
+			ArtPlaceholders:  viper.GetBool("gui.art_placeholders"),
+
+			StatusFormat:      viper.GetString("gui.status_format"),
+			ShowRemainingTime: viper.GetBool("gui.show_remaining_time"),
+			ShowEndClock:      viper.GetBool("gui.show_end_clock"),
+			EnableVisualizer:  viper.GetBool("gui.enable_visualizer"),
+
+			EnableTerminalTitle: viper.GetBool("gui.enable_terminal_title"),
+			TerminalTitleFormat: viper.GetString("gui.terminal_title_format"),
+			CopyFormat:          viper.GetString("gui.copy_format"),
+
+			InfiniteScroll: viper.GetBool("gui.infinite_scroll"),
+			Theme:          viper.GetString("gui.theme"),
+			ColorMode:      viper.GetString("gui.color_mode"),
+			Language:       viper.GetString("gui.language"),
+			AsciiOnly:      viper.GetBool("gui.ascii_only"),
+			AccessibleMode: viper.GetBool("gui.accessible_mode"),
+		},
+		Hooks: Hooks{
+			OnSongChange: viper.GetString("hooks.on_song_change"),
+			OnPause:      viper.GetString("hooks.on_pause"),
+			OnQueueEmpty: viper.GetString("hooks.on_queue_empty"),
 		},
 	}
 
+	if AppConfig.Player.UseKeyring {
+		AppConfig.Jellyfin.Token = readSecret(keyringUserJellyfin, AppConfig.Jellyfin.Token)
+		AppConfig.Subsonic.Token = readSecret(keyringUserSubsonic, AppConfig.Subsonic.Token)
+		AppConfig.Ampache.ApiKey = readSecret(keyringUserAmpache, AppConfig.Ampache.ApiKey)
+	}
+
+	headers := viper.GetStringMapString("player.http_headers")
+	if len(headers) > 0 {
+		AppConfig.Player.HttpHeaders = headers
+	}
+
+	for output := range viper.GetStringMap("player.volumes") {
+		if AppConfig.Player.Volumes == nil {
+			AppConfig.Player.Volumes = map[string]int{}
+		}
+		AppConfig.Player.Volumes[output] = viper.GetInt("player.volumes." + output)
+	}
+
+	for view, columns := range viper.GetStringMapStringSlice("gui.song_columns") {
+		if AppConfig.Gui.SongColumns == nil {
+			AppConfig.Gui.SongColumns = map[string][]string{}
+		}
+		AppConfig.Gui.SongColumns[view] = append([]string{}, columns...)
+	}
+
+	for _, v := range viper.GetStringSlice("gui.mouse_ignore_widgets") {
+		AppConfig.Gui.MouseIgnoreWidgets = append(AppConfig.Gui.MouseIgnoreWidgets, strings.ToLower(v))
+	}
+
+	// 0 is valid and always restarts song, so only default if it's missing
+	if !viper.IsSet("player.previous_restart_s") {
+		AppConfig.Player.PreviousRestartS = 3
+	}
+
 	searchTypes := viper.GetStringSlice("gui.search_types")
 	for _, v := range searchTypes {
 		searchType := models.ItemType(v)
@@ -238,15 +761,18 @@ func ConfigFromViper() error {
 
 	}
 
-	if AppConfig.Jellyfin.Url == "" && AppConfig.Subsonic.Url == "" {
+	if AppConfig.Jellyfin.Url == "" && AppConfig.Subsonic.Url == "" && AppConfig.Ampache.Url == "" {
 		configIsEmpty = true
 		setDefaults()
 	} else {
 		AppConfig.Player.sanitize()
 		AppConfig.Gui.sanitize()
 	}
+	SetTheme(AppConfig.Gui.Theme, AppConfig.Gui.ColorMode)
 	AudioBufferPeriod = time.Millisecond * time.Duration(AppConfig.Player.AudioBufferingMs)
-	VolumeStepSize = (AudioMinVolume + AudioMaxVolume) / AppConfig.Gui.VolumeSteps
+	VolumeStepSize = AppConfig.Gui.VolumeStepSize()
+	InstantMixSize = AppConfig.Player.InstantMixSize
+	InstantMixSkipPlayed = AppConfig.Player.InstantMixSkipPlayed
 	return nil
 }
 
@@ -275,44 +801,126 @@ func configFrom(conf *Config) {
 }
 
 func UpdateViper() {
-	viper.Set("jellyfin.url", AppConfig.Jellyfin.Url)
-	viper.Set("jellyfin.token", AppConfig.Jellyfin.Token)
-	viper.Set("jellyfin.userid", AppConfig.Jellyfin.UserId)
-	viper.Set("jellyfin.device_id", AppConfig.Jellyfin.DeviceId)
-	viper.Set("jellyfin.server_id", AppConfig.Jellyfin.ServerId)
-	viper.Set("jellyfin.music_view", AppConfig.Jellyfin.MusicView)
-
-	viper.Set("subsonic.url", AppConfig.Subsonic.Url)
-	viper.Set("subsonic.username", AppConfig.Subsonic.Username)
-	viper.Set("subsonic.salt", AppConfig.Subsonic.Salt)
-	viper.Set("subsonic.token", AppConfig.Subsonic.Token)
-
-	viper.Set("player.server", AppConfig.Player.Server)
-	viper.Set("player.logfile", AppConfig.Player.LogFile)
-	viper.Set("player.loglevel", AppConfig.Player.LogLevel)
-	viper.Set("player.http_buffering_s", AppConfig.Player.HttpBufferingS)
-	viper.Set("player.http_buffering_limit_mem", AppConfig.Player.HttpBufferingLimitMem)
-	viper.Set("player.enable_remote_control", AppConfig.Player.EnableRemoteControl)
-	viper.Set("player.audio_buffering_ms", AppConfig.Player.AudioBufferingMs)
-	viper.Set("player.local_cache_dir", AppConfig.Player.LocalCacheDir)
-	viper.Set("player.enable_local_cache", AppConfig.Player.EnableLocalCache)
-
-	viper.Set("gui.search_results_limit", AppConfig.Gui.SearchResultsLimit)
-	viper.Set("gui.debug_mode", AppConfig.Gui.DebugMode)
-	viper.Set("gui.limit_recently_played", AppConfig.Gui.LimitRecentlyPlayed)
-	viper.Set("gui.mouse_enabled", AppConfig.Gui.MouseEnabled)
-	viper.Set("gui.double_click_ms", AppConfig.Gui.DoubleClickMs)
-	viper.Set("gui.pagesize", AppConfig.Gui.PageSize)
-	viper.Set("gui.volume_steps", AppConfig.Gui.VolumeSteps)
-
-	sTypes := make([]string, len(AppConfig.Gui.SearchTypes))
-	for i, v := range AppConfig.Gui.SearchTypes {
-		sTypes[i] = string(v)
+	conf := *AppConfig
+	if conf.Player.UseKeyring {
+		conf.Jellyfin.Token = storeSecret(keyringUserJellyfin, conf.Jellyfin.Token)
+		conf.Subsonic.Token = storeSecret(keyringUserSubsonic, conf.Subsonic.Token)
+		conf.Ampache.ApiKey = storeSecret(keyringUserAmpache, conf.Ampache.ApiKey)
 	}
+	setViper(viper.GetViper(), &conf)
+}
 
-	viper.Set("gui.search_types", sTypes)
+// setViper sets every config key in v.
+func setViper(v *viper.Viper, conf *Config) {
+	v.Set("jellyfin.url", conf.Jellyfin.Url)
+	v.Set("jellyfin.token", conf.Jellyfin.Token)
+	v.Set("jellyfin.userid", conf.Jellyfin.UserId)
+	v.Set("jellyfin.device_id", conf.Jellyfin.DeviceId)
+	v.Set("jellyfin.server_id", conf.Jellyfin.ServerId)
+	v.Set("jellyfin.music_view", conf.Jellyfin.MusicView)
+	v.Set("jellyfin.podcast_view", conf.Jellyfin.PodcastView)
+
+	v.Set("subsonic.url", conf.Subsonic.Url)
+	v.Set("subsonic.username", conf.Subsonic.Username)
+	v.Set("subsonic.salt", conf.Subsonic.Salt)
+	v.Set("subsonic.token", conf.Subsonic.Token)
+
+	v.Set("ampache.url", conf.Ampache.Url)
+	v.Set("ampache.api_key", conf.Ampache.ApiKey)
+
+	v.Set("player.server", conf.Player.Server)
+	v.Set("player.secondary_server", conf.Player.SecondaryServer)
+	v.Set("player.stream_preference", conf.Player.StreamPreference)
+	v.Set("player.logfile", conf.Player.LogFile)
+	v.Set("player.loglevel", conf.Player.LogLevel)
+	v.Set("player.http_buffering_s", conf.Player.HttpBufferingS)
+	v.Set("player.http_buffering_limit_mem", conf.Player.HttpBufferingLimitMem)
+	v.Set("player.buffer_whole_track", conf.Player.BufferWholeTrack)
+	v.Set("player.enable_remote_control", conf.Player.EnableRemoteControl)
+	v.Set("player.enable_media_keys", conf.Player.EnableMediaKeys)
+	v.Set("player.enable_control_socket", conf.Player.EnableControlSocket)
+	v.Set("player.control_socket", conf.Player.ControlSocket)
+	v.Set("player.http_remote_address", conf.Player.HttpRemoteAddress)
+	v.Set("player.http_remote_token", conf.Player.HttpRemoteToken)
+	v.Set("player.metrics_address", conf.Player.MetricsAddress)
+	v.Set("player.now_playing_file", conf.Player.NowPlayingFile)
+	v.Set("player.now_playing_format", conf.Player.NowPlayingFormat)
+	v.Set("player.enable_history_db", conf.Player.EnableHistoryDb)
+	v.Set("player.max_bitrate_kbps", conf.Player.MaxBitrateKbps)
+	v.Set("player.previous_restart_s", conf.Player.PreviousRestartS)
+	v.Set("player.audio_buffering_ms", conf.Player.AudioBufferingMs)
+	v.Set("player.local_cache_dir", conf.Player.LocalCacheDir)
+	v.Set("player.enable_local_cache", conf.Player.EnableLocalCache)
+	v.Set("player.download_quota_mb", conf.Player.DownloadQuotaMb)
+	v.Set("player.cover_cache_mb", conf.Player.CoverCacheMb)
+	v.Set("player.metadata_cache_ttl_min", conf.Player.MetadataCacheTtlMin)
+	v.Set("player.prefetch_library", conf.Player.PrefetchLibrary)
+	v.Set("player.use_keyring", conf.Player.UseKeyring)
+	v.Set("player.tls_ca_file", conf.Player.TlsCaFile)
+	v.Set("player.tls_skip_verify", conf.Player.TlsSkipVerify)
+	v.Set("player.tls_client_cert", conf.Player.TlsClientCert)
+	v.Set("player.tls_client_key", conf.Player.TlsClientKey)
+	v.Set("player.http_headers", conf.Player.HttpHeaders)
+	v.Set("player.volumes", conf.Player.Volumes)
+	v.Set("player.instant_mix_size", conf.Player.InstantMixSize)
+	v.Set("player.instant_mix_skip_played", conf.Player.InstantMixSkipPlayed)
+
+	v.Set("gui.search_results_limit", conf.Gui.SearchResultsLimit)
+	v.Set("gui.debug_mode", conf.Gui.DebugMode)
+	v.Set("gui.limit_recently_played", conf.Gui.LimitRecentlyPlayed)
+	v.Set("gui.mouse_enabled", conf.Gui.MouseEnabled)
+	v.Set("gui.double_click_ms", conf.Gui.DoubleClickMs)
+	v.Set("gui.mouse_single_click_play", conf.Gui.MouseSingleClickPlay)
+	v.Set("gui.mouse_right_click", conf.Gui.MouseRightClick)
+	v.Set("gui.mouse_scroll_lines", conf.Gui.MouseScrollLines)
+	v.Set("gui.mouse_ignore_widgets", conf.Gui.MouseIgnoreWidgets)
+	v.Set("gui.pagesize", conf.Gui.PageSize)
+	v.Set("gui.song_columns", conf.Gui.SongColumns)
+	v.Set("gui.infinite_scroll", conf.Gui.InfiniteScroll)
+	v.Set("gui.theme", conf.Gui.Theme)
+	v.Set("gui.color_mode", conf.Gui.ColorMode)
+	v.Set("gui.language", conf.Gui.Language)
+	v.Set("gui.ascii_only", conf.Gui.AsciiOnly)
+	v.Set("gui.accessible_mode", conf.Gui.AccessibleMode)
+	v.Set("gui.volume_steps", conf.Gui.VolumeSteps)
+	v.Set("gui.volume_step", conf.Gui.VolumeStep)
+	v.Set("gui.seek_step_s", conf.Gui.SeekStepS)
+	v.Set("gui.seek_repeat_ms", conf.Gui.SeekRepeatMs)
+	v.Set("gui.seek_accel_after_ms", conf.Gui.SeekAccelAfterMs)
+	v.Set("gui.seek_accel_steps_s", conf.Gui.SeekAccelStepsS)
+
+	sTypes := make([]string, len(conf.Gui.SearchTypes))
+	for i, v := range conf.Gui.SearchTypes {
+		sTypes[i] = string(v)
+	}
 
-	viper.Set("gui.enable_sorting", AppConfig.Gui.EnableSorting)
-	viper.Set("gui.enable_filtering", AppConfig.Gui.EnableFiltering)
-	viper.Set("gui.enable_results_filtering", AppConfig.Gui.EnableResultsFiltering)
+	v.Set("gui.search_types", sTypes)
+
+	v.Set("gui.enable_sorting", conf.Gui.EnableSorting)
+	v.Set("gui.enable_filtering", conf.Gui.EnableFiltering)
+	v.Set("gui.enable_results_filtering", conf.Gui.EnableResultsFiltering)
+
+	v.Set("gui.sort_artists", conf.Gui.SortArtists)
+	v.Set("gui.sort_albums", conf.Gui.SortAlbums)
+	v.Set("gui.sort_songs", conf.Gui.SortSongs)
+
+	v.Set("gui.startup_view", conf.Gui.StartupView)
+	v.Set("gui.last_view", conf.Gui.LastView)
+
+	v.Set("gui.navigation_width", conf.Gui.NavigationWidth)
+	v.Set("gui.navigation_hidden", conf.Gui.NavigationHidden)
+	v.Set("gui.queue_docked", conf.Gui.QueueDocked)
+	v.Set("gui.album_grid", conf.Gui.AlbumGrid)
+	v.Set("gui.art_placeholders", conf.Gui.ArtPlaceholders)
+	v.Set("gui.status_format", conf.Gui.StatusFormat)
+	v.Set("gui.show_remaining_time", conf.Gui.ShowRemainingTime)
+	v.Set("gui.show_end_clock", conf.Gui.ShowEndClock)
+	v.Set("gui.enable_visualizer", conf.Gui.EnableVisualizer)
+	v.Set("gui.enable_terminal_title", conf.Gui.EnableTerminalTitle)
+	v.Set("gui.terminal_title_format", conf.Gui.TerminalTitleFormat)
+	v.Set("gui.copy_format", conf.Gui.CopyFormat)
+
+	v.Set("hooks.on_song_change", conf.Hooks.OnSongChange)
+	v.Set("hooks.on_pause", conf.Hooks.OnPause)
+	v.Set("hooks.on_queue_empty", conf.Hooks.OnQueueEmpty)
 }
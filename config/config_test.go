@@ -25,6 +25,7 @@ import (
 	"path"
 	"reflect"
 	"testing"
+	"time"
 	"tryffel.net/go/jellycli/models"
 )
 
@@ -32,12 +33,13 @@ func TestConfigToFromViper(t *testing.T) {
 	// test every var is read & written
 	conf := &Config{
 		Jellyfin: Jellyfin{
-			Url:       "http://localhost",
-			Token:     "jellytoken",
-			UserId:    "jellyuser",
-			DeviceId:  "jellydevice",
-			ServerId:  "jellyserver",
-			MusicView: "jellyview",
+			Url:         "http://localhost",
+			Token:       "jellytoken",
+			UserId:      "jellyuser",
+			DeviceId:    "jellydevice",
+			ServerId:    "jellyserver",
+			MusicView:   "jellyview",
+			PodcastView: "Podcasts",
 		},
 		Subsonic: Subsonic{
 			Url:      "https://localhost",
@@ -45,16 +47,47 @@ func TestConfigToFromViper(t *testing.T) {
 			Salt:     "subsalt",
 			Token:    "subtoken",
 		},
+		Ampache: Ampache{
+			Url:    "https://ampache.localhost",
+			ApiKey: "ampachekey",
+		},
 		Player: Player{
 			Server:                "jellyfin",
+			SecondaryServer:       "subsonic",
+			StreamPreference:      "lossless",
 			LogFile:               "/var/log/jellyfin.log",
 			LogLevel:              "info",
 			AudioBufferingMs:      150,
 			HttpBufferingS:        5,
 			HttpBufferingLimitMem: 20,
+			BufferWholeTrack:      true,
 			EnableRemoteControl:   true,
+			EnableMediaKeys:       true,
+			EnableControlSocket:   true,
+			ControlSocket:         "/run/user/1000/jellycli.sock",
+			HttpRemoteAddress:     "127.0.0.1:8097",
+			HttpRemoteToken:       "secret-token",
+			MetricsAddress:        ":9590",
+			NowPlayingFile:        "/tmp/jellycli-now-playing",
+			NowPlayingFormat:      "{title} ({position}/{duration})",
+			EnableHistoryDb:       true,
+			MaxBitrateKbps:        192,
+			PreviousRestartS:      5,
 			LocalCacheDir:         "/tmp/jellycli",
 			EnableLocalCache:      true,
+			DownloadQuotaMb:       512,
+			CoverCacheMb:          50,
+			MetadataCacheTtlMin:   30,
+			PrefetchLibrary:       true,
+			UseKeyring:            false,
+			TlsCaFile:             "/etc/ssl/jellyfin-ca.pem",
+			TlsSkipVerify:         true,
+			TlsClientCert:         "/etc/ssl/client.pem",
+			TlsClientKey:          "/etc/ssl/client-key.pem",
+			HttpHeaders:           map[string]string{"cf-access-client-id": "jellycli"},
+			Volumes:               map[string]int{"default": 40, "headphones": 80},
+			InstantMixSize:        50,
+			InstantMixSkipPlayed:  true,
 		},
 		Gui: Gui{
 			PageSize:               100,
@@ -62,12 +95,53 @@ func TestConfigToFromViper(t *testing.T) {
 			LimitRecentlyPlayed:    true,
 			MouseEnabled:           true,
 			DoubleClickMs:          200,
+			MouseSingleClickPlay:   true,
+			MouseRightClick:        "play",
+			MouseScrollLines:       3,
+			MouseIgnoreWidgets:     []string{"status", "breadcrumbs"},
 			SearchTypes:            []models.ItemType{"Artist", "Album"},
 			SearchResultsLimit:     10,
 			EnableSorting:          true,
 			EnableFiltering:        true,
 			EnableResultsFiltering: true,
 			VolumeSteps:            20,
+			SeekStepS:              10,
+			SeekRepeatMs:           400,
+			SeekAccelAfterMs:       []int{500, 2000, 5000},
+			SeekAccelStepsS:        []int{20, 60, 300},
+			SortArtists:            "Random ASC",
+			SortAlbums:             "Release year DESC",
+			SortSongs:              "Name DESC",
+			StartupView:            "last",
+			LastView:               "playlists",
+			NavigationWidth:        30,
+			NavigationHidden:       true,
+			QueueDocked:            true,
+			AlbumGrid:              true,
+			ArtPlaceholders:        true,
+			StatusFormat:           "{title} - {artist}\\n{album} {clock}",
+			ShowRemainingTime:      true,
+			ShowEndClock:           true,
+			EnableVisualizer:       true,
+			EnableTerminalTitle:    true,
+			TerminalTitleFormat:    "{title} [{artist}]",
+			InfiniteScroll:         true,
+			Theme:                  "light",
+			ColorMode:              "16",
+			Language:               "fi",
+			AsciiOnly:              true,
+			AccessibleMode:         true,
+			SongColumns: map[string][]string{
+				"album": {"track", "title", "duration"},
+				"queue": {"title", "artist", "album", "plays"},
+			},
+			VolumeStep: 4,
+			CopyFormat: "{title} by {artist}",
+		},
+		Hooks: Hooks{
+			OnSongChange: "notify-send \"$JELLYCLI_SONG_NAME\"",
+			OnPause:      "https://home.example.com/api/webhook/pause",
+			OnQueueEmpty: "/usr/local/bin/queue-empty.sh",
 		},
 	}
 
@@ -116,8 +190,16 @@ func TestInitEmptyConfig(t *testing.T) {
 			HttpBufferingS:        5,
 			HttpBufferingLimitMem: 20,
 			EnableRemoteControl:   true,
+			ControlSocket:         DefaultControlSocket(),
+			NowPlayingFormat:      "{artist} - {title}",
 			LocalCacheDir:         path.Join(cachedir, AppNameLower),
 			EnableLocalCache:      false,
+			DownloadQuotaMb:       2048,
+			CoverCacheMb:          100,
+			MetadataCacheTtlMin:   60,
+			PreviousRestartS:      3,
+			UseKeyring:            true,
+			InstantMixSize:        200,
 		},
 		Gui: Gui{
 			PageSize:            100,
@@ -125,6 +207,8 @@ func TestInitEmptyConfig(t *testing.T) {
 			LimitRecentlyPlayed: true,
 			MouseEnabled:        true,
 			DoubleClickMs:       220,
+			MouseRightClick:     "menu",
+			MouseScrollLines:    1,
 			SearchTypes: []models.ItemType{models.TypeArtist, models.TypeAlbum,
 				models.TypeSong, models.TypePlaylist},
 			SearchResultsLimit:     30,
@@ -132,6 +216,13 @@ func TestInitEmptyConfig(t *testing.T) {
 			EnableFiltering:        false,
 			EnableResultsFiltering: true,
 			VolumeSteps:            20,
+			SeekStepS:              3,
+			SeekRepeatMs:           500,
+			SeekAccelAfterMs:       []int{1000, 3000},
+			SeekAccelStepsS:        []int{30, 120},
+			TerminalTitleFormat:    "{artist} – {title} [Jellycli]",
+			Theme:                  "default",
+			CopyFormat:             "{artist} – {title}",
 		},
 	}
 
@@ -176,12 +267,18 @@ func TestSanitizeConfig(t *testing.T) {
 		},
 		Player: Player{
 			Server:                "",
+			SecondaryServer:       "Subsonic",
+			StreamPreference:      "fastest",
 			LogFile:               "/var/log/jellyfin.log",
 			LogLevel:              "",
 			AudioBufferingMs:      0,
 			HttpBufferingS:        0,
 			HttpBufferingLimitMem: 0,
 			EnableRemoteControl:   true,
+			MaxBitrateKbps:        -1,
+			PreviousRestartS:      -10,
+			Volumes:               map[string]int{"default": 150, "usb": 30},
+			InstantMixSize:        5000,
 		},
 		Gui: Gui{
 			PageSize:               1000,
@@ -189,12 +286,26 @@ func TestSanitizeConfig(t *testing.T) {
 			LimitRecentlyPlayed:    true,
 			MouseEnabled:           true,
 			DoubleClickMs:          0,
+			MouseRightClick:        "Drag",
 			SearchTypes:            []models.ItemType{"Artist", "Album"},
 			SearchResultsLimit:     0,
 			EnableSorting:          true,
 			EnableFiltering:        true,
 			EnableResultsFiltering: true,
 			VolumeSteps:            20,
+			SeekRepeatMs:           -300,
+			SeekAccelAfterMs:       []int{3000, 1000},
+			SeekAccelStepsS:        []int{30, 120},
+			StartupView:            "home",
+			NavigationWidth:        5,
+			Theme:                  "neon",
+			ColorMode:              "rainbow",
+			SongColumns: map[string][]string{
+				"album": {"Track", "title", "bpm", "title"},
+				"home":  {"title"},
+				"queue": {"bpm"},
+			},
+			VolumeStep: 80,
 		},
 	}
 
@@ -210,11 +321,36 @@ func TestSanitizeConfig(t *testing.T) {
 	invalidConf.Player.AudioBufferingMs = 150
 	invalidConf.Player.HttpBufferingS = 5
 	invalidConf.Player.HttpBufferingLimitMem = 20
+	invalidConf.Player.ControlSocket = DefaultControlSocket()
+	invalidConf.Player.NowPlayingFormat = "{artist} - {title}"
+	invalidConf.Player.MaxBitrateKbps = 0
+	invalidConf.Player.PreviousRestartS = -1
 	invalidConf.Player.LocalCacheDir = path.Join(cachedir, AppNameLower)
+	invalidConf.Player.DownloadQuotaMb = 2048
+	invalidConf.Player.CoverCacheMb = 100
+	invalidConf.Player.MetadataCacheTtlMin = 60
+	invalidConf.Player.SecondaryServer = "subsonic"
+	invalidConf.Player.StreamPreference = ""
+	invalidConf.Player.Volumes = map[string]int{"usb": 30}
+	invalidConf.Player.InstantMixSize = 1000
 
 	invalidConf.Gui.PageSize = 100
 	invalidConf.Gui.DoubleClickMs = 220
+	invalidConf.Gui.MouseRightClick = "menu"
+	invalidConf.Gui.MouseScrollLines = 1
 	invalidConf.Gui.SearchResultsLimit = 30
+	invalidConf.Gui.SeekStepS = 3
+	invalidConf.Gui.SeekRepeatMs = -1
+	invalidConf.Gui.SeekAccelAfterMs = []int{1000, 3000}
+	invalidConf.Gui.SeekAccelStepsS = []int{30, 120}
+	invalidConf.Gui.TerminalTitleFormat = "{artist} – {title} [Jellycli]"
+	invalidConf.Gui.StartupView = ""
+	invalidConf.Gui.NavigationWidth = 0
+	invalidConf.Gui.Theme = "default"
+	invalidConf.Gui.ColorMode = ""
+	invalidConf.Gui.SongColumns = map[string][]string{"album": {"track", "title"}}
+	invalidConf.Gui.VolumeStep = 0
+	invalidConf.Gui.CopyFormat = "{artist} – {title}"
 
 	// clear config
 	configFrom(&Config{})
@@ -231,3 +367,52 @@ func TestSanitizeConfig(t *testing.T) {
 		t.Errorf("sanitized config invalid: %s", diff)
 	}
 }
+
+func TestPlayer_PreviousRestartS(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  int
+	}{
+		{name: "default", value: nil, want: 3},
+		{name: "always restart", value: 0, want: 0},
+		{name: "seconds", value: 10, want: 10},
+		{name: "always previous", value: -5, want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.Set("jellyfin.url", "http://localhost:8096")
+			if tt.value != nil {
+				viper.Set("player.previous_restart_s", tt.value)
+			}
+			err := ConfigFromViper()
+			if err != nil {
+				t.Fatalf("read config from viper: %v", err)
+			}
+			if got := AppConfig.Player.PreviousRestartS; got != tt.want {
+				t.Errorf("PreviousRestartS = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGui_SeekStep(t *testing.T) {
+	gui := &Gui{SeekStepS: 5, SeekAccelAfterMs: []int{1000, 3000}, SeekAccelStepsS: []int{30, 120}}
+	tests := []struct {
+		held time.Duration
+		want int
+	}{
+		{held: 0, want: 5},
+		{held: time.Millisecond * 999, want: 5},
+		{held: time.Second, want: 30},
+		{held: time.Millisecond * 2500, want: 30},
+		{held: time.Second * 3, want: 120},
+		{held: time.Minute, want: 120},
+	}
+	for _, tt := range tests {
+		if got := gui.SeekStep(tt.held); got != tt.want {
+			t.Errorf("SeekStep(%v) = %d, want %d", tt.held, got, tt.want)
+		}
+	}
+}
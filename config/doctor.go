@@ -0,0 +1,251 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Problem is an issue in configuration, found by config doctor.
+type Problem struct {
+	// Error means application cannot work properly. Otherwise problem is a warning.
+	Error bool
+	// Key is the config key problem is related to, if any.
+	Key     string
+	Message string
+	// Fix suggests how to fix the problem.
+	Fix string
+}
+
+// Backends lists supported values for player.server.
+var Backends = []string{"jellyfin", "subsonic", "ampache"}
+
+// secretKeys may be stored in keyring, so their values in viper are not compared.
+var secretKeys = map[string]bool{
+	"jellyfin.token":  true,
+	"subsonic.token":  true,
+	"ampache.api_key": true,
+}
+
+// knownKeys returns every config key application reads, sorted.
+func knownKeys() []string {
+	v := viper.New()
+	setViper(v, &Config{})
+	keys := v.AllKeys()
+	sort.Strings(keys)
+	return keys
+}
+
+// UnknownKeys returns problems for keys that application does not read, e.g. misspelled ones.
+// Keys are as returned by viper.AllKeys.
+func UnknownKeys(keys []string) []Problem {
+	known := knownKeys()
+	problems := []Problem{}
+	for _, key := range keys {
+		if isKnownKey(key, known) {
+			continue
+		}
+		problem := Problem{Key: key, Message: "unknown key, value is not used", Fix: "remove key"}
+		if closest := closestKey(key, known); closest != "" {
+			problem.Fix = fmt.Sprintf("did you mean '%s'?", closest)
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+func isKnownKey(key string, known []string) bool {
+	for _, v := range known {
+		// maps, e.g. player.http_headers, have arbitrary keys
+		if key == v || strings.HasPrefix(key, v+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// closestKey returns known key that key is likely a typo of, or empty string.
+// Key in wrong block, e.g. gui.loglevel, matches as well.
+func closestKey(key string, known []string) string {
+	name := key[strings.LastIndex(key, ".")+1:]
+	closest := ""
+	// allow roughly one typo per four characters
+	maxDistance := len(key)/4 + 1
+	for _, v := range known {
+		distance := editDistance(key, v)
+		if name == v[strings.LastIndex(v, ".")+1:] {
+			distance = 1
+		}
+		if distance < maxDistance {
+			closest = v
+			maxDistance = distance
+		}
+	}
+	return closest
+}
+
+// editDistance returns Levenshtein distance of a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// InvalidValues returns problems for values that were replaced with defaults when reading config.
+// It must be called after ConfigFromViper.
+func InvalidValues() []Problem {
+	sanitized := viper.New()
+	setViper(sanitized, AppConfig)
+	problems := []Problem{}
+	for _, key := range sanitized.AllKeys() {
+		value := viper.Get(key)
+		if secretKeys[key] || isEmptyValue(value) {
+			continue
+		}
+		want := sanitized.Get(key)
+		if strings.EqualFold(fmt.Sprint(value), fmt.Sprint(want)) {
+			continue
+		}
+		problems = append(problems, Problem{
+			Key:     key,
+			Message: fmt.Sprintf("invalid value '%v', using '%v' instead", value, want),
+			Fix:     "see config.sample.yaml for valid values",
+		})
+	}
+	return problems
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// Check returns problems in configuration that do not need connection to server:
+// server settings, log level, tls files and conflicting key bindings.
+func (c *Config) Check() []Problem {
+	problems := c.checkServer("player.server", c.Player.Server)
+	if c.Player.SecondaryServer != "" {
+		problems = append(problems, c.checkServer("player.secondary_server", c.Player.SecondaryServer)...)
+	}
+
+	if _, err := logrus.ParseLevel(c.Player.LogLevel); err != nil {
+		problems = append(problems, Problem{
+			Error:   true,
+			Key:     "player.loglevel",
+			Message: err.Error(),
+			Fix:     "use one of: panic, fatal, error, warning, info, debug, trace",
+		})
+	}
+
+	if _, err := c.Player.TlsConfig(); err != nil {
+		problems = append(problems, Problem{
+			Error:   true,
+			Key:     "player.tls_*",
+			Message: err.Error(),
+			Fix:     "check paths to certificate files, or clear them",
+		})
+	}
+
+	for _, bindings := range KeyBinds.Conflicts() {
+		actions := make([]string, len(bindings))
+		for i, v := range bindings {
+			actions[i] = fmt.Sprintf("'%s'", v.Action)
+		}
+		problems = append(problems, Problem{
+			Message: fmt.Sprintf("key %s is bound to %s", tcell.KeyNames[bindings[0].Key],
+				strings.Join(actions, " and ")),
+			Fix: fmt.Sprintf("only %s works, change other key bindings", actions[0]),
+		})
+	}
+	return problems
+}
+
+func (c *Config) checkServer(key, backend string) []Problem {
+	supported := false
+	for _, v := range Backends {
+		supported = supported || v == strings.ToLower(backend)
+	}
+	if !supported {
+		return []Problem{{
+			Error:   true,
+			Key:     key,
+			Message: fmt.Sprintf("unsupported server '%s'", backend),
+			Fix:     "use one of: " + strings.Join(Backends, ", "),
+		}}
+	}
+	if c.ServerUrl(backend) == "" {
+		return []Problem{{
+			Error:   true,
+			Key:     strings.ToLower(backend) + ".url",
+			Message: fmt.Sprintf("%s server is not configured", backend),
+			Fix:     "run jellycli to log in to server",
+		}}
+	}
+	return nil
+}
+
+// ServerUrl returns url of backend, or empty string if backend is unknown or not configured.
+func (c *Config) ServerUrl(backend string) string {
+	switch strings.ToLower(backend) {
+	case "jellyfin":
+		return c.Jellyfin.Url
+	case "subsonic":
+		return c.Subsonic.Url
+	case "ampache":
+		return c.Ampache.Url
+	}
+	return ""
+}
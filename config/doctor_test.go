@@ -0,0 +1,149 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+	"reflect"
+	"testing"
+)
+
+func Test_editDistance(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "pagesize", b: "pagesize", want: 0},
+		{a: "page_size", b: "pagesize", want: 1},
+		{a: "loglevl", b: "loglevel", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "", b: "url", want: 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	keys := []string{"gui.pagesize", "gui.page_size", "gui.loglevel", "jellyfin.user_id",
+		"player.http_headers.x-token", "foo.bar"}
+	got := UnknownKeys(keys)
+	want := []Problem{
+		{Key: "gui.page_size", Message: "unknown key, value is not used", Fix: "did you mean 'gui.pagesize'?"},
+		{Key: "gui.loglevel", Message: "unknown key, value is not used", Fix: "did you mean 'player.loglevel'?"},
+		{Key: "jellyfin.user_id", Message: "unknown key, value is not used", Fix: "did you mean 'jellyfin.userid'?"},
+		{Key: "foo.bar", Message: "unknown key, value is not used", Fix: "remove key"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnknownKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestInvalidValues(t *testing.T) {
+	viper.Reset()
+	viper.Set("jellyfin.url", "http://localhost")
+	viper.Set("player.loglevel", "info")
+	viper.Set("gui.pagesize", 1000)
+	viper.Set("gui.theme", "Light")
+	viper.Set("gui.volume_steps", "20")
+	defer viper.Reset()
+
+	err := ConfigFromViper()
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	got := InvalidValues()
+	want := []Problem{{
+		Key:     "gui.pagesize",
+		Message: "invalid value '1000', using '100' instead",
+		Fix:     "see config.sample.yaml for valid values",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InvalidValues() = %v, want %v", got, want)
+	}
+}
+
+func TestConfig_Check(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *Config
+		keys []string
+	}{
+		{
+			name: "valid",
+			conf: &Config{Jellyfin: Jellyfin{Url: "http://localhost"}, Player: Player{Server: "jellyfin", LogLevel: "info"}},
+			keys: []string{},
+		},
+		{
+			name: "unsupported server",
+			conf: &Config{Player: Player{Server: "plex", LogLevel: "info"}},
+			keys: []string{"player.server"},
+		},
+		{
+			name: "secondary server not configured",
+			conf: &Config{
+				Jellyfin: Jellyfin{Url: "http://localhost"},
+				Player:   Player{Server: "jellyfin", SecondaryServer: "subsonic", LogLevel: "loud"},
+			},
+			keys: []string{"subsonic.url", "player.loglevel"},
+		},
+		{
+			name: "tls",
+			conf: &Config{
+				Jellyfin: Jellyfin{Url: "http://localhost"},
+				Player:   Player{Server: "jellyfin", LogLevel: "info", TlsClientCert: "client.pem"},
+			},
+			keys: []string{"player.tls_*"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := []string{}
+			for _, v := range tt.conf.Check() {
+				if !v.Error {
+					t.Errorf("%s: not an error", v.Key)
+				}
+				keys = append(keys, v.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("Check() problems = %v, want %v", keys, tt.keys)
+			}
+		})
+	}
+}
+
+func TestKeyBindings_Conflicts(t *testing.T) {
+	k := DefaultKeyBindings()
+	if conflicts := k.Conflicts(); len(conflicts) != 0 {
+		t.Errorf("default key bindings conflict: %v", conflicts)
+	}
+
+	k.Global.Stop = k.Global.PlayPause
+	conflicts := k.Conflicts()
+	if len(conflicts) != 1 || len(conflicts[0]) != 2 {
+		t.Fatalf("conflicts, got %v, want 1 conflict of 2 bindings", conflicts)
+	}
+	if conflicts[0][0].Action != "Play / pause" || conflicts[0][1].Action != "Stop" {
+		t.Errorf("conflicting actions, got %s and %s", conflicts[0][0].Action, conflicts[0][1].Action)
+	}
+}
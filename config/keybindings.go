@@ -18,7 +18,10 @@
 
 package config
 
-import "github.com/gdamore/tcell"
+import (
+	"github.com/gdamore/tcell"
+	"tryffel.net/go/jellycli/i18n"
+)
 
 var (
 	KeyBinds = DefaultKeyBindings()
@@ -36,6 +39,11 @@ type GlobalBindings struct {
 	VolumeDown tcell.Key
 	MuteUnmute tcell.Key
 	Shuffle    tcell.Key
+	// ToggleRemaining toggles showing elapsed / remaining song time
+	ToggleRemaining tcell.Key
+	// NextChapter and PreviousChapter move between chapters of playing song
+	NextChapter     tcell.Key
+	PreviousChapter tcell.Key
 }
 
 // NavigationBarBindings also override every other key
@@ -48,6 +56,33 @@ type NavigationBarBindings struct {
 	History  tcell.Key
 	Settings tcell.Key
 	Dump     tcell.Key
+	// Info shows details of highlighted item
+	Info tcell.Key
+	// ToggleNavigation shows / hides navigation pane
+	ToggleNavigation tcell.Key
+	// DockQueue shows / hides queue beside media view
+	DockQueue tcell.Key
+	// JumpToPlaying opens album of playing song, or selects it in queue
+	JumpToPlaying tcell.Key
+	// OpenPlaying opens playing song in server's web interface
+	OpenPlaying tcell.Key
+	// CopyPlaying copies playing song to clipboard with gui.copy_format
+	CopyPlaying tcell.Key
+	// ToggleVisualizer shows / hides audio visualizer
+	ToggleVisualizer tcell.Key
+	// CheatSheet shows searchable list of key bindings
+	CheatSheet tcell.Key
+	// SyncPlay opens group playback menu
+	SyncPlay tcell.Key
+	// Cast selects device to play on
+	Cast tcell.Key
+	// Library selects active music library
+	Library tcell.Key
+	// Chapters lists chapters of playing song
+	Chapters tcell.Key
+	// HistoryBack and HistoryForward move in navigation history, like in a browser
+	HistoryBack    tcell.Key
+	HistoryForward tcell.Key
 }
 
 // MovingBindings control moving cursor inside panel
@@ -87,6 +122,11 @@ func DefaultKeyBindings() KeyBindings {
 			VolumeDown: tcell.KeyF9,
 			MuteUnmute: tcell.KeyCtrlU,
 			Shuffle:    tcell.KeyCtrlD,
+
+			ToggleRemaining: tcell.KeyCtrlT,
+			// shift + F7 and shift + F4 in most terminals
+			NextChapter:     tcell.KeyF19,
+			PreviousChapter: tcell.KeyF16,
 		},
 		NavigationBar: NavigationBarBindings{
 			Help:    tcell.KeyF1,
@@ -94,6 +134,22 @@ func DefaultKeyBindings() KeyBindings {
 			Queue:   tcell.KeyF2,
 			History: tcell.KeyF3,
 			Dump:    tcell.KeyCtrlW,
+			Info:    tcell.KeyCtrlO,
+
+			ToggleNavigation: tcell.KeyCtrlN,
+			DockQueue:        tcell.KeyF8,
+			JumpToPlaying:    tcell.KeyCtrlG,
+			OpenPlaying:      tcell.KeyCtrlV,
+			CopyPlaying:      tcell.KeyCtrlX,
+			ToggleVisualizer: tcell.KeyF12,
+			CheatSheet:       tcell.KeyCtrlE,
+			SyncPlay:         tcell.KeyF11,
+			Cast:             tcell.KeyCtrlR,
+			Library:          tcell.KeyCtrlB,
+			Chapters:         tcell.KeyCtrlA,
+			Settings:         tcell.KeyCtrlP,
+			HistoryBack:      tcell.KeyCtrlZ,
+			HistoryForward:   tcell.KeyCtrlY,
 		},
 		Moving: MovingBindings{
 			Up:    tcell.KeyUp,
@@ -118,3 +174,81 @@ func DefaultKeyBindings() KeyBindings {
 	}
 	return k
 }
+
+// KeyBindingInfo describes single key binding for help pages.
+type KeyBindingInfo struct {
+	Group  string
+	Action string
+	Key    tcell.Key
+}
+
+// List returns global and navigation bar key bindings with descriptions in display order.
+// Bindings that are not set are omitted.
+func (k *KeyBindings) List() []KeyBindingInfo {
+	bindings := []KeyBindingInfo{
+		{i18n.N("Audio"), i18n.N("Play / pause"), k.Global.PlayPause},
+		{i18n.N("Audio"), i18n.N("Stop"), k.Global.Stop},
+		{i18n.N("Audio"), i18n.N("Next song"), k.Global.Next},
+		{i18n.N("Audio"), i18n.N("Previous song"), k.Global.Previous},
+		{i18n.N("Audio"), i18n.N("Seek forward"), k.Global.Forward},
+		{i18n.N("Audio"), i18n.N("Seek backward"), k.Global.Backward},
+		{i18n.N("Audio"), i18n.N("Volume up"), k.Global.VolumeUp},
+		{i18n.N("Audio"), i18n.N("Volume down"), k.Global.VolumeDown},
+		{i18n.N("Audio"), i18n.N("Mute / unmute"), k.Global.MuteUnmute},
+		{i18n.N("Audio"), i18n.N("Shuffle"), k.Global.Shuffle},
+		{i18n.N("Audio"), i18n.N("Elapsed / remaining time"), k.Global.ToggleRemaining},
+		{i18n.N("Audio"), i18n.N("Next chapter"), k.Global.NextChapter},
+		{i18n.N("Audio"), i18n.N("Previous chapter"), k.Global.PreviousChapter},
+
+		{i18n.N("Views"), i18n.N("Quit"), k.NavigationBar.Quit},
+		{i18n.N("Views"), i18n.N("Help"), k.NavigationBar.Help},
+		{i18n.N("Views"), i18n.N("Key binding cheat sheet"), k.NavigationBar.CheatSheet},
+		{i18n.N("Views"), i18n.N("View"), k.NavigationBar.View},
+		{i18n.N("Views"), i18n.N("Search"), k.NavigationBar.Search},
+		{i18n.N("Views"), i18n.N("Queue"), k.NavigationBar.Queue},
+		{i18n.N("Views"), i18n.N("History"), k.NavigationBar.History},
+		{i18n.N("Views"), i18n.N("Settings"), k.NavigationBar.Settings},
+		{i18n.N("Views"), i18n.N("Debug dump"), k.NavigationBar.Dump},
+		{i18n.N("Views"), i18n.N("Show details of highlighted song or album"), k.NavigationBar.Info},
+		{i18n.N("Views"), i18n.N("Show / hide navigation pane"), k.NavigationBar.ToggleNavigation},
+		{i18n.N("Views"), i18n.N("Show / hide queue beside current view"), k.NavigationBar.DockQueue},
+		{i18n.N("Views"), i18n.N("Jump to playing song"), k.NavigationBar.JumpToPlaying},
+		{i18n.N("Views"), i18n.N("Open playing song in browser"), k.NavigationBar.OpenPlaying},
+		{i18n.N("Views"), i18n.N("Copy playing song to clipboard"), k.NavigationBar.CopyPlaying},
+		{i18n.N("Views"), i18n.N("Show / hide visualizer"), k.NavigationBar.ToggleVisualizer},
+		{i18n.N("Views"), i18n.N("SyncPlay group playback"), k.NavigationBar.SyncPlay},
+		{i18n.N("Views"), i18n.N("Cast to another device"), k.NavigationBar.Cast},
+		{i18n.N("Views"), i18n.N("Select music library"), k.NavigationBar.Library},
+		{i18n.N("Views"), i18n.N("List chapters of playing song"), k.NavigationBar.Chapters},
+		{i18n.N("Views"), i18n.N("Go back to previous view"), k.NavigationBar.HistoryBack},
+		{i18n.N("Views"), i18n.N("Go forward to next view"), k.NavigationBar.HistoryForward},
+	}
+
+	list := make([]KeyBindingInfo, 0, len(bindings))
+	for _, v := range bindings {
+		if v.Key != 0 {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// Conflicts returns groups of bindings that share the same key. Only first of them would ever be triggered.
+func (k *KeyBindings) Conflicts() [][]KeyBindingInfo {
+	byKey := map[tcell.Key][]KeyBindingInfo{}
+	keys := []tcell.Key{}
+	for _, v := range k.List() {
+		if _, ok := byKey[v.Key]; !ok {
+			keys = append(keys, v.Key)
+		}
+		byKey[v.Key] = append(byKey[v.Key], v)
+	}
+
+	conflicts := [][]KeyBindingInfo{}
+	for _, key := range keys {
+		if len(byKey[key]) > 1 {
+			conflicts = append(conflicts, byKey[key])
+		}
+	}
+	return conflicts
+}
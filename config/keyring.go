@@ -0,0 +1,76 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+	"tryffel.net/go/jellycli/keyring"
+)
+
+// Keyring entries. Service name is shared, user identifies the backend.
+const (
+	keyringService      = "jellycli"
+	keyringUserJellyfin = "jellyfin"
+	keyringUserSubsonic = "subsonic"
+	keyringUserAmpache  = "ampache"
+)
+
+// keyringSecrets contains secrets that are known to be in keyring, so that they are not
+// rewritten every time config is saved.
+var keyringSecrets = map[string]string{}
+
+// keyringDisabled is set when keyring cannot be used, in which case secrets are stored in config file.
+var keyringDisabled = false
+
+// readSecret returns secret for user from keyring. If value is not empty, it is
+// from config file and is returned as is. It will be moved to keyring on next save.
+func readSecret(user, value string) string {
+	if value != "" || keyringDisabled {
+		return value
+	}
+	secret, err := keyring.Get(keyringService, user)
+	if err == keyring.ErrNotFound {
+		return ""
+	}
+	if err != nil {
+		logrus.Warningf("read %s token from keyring: %v", user, err)
+		return ""
+	}
+	keyringSecrets[user] = secret
+	return secret
+}
+
+// storeSecret stores secret for user in keyring and returns value to write to config file.
+// If keyring is not available, secret is returned and thus stored to config file.
+func storeSecret(user, secret string) string {
+	if secret == "" || keyringDisabled {
+		return secret
+	}
+	if stored, ok := keyringSecrets[user]; ok && stored == secret {
+		return ""
+	}
+	err := keyring.Set(keyringService, user, secret)
+	if err != nil {
+		logrus.Warningf("store %s token to keyring, using config file instead: %v", user, err)
+		keyringDisabled = true
+		return secret
+	}
+	keyringSecrets[user] = secret
+	return ""
+}
@@ -38,6 +38,11 @@ var (
 	AudioBufferPeriod          = time.Millisecond * 100
 
 	VolumeStepSize = 5
+
+	// InstantMixSize is how many songs instant mix contains.
+	InstantMixSize = 200
+	// InstantMixSkipPlayed leaves recently played songs out of instant mix.
+	InstantMixSkipPlayed = false
 )
 
 // audio configuration
@@ -55,6 +60,9 @@ const (
 	// Audio volume is logarithmic, which base to use
 	AudioVolumeLogBase = 2
 
+	// AudioMaxGainOffsetdB is the largest volume offset in decibels that can be set for a song or album.
+	AudioMaxGainOffsetdB = 12
+
 	CacheTimeout = time.Minute * 5
 )
 
@@ -0,0 +1,64 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// TlsConfig returns tls configuration for connecting to server. If no custom tls settings are set,
+// nil is returned and system defaults should be used.
+func (p *Player) TlsConfig() (*tls.Config, error) {
+	if p.TlsCaFile == "" && p.TlsClientCert == "" && p.TlsClientKey == "" && !p.TlsSkipVerify {
+		return nil, nil
+	}
+
+	conf := &tls.Config{InsecureSkipVerify: p.TlsSkipVerify}
+	if p.TlsCaFile != "" {
+		data, err := ioutil.ReadFile(p.TlsCaFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca file: %v", err)
+		}
+		// custom ca is added to system certificates so that public certificates still work
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in ca file %s", p.TlsCaFile)
+		}
+		conf.RootCAs = pool
+	}
+
+	if p.TlsClientCert != "" || p.TlsClientKey != "" {
+		if p.TlsClientCert == "" || p.TlsClientKey == "" {
+			return nil, errors.New("both client certificate and key must be set")
+		}
+		cert, err := tls.LoadX509KeyPair(p.TlsClientCert, p.TlsClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %v", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
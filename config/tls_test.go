@@ -0,0 +1,98 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestPlayer_TlsConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jellycli-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	invalidCa := path.Join(dir, "invalid.pem")
+	err = ioutil.WriteFile(invalidCa, []byte("not a certificate"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		player   Player
+		wantNil  bool
+		wantSkip bool
+		wantErr  bool
+	}{
+		{
+			name:    "defaults",
+			player:  Player{},
+			wantNil: true,
+		},
+		{
+			name:     "skip verify",
+			player:   Player{TlsSkipVerify: true},
+			wantSkip: true,
+		},
+		{
+			name:    "missing ca file",
+			player:  Player{TlsCaFile: path.Join(dir, "missing.pem")},
+			wantErr: true,
+		},
+		{
+			name:    "invalid ca file",
+			player:  Player{TlsCaFile: invalidCa},
+			wantErr: true,
+		},
+		{
+			name:    "client key without certificate",
+			player:  Player{TlsClientKey: invalidCa},
+			wantErr: true,
+		},
+		{
+			name:    "invalid client certificate",
+			player:  Player{TlsClientCert: invalidCa, TlsClientKey: invalidCa},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.player.TlsConfig()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TlsConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("TlsConfig() = %v, wantNil %v", got, tt.wantNil)
+				return
+			}
+			if got != nil && got.InsecureSkipVerify != tt.wantSkip {
+				t.Errorf("TlsConfig() InsecureSkipVerify = %v, want %v", got.InsecureSkipVerify, tt.wantSkip)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package control serves api for controlling the player from scripts and other programs, e.g. when running
+// headless as a daemon. Json-rpc api is served in unix socket, with methods following net/rpc conventions:
+// 'Player.PlayPause', 'Queue.Get'. Same api is served over http as rest api, along with a web remote.
+package control
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net"
+	"net/http"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"sync"
+	"tryffel.net/go/jellycli/interfaces"
+)
+
+// Server serves control api in unix socket and http.
+type Server struct {
+	player  *playerService
+	queue   *queueService
+	library *libraryService
+
+	listener net.Listener
+	http     *http.Server
+
+	lock   sync.Mutex
+	conns  map[net.Conn]bool
+	closed bool
+}
+
+// NewServer creates control api server. Start serving with ListenSocket and ListenHttp.
+func NewServer(player interfaces.Player, queue interfaces.QueueController, items interfaces.ItemController) *Server {
+	songs := newSongCache()
+	s := &Server{
+		player:  &playerService{player: player, queue: queue},
+		queue:   &queueService{player: player, queue: queue, items: items, songs: songs},
+		library: &libraryService{items: items, songs: songs},
+		conns:   map[net.Conn]bool{},
+	}
+	player.AddStatusCallback(s.player.updateStatus)
+	return s
+}
+
+// ListenSocket starts serving json-rpc api in given socket path. Stale socket left by previous instance is removed,
+// but if another instance is listening socket, return error.
+func (s *Server) ListenSocket(socket string) error {
+	if err := removeStaleSocket(socket); err != nil {
+		return err
+	}
+
+	server := rpc.NewServer()
+	err := server.RegisterName("Player", s.player)
+	if err == nil {
+		err = server.RegisterName("Queue", s.queue)
+	}
+	if err == nil {
+		err = server.RegisterName("Library", s.library)
+	}
+	if err != nil {
+		return fmt.Errorf("register control api: %v", err)
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return fmt.Errorf("listen control socket: %v", err)
+	}
+	// only user can control the player
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("set control socket permissions: %v", err)
+	}
+
+	s.lock.Lock()
+	s.listener = listener
+	s.lock.Unlock()
+	logrus.Infof("Serving control api at %s", socket)
+	go s.serve(server)
+	return nil
+}
+
+// Close stops serving, closes open connections and removes socket.
+func (s *Server) Close() error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return nil
+	}
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	httpServer, listener := s.http, s.listener
+	s.lock.Unlock()
+
+	var err error
+	if httpServer != nil {
+		err = httpServer.Close()
+	}
+	// unix listener removes socket file on close
+	if listener != nil {
+		if socketErr := listener.Close(); socketErr != nil {
+			err = socketErr
+		}
+	}
+	return err
+}
+
+func (s *Server) serve(server *rpc.Server) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.lock.Lock()
+			closed := s.closed
+			s.lock.Unlock()
+			if !closed {
+				logrus.Errorf("accept control connection: %v", err)
+			}
+			return
+		}
+		if !s.track(conn, true) {
+			conn.Close()
+			return
+		}
+		go func() {
+			server.ServeCodec(jsonrpc.NewServerCodec(conn))
+			s.track(conn, false)
+		}()
+	}
+}
+
+// track adds or removes open connection. Return false if server is closed.
+func (s *Server) track(conn net.Conn, open bool) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if open {
+		s.conns[conn] = true
+	} else {
+		delete(s.conns, conn)
+	}
+	return !s.closed
+}
+
+// removeStaleSocket removes socket, if it exists and no one is listening it.
+func removeStaleSocket(socket string) error {
+	info, err := os.Stat(socket)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("control socket: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("control socket: %s exists and is not a socket", socket)
+	}
+	if conn, err := net.Dial("unix", socket); err == nil {
+		conn.Close()
+		return fmt.Errorf("control socket %s is in use, is another instance running?", socket)
+	}
+	return os.Remove(socket)
+}
+
+// ErrNotRunning is returned when no instance is listening control socket.
+var ErrNotRunning = errors.New("jellycli is not running or control socket is disabled")
+
+// Call calls method in control socket, e.g. 'Player.PlayPause'. If socket cannot be connected,
+// return ErrNotRunning.
+func Call(socket string, method string, args interface{}, reply interface{}) error {
+	client, err := jsonrpc.Dial("unix", socket)
+	if err != nil {
+		logrus.Debugf("connect to control socket %s: %v", socket, err)
+		return ErrNotRunning
+	}
+	defer client.Close()
+	return client.Call(method, args, reply)
+}
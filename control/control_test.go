@@ -0,0 +1,124 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package control
+
+import (
+	"context"
+	"path"
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// fakePlayer records calls. Methods not overridden panic.
+type fakePlayer struct {
+	interfaces.Player
+	interfaces.QueueController
+	interfaces.ItemController
+
+	statusCb  func(status interfaces.AudioStatus)
+	calls     []string
+	queue     []*models.Song
+	albumSong *models.Song
+	searchHit *models.Song
+}
+
+func (f *fakePlayer) AddStatusCallback(cb func(status interfaces.AudioStatus)) { f.statusCb = cb }
+func (f *fakePlayer) PlayPause()                                               { f.calls = append(f.calls, "playpause") }
+func (f *fakePlayer) StopMedia()                                               { f.calls = append(f.calls, "stop") }
+func (f *fakePlayer) SetVolume(volume interfaces.AudioVolume)                  { f.calls = append(f.calls, "volume") }
+func (f *fakePlayer) Next()                                                    { f.calls = append(f.calls, "next") }
+func (f *fakePlayer) RemoveSong(index int)                                     { f.queue = append(f.queue[:index], f.queue[index+1:]...) }
+func (f *fakePlayer) GetQueue() []*models.Song                                 { return f.queue }
+func (f *fakePlayer) ClearQueue(first bool)                                    { f.queue = nil }
+func (f *fakePlayer) AddSongs(songs []*models.Song)                            { f.queue = append(f.queue, songs...) }
+
+func (f *fakePlayer) GetAlbumSongs(ctx context.Context, album models.Id) ([]*models.Song, error) {
+	return []*models.Song{f.albumSong}, nil
+}
+
+func (f *fakePlayer) Search(ctx context.Context, itemType models.ItemType, query string) ([]models.Item, error) {
+	if itemType != models.TypeSong {
+		return nil, nil
+	}
+	return []models.Item{f.searchHit}, nil
+}
+
+func TestServer(t *testing.T) {
+	socket := path.Join(t.TempDir(), "jellycli.sock")
+	player := &fakePlayer{
+		albumSong: &models.Song{Id: "song-1", Name: "first", Duration: 200, Album: "album-1"},
+		searchHit: &models.Song{Id: "song-2", Name: "second", Artists: []models.IdName{{Name: "artist"}}},
+	}
+	server := NewServer(player, player, player)
+	if err := server.ListenSocket(socket); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	if err := NewServer(&fakePlayer{}, player, player).ListenSocket(socket); err == nil {
+		t.Errorf("second server on same socket: no error")
+	}
+
+	if err := Call(socket, "Player.PlayPause", Empty{}, &Empty{}); err != nil {
+		t.Error(err)
+	}
+	if err := Call(socket, "Player.SetVolume", VolumeArgs{Volume: 101}, &Empty{}); err == nil {
+		t.Errorf("invalid volume: no error")
+	}
+
+	added := 0
+	err := Call(socket, "Queue.Add", QueueArgs{Type: "album", Id: "album-1", Replace: true}, &added)
+	if err != nil || added != 1 {
+		t.Errorf("add album: added %d, err: %v", added, err)
+	}
+	if err := Call(socket, "Queue.Add", QueueArgs{Type: "song", Id: "song-2"}, &added); err == nil {
+		t.Errorf("add song not searched: no error")
+	}
+
+	items := []Item{}
+	if err := Call(socket, "Library.Search", SearchArgs{Type: "Song", Query: "sec"}, &items); err != nil {
+		t.Fatal(err)
+	}
+	wantItems := []Item{{Id: "song-2", Type: "Song", Name: "second"}}
+	if !reflect.DeepEqual(items, wantItems) {
+		t.Errorf("search: got %v, want %v", items, wantItems)
+	}
+	if err := Call(socket, "Queue.Add", QueueArgs{Type: "song", Id: "song-2"}, &added); err != nil {
+		t.Error(err)
+	}
+
+	player.statusCb(interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Paused: true,
+		Song: player.albumSong, SongPast: 61500, Volume: 40})
+	status := Status{}
+	if err := Call(socket, "Player.Status", Empty{}, &status); err != nil {
+		t.Fatal(err)
+	}
+	wantStatus := Status{State: "paused", Position: 61, Volume: 40, Queue: 2,
+		Song: &Song{Id: "song-1", Name: "first", Artists: []string{}, Album: "album-1", Duration: 200}}
+	if !reflect.DeepEqual(status, wantStatus) {
+		t.Errorf("status: got %+v, want %+v", status, wantStatus)
+	}
+
+	wantCalls := []string{"playpause", "stop"}
+	if !reflect.DeepEqual(player.calls, wantCalls) {
+		t.Errorf("player calls: got %v, want %v", player.calls, wantCalls)
+	}
+}
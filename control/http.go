@@ -0,0 +1,213 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package control
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListenHttp starts serving rest api under /api/ and web remote at / in given address.
+// Api requests must have token in 'Authorization: Bearer <token>' header or in 'token' query parameter.
+//
+// Endpoints:
+//
+//	GET    /api/status
+//	POST   /api/player/{play-pause,play,pause,stop,next,previous,mute}
+//	POST   /api/player/{seek,volume,shuffle} with body SeekArgs, VolumeArgs or ShuffleArgs
+//	GET    /api/queue
+//	POST   /api/queue with body QueueArgs
+//	DELETE /api/queue, clear queue
+//	DELETE /api/queue/<index>
+//	GET    /api/search?type=<type>&q=<query>
+func (s *Server) ListenHttp(address string, token string) error {
+	if token == "" {
+		return errors.New("http remote requires token")
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("listen http remote: %v", err)
+	}
+
+	server := &http.Server{
+		Handler:      s.httpHandler(token),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	s.lock.Lock()
+	s.http = server
+	s.lock.Unlock()
+	logrus.Infof("Serving http remote at %s", listener.Addr())
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			logrus.Errorf("http remote: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) httpHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", serveRemotePage)
+	mux.Handle("/api/", authorize(token, http.HandlerFunc(s.serveApi)))
+	return mux
+}
+
+func authorize(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if got == "" {
+			got = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			writeJson(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func serveRemotePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(remotePage))
+}
+
+func (s *Server) serveApi(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/")
+	var reply interface{}
+	var err error
+
+	switch {
+	case path == "status" && r.Method == http.MethodGet:
+		status := &Status{}
+		reply, err = status, s.player.Status(Empty{}, status)
+	case strings.HasPrefix(path, "player/") && r.Method == http.MethodPost:
+		var ok bool
+		ok, err = s.playerAction(strings.TrimPrefix(path, "player/"), r)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+	case path == "queue" && r.Method == http.MethodGet:
+		songs := &[]Song{}
+		reply, err = songs, s.queue.Get(Empty{}, songs)
+	case path == "queue" && r.Method == http.MethodPost:
+		args := QueueArgs{}
+		if err = decodeBody(r, &args); err == nil {
+			added := 0
+			err = s.queue.Add(args, &added)
+			reply = map[string]int{"added": added}
+		}
+	case path == "queue" && r.Method == http.MethodDelete:
+		err = s.queue.Clear(Empty{}, &Empty{})
+	case strings.HasPrefix(path, "queue/") && r.Method == http.MethodDelete:
+		var index int
+		index, err = strconv.Atoi(strings.TrimPrefix(path, "queue/"))
+		if err == nil {
+			err = s.queue.Remove(IndexArgs{Index: index}, &Empty{})
+		}
+	case path == "search" && r.Method == http.MethodGet:
+		items := &[]Item{}
+		query := SearchArgs{Type: r.URL.Query().Get("type"), Query: r.URL.Query().Get("q")}
+		reply, err = items, s.library.Search(query, items)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if err != nil {
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if reply == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJson(w, http.StatusOK, reply)
+}
+
+// playerAction runs player action. If there is no such action, return false.
+func (s *Server) playerAction(action string, r *http.Request) (bool, error) {
+	actions := map[string]func(Empty, *Empty) error{
+		"play-pause": s.player.PlayPause,
+		"play":       s.player.Continue,
+		"pause":      s.player.Pause,
+		"stop":       s.player.Stop,
+		"next":       s.player.Next,
+		"previous":   s.player.Previous,
+		"mute":       s.player.ToggleMute,
+	}
+	if f, ok := actions[action]; ok {
+		return true, f(Empty{}, &Empty{})
+	}
+
+	switch action {
+	case "seek":
+		args := SeekArgs{}
+		if err := decodeBody(r, &args); err != nil {
+			return true, err
+		}
+		return true, s.player.Seek(args, &Empty{})
+	case "volume":
+		args := VolumeArgs{}
+		if err := decodeBody(r, &args); err != nil {
+			return true, err
+		}
+		return true, s.player.SetVolume(args, &Empty{})
+	case "shuffle":
+		args := ShuffleArgs{}
+		if err := decodeBody(r, &args); err != nil {
+			return true, err
+		}
+		return true, s.player.SetShuffle(args, &Empty{})
+	}
+	return false, nil
+}
+
+func decodeBody(r *http.Request, target interface{}) error {
+	err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(target)
+	if err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func writeJson(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		logrus.Debugf("write http remote response: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package control
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestServer_Http(t *testing.T) {
+	player := &fakePlayer{albumSong: &models.Song{Id: "song-1", Name: "first"}}
+	server := httptest.NewServer(NewServer(player, player, player).httpHandler("token"))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "web remote", method: "GET", path: "/", wantStatus: 200},
+		{name: "no token", method: "GET", path: "/api/status", wantStatus: 401},
+		{name: "invalid token", method: "GET", path: "/api/status", token: "invalid", wantStatus: 401},
+		{name: "token in query", method: "GET", path: "/api/status?token=token", wantStatus: 200},
+		{name: "action", method: "POST", path: "/api/player/next", token: "token", wantStatus: 204},
+		{name: "unknown action", method: "POST", path: "/api/player/rewind", token: "token", wantStatus: 404},
+		{name: "volume", method: "POST", path: "/api/player/volume", token: "token", body: `{"volume":30}`,
+			wantStatus: 204},
+		{name: "invalid volume", method: "POST", path: "/api/player/volume", token: "token",
+			body: `{"volume":300}`, wantStatus: 400},
+		{name: "invalid body", method: "POST", path: "/api/player/volume", token: "token", body: "{",
+			wantStatus: 400},
+		{name: "add album", method: "POST", path: "/api/queue", token: "token",
+			body: `{"type":"album","id":"album-1"}`, wantStatus: 200, wantBody: `{"added":1}`},
+		{name: "remove song", method: "DELETE", path: "/api/queue/0", token: "token", wantStatus: 204},
+		{name: "empty queue", method: "GET", path: "/api/queue", token: "token", wantStatus: 200,
+			wantBody: "[]"},
+		{name: "remove invalid index", method: "DELETE", path: "/api/queue/0", token: "token", wantStatus: 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("got status %d, want %d, body: %s", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantBody != "" && strings.TrimSpace(string(body)) != tt.wantBody {
+				t.Errorf("got body %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+
+	if !reflect.DeepEqual(player.calls, []string{"next", "volume"}) {
+		t.Errorf("player calls: got %v, want [next volume]", player.calls)
+	}
+}
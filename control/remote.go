@@ -0,0 +1,152 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package control
+
+// remotePage is web remote served by http remote. Token is read from url fragment, e.g. http://host:8097/#token,
+// and stored to browser local storage.
+const remotePage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Jellycli remote</title>
+<style>
+body { font-family: sans-serif; margin: 0 auto; max-width: 40em; padding: 1em; background: #111; color: #eee; }
+button { font-size: 1.2em; padding: 0.5em 0.8em; margin: 0.2em; background: #333; color: #eee; border: 0; border-radius: 4px; }
+input { font-size: 1em; padding: 0.4em; width: 100%; box-sizing: border-box; }
+ul { list-style: none; padding: 0; }
+li { padding: 0.4em 0; border-bottom: 1px solid #333; display: flex; justify-content: space-between; align-items: center; }
+li button { font-size: 0.9em; }
+.controls { text-align: center; }
+#song { font-size: 1.3em; font-weight: bold; }
+#error { color: #e66; }
+</style>
+</head>
+<body>
+<div id="error"></div>
+<div id="song">-</div>
+<div id="details"></div>
+<div class="controls">
+<button onclick="action('previous')">&#9198;</button>
+<button onclick="action('play-pause')">&#9199;</button>
+<button onclick="action('next')">&#9197;</button>
+<button onclick="action('stop')">&#9209;</button>
+<button onclick="action('seek', {seconds: -10})">-10s</button>
+<button onclick="action('seek', {seconds: 10})">+10s</button>
+</div>
+<p>Volume <input id="volume" type="range" min="0" max="100" onchange="action('volume', {volume: Number(this.value)})"></p>
+<form onsubmit="search(); return false;"><input id="query" placeholder="Search"></form>
+<ul id="results"></ul>
+<h3>Queue <button onclick="api('DELETE', 'queue').then(refresh)">Clear</button></h3>
+<ul id="queue"></ul>
+<script>
+if (location.hash.length > 1) {
+  localStorage.setItem('token', location.hash.substring(1));
+  history.replaceState(null, '', location.pathname);
+}
+var token = localStorage.getItem('token') || '';
+
+function api(method, path, body) {
+  return fetch('/api/' + path, {
+    method: method,
+    headers: {'Authorization': 'Bearer ' + token, 'Content-Type': 'application/json'},
+    body: body ? JSON.stringify(body) : undefined
+  }).then(function (resp) {
+    if (resp.status === 204) { return null; }
+    return resp.json().then(function (data) {
+      if (!resp.ok) { throw new Error(data.error || resp.statusText); }
+      return data;
+    });
+  }).catch(function (err) {
+    document.getElementById('error').textContent = err.message;
+    throw err;
+  });
+}
+
+function action(name, body) {
+  api('POST', 'player/' + name, body || {}).then(refresh);
+}
+
+function time(seconds) {
+  var s = seconds % 60;
+  return Math.floor(seconds / 60) + ':' + (s < 10 ? '0' : '') + s;
+}
+
+function item(text, buttons) {
+  var li = document.createElement('li');
+  var span = document.createElement('span');
+  span.textContent = text;
+  li.appendChild(span);
+  var div = document.createElement('div');
+  buttons.forEach(function (b) {
+    var button = document.createElement('button');
+    button.textContent = b[0];
+    button.onclick = b[1];
+    div.appendChild(button);
+  });
+  li.appendChild(div);
+  return li;
+}
+
+function refresh() {
+  api('GET', 'status').then(function (status) {
+    document.getElementById('error').textContent = '';
+    var song = status.song;
+    document.getElementById('song').textContent = song ? song.name : '-';
+    document.getElementById('details').textContent = song ?
+      status.artist + ' - ' + status.album + ' (' + time(status.position) + ' / ' + time(song.duration) + ') ' +
+      status.state : status.state;
+    var volume = document.getElementById('volume');
+    if (document.activeElement !== volume) { volume.value = status.volume; }
+  });
+  api('GET', 'queue').then(function (songs) {
+    var list = document.getElementById('queue');
+    list.innerHTML = '';
+    songs.forEach(function (song, i) {
+      list.appendChild(item(song.name + ' - ' + song.artists.join(', '), i === 0 ? [] : [
+        ['Remove', function () { api('DELETE', 'queue/' + i).then(refresh); }]]));
+    });
+  });
+}
+
+function search() {
+  var query = document.getElementById('query').value;
+  api('GET', 'search?q=' + encodeURIComponent(query)).then(function (items) {
+    var list = document.getElementById('results');
+    list.innerHTML = '';
+    items.forEach(function (it) {
+      var add = function (opts) {
+        return function () {
+          opts.type = it.type;
+          opts.id = it.id;
+          api('POST', 'queue', opts).then(refresh);
+        };
+      };
+      list.appendChild(item(it.type + ': ' + it.name,
+        [['Play', add({replace: true})], ['Next', add({next: true})], ['Add', add({})]]));
+    });
+  });
+}
+
+refresh();
+setInterval(refresh, 2000);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,195 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Response is reply to a command read with ServeCommands.
+type Response struct {
+	Command string      `json:"command"`
+	Ok      bool        `json:"ok"`
+	Error   string      `json:"error,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+}
+
+// QueueAdded is result of adding search result to queue.
+type QueueAdded struct {
+	Item  Item `json:"item"`
+	Added int  `json:"added"`
+}
+
+// command is a line-based command. Args is rest of the line after command name.
+type command struct {
+	usage string
+	run   func(s *Server, args string) (interface{}, error)
+}
+
+var commands = map[string]command{
+	"play":       {usage: "play", run: playerCommand((*playerService).Continue)},
+	"pause":      {usage: "pause", run: playerCommand((*playerService).Pause)},
+	"play-pause": {usage: "play-pause", run: playerCommand((*playerService).PlayPause)},
+	"stop":       {usage: "stop", run: playerCommand((*playerService).Stop)},
+	"next":       {usage: "next", run: playerCommand((*playerService).Next)},
+	"previous":   {usage: "previous", run: playerCommand((*playerService).Previous)},
+	"mute":       {usage: "mute", run: playerCommand((*playerService).ToggleMute)},
+	"seek":       {usage: "seek <seconds>", run: seekCommand},
+	"volume":     {usage: "volume <0-100>", run: volumeCommand},
+	"shuffle":    {usage: "shuffle <on|off>", run: shuffleCommand},
+	"status":     {usage: "status", run: statusCommand},
+	"queue":      {usage: "queue [query]", run: queueCommand},
+	"clear":      {usage: "clear", run: clearCommand},
+	"search":     {usage: "search <query>", run: searchCommand},
+}
+
+// ServeCommands reads commands from in, one per line, and writes a json Response for each of them to out,
+// one per line. Commands are e.g. 'play', 'next', 'status' and 'queue <query>', see 'help'.
+// Return when input ends or 'quit' is read. If quit, user asked to stop the application.
+func (s *Server) ServeCommands(in io.Reader, out io.Writer) (quit bool, err error) {
+	scanner := bufio.NewScanner(in)
+	encoder := json.NewEncoder(out)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		name, args := line, ""
+		if i := strings.IndexAny(line, " \t"); i > 0 {
+			name, args = line[:i], strings.TrimSpace(line[i:])
+		}
+		name = strings.ToLower(name)
+		if name == "quit" || name == "exit" {
+			return true, encoder.Encode(Response{Command: name, Ok: true})
+		}
+
+		response := s.runCommand(name, args)
+		if err := encoder.Encode(response); err != nil {
+			return false, fmt.Errorf("write response: %v", err)
+		}
+	}
+	return false, scanner.Err()
+}
+
+func (s *Server) runCommand(name, args string) Response {
+	response := Response{Command: name}
+	if name == "help" {
+		response.Ok = true
+		response.Result = commandUsage()
+		return response
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		response.Error = fmt.Sprintf("unknown command '%s', see 'help'", name)
+		return response
+	}
+	result, err := cmd.run(s, args)
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+	response.Ok = true
+	response.Result = result
+	return response
+}
+
+func playerCommand(action func(*playerService, Empty, *Empty) error) func(*Server, string) (interface{}, error) {
+	return func(s *Server, args string) (interface{}, error) {
+		if args != "" {
+			return nil, fmt.Errorf("unexpected arguments '%s'", args)
+		}
+		return nil, action(s.player, Empty{}, &Empty{})
+	}
+}
+
+func seekCommand(s *Server, args string) (interface{}, error) {
+	seconds, err := strconv.Atoi(args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seconds '%s'", args)
+	}
+	return nil, s.player.Seek(SeekArgs{Seconds: seconds}, &Empty{})
+}
+
+func volumeCommand(s *Server, args string) (interface{}, error) {
+	volume, err := strconv.Atoi(args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid volume '%s'", args)
+	}
+	return nil, s.player.SetVolume(VolumeArgs{Volume: volume}, &Empty{})
+}
+
+func shuffleCommand(s *Server, args string) (interface{}, error) {
+	switch strings.ToLower(args) {
+	case "on":
+		return nil, s.player.SetShuffle(ShuffleArgs{Enabled: true}, &Empty{})
+	case "off":
+		return nil, s.player.SetShuffle(ShuffleArgs{Enabled: false}, &Empty{})
+	}
+	return nil, fmt.Errorf("shuffle must be 'on' or 'off'")
+}
+
+func statusCommand(s *Server, args string) (interface{}, error) {
+	status := &Status{}
+	return status, s.player.Status(Empty{}, status)
+}
+
+// queueCommand returns queue, or if there is query, adds first search result to queue.
+func queueCommand(s *Server, args string) (interface{}, error) {
+	if args == "" {
+		songs := &[]Song{}
+		return songs, s.queue.Get(Empty{}, songs)
+	}
+
+	items := []Item{}
+	if err := s.library.Search(SearchArgs{Query: args}, &items); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no results for '%s'", args)
+	}
+	result := &QueueAdded{Item: items[0]}
+	return result, s.queue.Add(QueueArgs{Type: result.Item.Type, Id: result.Item.Id}, &result.Added)
+}
+
+func clearCommand(s *Server, args string) (interface{}, error) {
+	return nil, s.queue.Clear(Empty{}, &Empty{})
+}
+
+func searchCommand(s *Server, args string) (interface{}, error) {
+	if args == "" {
+		return nil, fmt.Errorf("search requires query")
+	}
+	items := &[]Item{}
+	return items, s.library.Search(SearchArgs{Query: args}, items)
+}
+
+// commandUsage lists usage of each command.
+func commandUsage() []string {
+	usage := []string{"help", "quit"}
+	for _, v := range commands {
+		usage = append(usage, v.usage)
+	}
+	sort.Strings(usage)
+	return usage
+}
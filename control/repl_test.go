@@ -0,0 +1,84 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package control
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestServer_ServeCommands(t *testing.T) {
+	player := &fakePlayer{
+		searchHit: &models.Song{Id: "song-2", Name: "second", Duration: 90},
+	}
+	server := NewServer(player, player, player)
+
+	in := strings.NewReader("play-pause\n\nNEXT\nvolume 200\nqueue sec\nqueue\nfoo\nquit\nstop\n")
+	out := &bytes.Buffer{}
+	quit, err := server.ServeCommands(in, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !quit {
+		t.Errorf("quit: got false, want true")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []struct {
+		command string
+		ok      bool
+		result  string
+	}{
+		{command: "play-pause", ok: true},
+		{command: "next", ok: true},
+		{command: "volume"},
+		{command: "queue", ok: true, result: `{"item":{"id":"song-2","type":"Song","name":"second"},"added":1}`},
+		{command: "queue", ok: true, result: `[{"id":"song-2","name":"second","artists":[],"album_id":"","duration":90}]`},
+		{command: "foo"},
+		{command: "quit", ok: true},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("responses: got %d, want %d: %s", len(lines), len(want), out.String())
+	}
+	for i, line := range lines {
+		response := struct {
+			Command string          `json:"command"`
+			Ok      bool            `json:"ok"`
+			Error   string          `json:"error"`
+			Result  json.RawMessage `json:"result"`
+		}{}
+		if err := json.Unmarshal([]byte(line), &response); err != nil {
+			t.Fatalf("parse response '%s': %v", line, err)
+		}
+		if response.Command != want[i].command || response.Ok != want[i].ok || string(response.Result) != want[i].result {
+			t.Errorf("response %d: got %s", i, line)
+		}
+		if !response.Ok && response.Error == "" {
+			t.Errorf("response %d: no error message", i)
+		}
+	}
+
+	wantCalls := []string{"playpause", "next"}
+	if !reflect.DeepEqual(player.calls, wantCalls) {
+		t.Errorf("player calls: got %v, want %v", player.calls, wantCalls)
+	}
+}
@@ -0,0 +1,401 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package control
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// maxCachedSongs limits songs remembered from search results and queue.
+const maxCachedSongs = 5000
+
+// searchTypes are searched when search has no item type.
+var searchTypes = []models.ItemType{models.TypeArtist, models.TypeAlbum, models.TypeSong, models.TypePlaylist}
+
+// Empty is argument or reply for methods that have none.
+type Empty struct{}
+
+// Song is a song in queue or in search results.
+type Song struct {
+	Id      string   `json:"id"`
+	Name    string   `json:"name"`
+	Artists []string `json:"artists"`
+	Album   string   `json:"album_id"`
+	// Duration in seconds
+	Duration int `json:"duration"`
+}
+
+// Item is a search result.
+type Item struct {
+	Id   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// Status is player status.
+type Status struct {
+	// State is one of 'playing', 'paused', 'stopped'
+	State  string `json:"state"`
+	Song   *Song  `json:"song"`
+	Album  string `json:"album"`
+	Artist string `json:"artist"`
+	// Position in seconds
+	Position int  `json:"position"`
+	Volume   int  `json:"volume"`
+	Muted    bool `json:"muted"`
+	Shuffle  bool `json:"shuffle"`
+	// Queue is number of songs in queue, including current song.
+	Queue int `json:"queue"`
+}
+
+// SeekArgs seeks current song by given seconds, negative seconds seek backwards.
+type SeekArgs struct {
+	Seconds int `json:"seconds"`
+}
+
+// VolumeArgs sets volume in range [0,100].
+type VolumeArgs struct {
+	Volume int `json:"volume"`
+}
+
+// MuteArgs mutes or un-mutes audio.
+type MuteArgs struct {
+	Muted bool `json:"muted"`
+}
+
+// ShuffleArgs enables or disables shuffle.
+type ShuffleArgs struct {
+	Enabled bool `json:"enabled"`
+}
+
+// QueueArgs adds artist, album, playlist or song to queue. Songs must have been returned by search or queue.
+// If Next, songs are played next. If Replace, queue is cleared and songs start playing.
+type QueueArgs struct {
+	Type    string `json:"type"`
+	Id      string `json:"id"`
+	Next    bool   `json:"next"`
+	Replace bool   `json:"replace"`
+}
+
+// IndexArgs points to item in queue, first index is 0.
+type IndexArgs struct {
+	Index int `json:"index"`
+}
+
+// SearchArgs searches items of type, or all types if empty, with query.
+type SearchArgs struct {
+	Type  string `json:"type"`
+	Query string `json:"query"`
+}
+
+func songFromModel(song *models.Song) Song {
+	s := Song{
+		Id:       song.Id.String(),
+		Name:     song.Name,
+		Album:    song.Album.String(),
+		Duration: song.Duration,
+		Artists:  make([]string, len(song.Artists)),
+	}
+	for i, v := range song.Artists {
+		s.Artists[i] = v.Name
+	}
+	return s
+}
+
+func statusFromAudio(audio interfaces.AudioStatus) Status {
+	status := Status{
+		State:    "stopped",
+		Position: audio.SongPast.Seconds(),
+		Volume:   int(audio.Volume),
+		Muted:    audio.Muted,
+		Shuffle:  audio.Shuffle,
+	}
+	if audio.State == interfaces.AudioStatePlaying {
+		status.State = "playing"
+		if audio.Paused {
+			status.State = "paused"
+		}
+	}
+	if audio.Song != nil {
+		song := songFromModel(audio.Song)
+		status.Song = &song
+	}
+	if audio.Album != nil {
+		status.Album = audio.Album.Name
+	}
+	if audio.Artist != nil {
+		status.Artist = audio.Artist.Name
+	}
+	return status
+}
+
+// parseItemType parses item type case-insensitively.
+func parseItemType(name string) (models.ItemType, error) {
+	for _, v := range searchTypes {
+		if strings.EqualFold(string(v), name) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("unknown item type '%s', must be one of artist, album, song, playlist", name)
+}
+
+// songCache remembers songs returned to clients, so that they can be queued with id.
+type songCache struct {
+	lock  sync.Mutex
+	songs map[models.Id]*models.Song
+}
+
+func newSongCache() *songCache {
+	return &songCache{songs: map[models.Id]*models.Song{}}
+}
+
+func (s *songCache) put(songs ...*models.Song) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.songs)+len(songs) > maxCachedSongs {
+		s.songs = map[models.Id]*models.Song{}
+	}
+	for _, v := range songs {
+		s.songs[v.Id] = v
+	}
+}
+
+func (s *songCache) get(id models.Id) (*models.Song, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	song, ok := s.songs[id]
+	return song, ok
+}
+
+// playerService controls playback.
+type playerService struct {
+	player interfaces.Player
+	queue  interfaces.QueueController
+
+	lock   sync.Mutex
+	status interfaces.AudioStatus
+}
+
+func (p *playerService) updateStatus(status interfaces.AudioStatus) {
+	p.lock.Lock()
+	p.status = status
+	p.lock.Unlock()
+}
+
+// Status returns current status.
+func (p *playerService) Status(args Empty, reply *Status) error {
+	p.lock.Lock()
+	*reply = statusFromAudio(p.status)
+	p.lock.Unlock()
+	reply.Queue = len(p.queue.GetQueue())
+	return nil
+}
+
+func (p *playerService) PlayPause(args Empty, reply *Empty) error {
+	p.player.PlayPause()
+	return nil
+}
+
+func (p *playerService) Pause(args Empty, reply *Empty) error {
+	p.player.Pause()
+	return nil
+}
+
+// Continue continues paused song.
+func (p *playerService) Continue(args Empty, reply *Empty) error {
+	p.player.Continue()
+	return nil
+}
+
+func (p *playerService) Stop(args Empty, reply *Empty) error {
+	p.player.StopMedia()
+	return nil
+}
+
+func (p *playerService) Next(args Empty, reply *Empty) error {
+	p.player.Next()
+	return nil
+}
+
+func (p *playerService) Previous(args Empty, reply *Empty) error {
+	p.player.Previous()
+	return nil
+}
+
+func (p *playerService) Seek(args SeekArgs, reply *Empty) error {
+	p.player.Seek(interfaces.AudioTick(args.Seconds * 1000))
+	return nil
+}
+
+func (p *playerService) SetVolume(args VolumeArgs, reply *Empty) error {
+	volume := interfaces.AudioVolume(args.Volume)
+	if !volume.InRange() {
+		return fmt.Errorf("volume must be in range [%d,%d]", interfaces.AudioVolumeMin, interfaces.AudioVolumeMax)
+	}
+	p.player.SetVolume(volume)
+	return nil
+}
+
+func (p *playerService) SetMute(args MuteArgs, reply *Empty) error {
+	p.player.SetMute(args.Muted)
+	return nil
+}
+
+func (p *playerService) ToggleMute(args Empty, reply *Empty) error {
+	p.player.ToggleMute()
+	return nil
+}
+
+func (p *playerService) SetShuffle(args ShuffleArgs, reply *Empty) error {
+	p.player.SetShuffle(args.Enabled)
+	return nil
+}
+
+// queueService reads and modifies queue.
+type queueService struct {
+	player interfaces.Player
+	queue  interfaces.QueueController
+	items  interfaces.ItemController
+	songs  *songCache
+}
+
+// Get returns songs in queue, first one being current song.
+func (q *queueService) Get(args Empty, reply *[]Song) error {
+	queue := q.queue.GetQueue()
+	q.songs.put(queue...)
+	songs := make([]Song, len(queue))
+	for i, v := range queue {
+		songs[i] = songFromModel(v)
+	}
+	*reply = songs
+	return nil
+}
+
+// Add adds item to queue and returns number of songs added.
+func (q *queueService) Add(args QueueArgs, reply *int) error {
+	itemType, err := parseItemType(args.Type)
+	if err != nil {
+		return err
+	}
+	songs, err := q.resolveSongs(itemType, models.Id(args.Id))
+	if err != nil {
+		return err
+	}
+	if len(songs) == 0 {
+		return fmt.Errorf("%s %s has no songs", strings.ToLower(string(itemType)), args.Id)
+	}
+
+	if args.Replace {
+		q.player.StopMedia()
+		q.queue.ClearQueue(true)
+		q.queue.AddSongs(songs)
+	} else if args.Next {
+		q.queue.PlayNext(songs)
+	} else {
+		q.queue.AddSongs(songs)
+	}
+	*reply = len(songs)
+	return nil
+}
+
+// Remove removes song in index from queue.
+func (q *queueService) Remove(args IndexArgs, reply *Empty) error {
+	if args.Index < 0 || args.Index >= len(q.queue.GetQueue()) {
+		return fmt.Errorf("no song in index %d", args.Index)
+	}
+	q.queue.RemoveSong(args.Index)
+	return nil
+}
+
+// Clear removes upcoming songs from queue. Current song keeps playing.
+func (q *queueService) Clear(args Empty, reply *Empty) error {
+	q.queue.ClearQueue(false)
+	return nil
+}
+
+func (q *queueService) resolveSongs(itemType models.ItemType, id models.Id) ([]*models.Song, error) {
+	switch itemType {
+	case models.TypeSong:
+		song, ok := q.songs.get(id)
+		if !ok {
+			return nil, fmt.Errorf("song %s not found, songs must be searched before adding them", id)
+		}
+		return []*models.Song{song}, nil
+	case models.TypeAlbum:
+		return q.items.GetAlbumSongs(context.Background(), id)
+	case models.TypePlaylist:
+		playlist := &models.Playlist{Id: id}
+		err := q.items.GetPlaylistSongs(context.Background(), playlist)
+		return playlist.Songs, err
+	case models.TypeArtist:
+		albums, err := q.items.GetArtistAlbums(context.Background(), id)
+		if err != nil {
+			return nil, err
+		}
+		var songs []*models.Song
+		for _, album := range albums {
+			albumSongs, err := q.items.GetAlbumSongs(context.Background(), album.Id)
+			if err != nil {
+				return nil, err
+			}
+			songs = append(songs, albumSongs...)
+		}
+		return songs, nil
+	}
+	return nil, fmt.Errorf("cannot queue %s", itemType)
+}
+
+// libraryService browses library.
+type libraryService struct {
+	items interfaces.ItemController
+	songs *songCache
+}
+
+// Search searches items. Found songs can be added to queue.
+func (l *libraryService) Search(args SearchArgs, reply *[]Item) error {
+	types := searchTypes
+	if args.Type != "" {
+		itemType, err := parseItemType(args.Type)
+		if err != nil {
+			return err
+		}
+		types = []models.ItemType{itemType}
+	}
+
+	items := []Item{}
+	for _, itemType := range types {
+		results, err := l.items.Search(context.Background(), itemType, args.Query)
+		if err != nil {
+			return fmt.Errorf("search %s: %v", strings.ToLower(string(itemType)), err)
+		}
+		for _, v := range results {
+			if song, ok := v.(*models.Song); ok {
+				l.songs.put(song)
+			}
+			items = append(items, Item{Id: v.GetId().String(), Type: string(v.GetType()), Name: v.GetName()})
+		}
+	}
+	*reply = items
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package history
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+	"tryffel.net/go/jellycli/models"
+)
+
+// Stats is listening summary for single song.
+type Stats struct {
+	Id     models.Id `json:"id"`
+	Title  string    `json:"title"`
+	Artist string    `json:"artist"`
+	Album  string    `json:"album"`
+	Plays  int       `json:"plays"`
+	// Listened is total listening time in seconds.
+	Listened   int       `json:"listened"`
+	LastPlayed time.Time `json:"last_played"`
+}
+
+// Filter returns plays that started at or after since.
+func Filter(plays []*Play, since time.Time) []*Play {
+	filtered := []*Play{}
+	for _, v := range plays {
+		if !v.Started.Before(since) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// SongStats sums plays by song, most played first.
+func SongStats(plays []*Play) []*Stats {
+	stats := []*Stats{}
+	songs := map[models.Id]*Stats{}
+	for _, v := range plays {
+		s, ok := songs[v.Id]
+		if !ok {
+			s = &Stats{Id: v.Id}
+			songs[v.Id] = s
+			stats = append(stats, s)
+		}
+		s.Title = v.Title
+		s.Artist = v.Artist
+		s.Album = v.Album
+		s.Plays++
+		s.Listened += v.Listened
+		if v.Started.After(s.LastPlayed) {
+			s.LastPlayed = v.Started
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Plays != stats[j].Plays {
+			return stats[i].Plays > stats[j].Plays
+		}
+		return stats[i].Listened > stats[j].Listened
+	})
+	return stats
+}
+
+// WriteJSON writes value, e.g. plays or stats, as indented json.
+func WriteJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// WritePlaysCSV writes plays as csv with header row.
+func WritePlaysCSV(w io.Writer, plays []*Play) error {
+	rows := [][]string{{"started", "artist", "album", "title", "duration", "listened", "completion", "id"}}
+	for _, v := range plays {
+		rows = append(rows, []string{v.Started.Format(time.RFC3339), v.Artist, v.Album, v.Title,
+			strconv.Itoa(v.Duration), strconv.Itoa(v.Listened), strconv.FormatFloat(v.Completion, 'f', 2, 64),
+			v.Id.String()})
+	}
+	return writeCsv(w, rows)
+}
+
+// WriteStatsCSV writes stats as csv with header row.
+func WriteStatsCSV(w io.Writer, stats []*Stats) error {
+	rows := [][]string{{"artist", "album", "title", "plays", "listened", "last_played", "id"}}
+	for _, v := range stats {
+		rows = append(rows, []string{v.Artist, v.Album, v.Title, strconv.Itoa(v.Plays),
+			strconv.Itoa(v.Listened), v.LastPlayed.Format(time.RFC3339), v.Id.String()})
+	}
+	return writeCsv(w, rows)
+}
+
+func writeCsv(w io.Writer, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	err := writer.WriteAll(rows)
+	if err != nil {
+		return err
+	}
+	return writer.Error()
+}
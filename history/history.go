@@ -0,0 +1,159 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+// Package history records played songs to a file and exports them, e.g. for analyzing own listening habits.
+package history
+
+import (
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// maxTickGap is the largest position change that is counted as listening. Larger changes are seeks.
+const maxTickGap = interfaces.AudioTick(5000)
+
+// Play is a single played song.
+type Play struct {
+	Id     models.Id `json:"id"`
+	Title  string    `json:"title"`
+	Artist string    `json:"artist"`
+	Album  string    `json:"album"`
+	// ArtistId and AlbumId link play to library, they are empty if unknown.
+	ArtistId models.Id `json:"artist_id,omitempty"`
+	AlbumId  models.Id `json:"album_id,omitempty"`
+	// Started is time when song started playing.
+	Started time.Time `json:"started"`
+	// Duration is song duration in seconds.
+	Duration int `json:"duration"`
+	// Listened is time song was actually played in seconds, without pauses and seeks.
+	Listened int `json:"listened"`
+	// Completion is share of song that was listened, from 0 to 1. It is 0 if duration is unknown.
+	Completion float64 `json:"completion"`
+}
+
+// Store saves finished plays.
+type Store interface {
+	AddPlay(play *Play) error
+}
+
+// Recorder saves every played song to store. Song is saved when next song
+// starts, player stops or recorder is closed.
+type Recorder struct {
+	store Store
+	now   func() time.Time
+
+	lock     sync.Mutex
+	current  *Play
+	position interfaces.AudioTick
+	listened interfaces.AudioTick
+	closed   bool
+}
+
+// NewRecorder starts recording songs from player to store.
+func NewRecorder(store Store, player interfaces.Player) *Recorder {
+	r := &Recorder{
+		store: store,
+		now:   time.Now,
+	}
+	player.AddStatusCallback(r.statusChanged)
+	return r
+}
+
+// Close writes current song and stops recording.
+func (r *Recorder) Close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return
+	}
+	r.finish()
+	r.closed = true
+}
+
+func (r *Recorder) statusChanged(status interfaces.AudioStatus) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return
+	}
+	if status.State == interfaces.AudioStateStopped || status.Song == nil {
+		r.finish()
+		return
+	}
+	if r.current == nil || r.current.Id != status.Song.Id {
+		r.finish()
+		r.start(status)
+		return
+	}
+	diff := status.SongPast - r.position
+	if diff > 0 && diff <= maxTickGap {
+		r.listened += diff
+	}
+	r.position = status.SongPast
+}
+
+// start new play. Lock must be held.
+func (r *Recorder) start(status interfaces.AudioStatus) {
+	r.current = &Play{
+		Id:       status.Song.Id,
+		Title:    status.Song.Name,
+		Started:  r.now().Truncate(time.Second),
+		Duration: status.Song.Duration,
+	}
+	if status.Artist != nil {
+		r.current.Artist = status.Artist.Name
+		r.current.ArtistId = status.Artist.Id
+	}
+	if status.Album != nil {
+		r.current.Album = status.Album.Name
+		r.current.AlbumId = status.Album.Id
+	}
+	r.position = status.SongPast
+	r.listened = 0
+}
+
+// finish saves current play, if it was listened at all. Lock must be held.
+func (r *Recorder) finish() {
+	if r.current == nil {
+		return
+	}
+	play := r.current
+	play.Listened = r.listened.Seconds()
+	r.current = nil
+	if play.Listened == 0 {
+		return
+	}
+	play.Completion = completion(play.Listened, play.Duration)
+	err := r.store.AddPlay(play)
+	if err != nil {
+		logrus.Errorf("write history: %v", err)
+	}
+}
+
+// completion returns share of duration listened, at most 1.
+func completion(listened, duration int) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	if listened >= duration {
+		return 1
+	}
+	return float64(listened) / float64(duration)
+}
@@ -0,0 +1,117 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package history
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+type fakePlayer struct {
+	interfaces.Player
+	statusCb func(status interfaces.AudioStatus)
+}
+
+func (f *fakePlayer) AddStatusCallback(cb func(status interfaces.AudioStatus)) { f.statusCb = cb }
+
+type memoryStore []*Play
+
+func (m *memoryStore) AddPlay(play *Play) error {
+	*m = append(*m, play)
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	store := &memoryStore{}
+	player := &fakePlayer{}
+	r := NewRecorder(store, player)
+	started := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return started }
+
+	first := interfaces.AudioStatus{
+		State:  interfaces.AudioStatePlaying,
+		Song:   &models.Song{Id: "1", Name: "first", Duration: 180},
+		Artist: &models.Artist{Name: "artist"},
+		Album:  &models.Album{Name: "album"},
+	}
+	for _, tick := range []interfaces.AudioTick{0, 1000, 2000, 60000, 61000, 61000, 62000} {
+		first.SongPast = tick
+		player.statusCb(first)
+	}
+	// skipped right away, not recorded
+	player.statusCb(interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Song: &models.Song{Id: "2"}})
+	third := interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Song: &models.Song{Id: "3", Name: "third"}}
+	for _, tick := range []interfaces.AudioTick{0, 1000, 2000} {
+		third.SongPast = tick
+		player.statusCb(third)
+	}
+	r.Close()
+	player.statusCb(first)
+
+	got := *store
+	want := []*Play{
+		{Id: "1", Title: "first", Artist: "artist", Album: "album", Started: started, Duration: 180, Listened: 4,
+			Completion: 4.0 / 180},
+		{Id: "3", Title: "third", Started: started, Listened: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d plays, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Started.Equal(want[i].Started) {
+			got[i].Started = want[i].Started
+		}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("play %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSongStats(t *testing.T) {
+	day := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	plays := []*Play{
+		{Id: "1", Title: "a", Started: day, Listened: 100},
+		{Id: "2", Title: "b", Started: day.Add(time.Hour), Listened: 10},
+		{Id: "2", Title: "b", Started: day.Add(2 * time.Hour), Listened: 20},
+		{Id: "3", Title: "c", Started: day.Add(3 * time.Hour), Listened: 200},
+	}
+	stats := SongStats(Filter(plays, day.Add(time.Minute)))
+	want := []*Stats{
+		{Id: "2", Title: "b", Plays: 2, Listened: 30, LastPlayed: day.Add(2 * time.Hour)},
+		{Id: "3", Title: "c", Plays: 1, Listened: 200, LastPlayed: day.Add(3 * time.Hour)},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("SongStats() = %+v, want %+v", stats, want)
+	}
+
+	buf := &bytes.Buffer{}
+	err := WriteStatsCSV(buf, stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCsv := "artist,album,title,plays,listened,last_played,id\n" +
+		",,b,2,30,2020-11-01T14:00:00Z,2\n" +
+		",,c,1,200,2020-11-01T15:00:00Z,3\n"
+	if buf.String() != wantCsv {
+		t.Errorf("WriteStatsCSV() = %q, want %q", buf.String(), wantCsv)
+	}
+}
@@ -0,0 +1,273 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package hooks runs shell commands or posts webhooks on player events, e.g. for home automation or scrobbling.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+const (
+	// hookTimeout is max duration of single hook.
+	hookTimeout = 30 * time.Second
+	// maxPendingEvents is max number of events waiting for hooks. Further events are dropped.
+	maxPendingEvents = 20
+)
+
+// Event names
+const (
+	EventSongChange = "song_change"
+	EventPause      = "pause"
+	EventQueueEmpty = "queue_empty"
+)
+
+// Event is sent to hooks.
+type Event struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	// Song is current song, or nil if there's none.
+	Song *Song `json:"song"`
+	// Position in seconds
+	Position int `json:"position"`
+}
+
+// Song contains song metadata.
+type Song struct {
+	Id       string   `json:"id"`
+	Name     string   `json:"name"`
+	Artists  []string `json:"artists"`
+	Album    string   `json:"album"`
+	AlbumId  string   `json:"album_id"`
+	Artist   string   `json:"album_artist"`
+	Year     int      `json:"year"`
+	Duration int      `json:"duration"`
+}
+
+// Hooks runs configured hooks on player events. Hooks are run one at a time in order of events.
+type Hooks struct {
+	conf   config.Hooks
+	client *http.Client
+	events chan Event
+
+	lock     sync.Mutex
+	song     models.Id
+	paused   bool
+	queueLen int
+	status   interfaces.AudioStatus
+	closed   bool
+}
+
+// NewHooks starts running hooks on events from player and queue.
+func NewHooks(conf config.Hooks, player interfaces.Player, queue interfaces.QueueController) *Hooks {
+	h := &Hooks{
+		conf:     conf,
+		client:   &http.Client{Timeout: hookTimeout},
+		events:   make(chan Event, maxPendingEvents),
+		queueLen: len(queue.GetQueue()),
+	}
+	player.AddStatusCallback(h.statusChanged)
+	queue.AddQueueChangedCallback(h.queueChanged)
+	go h.loop()
+	return h
+}
+
+// Close stops running hooks. Pending events are dropped.
+func (h *Hooks) Close() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if !h.closed {
+		h.closed = true
+		close(h.events)
+	}
+}
+
+func (h *Hooks) statusChanged(status interfaces.AudioStatus) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.status = status
+
+	var song models.Id
+	if status.Song != nil && status.State == interfaces.AudioStatePlaying {
+		song = status.Song.Id
+	}
+	if song != "" && song != h.song {
+		h.send(EventSongChange)
+	}
+	h.song = song
+
+	paused := song != "" && status.Paused
+	if paused && !h.paused {
+		h.send(EventPause)
+	}
+	h.paused = paused
+}
+
+func (h *Hooks) queueChanged(queue []*models.Song) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if len(queue) == 0 && h.queueLen > 0 {
+		h.send(EventQueueEmpty)
+	}
+	h.queueLen = len(queue)
+}
+
+// send queues event, if there is hook for it. Lock must be held.
+func (h *Hooks) send(event string) {
+	if h.closed || h.hook(event) == "" {
+		return
+	}
+	e := Event{
+		Event:    event,
+		Time:     time.Now(),
+		Position: h.status.SongPast.Seconds(),
+	}
+	if h.status.Song != nil && event != EventQueueEmpty {
+		e.Song = songFromStatus(h.status)
+	}
+	select {
+	case h.events <- e:
+	default:
+		logrus.Warningf("too many pending hooks, drop event %s", event)
+	}
+}
+
+func (h *Hooks) hook(event string) string {
+	switch event {
+	case EventSongChange:
+		return h.conf.OnSongChange
+	case EventPause:
+		return h.conf.OnPause
+	case EventQueueEmpty:
+		return h.conf.OnQueueEmpty
+	}
+	return ""
+}
+
+func (h *Hooks) loop() {
+	for event := range h.events {
+		hook := h.hook(event.Event)
+		var err error
+		if isUrl(hook) {
+			err = h.post(hook, event)
+		} else {
+			err = runCommand(hook, event)
+		}
+		if err != nil {
+			logrus.Errorf("hook %s: %v", event.Event, err)
+		}
+	}
+}
+
+func isUrl(hook string) bool {
+	return strings.HasPrefix(hook, "http://") || strings.HasPrefix(hook, "https://")
+}
+
+// post posts event as json to url.
+func (h *Hooks) post(url string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", config.AppName+"/"+config.Version)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("post webhook: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// runCommand runs command in shell. Event is passed in environment variables and as json in stdin.
+func runCommand(command string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, shell[0], append(shell[1:], command)...)
+	cmd.Env = append(os.Environ(), eventEnv(event)...)
+	cmd.Stdin = bytes.NewReader(body)
+	output, err := cmd.CombinedOutput()
+	if len(output) > 0 {
+		logrus.Debugf("hook %s output: %s", event.Event, output)
+	}
+	if err != nil {
+		return fmt.Errorf("run command: %v", err)
+	}
+	return nil
+}
+
+func eventEnv(event Event) []string {
+	env := []string{"JELLYCLI_EVENT=" + event.Event, "JELLYCLI_POSITION=" + strconv.Itoa(event.Position)}
+	if song := event.Song; song != nil {
+		env = append(env,
+			"JELLYCLI_SONG_ID="+song.Id,
+			"JELLYCLI_SONG_NAME="+song.Name,
+			"JELLYCLI_ARTIST="+strings.Join(song.Artists, ", "),
+			"JELLYCLI_ALBUM="+song.Album,
+			"JELLYCLI_DURATION="+strconv.Itoa(song.Duration),
+		)
+	}
+	return env
+}
+
+func songFromStatus(status interfaces.AudioStatus) *Song {
+	song := &Song{
+		Id:       status.Song.Id.String(),
+		Name:     status.Song.Name,
+		AlbumId:  status.Song.Album.String(),
+		Duration: status.Song.Duration,
+		Artists:  make([]string, len(status.Song.Artists)),
+	}
+	for i, v := range status.Song.Artists {
+		song.Artists[i] = v.Name
+	}
+	if status.Album != nil {
+		song.Album = status.Album.Name
+		song.Year = status.Album.Year
+	}
+	if status.Artist != nil {
+		song.Artist = status.Artist.Name
+	}
+	return song
+}
@@ -0,0 +1,112 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package hooks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestHooks_events(t *testing.T) {
+	h := &Hooks{
+		conf:   config.Hooks{OnSongChange: "a", OnPause: "b", OnQueueEmpty: "c"},
+		events: make(chan Event, maxPendingEvents),
+	}
+	first := &models.Song{Id: "1", Name: "first"}
+	second := &models.Song{Id: "2", Name: "second"}
+	playing := interfaces.AudioStatePlaying
+
+	h.queueChanged([]*models.Song{first, second})
+	h.statusChanged(interfaces.AudioStatus{State: playing, Song: first})
+	h.statusChanged(interfaces.AudioStatus{State: playing, Song: first, SongPast: 1000})
+	h.statusChanged(interfaces.AudioStatus{State: playing, Song: first, SongPast: 2000, Paused: true})
+	h.statusChanged(interfaces.AudioStatus{State: playing, Song: first, SongPast: 2000, Paused: true})
+	h.statusChanged(interfaces.AudioStatus{State: playing, Song: first, SongPast: 3000})
+	h.statusChanged(interfaces.AudioStatus{State: playing, Song: second})
+	h.queueChanged([]*models.Song{second})
+	h.queueChanged([]*models.Song{})
+	h.statusChanged(interfaces.AudioStatus{State: interfaces.AudioStateStopped})
+	h.queueChanged([]*models.Song{})
+	h.Close()
+
+	var got []string
+	for e := range h.events {
+		name := e.Event
+		if e.Song != nil {
+			name += ":" + e.Song.Name
+		}
+		got = append(got, name)
+	}
+	want := []string{"song_change:first", "pause:first", "song_change:second", "queue_empty"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got events %v, want %v", got, want)
+	}
+}
+
+func TestHooks_post(t *testing.T) {
+	var got Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s request, content type %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	h := &Hooks{client: server.Client()}
+	event := Event{Event: EventSongChange, Song: &Song{Id: "1", Name: "song", Artists: []string{"artist"}}}
+	if err := h.post(server.URL, event); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, event) {
+		t.Errorf("posted %+v, want %+v", got, event)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses sh")
+	}
+	file := path.Join(t.TempDir(), "out")
+	event := Event{Event: EventSongChange, Song: &Song{Id: "1", Name: "song"}}
+	err := runCommand("echo $JELLYCLI_EVENT $JELLYCLI_SONG_NAME > "+file+"; cat >> "+file, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitN(string(data), "\n", 2)
+	if lines[0] != "song_change song" || !strings.HasPrefix(lines[1], `{"event":"song_change"`) {
+		t.Errorf("command output: %s", data)
+	}
+}
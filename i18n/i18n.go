@@ -0,0 +1,123 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+// Package i18n translates user interface texts. Texts are written in English in code, and English text is also
+// the key of translation. Translations are read from locale files, which map English texts to translated texts.
+// Missing and empty translations fall back to English.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language of texts in code.
+const DefaultLanguage = "en"
+
+var (
+	lock         sync.RWMutex
+	language     = DefaultLanguage
+	translations = map[string]string{}
+)
+
+// T returns translated text, or text itself if there's no translation.
+func T(text string) string {
+	lock.RLock()
+	defer lock.RUnlock()
+	if translated, ok := translations[text]; ok {
+		return translated
+	}
+	return text
+}
+
+// Tf translates format and formats it with args, like fmt.Sprintf.
+func Tf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// N marks text for translation without translating it. Use it for package level texts,
+// which are translated with T when shown.
+func N(text string) string {
+	return text
+}
+
+// Language returns current language.
+func Language() string {
+	lock.RLock()
+	defer lock.RUnlock()
+	return language
+}
+
+// Set sets language and its translations. Empty translations are ignored.
+func Set(lang string, texts map[string]string) {
+	lock.Lock()
+	defer lock.Unlock()
+	language = lang
+	translations = make(map[string]string, len(texts))
+	for k, v := range texts {
+		if v != "" {
+			translations[k] = v
+		}
+	}
+}
+
+// Parse parses locale file, which is a yaml map of English texts to translations.
+func Parse(data []byte) (map[string]string, error) {
+	texts := map[string]string{}
+	err := yaml.Unmarshal(data, &texts)
+	return texts, err
+}
+
+// Load loads translations for lang from first directory that has locale file '<lang>.yaml'.
+// If there's no file for region, e.g. 'pt_BR', language file 'pt.yaml' is used.
+// Empty language and DefaultLanguage use texts in code.
+func Load(lang string, dirs ...string) error {
+	lang = strings.TrimSpace(lang)
+	if lang == "" || lang == DefaultLanguage {
+		Set(DefaultLanguage, nil)
+		return nil
+	}
+	names := []string{lang}
+	if i := strings.IndexAny(lang, "_-"); i > 0 {
+		names = append(names, lang[:i])
+	}
+	for _, name := range names {
+		for _, dir := range dirs {
+			file := path.Join(dir, name+".yaml")
+			data, err := ioutil.ReadFile(file)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			texts, err := Parse(data)
+			if err != nil {
+				return fmt.Errorf("parse locale file %s: %v", file, err)
+			}
+			Set(lang, texts)
+			return nil
+		}
+	}
+	return fmt.Errorf("no locale file for language '%s' in %s", lang, strings.Join(dirs, ", "))
+}
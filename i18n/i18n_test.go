@@ -0,0 +1,198 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package i18n
+
+import (
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update locale template")
+
+const templateFile = "../locales/template.yaml"
+
+// sourceDirs contain translated texts.
+var sourceDirs = []string{"../ui", "../config"}
+
+const templateHeader = `# Jellycli translation template. Copy this file to <language>.yaml, e.g. fi.yaml, and fill in translations.
+# Keys are English texts of user interface, empty translations show English text.
+# Keep format verbs like %s and %d and color tags like [yellow] in translations.
+`
+
+func TestLoad(t *testing.T) {
+	defer Set(DefaultLanguage, nil)
+	dir := t.TempDir()
+	err := ioutil.WriteFile(path.Join(dir, "fi.yaml"), []byte("# comment\n\"Play all\": \"Soita kaikki\"\n"+
+		"\"Added %d songs to queue\": \"Lisätty %d kappaletta jonoon\"\n\"Queue\": \"\"\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Load("fi_FI", t.TempDir(), dir); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := Language(); got != "fi_FI" {
+		t.Errorf("Language() = %s, want fi_FI", got)
+	}
+	if got := T("Play all"); got != "Soita kaikki" {
+		t.Errorf("T() = %s, want Soita kaikki", got)
+	}
+	if got := Tf("Added %d songs to queue", 3); got != "Lisätty 3 kappaletta jonoon" {
+		t.Errorf("Tf() = %s", got)
+	}
+	if got := T("Queue"); got != "Queue" {
+		t.Errorf("T() empty translation = %s, want Queue", got)
+	}
+
+	if err := Load("sv", dir); err == nil {
+		t.Error("Load() missing language, want error")
+	}
+	if err := Load(""); err != nil || T("Play all") != "Play all" {
+		t.Errorf("Load() default language did not reset translations, error %v", err)
+	}
+}
+
+// TestTemplate checks that locale template has all translated texts in source.
+// Run 'go test ./i18n -update' to update template.
+func TestTemplate(t *testing.T) {
+	texts, err := sourceTexts(sourceDirs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		err = ioutil.WriteFile(templateFile, []byte(formatTemplate(texts)), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(templateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template, err := Parse(data)
+	if err != nil {
+		t.Fatalf("parse template: %v", err)
+	}
+	for _, v := range texts {
+		if _, ok := template[v]; !ok {
+			t.Errorf("template is missing %q, run 'go test ./i18n -update'", v)
+		}
+	}
+	if len(template) != len(texts) {
+		t.Errorf("template has %d texts, source has %d, run 'go test ./i18n -update'", len(template), len(texts))
+	}
+}
+
+// sourceTexts returns sorted string literals passed to T, Tf and N in go files in dirs.
+func sourceTexts(dirs ...string) ([]string, error) {
+	found := map[string]bool{}
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+				return err
+			}
+			f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+			if err != nil {
+				return err
+			}
+			ast.Inspect(f, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok || len(call.Args) == 0 {
+					return true
+				}
+				selector, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if pkg, ok := selector.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+					return true
+				}
+				switch selector.Sel.Name {
+				case "T", "Tf", "N":
+					if text, ok := stringValue(call.Args[0]); ok {
+						found[text] = true
+					}
+				}
+				return true
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	texts := make([]string, 0, len(found))
+	for k := range found {
+		texts = append(texts, k)
+	}
+	sort.Strings(texts)
+	return texts, nil
+}
+
+// stringValue returns value of string literal or concatenated literals.
+func stringValue(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		text, err := strconv.Unquote(e.Value)
+		return text, err == nil
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := stringValue(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := stringValue(e.Y)
+		return x + y, ok
+	case *ast.ParenExpr:
+		return stringValue(e.X)
+	}
+	return "", false
+}
+
+// formatTemplate formats texts as yaml with empty translations. Go quoted strings are valid
+// yaml double-quoted strings. Yaml limits implicit keys to 1024 characters, so longer keys are explicit.
+func formatTemplate(texts []string) string {
+	b := strings.Builder{}
+	b.WriteString(templateHeader)
+	for _, v := range texts {
+		key := strconv.Quote(v)
+		if len(key) > 1000 {
+			b.WriteString("? " + key + "\n")
+		} else {
+			b.WriteString(key)
+		}
+		b.WriteString(": \"\"\n")
+	}
+	return b.String()
+}
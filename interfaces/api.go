@@ -58,8 +58,8 @@ type ApiPlaybackState struct {
 	IsMuted  bool
 	// Total length of current playlist in seconds
 	PlaylistLength int
-	// Position in seconds
-	Position int
+	// Position in song
+	Position AudioTick
 	// Volume in 0-100
 	Volume int
 
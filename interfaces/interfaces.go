@@ -20,8 +20,10 @@
 package interfaces
 
 import (
+	"context"
 	"errors"
 	"math"
+	"strings"
 	"time"
 	"tryffel.net/go/jellycli/config"
 	"tryffel.net/go/jellycli/models"
@@ -61,61 +63,307 @@ type QueueController interface {
 	SetHistoryChangedCallback(func(songs []*models.Song))
 }
 
+// QueueSource tells which group song in queue belongs to. Songs added to queue at the same time,
+// e.g. an album or a playlist, form a group.
+type QueueSource struct {
+	// Group identifies songs that were added at the same time.
+	Group int
+	// Name is where songs were added from, e.g. album or playlist name. Empty if unknown.
+	Name string
+}
+
+// QueueGroupController is a queue that remembers where songs were added from.
+type QueueGroupController interface {
+	// AddSongsFrom adds songs to the end of queue as a new group with given source name.
+	AddSongsFrom(source string, songs []*models.Song)
+	// GetQueueSources returns source of each song, in same order as QueueController.GetQueue.
+	GetQueueSources() []QueueSource
+	// RemoveGroup removes songs of group from queue. Currently playing song is not removed.
+	RemoveGroup(group int)
+}
+
 //MediaManager manages media: artists, albums, songs
+//
+// Requests that load views take context, which is cancelled once response is no longer needed,
+// e.g. user has navigated to another view. Cancelled requests return ctx.Err().
 type ItemController interface {
 	// Search returns list of items based on search query. Item types
 	// Queue and history returns error.
-	Search(itemType models.ItemType, query string) ([]models.Item, error)
+	Search(ctx context.Context, itemType models.ItemType, query string) ([]models.Item, error)
 	// GetArtists gets artist with given paging. Only PageSize and CurrentPage are used. Total count is returned
-	GetArtists(opts *QueryOpts) ([]*models.Artist, int, error)
+	GetArtists(ctx context.Context, opts *QueryOpts) ([]*models.Artist, int, error)
 
 	// GetAlbumArtists returns artists that are marked as album artists. See GetArtists.
-	GetAlbumArtists(paging Paging) ([]*models.Artist, int, error)
+	GetAlbumArtists(ctx context.Context, paging Paging) ([]*models.Artist, int, error)
 	// GetAlbums gets albums with given paging. Only PageSize and CurrentPage are used. Total count is returned
-	GetAlbums(opts *QueryOpts) ([]*models.Album, int, error)
+	GetAlbums(ctx context.Context, opts *QueryOpts) ([]*models.Album, int, error)
+
+	GetArtistAlbums(ctx context.Context, artist models.Id) ([]*models.Album, error)
+
+	// GetArtistAppearsOn returns albums that artist contributes to, excluding artist's own albums.
+	GetArtistAppearsOn(ctx context.Context, artist models.Id) ([]*models.Album, error)
+
+	// GetArtistTopSongs returns artist's most played songs, max limit songs.
+	GetArtistTopSongs(ctx context.Context, artist *models.Artist, limit int) ([]*models.Song, error)
+
+	// GetArtistOverview returns artist biography.
+	GetArtistOverview(ctx context.Context, artist *models.Artist) (string, error)
+
+	// GetAlbum returns album with details, such as overview and genres.
+	GetAlbum(ctx context.Context, id models.Id) (*models.Album, error)
+
+	GetAlbumSongs(ctx context.Context, album models.Id) ([]*models.Song, error)
 
-	GetArtistAlbums(artist models.Id) ([]*models.Album, error)
+	// GetItemInfo returns detailed info of song or album, such as file path and codec.
+	GetItemInfo(ctx context.Context, item models.Item) (*models.ItemInfo, error)
 
-	GetAlbumSongs(album models.Id) ([]*models.Song, error)
-	GetPlaylists() ([]*models.Playlist, error)
+	GetPlaylists(ctx context.Context) ([]*models.Playlist, error)
 	// GetPlaylistSongs fills songs array for playlist. If there's error, songs will not be filled
-	GetPlaylistSongs(playlist *models.Playlist) error
-	GetFavoriteArtists() ([]*models.Artist, error)
-	GetFavoriteAlbums(paging Paging) ([]*models.Album, int, error)
+	GetPlaylistSongs(ctx context.Context, playlist *models.Playlist) error
+	GetFavoriteArtists(ctx context.Context) ([]*models.Artist, error)
+	GetFavoriteAlbums(ctx context.Context, paging Paging) ([]*models.Album, int, error)
 
 	// GetSimilarArtists returns similar artists for artist id
-	GetSimilarArtists(artist models.Id) ([]*models.Artist, error)
+	GetSimilarArtists(ctx context.Context, artist models.Id) ([]*models.Artist, error)
 
-	GetSimilarAlbums(album models.Id) ([]*models.Album, error)
+	GetSimilarAlbums(ctx context.Context, album models.Id) ([]*models.Album, error)
 
-	GetLatestAlbums() ([]*models.Album, error)
+	// GetLatestAlbums returns albums that were most recently added to library.
+	GetLatestAlbums(ctx context.Context) ([]*models.Album, error)
 
-	GetRecentlyPlayed(paging Paging) ([]*models.Song, int, error)
+	// GetRecentlyReleasedAlbums returns albums sorted by release date, newest first.
+	GetRecentlyReleasedAlbums(ctx context.Context) ([]*models.Album, error)
+
+	GetRecentlyPlayed(ctx context.Context, paging Paging) ([]*models.Song, int, error)
+
+	// GetPlayHistory returns songs played with this client from local play history, latest first.
+	// It returns no songs if local history is disabled.
+	GetPlayHistory(ctx context.Context, paging Paging) ([]*models.Song, int, error)
+
+	// GetDuplicateSongs returns groups of songs that are probably duplicates: same artist and title
+	// and nearly same duration. It goes through whole library, which may take a while.
+	GetDuplicateSongs(ctx context.Context) ([][]*models.Song, error)
 
 	// GetStatistics returns application statistics
 	GetStatistics() models.Stats
 
-	// GetSongs returns songs by paging. It also returns total number of songs.
-	GetSongs(page, pageSize int) ([]*models.Song, int, error)
+	// GetSongs returns songs by paging and sorting. It also returns total number of songs.
+	GetSongs(ctx context.Context, query *QueryOpts) ([]*models.Song, int, error)
 
 	// GetGenres returns music genres with paging. Return genres, total genres and possible error
-	GetGenres(paging Paging) ([]*models.IdName, int, error)
+	GetGenres(ctx context.Context, paging Paging) ([]*models.IdName, int, error)
+
+	// GetComposers returns composers with paging. Return composers, total composers and possible error
+	GetComposers(ctx context.Context, paging Paging) ([]*models.IdName, int, error)
 
 	// GetGenreAlbums returns all albums that belong to given genre
-	GetGenreAlbums(genre models.IdName) ([]*models.Album, error)
+	GetGenreAlbums(ctx context.Context, genre models.IdName) ([]*models.Album, error)
 
-	GetAlbumArtist(album *models.Album) (*models.Artist, error)
+	GetAlbumArtist(ctx context.Context, album *models.Album) (*models.Artist, error)
 
-	GetSongArtistAlbum(song *models.Song) (*models.Album, *models.Artist, error)
+	GetSongArtistAlbum(ctx context.Context, song *models.Song) (*models.Album, *models.Artist, error)
 
 	// GetInstantMix returns instant mix based on given item.
-	GetInstantMix(item models.Item) ([]*models.Song, error)
+	GetInstantMix(ctx context.Context, item models.Item) ([]*models.Song, error)
 
 	// GetLink returns a link to item that can be opened with browser.
 	// If there is no link or item is invalid, empty link is returned.
 	GetLink(item models.Item) string
 }
 
+// SyncPlayController controls group playback, where multiple clients play same queue in sync.
+// Group controls playback: queue, pausing and seeking are synchronized with group.
+type SyncPlayController interface {
+	// GetSyncPlayGroups returns groups that user can join.
+	GetSyncPlayGroups() ([]*models.SyncPlayGroup, error)
+	// NewSyncPlayGroup creates a new group and joins it.
+	NewSyncPlayGroup(name string) error
+	// JoinSyncPlayGroup joins existing group.
+	JoinSyncPlayGroup(id models.Id) error
+	// LeaveSyncPlayGroup leaves current group.
+	LeaveSyncPlayGroup() error
+	// AddSyncPlayCallback adds callback that gets called every time group or its state changes.
+	// Group is nil after leaving group.
+	AddSyncPlayCallback(func(group *models.SyncPlayGroup))
+}
+
+// ErrSyncPlayNotSupported occurs if server does not support group playback.
+var ErrSyncPlayNotSupported = errors.New("server does not support SyncPlay")
+
+// SessionCommand is a playback command sent to remote session.
+type SessionCommand string
+
+const (
+	SessionPlayPause  SessionCommand = "PlayPause"
+	SessionStop       SessionCommand = "Stop"
+	SessionNext       SessionCommand = "NextTrack"
+	SessionPrevious   SessionCommand = "PreviousTrack"
+	SessionVolumeUp   SessionCommand = "VolumeUp"
+	SessionVolumeDown SessionCommand = "VolumeDown"
+	SessionToggleMute SessionCommand = "ToggleMute"
+)
+
+// SessionController controls playback on other clients, e.g. a web client or another device.
+type SessionController interface {
+	// GetSessions returns other sessions that can be controlled remotely.
+	GetSessions() ([]*models.Session, error)
+	// PlaySessionSongs sends songs to session. If playNow, session starts playing songs immediately,
+	// else songs are added to the end of session's queue.
+	PlaySessionSongs(session models.Id, songs []models.Id, playNow bool) error
+	// SendSessionCommand sends playback command to session.
+	SendSessionCommand(session models.Id, command SessionCommand) error
+}
+
+// ErrSessionsNotSupported occurs if server does not support controlling other sessions.
+var ErrSessionsNotSupported = errors.New("server does not support controlling other sessions")
+
+// LibraryController selects active music library, if server has multiple music libraries.
+type LibraryController interface {
+	// GetLibraries returns music libraries user has access to.
+	GetLibraries() ([]*models.View, error)
+	// GetLibrary returns id of active library. Empty id means all libraries are used.
+	GetLibrary() models.Id
+	// SetLibrary sets active library. Empty id uses all libraries.
+	SetLibrary(id models.Id) error
+}
+
+// ErrLibrariesNotSupported occurs if server does not support selecting music library.
+var ErrLibrariesNotSupported = errors.New("server does not support selecting music library")
+
+// PodcastController lists podcasts and marks episodes played, if server has a podcast library.
+// Podcast shows are albums and episodes are songs.
+type PodcastController interface {
+	// GetPodcasts returns podcast shows.
+	GetPodcasts() ([]*models.Album, error)
+	// GetEpisodes returns episodes of podcast, latest episode first.
+	GetEpisodes(podcast models.Id) ([]*models.Song, error)
+	// SetPlayed marks episode played or not played.
+	SetPlayed(episode models.Id, played bool) error
+}
+
+// ErrPodcastsNotSupported occurs if server does not support podcasts or there is no podcast library.
+var ErrPodcastsNotSupported = errors.New("server does not support podcasts")
+
+// FavoriteController marks items favorite on server.
+type FavoriteController interface {
+	// SetFavorite adds song, album or artist to favorites or removes it from favorites.
+	SetFavorite(item models.Item, favorite bool) error
+}
+
+// ErrFavoritesNotSupported occurs if server does not support changing favorites.
+var ErrFavoritesNotSupported = errors.New("server does not support changing favorites")
+
+// GainController stores volume offsets for songs and albums that are mastered louder or quieter than
+// rest of the library. Offsets are stored locally and applied when song starts playing.
+type GainController interface {
+	// GetGainOffset returns volume offset in decibels of song or album, 0 if there is none.
+	GetGainOffset(item models.Item) int
+	// SetGainOffset sets volume offset in decibels of song or album. Zero removes offset.
+	SetGainOffset(item models.Item, offset int) error
+}
+
+// ErrGainNotSupported occurs if volume offsets cannot be set.
+var ErrGainNotSupported = errors.New("volume offsets are not supported")
+
+// ChapterProvider provides chapters of songs from server.
+type ChapterProvider interface {
+	// GetChapters returns chapters of song ordered by start, or empty if song has none.
+	GetChapters(song *models.Song) ([]models.Chapter, error)
+}
+
+// ChapterController navigates chapters of playing song.
+type ChapterController interface {
+	// GetPlayingChapters returns chapters of playing song, or empty if it has none.
+	GetPlayingChapters() []models.Chapter
+	// NextChapter seeks to next chapter and returns it, or nil if there is no next chapter.
+	NextChapter() *models.Chapter
+	// PreviousChapter seeks to beginning of current chapter, or to previous chapter if current one
+	// has just started. Returns chapter seeked to, or nil if song has no chapters.
+	PreviousChapter() *models.Chapter
+	// SeekChapter seeks to chapter at index of GetPlayingChapters.
+	SeekChapter(index int)
+}
+
+// ErrChaptersNotSupported occurs if server does not provide chapters.
+var ErrChaptersNotSupported = errors.New("server does not provide chapters")
+
+// CoverProvider provides album covers cached to local files, e.g. for notifications or drawing covers.
+type CoverProvider interface {
+	// GetAlbumCover returns path to cover of album, downloading it to cache if needed.
+	GetAlbumCover(album models.Id) (string, error)
+}
+
+// ArtColorProvider provides dominant colors of album covers and artist images, e.g. for drawing
+// colored placeholders where images cannot be drawn.
+type ArtColorProvider interface {
+	// GetArtColor returns dominant color of item's image as '#rrggbb', downloading image to cache if needed.
+	GetArtColor(item models.Id, itemType models.ItemType) (string, error)
+}
+
+// DownloadController downloads songs to local cache for offline playback.
+// Downloaded songs are played from cache.
+type DownloadController interface {
+	// DownloadSongs adds songs to download queue. Songs that are already downloaded are skipped.
+	DownloadSongs(songs []*models.Song)
+	// GetDownloads returns downloaded, running and queued downloads.
+	GetDownloads() []*models.Download
+	// RemoveDownload removes song from cache.
+	RemoveDownload(song models.Id) error
+	// AddDownloadCallback adds callback that is called when download starts, progresses or completes.
+	AddDownloadCallback(cb func())
+}
+
+// ErrDownloadsNotSupported occurs if songs cannot be downloaded for offline playback.
+var ErrDownloadsNotSupported = errors.New("downloading songs is not supported")
+
+// PlaylistExporter writes songs to playlist files that other players can open.
+type PlaylistExporter interface {
+	// ExportPlaylist writes songs to m3u file and returns number of songs written. Songs refer to stream urls,
+	// or if preferLocal, to local files for downloaded songs. Songs that have neither are skipped.
+	ExportPlaylist(name string, songs []*models.Song, file string, preferLocal bool) (int, error)
+}
+
+// ErrExportNotSupported occurs if playlists cannot be exported.
+var ErrExportNotSupported = errors.New("exporting playlists is not supported")
+
+// PlaylistImporter creates playlists from playlist files.
+type PlaylistImporter interface {
+	// ImportPlaylist matches songs in m3u file against library and creates playlist of matched songs.
+	// If name is empty, playlist is named after the file.
+	ImportPlaylist(file string, name string) (*models.PlaylistImport, error)
+}
+
+// ErrImportNotSupported occurs if server playlists cannot be created.
+var ErrImportNotSupported = errors.New("importing playlists is not supported")
+
+// PlaylistCopier copies playlists, e.g. playlists shared by other users, into user's own playlists.
+type PlaylistCopier interface {
+	// CopyPlaylist creates new playlist with name and songs of playlist. Copy is owned by current user.
+	CopyPlaylist(playlist *models.Playlist, name string) (*models.Playlist, error)
+}
+
+// ErrCopyNotSupported occurs if server playlists cannot be copied.
+var ErrCopyNotSupported = errors.New("copying playlists is not supported")
+
+// LibraryChangeNotifier notifies when items have been added to, removed from or updated in library.
+type LibraryChangeNotifier interface {
+	// AddLibraryChangedCallback adds callback that is called after library contents have changed.
+	AddLibraryChangedCallback(cb func())
+}
+
+// ConnectionNotifier notifies when server becomes unreachable and when connection is restored.
+type ConnectionNotifier interface {
+	// AddConnectionCallback adds callback that is called with online=false when server goes offline
+	// and with online=true once connection has been restored.
+	AddConnectionCallback(cb func(online bool))
+}
+
+// PagePrefetcher loads next page of paged lists in background.
+type PagePrefetcher interface {
+	// CancelPrefetch cancels pending prefetches, as view has changed.
+	CancelPrefetch()
+}
+
 // Paging. First page is 0
 type Paging struct {
 	TotalItems  int
@@ -173,13 +421,16 @@ type SortField string
 
 const (
 	SortByName       SortField = "Name"
-	SortByDate       SortField = "Date"
+	SortByDate       SortField = "Release year"
 	SortByArtist     SortField = "Artist"
 	SortByAlbum      SortField = "Album"
 	SortByPlayCount  SortField = "Most played"
 	SortByRandom     SortField = "Random"
-	SortByLatest     SortField = "Latest"
+	SortByLatest     SortField = "Date added"
 	SortByLastPlayed SortField = "Last played"
+	SortByRating     SortField = "Rating"
+	// SortByReleaseDate sorts by full release date, where available, unlike SortByDate.
+	SortByReleaseDate SortField = "Release date"
 )
 
 // Sort describes sorting
@@ -188,6 +439,24 @@ type Sort struct {
 	Mode  string
 }
 
+// String returns sort as '<field> <mode>', e.g. 'Most played DESC'. Use SortFromString to parse it.
+func (s Sort) String() string {
+	return string(s.Field) + " " + s.Mode
+}
+
+// SortFromString parses sort created with Sort.String. Invalid or empty sort results in default sorting.
+func SortFromString(text string) Sort {
+	i := strings.LastIndex(text, " ")
+	if i < 1 {
+		return NewSort("")
+	}
+	mode := text[i+1:]
+	if mode != SortAsc && mode != SortDesc {
+		return NewSort("")
+	}
+	return Sort{Field: SortField(text[:i]), Mode: mode}
+}
+
 // NewSort creates default sorting, that is, ASC.
 // If field is empty, use SortbyName and ASC
 func NewSort(field SortField) Sort {
@@ -218,6 +487,28 @@ type Filter struct {
 	Genres []models.IdName
 	// YearRange contains two elements, items must be within these boundaries.
 	YearRange [2]int
+	// NameStartsWith limits items to those whose name starts with given letter.
+	// NameStartsOther means names that do not start with a letter.
+	NameStartsWith string
+	// Composers contains list of composers to include.
+	Composers []models.IdName
+}
+
+// NameStartsOther is a NameStartsWith filter for names that start with number or special character.
+// Name matches it if it sorts before letter 'a' case-insensitively. Jellyfin applies this rule on server,
+// so local cache and servers filtered on client use it too. Names starting with letters outside A-Z
+// do not match any filter.
+const NameStartsOther = "#"
+
+// NameMatches returns true if name matches NameStartsWith. Empty filter matches every name.
+func (f Filter) NameMatches(name string) bool {
+	if f.NameStartsWith == "" {
+		return true
+	}
+	if f.NameStartsWith == NameStartsOther {
+		return strings.ToLower(name) < "a"
+	}
+	return strings.HasPrefix(strings.ToUpper(name), strings.ToUpper(f.NameStartsWith))
 }
 
 // YearRangeValid returns true if year range is considered valid and sane.
@@ -247,7 +538,8 @@ func (f Filter) YearRangeValid() bool {
 }
 
 func (f Filter) Empty() bool {
-	return !(f.FilterPlayed == "" && !f.Favorite && len(f.Genres) == 0 && f.YearRange == [2]int{0, 0})
+	return !(f.FilterPlayed == "" && !f.Favorite && len(f.Genres) == 0 && f.YearRange == [2]int{0, 0} &&
+		f.NameStartsWith == "" && len(f.Composers) == 0)
 }
 
 type QueryOpts struct {
@@ -0,0 +1,49 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package interfaces
+
+import "testing"
+
+func TestSortFromString(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Sort
+	}{
+		{name: "empty", text: "", want: Sort{Field: SortByName, Mode: SortAsc}},
+		{name: "name desc", text: "Name DESC", want: Sort{Field: SortByName, Mode: SortDesc}},
+		{name: "field with space", text: "Most played DESC", want: Sort{Field: SortByPlayCount, Mode: SortDesc}},
+		{name: "invalid mode", text: "Rating UP", want: Sort{Field: SortByName, Mode: SortAsc}},
+		{name: "no mode", text: "Rating", want: Sort{Field: SortByName, Mode: SortAsc}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SortFromString(tt.text); got != tt.want {
+				t.Errorf("SortFromString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSort_String(t *testing.T) {
+	sort := Sort{Field: SortByPlayCount, Mode: SortDesc}
+	if got := SortFromString(sort.String()); got != sort {
+		t.Errorf("SortFromString(Sort.String()) = %v, want %v", got, sort)
+	}
+}
@@ -18,7 +18,11 @@
 
 package interfaces
 
-import "tryffel.net/go/jellycli/models"
+import (
+	"fmt"
+	"time"
+	"tryffel.net/go/jellycli/models"
+)
 
 // AudioState is audio player state, playing song, stopped
 type AudioState int
@@ -54,7 +58,8 @@ const (
 	AudioActionShuffleChanged
 )
 
-// AudioTick is alias for millisecond
+// AudioTick is alias for millisecond. Positions and seeks are tracked in milliseconds throughout player,
+// and only rounded to seconds for displaying them.
 type AudioTick int
 
 func (a AudioTick) Seconds() int {
@@ -69,6 +74,10 @@ func (a AudioTick) MicroSeconds() int {
 	return int(a) * 1000
 }
 
+func (a AudioTick) Duration() time.Duration {
+	return time.Duration(a) * time.Millisecond
+}
+
 // AudioVolume is volume level in [0,100]
 type AudioVolume int
 
@@ -109,6 +118,8 @@ type AudioStatus struct {
 	Muted    bool
 	Paused   bool
 	Shuffle  bool
+	// Buffered is percentage of current song downloaded, or 0 if download progress is not known.
+	Buffered int
 }
 
 func (a *AudioStatus) Clear() {
@@ -118,6 +129,66 @@ func (a *AudioStatus) Clear() {
 	a.AlbumImageUrl = ""
 	a.SongPast = 0
 	a.Volume = 0
+	a.Buffered = 0
+}
+
+// DownloadProgress is implemented by song streams that report how much of the song has been downloaded.
+type DownloadProgress interface {
+	// Progress returns downloaded and total bytes. Total is 0 if progress is not known.
+	Progress() (downloaded, total int64)
+}
+
+// BufferedPercent returns downloaded percentage, or 0 if total is not known.
+func BufferedPercent(downloaded, total int64) int {
+	if total <= 0 {
+		return 0
+	}
+	if downloaded >= total {
+		return 100
+	}
+	return int(downloaded * 100 / total)
+}
+
+// StatusTokens returns format tokens and their values for status, e.g. '{title}', 'song name',
+// to use with strings.NewReplacer. Tokens are {title}, {artist}, {album}, {year}, {codec}, {bitrate},
+// {volume} and {shuffle}. Missing values are empty.
+func StatusTokens(status AudioStatus) []string {
+	title, artist, album, year, codec, bitrate := "", "", "", "", "", ""
+	if status.Song != nil {
+		title = status.Song.Name
+		codec = status.Song.Codec
+		if status.Song.Bitrate > 0 {
+			bitrate = fmt.Sprintf("%d kbps", status.Song.Bitrate)
+		}
+	}
+	if status.Artist != nil {
+		artist = status.Artist.Name
+	}
+	if status.Album != nil {
+		album = status.Album.Name
+		if status.Album.Year > 0 {
+			year = fmt.Sprint(status.Album.Year)
+		}
+	}
+	volume := fmt.Sprintf("%d%%", status.Volume)
+	if status.Muted {
+		volume = "muted"
+	}
+	shuffle := ""
+	if status.Shuffle {
+		shuffle = "shuffle"
+	}
+
+	return []string{
+		"{title}", title,
+		"{artist}", artist,
+		"{album}", album,
+		"{year}", year,
+		"{codec}", codec,
+		"{bitrate}", bitrate,
+		"{volume}", volume,
+		"{shuffle}", shuffle,
+	}
 }
 
 // Player controls media playback. Current status is sent to StatusCallback, if set. Multiple status callbacks
@@ -135,9 +206,10 @@ type Player interface {
 	Next()
 	//Previous plays last played song (first in history) if there is one.
 	Previous()
-	//Seek seeks forward given seconds
+	//Seek seeks forward given ticks, or backwards if ticks is negative.
 	Seek(ticks AudioTick)
-	//SeekBackwards seeks backwards given seconds
+	//SeekTo seeks to given position from the beginning of current song.
+	SeekTo(position AudioTick)
 	//AddStatusCallback adds callback that get's called every time status has changed,
 	//including playback progress
 	AddStatusCallback(func(status AudioStatus))
@@ -149,6 +221,18 @@ type Player interface {
 	ToggleMute()
 
 	SetShuffle(enabled bool)
+
+	// AddErrorCallback adds callback that gets called every time playback fails, e.g. song cannot be downloaded.
+	AddErrorCallback(func(err error))
+}
+
+// SpectrumAnalyzer provides frequency spectrum of currently playing audio, e.g. for visualizer.
+type SpectrumAnalyzer interface {
+	// SetSpectrumEnabled enables or disables collecting audio samples. Collecting samples costs cpu,
+	// so it should be disabled when spectrum is not needed.
+	SetSpectrumEnabled(enabled bool)
+	// Spectrum returns levels in range [0,1] for given number of frequency bands, from low to high frequencies.
+	Spectrum(bands int) []float64
 }
 
 // Queuer contains read-only methods for song queue.
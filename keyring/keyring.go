@@ -0,0 +1,41 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package keyring stores secrets in operating system's credential store: Secret Service on Linux,
+// Keychain on macOS and Credential Manager on Windows.
+package keyring
+
+import "errors"
+
+// ErrNotFound occurs when there's no secret for given service and user.
+var ErrNotFound = errors.New("secret not found in keyring")
+
+// Set stores secret for user in service. Existing secret is replaced.
+func Set(service, user, secret string) error {
+	return set(service, user, secret)
+}
+
+// Get returns secret for user in service. If there's no secret, ErrNotFound is returned.
+func Get(service, user string) (string, error) {
+	return get(service, user)
+}
+
+// Delete removes secret for user in service. If there's no secret, ErrNotFound is returned.
+func Delete(service, user string) error {
+	return del(service, user)
+}
@@ -0,0 +1,73 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// security exits with this code when item is not found.
+const securityNotFound = 44
+
+func set(service, user, secret string) error {
+	// pass command through stdin, so that secret is not visible in process list
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		quote(service), quote(user), quote(secret)))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("security: %v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+func get(service, user string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", user, "-w").Output()
+	if err != nil {
+		return "", securityError(err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func del(service, user string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", service, "-a", user).Run()
+	if err != nil {
+		return securityError(err)
+	}
+	return nil
+}
+
+func securityError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == securityNotFound {
+		return ErrNotFound
+	}
+	return fmt.Errorf("security: %v", err)
+}
+
+// quote quotes value for security interactive mode.
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
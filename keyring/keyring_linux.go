@@ -0,0 +1,219 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"github.com/godbus/dbus"
+)
+
+const (
+	secretsDest       = "org.freedesktop.secrets"
+	secretsPath       = dbus.ObjectPath("/org/freedesktop/secrets")
+	defaultCollection = dbus.ObjectPath("/org/freedesktop/secrets/aliases/default")
+	serviceIface      = "org.freedesktop.Secret.Service"
+	collectionIface   = "org.freedesktop.Secret.Collection"
+	itemIface         = "org.freedesktop.Secret.Item"
+	promptIface       = "org.freedesktop.Secret.Prompt"
+	// noPrompt is returned when action does not need a prompt
+	noPrompt = dbus.ObjectPath("/")
+)
+
+// secret is Secret Service secret struct.
+type secret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// secretService is a session to Secret Service, which is provided by e.g. gnome-keyring or KWallet.
+type secretService struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+func newSecretService() (*secretService, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("connect to session bus: %v", err)
+	}
+	s := &secretService{conn: conn}
+	var output dbus.Variant
+	err = s.service().Call(serviceIface+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&output, &s.session)
+	if err != nil {
+		return nil, fmt.Errorf("open secret service session: %v", err)
+	}
+	return s, nil
+}
+
+func (s *secretService) service() dbus.BusObject {
+	return s.conn.Object(secretsDest, secretsPath)
+}
+
+func (s *secretService) close() {
+	s.conn.Object(secretsDest, s.session).Call("org.freedesktop.Secret.Session.Close", 0)
+}
+
+func attributes(service, user string) map[string]string {
+	return map[string]string{"service": service, "username": user}
+}
+
+// search returns items matching attributes. Locked items are unlocked.
+func (s *secretService) search(attrs map[string]string) ([]dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	err := s.service().Call(serviceIface+".SearchItems", 0, attrs).Store(&unlocked, &locked)
+	if err != nil {
+		return nil, fmt.Errorf("search items: %v", err)
+	}
+	if len(locked) > 0 {
+		err = s.unlock(locked)
+		if err != nil {
+			return nil, err
+		}
+		unlocked = append(unlocked, locked...)
+	}
+	return unlocked, nil
+}
+
+// unlock unlocks objects, prompting user if needed.
+func (s *secretService) unlock(objects []dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := s.service().Call(serviceIface+".Unlock", 0, objects).Store(&unlocked, &prompt)
+	if err != nil {
+		return fmt.Errorf("unlock: %v", err)
+	}
+	return s.prompt(prompt)
+}
+
+// prompt shows prompt, if there is one, and waits until user has completed it.
+func (s *secretService) prompt(prompt dbus.ObjectPath) error {
+	if prompt == "" || prompt == noPrompt {
+		return nil
+	}
+	rule := fmt.Sprintf("type='signal',interface='%s',member='Completed',path='%s'", promptIface, prompt)
+	err := s.conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, rule).Err
+	if err != nil {
+		return fmt.Errorf("add signal match: %v", err)
+	}
+	defer s.conn.BusObject().Call("org.freedesktop.DBus.RemoveMatch", 0, rule)
+
+	signals := make(chan *dbus.Signal, 5)
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	err = s.conn.Object(secretsDest, prompt).Call(promptIface+".Prompt", 0, "").Err
+	if err != nil {
+		return fmt.Errorf("prompt: %v", err)
+	}
+	for signal := range signals {
+		if signal.Path != prompt || signal.Name != promptIface+".Completed" {
+			continue
+		}
+		if len(signal.Body) > 0 {
+			if dismissed, ok := signal.Body[0].(bool); ok && dismissed {
+				return errors.New("prompt dismissed")
+			}
+		}
+		return nil
+	}
+	return errors.New("prompt: session bus closed")
+}
+
+func set(service, user, value string) error {
+	s, err := newSecretService()
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	err = s.unlock([]dbus.ObjectPath{defaultCollection})
+	if err != nil {
+		return err
+	}
+	properties := map[string]dbus.Variant{
+		itemIface + ".Label":      dbus.MakeVariant(fmt.Sprintf("%s (%s)", service, user)),
+		itemIface + ".Attributes": dbus.MakeVariant(attributes(service, user)),
+	}
+	sec := secret{
+		Session:     s.session,
+		Parameters:  []byte{},
+		Value:       []byte(value),
+		ContentType: "text/plain",
+	}
+	var item, prompt dbus.ObjectPath
+	err = s.conn.Object(secretsDest, defaultCollection).Call(collectionIface+".CreateItem", 0,
+		properties, sec, true).Store(&item, &prompt)
+	if err != nil {
+		return fmt.Errorf("create item: %v", err)
+	}
+	return s.prompt(prompt)
+}
+
+func get(service, user string) (string, error) {
+	s, err := newSecretService()
+	if err != nil {
+		return "", err
+	}
+	defer s.close()
+
+	items, err := s.search(attributes(service, user))
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "", ErrNotFound
+	}
+	sec := secret{}
+	err = s.conn.Object(secretsDest, items[0]).Call(itemIface+".GetSecret", 0, s.session).Store(&sec)
+	if err != nil {
+		return "", fmt.Errorf("get secret: %v", err)
+	}
+	return string(sec.Value), nil
+}
+
+func del(service, user string) error {
+	s, err := newSecretService()
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	items, err := s.search(attributes(service, user))
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return ErrNotFound
+	}
+	for _, v := range items {
+		var prompt dbus.ObjectPath
+		err = s.conn.Object(secretsDest, v).Call(itemIface+".Delete", 0).Store(&prompt)
+		if err != nil {
+			return fmt.Errorf("delete item: %v", err)
+		}
+		err = s.prompt(prompt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package keyring
+
+import "errors"
+
+var errUnsupported = errors.New("keyring is not supported on this platform")
+
+func set(service, user, secret string) error {
+	return errUnsupported
+}
+
+func get(service, user string) (string, error) {
+	return "", errUnsupported
+}
+
+func del(service, user string) error {
+	return errUnsupported
+}
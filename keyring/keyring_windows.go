@@ -0,0 +1,125 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package keyring
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = syscall.Errno(1168)
+)
+
+// credential is windows CREDENTIALW struct.
+type credential struct {
+	flags              uint32
+	credType           uint32
+	targetName         *uint16
+	comment            *uint16
+	lastWritten        syscall.Filetime
+	credentialBlobSize uint32
+	credentialBlob     *byte
+	persist            uint32
+	attributeCount     uint32
+	attributes         uintptr
+	targetAlias        *uint16
+	userName           *uint16
+}
+
+func target(service, user string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(service + ":" + user)
+}
+
+func set(service, user, secret string) error {
+	targetName, err := target(service, user)
+	if err != nil {
+		return err
+	}
+	userName, err := syscall.UTF16PtrFromString(user)
+	if err != nil {
+		return err
+	}
+	cred := credential{
+		credType:           credTypeGeneric,
+		targetName:         targetName,
+		credentialBlobSize: uint32(len(secret)),
+		persist:            credPersistLocalMachine,
+		userName:           userName,
+	}
+	blob := []byte(secret)
+	if len(blob) > 0 {
+		cred.credentialBlob = &blob[0]
+	}
+	ok, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0)
+	if ok == 0 {
+		return fmt.Errorf("CredWrite: %v", err)
+	}
+	return nil
+}
+
+func get(service, user string) (string, error) {
+	targetName, err := target(service, user)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	ok, _, err := procCredRead.Call(uintptr(unsafe.Pointer(targetName)), credTypeGeneric, 0,
+		uintptr(unsafe.Pointer(&cred)))
+	if ok == 0 {
+		if err == errorNotFound {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("CredRead: %v", err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	size := int(cred.credentialBlobSize)
+	if size == 0 {
+		return "", nil
+	}
+	blob := (*[1 << 20]byte)(unsafe.Pointer(cred.credentialBlob))[:size:size]
+	return string(blob), nil
+}
+
+func del(service, user string) error {
+	targetName, err := target(service, user)
+	if err != nil {
+		return err
+	}
+	ok, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(targetName)), credTypeGeneric, 0)
+	if ok == 0 {
+		if err == errorNotFound {
+			return ErrNotFound
+		}
+		return fmt.Errorf("CredDelete: %v", err)
+	}
+	return nil
+}
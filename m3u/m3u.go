@@ -0,0 +1,129 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+// Package m3u reads and writes extended M3U playlists, for interoperability with other players.
+package m3u
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Entry is a song in playlist.
+type Entry struct {
+	// Location is path to file or url.
+	Location string
+	// Title is shown by players instead of location, e.g. 'Artist - Song'.
+	Title string
+	// Duration in seconds, -1 if unknown.
+	Duration int
+}
+
+// Write writes playlist with given name to w. Playlist is UTF-8 encoded,
+// which is expected for .m3u8 files and supported by most players for .m3u files too.
+func Write(w io.Writer, name string, entries []Entry) error {
+	buf := bufio.NewWriter(w)
+	buf.WriteString("#EXTM3U\n")
+	if name != "" {
+		fmt.Fprintf(buf, "#PLAYLIST:%s\n", singleLine(name))
+	}
+	for _, v := range entries {
+		if v.Location == "" {
+			return fmt.Errorf("entry '%s' has no location", v.Title)
+		}
+		fmt.Fprintf(buf, "#EXTINF:%d,%s\n%s\n", v.Duration, singleLine(v.Title), singleLine(v.Location))
+	}
+	return buf.Flush()
+}
+
+// Read reads playlist name and entries from m3u or extended m3u playlist. Comments and unknown directives
+// are skipped. Entries without #EXTINF have no title and duration -1.
+func Read(r io.Reader) (name string, entries []Entry, err error) {
+	scanner := bufio.NewScanner(r)
+	entry := Entry{Duration: -1}
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if first {
+			// skip utf-8 byte order mark
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+		case strings.HasPrefix(line, "#EXTINF:"):
+			entry.Duration, entry.Title = parseExtInf(strings.TrimPrefix(line, "#EXTINF:"))
+		case strings.HasPrefix(line, "#"):
+		default:
+			entry.Location = line
+			entries = append(entries, entry)
+			entry = Entry{Duration: -1}
+		}
+	}
+	return name, entries, scanner.Err()
+}
+
+// parseExtInf parses '<duration> [attributes],<title>'. Invalid duration is returned as -1.
+func parseExtInf(info string) (int, string) {
+	parts := strings.SplitN(info, ",", 2)
+	title := ""
+	if len(parts) == 2 {
+		title = strings.TrimSpace(parts[1])
+	}
+	fields := strings.Fields(parts[0])
+	if len(fields) == 0 {
+		return -1, title
+	}
+	duration, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || duration < 0 {
+		return -1, title
+	}
+	return int(duration), title
+}
+
+// WriteFile writes playlist to file, replacing existing file. Locations may contain credentials,
+// so file is only readable by user.
+func WriteFile(file, name string, entries []Entry) error {
+	data := &bytes.Buffer{}
+	err := Write(data, name, entries)
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	err = ioutil.WriteFile(tmp, data.Bytes(), 0600)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, file)
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// singleLine replaces line breaks, which would break playlist format.
+func singleLine(text string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(text)
+}
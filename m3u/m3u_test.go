@@ -0,0 +1,107 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package m3u
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	entries := []Entry{
+		{Location: "/music/song.flac", Title: "Artist - Song", Duration: 215},
+		{Location: "https://example.com/rest/stream?id=2&t=token", Title: "Second\nline", Duration: -1},
+	}
+	buf := &bytes.Buffer{}
+	if err := Write(buf, "Mix", entries); err != nil {
+		t.Fatal(err)
+	}
+	want := `#EXTM3U
+#PLAYLIST:Mix
+#EXTINF:215,Artist - Song
+/music/song.flac
+#EXTINF:-1,Second line
+https://example.com/rest/stream?id=2&t=token
+`
+	if buf.String() != want {
+		t.Errorf("Write() got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	if err := Write(&bytes.Buffer{}, "", []Entry{{Title: "no location"}}); err == nil {
+		t.Errorf("Write() entry without location: no error")
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	file := path.Join(t.TempDir(), "queue.m3u8")
+	if err := ioutil.WriteFile(file, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := WriteFile(file, "", []Entry{{Location: "song.mp3", Title: "Song", Duration: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "#EXTM3U\n#EXTINF:1,Song\nsong.mp3\n"
+	if string(data) != want {
+		t.Errorf("WriteFile() got %q, want %q", data, want)
+	}
+	if _, err := os.Stat(file + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left: %v", err)
+	}
+}
+
+func TestRead(t *testing.T) {
+	text := "\ufeff#EXTM3U\r\n#PLAYLIST:Road trip\r\n" +
+		"#EXTINF:215 tvg-id=\"x\",Artist - Song, with comma\r\n/music/song.flac\r\n" +
+		"# comment\n\n#EXTINF:abc,Bad duration\nhttp://example.com/2\n" +
+		"relative/03 - Plain.mp3\n"
+	name, entries, err := Read(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "Road trip" {
+		t.Errorf("name: got %s, want Road trip", name)
+	}
+	want := []Entry{
+		{Location: "/music/song.flac", Title: "Artist - Song, with comma", Duration: 215},
+		{Location: "http://example.com/2", Title: "Bad duration", Duration: -1},
+		{Location: "relative/03 - Plain.mp3", Duration: -1},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries: got %v, want %v", entries, want)
+	}
+
+	// written playlist is read back as is
+	buf := &bytes.Buffer{}
+	if err := Write(buf, name, want); err != nil {
+		t.Fatal(err)
+	}
+	name, entries, err = Read(buf)
+	if err != nil || name != "Road trip" || !reflect.DeepEqual(entries, want) {
+		t.Errorf("read written playlist: got %s, %v, %v", name, entries, err)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package mediakeys handles global media keys (play / pause, next, previous, stop) directly from the operating
+// system. It is meant for environments that don't provide media keys through MPRIS.
+package mediakeys
+
+import (
+	"github.com/sirupsen/logrus"
+	"tryffel.net/go/jellycli/interfaces"
+)
+
+// key is a media key
+type key int
+
+const (
+	keyPlayPause key = iota
+	keyPlay
+	keyPause
+	keyStop
+	keyNext
+	keyPrevious
+)
+
+// listener reads media keys from operating system.
+type listener interface {
+	// start starts sending pressed keys to keys. Keys is closed once listener is closed.
+	start(keys chan<- key) error
+	Close() error
+}
+
+// MediaKeys controls player with media keys.
+type MediaKeys struct {
+	player   interfaces.Player
+	listener listener
+	keys     chan key
+}
+
+// NewMediaKeys starts listening for media keys. If platform does not support media keys, or
+// they cannot be read, return error.
+func NewMediaKeys(player interfaces.Player) (*MediaKeys, error) {
+	l, err := newListener()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MediaKeys{
+		player:   player,
+		listener: l,
+		keys:     make(chan key, 5),
+	}
+	err = l.start(m.keys)
+	if err != nil {
+		return nil, err
+	}
+	go m.loop()
+	return m, nil
+}
+
+// Close stops listening for media keys.
+func (m *MediaKeys) Close() error {
+	return m.listener.Close()
+}
+
+func (m *MediaKeys) loop() {
+	for k := range m.keys {
+		m.handleKey(k)
+	}
+}
+
+func (m *MediaKeys) handleKey(k key) {
+	logrus.Debugf("media key pressed: %d", k)
+	switch k {
+	case keyPlayPause:
+		m.player.PlayPause()
+	case keyPlay:
+		m.player.Continue()
+	case keyPause:
+		m.player.Pause()
+	case keyStop:
+		m.player.StopMedia()
+	case keyNext:
+		m.player.Next()
+	case keyPrevious:
+		m.player.Previous()
+	}
+}
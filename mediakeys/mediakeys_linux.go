@@ -0,0 +1,139 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package mediakeys
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// Linux input event types and key codes, see linux/input-event-codes.h
+const (
+	evKey = 0x01
+
+	keyCodeNextSong     = 163
+	keyCodePlayPause    = 164
+	keyCodePreviousSong = 165
+	keyCodeStopCd       = 166
+	keyCodePlayCd       = 200
+	keyCodePauseCd      = 201
+
+	keyValuePressed = 1
+)
+
+// inputEvent is linux struct input_event.
+type inputEvent struct {
+	Time  syscall.Timeval
+	Type  uint16
+	Code  uint16
+	Value int32
+}
+
+// evdevListener reads media keys from input devices. This works without display server,
+// but requires read access to /dev/input/event*, usually by being member of group 'input'.
+type evdevListener struct {
+	devices []*os.File
+}
+
+func newListener() (listener, error) {
+	paths, err := filepath.Glob("/dev/input/event*")
+	if err != nil {
+		return nil, err
+	}
+
+	l := &evdevListener{}
+	for _, v := range paths {
+		file, err := os.Open(v)
+		if err != nil {
+			continue
+		}
+		l.devices = append(l.devices, file)
+	}
+	if len(l.devices) == 0 {
+		return nil, errors.New("no readable input devices in /dev/input, is user in group 'input'?")
+	}
+	return l, nil
+}
+
+func (l *evdevListener) start(keys chan<- key) error {
+	wg := &sync.WaitGroup{}
+	for _, v := range l.devices {
+		wg.Add(1)
+		go func(device *os.File) {
+			defer wg.Done()
+			readDevice(device, keys)
+		}(v)
+	}
+	go func() {
+		wg.Wait()
+		close(keys)
+	}()
+	return nil
+}
+
+func (l *evdevListener) Close() error {
+	var err error
+	for _, v := range l.devices {
+		if closeErr := v.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// readDevice reads input events until device is closed.
+func readDevice(device *os.File, keys chan<- key) {
+	event := inputEvent{}
+	for {
+		// input events are in native byte order, which is little endian on supported architectures.
+		err := binary.Read(device, binary.LittleEndian, &event)
+		if err != nil {
+			return
+		}
+		if k, ok := keyFromEvent(event); ok {
+			keys <- k
+		}
+	}
+}
+
+// keyFromEvent returns media key if event is a media key press.
+func keyFromEvent(event inputEvent) (key, bool) {
+	if event.Type != evKey || event.Value != keyValuePressed {
+		return 0, false
+	}
+	switch event.Code {
+	case keyCodePlayPause:
+		return keyPlayPause, true
+	case keyCodePlayCd:
+		return keyPlay, true
+	case keyCodePauseCd:
+		return keyPause, true
+	case keyCodeStopCd:
+		return keyStop, true
+	case keyCodeNextSong:
+		return keyNext, true
+	case keyCodePreviousSong:
+		return keyPrevious, true
+	}
+	return 0, false
+}
@@ -0,0 +1,47 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package mediakeys
+
+import "testing"
+
+func Test_keyFromEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  inputEvent
+		want   key
+		wantOk bool
+	}{
+		{name: "play pause", event: inputEvent{Type: evKey, Code: keyCodePlayPause, Value: keyValuePressed},
+			want: keyPlayPause, wantOk: true},
+		{name: "next", event: inputEvent{Type: evKey, Code: keyCodeNextSong, Value: keyValuePressed},
+			want: keyNext, wantOk: true},
+		{name: "release", event: inputEvent{Type: evKey, Code: keyCodeNextSong, Value: 0}},
+		{name: "repeat", event: inputEvent{Type: evKey, Code: keyCodeNextSong, Value: 2}},
+		{name: "other key", event: inputEvent{Type: evKey, Code: 30, Value: keyValuePressed}},
+		{name: "other event", event: inputEvent{Type: 0x02, Code: keyCodePlayPause, Value: keyValuePressed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := keyFromEvent(tt.event)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("keyFromEvent() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package mediakeys
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32                 = syscall.NewLazyDLL("user32.dll")
+	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
+	procGetMessage         = user32.NewProc("GetMessageW")
+	procPostThreadMessage  = user32.NewProc("PostThreadMessageW")
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
+)
+
+const (
+	wmQuit      = 0x0012
+	wmHotkey    = 0x0312
+	modNoRepeat = 0x4000
+
+	vkMediaNextTrack = 0xB0
+	vkMediaPrevTrack = 0xB1
+	vkMediaStop      = 0xB2
+	vkMediaPlayPause = 0xB3
+)
+
+// hotkeys maps hotkey ids to media keys.
+var hotkeys = []struct {
+	virtualKey uintptr
+	key        key
+}{
+	{vkMediaPlayPause, keyPlayPause},
+	{vkMediaStop, keyStop},
+	{vkMediaNextTrack, keyNext},
+	{vkMediaPrevTrack, keyPrevious},
+}
+
+// msg is windows MSG struct.
+type msg struct {
+	hwnd     uintptr
+	message  uint32
+	wParam   uintptr
+	lParam   uintptr
+	time     uint32
+	x        int32
+	y        int32
+	lPrivate uint32
+}
+
+// hotkeyListener registers media keys as global hotkeys. Hotkeys are received
+// on the thread that registered them, so listener locks its own thread.
+type hotkeyListener struct {
+	threadId uintptr
+}
+
+func newListener() (listener, error) {
+	return &hotkeyListener{}, nil
+}
+
+func (h *hotkeyListener) start(keys chan<- key) error {
+	started := make(chan error)
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		defer close(keys)
+
+		h.threadId, _, _ = procGetCurrentThreadId.Call()
+		registered := 0
+		for i, v := range hotkeys {
+			ok, _, err := procRegisterHotKey.Call(0, uintptr(i+1), modNoRepeat, v.virtualKey)
+			if ok == 0 {
+				started <- fmt.Errorf("register media key %x: %v", v.virtualKey, err)
+				break
+			}
+			registered++
+		}
+		defer func() {
+			for i := 0; i < registered; i++ {
+				procUnregisterHotKey.Call(0, uintptr(i+1))
+			}
+		}()
+		if registered != len(hotkeys) {
+			return
+		}
+		started <- nil
+
+		m := msg{}
+		for {
+			ret, _, _ := procGetMessage.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+			// 0 is WM_QUIT, -1 is error
+			if ret == 0 || int32(ret) == -1 {
+				return
+			}
+			if m.message == wmHotkey && m.wParam >= 1 && int(m.wParam) <= len(hotkeys) {
+				keys <- hotkeys[m.wParam-1].key
+			}
+		}
+	}()
+	return <-started
+}
+
+func (h *hotkeyListener) Close() error {
+	ok, _, err := procPostThreadMessage.Call(h.threadId, wmQuit, 0, 0)
+	if ok == 0 {
+		return fmt.Errorf("stop media key listener: %v", err)
+	}
+	return nil
+}
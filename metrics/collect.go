@@ -0,0 +1,143 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// maxProgressStep is max progress between two status updates that is counted as played.
+// Larger steps are seeks.
+const maxProgressStep = 5
+
+// playerWatcher counts songs and playback time from player status.
+type playerWatcher struct {
+	lock   sync.Mutex
+	song   models.Id
+	past   int
+	status interfaces.AudioStatus
+}
+
+// WatchPlayer collects playback metrics from player.
+func WatchPlayer(player interfaces.Player) {
+	w := &playerWatcher{}
+	player.AddStatusCallback(w.update)
+	player.AddErrorCallback(func(err error) { PlaybackErrors.Inc() })
+
+	NewGaugeFunc("jellycli_playing", "1 if song is playing, else 0.", func() float64 {
+		status := w.getStatus()
+		if status.State == interfaces.AudioStatePlaying && !status.Paused {
+			return 1
+		}
+		return 0
+	})
+	NewGaugeFunc("jellycli_volume", "Volume in range [0,100].", func() float64 {
+		return float64(w.getStatus().Volume)
+	})
+}
+
+func (w *playerWatcher) update(status interfaces.AudioStatus) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.status = status
+	if status.Song == nil {
+		w.song = ""
+		return
+	}
+
+	past := status.SongPast.Seconds()
+	if status.Song.Id != w.song {
+		SongsPlayed.Inc()
+		w.song = status.Song.Id
+	} else if status.State == interfaces.AudioStatePlaying && !status.Paused {
+		if step := past - w.past; step > 0 && step <= maxProgressStep {
+			PlaybackSeconds.Add(uint64(step))
+		}
+	}
+	w.past = past
+}
+
+func (w *playerWatcher) getStatus() interfaces.AudioStatus {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.status
+}
+
+// WatchRequests collects api request statistics from stats.
+func WatchRequests(stats func() models.RequestStats) {
+	counters := []struct {
+		name  string
+		help  string
+		value func(s models.RequestStats) int
+	}{
+		{"jellycli_api_requests_total", "Api requests sent, including retries.",
+			func(s models.RequestStats) int { return s.Requests }},
+		{"jellycli_api_retries_total", "Api requests retried after transient error.",
+			func(s models.RequestStats) int { return s.Retries }},
+		{"jellycli_api_shared_total", "Api requests that shared identical ongoing request.",
+			func(s models.RequestStats) int { return s.Shared }},
+		{"jellycli_api_revalidated_total", "Cached api responses revalidated with server.",
+			func(s models.RequestStats) int { return s.Revalidated }},
+		{"jellycli_api_not_modified_total", "Revalidated api responses that were not modified.",
+			func(s models.RequestStats) int { return s.NotModified }},
+		{"jellycli_api_received_bytes_total", "Bytes received from server.",
+			func(s models.RequestStats) int { return s.BytesReceived }},
+		{"jellycli_api_decoded_bytes_total", "Bytes received from server after decompression.",
+			func(s models.RequestStats) int { return s.BytesDecoded }},
+		{"jellycli_api_cached_bytes_total", "Bytes served from response cache.",
+			func(s models.RequestStats) int { return s.BytesCached }},
+	}
+	for _, v := range counters {
+		counter := v
+		NewCounterFunc(counter.name, counter.help, func() float64 { return float64(counter.value(stats())) })
+	}
+}
+
+// Listen serves metrics at /metrics in given address. Close returned server to stop serving.
+func Listen(address string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listen metrics: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		Write(w)
+	})
+	server := &http.Server{
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	logrus.Infof("Serving metrics at %s/metrics", listener.Addr())
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			logrus.Errorf("serve metrics: %v", err)
+		}
+	}()
+	return server, nil
+}
@@ -0,0 +1,249 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package metrics collects playback, api and cache metrics and serves them in Prometheus text format.
+// Metrics are meant for running jellycli headless on always-on machines.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// SongsPlayed counts songs that started playing.
+	SongsPlayed = NewCounter("jellycli_songs_played_total", "Songs started playing.")
+	// PlaybackSeconds counts seconds of audio played.
+	PlaybackSeconds = NewCounter("jellycli_playback_seconds_total", "Seconds of audio played.")
+	// PlaybackErrors counts songs that failed to play.
+	PlaybackErrors = NewCounter("jellycli_playback_errors_total", "Songs that failed to play.")
+	// BufferUnderruns counts times stream buffer ran empty before stream was fully downloaded.
+	BufferUnderruns = NewCounter("jellycli_stream_buffer_underruns_total",
+		"Times stream buffer ran empty before stream was downloaded.")
+
+	// ApiLatency observes duration of api requests, until response headers are received.
+	ApiLatency = NewHistogram("jellycli_api_request_duration_seconds", "Api request latency.",
+		[]float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10})
+	// ApiErrors counts api requests that failed or returned server error.
+	ApiErrors = NewCounter("jellycli_api_request_errors_total", "Api requests that failed or returned 5xx.")
+
+	// ItemCache counts lookups to server item cache.
+	ItemCache = newCacheCounter("items")
+	// PageCache counts lookups to cached pages of artists, albums and songs.
+	PageCache = newCacheCounter("pages")
+	// CoverCache counts lookups to album cover cache.
+	CoverCache = newCacheCounter("covers")
+)
+
+var registry = &metricRegistry{families: map[string]*family{}}
+
+type metricRegistry struct {
+	lock     sync.Mutex
+	families map[string]*family
+}
+
+// family is a metric with one or more label sets.
+type family struct {
+	name   string
+	help   string
+	kind   string
+	series []series
+}
+
+type series interface {
+	write(w io.Writer, name string)
+}
+
+func (r *metricRegistry) register(name, help, kind string, s series) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{name: name, help: help, kind: kind}
+		r.families[name] = f
+	}
+	f.series = append(f.series, s)
+}
+
+// Write writes all metrics in Prometheus text format.
+func Write(w io.Writer) {
+	registry.lock.Lock()
+	families := make([]*family, 0, len(registry.families))
+	for _, f := range registry.families {
+		families = append(families, f)
+	}
+	registry.lock.Unlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	for _, f := range families {
+		fmt.Fprintf(w, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+		for _, s := range f.series {
+			s.write(w, f.name)
+		}
+	}
+}
+
+// formatLabels formats label pairs: 'cache', 'items' -> '{cache="items"}'.
+func formatLabels(pairs []string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	labels := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, fmt.Sprintf("%s=%q", pairs[i], pairs[i+1]))
+	}
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return fmt.Sprint(value)
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	labels string
+	value  uint64
+}
+
+// NewCounter registers new counter. Labels are key-value pairs.
+func NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{labels: formatLabels(labels)}
+	registry.register(name, help, "counter", c)
+	return c
+}
+
+// Inc increases counter by one.
+func (c *Counter) Inc() {
+	atomic.AddUint64(&c.value, 1)
+}
+
+// Add increases counter by n.
+func (c *Counter) Add(n uint64) {
+	atomic.AddUint64(&c.value, n)
+}
+
+// Value returns current value.
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.value)
+}
+
+func (c *Counter) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "%s%s %d\n", name, c.labels, c.Value())
+}
+
+// valueFunc is a metric whose value is read when metrics are written.
+type valueFunc struct {
+	labels string
+	value  func() float64
+}
+
+func (v *valueFunc) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "%s%s %s\n", name, v.labels, formatFloat(v.value()))
+}
+
+// NewGaugeFunc registers gauge that reads its value from function.
+func NewGaugeFunc(name, help string, value func() float64, labels ...string) {
+	registry.register(name, help, "gauge", &valueFunc{labels: formatLabels(labels), value: value})
+}
+
+// NewCounterFunc registers counter that reads its value from function, e.g. from existing statistics.
+func NewCounterFunc(name, help string, value func() float64, labels ...string) {
+	registry.register(name, help, "counter", &valueFunc{labels: formatLabels(labels), value: value})
+}
+
+// Histogram counts observations in buckets.
+type Histogram struct {
+	labels string
+	bounds []float64
+
+	lock   sync.Mutex
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram registers new histogram with given bucket upper bounds in ascending order.
+func NewHistogram(name, help string, bounds []float64, labels ...string) *Histogram {
+	h := &Histogram{
+		labels: formatLabels(labels),
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)),
+	}
+	registry.register(name, help, "histogram", h)
+	return h
+}
+
+// Observe adds value to histogram.
+func (h *Histogram) Observe(value float64) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for i, bound := range h.bounds {
+		if value <= bound {
+			h.counts[i] += 1
+		}
+	}
+	h.sum += value
+	h.count += 1
+}
+
+func (h *Histogram) write(w io.Writer, name string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	// bucket label is added to other labels
+	labels := strings.TrimSuffix(strings.TrimPrefix(h.labels, "{"), "}")
+	if labels != "" {
+		labels += ","
+	}
+	for i, bound := range h.bounds {
+		fmt.Fprintf(w, "%s_bucket{%sle=\"%s\"} %d\n", name, labels, formatFloat(bound), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", name, labels, h.count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, h.labels, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, h.labels, h.count)
+}
+
+// CacheCounter counts cache hits and misses.
+type CacheCounter struct {
+	hits   *Counter
+	misses *Counter
+}
+
+func newCacheCounter(cache string) *CacheCounter {
+	return &CacheCounter{
+		hits:   NewCounter("jellycli_cache_hits_total", "Cache lookups that found item.", "cache", cache),
+		misses: NewCounter("jellycli_cache_misses_total", "Cache lookups that did not find item.", "cache", cache),
+	}
+}
+
+// Lookup counts cache lookup, found tells whether it was a hit.
+func (c *CacheCounter) Lookup(found bool) {
+	if found {
+		c.hits.Inc()
+	} else {
+		c.misses.Inc()
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestWrite(t *testing.T) {
+	counter := NewCounter("test_events_total", "Test events.", "kind", "a")
+	counter.Add(3)
+	NewCounter("test_events_total", "Test events.", "kind", "b").Inc()
+	histogram := NewHistogram("test_duration_seconds", "Test duration.", []float64{0.1, 1})
+	histogram.Observe(0.05)
+	histogram.Observe(0.5)
+	histogram.Observe(2)
+	NewGaugeFunc("test_value", "Test value.", func() float64 { return 1.5 })
+
+	buf := &bytes.Buffer{}
+	Write(buf)
+	want := `# HELP test_duration_seconds Test duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{le="0.1"} 1
+test_duration_seconds_bucket{le="1"} 2
+test_duration_seconds_bucket{le="+Inf"} 3
+test_duration_seconds_sum 2.55
+test_duration_seconds_count 3
+# HELP test_events_total Test events.
+# TYPE test_events_total counter
+test_events_total{kind="a"} 3
+test_events_total{kind="b"} 1
+# HELP test_value Test value.
+# TYPE test_value gauge
+test_value 1.5
+`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("metrics output:\n%s\nwant it to contain:\n%s", buf.String(), want)
+	}
+}
+
+func TestPlayerWatcher(t *testing.T) {
+	w := &playerWatcher{}
+	songs := SongsPlayed.Value()
+	seconds := PlaybackSeconds.Value()
+	song := &models.Song{Id: "1"}
+	updates := []interfaces.AudioStatus{
+		{State: interfaces.AudioStatePlaying, Song: song, SongPast: 0},
+		{State: interfaces.AudioStatePlaying, Song: song, SongPast: 1000},
+		{State: interfaces.AudioStatePlaying, Song: song, SongPast: 3000},
+		// seek
+		{State: interfaces.AudioStatePlaying, Song: song, SongPast: 60000},
+		{State: interfaces.AudioStatePlaying, Song: song, SongPast: 61000},
+		// paused
+		{State: interfaces.AudioStatePlaying, Song: song, SongPast: 62000, Paused: true},
+		{State: interfaces.AudioStatePlaying, Song: &models.Song{Id: "2"}, SongPast: 0},
+		{State: interfaces.AudioStateStopped},
+		{State: interfaces.AudioStatePlaying, Song: song, SongPast: 0},
+	}
+	for _, v := range updates {
+		w.update(v)
+	}
+	if got := SongsPlayed.Value() - songs; got != 3 {
+		t.Errorf("songs played: got %d, want 3", got)
+	}
+	if got := PlaybackSeconds.Value() - seconds; got != 4 {
+		t.Errorf("playback seconds: got %d, want 4", got)
+	}
+}
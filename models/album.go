@@ -37,6 +37,15 @@ type Album struct {
 	DiscCount int    `db:"disc_count"`
 
 	Favorite bool `db:"favorite"`
+
+	// Overview is optional description of album.
+	Overview string
+	// Genres album belongs to, if known.
+	Genres []IdName
+	// DiscTitles are optional disc subtitles, key is disc number.
+	DiscTitles map[int]string
+	// Sources are names of servers album is found from. Only set when using multiple servers.
+	Sources []string
 }
 
 func (a *Album) GetId() Id {
@@ -27,6 +27,9 @@ type Artist struct {
 	AlbumCount    int `db:"album_count"`
 
 	Favorite bool `db:"favorite"`
+
+	// Sources are names of servers artist is found from. Only set when using multiple servers.
+	Sources []string
 }
 
 func (a *Artist) GetId() Id {
@@ -0,0 +1,52 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package models
+
+// DownloadState is state of song download.
+type DownloadState int
+
+const (
+	DownloadQueued DownloadState = iota
+	DownloadRunning
+	DownloadDone
+	DownloadFailed
+)
+
+// Download is song that is downloaded for offline playback.
+type Download struct {
+	Song  *Song
+	State DownloadState
+	// Size is file size in bytes, 0 if unknown.
+	Size int64
+	// Downloaded is number of bytes downloaded so far.
+	Downloaded int64
+	// Error is set if download failed.
+	Error string
+}
+
+// Progress returns download progress in range 0-100, or -1 if size is unknown.
+func (d *Download) Progress() int {
+	if d.State == DownloadDone {
+		return 100
+	}
+	if d.Size <= 0 {
+		return -1
+	}
+	return int(d.Downloaded * 100 / d.Size)
+}
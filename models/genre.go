@@ -0,0 +1,46 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package models
+
+// Genre is a music genre. It implements Item, so it can be used e.g. for instant mix.
+type Genre IdName
+
+func (g Genre) GetId() Id {
+	return g.Id
+}
+
+func (g Genre) GetName() string {
+	return g.Name
+}
+
+func (g Genre) HasChildren() bool {
+	return false
+}
+
+func (g Genre) GetChildren() []Id {
+	return []Id{}
+}
+
+func (g Genre) GetParent() Id {
+	return ""
+}
+
+func (g Genre) GetType() ItemType {
+	return TypeGenre
+}
@@ -0,0 +1,44 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package models
+
+import "time"
+
+// ItemInfo contains detailed information of a song or album, such as file and codec info.
+// Values that server does not provide are left empty.
+type ItemInfo struct {
+	Id   Id
+	Type ItemType
+	Name string
+	// Album is set if item is a song
+	Album   IdName
+	Artists []IdName
+	Genres  []IdName
+	// Path is file path on server
+	Path  string
+	Codec string
+	// Bitrate in kbps
+	Bitrate int
+	// SampleRate in Hz
+	SampleRate int
+	// Size in bytes
+	Size      int64
+	PlayCount int
+	DateAdded time.Time
+}
@@ -26,6 +26,11 @@ type Playlist struct {
 
 	Songs     []*Song
 	SongCount int `db:"song_count"`
+
+	// Shared is set when playlist is owned by another user and shared with current user.
+	Shared bool
+	// ReadOnly is set when current user is not allowed to edit shared playlist.
+	ReadOnly bool
 }
 
 func (p Playlist) GetId() Id {
@@ -55,3 +60,11 @@ func (p Playlist) GetParent() Id {
 func (p Playlist) GetType() ItemType {
 	return TypePlaylist
 }
+
+// PlaylistImport is result of creating playlist from playlist file.
+type PlaylistImport struct {
+	// Playlist is the created playlist with matched songs.
+	Playlist *Playlist
+	// Unmatched lists entries that were not found in library.
+	Unmatched []string
+}
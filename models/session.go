@@ -0,0 +1,42 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package models
+
+// Session is another client's playback session that can be controlled remotely.
+type Session struct {
+	Id         Id
+	Client     string
+	DeviceName string
+	UserName   string
+	// NowPlaying is name of the item being played, empty if nothing is playing.
+	NowPlaying string
+	Paused     bool
+}
+
+// Label returns human readable name for session.
+func (s *Session) Label() string {
+	label := s.DeviceName
+	if s.Client != "" {
+		label += " (" + s.Client + ")"
+	}
+	if s.NowPlaying != "" {
+		label += " - " + s.NowPlaying
+	}
+	return label
+}
@@ -18,6 +18,8 @@
 
 package models
 
+import "time"
+
 // Song always belongs to album (even if single) and has artist.
 // There might be multiple artists.
 type Song struct {
@@ -33,8 +35,36 @@ type Song struct {
 	Artists []IdName
 	// AlbumArtist is primary artist
 	AlbumArtist Id `db:"artist"`
+	// Composers of song, if known.
+	Composers []IdName
 
 	Favorite bool `db:"favorite"`
+
+	// Size is file size in bytes, 0 if unknown.
+	Size int64
+	// Codec is audio codec or file format, e.g. 'flac'. Empty if unknown.
+	Codec string
+	// Bitrate in kbps, 0 if unknown.
+	Bitrate int
+	// AlbumName is name of album, empty if unknown.
+	AlbumName string
+	// Year is release year, 0 if unknown.
+	Year int
+	// PlayCount is how many times user has played song, 0 if unknown.
+	PlayCount int
+	// Rating is user rating from 1 to 5, 0 if not rated.
+	Rating int
+	// LastPlayed is when user last played song, zero if unknown.
+	LastPlayed time.Time
+	// Sources are names of servers song is found from. Only set when using multiple servers.
+	Sources []string
+
+	// Published is publish date of podcast episode, zero if unknown.
+	Published time.Time
+	// Position is saved playback position in milliseconds, for resuming podcast episodes.
+	Position int
+	// Played is set after song or episode has been played.
+	Played bool
 }
 
 func (s *Song) GetId() Id {
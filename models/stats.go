@@ -38,6 +38,12 @@ type Stats struct {
 	ServerInfo *ServerInfo
 
 	StorageInfo StorageInfo
+
+	// RequestStats contains statistics of api requests to remote server, if server provides them.
+	RequestStats RequestStats
+
+	// PlayStats contains statistics of local play history, if it is enabled.
+	PlayStats PlayStats
 }
 
 // HeapString returns heap usage in human-readable format
@@ -84,6 +90,77 @@ func (s StorageInfo) LastUpdatedString() string {
 
 }
 
+// PlayStats contains statistics of plays recorded to local history.
+type PlayStats struct {
+	// Plays is the number of recorded plays.
+	Plays int
+	// Songs is the number of distinct songs played.
+	Songs int
+	// Listened is total listening time in seconds.
+	Listened int
+	// Completion is average share of song listened per play, from 0 to 1.
+	Completion float64
+	// PendingScrobbles is the number of plays waiting to be scrobbled to server.
+	PendingScrobbles int
+}
+
+// CompletionString returns average completion as percentage, e.g. '85%'.
+func (s PlayStats) CompletionString() string {
+	return fmt.Sprintf("%.0f%%", s.Completion*100)
+}
+
+// RequestStats contains statistics of api requests made to remote server.
+type RequestStats struct {
+	// Requests is the number of requests sent, including retries.
+	Requests int
+	// Retries is the number of requests retried after transient error.
+	Retries int
+	// Shared is the number of requests that used response of identical concurrent request.
+	Shared int
+	// Revalidated is the number of requests made with cached response.
+	Revalidated int
+	// NotModified is the number of cached responses that were not changed on server.
+	NotModified int
+	// BytesReceived is the number of response bytes received, compressed.
+	BytesReceived int
+	// BytesDecoded is the number of response bytes after decompression.
+	BytesDecoded int
+	// BytesCached is the number of response bytes served from cache.
+	BytesCached int
+}
+
+// Add adds other stats to s.
+func (s *RequestStats) Add(other RequestStats) {
+	s.Requests += other.Requests
+	s.Retries += other.Retries
+	s.Shared += other.Shared
+	s.Revalidated += other.Revalidated
+	s.NotModified += other.NotModified
+	s.BytesReceived += other.BytesReceived
+	s.BytesDecoded += other.BytesDecoded
+	s.BytesCached += other.BytesCached
+}
+
+// CacheHitString returns cache hits of revalidated requests, e.g. '8 / 10 (80%)'.
+func (s RequestStats) CacheHitString() string {
+	if s.Revalidated == 0 {
+		return "0 / 0"
+	}
+	return fmt.Sprintf("%d / %d (%d%%)", s.NotModified, s.Revalidated, s.NotModified*100/s.Revalidated)
+}
+
+func (s RequestStats) BytesReceivedString() string {
+	return byteToString(s.BytesReceived)
+}
+
+func (s RequestStats) BytesDecodedString() string {
+	return byteToString(s.BytesDecoded)
+}
+
+func (s RequestStats) BytesCachedString() string {
+	return byteToString(s.BytesCached)
+}
+
 func byteToString(bytes int) string {
 	f := float32(bytes)
 	if bytes < 1024 {
@@ -23,4 +23,6 @@ type View struct {
 	Name string
 	Id   Id
 	Type string
+	// CollectionType is type of collection content, e.g. 'music' or 'movies'.
+	CollectionType string
 }
@@ -0,0 +1,58 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package mpris
+
+import (
+	"fmt"
+	"github.com/godbus/dbus"
+	"strings"
+	"tryffel.net/go/jellycli/config"
+)
+
+// CallPlayer calls method without arguments, e.g. 'PlayPause', in player interface of every running jellycli
+// instance on session bus. If no instance is found, return error.
+func CallPlayer(method string) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return fmt.Errorf("connect to session bus: %v", err)
+	}
+
+	var names []string
+	err = conn.BusObject().Call("org.freedesktop.DBus.ListNames", 0).Store(&names)
+	if err != nil {
+		return fmt.Errorf("list dbus names: %v", err)
+	}
+
+	prefix := baseObject + "." + strings.ToLower(config.AppName) + "."
+	found := false
+	for _, name := range names {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		found = true
+		call := conn.Object(name, basePath).Call(objectName("Player")+"."+method, 0)
+		if call.Err != nil {
+			return fmt.Errorf("call %s: %v", name, call.Err)
+		}
+	}
+	if !found {
+		return fmt.Errorf("no %s instance found in session bus", config.AppName)
+	}
+	return nil
+}
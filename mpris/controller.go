@@ -45,6 +45,7 @@ type MediaController struct {
 	props      *prop.Properties
 	controller interfaces.Player
 	name       string
+	player     *Player
 }
 
 // Close ends the connection.
@@ -52,6 +53,11 @@ func (m *MediaController) Close() error {
 	return m.dbus.Close()
 }
 
+// Player returns player object that is exported to DBus.
+func (m *MediaController) Player() *Player {
+	return m.player
+}
+
 // Name returns the name of the instance.
 func (m *MediaController) Name() string {
 	return m.name
@@ -70,6 +76,7 @@ func NewController(controller interfaces.Player) (c *MediaController, err error)
 	c.dbus.Export(c, basePath, baseObject)
 
 	player := &Player{MediaController: c}
+	c.player = player
 	c.dbus.Export(player, basePath, objectName("Player"))
 
 	c.dbus.Export(introspect.NewIntrospectable(c.IntrospectNode()), basePath,
@@ -22,10 +22,12 @@ package mpris
 
 import (
 	"errors"
+	"fmt"
 	"github.com/godbus/dbus"
 	"github.com/godbus/dbus/prop"
 	"github.com/sirupsen/logrus"
 	"math"
+	"sync"
 	"time"
 	"tryffel.net/go/jellycli/interfaces"
 )
@@ -36,6 +38,7 @@ import (
 // https://specifications.freedesktop.org/mpris-spec/latest/Player_Interface.html
 type Player struct {
 	*MediaController
+	lock      sync.Mutex
 	lastState interfaces.AudioStatus
 }
 
@@ -83,7 +86,10 @@ const (
 
 //UpdateStatus updates status to dbus
 func (p *Player) UpdateStatus(state interfaces.AudioStatus) {
+	p.lock.Lock()
+	last := p.lastState
 	p.lastState = state
+	p.lock.Unlock()
 	var playStatus PlaybackStatus
 	switch state.State {
 	case interfaces.AudioStatePlaying:
@@ -104,9 +110,11 @@ func (p *Player) UpdateStatus(state interfaces.AudioStatus) {
 		pos = int64(state.SongPast.MicroSeconds())
 		data = mapFromStatus(state)
 	}
-	if err := p.props.Set(object, "Metadata", dbus.MakeVariant(data)); err != nil {
-		logrus.Error(err)
-		return
+	if metadataChanged(last, state) {
+		if err := p.props.Set(object, "Metadata", dbus.MakeVariant(data)); err != nil {
+			logrus.Error(err)
+			return
+		}
 	}
 	if err := p.props.Set(object, "Position", dbus.MakeVariant(pos)); err != nil {
 		logrus.Error(err)
@@ -117,6 +125,54 @@ func (p *Player) UpdateStatus(state interfaces.AudioStatus) {
 		logrus.Error(err)
 		return
 	}
+
+	if state.Shuffle != last.Shuffle {
+		if err := p.props.Set(object, "Shuffle", dbus.MakeVariant(state.Shuffle)); err != nil {
+			logrus.Error(err)
+			return
+		}
+	}
+	if state.Volume != last.Volume || state.Muted != last.Muted {
+		if err := p.props.Set(object, "Volume", dbus.MakeVariant(volumeFromStatus(state))); err != nil {
+			logrus.Error(err)
+			return
+		}
+	}
+	if state.Action == interfaces.AudioActionSeek {
+		p.emitSeeked(pos)
+	}
+}
+
+// metadataChanged returns true if song or its metadata differ in states.
+func metadataChanged(last, state interfaces.AudioStatus) bool {
+	if last.Song == nil || state.Song == nil {
+		return last.Song != state.Song
+	}
+	return last.Song.Id != state.Song.Id || last.Song.Favorite != state.Song.Favorite ||
+		last.AlbumImageUrl != state.AlbumImageUrl
+}
+
+// volumeFromStatus returns volume in range [0,1], muted volume being 0.
+func volumeFromStatus(state interfaces.AudioStatus) float64 {
+	if state.Muted {
+		return 0
+	}
+	return float64(state.Volume) / 100
+}
+
+// emitSeeked signals that position has changed other than by normal playback.
+// https://specifications.freedesktop.org/mpris-spec/latest/Player_Interface.html#Signal:Seeked
+func (p *Player) emitSeeked(position int64) {
+	err := p.dbus.Emit(basePath, objectName("Player")+".Seeked", position)
+	if err != nil {
+		logrus.Errorf("emit seeked signal: %v", err)
+	}
+}
+
+func (p *Player) state() interfaces.AudioStatus {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.lastState
 }
 
 func notImplemented(c *prop.Change) *dbus.Error {
@@ -125,10 +181,13 @@ func notImplemented(c *prop.Change) *dbus.Error {
 
 // OnLoopStatus handles LoopStatus change.
 // https://specifications.freedesktop.org/mpris-spec/latest/Player_Interface.html#Property:LoopStatus
+// Repeating is not supported, so only LoopStatusNone is accepted.
 func (p *Player) OnLoopStatus(c *prop.Change) *dbus.Error {
 	loop := LoopStatus(c.Value.(string))
 	logrus.Debugf("LoopStatus changed to %v\n", loop)
-
+	if loop != LoopStatusNone {
+		return dbus.MakeFailedError(fmt.Errorf("loop status %s not supported", loop))
+	}
 	return nil
 }
 
@@ -149,22 +208,23 @@ func (p *Player) OnVolume(c *prop.Change) *dbus.Error {
 // https://specifications.freedesktop.org/mpris-spec/latest/Player_Interface.html#Property:Shuffle
 func (p *Player) OnShuffle(c *prop.Change) *dbus.Error {
 	logrus.Debugf("Shuffle changed to %v\n", c.Value.(bool))
-	//return transform(p.mpd.Random(c.Value.(bool)))
+	p.controller.SetShuffle(c.Value.(bool))
 	return nil
 }
 
 func (p *Player) properties() map[string]*prop.Prop {
 	return map[string]*prop.Prop{
 		"PlaybackStatus": newProp(PlaybackStatusPlaying, true, true, nil),
-		"LoopStatus":     newProp(LoopStatusTrack, true, true, p.OnLoopStatus),
+		"LoopStatus":     newProp(LoopStatusNone, true, true, p.OnLoopStatus),
 		"Rate":           newProp(1.0, true, true, notImplemented),
-		"Shuffle":        newProp(false, true, true, p.OnShuffle),
+		"Shuffle":        newProp(p.lastState.Shuffle, true, true, p.OnShuffle),
 		"Metadata":       newProp(mapFromStatus(p.lastState), true, true, nil),
 		"Volume":         newProp(math.Max(0, float64(80)/100.0), true, true, p.OnVolume),
+		// position changes constantly, clients read it and listen to Seeked signal.
 		"Position": &prop.Prop{
-			Value:    UsFromDuration(0),
-			Writable: true,
-			Emit:     prop.EmitTrue,
+			Value:    int64(0),
+			Writable: false,
+			Emit:     prop.EmitFalse,
 			Callback: nil,
 		},
 		"MinimumRate":   newProp(1.0, false, true, nil),
@@ -173,7 +233,7 @@ func (p *Player) properties() map[string]*prop.Prop {
 		"CanGoPrevious": newProp(true, false, true, nil),
 		"CanPlay":       newProp(true, false, true, nil),
 		"CanPause":      newProp(true, false, true, nil),
-		"CanSeek":       newProp(false, true, true, nil),
+		"CanSeek":       newProp(true, false, true, nil),
 		"CanControl":    newProp(true, false, true, nil),
 	}
 }
@@ -225,11 +285,26 @@ func (p *Player) PlayPause() *dbus.Error {
 // Seek seeks forward in the current track by the specified number of microseconds.
 // https://specifications.freedesktop.org/mpris-spec/latest/Player_Interface.html#Method:Seek
 func (p *Player) Seek(x TimeInUs) *dbus.Error {
+	if p.state().Song == nil {
+		return nil
+	}
+	p.controller.Seek(interfaces.AudioTick(x.Duration().Milliseconds()))
 	return nil
 }
 
 // SetPosition sets the current track position in microseconds.
 // https://specifications.freedesktop.org/mpris-spec/latest/Player_Interface.html#Method:SetPosition
-func (p *Player) SetPosition(o TrackID, x TimeInUs) *dbus.Error {
+// If track is not current track or position is outside track, do nothing.
+func (p *Player) SetPosition(o dbus.ObjectPath, x TimeInUs) *dbus.Error {
+	state := p.state()
+	song := state.Song
+	if song == nil || o != trackId(song) {
+		return nil
+	}
+	target := x.Duration()
+	if target < 0 || target > time.Duration(song.Duration)*time.Second {
+		return nil
+	}
+	p.controller.SeekTo(interfaces.AudioTick(target.Milliseconds()))
 	return nil
 }
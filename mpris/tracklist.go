@@ -21,6 +21,7 @@ package mpris
 import (
 	"fmt"
 	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
 
 	"github.com/godbus/dbus"
 )
@@ -72,22 +73,47 @@ func mapFromStatus(s interfaces.AudioStatus) MetadataMap {
 	}
 
 	m := &MetadataMap{
-		"mpris:trackid": dbus.ObjectPath(fmt.Sprintf(TrackIDFormat, s.Song.Id)),
-		"mpris:length":  s.Song.Duration * 1000 * 1000,
+		"mpris:trackid": trackId(s.Song),
+		"mpris:length":  int64(s.Song.Duration) * 1000 * 1000,
 	}
 
+	// mpris:artUrl is file url to cached album cover
+	m.nonEmptyString("mpris:artUrl", s.AlbumImageUrl)
 	if s.Album != nil {
 		m.nonEmptyString("xesam:album", s.Album.Name)
-		m.nonEmptyString("mpris:artUrl", s.AlbumImageUrl)
 	}
 	if s.Artist != nil {
-		m.nonEmptyString("xesam:artist", s.Artist.Name)
+		m.nonEmptySlice("xesam:albumArtist", []string{s.Artist.Name})
 	}
 
+	artists := idNames(s.Song.Artists)
+	if len(artists) == 0 && s.Artist != nil {
+		artists = []string{s.Artist.Name}
+	}
+	m.nonEmptySlice("xesam:artist", artists)
+	m.nonEmptySlice("xesam:composer", idNames(s.Song.Composers))
 	m.nonEmptyString("xesam:title", s.Song.Name)
 
-	// mpris:artUrl, xesam:artist, xesam:url
-	(*m)["xesam:trackNumber"] = s.Song.Index
+	(*m)["xesam:trackNumber"] = int32(s.Song.Index)
+	if s.Song.DiscNumber > 0 {
+		(*m)["xesam:discNumber"] = int32(s.Song.DiscNumber)
+	}
+	if s.Song.Favorite {
+		(*m)["xesam:userRating"] = 1.0
+	}
 
 	return *m
 }
+
+// trackId returns mpris track id for song.
+func trackId(song *models.Song) dbus.ObjectPath {
+	return dbus.ObjectPath(fmt.Sprintf(TrackIDFormat, song.Id))
+}
+
+func idNames(items []models.IdName) []string {
+	names := make([]string, len(items))
+	for i, v := range items {
+		names[i] = v.Name
+	}
+	return names
+}
@@ -0,0 +1,145 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package nowplaying writes current song to a file, named pipe or terminal title, e.g. for status bars
+// and stream overlays.
+package nowplaying
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"tryffel.net/go/jellycli/interfaces"
+)
+
+// Writer writes current song to file every time it changes. Regular files are replaced atomically,
+// so readers never see partial content. Named pipes get a line for each change, if there's a reader.
+type Writer struct {
+	file   string
+	format string
+
+	lock    sync.Mutex
+	last    string
+	closed  bool
+	pending chan string
+	done    chan bool
+}
+
+// NewWriter starts writing status from player to file with format. See config.Player.NowPlayingFormat.
+func NewWriter(file, format string, player interfaces.Player) *Writer {
+	w := &Writer{
+		file:    file,
+		format:  strings.ReplaceAll(format, `\n`, "\n"),
+		pending: make(chan string, 1),
+		done:    make(chan bool),
+	}
+	// clear song from previous run
+	w.pending <- ""
+	go w.loop()
+	player.AddStatusCallback(w.statusChanged)
+	return w
+}
+
+// Close clears file and stops writing.
+func (w *Writer) Close() {
+	w.lock.Lock()
+	if w.closed {
+		w.lock.Unlock()
+		return
+	}
+	w.closed = true
+	w.push("")
+	close(w.pending)
+	w.lock.Unlock()
+	<-w.done
+}
+
+func (w *Writer) statusChanged(status interfaces.AudioStatus) {
+	text := formatStatus(w.format, status)
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.closed || text == w.last {
+		return
+	}
+	w.last = text
+	w.push(text)
+}
+
+// push replaces pending text, if it's not written yet. Lock must be held.
+func (w *Writer) push(text string) {
+	select {
+	case <-w.pending:
+	default:
+	}
+	w.pending <- text
+}
+
+func (w *Writer) loop() {
+	defer close(w.done)
+	lastErr := ""
+	for text := range w.pending {
+		err := w.write(text)
+		if err == nil {
+			lastErr = ""
+			continue
+		}
+		// position may change every second, don't flood log with same error
+		if err.Error() != lastErr {
+			logrus.Errorf("write now playing file: %v", err)
+		}
+		lastErr = err.Error()
+	}
+}
+
+func (w *Writer) write(text string) error {
+	info, err := os.Stat(w.file)
+	if err == nil && info.Mode()&os.ModeNamedPipe != 0 {
+		return writePipe(w.file, text)
+	}
+
+	tmp := w.file + ".tmp"
+	err = ioutil.WriteFile(tmp, []byte(text), 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, w.file)
+}
+
+// formatStatus formats status. If nothing is playing, return empty string.
+func formatStatus(format string, status interfaces.AudioStatus) string {
+	if status.State == interfaces.AudioStateStopped || status.Song == nil {
+		return ""
+	}
+	state := "playing"
+	if status.Paused {
+		state = "paused"
+	}
+	tokens := append(interfaces.StatusTokens(status),
+		"{state}", state,
+		"{position}", formatSeconds(status.SongPast.Seconds()),
+		"{duration}", formatSeconds(status.Song.Duration),
+	)
+	return strings.NewReplacer(tokens...).Replace(format) + "\n"
+}
+
+func formatSeconds(seconds int) string {
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
@@ -0,0 +1,133 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package nowplaying
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path"
+	"testing"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+type fakePlayer struct {
+	interfaces.Player
+	statusCb func(status interfaces.AudioStatus)
+}
+
+func (f *fakePlayer) AddStatusCallback(cb func(status interfaces.AudioStatus)) { f.statusCb = cb }
+
+func Test_formatStatus(t *testing.T) {
+	status := interfaces.AudioStatus{
+		State:    interfaces.AudioStatePlaying,
+		Song:     &models.Song{Name: "song", Duration: 185},
+		Artist:   &models.Artist{Name: "artist"},
+		Album:    &models.Album{Name: "album", Year: 1999},
+		SongPast: 61000,
+		Paused:   true,
+	}
+	tests := []struct {
+		name   string
+		format string
+		status interfaces.AudioStatus
+		want   string
+	}{
+		{name: "default", format: "{artist} - {title}", status: status, want: "artist - song\n"},
+		{name: "progress", format: "{title} [{state}] {position}/{duration}\n{album} ({year})", status: status,
+			want: "song [paused] 1:01/3:05\nalbum (1999)\n"},
+		{name: "stopped", format: "{artist} - {title}", status: interfaces.AudioStatus{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatStatus(tt.format, tt.status); got != tt.want {
+				t.Errorf("formatStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	file := path.Join(t.TempDir(), "now-playing")
+	player := &fakePlayer{}
+	w := NewWriter(file, "{title}", player)
+	player.statusCb(interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Song: &models.Song{Name: "first"}})
+	player.statusCb(interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Song: &models.Song{Name: "second"}})
+
+	// pending write finishes before close
+	w.lock.Lock()
+	w.closed = true
+	close(w.pending)
+	w.lock.Unlock()
+	<-w.done
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "second\n" {
+		t.Errorf("got %q, want %q", data, "second\n")
+	}
+}
+
+func TestWriter_Close(t *testing.T) {
+	file := path.Join(t.TempDir(), "now-playing")
+	player := &fakePlayer{}
+	w := NewWriter(file, "{title}", player)
+	player.statusCb(interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Song: &models.Song{Name: "song"}})
+	w.Close()
+	w.Close()
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 0 {
+		t.Errorf("file not cleared on close: %q", data)
+	}
+}
+
+func Test_formatTitle(t *testing.T) {
+	status := interfaces.AudioStatus{
+		State:  interfaces.AudioStatePlaying,
+		Song:   &models.Song{Name: "song\x1b]2;evil\x07"},
+		Artist: &models.Artist{Name: "artist"},
+	}
+	if got := formatTitle("{artist} – {title} [Jellycli]", status); got != "artist – song]2;evil [Jellycli]" {
+		t.Errorf("formatTitle() = %q", got)
+	}
+	if got := formatTitle("{title}", interfaces.AudioStatus{}); got != DefaultTitle {
+		t.Errorf("formatTitle() stopped = %q, want %q", got, DefaultTitle)
+	}
+}
+
+func TestTerminalTitle(t *testing.T) {
+	out := &bytes.Buffer{}
+	player := &fakePlayer{}
+	title := NewTerminalTitle("{title}", out, player)
+	song := interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Song: &models.Song{Name: "first"}}
+	player.statusCb(song)
+	song.SongPast = 1000
+	player.statusCb(song)
+	title.Close()
+	player.statusCb(interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Song: &models.Song{Name: "second"}})
+
+	want := "\x1b[22;0t\x1b]2;Jellycli\x07\x1b]2;first\x07\x1b]2;\x07\x1b[23;0t"
+	if out.String() != want {
+		t.Errorf("terminal output = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,44 @@
+//go:build !windows
+// +build !windows
+
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package nowplaying
+
+import (
+	"os"
+	"syscall"
+)
+
+// writePipe writes text to named pipe. If there's no reader, or pipe is full, text is dropped.
+func writePipe(file string, text string) error {
+	fd, err := os.OpenFile(file, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		if pathErr, ok := err.(*os.PathError); ok && pathErr.Err == syscall.ENXIO {
+			return nil
+		}
+		return err
+	}
+	defer fd.Close()
+	_, err = fd.Write([]byte(text))
+	if pathErr, ok := err.(*os.PathError); ok && pathErr.Err == syscall.EAGAIN {
+		return nil
+	}
+	return err
+}
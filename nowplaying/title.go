@@ -0,0 +1,107 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package nowplaying
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"strings"
+	"sync"
+	"tryffel.net/go/jellycli/interfaces"
+)
+
+// DefaultTitle is terminal title when nothing is playing.
+const DefaultTitle = "Jellycli"
+
+// TerminalTitle sets terminal window title to current song with xterm escape sequences.
+// Previous title is saved on start and restored on close, if terminal supports it.
+type TerminalTitle struct {
+	out    io.Writer
+	format string
+
+	lock   sync.Mutex
+	last   string
+	closed bool
+}
+
+// NewTerminalTitle starts updating title on out, usually stdout, with format.
+// See config.Gui.TerminalTitleFormat.
+func NewTerminalTitle(format string, out io.Writer, player interfaces.Player) *TerminalTitle {
+	t := &TerminalTitle{
+		out:    out,
+		format: format,
+	}
+	// push current title to terminal's stack
+	t.write("\x1b[22;0t")
+	t.setTitle(DefaultTitle)
+	player.AddStatusCallback(t.statusChanged)
+	return t
+}
+
+// Close restores previous title.
+func (t *TerminalTitle) Close() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	t.setTitle("")
+	t.write("\x1b[23;0t")
+}
+
+func (t *TerminalTitle) statusChanged(status interfaces.AudioStatus) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.closed {
+		return
+	}
+	t.setTitle(formatTitle(t.format, status))
+}
+
+// setTitle sets title if it has changed. Lock must be held.
+func (t *TerminalTitle) setTitle(title string) {
+	if title == t.last {
+		return
+	}
+	t.last = title
+	t.write(fmt.Sprintf("\x1b]2;%s\x07", title))
+}
+
+func (t *TerminalTitle) write(text string) {
+	_, err := io.WriteString(t.out, text)
+	if err != nil {
+		logrus.Errorf("set terminal title: %v", err)
+	}
+}
+
+// formatTitle formats title for status. If nothing is playing, return DefaultTitle.
+// Control characters are removed, so that song metadata can't inject escape sequences.
+func formatTitle(format string, status interfaces.AudioStatus) string {
+	if status.State == interfaces.AudioStateStopped || status.Song == nil {
+		return DefaultTitle
+	}
+	title := strings.NewReplacer(interfaces.StatusTokens(status)...).Replace(format)
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, title)
+}
@@ -0,0 +1,147 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path"
+	"tryffel.net/go/jellycli/models"
+)
+
+// artColorSamples is number of pixels sampled from each row and column of image.
+const artColorSamples = 50
+
+// color returns dominant color of item's image. Image is downloaded to cover cache from imageUrl,
+// and color is stored in cache index, so both are fetched only once. Items whose image fails
+// are not retried until restart.
+func (c *covers) color(item models.Id, imageUrl string) (string, error) {
+	c.lock.Lock()
+	if c.noColor[item] {
+		c.lock.Unlock()
+		return "", errNoCover
+	}
+	if file, ok := c.albums[item]; ok && c.files[file].Color != "" {
+		c.lock.Unlock()
+		return c.files[file].Color, nil
+	}
+	c.lock.Unlock()
+
+	file, err := c.get(item, imageUrl)
+	var hex string
+	if err == nil {
+		hex, err = imageColor(file)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err != nil {
+		c.noColor[item] = true
+		return "", err
+	}
+	if entry, ok := c.files[path.Base(file)]; ok {
+		entry.Color = hex
+		if err := c.save(); err != nil {
+			logrus.Warningf("save cover cache: %v", err)
+		}
+	}
+	return hex, nil
+}
+
+// imageColor decodes image file and returns its dominant color as '#rrggbb'.
+func imageColor(file string) (string, error) {
+	fd, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer fd.Close()
+	img, _, err := image.Decode(fd)
+	if err != nil {
+		return "", fmt.Errorf("decode image: %v", err)
+	}
+	c, ok := dominantColor(img)
+	if !ok {
+		return "", errNoCover
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B), nil
+}
+
+// dominantColor returns the most common color of image. Sampled pixels are grouped by 4 most significant
+// bits of each channel, and average of the largest group is returned. Transparent pixels are skipped,
+// and if there are only transparent pixels, ok is false.
+func dominantColor(img image.Image) (c color.RGBA, ok bool) {
+	type group struct {
+		r, g, b, count int
+	}
+	groups := map[int]*group{}
+	bounds := img.Bounds()
+	stepX := bounds.Dx() / artColorSamples
+	if stepX < 1 {
+		stepX = 1
+	}
+	stepY := bounds.Dy() / artColorSamples
+	if stepY < 1 {
+		stepY = 1
+	}
+
+	var largest *group
+	largestKey := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			pixel := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if pixel.A < 0x80 {
+				continue
+			}
+			key := int(pixel.R>>4)<<8 | int(pixel.G>>4)<<4 | int(pixel.B>>4)
+			g, found := groups[key]
+			if !found {
+				g = &group{}
+				groups[key] = g
+			}
+			g.r += int(pixel.R)
+			g.g += int(pixel.G)
+			g.b += int(pixel.B)
+			g.count++
+			if largest == nil || g.count > largest.count || (g.count == largest.count && key < largestKey) {
+				largest = g
+				largestKey = key
+			}
+		}
+	}
+	if largest == nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{
+		R: uint8(largest.r / largest.count),
+		G: uint8(largest.g / largest.count),
+		B: uint8(largest.b / largest.count),
+		A: 0xff,
+	}, true
+}
+
+// GetArtColor implements interfaces.ArtColorProvider.
+func (p *Player) GetArtColor(item models.Id, itemType models.ItemType) (string, error) {
+	return p.covers.color(item, p.api.GetImageUrl(item, itemType))
+}
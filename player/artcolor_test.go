@@ -0,0 +1,112 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync/atomic"
+	"testing"
+)
+
+// twoColorImage is mostly main color, with a stripe of other color on the left.
+func twoColorImage(main, other color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			if x < 30 {
+				img.Set(x, y, other)
+			} else {
+				img.Set(x, y, main)
+			}
+		}
+	}
+	return img
+}
+
+func Test_dominantColor(t *testing.T) {
+	red := color.NRGBA{R: 200, G: 20, B: 30, A: 255}
+	blue := color.NRGBA{R: 10, G: 40, B: 220, A: 255}
+	got, ok := dominantColor(twoColorImage(red, blue))
+	want := color.RGBA{R: 200, G: 20, B: 30, A: 255}
+	if !ok || got != want {
+		t.Errorf("dominantColor() = %v, %v, want %v", got, ok, want)
+	}
+
+	// transparent pixels are skipped
+	got, ok = dominantColor(twoColorImage(color.NRGBA{}, blue))
+	want = color.RGBA{R: 10, G: 40, B: 220, A: 255}
+	if !ok || got != want {
+		t.Errorf("dominantColor() with transparency = %v, %v, want %v", got, ok, want)
+	}
+
+	if _, ok := dominantColor(image.NewNRGBA(image.Rect(0, 0, 10, 10))); ok {
+		t.Errorf("transparent image must not have dominant color")
+	}
+}
+
+func TestCovers_color(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := png.Encode(buf, twoColorImage(color.NRGBA{R: 0x20, G: 0x80, B: 0x40, A: 255}, color.White))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/broken" {
+			w.Write([]byte("not an image"))
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	dir := path.Join(t.TempDir(), "covers")
+	c := newCovers(dir, 1024*1024)
+	got, err := c.color("artist-1", server.URL+"/artist")
+	if err != nil || got != "#208040" {
+		t.Errorf("color: %s, err: %v, want #208040", got, err)
+	}
+
+	// color is stored in cache index
+	loaded := newCovers(dir, 1024*1024)
+	if got, err := loaded.color("artist-1", ""); err != nil || got != "#208040" {
+		t.Errorf("loaded color: %s, err: %v", got, err)
+	}
+	if requests != 1 {
+		t.Errorf("image downloaded %d times, want once", requests)
+	}
+
+	// broken image is not downloaded again
+	for i := 0; i < 2; i++ {
+		if _, err := c.color("album-1", server.URL+"/broken"); err == nil {
+			t.Errorf("broken image must fail")
+		}
+	}
+	if requests != 2 {
+		t.Errorf("broken image downloaded %d times, want once", requests-1)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"time"
 	"tryffel.net/go/jellycli/config"
 	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
 )
 
 type audioFormat string
@@ -49,12 +50,22 @@ type Audio struct {
 	volume *effects.Volume
 	// mixer allows adding multiple streams sequentially
 	mixer *beep.Mixer
+	// visualizer collects samples for spectrum before volume is applied
+	visualizer *visualizer
+	// gain is volume offset of current song in volume effect units, added to volume
+	gain float64
 
 	songCompleteFunc func()
+	// gainFunc returns volume offset in decibels for song that starts playing
+	gainFunc func(song *models.Song) int
 
 	statusCallbacks []func(status interfaces.AudioStatus)
 
 	currentSampleRate int
+	// streamSampleRate is the sample rate of current song
+	streamSampleRate int
+	// download reports download progress of current song, nil if song is not downloaded while playing.
+	download interfaces.DownloadProgress
 }
 
 // initialize new player. This also initializes faiface.Speaker, which should be initialized only once.
@@ -75,14 +86,21 @@ func newAudio() *Audio {
 	}
 	a.ctrl.Streamer = a.mixer
 	a.ctrl.Paused = false
-	a.volume.Streamer = a.ctrl
+	a.visualizer = newVisualizer(a.ctrl, config.AudioSamplingRate)
+	a.volume.Streamer = a.visualizer
 	a.volume.Silent = false
 	a.status.Volume = 50
 
 	a.currentSampleRate = config.AudioSamplingRate
+	a.streamSampleRate = config.AudioSamplingRate
 	return a
 }
 
+// CheckAudio opens audio output, so that missing audio device or backend can be reported before playing.
+func CheckAudio() error {
+	return initAudio()
+}
+
 func initAudio() error {
 	err := speaker.Init(config.AudioSamplingRate, config.AudioSamplingRate/1000*
 		int(config.AudioBufferPeriod.Milliseconds()))
@@ -197,8 +215,85 @@ func (a *Audio) Previous() {
 	go a.flushStatus()
 }
 
-// Seek seeks given ticks. If there is no audio, do nothing.
+// Seek seeks given ticks. If there is no audio, do nothing. If stream does not support seeking,
+// seeking forward skips samples and seeking backwards is not possible.
 func (a *Audio) Seek(ticks interfaces.AudioTick) {
+	speaker.Lock()
+	defer speaker.Unlock()
+	if a.streamer == nil {
+		return
+	}
+	a.seekSample(a.streamer.Position() + ticksToSamples(ticks, a.streamSampleRate))
+}
+
+// SeekTo seeks to position from the beginning of song. Position is converted directly to samples,
+// so seeking is accurate even though reported status is only updated once a second.
+func (a *Audio) SeekTo(position interfaces.AudioTick) {
+	speaker.Lock()
+	defer speaker.Unlock()
+	if a.streamer == nil {
+		return
+	}
+	a.seekSample(ticksToSamples(position, a.streamSampleRate))
+}
+
+// seekSample seeks streamer to target sample. Speaker lock must be held and streamer must not be nil.
+func (a *Audio) seekSample(target int) {
+	current := a.streamer.Position()
+	if target < 0 {
+		target = 0
+	}
+	if length := a.streamer.Len(); length > 0 && target >= length {
+		target = length - 1
+	}
+
+	err := a.streamer.Seek(target)
+	if err != nil {
+		if target <= current {
+			logrus.Warningf("seek backwards: %v", err)
+			return
+		}
+		skipSamples(a.streamer, target-current)
+	}
+
+	a.status.SongPast = samplesToTicks(a.streamer.Position(), a.streamSampleRate)
+	a.status.Action = interfaces.AudioActionSeek
+	go a.flushStatus()
+}
+
+// resumeSong seeks streamer to saved position of song, if song has been partially played,
+// e.g. podcast episodes.
+func resumeSong(streamer beep.StreamSeeker, song *models.Song, sampleRate int) {
+	if song.Position <= 0 || song.Played {
+		return
+	}
+	position := interfaces.AudioTick(song.Position)
+	target := ticksToSamples(position, sampleRate)
+	if length := streamer.Len(); length > 0 && target >= length {
+		return
+	}
+	err := streamer.Seek(target)
+	if err != nil {
+		logrus.Warningf("resume song at %d ms: %v", song.Position, err)
+		return
+	}
+	logrus.Debugf("resume song %s at %s", song.Name, position.Duration())
+}
+
+// skipSamples reads and discards n samples from streamer.
+func skipSamples(streamer beep.Streamer, n int) {
+	buf := make([][2]float64, 512)
+	for n > 0 {
+		size := len(buf)
+		if n < size {
+			size = n
+		}
+		read, ok := streamer.Stream(buf[:size])
+		n -= read
+		if !ok {
+			return
+		}
+	}
 }
 
 // AddStatusCallback adds a callback that gets called every time audio status is changed, or after certain time.
@@ -215,15 +310,15 @@ func (a *Audio) SetVolume(volume interfaces.AudioVolume) {
 	// settings volume to 0 does not mute audio, set silent to true
 	if decibels <= config.AudioMinVolumedB {
 		a.volume.Silent = true
-		a.volume.Volume = config.AudioMinVolumedB
+		a.volume.Volume = config.AudioMinVolumedB + a.gain
 		a.status.Volume = interfaces.AudioVolumeMin
 	} else if decibels >= config.AudioMaxVolumedB {
-		a.volume.Volume = config.AudioMaxVolumedB
+		a.volume.Volume = config.AudioMaxVolumedB + a.gain
 		a.volume.Silent = false
 		a.status.Volume = interfaces.AudioVolumeMax
 	} else {
 		a.volume.Silent = false
-		a.volume.Volume = decibels
+		a.volume.Volume = decibels + a.gain
 		a.status.Volume = volume
 	}
 	a.status.Action = interfaces.AudioActionSetVolume
@@ -231,6 +326,23 @@ func (a *Audio) SetVolume(volume interfaces.AudioVolume) {
 	go a.flushStatus()
 }
 
+// setGain sets volume offset in decibels, that is added to volume.
+func (a *Audio) setGain(offset int) {
+	speaker.Lock()
+	a.applyGain(offset)
+	speaker.Unlock()
+}
+
+// applyGain sets volume offset in decibels. Speaker lock must be held.
+func (a *Audio) applyGain(offset int) {
+	if offset != 0 {
+		logrus.Debugf("Set volume offset to %d dB", offset)
+	}
+	volume := a.volume.Volume - a.gain
+	a.gain = gainToVolume(offset)
+	a.volume.Volume = volume + a.gain
+}
+
 // SetMute mutes and un-mutes audio
 func (a *Audio) SetMute(muted bool) {
 
@@ -305,6 +417,9 @@ func (a *Audio) updateStatus() {
 	past := a.getPastTicks()
 	speaker.Lock()
 	a.status.SongPast = past
+	if a.download != nil {
+		a.status.Buffered = interfaces.BufferedPercent(a.download.Progress())
+	}
 	a.status.Action = interfaces.AudioActionTimeUpdate
 	speaker.Unlock()
 	a.flushStatus()
@@ -351,15 +466,22 @@ func (a *Audio) playSongFromReader(metadata songMetadata) error {
 			logrus.Errorf("Update sample rate (%d -> %d): %v", a.currentSampleRate, sampleRate, err)
 		} else {
 			a.currentSampleRate = sampleRate
+			a.visualizer.setSampleRate(sampleRate)
 		}
 	}
 	logrus.Debug("Setting new streamer from ", metadata.format.String())
 	if streamer == nil {
 		return fmt.Errorf("empty streamer")
 	}
+	resumeSong(streamer, metadata.song, sampleRate)
+	gain := 0
+	if a.gainFunc != nil {
+		gain = a.gainFunc(metadata.song)
+	}
 	stream := beep.Seq(streamer, beep.Callback(a.streamCompleted))
 	speaker.Clear()
 	speaker.Lock()
+	a.applyGain(gain)
 	old := a.streamer
 	a.mixer.Clear()
 	a.streamer = streamer
@@ -374,6 +496,13 @@ func (a *Audio) playSongFromReader(metadata songMetadata) error {
 	speaker.Play(a.volume)
 	speaker.Lock()
 
+	a.streamSampleRate = sampleRate
+	a.download, _ = metadata.reader.(interfaces.DownloadProgress)
+	a.status.Buffered = 0
+	if a.download != nil {
+		a.status.Buffered = interfaces.BufferedPercent(a.download.Progress())
+	}
+	a.status.SongPast = samplesToTicks(streamer.Position(), sampleRate)
 	a.status.Song = metadata.song
 	a.status.Album = metadata.album
 	a.status.Artist = metadata.artist
@@ -385,6 +514,16 @@ func (a *Audio) playSongFromReader(metadata songMetadata) error {
 	return err
 }
 
+// SetSpectrumEnabled enables collecting samples for Spectrum.
+func (a *Audio) SetSpectrumEnabled(enabled bool) {
+	a.visualizer.setEnabled(enabled)
+}
+
+// Spectrum returns levels of currently playing audio in given number of frequency bands.
+func (a *Audio) Spectrum(bands int) []float64 {
+	return a.visualizer.spectrum(bands)
+}
+
 // linear scaling with a & b coefficients
 var volumeTodBA = float32(config.AudioMaxVolumedB-config.AudioMinVolumedB) /
 	(config.AudioMaxVolume - config.AudioMinVolume)
@@ -402,6 +541,18 @@ func (a *Audio) getPastTicks() interfaces.AudioTick {
 	if a.streamer == nil {
 		return 0
 	}
-	left := a.streamer.Position() / config.AudioSamplingRate
-	return interfaces.AudioTick((time.Second * time.Duration(left)).Milliseconds())
+	return samplesToTicks(a.streamer.Position(), a.streamSampleRate)
+}
+
+// ticksToSamples converts ticks to number of samples at given sample rate.
+func ticksToSamples(ticks interfaces.AudioTick, sampleRate int) int {
+	return int(int64(ticks) * int64(sampleRate) / 1000)
+}
+
+// samplesToTicks converts number of samples at given sample rate to ticks, without rounding to seconds.
+func samplesToTicks(samples, sampleRate int) interfaces.AudioTick {
+	if sampleRate <= 0 {
+		return 0
+	}
+	return interfaces.AudioTick(int64(samples) * 1000 / int64(sampleRate))
 }
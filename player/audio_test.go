@@ -146,3 +146,63 @@ func TestAudio_SetMute(t *testing.T) {
 		t.Errorf("want audio.volume not muted")
 	}
 }
+
+// countingStreamer streams given number of samples.
+type countingStreamer struct {
+	position int
+	length   int
+}
+
+func (c *countingStreamer) Stream(samples [][2]float64) (int, bool) {
+	n := len(samples)
+	if c.length-c.position < n {
+		n = c.length - c.position
+	}
+	c.position += n
+	return n, n > 0
+}
+
+func (c *countingStreamer) Err() error {
+	return nil
+}
+
+func Test_skipSamples(t *testing.T) {
+	streamer := &countingStreamer{length: 2000}
+	skipSamples(streamer, 1500)
+	if streamer.position != 1500 {
+		t.Errorf("skip samples, got position %d, want 1500", streamer.position)
+	}
+
+	skipSamples(streamer, 1000)
+	if streamer.position != 2000 {
+		t.Errorf("skip past end, got position %d, want 2000", streamer.position)
+	}
+}
+
+func Test_samplesToTicks(t *testing.T) {
+	tests := []struct {
+		name       string
+		samples    int
+		sampleRate int
+		want       interfaces.AudioTick
+	}{
+		{name: "zero", samples: 0, sampleRate: 44100, want: 0},
+		{name: "sub-second", samples: 22050, sampleRate: 44100, want: 500},
+		{name: "not rounded to seconds", samples: 44100*61 + 4410, sampleRate: 44100, want: 61100},
+		{name: "high sample rate", samples: 96000 * 3600, sampleRate: 96000, want: 3600000},
+		{name: "no sample rate", samples: 1000, sampleRate: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := samplesToTicks(tt.samples, tt.sampleRate)
+			if got != tt.want {
+				t.Errorf("samplesToTicks() = %d, want %d", got, tt.want)
+			}
+			if tt.sampleRate > 0 {
+				if samples := ticksToSamples(got, tt.sampleRate); samples != tt.samples {
+					t.Errorf("ticksToSamples() = %d, want %d", samples, tt.samples)
+				}
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@
 package player
 
 import (
+	"context"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"time"
@@ -42,7 +43,7 @@ func (i *Items) UpdateLocalArtists(limit int) error {
 	query.Paging.CurrentPage = 0
 
 	for {
-		artists, n, err := i.browser.GetArtists(query)
+		artists, n, err := i.browser.GetArtists(context.Background(), query)
 		if err != nil {
 			return fmt.Errorf("pull artists: %v", err)
 		}
@@ -93,7 +94,7 @@ func (i *Items) UpdateLocalAlbums(limit int) error {
 	query.Paging.CurrentPage = 0
 
 	for {
-		albums, n, err := i.browser.GetAlbums(query)
+		albums, n, err := i.browser.GetAlbums(context.Background(), query)
 		if err != nil {
 			return fmt.Errorf("pull albums: %v", err)
 		}
@@ -170,7 +171,7 @@ func (i *Items) pullSongs(limit int) error {
 	query.Paging.CurrentPage = 0
 
 	for {
-		songs, n, err := i.browser.GetSongs(query)
+		songs, n, err := i.browser.GetSongs(context.Background(), query)
 		if err != nil {
 			return fmt.Errorf("pull songs: %v", err)
 		}
@@ -221,13 +222,13 @@ func (i *Items) pullSongsByAlbums(limit int) error {
 
 	for {
 		logrus.Infof("get albums, page %d", query.Paging.CurrentPage)
-		albums, _, err = i.GetAlbums(query)
+		albums, _, err = i.GetAlbums(context.Background(), query)
 		if err != nil {
 			logrus.Errorf("get albums (page %d): %v", query.Paging.CurrentPage, err)
 			continue
 		}
 		for _, album := range albums {
-			songs, err := i.browser.GetAlbumSongs(album.Id)
+			songs, err := i.browser.GetAlbumSongs(context.Background(), album.Id)
 			if err != nil {
 				logrus.Errorf("get album songs: %v", err)
 				failed += 1
@@ -260,7 +261,7 @@ func (i *Items) pullSongsByAlbums(limit int) error {
 func (i *Items) UpdatePlaylists() error {
 	logrus.Info("Update playlists from server")
 
-	playlists, err := i.browser.GetPlaylists()
+	playlists, err := i.browser.GetPlaylists(context.Background())
 	if err != nil {
 		return fmt.Errorf("get playlists: %v", err)
 	}
@@ -270,7 +271,7 @@ func (i *Items) UpdatePlaylists() error {
 	}
 
 	for index, v := range playlists {
-		songs, err := i.browser.GetPlaylistSongs(v.Id)
+		songs, err := i.browser.GetPlaylistSongs(context.Background(), v.Id)
 		if err != nil {
 			return fmt.Errorf("get playlist songs: %v", err)
 		}
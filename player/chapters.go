@@ -0,0 +1,138 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"github.com/sirupsen/logrus"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// chapterRestartTicks is how long after chapter start previous chapter restarts current chapter.
+const chapterRestartTicks = interfaces.AudioTick(3000)
+
+// chaptersChanged loads chapters when new song starts playing.
+func (p *Player) chaptersChanged(status interfaces.AudioStatus) {
+	if status.Action != interfaces.AudioActionPlay && status.Action != interfaces.AudioActionStop {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if status.Song == nil || status.State == interfaces.AudioStateStopped {
+		p.chapterSong = ""
+		p.chapters = nil
+		return
+	}
+	if status.Song.Id == p.chapterSong {
+		return
+	}
+	p.chapterSong = status.Song.Id
+	p.chapters = nil
+	go p.loadChapters(status.Song)
+}
+
+func (p *Player) loadChapters(song *models.Song) {
+	chapters, err := p.chapterProvider.GetChapters(song)
+	if err != nil {
+		if err != interfaces.ErrChaptersNotSupported {
+			logrus.Warningf("get chapters of %s: %v", song.Id, err)
+		}
+		return
+	}
+	if len(chapters) > 0 {
+		logrus.Debugf("song %s has %d chapters", song.Id, len(chapters))
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.chapterSong == song.Id {
+		p.chapters = chapters
+	}
+}
+
+// GetPlayingChapters implements interfaces.ChapterController.
+func (p *Player) GetPlayingChapters() []models.Chapter {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return append([]models.Chapter{}, p.chapters...)
+}
+
+// NextChapter implements interfaces.ChapterController.
+func (p *Player) NextChapter() *models.Chapter {
+	chapters := p.GetPlayingChapters()
+	index := nextChapter(chapters, p.Audio.getPastTicks())
+	return p.seekChapter(chapters, index)
+}
+
+// PreviousChapter implements interfaces.ChapterController.
+func (p *Player) PreviousChapter() *models.Chapter {
+	chapters := p.GetPlayingChapters()
+	index := previousChapter(chapters, p.Audio.getPastTicks())
+	return p.seekChapter(chapters, index)
+}
+
+// SeekChapter implements interfaces.ChapterController.
+func (p *Player) SeekChapter(index int) {
+	p.seekChapter(p.GetPlayingChapters(), index)
+}
+
+func (p *Player) seekChapter(chapters []models.Chapter, index int) *models.Chapter {
+	if index < 0 || index >= len(chapters) {
+		return nil
+	}
+	chapter := chapters[index]
+	p.Audio.SeekTo(interfaces.AudioTick(chapter.Start))
+	return &chapter
+}
+
+// chapterAt returns index of chapter playing at position, or -1 if position is before first chapter.
+func chapterAt(chapters []models.Chapter, position interfaces.AudioTick) int {
+	index := -1
+	for i, v := range chapters {
+		if interfaces.AudioTick(v.Start) > position {
+			break
+		}
+		index = i
+	}
+	return index
+}
+
+// nextChapter returns index of chapter after position, or -1 if there is none.
+func nextChapter(chapters []models.Chapter, position interfaces.AudioTick) int {
+	index := chapterAt(chapters, position) + 1
+	if index >= len(chapters) {
+		return -1
+	}
+	return index
+}
+
+// previousChapter returns index of chapter playing at position, or the chapter before it if
+// playing chapter has started less than chapterRestartTicks ago.
+func previousChapter(chapters []models.Chapter, position interfaces.AudioTick) int {
+	index := chapterAt(chapters, position)
+	if index < 0 {
+		if len(chapters) > 0 {
+			return 0
+		}
+		return -1
+	}
+	if index > 0 && position-interfaces.AudioTick(chapters[index].Start) < chapterRestartTicks {
+		index -= 1
+	}
+	return index
+}
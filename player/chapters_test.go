@@ -0,0 +1,62 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"testing"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestChapterNavigation(t *testing.T) {
+	chapters := []models.Chapter{
+		{Name: "Intro", Start: 1000},
+		{Name: "First", Start: 60000},
+		{Name: "Second", Start: 120000},
+	}
+	tests := []struct {
+		name         string
+		position     interfaces.AudioTick
+		wantNext     int
+		wantPrevious int
+	}{
+		{name: "before first chapter", position: 500, wantNext: 0, wantPrevious: 0},
+		{name: "first chapter", position: 30000, wantNext: 1, wantPrevious: 0},
+		{name: "chapter just started", position: 61000, wantNext: 2, wantPrevious: 0},
+		{name: "middle of chapter", position: 90000, wantNext: 2, wantPrevious: 1},
+		{name: "last chapter", position: 150000, wantNext: -1, wantPrevious: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextChapter(chapters, tt.position); got != tt.wantNext {
+				t.Errorf("nextChapter() = %d, want %d", got, tt.wantNext)
+			}
+			if got := previousChapter(chapters, tt.position); got != tt.wantPrevious {
+				t.Errorf("previousChapter() = %d, want %d", got, tt.wantPrevious)
+			}
+		})
+	}
+
+	if got := nextChapter(nil, 0); got != -1 {
+		t.Errorf("nextChapter() without chapters = %d, want -1", got)
+	}
+	if got := previousChapter(nil, 0); got != -1 {
+		t.Errorf("previousChapter() without chapters = %d, want -1", got)
+	}
+}
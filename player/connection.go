@@ -0,0 +1,36 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+// AddConnectionCallback implements interfaces.ConnectionNotifier. If server does not report
+// connection state, callback is never called.
+func (p *Player) AddConnectionCallback(cb func(online bool)) {
+	if p.connection == nil {
+		return
+	}
+	p.connection.AddConnectionCallback(cb)
+}
+
+// connectionChanged keeps track of connection to server. Progress reports fail while server is offline,
+// which is not shown to user, as plays are still recorded and scrobbled once server is back online.
+func (p *Player) connectionChanged(online bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.offline = !online
+}
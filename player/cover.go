@@ -0,0 +1,287 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/metrics"
+	"tryffel.net/go/jellycli/models"
+)
+
+const coverDownloadTimeout = time.Second * 5
+
+// coverIndexFile contains cached covers and albums using them.
+const coverIndexFile = "index.json"
+
+// errNoCover occurs when album has no cached cover and cover cannot be downloaded.
+var errNoCover = errors.New("no cover")
+
+// coverEntry is image file stored in cover cache.
+type coverEntry struct {
+	File     string    `json:"file"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+	// Color is dominant color of image as '#rrggbb', once it has been computed.
+	Color string `json:"color,omitempty"`
+}
+
+// coverIndex is stored to coverIndexFile.
+type coverIndex struct {
+	Albums map[models.Id]string `json:"albums"`
+	Covers []*coverEntry        `json:"covers"`
+}
+
+// covers caches album covers in local directory. Files are named by hash of their content,
+// so albums that share a cover share the file too. When cache exceeds quota,
+// least recently used covers are removed.
+type covers struct {
+	lock  *sync.Mutex
+	dir   string
+	quota int64
+
+	// albums maps album, or artist, to its cover file.
+	albums map[models.Id]string
+	files  map[string]*coverEntry
+	// noColor contains items whose image could not be downloaded or decoded during this session.
+	noColor map[models.Id]bool
+}
+
+// newCovers creates cover cache in dir. Quota is max cache size in bytes.
+func newCovers(dir string, quota int64) *covers {
+	c := &covers{
+		lock:    &sync.Mutex{},
+		dir:     dir,
+		quota:   quota,
+		albums:  map[models.Id]string{},
+		files:   map[string]*coverEntry{},
+		noColor: map[models.Id]bool{},
+	}
+	err := c.load()
+	if err != nil {
+		logrus.Errorf("load cover cache: %v", err)
+	}
+	return c
+}
+
+func (c *covers) indexFile() string {
+	return path.Join(c.dir, coverIndexFile)
+}
+
+// load reads index of cached covers. Covers whose file has been removed are skipped.
+func (c *covers) load() error {
+	data, err := ioutil.ReadFile(c.indexFile())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	index := coverIndex{}
+	err = json.Unmarshal(data, &index)
+	if err != nil {
+		return fmt.Errorf("parse index: %v", err)
+	}
+	for _, v := range index.Covers {
+		if _, err := os.Stat(path.Join(c.dir, v.File)); err != nil {
+			continue
+		}
+		c.files[v.File] = v
+	}
+	for album, file := range index.Albums {
+		if _, ok := c.files[file]; ok {
+			c.albums[album] = file
+		}
+	}
+	return nil
+}
+
+// save writes index of cached covers. Lock must be held.
+func (c *covers) save() error {
+	index := coverIndex{Albums: c.albums, Covers: c.sortedEntries()}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode index: %v", err)
+	}
+	err = os.MkdirAll(c.dir, 0760)
+	if err != nil {
+		return fmt.Errorf("create cover directory: %v", err)
+	}
+	tmp := c.indexFile() + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0660)
+	if err != nil {
+		return fmt.Errorf("write index: %v", err)
+	}
+	return os.Rename(tmp, c.indexFile())
+}
+
+// sortedEntries returns cached covers, least recently used first. Lock must be held.
+func (c *covers) sortedEntries() []*coverEntry {
+	entries := make([]*coverEntry, 0, len(c.files))
+	for _, v := range c.files {
+		entries = append(entries, v)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+	return entries
+}
+
+// get returns path to cover of album. If cover is not cached, it is downloaded from imageUrl.
+func (c *covers) get(album models.Id, imageUrl string) (string, error) {
+	c.lock.Lock()
+	file, ok := c.albums[album]
+	metrics.CoverCache.Lookup(ok)
+	if ok {
+		c.files[file].LastUsed = time.Now()
+		err := c.save()
+		c.lock.Unlock()
+		if err != nil {
+			logrus.Warningf("save cover cache: %v", err)
+		}
+		return path.Join(c.dir, file), nil
+	}
+	c.lock.Unlock()
+
+	if imageUrl == "" {
+		return "", errNoCover
+	}
+	file, size, err := c.download(imageUrl)
+	if err != nil {
+		return "", err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.albums[album] = file
+	c.files[file] = &coverEntry{File: file, Size: size, LastUsed: time.Now()}
+	c.evict()
+	err = c.save()
+	if err != nil {
+		logrus.Warningf("save cover cache: %v", err)
+	}
+	return path.Join(c.dir, file), nil
+}
+
+// download downloads image and stores it with name from hash of its content.
+func (c *covers) download(imageUrl string) (string, int64, error) {
+	err := os.MkdirAll(c.dir, 0760)
+	if err != nil {
+		return "", 0, fmt.Errorf("create cover directory: %v", err)
+	}
+
+	client, err := api.NewHttpClient(coverDownloadTimeout)
+	if err != nil {
+		return "", 0, err
+	}
+	resp, err := client.Get(imageUrl)
+	if err != nil {
+		return "", 0, fmt.Errorf("download cover: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("download cover: http %d", resp.StatusCode)
+	}
+
+	// write to temporary file first so that partial downloads are never used
+	fd, err := ioutil.TempFile(c.dir, "cover-*.tmp")
+	if err != nil {
+		return "", 0, fmt.Errorf("create cover file: %v", err)
+	}
+	tmp := fd.Name()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(fd, hash), resp.Body)
+	closeErr := fd.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", 0, fmt.Errorf("write cover file: %v", err)
+	}
+
+	file := hex.EncodeToString(hash.Sum(nil)) + coverExtension(resp.Header.Get("Content-Type"))
+	err = os.Rename(tmp, path.Join(c.dir, file))
+	if err != nil {
+		os.Remove(tmp)
+		return "", 0, fmt.Errorf("rename cover file: %v", err)
+	}
+	return file, size, nil
+}
+
+// evict removes least recently used covers until cache fits in quota. Latest cover is always kept.
+// Lock must be held.
+func (c *covers) evict() {
+	var total int64
+	for _, v := range c.files {
+		total += v.Size
+	}
+	entries := c.sortedEntries()
+	for i := 0; total > c.quota && i < len(entries)-1; i++ {
+		entry := entries[i]
+		err := os.Remove(path.Join(c.dir, entry.File))
+		if err != nil && !os.IsNotExist(err) {
+			logrus.Warningf("remove cover: %v", err)
+		}
+		delete(c.files, entry.File)
+		total -= entry.Size
+		for album, file := range c.albums {
+			if file == entry.File {
+				delete(c.albums, album)
+			}
+		}
+	}
+}
+
+func coverExtension(contentType string) string {
+	switch contentType {
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	case "image/gif":
+		return ".gif"
+	default:
+		return ".jpg"
+	}
+}
+
+// fileUrl returns file url for local file.
+func fileUrl(file string) string {
+	return (&url.URL{Scheme: "file", Path: file}).String()
+}
+
+// GetAlbumCover implements interfaces.CoverProvider.
+func (p *Player) GetAlbumCover(album models.Id) (string, error) {
+	return p.covers.get(album, p.api.GetImageUrl(album, models.TypeAlbum))
+}
@@ -0,0 +1,93 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// coverServer serves image of given size. Image content is the path.
+func coverServer(size int, requests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte(strings.Repeat(r.URL.Path, size/len(r.URL.Path))))
+	}))
+}
+
+func TestCovers(t *testing.T) {
+	var requests int32
+	server := coverServer(1000, &requests)
+	defer server.Close()
+
+	dir := path.Join(t.TempDir(), "covers")
+	c := newCovers(dir, 2500)
+
+	first, err := c.get("album-1", server.URL+"/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path.Ext(first) != ".png" {
+		t.Errorf("cover file %s, want .png", first)
+	}
+	if _, err := c.get("album-1", server.URL+"/a"); err != nil || requests != 1 {
+		t.Errorf("cached cover downloaded again, %d requests, err: %v", requests, err)
+	}
+
+	// same image is stored once
+	shared, err := c.get("album-2", server.URL+"/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared != first || len(c.files) != 1 {
+		t.Errorf("identical covers stored to %s and %s", first, shared)
+	}
+
+	// offline, cached cover is still found
+	loaded := newCovers(dir, 2500)
+	if got, err := loaded.get("album-2", ""); err != nil || got != first {
+		t.Errorf("loaded cover: %s, err: %v", got, err)
+	}
+	if _, err := loaded.get("album-3", ""); err != errNoCover {
+		t.Errorf("missing cover, got err %v, want %v", err, errNoCover)
+	}
+
+	// quota fits two covers, least recently used is removed
+	if _, err = c.get("album-3", server.URL+"/b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.get("album-4", server.URL+"/c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.albums["album-1"]; ok {
+		t.Errorf("least recently used cover not removed")
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("removed cover file exists: %v", err)
+	}
+	if len(c.files) != 2 || len(c.albums) != 2 {
+		t.Errorf("got %d files for %d albums, want 2 and 2", len(c.files), len(c.albums))
+	}
+}
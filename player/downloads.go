@@ -0,0 +1,446 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// downloadIndexFile contains metadata of downloaded songs.
+const downloadIndexFile = "index.json"
+
+// downloadNotifyInterval limits how often download progress is notified.
+const downloadNotifyInterval = time.Millisecond * 500
+
+// downloadEntry is song stored in download cache.
+type downloadEntry struct {
+	Song     *models.Song           `json:"song"`
+	File     string                 `json:"file"`
+	Format   interfaces.AudioFormat `json:"format"`
+	Size     int64                  `json:"size"`
+	LastUsed time.Time              `json:"last_used"`
+}
+
+// downloads saves songs to local directory for offline playback. When cache exceeds quota,
+// least recently used songs are removed.
+type downloads struct {
+	lock   *sync.Mutex
+	dir    string
+	quota  int64
+	server api.Streamer
+
+	entries map[models.Id]*downloadEntry
+	// pending contains queued, running and failed downloads.
+	pending []*models.Download
+	wake    chan bool
+
+	callbacks    []func()
+	lastNotified time.Time
+}
+
+// newDownloads creates download cache in dir and starts downloading queued songs in background.
+// Quota is max cache size in bytes.
+func newDownloads(dir string, quota int64, server api.Streamer) *downloads {
+	d := &downloads{
+		lock:    &sync.Mutex{},
+		dir:     dir,
+		quota:   quota,
+		server:  server,
+		entries: map[models.Id]*downloadEntry{},
+		wake:    make(chan bool, 1),
+	}
+	err := d.load()
+	if err != nil {
+		logrus.Errorf("load downloaded songs: %v", err)
+	}
+	go d.loop()
+	return d
+}
+
+func (d *downloads) indexFile() string {
+	return path.Join(d.dir, downloadIndexFile)
+}
+
+// load reads index of downloaded songs. Songs whose file has been removed are skipped.
+func (d *downloads) load() error {
+	data, err := ioutil.ReadFile(d.indexFile())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var entries []*downloadEntry
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return fmt.Errorf("parse index: %v", err)
+	}
+	for _, v := range entries {
+		if v.Song == nil {
+			continue
+		}
+		if _, err := os.Stat(path.Join(d.dir, v.File)); err != nil {
+			logrus.Warningf("downloaded song %s not found, skip", v.Song.Id)
+			continue
+		}
+		d.entries[v.Song.Id] = v
+	}
+	return nil
+}
+
+// save writes index of downloaded songs. Lock must be held.
+func (d *downloads) save() error {
+	entries := d.sortedEntries()
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode index: %v", err)
+	}
+	err = os.MkdirAll(d.dir, 0760)
+	if err != nil {
+		return fmt.Errorf("create download directory: %v", err)
+	}
+	tmp := d.indexFile() + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0660)
+	if err != nil {
+		return fmt.Errorf("write index: %v", err)
+	}
+	return os.Rename(tmp, d.indexFile())
+}
+
+// sortedEntries returns downloaded songs, least recently used first. Lock must be held.
+func (d *downloads) sortedEntries() []*downloadEntry {
+	entries := make([]*downloadEntry, 0, len(d.entries))
+	for _, v := range d.entries {
+		entries = append(entries, v)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+	return entries
+}
+
+// open returns downloaded song. If song has not been downloaded, ok is false.
+func (d *downloads) open(song models.Id) (rc io.ReadCloser, format interfaces.AudioFormat, ok bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	entry, found := d.entries[song]
+	if !found {
+		return nil, interfaces.AudioFormatNil, false
+	}
+	fd, err := os.Open(path.Join(d.dir, entry.File))
+	if err != nil {
+		logrus.Warningf("open downloaded song: %v", err)
+		return nil, interfaces.AudioFormatNil, false
+	}
+	entry.LastUsed = time.Now()
+	err = d.save()
+	if err != nil {
+		logrus.Warningf("save downloaded songs: %v", err)
+	}
+	return fd, entry.Format, true
+}
+
+// file returns path to downloaded song, or empty string if song has not been downloaded.
+func (d *downloads) file(song models.Id) string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	entry, found := d.entries[song]
+	if !found {
+		return ""
+	}
+	return path.Join(d.dir, entry.File)
+}
+
+// add adds songs to download queue. Downloaded and queued songs are skipped, failed downloads are retried.
+func (d *downloads) add(songs []*models.Song) {
+	d.lock.Lock()
+	for _, v := range songs {
+		if _, ok := d.entries[v.Id]; ok {
+			continue
+		}
+		if download := d.findPending(v.Id); download != nil {
+			if download.State == models.DownloadFailed {
+				download.State = models.DownloadQueued
+				download.Downloaded = 0
+				download.Error = ""
+			}
+			continue
+		}
+		d.pending = append(d.pending, &models.Download{Song: v, State: models.DownloadQueued, Size: v.Size})
+	}
+	d.lock.Unlock()
+	d.notify(true)
+
+	select {
+	case d.wake <- true:
+	default:
+	}
+}
+
+// findPending returns pending download for song, or nil. Lock must be held.
+func (d *downloads) findPending(song models.Id) *models.Download {
+	for _, v := range d.pending {
+		if v.Song.Id == song {
+			return v
+		}
+	}
+	return nil
+}
+
+// next marks next queued download running and returns it, or nil if queue is empty.
+func (d *downloads) next() *models.Download {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, v := range d.pending {
+		if v.State == models.DownloadQueued {
+			v.State = models.DownloadRunning
+			return v
+		}
+	}
+	return nil
+}
+
+func (d *downloads) loop() {
+	for range d.wake {
+		for download := d.next(); download != nil; download = d.next() {
+			err := d.download(download)
+			d.lock.Lock()
+			if err != nil {
+				logrus.Errorf("download song %s: %v", download.Song.Id, err)
+				download.State = models.DownloadFailed
+				download.Error = err.Error()
+			} else {
+				d.removePending(download.Song.Id)
+			}
+			d.lock.Unlock()
+			d.notify(true)
+		}
+	}
+}
+
+// removePending removes song from pending downloads. Lock must be held.
+func (d *downloads) removePending(song models.Id) {
+	for i, v := range d.pending {
+		if v.Song.Id == song {
+			d.pending = append(d.pending[:i], d.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// download downloads song to cache. If original file cannot be played, transcoded stream is saved instead.
+func (d *downloads) download(download *models.Download) error {
+	reader, format, err := d.server.Download(download.Song)
+	if err == nil && !isSupportedFormat(format) {
+		reader.Close()
+		reader, format, err = d.server.Stream(download.Song)
+	}
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	err = os.MkdirAll(d.dir, 0760)
+	if err != nil {
+		return fmt.Errorf("create download directory: %v", err)
+	}
+	file := url.PathEscape(download.Song.Id.String()) + "." + format.String()
+	// write to temporary file first so that partial downloads are never played
+	tmp := path.Join(d.dir, file+".tmp")
+	fd, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create file: %v", err)
+	}
+	size, err := io.Copy(fd, &progressReader{reader: reader, download: download, downloads: d})
+	closeErr := fd.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write file: %v", err)
+	}
+	err = os.Rename(tmp, path.Join(d.dir, file))
+	if err != nil {
+		return fmt.Errorf("rename file: %v", err)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.entries[download.Song.Id] = &downloadEntry{
+		Song:     download.Song,
+		File:     file,
+		Format:   format,
+		Size:     size,
+		LastUsed: time.Now(),
+	}
+	d.evict()
+	return d.save()
+}
+
+// evict removes least recently used songs until cache fits in quota. Latest song is always kept.
+// Lock must be held.
+func (d *downloads) evict() {
+	var total int64
+	for _, v := range d.entries {
+		total += v.Size
+	}
+	entries := d.sortedEntries()
+	for i := 0; total > d.quota && i < len(entries)-1; i++ {
+		entry := entries[i]
+		err := os.Remove(path.Join(d.dir, entry.File))
+		if err != nil && !os.IsNotExist(err) {
+			logrus.Warningf("remove downloaded song: %v", err)
+		}
+		delete(d.entries, entry.Song.Id)
+		total -= entry.Size
+		logrus.Infof("download quota exceeded, removed song %s", entry.Song.Name)
+	}
+}
+
+// remove removes downloaded song or cancels queued download.
+func (d *downloads) remove(song models.Id) error {
+	d.lock.Lock()
+	if download := d.findPending(song); download != nil {
+		if download.State == models.DownloadRunning {
+			d.lock.Unlock()
+			return errors.New("song is being downloaded")
+		}
+		d.removePending(song)
+		d.lock.Unlock()
+		d.notify(true)
+		return nil
+	}
+	entry, ok := d.entries[song]
+	if !ok {
+		d.lock.Unlock()
+		return nil
+	}
+	err := os.Remove(path.Join(d.dir, entry.File))
+	if err != nil && !os.IsNotExist(err) {
+		d.lock.Unlock()
+		return fmt.Errorf("remove file: %v", err)
+	}
+	delete(d.entries, song)
+	err = d.save()
+	d.lock.Unlock()
+	d.notify(true)
+	return err
+}
+
+// list returns pending downloads followed by downloaded songs, most recently used first.
+func (d *downloads) list() []*models.Download {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	downloads := make([]*models.Download, 0, len(d.pending)+len(d.entries))
+	for _, v := range d.pending {
+		download := *v
+		downloads = append(downloads, &download)
+	}
+	entries := d.sortedEntries()
+	for i := len(entries) - 1; i >= 0; i-- {
+		downloads = append(downloads, &models.Download{
+			Song:       entries[i].Song,
+			State:      models.DownloadDone,
+			Size:       entries[i].Size,
+			Downloaded: entries[i].Size,
+		})
+	}
+	return downloads
+}
+
+func (d *downloads) addCallback(cb func()) {
+	d.lock.Lock()
+	d.callbacks = append(d.callbacks, cb)
+	d.lock.Unlock()
+}
+
+// notify calls callbacks. Unless forced, callbacks are called at most once in downloadNotifyInterval.
+func (d *downloads) notify(force bool) {
+	d.lock.Lock()
+	if !force && time.Since(d.lastNotified) < downloadNotifyInterval {
+		d.lock.Unlock()
+		return
+	}
+	d.lastNotified = time.Now()
+	callbacks := d.callbacks
+	d.lock.Unlock()
+	for _, cb := range callbacks {
+		cb()
+	}
+}
+
+// progressReader updates download progress while reading.
+type progressReader struct {
+	reader    io.Reader
+	download  *models.Download
+	downloads *downloads
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	p.downloads.lock.Lock()
+	p.download.Downloaded += int64(n)
+	p.downloads.lock.Unlock()
+	p.downloads.notify(false)
+	return n, err
+}
+
+func isSupportedFormat(format interfaces.AudioFormat) bool {
+	for _, v := range interfaces.SupportedAudioFormats {
+		if v == format {
+			return true
+		}
+	}
+	return false
+}
+
+// DownloadSongs implements interfaces.DownloadController.
+func (p *Player) DownloadSongs(songs []*models.Song) {
+	p.downloads.add(songs)
+}
+
+// GetDownloads implements interfaces.DownloadController.
+func (p *Player) GetDownloads() []*models.Download {
+	return p.downloads.list()
+}
+
+// RemoveDownload implements interfaces.DownloadController.
+func (p *Player) RemoveDownload(song models.Id) error {
+	return p.downloads.remove(song)
+}
+
+// AddDownloadCallback implements interfaces.DownloadController.
+func (p *Player) AddDownloadCallback(cb func()) {
+	p.downloads.addCallback(cb)
+}
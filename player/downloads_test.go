@@ -0,0 +1,126 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// fileServer serves same content of given size for every song.
+type fileServer struct {
+	api.Streamer
+	size   int
+	format interfaces.AudioFormat
+}
+
+func (f *fileServer) Download(song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
+	return ioutil.NopCloser(bytes.NewReader(make([]byte, f.size))), f.format, nil
+}
+
+func (f *fileServer) Stream(song *models.Song) (io.ReadCloser, interfaces.AudioFormat, error) {
+	return ioutil.NopCloser(bytes.NewReader(make([]byte, f.size/2))), interfaces.AudioFormatMp3, nil
+}
+
+// waitDownloads waits until there are no pending downloads.
+func waitDownloads(t *testing.T, d *downloads) {
+	deadline := time.Now().Add(time.Second * 5)
+	for time.Now().Before(deadline) {
+		d.lock.Lock()
+		pending := len(d.pending)
+		d.lock.Unlock()
+		if pending == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Fatalf("downloads did not complete")
+}
+
+func TestDownloads(t *testing.T) {
+	dir := path.Join(t.TempDir(), "songs")
+	server := &fileServer{size: 1000, format: interfaces.AudioFormatFlac}
+	d := newDownloads(dir, 2500, server)
+
+	songs := []*models.Song{{Id: "a", Name: "a"}, {Id: "b", Name: "b"}}
+	d.add(songs)
+	waitDownloads(t, d)
+
+	rc, format, ok := d.open("a")
+	if !ok {
+		t.Fatalf("song a not downloaded")
+	}
+	rc.Close()
+	if format != interfaces.AudioFormatFlac {
+		t.Errorf("format: got %s, want %s", format, interfaces.AudioFormatFlac)
+	}
+
+	// song b is least recently used and is removed
+	d.add([]*models.Song{{Id: "c", Name: "c"}})
+	waitDownloads(t, d)
+	if _, _, ok := d.open("b"); ok {
+		t.Errorf("song b not evicted")
+	}
+	if _, err := os.Stat(path.Join(dir, "b.flac")); !os.IsNotExist(err) {
+		t.Errorf("song b file not removed: %v", err)
+	}
+
+	downloads := d.list()
+	if len(downloads) != 2 || downloads[0].Song.Id != "c" || downloads[1].Song.Id != "a" {
+		t.Errorf("list: got %v", downloads)
+	}
+
+	// index is persisted
+	d = newDownloads(dir, 2500, server)
+	if _, _, ok := d.open("c"); !ok {
+		t.Errorf("song c not loaded from index")
+	}
+
+	err := d.remove("c")
+	if err != nil {
+		t.Errorf("remove: %v", err)
+	}
+	if _, _, ok := d.open("c"); ok {
+		t.Errorf("song c not removed")
+	}
+}
+
+func TestDownloads_unsupportedFormat(t *testing.T) {
+	server := &fileServer{size: 1000, format: interfaces.AudioFormat("m4a")}
+	d := newDownloads(t.TempDir(), 2500, server)
+	d.add([]*models.Song{{Id: "a", Name: "a"}})
+	waitDownloads(t, d)
+
+	rc, format, ok := d.open("a")
+	if !ok {
+		t.Fatalf("song a not downloaded")
+	}
+	rc.Close()
+	if format != interfaces.AudioFormatMp3 {
+		t.Errorf("format: got %s, want stream format %s", format, interfaces.AudioFormatMp3)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// duplicateToleranceS is how many seconds durations of duplicate songs may differ.
+const duplicateToleranceS = 3
+
+// duplicatePageSize is how many songs are requested at once when searching duplicates.
+const duplicatePageSize = 500
+
+// GetDuplicateSongs goes through all songs in library and returns groups of probable duplicates.
+func (i *Items) GetDuplicateSongs(ctx context.Context) ([][]*models.Song, error) {
+	songs := []*models.Song{}
+	query := interfaces.DefaultQueryOpts()
+	query.Paging.PageSize = duplicatePageSize
+	for {
+		page, total, err := i.GetSongs(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("get songs: %v", err)
+		}
+		songs = append(songs, page...)
+		query.Paging.CurrentPage += 1
+		if len(page) < query.Paging.PageSize || len(songs) >= total {
+			break
+		}
+	}
+	return findDuplicates(songs, duplicateToleranceS), nil
+}
+
+// duplicateKey returns key that duplicate songs share: artist and title ignoring case and punctuation.
+// Songs without artist use album artist id. If song has neither, it returns empty key.
+func duplicateKey(song *models.Song) string {
+	artist := ""
+	if len(song.Artists) > 0 {
+		artist = normalizeName(song.Artists[0].Name)
+	} else if song.AlbumArtist != "" {
+		artist = "id:" + song.AlbumArtist.String()
+	}
+	title := normalizeName(song.Name)
+	if artist == "" || title == "" {
+		return ""
+	}
+	return artist + "\x00" + title
+}
+
+// findDuplicates groups songs that have same artist and title, and duration within tolerance seconds
+// of another song in group. Groups contain at least two songs, shortest song first, and are sorted by
+// artist and title.
+func findDuplicates(songs []*models.Song, tolerance int) [][]*models.Song {
+	byKey := map[string][]*models.Song{}
+	seen := map[models.Id]bool{}
+	for _, v := range songs {
+		key := duplicateKey(v)
+		if key == "" || seen[v.Id] {
+			continue
+		}
+		seen[v.Id] = true
+		byKey[key] = append(byKey[key], v)
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key, v := range byKey {
+		if len(v) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	groups := [][]*models.Song{}
+	for _, key := range keys {
+		candidates := byKey[key]
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Duration < candidates[j].Duration
+		})
+		start := 0
+		for i := 1; i <= len(candidates); i++ {
+			if i < len(candidates) && candidates[i].Duration-candidates[i-1].Duration <= tolerance {
+				continue
+			}
+			if i-start > 1 {
+				groups = append(groups, candidates[start:i])
+			}
+			start = i
+		}
+	}
+	return groups
+}
@@ -0,0 +1,69 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	artist := []models.IdName{{Id: "artist-1", Name: "Artist"}}
+	songs := []*models.Song{
+		{Id: "song-1", Name: "Song", Duration: 200, Artists: artist},
+		{Id: "song-2", Name: " song ", Duration: 202, Artists: []models.IdName{{Id: "artist-2", Name: "ARTIST"}}},
+		{Id: "song-3", Name: "Song", Duration: 260, Artists: artist},
+		{Id: "song-4", Name: "Other", Duration: 200, Artists: artist},
+		{Id: "song-1", Name: "Song", Duration: 200, Artists: artist},
+		{Id: "song-5", Name: "Other", Duration: 300, AlbumArtist: "artist-1"},
+		{Id: "song-6", Name: "Other", Duration: 301, AlbumArtist: "artist-1"},
+		{Id: "song-7", Name: "Song", Duration: 200},
+	}
+
+	got := findDuplicates(songs, 3)
+	want := [][]models.Id{{"song-1", "song-2"}, {"song-5", "song-6"}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(got), len(want))
+	}
+	for i, group := range got {
+		if len(group) != len(want[i]) {
+			t.Errorf("group %d: got %d songs, want %d", i, len(group), len(want[i]))
+			continue
+		}
+		for j, song := range group {
+			if song.Id != want[i][j] {
+				t.Errorf("group %d song %d: got %s, want %s", i, j, song.Id, want[i][j])
+			}
+		}
+	}
+}
+
+func TestFindDuplicates_Chain(t *testing.T) {
+	artist := []models.IdName{{Id: "artist-1", Name: "Artist"}}
+	songs := []*models.Song{
+		{Id: "song-1", Name: "Song", Duration: 100, Artists: artist},
+		{Id: "song-2", Name: "Song", Duration: 103, Artists: artist},
+		{Id: "song-3", Name: "Song", Duration: 106, Artists: artist},
+		{Id: "song-4", Name: "Song", Duration: 120, Artists: artist},
+	}
+	got := findDuplicates(songs, 3)
+	if len(got) != 1 || len(got[0]) != 3 {
+		t.Fatalf("expected one group of three songs, got %v", got)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package player
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/m3u"
+	"tryffel.net/go/jellycli/models"
+)
+
+// ExportPlaylist implements interfaces.PlaylistExporter.
+func (p *Player) ExportPlaylist(name string, songs []*models.Song, file string, preferLocal bool) (int, error) {
+	streamUrl := func(song *models.Song) string { return "" }
+	if linker, ok := p.api.(api.StreamLinker); ok {
+		streamUrl = linker.GetStreamUrl
+	}
+	localFile := func(song *models.Song) string {
+		downloaded := p.downloads.file(song.Id)
+		if downloaded == "" {
+			return ""
+		}
+		// other players may have different working directory
+		abs, err := filepath.Abs(downloaded)
+		if err != nil {
+			return downloaded
+		}
+		return abs
+	}
+
+	entries := playlistEntries(songs, streamUrl, localFile, preferLocal)
+	if len(entries) == 0 {
+		return 0, errors.New("songs have no stream urls or downloaded files")
+	}
+	return len(entries), m3u.WriteFile(file, name, entries)
+}
+
+// playlistEntries returns playlist entries for songs. Location is stream url, or local file if preferLocal.
+// If preferred location is not available, the other one is used. Songs without location are skipped.
+func playlistEntries(songs []*models.Song, streamUrl, localFile func(song *models.Song) string,
+	preferLocal bool) []m3u.Entry {
+	entries := make([]m3u.Entry, 0, len(songs))
+	for _, song := range songs {
+		first, second := streamUrl, localFile
+		if preferLocal {
+			first, second = localFile, streamUrl
+		}
+		location := first(song)
+		if location == "" {
+			location = second(song)
+		}
+		if location == "" {
+			continue
+		}
+
+		title := song.Name
+		if len(song.Artists) > 0 {
+			artists := make([]string, len(song.Artists))
+			for i, v := range song.Artists {
+				artists[i] = v.Name
+			}
+			title = strings.Join(artists, ", ") + " - " + song.Name
+		}
+		duration := song.Duration
+		if duration <= 0 {
+			duration = -1
+		}
+		entries = append(entries, m3u.Entry{Location: location, Title: title, Duration: duration})
+	}
+	return entries
+}
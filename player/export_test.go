@@ -0,0 +1,66 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package player
+
+import (
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/m3u"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_playlistEntries(t *testing.T) {
+	songs := []*models.Song{
+		{Id: "a", Name: "first", Duration: 100, Artists: []models.IdName{{Name: "x"}, {Name: "y"}}},
+		{Id: "b", Name: "second"},
+		{Id: "c", Name: "third", Duration: 30},
+	}
+	streamUrl := func(song *models.Song) string {
+		if song.Id == "c" {
+			return ""
+		}
+		return "http://server/" + song.Id.String()
+	}
+	localFile := func(song *models.Song) string {
+		if song.Id == "b" {
+			return ""
+		}
+		return "/cache/" + song.Id.String() + ".mp3"
+	}
+
+	got := playlistEntries(songs, streamUrl, localFile, false)
+	want := []m3u.Entry{
+		{Location: "http://server/a", Title: "x, y - first", Duration: 100},
+		{Location: "http://server/b", Title: "second", Duration: -1},
+		{Location: "/cache/c.mp3", Title: "third", Duration: 30},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("stream urls: got %v, want %v", got, want)
+	}
+
+	got = playlistEntries(songs, streamUrl, localFile, true)
+	want[0].Location = "/cache/a.mp3"
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("local files: got %v, want %v", got, want)
+	}
+
+	none := func(song *models.Song) string { return "" }
+	if got := playlistEntries(songs, none, none, true); len(got) != 0 {
+		t.Errorf("no locations: got %v, want none", got)
+	}
+}
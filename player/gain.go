@@ -0,0 +1,173 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"sync"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/models"
+)
+
+// gainFile contains volume offsets of songs and albums.
+const gainFile = "gains.json"
+
+// gains stores volume offsets in decibels for songs and albums that are mastered louder or quieter
+// than rest of the library. Offset of song overrides offset of its album.
+type gains struct {
+	lock    *sync.Mutex
+	file    string
+	offsets map[models.Id]int
+}
+
+// newGains loads offsets from file.
+func newGains(file string) *gains {
+	g := &gains{
+		lock:    &sync.Mutex{},
+		file:    file,
+		offsets: map[models.Id]int{},
+	}
+	err := g.load()
+	if err != nil {
+		logrus.Errorf("load volume offsets: %v", err)
+	}
+	return g
+}
+
+func (g *gains) load() error {
+	data, err := ioutil.ReadFile(g.file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	offsets := map[models.Id]int{}
+	err = json.Unmarshal(data, &offsets)
+	if err != nil {
+		return fmt.Errorf("parse %s: %v", g.file, err)
+	}
+	for id, offset := range offsets {
+		if offset != 0 && validGainOffset(offset) {
+			g.offsets[id] = offset
+		}
+	}
+	return nil
+}
+
+// save writes offsets to file. Lock must be held.
+func (g *gains) save() error {
+	data, err := json.MarshalIndent(g.offsets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode volume offsets: %v", err)
+	}
+	err = os.MkdirAll(path.Dir(g.file), 0760)
+	if err != nil {
+		return fmt.Errorf("create directory: %v", err)
+	}
+	tmp := g.file + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0660)
+	if err != nil {
+		return fmt.Errorf("write volume offsets: %v", err)
+	}
+	return os.Rename(tmp, g.file)
+}
+
+// get returns offset of item, or 0 if there is none.
+func (g *gains) get(id models.Id) int {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.offsets[id]
+}
+
+// set stores offset of item. Zero removes offset.
+func (g *gains) set(id models.Id, offset int) error {
+	if !validGainOffset(offset) {
+		return fmt.Errorf("volume offset must be between -%d and %d dB",
+			config.AudioMaxGainOffsetdB, config.AudioMaxGainOffsetdB)
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.offsets[id] == offset {
+		return nil
+	}
+	if offset == 0 {
+		delete(g.offsets, id)
+	} else {
+		g.offsets[id] = offset
+	}
+	return g.save()
+}
+
+// songOffset returns offset of song, or its album if song has none.
+func (g *gains) songOffset(song *models.Song) int {
+	if song == nil {
+		return 0
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if offset, ok := g.offsets[song.Id]; ok {
+		return offset
+	}
+	return g.offsets[song.Album]
+}
+
+func validGainOffset(offset int) bool {
+	return offset >= -config.AudioMaxGainOffsetdB && offset <= config.AudioMaxGainOffsetdB
+}
+
+// gainToVolume converts offset in decibels to volume effect units, which are exponents of
+// config.AudioVolumeLogBase.
+func gainToVolume(offset int) float64 {
+	return float64(offset) / (20 * math.Log10(config.AudioVolumeLogBase))
+}
+
+// GetGainOffset returns volume offset in decibels of song or album.
+func (p *Player) GetGainOffset(item models.Item) int {
+	if item == nil {
+		return 0
+	}
+	return p.gains.get(item.GetId())
+}
+
+// SetGainOffset sets volume offset in decibels for song or album and saves it. Zero removes offset.
+// If playing song is affected, new offset is applied immediately.
+func (p *Player) SetGainOffset(item models.Item, offset int) error {
+	if item == nil {
+		return fmt.Errorf("empty item")
+	}
+	if item.GetType() != models.TypeSong && item.GetType() != models.TypeAlbum {
+		return fmt.Errorf("volume offset can only be set for songs and albums")
+	}
+	err := p.gains.set(item.GetId(), offset)
+	if err != nil {
+		return err
+	}
+	song := p.Audio.getStatus().Song
+	if song != nil && (song.Id == item.GetId() || song.Album == item.GetId()) {
+		p.Audio.setGain(p.gains.songOffset(song))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"math"
+	"path"
+	"testing"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestGains(t *testing.T) {
+	file := path.Join(t.TempDir(), "gains.json")
+	g := newGains(file)
+
+	if err := g.set("album", -4); err != nil {
+		t.Fatalf("set album offset: %v", err)
+	}
+	if err := g.set("loud", -8); err != nil {
+		t.Fatalf("set song offset: %v", err)
+	}
+	if err := g.set("song", 20); err == nil {
+		t.Errorf("offset out of range accepted")
+	}
+
+	tests := []struct {
+		name string
+		song *models.Song
+		want int
+	}{
+		{name: "song offset", song: &models.Song{Id: "loud", Album: "album"}, want: -8},
+		{name: "album offset", song: &models.Song{Id: "song", Album: "album"}, want: -4},
+		{name: "no offset", song: &models.Song{Id: "song", Album: "other"}, want: 0},
+		{name: "nil song", song: nil, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.songOffset(tt.song); got != tt.want {
+				t.Errorf("songOffset() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// offsets are persisted and zero removes offset
+	if err := g.set("album", 0); err != nil {
+		t.Fatalf("remove album offset: %v", err)
+	}
+	g = newGains(file)
+	if got := g.get("loud"); got != -8 {
+		t.Errorf("loaded song offset = %d, want -8", got)
+	}
+	if got := g.get("album"); got != 0 {
+		t.Errorf("removed album offset = %d, want 0", got)
+	}
+}
+
+func TestAudio_applyGain(t *testing.T) {
+	a := newAudio()
+	a.SetVolume(interfaces.AudioVolume(50))
+
+	a.setGain(-6)
+	want := -3 + gainToVolume(-6)
+	if math.Abs(a.volume.Volume-want) > 1e-9 {
+		t.Errorf("volume with offset: got %f, want %f", a.volume.Volume, want)
+	}
+	if math.Abs(gainToVolume(-6)+1) > 0.01 {
+		t.Errorf("-6 dB should halve amplitude, got exponent %f", gainToVolume(-6))
+	}
+
+	// volume changes keep offset
+	a.SetVolume(interfaces.AudioVolume(100))
+	if math.Abs(a.volume.Volume-gainToVolume(-6)) > 1e-9 {
+		t.Errorf("volume after change: got %f, want %f", a.volume.Volume, gainToVolume(-6))
+	}
+
+	a.setGain(0)
+	if a.volume.Volume != 0 {
+		t.Errorf("volume without offset: got %f, want 0", a.volume.Volume)
+	}
+}
@@ -0,0 +1,239 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/m3u"
+	"tryffel.net/go/jellycli/models"
+	"unicode"
+)
+
+// importSearchLimit limits search results when matching a song in imported playlist.
+const importSearchLimit = 20
+
+// trackNumberRe matches track number in the beginning of file name, e.g. '01 - ', '1. '.
+var trackNumberRe = regexp.MustCompile(`^\d+[\s.\-_]+`)
+
+// ImportPlaylist implements interfaces.PlaylistImporter.
+func (p *Player) ImportPlaylist(file string, name string) (*models.PlaylistImport, error) {
+	result, err := ImportM3U(p.api, file, name)
+	if err != nil {
+		return nil, err
+	}
+	if config.AppConfig.Player.EnableLocalCache {
+		if err := p.UpdatePlaylists(); err != nil {
+			logrus.Warningf("update local playlists: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// CopyPlaylist implements interfaces.PlaylistCopier.
+func (p *Player) CopyPlaylist(playlist *models.Playlist, name string) (*models.Playlist, error) {
+	copied, err := CopyPlaylist(p.api, playlist, name)
+	if err != nil {
+		return nil, err
+	}
+	if config.AppConfig.Player.EnableLocalCache {
+		if err := p.UpdatePlaylists(); err != nil {
+			logrus.Warningf("update local playlists: %v", err)
+		}
+	}
+	return copied, nil
+}
+
+// CopyPlaylist creates new playlist with songs of playlist. If name is empty, copy is named after playlist.
+func CopyPlaylist(server api.Browser, playlist *models.Playlist, name string) (*models.Playlist, error) {
+	creator, ok := server.(api.PlaylistCreator)
+	if !ok {
+		return nil, interfaces.ErrCopyNotSupported
+	}
+	if name == "" {
+		name = playlist.Name
+	}
+	songs := playlist.Songs
+	if songs == nil {
+		var err error
+		songs, err = server.GetPlaylistSongs(context.Background(), playlist.Id)
+		if err != nil {
+			return nil, fmt.Errorf("get playlist songs: %v", err)
+		}
+	}
+	if len(songs) == 0 {
+		return nil, errors.New("playlist has no songs")
+	}
+
+	copied := &models.Playlist{Name: name, Songs: songs, SongCount: len(songs)}
+	ids := make([]models.Id, len(songs))
+	for i, v := range songs {
+		ids[i] = v.Id
+		copied.Duration += v.Duration
+	}
+	var err error
+	copied.Id, err = creator.CreatePlaylist(name, ids)
+	if err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// ImportM3U matches songs in m3u file against server library by artist and title,
+// and creates playlist of matched songs. If name is empty, playlist is named after the file.
+func ImportM3U(server api.Browser, file string, name string) (*models.PlaylistImport, error) {
+	creator, ok := server.(api.PlaylistCreator)
+	if !ok {
+		return nil, interfaces.ErrImportNotSupported
+	}
+	fd, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	fileName, entries, err := m3u.Read(fd)
+	if err != nil {
+		return nil, fmt.Errorf("read playlist: %v", err)
+	}
+	if name == "" {
+		name = fileName
+	}
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+
+	result := &models.PlaylistImport{Playlist: &models.Playlist{Name: name}}
+	ids := make([]models.Id, 0, len(entries))
+	for _, entry := range entries {
+		song, err := matchSong(server, entry)
+		if err != nil {
+			return nil, fmt.Errorf("search songs: %v", err)
+		}
+		if song == nil {
+			label := entry.Title
+			if label == "" {
+				label = entry.Location
+			}
+			result.Unmatched = append(result.Unmatched, label)
+			continue
+		}
+		ids = append(ids, song.Id)
+		result.Playlist.Songs = append(result.Playlist.Songs, song)
+		result.Playlist.Duration += song.Duration
+	}
+	if len(ids) == 0 {
+		return result, errors.New("no songs found in library")
+	}
+	result.Playlist.SongCount = len(ids)
+	result.Playlist.Id, err = creator.CreatePlaylist(name, ids)
+	return result, err
+}
+
+// matchSong searches song for playlist entry. If song is not found, return nil.
+func matchSong(browser api.Browser, entry m3u.Entry) (*models.Song, error) {
+	artist, title := entryArtistTitle(entry)
+	if title == "" {
+		return nil, nil
+	}
+	song, err := findSong(browser, artist, title, entry.Duration)
+	if song != nil || err != nil || artist == "" {
+		return song, err
+	}
+	// title might contain ' - ' without artist
+	return findSong(browser, "", artist+" - "+title, entry.Duration)
+}
+
+// findSong returns song with title and artist, if any. If there are multiple songs, return one whose duration
+// is closest to duration.
+func findSong(browser api.Browser, artist, title string, duration int) (*models.Song, error) {
+	results, err := browser.Search(context.Background(), title, models.TypeSong, importSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+	var best *models.Song
+	for _, v := range results {
+		song, ok := v.(*models.Song)
+		if !ok || normalizeName(song.Name) != normalizeName(title) || !hasArtist(song, artist) {
+			continue
+		}
+		if best == nil || durationDiff(song, duration) < durationDiff(best, duration) {
+			best = song
+		}
+	}
+	return best, nil
+}
+
+// entryArtistTitle returns artist and title from entry title 'Artist - Title', or from file name.
+func entryArtistTitle(entry m3u.Entry) (string, string) {
+	text := entry.Title
+	if text == "" && !strings.Contains(entry.Location, "://") {
+		text = filepath.Base(filepath.FromSlash(entry.Location))
+		text = strings.TrimSuffix(text, filepath.Ext(text))
+		text = trackNumberRe.ReplaceAllString(text, "")
+	}
+	if i := strings.Index(text, " - "); i >= 0 {
+		return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+3:])
+	}
+	return "", strings.TrimSpace(text)
+}
+
+// hasArtist returns true if artist is empty or song has artist. Artist may also be e.g. 'Artist feat. Other'.
+func hasArtist(song *models.Song, artist string) bool {
+	if artist == "" {
+		return true
+	}
+	artist = normalizeName(artist)
+	for _, v := range song.Artists {
+		name := normalizeName(v.Name)
+		if name != "" && (strings.Contains(artist, name) || strings.Contains(name, artist)) {
+			return true
+		}
+	}
+	return false
+}
+
+func durationDiff(song *models.Song, duration int) int {
+	if duration < 0 {
+		return 0
+	}
+	diff := song.Duration - duration
+	if diff < 0 {
+		return -diff
+	}
+	return diff
+}
+
+// normalizeName lowercases name and removes punctuation.
+func normalizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, name)
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,137 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package player
+
+import (
+	"context"
+	"io/ioutil"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/m3u"
+	"tryffel.net/go/jellycli/models"
+)
+
+// libraryServer searches songs by name and records created playlists.
+type libraryServer struct {
+	api.Browser
+	songs []*models.Song
+
+	playlistName  string
+	playlistSongs []models.Id
+}
+
+func (l *libraryServer) Search(ctx context.Context, query string, itemType models.ItemType, maxResults int) ([]models.Item, error) {
+	items := []models.Item{}
+	for _, v := range l.songs {
+		if strings.Contains(strings.ToLower(v.Name), strings.ToLower(query)) {
+			items = append(items, v)
+		}
+	}
+	return items, nil
+}
+
+func (l *libraryServer) CreatePlaylist(name string, songs []models.Id) (models.Id, error) {
+	l.playlistName = name
+	l.playlistSongs = songs
+	return "playlist-1", nil
+}
+
+func TestImportM3U(t *testing.T) {
+	server := &libraryServer{songs: []*models.Song{
+		{Id: "a", Name: "Song", Duration: 200, Artists: []models.IdName{{Name: "Artist"}}},
+		{Id: "b", Name: "Song", Duration: 300, Artists: []models.IdName{{Name: "Artist"}}},
+		{Id: "c", Name: "Other", Duration: 100, Artists: []models.IdName{{Name: "Band"}}},
+		{Id: "d", Name: "Intro - Live", Duration: 60, Artists: []models.IdName{{Name: "Band"}}},
+	}}
+	file := path.Join(t.TempDir(), "mix.m3u")
+	text := "#EXTM3U\n#EXTINF:298,Artist - Song\nsong.flac\n" +
+		"#EXTINF:-1,Band feat. Someone - other\nhttp://server/other\n" +
+		"music/Band/02 - Intro - Live.mp3\n" +
+		"#EXTINF:100,Unknown - Song\nunknown.mp3\n"
+	if err := ioutil.WriteFile(file, []byte(text), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ImportM3U(server, file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Playlist.Id != "playlist-1" || result.Playlist.Name != "mix" || result.Playlist.SongCount != 3 {
+		t.Errorf("playlist: got %+v", result.Playlist)
+	}
+	wantSongs := []models.Id{"b", "c", "d"}
+	if server.playlistName != "mix" || !reflect.DeepEqual(server.playlistSongs, wantSongs) {
+		t.Errorf("created playlist: got %s %v, want mix %v", server.playlistName, server.playlistSongs, wantSongs)
+	}
+	wantUnmatched := []string{"Unknown - Song"}
+	if !reflect.DeepEqual(result.Unmatched, wantUnmatched) {
+		t.Errorf("unmatched: got %v, want %v", result.Unmatched, wantUnmatched)
+	}
+}
+
+func TestCopyPlaylist(t *testing.T) {
+	server := &libraryServer{}
+	shared := &models.Playlist{Id: "shared-1", Name: "Friday", Shared: true, ReadOnly: true, Songs: []*models.Song{
+		{Id: "a", Duration: 200},
+		{Id: "b", Duration: 100},
+	}}
+
+	copied, err := CopyPlaylist(server, shared, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copied.Id != "playlist-1" || copied.Name != "Friday" || copied.SongCount != 2 || copied.Duration != 300 {
+		t.Errorf("copy: got %+v", copied)
+	}
+	if copied.Shared || copied.ReadOnly {
+		t.Errorf("copy must be user's own playlist")
+	}
+	wantSongs := []models.Id{"a", "b"}
+	if server.playlistName != "Friday" || !reflect.DeepEqual(server.playlistSongs, wantSongs) {
+		t.Errorf("created playlist: got %s %v, want Friday %v", server.playlistName, server.playlistSongs, wantSongs)
+	}
+
+	if _, err := CopyPlaylist(server, &models.Playlist{Name: "empty", Songs: []*models.Song{}}, ""); err == nil {
+		t.Errorf("copying empty playlist must fail")
+	}
+}
+
+func Test_entryArtistTitle(t *testing.T) {
+	tests := []struct {
+		location string
+		title    string
+		artist   string
+		want     string
+	}{
+		{title: "Artist - Song", artist: "Artist", want: "Song"},
+		{title: "Song", want: "Song"},
+		{location: "/music/01 - Song.flac", want: "Song"},
+		{location: "/music/3. Artist - Song.mp3", artist: "Artist", want: "Song"},
+		{location: "http://server/stream?id=1"},
+	}
+	for _, tt := range tests {
+		artist, title := entryArtistTitle(m3u.Entry{Location: tt.location, Title: tt.title})
+		if artist != tt.artist || title != tt.want {
+			t.Errorf("entryArtistTitle(%s, %s) = %s, %s, want %s, %s", tt.location, tt.title, artist, title,
+				tt.artist, tt.want)
+		}
+	}
+}
@@ -19,9 +19,11 @@
 package player
 
 import (
+	"context"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"runtime"
+	"time"
 	"tryffel.net/go/jellycli/api"
 	"tryffel.net/go/jellycli/config"
 	"tryffel.net/go/jellycli/interfaces"
@@ -34,11 +36,16 @@ type Items struct {
 	browser api.MediaServer
 
 	db *storage.Db
+	// history is local play history, nil if disabled.
+	history *storage.History
+	// pages contains prefetched pages of artists, albums and songs.
+	pages *pageCache
 }
 
 func newItems(api api.MediaServer) (*Items, error) {
 	items := &Items{
 		browser: api,
+		pages:   newPageCache(time.Duration(config.AppConfig.Player.MetadataCacheTtlMin) * time.Minute),
 	}
 	var err error
 
@@ -49,6 +56,12 @@ func newItems(api api.MediaServer) (*Items, error) {
 			return items, fmt.Errorf("init local database: %v", err)
 		}
 	}
+	if config.AppConfig.Player.EnableHistoryDb {
+		items.history, err = storage.NewHistory()
+		if err != nil {
+			return items, fmt.Errorf("init play history: %v", err)
+		}
+	}
 	return items, err
 }
 
@@ -59,51 +72,125 @@ func (i *Items) closeDb() {
 			logrus.Errorf("close db: %s", err)
 		}
 	}
+	if i.history != nil {
+		err := i.history.Close()
+		if err != nil {
+			logrus.Errorf("close play history: %s", err)
+		}
+	}
 }
 
-func (i *Items) Search(itemType models.ItemType, query string) ([]models.Item, error) {
-	return i.browser.Search(query, itemType, config.AppConfig.Gui.SearchResultsLimit)
+// useLocal returns true if request to server failed and local cache can answer it instead.
+// This keeps browsing synced library working while server is unreachable. Cancelled request
+// is not answered from local cache.
+func (i *Items) useLocal(ctx context.Context, err error, request string) bool {
+	if err == nil || i.db == nil || ctx.Err() != nil {
+		return false
+	}
+	logrus.Warningf("%s: %v, using local cache", request, err)
+	return true
 }
 
-func (i *Items) GetArtists(opts *interfaces.QueryOpts) ([]*models.Artist, int, error) {
-	if config.AppConfig.Player.EnableLocalCache {
+func (i *Items) Search(ctx context.Context, itemType models.ItemType, query string) ([]models.Item, error) {
+	items, err := i.browser.Search(ctx, query, itemType, config.AppConfig.Gui.SearchResultsLimit)
+	if i.useLocal(ctx, err, "search") {
+		items, err = i.db.Search(itemType, query, config.AppConfig.Gui.SearchResultsLimit)
+	}
+	return items, err
+}
+
+// GetArtists returns artists from local cache, if enabled. Local cache has no genres or composers,
+// so genre and composer queries always use remote server. Same applies to GetAlbums and GetSongs.
+func (i *Items) GetArtists(ctx context.Context, opts *interfaces.QueryOpts) ([]*models.Artist, int, error) {
+	if config.AppConfig.Player.EnableLocalCache && len(opts.Filter.Genres) == 0 {
 		return i.db.GetArtists(opts)
-	} else {
-		return i.browser.GetArtists(opts)
 	}
+	artists, total, ok := i.pages.getArtists(opts)
+	if !ok {
+		var err error
+		artists, total, err = i.browser.GetArtists(ctx, opts)
+		if err != nil {
+			return artists, total, err
+		}
+		i.pages.putArtists(opts, artists, total)
+	}
+	i.prefetchNext(models.TypeArtist, opts, total)
+	return artists, total, nil
 }
 
-func (i *Items) GetAlbumArtists(paging interfaces.Paging) ([]*models.Artist, int, error) {
-	return i.browser.GetAlbumArtists(interfaces.DefaultQueryOpts())
+func (i *Items) GetAlbumArtists(ctx context.Context, paging interfaces.Paging) ([]*models.Artist, int, error) {
+	return i.browser.GetAlbumArtists(ctx, interfaces.DefaultQueryOpts())
 }
 
-func (i *Items) GetAlbums(opts *interfaces.QueryOpts) ([]*models.Album, int, error) {
-	if config.AppConfig.Player.EnableLocalCache {
+func (i *Items) GetAlbums(ctx context.Context, opts *interfaces.QueryOpts) ([]*models.Album, int, error) {
+	if config.AppConfig.Player.EnableLocalCache && len(opts.Filter.Genres) == 0 && len(opts.Filter.Composers) == 0 {
 		return i.db.GetAlbums(opts)
-	} else {
-		return i.browser.GetAlbums(opts)
 	}
+	albums, total, ok := i.pages.getAlbums(opts)
+	if !ok {
+		var err error
+		albums, total, err = i.browser.GetAlbums(ctx, opts)
+		if err != nil {
+			return albums, total, err
+		}
+		i.pages.putAlbums(opts, albums, total)
+	}
+	i.prefetchNext(models.TypeAlbum, opts, total)
+	return albums, total, nil
+}
+
+func (i *Items) GetArtistAlbums(ctx context.Context, artist models.Id) ([]*models.Album, error) {
+	albums, err := i.browser.GetArtistAlbums(ctx, artist)
+	if i.useLocal(ctx, err, "get artist albums") {
+		albums, err = i.db.GetArtistAlbums(artist)
+	}
+	return albums, err
 }
 
-func (i *Items) GetArtistAlbums(artist models.Id) ([]*models.Album, error) {
-	return i.browser.GetArtistAlbums(artist)
+func (i *Items) GetArtistAppearsOn(ctx context.Context, artist models.Id) ([]*models.Album, error) {
+	return i.browser.GetArtistAppearsOn(ctx, artist)
 }
 
-func (i *Items) GetAlbumSongs(album models.Id) ([]*models.Song, error) {
-	return i.browser.GetAlbumSongs(album)
+func (i *Items) GetArtistTopSongs(ctx context.Context, artist *models.Artist, limit int) ([]*models.Song, error) {
+	return i.browser.GetArtistTopSongs(ctx, artist, limit)
+}
+
+func (i *Items) GetArtistOverview(ctx context.Context, artist *models.Artist) (string, error) {
+	return i.browser.GetArtistOverview(ctx, artist)
+}
+
+func (i *Items) GetAlbum(ctx context.Context, id models.Id) (*models.Album, error) {
+	album, err := i.browser.GetAlbum(ctx, id)
+	if i.useLocal(ctx, err, "get album") {
+		album, err = i.db.GetAlbum(id)
+	}
+	return album, err
 }
 
-func (i *Items) GetPlaylists() ([]*models.Playlist, error) {
+func (i *Items) GetItemInfo(ctx context.Context, item models.Item) (*models.ItemInfo, error) {
+	return i.browser.GetItemInfo(ctx, item)
+}
+
+func (i *Items) GetAlbumSongs(ctx context.Context, album models.Id) ([]*models.Song, error) {
+	songs, err := i.browser.GetAlbumSongs(ctx, album)
+	if i.useLocal(ctx, err, "get album songs") {
+		songs, err = i.db.GetAlbumSongs(album)
+	}
+	return songs, err
+}
+
+func (i *Items) GetPlaylists(ctx context.Context) ([]*models.Playlist, error) {
 	if config.AppConfig.Player.EnableLocalCache {
 		return i.db.GetPlaylists()
-	} else {
-		return i.browser.GetPlaylists()
 	}
-
+	return i.browser.GetPlaylists(ctx)
 }
 
-func (i *Items) GetPlaylistSongs(playlist *models.Playlist) error {
-	songs, err := i.browser.GetPlaylistSongs(playlist.Id)
+func (i *Items) GetPlaylistSongs(ctx context.Context, playlist *models.Playlist) error {
+	songs, err := i.browser.GetPlaylistSongs(ctx, playlist.Id)
+	if i.useLocal(ctx, err, "get playlist songs") {
+		songs, err = i.db.GetPlaylistSongs(playlist.Id)
+	}
 	if err != nil {
 		return err
 	}
@@ -112,52 +199,125 @@ func (i *Items) GetPlaylistSongs(playlist *models.Playlist) error {
 	return nil
 }
 
-func (i *Items) GetFavoriteArtists() ([]*models.Artist, error) {
+func (i *Items) GetFavoriteArtists(ctx context.Context) ([]*models.Artist, error) {
 	query := interfaces.DefaultQueryOpts()
 	query.Filter.Favorite = true
-	artists, _, err := i.browser.GetArtists(query)
+	artists, _, err := i.browser.GetArtists(ctx, query)
+	if i.useLocal(ctx, err, "get favorite artists") {
+		artists, _, err = i.db.GetArtists(query)
+	}
 	return artists, err
 }
 
-func (i *Items) GetFavoriteAlbums(paging interfaces.Paging) ([]*models.Album, int, error) {
+func (i *Items) GetFavoriteAlbums(ctx context.Context, paging interfaces.Paging) ([]*models.Album, int, error) {
 	query := interfaces.DefaultQueryOpts()
 	query.Filter.Favorite = true
 	query.Paging = paging
-	return i.browser.GetAlbums(query)
+	albums, n, err := i.browser.GetAlbums(ctx, query)
+	if i.useLocal(ctx, err, "get favorite albums") {
+		albums, n, err = i.db.GetAlbums(query)
+	}
+	return albums, n, err
+}
+
+func (i *Items) GetLatestAlbums(ctx context.Context) ([]*models.Album, error) {
+	return i.getNewestAlbums(ctx, interfaces.SortByLatest)
+}
+
+func (i *Items) GetRecentlyReleasedAlbums(ctx context.Context) ([]*models.Album, error) {
+	return i.getNewestAlbums(ctx, interfaces.SortByReleaseDate)
 }
 
-func (i *Items) GetLatestAlbums() ([]*models.Album, error) {
+// get albums sorted descending by given date field
+func (i *Items) getNewestAlbums(ctx context.Context, field interfaces.SortField) ([]*models.Album, error) {
 	query := interfaces.DefaultQueryOpts()
 	if config.AppConfig.Gui.LimitRecentlyPlayed {
 		query.Paging.PageSize = 100
 	}
-	query.Sort.Field = interfaces.SortByLatest
+	query.Sort.Field = field
 	query.Sort.Mode = interfaces.SortDesc
-	albums, _, err := i.browser.GetAlbums(query)
+	albums, _, err := i.browser.GetAlbums(ctx, query)
 	return albums, err
 }
 
-func (i *Items) GetRecentlyPlayed(paging interfaces.Paging) ([]*models.Song, int, error) {
-	return i.browser.GetRecentlyPlayed(paging)
+func (i *Items) GetRecentlyPlayed(ctx context.Context, paging interfaces.Paging) ([]*models.Song, int, error) {
+	var songs []*models.Song
+	var total int
+	var err error
+	if i.history != nil {
+		songs, total, err = i.getLocalPlays(paging)
+	} else {
+		songs, total, err = i.browser.GetRecentlyPlayed(ctx, paging)
+	}
+	return dedupConsecutive(songs), total, err
+}
+
+func (i *Items) GetPlayHistory(ctx context.Context, paging interfaces.Paging) ([]*models.Song, int, error) {
+	if i.history == nil {
+		return []*models.Song{}, 0, nil
+	}
+	songs, total, err := i.getLocalPlays(paging)
+	return dedupConsecutive(songs), total, err
+}
+
+// getLocalPlays returns songs from local play history, latest first. Songs only have
+// metadata that was recorded with play.
+func (i *Items) getLocalPlays(paging interfaces.Paging) ([]*models.Song, int, error) {
+	plays, total, err := i.history.GetRecentPlays(paging)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get play history: %v", err)
+	}
+	songs := make([]*models.Song, len(plays))
+	for index, v := range plays {
+		songs[index] = &models.Song{
+			Id:          v.Id,
+			Name:        v.Title,
+			Duration:    v.Duration,
+			Album:       v.AlbumId,
+			AlbumArtist: v.ArtistId,
+			Artists:     []models.IdName{{Id: v.ArtistId, Name: v.Artist}},
+			AlbumName:   v.Album,
+			LastPlayed:  v.Started,
+		}
+	}
+	return songs, total, nil
+}
+
+// dedupConsecutive removes songs that are same as previous song, e.g. when song was resumed.
+func dedupConsecutive(songs []*models.Song) []*models.Song {
+	if len(songs) < 2 {
+		return songs
+	}
+	deduped := make([]*models.Song, 0, len(songs))
+	for i, v := range songs {
+		if i == 0 || v.Id != songs[i-1].Id {
+			deduped = append(deduped, v)
+		}
+	}
+	return deduped
+}
+
+func (i *Items) GetSimilarArtists(ctx context.Context, artist models.Id) ([]*models.Artist, error) {
+	return i.browser.GetSimilarArtists(ctx, artist)
 }
 
-func (i *Items) GetSimilarArtists(artist models.Id) ([]*models.Artist, error) {
-	return i.browser.GetSimilarArtists(artist)
+func (i *Items) GetSimilarAlbums(ctx context.Context, album models.Id) ([]*models.Album, error) {
+	return i.browser.GetSimilarAlbums(ctx, album)
 }
 
-func (i *Items) GetSimilarAlbums(album models.Id) ([]*models.Album, error) {
-	return i.browser.GetSimilarAlbums(album)
+func (i *Items) GetGenres(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
+	return i.browser.GetGenres(ctx, paging)
 }
 
-func (i *Items) GetGenres(paging interfaces.Paging) ([]*models.IdName, int, error) {
-	return i.browser.GetGenres(paging)
+func (i *Items) GetComposers(ctx context.Context, paging interfaces.Paging) ([]*models.IdName, int, error) {
+	return i.browser.GetComposers(ctx, paging)
 }
 
-func (i *Items) GetGenreAlbums(genre models.IdName) ([]*models.Album, error) {
+func (i *Items) GetGenreAlbums(ctx context.Context, genre models.IdName) ([]*models.Album, error) {
 	query := interfaces.DefaultQueryOpts()
 	query.Filter.Genres = []models.IdName{genre}
 
-	albums, _, err := i.browser.GetAlbums(query)
+	albums, _, err := i.browser.GetAlbums(ctx, query)
 	return albums, err
 }
 
@@ -177,43 +337,74 @@ func (i *Items) GetStatistics() models.Stats {
 		logrus.Errorf("get server info: %v", err)
 	}
 
+	if server, ok := i.browser.(api.RequestStatistics); ok {
+		stats.RequestStats = server.GetRequestStats()
+	}
+
 	if i.db != nil {
 		stats.StorageInfo, err = i.db.GetStats()
 		if err != nil {
 			logrus.Errorf("get local storage info: %v", err)
 		}
 	}
+	if i.history != nil {
+		stats.PlayStats, err = i.history.GetPlayStats()
+		if err != nil {
+			logrus.Errorf("get play history stats: %v", err)
+		}
+	}
 	return stats
 }
 
-func (i *Items) GetSongs(page, pageSize int) ([]*models.Song, int, error) {
-	if config.AppConfig.Player.EnableLocalCache {
-		return i.db.GetSongs(page, pageSize)
-	} else {
-		return i.browser.GetSongs(interfaces.DefaultQueryOpts())
+func (i *Items) GetSongs(ctx context.Context, query *interfaces.QueryOpts) ([]*models.Song, int, error) {
+	if config.AppConfig.Player.EnableLocalCache && len(query.Filter.Genres) == 0 && len(query.Filter.Composers) == 0 {
+		return i.db.GetSongs(query)
+	}
+	songs, total, ok := i.pages.getSongs(query)
+	if !ok {
+		var err error
+		songs, total, err = i.browser.GetSongs(ctx, query)
+		if err != nil {
+			return songs, total, err
+		}
+		i.pages.putSongs(query, songs, total)
 	}
+	i.prefetchNext(models.TypeSong, query, total)
+	return songs, total, nil
 }
 
-func (i *Items) GetAlbumArtist(album *models.Album) (*models.Artist, error) {
-	return i.browser.GetAlbumArtist(album)
+func (i *Items) GetAlbumArtist(ctx context.Context, album *models.Album) (*models.Artist, error) {
+	artist, err := i.browser.GetAlbumArtist(ctx, album)
+	if i.useLocal(ctx, err, "get album artist") {
+		artist, err = i.db.GetArtist(album.Artist)
+	}
+	return artist, err
 }
 
-func (i *Items) GetSongArtistAlbum(song *models.Song) (*models.Album, *models.Artist, error) {
+func (i *Items) GetSongArtistAlbum(ctx context.Context, song *models.Song) (*models.Album, *models.Artist, error) {
 	id := song.AlbumArtist
 	if id == "" && len(song.Artists) > 0 {
 		id = song.Artists[0].Id
 	}
 
-	artist, err := i.browser.GetArtist(id)
+	artist, err := i.getArtist(ctx, id)
 	if err != nil {
 		return nil, artist, err
 	}
-	album, err := i.browser.GetAlbum(song.Album)
+	album, err := i.GetAlbum(ctx, song.Album)
 	return album, artist, err
 }
 
-func (i *Items) GetInstantMix(item models.Item) ([]*models.Song, error) {
-	return i.browser.GetInstantMix(item)
+func (i *Items) getArtist(ctx context.Context, id models.Id) (*models.Artist, error) {
+	artist, err := i.browser.GetArtist(ctx, id)
+	if i.useLocal(ctx, err, "get artist") {
+		return i.db.GetArtist(id)
+	}
+	return artist, err
+}
+
+func (i *Items) GetInstantMix(ctx context.Context, item models.Item) ([]*models.Song, error) {
+	return i.browser.GetInstantMix(ctx, item)
 }
 
 func (i *Items) GetLink(item models.Item) string {
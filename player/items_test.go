@@ -0,0 +1,58 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/jellycli/storage"
+)
+
+func TestDedupConsecutive(t *testing.T) {
+	songs := []*models.Song{{Id: "song-1"}, {Id: "song-1"}, {Id: "song-2"}, {Id: "song-1"}, {Id: "song-2"},
+		{Id: "song-2"}}
+	want := []models.Id{"song-1", "song-2", "song-1", "song-2"}
+
+	got := dedupConsecutive(songs)
+	if len(got) != len(want) {
+		t.Fatalf("got %d songs, want %d", len(got), len(want))
+	}
+	for i, v := range got {
+		if v.Id != want[i] {
+			t.Errorf("song %d: got %s, want %s", i, v.Id, want[i])
+		}
+	}
+}
+
+func TestItems_useLocal(t *testing.T) {
+	items := &Items{db: &storage.Db{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	if items.useLocal(ctx, nil, "test") {
+		t.Errorf("local cache used without error")
+	}
+	if !items.useLocal(ctx, errors.New("offline"), "test") {
+		t.Errorf("local cache not used after failed request")
+	}
+	cancel()
+	if items.useLocal(ctx, context.Canceled, "test") {
+		t.Errorf("local cache used after cancelled request")
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"fmt"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// GetLibraries implements interfaces.LibraryController.
+func (p *Player) GetLibraries() ([]*models.View, error) {
+	if p.libraries == nil {
+		return nil, interfaces.ErrLibrariesNotSupported
+	}
+	return p.libraries.GetLibraries()
+}
+
+// GetLibrary implements interfaces.LibraryController.
+func (p *Player) GetLibrary() models.Id {
+	if p.libraries == nil {
+		return ""
+	}
+	return p.libraries.GetLibrary()
+}
+
+// SetLibrary implements interfaces.LibraryController. Selected library is saved to config file.
+func (p *Player) SetLibrary(id models.Id) error {
+	if p.libraries == nil {
+		return interfaces.ErrLibrariesNotSupported
+	}
+	err := p.libraries.SetLibrary(id)
+	if err != nil {
+		return err
+	}
+	p.refreshPages()
+	config.SetBackendConfig(p.api.GetConfig())
+	err = config.SaveConfig()
+	if err != nil {
+		return fmt.Errorf("save config: %v", err)
+	}
+	return nil
+}
+
+// AddLibraryChangedCallback implements interfaces.LibraryChangeNotifier. If server does not notify
+// library changes, callback is never called.
+func (p *Player) AddLibraryChangedCallback(cb func()) {
+	if p.libraryChanges == nil {
+		return
+	}
+	p.libraryChanges.AddLibraryChangedCallback(cb)
+}
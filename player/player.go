@@ -21,13 +21,17 @@
 package player
 
 import (
+	"context"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"io"
+	"path"
 	"strings"
 	"sync"
 	"time"
 	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/history"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/task"
@@ -63,8 +67,43 @@ type Player struct {
 
 	api              api.MediaServer
 	remoteController api.RemoteController
+	syncPlay         interfaces.SyncPlayController
+	sessions         interfaces.SessionController
+	libraries        interfaces.LibraryController
+	podcasts         interfaces.PodcastController
+	favorites        interfaces.FavoriteController
+	chapterProvider  interfaces.ChapterProvider
+	libraryChanges   interfaces.LibraryChangeNotifier
+	connection       interfaces.ConnectionNotifier
+	downloads        *downloads
+	covers           *covers
+	gains            *gains
+
+	// recorder and scrobbles are set when local play history is enabled.
+	recorder  *history.Recorder
+	scrobbles *scrobbles
 
 	lastApiReport time.Time
+	reports       chan *interfaces.ApiPlaybackState
+	// reportFailing is set when progress report failed, until a report succeeds.
+	// Only first failure is shown to user, rest are logged.
+	reportFailing bool
+	// offline is set while connection to server is down.
+	offline bool
+
+	// reportedSong is the song server was last told to be playing and reportedPast its latest position.
+	// reportedSongCompleted is set when reportedSong has been played until the end.
+	reportedSong          *models.Song
+	reportedPast          interfaces.AudioTick
+	reportedSongCompleted bool
+	// reportedQueue is queue that server was last told about.
+	reportedQueue []models.Id
+
+	// chapters of chapterSong, which is the playing song.
+	chapterSong models.Id
+	chapters    []models.Chapter
+
+	errorCallbacks []func(err error)
 }
 
 // initialize new player. This also initializes faiface.Speaker, which should be initialized only once.
@@ -75,6 +114,7 @@ func NewPlayer(browser api.MediaServer) (*Player, error) {
 		songComplete:   make(chan bool, 3),
 		audioUpdated:   make(chan interfaces.AudioStatus, 3),
 		songDownloaded: make(chan songMetadata, 3),
+		reports:        make(chan *interfaces.ApiPlaybackState, 20),
 		api:            browser,
 	}
 	p.Name = "Player"
@@ -86,25 +126,78 @@ func NewPlayer(browser api.MediaServer) (*Player, error) {
 	if err != nil {
 		return p, err
 	}
+	p.downloads = newDownloads(path.Join(config.AppConfig.Player.LocalCacheDir, "songs"),
+		int64(config.AppConfig.Player.DownloadQuotaMb)*1024*1024, browser)
+	p.covers = newCovers(path.Join(config.AppConfig.Player.LocalCacheDir, "covers"),
+		int64(config.AppConfig.Player.CoverCacheMb)*1024*1024)
+	p.gains = newGains(path.Join(config.AppConfig.Player.LocalCacheDir, gainFile))
 	if remoteController, ok := browser.(api.RemoteController); ok {
 		p.remoteController = remoteController
 		p.remoteController.SetPlayer(p)
 	}
+	if syncPlay, ok := browser.(interfaces.SyncPlayController); ok {
+		p.syncPlay = syncPlay
+	}
+	if sessions, ok := browser.(interfaces.SessionController); ok {
+		p.sessions = sessions
+	}
+	if libraries, ok := browser.(interfaces.LibraryController); ok {
+		p.libraries = libraries
+	}
+	if podcasts, ok := browser.(interfaces.PodcastController); ok {
+		p.podcasts = podcasts
+	}
+	if favorites, ok := browser.(interfaces.FavoriteController); ok {
+		p.favorites = favorites
+	}
+	if chapterProvider, ok := browser.(interfaces.ChapterProvider); ok {
+		p.chapterProvider = chapterProvider
+	}
+	if libraryChanges, ok := browser.(interfaces.LibraryChangeNotifier); ok {
+		p.libraryChanges = libraryChanges
+		p.libraryChanges.AddLibraryChangedCallback(p.refreshPages)
+	}
+	if connection, ok := browser.(interfaces.ConnectionNotifier); ok {
+		p.connection = connection
+		p.connection.AddConnectionCallback(p.connectionChanged)
+	}
 
 	err = initAudio()
 	if err != nil {
 		return p, fmt.Errorf("init audio backend: %v", err)
 	}
 
+	p.Audio.restoreVolume(config.AppConfig.Player.Volumes, audioOutput())
 	p.Audio.songCompleteFunc = p.songCompleted
+	p.Audio.gainFunc = p.gains.songOffset
 	p.Audio.AddStatusCallback(p.audioCallback)
+	if p.chapterProvider != nil {
+		p.Audio.AddStatusCallback(p.chaptersChanged)
+	}
 
 	p.Queue.AddQueueChangedCallback(p.queueChanged)
+	if p.Items.history != nil {
+		p.recorder = history.NewRecorder(p.Items.history, p)
+		p.scrobbles = newScrobbles(p.Items.history, browser)
+		p.AddConnectionCallback(p.scrobbles.connectionChanged)
+		go p.scrobbles.loop()
+	}
+	go p.reportLoop()
+	p.Items.prefetchLibrary()
 	return p, nil
 }
 
+// refreshPages discards prefetched pages and prefetches library again.
+func (p *Player) refreshPages() {
+	p.Items.pages.clear()
+	p.Items.prefetchLibrary()
+}
+
 // notify song has completed
 func (p *Player) songCompleted() {
+	p.lock.Lock()
+	p.reportedSongCompleted = true
+	p.lock.Unlock()
 	p.songComplete <- true
 }
 
@@ -124,6 +217,11 @@ func (p *Player) loop() {
 		case <-p.StopChan():
 			// stop application
 			p.Audio.StopMedia()
+			p.Items.pages.stopPrefetch()
+			if p.recorder != nil {
+				p.recorder.Close()
+				p.scrobbles.close()
+			}
 			p.Items.closeDb()
 			break
 		case <-p.songComplete:
@@ -137,6 +235,7 @@ func (p *Player) loop() {
 					err := p.Audio.playSongFromReader(*p.nextSong)
 					if err != nil {
 						logrus.Errorf("play track: %v", err)
+						p.reportError(fmt.Errorf("play track: %v", err))
 					}
 					p.nextSong = nil
 				} else {
@@ -160,6 +259,7 @@ func (p *Player) loop() {
 				err := p.Audio.playSongFromReader(metadata)
 				if err != nil {
 					logrus.Errorf("play track: %v", err)
+					p.reportError(fmt.Errorf("play track: %v", err))
 				}
 				p.nextSong = nil
 			} else {
@@ -181,7 +281,13 @@ func (p *Player) downloadSong(index int) {
 	p.lock.Unlock()
 	ok := false
 
-	reader, format, err := p.api.Stream(song)
+	reader, format, downloaded := p.downloads.open(song.Id)
+	var err error
+	if downloaded {
+		logrus.Debugf("play song %s from downloads", song.Id)
+	} else {
+		reader, format, err = p.api.Stream(song)
+	}
 	if err != nil {
 		if strings.Contains(err.Error(), "A task was canceled") {
 			// server task may fail sometimes, retry
@@ -192,9 +298,11 @@ func (p *Player) downloadSong(index int) {
 				ok = true
 			} else {
 				logrus.Errorf("retry downloading song: %v", err)
+				p.reportError(fmt.Errorf("download song: %v", err))
 			}
 		} else {
 			logrus.Errorf("download song: %v", err)
+			p.reportError(fmt.Errorf("download song: %v", err))
 		}
 	} else {
 		ok = true
@@ -202,7 +310,7 @@ func (p *Player) downloadSong(index int) {
 	if ok {
 		// fill metadata
 		albumId := song.GetParent()
-		album, err := p.api.GetAlbum(albumId)
+		album, err := p.Items.GetAlbum(context.Background(), albumId)
 		artist := &models.Artist{Name: "unknown artist"}
 		var imageId string
 		var imageUrl string
@@ -211,27 +319,33 @@ func (p *Player) downloadSong(index int) {
 			album = &models.Album{Name: "unknown album"}
 		} else {
 			imageId = album.ImageId
-			imageUrl = p.api.GetImageUrl(album.Id, models.TypeAlbum)
+			file, coverErr := p.GetAlbumCover(album.Id)
+			if coverErr == nil {
+				imageUrl = fileUrl(file)
+			} else if coverErr != errNoCover {
+				logrus.Warningf("cache album cover: %v", coverErr)
+			}
 		}
-		a, err := p.api.GetArtist(album.GetParent())
+		a, err := p.Items.getArtist(context.Background(), album.GetParent())
 		if err != nil {
+			// song can still be played, e.g. from downloads while server is offline
 			logrus.Errorf("Failed to get artist by id: %v", err)
 		} else {
 			artist = a
-			f := func() {
-				metadata := songMetadata{
-					song:          song,
-					album:         album,
-					artist:        artist,
-					albumImageUrl: imageUrl,
-					albumImageId:  imageId,
-					reader:        reader,
-					format:        format,
-				}
-				p.songDownloaded <- metadata
+		}
+		f := func() {
+			metadata := songMetadata{
+				song:          song,
+				album:         album,
+				artist:        artist,
+				albumImageUrl: imageUrl,
+				albumImageId:  imageId,
+				reader:        reader,
+				format:        format,
 			}
-			defer f()
+			p.songDownloaded <- metadata
 		}
+		defer f()
 	}
 
 	p.lock.Lock()
@@ -250,8 +364,14 @@ func (p *Player) Next() {
 	}
 }
 
-// Previous plays previous track. Override Audio previous to ensure there is track to play and download it
+// Previous plays previous track. Override Audio previous to ensure there is track to play and download it.
+// If more than player.previous_restart_s seconds of current song have been played, restart it instead.
 func (p *Player) Previous() {
+	if restartOnPrevious(p.Audio.getPastTicks(), config.AppConfig.Player.PreviousRestartS) {
+		logrus.Info("Restart song")
+		p.Audio.SeekTo(0)
+		return
+	}
 	if len(p.Queue.GetHistory(10)) > 0 {
 		p.StopMedia()
 		p.Queue.playLastSong()
@@ -260,11 +380,21 @@ func (p *Player) Previous() {
 	}
 }
 
+// restartOnPrevious returns true if song that has been played for past ticks should be restarted
+// instead of playing previous song. Negative restartS always plays previous song.
+func restartOnPrevious(past interfaces.AudioTick, restartS int) bool {
+	return restartS >= 0 && past > interfaces.AudioTick(restartS*1000)
+}
+
 // report audio status to server
 func (p *Player) audioCallback(status interfaces.AudioStatus) {
-	p.lock.RLock()
+	p.lock.Lock()
 	lastTime := p.lastApiReport
-	p.lock.RUnlock()
+	if p.reportedSong != nil && status.Song != nil && status.Song.Id == p.reportedSong.Id &&
+		status.State == interfaces.AudioStatePlaying {
+		p.reportedPast = status.SongPast
+	}
+	p.lock.Unlock()
 
 	if time.Now().Sub(lastTime) < time.Millisecond*9500 && status.Action == interfaces.AudioActionTimeUpdate {
 		// jellyfin server instructs to update every 10 sec
@@ -275,18 +405,79 @@ func (p *Player) audioCallback(status interfaces.AudioStatus) {
 		// don't report TimeUpdate if player is stopped
 		return
 	}
+	p.reportProgress(status)
+}
 
+// reportProgress reports status and current queue to server. Server is told that previous song has
+// stopped before next song is reported started, so that server can mark songs as played.
+func (p *Player) reportProgress(status interfaces.AudioStatus) {
 	p.lock.Lock()
 	p.lastApiReport = time.Now()
+	previous := p.reportedSong
+	previousPast := p.reportedPast
+	if previous != nil && p.reportedSongCompleted {
+		previousPast = interfaces.AudioTick(previous.Duration * 1000)
+	}
+	switch status.Action {
+	case interfaces.AudioActionPlay:
+		p.reportedSong = status.Song
+		p.reportedPast = status.SongPast
+		p.reportedSongCompleted = false
+	case interfaces.AudioActionStop:
+		p.reportedSong = nil
+		p.reportedSongCompleted = false
+	}
 	p.lock.Unlock()
 
+	if previous == nil && status.Action != interfaces.AudioActionPlay {
+		// server has not been told anything is playing
+		return
+	}
+
+	apiStatus := p.apiPlaybackState(status)
+	var reports []*interfaces.ApiPlaybackState
+	switch status.Action {
+	case interfaces.AudioActionPlay:
+		if previous != nil {
+			stopped := p.apiPlaybackState(status)
+			stopped.Event = interfaces.EventStop
+			stopped.ItemId = previous.Id.String()
+			stopped.PlaylistLength = previous.Duration
+			stopped.Position = previousPast
+			stopped.IsPaused = false
+			reports = append(reports, stopped)
+		}
+	case interfaces.AudioActionStop:
+		// status might already contain next song
+		apiStatus.ItemId = previous.Id.String()
+		apiStatus.PlaylistLength = previous.Duration
+		apiStatus.Position = previousPast
+	case interfaces.AudioActionNext, interfaces.AudioActionPrevious:
+		if status.State != interfaces.AudioStatePlaying {
+			// previous song is already stopped and next one gets reported when it starts
+			return
+		}
+	}
+	reports = append(reports, apiStatus)
+
+	for _, v := range reports {
+		select {
+		case p.reports <- v:
+		default:
+			logrus.Warningf("report progress to server: too many pending reports, dropping %s", v.Event)
+		}
+	}
+}
+
+// apiPlaybackState converts audio status to state reported to server.
+func (p *Player) apiPlaybackState(status interfaces.AudioStatus) *interfaces.ApiPlaybackState {
 	apiStatus := &interfaces.ApiPlaybackState{
 		Event:          "",
 		ItemId:         "",
 		IsPaused:       false,
 		IsMuted:        status.Muted,
 		PlaylistLength: 0,
-		Position:       status.SongPast.Seconds(),
+		Position:       status.SongPast,
 		Volume:         int(status.Volume),
 		Shuffle:        status.Shuffle,
 	}
@@ -312,6 +503,9 @@ func (p *Player) audioCallback(status interfaces.AudioStatus) {
 		}
 	case interfaces.AudioActionShuffleChanged:
 		apiStatus.Event = interfaces.EventShuffleModeChange
+	case interfaces.AudioActionSeek:
+		// there's no seek event, progress update with new position is enough
+		apiStatus.Event = interfaces.EventTimeUpdate
 	default:
 		apiStatus.Event = interfaces.EventTimeUpdate
 		logrus.Warningf("cannot map audio state to browser event: %v", status.Action)
@@ -329,13 +523,48 @@ func (p *Player) audioCallback(status interfaces.AudioStatus) {
 		apiStatus.ItemId = status.Song.Id.String()
 		apiStatus.PlaylistLength = status.Song.Duration
 	}
-	f := func() {
-		err := p.browser.ReportProgress(apiStatus)
-		if err != nil {
+	return apiStatus
+}
+
+// reportLoop sends progress reports to server one by one, so that they arrive in order.
+func (p *Player) reportLoop() {
+	for report := range p.reports {
+		err := p.browser.ReportProgress(report)
+		if p.scrobbles != nil {
+			p.scrobbles.reported(report, err)
+		}
+		if err == api.ErrNotScrobbled {
+			logrus.Warningf("song %s stopped before it was scrobbled", report.ItemId)
+		} else if err != nil {
 			logrus.Errorf("report audio progress to server: %v", err)
+			p.lock.RLock()
+			offline := p.offline
+			p.lock.RUnlock()
+			if !p.reportFailing && !offline {
+				p.reportError(fmt.Errorf("report progress to server: %v", err))
+			}
+			p.reportFailing = true
+		} else {
+			p.reportFailing = false
 		}
 	}
-	go f()
+}
+
+// AddErrorCallback adds callback that gets called every time playback fails.
+func (p *Player) AddErrorCallback(cb func(err error)) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.errorCallbacks = append(p.errorCallbacks, cb)
+}
+
+// push error to all error callbacks
+func (p *Player) reportError(err error) {
+	p.lock.RLock()
+	callbacks := p.errorCallbacks
+	p.lock.RUnlock()
+	for _, v := range callbacks {
+		v(err)
+	}
 }
 
 func (p *Player) queueChanged(queue []*models.Song) {
@@ -343,7 +572,55 @@ func (p *Player) queueChanged(queue []*models.Song) {
 	state := p.Audio.getStatus()
 	if state.State == interfaces.AudioStateStopped && len(queue) > 0 {
 		go p.downloadSong(0)
+	} else if state.State == interfaces.AudioStatePlaying {
+		// keep remote clients' view of the queue up to date. Queue may still be locked, so report asynchronously.
+		ids := make([]models.Id, len(queue))
+		for i, v := range queue {
+			ids[i] = v.Id
+		}
+		go p.reportQueueChange(state, ids)
+	}
+}
+
+// reportQueueChange reports changed queue to server, so that other clients of the session see it.
+func (p *Player) reportQueueChange(status interfaces.AudioStatus, queue []models.Id) {
+	p.lock.Lock()
+	event := queueChangeEvent(p.reportedQueue, queue)
+	p.reportedQueue = queue
+	if event == "" {
+		p.lock.Unlock()
+		return
+	}
+	p.lastApiReport = time.Now()
+	p.lock.Unlock()
+
+	status.Action = interfaces.AudioActionTimeUpdate
+	report := p.apiPlaybackState(status)
+	report.Event = event
+	select {
+	case p.reports <- report:
+	default:
+		logrus.Warningf("report queue to server: too many pending reports, dropping %s", report.Event)
+	}
+}
+
+// queueChangeEvent returns event that describes change from old queue to new one. If playing song has changed,
+// return time update, and if queues are equal, return empty event.
+func queueChangeEvent(old, new []models.Id) interfaces.ApiPlaybackEvent {
+	if len(old) == 0 || len(new) == 0 || old[0] != new[0] {
+		return interfaces.EventTimeUpdate
+	}
+	if len(new) > len(old) {
+		return interfaces.EventPlaylistItemAdd
+	} else if len(new) < len(old) {
+		return interfaces.EventPlaylistItemRemove
+	}
+	for i := range new {
+		if new[i] != old[i] {
+			return interfaces.EventPlaylistItemMove
+		}
 	}
+	return ""
 }
 
 func (p *Player) Reorder(index int, left bool) bool {
@@ -0,0 +1,181 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestPlayer_reportProgress(t *testing.T) {
+	p := &Player{
+		lock:         &sync.RWMutex{},
+		songComplete: make(chan bool, 1),
+		reports:      make(chan *interfaces.ApiPlaybackState, 10),
+		Queue:        newQueue(),
+	}
+	first := &models.Song{Id: "song-1", Duration: 180}
+	second := &models.Song{Id: "song-2", Duration: 200}
+
+	type report struct {
+		event    interfaces.ApiPlaybackEvent
+		item     string
+		position interfaces.AudioTick
+	}
+	want := func(step string, reports ...report) {
+		for _, v := range reports {
+			select {
+			case got := <-p.reports:
+				if got.Event != v.event || got.ItemId != v.item || got.Position != v.position {
+					t.Errorf("%s: got %s %s at %d, want %s %s at %d", step, got.Event, got.ItemId, got.Position,
+						v.event, v.item, v.position)
+				}
+			default:
+				t.Errorf("%s: missing report %s %s", step, v.event, v.item)
+			}
+		}
+		if len(p.reports) > 0 {
+			t.Errorf("%s: %d unexpected reports", step, len(p.reports))
+		}
+	}
+
+	p.reportProgress(interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Action: interfaces.AudioActionPlay,
+		Song: first})
+	want("start first", report{interfaces.EventStart, "song-1", 0})
+
+	p.audioCallback(interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Action: interfaces.AudioActionTimeUpdate,
+		Song: first, SongPast: 179000})
+	want("time update within interval")
+
+	// song played until end, next song starts
+	p.songCompleted()
+	<-p.songComplete
+	p.reportProgress(interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Action: interfaces.AudioActionPlay,
+		Song: second})
+	want("start second", report{interfaces.EventStop, "song-1", 180000}, report{interfaces.EventStart, "song-2", 0})
+
+	p.audioCallback(interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Action: interfaces.AudioActionTimeUpdate,
+		Song: second, SongPast: 30000})
+	p.reportProgress(interfaces.AudioStatus{State: interfaces.AudioStateStopped, Action: interfaces.AudioActionStop,
+		Song: second, SongPast: 29000})
+	want("stop second", report{interfaces.EventStop, "song-2", 30000})
+
+	p.reportProgress(interfaces.AudioStatus{State: interfaces.AudioStateStopped, Action: interfaces.AudioActionNext,
+		Song: second})
+	want("next after stop")
+}
+
+// reportServer returns errs for progress reports in order.
+type reportServer struct {
+	api.MediaServer
+	errs []error
+}
+
+func (r *reportServer) ReportProgress(state *interfaces.ApiPlaybackState) error {
+	err := r.errs[0]
+	r.errs = r.errs[1:]
+	return err
+}
+
+func TestPlayer_reportLoop(t *testing.T) {
+	failed := errors.New("offline")
+	server := &reportServer{errs: []error{nil, failed, failed, failed, nil, failed}}
+	p := &Player{
+		lock:    &sync.RWMutex{},
+		reports: make(chan *interfaces.ApiPlaybackState, len(server.errs)),
+		Items:   &Items{browser: server},
+	}
+	notified := 0
+	p.AddErrorCallback(func(err error) { notified++ })
+
+	for range server.errs {
+		p.reports <- &interfaces.ApiPlaybackState{Event: interfaces.EventTimeUpdate, ItemId: "song-1"}
+	}
+	close(p.reports)
+	p.reportLoop()
+
+	// once for each streak of failed reports
+	if notified != 2 {
+		t.Errorf("got %d notifications, want 2", notified)
+	}
+
+	// failures while offline are only logged
+	server.errs = []error{nil, failed, failed}
+	p.reports = make(chan *interfaces.ApiPlaybackState, len(server.errs))
+	p.connectionChanged(false)
+	for range server.errs {
+		p.reports <- &interfaces.ApiPlaybackState{Event: interfaces.EventTimeUpdate, ItemId: "song-1"}
+	}
+	close(p.reports)
+	p.reportLoop()
+	if notified != 2 {
+		t.Errorf("got %d notifications while offline, want none", notified-2)
+	}
+}
+
+func Test_restartOnPrevious(t *testing.T) {
+	tests := []struct {
+		name     string
+		past     interfaces.AudioTick
+		restartS int
+		want     bool
+	}{
+		{name: "start of song", past: 1500, restartS: 3, want: false},
+		{name: "at limit", past: 3000, restartS: 3, want: false},
+		{name: "past limit", past: 3001, restartS: 3, want: true},
+		{name: "always previous", past: 60000, restartS: -1, want: false},
+		{name: "always restart", past: 1, restartS: 0, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restartOnPrevious(tt.past, tt.restartS); got != tt.want {
+				t.Errorf("restartOnPrevious() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_queueChangeEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		old  []models.Id
+		new  []models.Id
+		want interfaces.ApiPlaybackEvent
+	}{
+		{name: "not reported", old: nil, new: []models.Id{"a", "b"}, want: interfaces.EventTimeUpdate},
+		{name: "song changed", old: []models.Id{"a", "b"}, new: []models.Id{"b"}, want: interfaces.EventTimeUpdate},
+		{name: "added", old: []models.Id{"a"}, new: []models.Id{"a", "b"}, want: interfaces.EventPlaylistItemAdd},
+		{name: "removed", old: []models.Id{"a", "b", "c"}, new: []models.Id{"a", "c"},
+			want: interfaces.EventPlaylistItemRemove},
+		{name: "moved", old: []models.Id{"a", "b", "c"}, new: []models.Id{"a", "c", "b"},
+			want: interfaces.EventPlaylistItemMove},
+		{name: "unchanged", old: []models.Id{"a", "b"}, new: []models.Id{"a", "b"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := queueChangeEvent(tt.old, tt.new); got != tt.want {
+				t.Errorf("queueChangeEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// GetPodcasts implements interfaces.PodcastController.
+func (p *Player) GetPodcasts() ([]*models.Album, error) {
+	if p.podcasts == nil {
+		return nil, interfaces.ErrPodcastsNotSupported
+	}
+	return p.podcasts.GetPodcasts()
+}
+
+// GetEpisodes implements interfaces.PodcastController.
+func (p *Player) GetEpisodes(podcast models.Id) ([]*models.Song, error) {
+	if p.podcasts == nil {
+		return nil, interfaces.ErrPodcastsNotSupported
+	}
+	return p.podcasts.GetEpisodes(podcast)
+}
+
+// SetPlayed implements interfaces.PodcastController.
+func (p *Player) SetPlayed(episode models.Id, played bool) error {
+	if p.podcasts == nil {
+		return interfaces.ErrPodcastsNotSupported
+	}
+	return p.podcasts.SetPlayed(episode, played)
+}
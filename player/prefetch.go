@@ -0,0 +1,326 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/metrics"
+	"tryffel.net/go/jellycli/models"
+)
+
+const (
+	// prefetchInterval limits how often library is requested while prefetching.
+	prefetchInterval = time.Millisecond * 500
+	// nextPageDelay is waited before prefetching next page, so that quickly changing view
+	// cancels prefetch before request is made.
+	nextPageDelay = time.Millisecond * 300
+)
+
+// pageCache stores pages of artists, albums and songs, so that browsing large library
+// does not need a request to server for every page.
+type pageCache struct {
+	lock  sync.RWMutex
+	ttl   time.Duration
+	pages map[string]page
+
+	// stop stops running library prefetch, nil if there is none.
+	stop chan struct{}
+	// next is pending prefetch of next page, nil if there is none.
+	next *nextPage
+	// view is increased every time view changes.
+	view int
+}
+
+type page struct {
+	items   interface{}
+	total   int
+	expires time.Time
+}
+
+type nextPage struct {
+	key    string
+	view   int
+	cancel chan struct{}
+}
+
+func newPageCache(ttl time.Duration) *pageCache {
+	return &pageCache{
+		ttl:   ttl,
+		pages: map[string]page{},
+	}
+}
+
+// pageKey identifies page of query. Total items is ignored, as it varies between requests.
+func pageKey(itemType models.ItemType, opts *interfaces.QueryOpts) string {
+	return fmt.Sprintf("%s:%d:%d:%v:%v", itemType, opts.Paging.CurrentPage, opts.Paging.PageSize,
+		opts.Filter, opts.Sort)
+}
+
+func (c *pageCache) get(itemType models.ItemType, opts *interfaces.QueryOpts) (interface{}, int, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	p, ok := c.pages[pageKey(itemType, opts)]
+	if !ok || time.Now().After(p.expires) {
+		return nil, 0, false
+	}
+	return p.items, p.total, true
+}
+
+func (c *pageCache) put(itemType models.ItemType, opts *interfaces.QueryOpts, items interface{}, total int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.pages[pageKey(itemType, opts)] = page{items: items, total: total, expires: time.Now().Add(c.ttl)}
+}
+
+func (c *pageCache) getArtists(opts *interfaces.QueryOpts) ([]*models.Artist, int, bool) {
+	items, total, ok := c.get(models.TypeArtist, opts)
+	metrics.PageCache.Lookup(ok)
+	if !ok {
+		return nil, 0, false
+	}
+	return items.([]*models.Artist), total, true
+}
+
+func (c *pageCache) putArtists(opts *interfaces.QueryOpts, artists []*models.Artist, total int) {
+	c.put(models.TypeArtist, opts, artists, total)
+}
+
+func (c *pageCache) getAlbums(opts *interfaces.QueryOpts) ([]*models.Album, int, bool) {
+	items, total, ok := c.get(models.TypeAlbum, opts)
+	metrics.PageCache.Lookup(ok)
+	if !ok {
+		return nil, 0, false
+	}
+	return items.([]*models.Album), total, true
+}
+
+func (c *pageCache) putAlbums(opts *interfaces.QueryOpts, albums []*models.Album, total int) {
+	c.put(models.TypeAlbum, opts, albums, total)
+}
+
+func (c *pageCache) getSongs(opts *interfaces.QueryOpts) ([]*models.Song, int, bool) {
+	items, total, ok := c.get(models.TypeSong, opts)
+	metrics.PageCache.Lookup(ok)
+	if !ok {
+		return nil, 0, false
+	}
+	return items.([]*models.Song), total, true
+}
+
+func (c *pageCache) putSongs(opts *interfaces.QueryOpts, songs []*models.Song, total int) {
+	c.put(models.TypeSong, opts, songs, total)
+}
+
+// clear removes all pages, e.g. after library has changed.
+func (c *pageCache) clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.pages = map[string]page{}
+}
+
+// startPrefetch stops running prefetch, if any, and returns channel for new one.
+func (c *pageCache) startPrefetch() chan struct{} {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.stop != nil {
+		close(c.stop)
+	}
+	c.stop = make(chan struct{})
+	return c.stop
+}
+
+// stopPrefetch stops running library prefetch and pending next page, if any.
+func (c *pageCache) stopPrefetch() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+	c.cancelNext()
+}
+
+// startNext cancels pending next page and returns channel for prefetching page with key.
+// If page is already being prefetched, nil is returned.
+func (c *pageCache) startNext(key string) chan struct{} {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.next != nil && c.next.key == key {
+		return nil
+	}
+	c.cancelNext()
+	c.next = &nextPage{key: key, view: c.view, cancel: make(chan struct{})}
+	return c.next.cancel
+}
+
+// doneNext marks prefetch of next page complete.
+func (c *pageCache) doneNext(cancel chan struct{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.next != nil && c.next.cancel == cancel {
+		c.next = nil
+	}
+}
+
+// cancelNext cancels pending next page. Lock must be held.
+func (c *pageCache) cancelNext() {
+	if c.next != nil {
+		close(c.next.cancel)
+		c.next = nil
+	}
+}
+
+// viewChanged cancels next page, unless it was requested for the view that is now shown.
+// View is changed after its items have been requested, so next page of new view is kept.
+func (c *pageCache) viewChanged() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.next != nil && c.next.view < c.view {
+		c.cancelNext()
+	}
+	c.view += 1
+}
+
+// CancelPrefetch implements interfaces.PagePrefetcher.
+func (i *Items) CancelPrefetch() {
+	i.pages.viewChanged()
+}
+
+// prefetchNext loads page after opts in background, unless opts is the last page.
+// Only one page is prefetched at a time.
+func (i *Items) prefetchNext(itemType models.ItemType, opts *interfaces.QueryOpts, total int) {
+	next := *opts
+	next.Paging.CurrentPage += 1
+	if next.Paging.PageSize <= 0 || next.Paging.Offset() >= total {
+		return
+	}
+	if _, _, ok := i.pages.get(itemType, &next); ok {
+		return
+	}
+	cancel := i.pages.startNext(pageKey(itemType, &next))
+	if cancel == nil {
+		return
+	}
+
+	go func() {
+		defer i.pages.doneNext(cancel)
+		select {
+		case <-cancel:
+			return
+		case <-time.After(nextPageDelay):
+		}
+
+		var err error
+		switch itemType {
+		case models.TypeArtist:
+			var artists []*models.Artist
+			artists, total, err = i.browser.GetArtists(context.Background(), &next)
+			if err == nil {
+				i.pages.putArtists(&next, artists, total)
+			}
+		case models.TypeAlbum:
+			var albums []*models.Album
+			albums, total, err = i.browser.GetAlbums(context.Background(), &next)
+			if err == nil {
+				i.pages.putAlbums(&next, albums, total)
+			}
+		case models.TypeSong:
+			var songs []*models.Song
+			songs, total, err = i.browser.GetSongs(context.Background(), &next)
+			if err == nil {
+				i.pages.putSongs(&next, songs, total)
+			}
+		}
+		if err != nil {
+			logrus.Warningf("prefetch %s page %d: %v", itemType, next.Paging.CurrentPage, err)
+		}
+	}()
+}
+
+// prefetchLibrary starts crawling all artists and albums page by page in background,
+// so that paging them later is instant. Running prefetch is restarted.
+// Local cache already serves full library, so nothing is prefetched when it is enabled.
+func (i *Items) prefetchLibrary() {
+	if !config.AppConfig.Player.PrefetchLibrary || config.AppConfig.Player.EnableLocalCache {
+		return
+	}
+	stop := i.pages.startPrefetch()
+	go i.prefetch(stop)
+}
+func (i *Items) prefetch(stop chan struct{}) {
+	start := time.Now()
+	ticker := time.NewTicker(prefetchInterval)
+	defer ticker.Stop()
+
+	// wait returns false if prefetch has been stopped.
+	wait := func() bool {
+		select {
+		case <-stop:
+			return false
+		case <-ticker.C:
+			return true
+		}
+	}
+
+	artists := 0
+	query := interfaces.DefaultQueryOpts()
+	for wait() {
+		page, total, err := i.browser.GetArtists(context.Background(), query)
+		if err != nil {
+			logrus.Warningf("prefetch artists: %v", err)
+			return
+		}
+		i.pages.putArtists(query, page, total)
+		artists += len(page)
+		query.Paging.CurrentPage += 1
+		if len(page) < query.Paging.PageSize || artists >= total {
+			break
+		}
+	}
+
+	albums := 0
+	query = interfaces.DefaultQueryOpts()
+	for wait() {
+		page, total, err := i.browser.GetAlbums(context.Background(), query)
+		if err != nil {
+			logrus.Warningf("prefetch albums: %v", err)
+			return
+		}
+		i.pages.putAlbums(query, page, total)
+		albums += len(page)
+		query.Paging.CurrentPage += 1
+		if len(page) < query.Paging.PageSize || albums >= total {
+			break
+		}
+	}
+
+	select {
+	case <-stop:
+		return
+	default:
+	}
+	logrus.Infof("Prefetched %d artists and %d albums in %.2f s", artists, albums,
+		float32(time.Since(start).Milliseconds())/1000)
+}
@@ -0,0 +1,112 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func TestPageCache(t *testing.T) {
+	c := newPageCache(time.Hour)
+
+	opts := interfaces.DefaultQueryOpts()
+	opts.Paging.CurrentPage = 2
+	artists := []*models.Artist{{Id: "artist-1"}, {Id: "artist-2"}}
+	c.putArtists(opts, artists, 250)
+
+	// total items is set by ui after query and must not affect page
+	query := interfaces.DefaultQueryOpts()
+	query.Paging.CurrentPage = 2
+	query.Paging.SetTotalItems(250)
+
+	got, total, ok := c.getArtists(query)
+	if !ok {
+		t.Fatalf("page not found")
+	}
+	if total != 250 || len(got) != len(artists) {
+		t.Errorf("got %d artists, total %d, want %d, %d", len(got), total, len(artists), 250)
+	}
+
+	query.Paging.CurrentPage = 3
+	if _, _, ok := c.getArtists(query); ok {
+		t.Errorf("found page that was not stored")
+	}
+	query.Paging.CurrentPage = 2
+	query.Sort.Mode = interfaces.SortDesc
+	if _, _, ok := c.getArtists(query); ok {
+		t.Errorf("found page with different sorting")
+	}
+	if _, _, ok := c.getAlbums(opts); ok {
+		t.Errorf("found albums from artist page")
+	}
+
+	c.clear()
+	if _, _, ok := c.getArtists(opts); ok {
+		t.Errorf("found page after clear")
+	}
+}
+
+func TestPageCache_Expired(t *testing.T) {
+	c := newPageCache(time.Millisecond)
+	opts := interfaces.DefaultQueryOpts()
+	c.putAlbums(opts, []*models.Album{{Id: "album-1"}}, 1)
+	time.Sleep(time.Millisecond * 5)
+	if _, _, ok := c.getAlbums(opts); ok {
+		t.Errorf("found expired page")
+	}
+}
+
+func TestPageCache_ViewChanged(t *testing.T) {
+	c := newPageCache(time.Hour)
+
+	// items for new view are requested before view is changed, so its next page must survive
+	cancel := c.startNext("page-1")
+	c.viewChanged()
+	select {
+	case <-cancel:
+		t.Fatalf("next page of current view cancelled")
+	default:
+	}
+	if c.startNext("page-1") != nil {
+		t.Errorf("same page prefetched twice")
+	}
+
+	c.viewChanged()
+	select {
+	case <-cancel:
+	default:
+		t.Fatalf("next page of previous view not cancelled")
+	}
+
+	cancel = c.startNext("page-2")
+	other := c.startNext("page-3")
+	select {
+	case <-cancel:
+	default:
+		t.Errorf("previous next page not cancelled")
+	}
+	c.doneNext(other)
+	if c.next != nil {
+		t.Errorf("next page not cleared when done")
+	}
+	c.stopPrefetch()
+}
@@ -37,6 +37,11 @@ type queueItem struct {
 
 	// priority is random number between 0-len(queue).
 	priority int
+
+	// group identifies songs that were added to queue at the same time.
+	group int
+	// source is where group was added from, e.g. album name. Empty if unknown.
+	source string
 }
 
 // queueList implements sort.Interface.
@@ -104,7 +109,7 @@ func (q *queueList) Clear(first bool) {
 	}
 }
 
-func (q *queueList) AddSong(song *models.Song, playNext bool, playFirst bool) {
+func (q *queueList) AddSong(song *models.Song, playNext bool, playFirst bool) *queueItem {
 	index := q.maxIndex
 	priority := rand.Int()
 	needsSort := false
@@ -143,6 +148,7 @@ func (q *queueList) AddSong(song *models.Song, playNext bool, playFirst bool) {
 	if needsSort {
 		sort.Sort(q)
 	}
+	return item
 }
 
 func (q *queueList) RemoveSong(index int) (song *models.Song) {
@@ -174,6 +180,31 @@ func (q *queueList) GetQueue() []*models.Song {
 	return songs
 }
 
+// GetSources returns source of each song in queue.
+func (q *queueList) GetSources() []interfaces.QueueSource {
+	sources := make([]interfaces.QueueSource, q.Len())
+	for i, v := range q.items {
+		sources[i] = interfaces.QueueSource{Group: v.group, Name: v.source}
+	}
+	return sources
+}
+
+// RemoveGroup removes songs of group, except first song, and returns number of removed songs.
+func (q *queueList) RemoveGroup(group int) int {
+	if q.Len() == 0 {
+		return 0
+	}
+	items := []*queueItem{q.items[0]}
+	for _, v := range q.items[1:] {
+		if v.group != group {
+			items = append(items, v)
+		}
+	}
+	removed := len(q.items) - len(items)
+	q.items = items
+	return removed
+}
+
 func (q *queueList) GetTotalDuration() interfaces.AudioTick {
 	ms := 0
 	for _, v := range q.items {
@@ -206,6 +237,9 @@ type Queue struct {
 	history            []*models.Song
 	queueUpdatedFunc   []func([]*models.Song)
 	historyUpdatedFunc func([]*models.Song)
+
+	// groups is the last group id given to songs added to queue.
+	groups int
 }
 
 func newQueue() *Queue {
@@ -235,12 +269,20 @@ func (q *Queue) ClearQueue(first bool) {
 // AddSongs adds songs to the end of queue.
 // Adding songs calls QueueChangedCallback.
 func (q *Queue) AddSongs(songs []*models.Song) {
+	q.AddSongsFrom("", songs)
+}
+
+// AddSongsFrom implements interfaces.QueueGroupController.
+func (q *Queue) AddSongsFrom(source string, songs []*models.Song) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 	defer q.notifyQueueUpdated()
 
+	q.groups++
 	for _, v := range songs {
-		q.list.AddSong(v, false, false)
+		item := q.list.AddSong(v, false, false)
+		item.group = q.groups
+		item.source = source
 	}
 
 	logrus.Debug("Adding songs to queue, current size: ", q.list.Len())
@@ -248,13 +290,32 @@ func (q *Queue) AddSongs(songs []*models.Song) {
 
 func (q *Queue) PlayNext(songs []*models.Song) {
 	q.lock.Lock()
+	q.groups++
 	for i := len(songs); i > 0; i-- {
-		q.list.AddSong(songs[i-1], true, false)
+		item := q.list.AddSong(songs[i-1], true, false)
+		item.group = q.groups
 	}
 	q.lock.Unlock()
 	q.notifyQueueUpdated()
 }
 
+// GetQueueSources implements interfaces.QueueGroupController.
+func (q *Queue) GetQueueSources() []interfaces.QueueSource {
+	q.lock.RLock()
+	defer q.lock.RUnlock()
+	return q.list.GetSources()
+}
+
+// RemoveGroup implements interfaces.QueueGroupController.
+func (q *Queue) RemoveGroup(group int) {
+	q.lock.Lock()
+	removed := q.list.RemoveGroup(group)
+	q.lock.Unlock()
+	if removed > 0 {
+		q.notifyQueueUpdated()
+	}
+}
+
 func (q *Queue) RemoveSong(index int) {
 	changed := false
 	q.lock.Lock()
@@ -365,7 +426,9 @@ func (q *Queue) playLastSong() {
 		return
 	}
 	song := q.history[0]
-	q.list.AddSong(song, false, true)
+	q.groups++
+	item := q.list.AddSong(song, false, true)
+	item.group = q.groups
 	if q.history == nil {
 		q.history = q.history[1:]
 	} else {
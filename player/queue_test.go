@@ -22,6 +22,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"reflect"
 	"testing"
+	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 )
 
@@ -651,3 +652,31 @@ func BenchmarkQueue_SetShuffle_10000(b *testing.B) {
 		q.SetShuffle(true)
 	}
 }
+
+func TestQueue_RemoveGroup(t *testing.T) {
+	songs := testSongs()
+	q := newQueue()
+	q.AddSongsFrom("first", songs[:2])
+	q.AddSongsFrom("second", songs[2:4])
+	q.AddSongs(songs[4:5])
+
+	sources := q.GetQueueSources()
+	want := []interfaces.QueueSource{{Group: 1, Name: "first"}, {Group: 1, Name: "first"},
+		{Group: 2, Name: "second"}, {Group: 2, Name: "second"}, {Group: 3}}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("GetQueueSources() = %v, want %v", sources, want)
+	}
+
+	q.RemoveGroup(2)
+	got := q.GetQueue()
+	if !reflect.DeepEqual(got, []*models.Song{songs[0], songs[1], songs[4]}) {
+		t.Errorf("RemoveGroup(2) = %v", got)
+	}
+
+	// playing song is not removed
+	q.RemoveGroup(1)
+	got = q.GetQueue()
+	if !reflect.DeepEqual(got, []*models.Song{songs[0], songs[4]}) {
+		t.Errorf("RemoveGroup(1) = %v", got)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"github.com/sirupsen/logrus"
+	"time"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/jellycli/storage"
+)
+
+const (
+	// scrobbleRetryInterval is how often queued scrobbles are retried, in addition to when connection is restored.
+	scrobbleRetryInterval = time.Minute * 10
+	// scrobbleBatch is max number of queued scrobbles sent at once.
+	scrobbleBatch = 50
+	// maxScrobbleAttempts is how many times server may reject scrobble before it is dropped.
+	maxScrobbleAttempts = 5
+	// scrobbleMinListenedS is listening time after which song is scrobbled even if less than half of it was played.
+	scrobbleMinListenedS = 240
+)
+
+// scrobbleStore keeps scrobbles that are waiting to be sent.
+type scrobbleStore interface {
+	QueueScrobble(server string, song models.Id, played time.Time) error
+	GetScrobbles(server string, limit int) ([]*storage.Scrobble, error)
+	RemoveScrobble(id int64) error
+	ScrobbleFailed(id int64, cause error) error
+}
+
+// scrobbles queues plays that could not be reported to server and sends them once server is reachable again.
+type scrobbles struct {
+	store     scrobbleStore
+	server    api.RemoteServer
+	serverId  string
+	scrobbler api.Scrobbler
+	// offline is set when server is an offline stand-in, and every play is queued for later.
+	offline bool
+	now     func() time.Time
+
+	retry chan bool
+	stop  chan bool
+}
+
+func newScrobbles(store scrobbleStore, server api.MediaServer) *scrobbles {
+	s := &scrobbles{
+		store:    store,
+		server:   server,
+		serverId: server.GetId(),
+		now:      time.Now,
+		retry:    make(chan bool, 1),
+		stop:     make(chan bool),
+	}
+	s.scrobbler, _ = server.(api.Scrobbler)
+	_, s.offline = server.(*api.Offline)
+	return s
+}
+
+// reported queues stopped song for scrobbling if reporting it to server failed and it was played long enough.
+func (s *scrobbles) reported(report *interfaces.ApiPlaybackState, err error) {
+	if report.Event != interfaces.EventStop || report.ItemId == "" || (err == nil && !s.offline) {
+		return
+	}
+	listened := report.Position.Seconds()
+	if listened < report.PlaylistLength/2 && listened < scrobbleMinListenedS {
+		return
+	}
+	played := s.now().Add(-time.Duration(listened) * time.Second)
+	err = s.store.QueueScrobble(s.serverId, models.Id(report.ItemId), played)
+	if err != nil {
+		logrus.Errorf("queue scrobble: %v", err)
+	}
+}
+
+// connectionChanged retries queued scrobbles when server is back online.
+func (s *scrobbles) connectionChanged(online bool) {
+	if online {
+		s.retryNow()
+	}
+}
+
+// retryNow sends queued scrobbles in background without waiting for next retry interval.
+func (s *scrobbles) retryNow() {
+	select {
+	case s.retry <- true:
+	default:
+	}
+}
+
+func (s *scrobbles) loop() {
+	ticker := time.NewTicker(scrobbleRetryInterval)
+	defer ticker.Stop()
+	s.send()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.send()
+		case <-s.retry:
+			s.send()
+		}
+	}
+}
+
+// send sends queued scrobbles, oldest first. Sending stops at first failure, as rest would likely fail too.
+func (s *scrobbles) send() {
+	if s.scrobbler == nil {
+		return
+	}
+	queued, err := s.store.GetScrobbles(s.serverId, scrobbleBatch)
+	if err != nil {
+		logrus.Errorf("get queued scrobbles: %v", err)
+		return
+	}
+	if len(queued) == 0 || s.server.ConnectionOk() != nil {
+		return
+	}
+	logrus.Debugf("send %d queued scrobbles", len(queued))
+	for _, v := range queued {
+		err = s.scrobbler.Scrobble(v.Song, v.Played)
+		if err == nil {
+			err = s.store.RemoveScrobble(v.Id)
+			if err != nil {
+				logrus.Errorf("remove sent scrobble: %v", err)
+				return
+			}
+			continue
+		}
+
+		logrus.Warningf("send queued scrobble of %s: %v", v.Song, err)
+		if v.Attempts+1 >= maxScrobbleAttempts {
+			logrus.Errorf("drop scrobble of %s after %d attempts", v.Song, maxScrobbleAttempts)
+			err = s.store.RemoveScrobble(v.Id)
+		} else {
+			err = s.store.ScrobbleFailed(v.Id, err)
+		}
+		if err != nil {
+			logrus.Errorf("update queued scrobble: %v", err)
+		}
+		return
+	}
+	if len(queued) == scrobbleBatch {
+		s.retryNow()
+	}
+}
+
+func (s *scrobbles) close() {
+	close(s.stop)
+}
@@ -0,0 +1,148 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/api"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/jellycli/storage"
+)
+
+// memScrobbles keeps queued scrobbles in memory.
+type memScrobbles struct {
+	scrobbles []*storage.Scrobble
+	nextId    int64
+}
+
+func (m *memScrobbles) QueueScrobble(server string, song models.Id, played time.Time) error {
+	m.nextId++
+	m.scrobbles = append(m.scrobbles, &storage.Scrobble{Id: m.nextId, Song: song, Played: played})
+	return nil
+}
+
+func (m *memScrobbles) GetScrobbles(server string, limit int) ([]*storage.Scrobble, error) {
+	if len(m.scrobbles) < limit {
+		limit = len(m.scrobbles)
+	}
+	return append([]*storage.Scrobble{}, m.scrobbles[:limit]...), nil
+}
+
+func (m *memScrobbles) RemoveScrobble(id int64) error {
+	for i, v := range m.scrobbles {
+		if v.Id == id {
+			m.scrobbles = append(m.scrobbles[:i], m.scrobbles[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func (m *memScrobbles) ScrobbleFailed(id int64, cause error) error {
+	for _, v := range m.scrobbles {
+		if v.Id == id {
+			v.Attempts++
+		}
+	}
+	return nil
+}
+
+// scrobbleServer records scrobbles and rejects songs in failing.
+type scrobbleServer struct {
+	api.MediaServer
+	offline   bool
+	failing   map[models.Id]bool
+	scrobbled []models.Id
+}
+
+func (s *scrobbleServer) GetId() string { return "server" }
+
+func (s *scrobbleServer) ConnectionOk() error {
+	if s.offline {
+		return errors.New("offline")
+	}
+	return nil
+}
+
+func (s *scrobbleServer) Scrobble(song models.Id, played time.Time) error {
+	if s.failing[song] {
+		return errors.New("server error")
+	}
+	s.scrobbled = append(s.scrobbled, song)
+	return nil
+}
+
+func TestScrobbles_reported(t *testing.T) {
+	store := &memScrobbles{}
+	s := newScrobbles(store, &scrobbleServer{})
+	now := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	failed := errors.New("offline")
+
+	s.reported(&interfaces.ApiPlaybackState{Event: interfaces.EventStop, ItemId: "1", PlaylistLength: 180,
+		Position: 100000}, failed)
+	// too short
+	s.reported(&interfaces.ApiPlaybackState{Event: interfaces.EventStop, ItemId: "2", PlaylistLength: 180,
+		Position: 30000}, failed)
+	// long song, scrobbled after 4 minutes
+	s.reported(&interfaces.ApiPlaybackState{Event: interfaces.EventStop, ItemId: "3", PlaylistLength: 1200,
+		Position: 240000}, failed)
+	// reported successfully
+	s.reported(&interfaces.ApiPlaybackState{Event: interfaces.EventStop, ItemId: "4", PlaylistLength: 180,
+		Position: 180000}, nil)
+	s.reported(&interfaces.ApiPlaybackState{Event: interfaces.EventTimeUpdate, ItemId: "5", PlaylistLength: 180,
+		Position: 180000}, failed)
+
+	if len(store.scrobbles) != 2 || store.scrobbles[0].Song != "1" || store.scrobbles[1].Song != "3" {
+		t.Fatalf("queued %+v, want songs 1 and 3", store.scrobbles)
+	}
+	if played := now.Add(-100 * time.Second); !store.scrobbles[0].Played.Equal(played) {
+		t.Errorf("song 1 played at %s, want %s", store.scrobbles[0].Played, played)
+	}
+}
+
+func TestScrobbles_send(t *testing.T) {
+	store := &memScrobbles{}
+	server := &scrobbleServer{offline: true, failing: map[models.Id]bool{"2": true}}
+	s := newScrobbles(store, server)
+	for _, v := range []models.Id{"1", "2", "3"} {
+		store.QueueScrobble("server", v, time.Now())
+	}
+
+	s.send()
+	if len(server.scrobbled) != 0 {
+		t.Fatalf("scrobbled %v while offline", server.scrobbled)
+	}
+
+	server.offline = false
+	s.send()
+	if len(server.scrobbled) != 1 || len(store.scrobbles) != 2 || store.scrobbles[0].Attempts != 1 {
+		t.Fatalf("scrobbled %v, queue %+v, want 1 sent and 2 failed once", server.scrobbled, store.scrobbles)
+	}
+
+	for i := 1; i < maxScrobbleAttempts; i++ {
+		s.send()
+	}
+	s.send()
+	if len(server.scrobbled) != 2 || server.scrobbled[1] != "3" || len(store.scrobbles) != 0 {
+		t.Errorf("scrobbled %v, queue %+v, want 2 dropped and 3 sent", server.scrobbled, store.scrobbles)
+	}
+}
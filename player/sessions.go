@@ -0,0 +1,48 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// GetSessions implements interfaces.SessionController.
+func (p *Player) GetSessions() ([]*models.Session, error) {
+	if p.sessions == nil {
+		return nil, interfaces.ErrSessionsNotSupported
+	}
+	return p.sessions.GetSessions()
+}
+
+// PlaySessionSongs implements interfaces.SessionController.
+func (p *Player) PlaySessionSongs(session models.Id, songs []models.Id, playNow bool) error {
+	if p.sessions == nil {
+		return interfaces.ErrSessionsNotSupported
+	}
+	return p.sessions.PlaySessionSongs(session, songs, playNow)
+}
+
+// SendSessionCommand implements interfaces.SessionController.
+func (p *Player) SendSessionCommand(session models.Id, command interfaces.SessionCommand) error {
+	if p.sessions == nil {
+		return interfaces.ErrSessionsNotSupported
+	}
+	return p.sessions.SendSessionCommand(session, command)
+}
@@ -0,0 +1,65 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// GetSyncPlayGroups implements interfaces.SyncPlayController.
+func (p *Player) GetSyncPlayGroups() ([]*models.SyncPlayGroup, error) {
+	if p.syncPlay == nil {
+		return nil, interfaces.ErrSyncPlayNotSupported
+	}
+	return p.syncPlay.GetSyncPlayGroups()
+}
+
+// NewSyncPlayGroup implements interfaces.SyncPlayController.
+func (p *Player) NewSyncPlayGroup(name string) error {
+	if p.syncPlay == nil {
+		return interfaces.ErrSyncPlayNotSupported
+	}
+	return p.syncPlay.NewSyncPlayGroup(name)
+}
+
+// JoinSyncPlayGroup implements interfaces.SyncPlayController.
+func (p *Player) JoinSyncPlayGroup(id models.Id) error {
+	if p.syncPlay == nil {
+		return interfaces.ErrSyncPlayNotSupported
+	}
+	return p.syncPlay.JoinSyncPlayGroup(id)
+}
+
+// LeaveSyncPlayGroup implements interfaces.SyncPlayController.
+func (p *Player) LeaveSyncPlayGroup() error {
+	if p.syncPlay == nil {
+		return interfaces.ErrSyncPlayNotSupported
+	}
+	return p.syncPlay.LeaveSyncPlayGroup()
+}
+
+// AddSyncPlayCallback implements interfaces.SyncPlayController. If server does not support SyncPlay,
+// callback is never called.
+func (p *Player) AddSyncPlayCallback(cb func(group *models.SyncPlayGroup)) {
+	if p.syncPlay == nil {
+		return
+	}
+	p.syncPlay.AddSyncPlayCallback(cb)
+}
@@ -0,0 +1,74 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"errors"
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+	"math"
+	"time"
+	"tryffel.net/go/jellycli/config"
+)
+
+const (
+	testToneFrequency = 440
+	testToneVolume    = 0.2
+)
+
+// PlayTestTone plays a short sine tone to verify that audio output works. It returns after
+// tone has been played. Speaker is initialized, so this must not be called while player is running.
+func PlayTestTone(duration time.Duration) error {
+	err := initAudio()
+	if err != nil {
+		return err
+	}
+	sampleRate := beep.SampleRate(config.AudioSamplingRate)
+	done := make(chan bool)
+	speaker.Play(beep.Seq(beep.Take(sampleRate.N(duration), sineTone(sampleRate, testToneFrequency)),
+		beep.Callback(func() {
+			close(done)
+		})))
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(duration + time.Second*2):
+		speaker.Clear()
+		return errors.New("audio device did not play test tone")
+	}
+}
+
+// sineTone returns endless sine wave of given frequency.
+func sineTone(sampleRate beep.SampleRate, frequency float64) beep.Streamer {
+	step := 2 * math.Pi * frequency / float64(sampleRate)
+	var phase float64
+	return beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		for i := range samples {
+			value := math.Sin(phase) * testToneVolume
+			samples[i][0] = value
+			samples[i][1] = value
+			phase += step
+			if phase > 2*math.Pi {
+				phase -= 2 * math.Pi
+			}
+		}
+		return len(samples), true
+	})
+}
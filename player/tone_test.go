@@ -0,0 +1,44 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_sineTone(t *testing.T) {
+	// 4 samples per period: 0, max, 0, min
+	tone := sineTone(400, 100)
+	samples := make([][2]float64, 10)
+	n, ok := tone.Stream(samples)
+	if n != len(samples) || !ok {
+		t.Fatalf("stream, got %d %t, want %d true", n, ok, len(samples))
+	}
+
+	want := []float64{0, testToneVolume, 0, -testToneVolume}
+	for i, v := range samples {
+		if v[0] != v[1] {
+			t.Errorf("sample %d: channels differ: %f, %f", i, v[0], v[1])
+		}
+		if math.Abs(v[0]-want[i%4]) > 1e-9 {
+			t.Errorf("sample %d: got %f, want %f", i, v[0], want[i%4])
+		}
+	}
+}
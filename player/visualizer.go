@@ -0,0 +1,165 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"github.com/faiface/beep"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// number of latest samples used for spectrum
+	visualizerSamples = 1024
+
+	visualizerMinFrequency = 50
+	visualizerMaxFrequency = 12000
+
+	// levels below this are drawn as silence
+	visualizerMinDb = -60
+)
+
+// visualizer passes audio through and keeps latest samples for spectrum analysis. Samples are
+// only collected when visualizer is enabled, since it costs cpu.
+type visualizer struct {
+	Streamer beep.Streamer
+
+	enabled int32
+
+	lock       sync.Mutex
+	samples    []float64
+	pos        int
+	sampleRate int
+}
+
+func newVisualizer(streamer beep.Streamer, sampleRate int) *visualizer {
+	return &visualizer{
+		Streamer:   streamer,
+		samples:    make([]float64, visualizerSamples),
+		sampleRate: sampleRate,
+	}
+}
+
+func (v *visualizer) Stream(samples [][2]float64) (int, bool) {
+	n, ok := v.Streamer.Stream(samples)
+	if atomic.LoadInt32(&v.enabled) == 0 {
+		return n, ok
+	}
+
+	v.lock.Lock()
+	for _, sample := range samples[:n] {
+		v.samples[v.pos] = (sample[0] + sample[1]) / 2
+		v.pos = (v.pos + 1) % len(v.samples)
+	}
+	v.lock.Unlock()
+	return n, ok
+}
+
+func (v *visualizer) Err() error {
+	return v.Streamer.Err()
+}
+
+func (v *visualizer) setEnabled(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&v.enabled, 1)
+		return
+	}
+	atomic.StoreInt32(&v.enabled, 0)
+	v.lock.Lock()
+	for i := range v.samples {
+		v.samples[i] = 0
+	}
+	v.lock.Unlock()
+}
+
+func (v *visualizer) setSampleRate(sampleRate int) {
+	v.lock.Lock()
+	v.sampleRate = sampleRate
+	v.lock.Unlock()
+}
+
+// spectrum returns levels of latest samples in given number of bands.
+func (v *visualizer) spectrum(bands int) []float64 {
+	v.lock.Lock()
+	samples := make([]float64, len(v.samples))
+	copy(samples, v.samples[v.pos:])
+	copy(samples[len(v.samples)-v.pos:], v.samples[:v.pos])
+	sampleRate := v.sampleRate
+	v.lock.Unlock()
+	return spectrumLevels(samples, sampleRate, bands)
+}
+
+// spectrumLevels calculates levels in range [0,1] for logarithmically spaced frequency bands
+// from low to high frequencies.
+func spectrumLevels(samples []float64, sampleRate int, bands int) []float64 {
+	levels := make([]float64, bands)
+	if len(samples) == 0 || sampleRate <= 0 || bands <= 0 {
+		return levels
+	}
+
+	windowed := make([]float64, len(samples))
+	for i, v := range samples {
+		// hann window
+		windowed[i] = v * 0.5 * (1 - math.Cos(2*math.Pi*float64(i)/float64(len(samples)-1)))
+	}
+
+	maxFrequency := math.Min(visualizerMaxFrequency, float64(sampleRate)/2)
+	ratio := math.Pow(maxFrequency/visualizerMinFrequency, 1/float64(bands))
+	binWidth := float64(sampleRate) / float64(len(samples))
+	for i := range levels {
+		low := visualizerMinFrequency * math.Pow(ratio, float64(i))
+		high := low * ratio
+		// use loudest frequency bin in band, or nearest bin if band is narrower than a bin.
+		first := int(math.Ceil(low / binWidth))
+		last := int(math.Floor(high / binWidth))
+		if last < first {
+			first = int(math.Round(math.Sqrt(low*high) / binWidth))
+			last = first
+		}
+		magnitude := 0.0
+		for bin := first; bin <= last; bin++ {
+			magnitude = math.Max(magnitude, goertzel(windowed, float64(bin)/float64(len(samples))))
+		}
+		// amplitude of full-scale sine is 1, hann window halves the magnitude
+		amplitude := 4 * magnitude / float64(len(samples))
+		if amplitude <= 0 {
+			continue
+		}
+		level := (20*math.Log10(amplitude) - visualizerMinDb) / -visualizerMinDb
+		levels[i] = math.Max(0, math.Min(1, level))
+	}
+	return levels
+}
+
+// goertzel returns magnitude of samples at given frequency, which is relative to sample rate.
+func goertzel(samples []float64, frequency float64) float64 {
+	coeff := 2 * math.Cos(2*math.Pi*frequency)
+	var s1, s2 float64
+	for _, v := range samples {
+		s := v + coeff*s1 - s2
+		s2 = s1
+		s1 = s
+	}
+	power := s1*s1 + s2*s2 - coeff*s1*s2
+	if power < 0 {
+		return 0
+	}
+	return math.Sqrt(power)
+}
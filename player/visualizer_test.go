@@ -0,0 +1,63 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_spectrumLevels(t *testing.T) {
+	sampleRate := 44100
+	samples := make([]float64, visualizerSamples)
+	for i := range samples {
+		samples[i] = math.Sin(2 * math.Pi * 1000 * float64(i) / float64(sampleRate))
+	}
+
+	levels := spectrumLevels(samples, sampleRate, 16)
+	if len(levels) != 16 {
+		t.Fatalf("levels count, got %d, want 16", len(levels))
+	}
+
+	loudest := 0
+	for i, v := range levels {
+		if v < 0 || v > 1 {
+			t.Errorf("level %d out of range: %f", i, v)
+		}
+		if v > levels[loudest] {
+			loudest = i
+		}
+	}
+
+	ratio := math.Pow(visualizerMaxFrequency/visualizerMinFrequency, 1.0/16)
+	low := visualizerMinFrequency * math.Pow(ratio, float64(loudest))
+	if low > 1000 || low*ratio < 1000 {
+		t.Errorf("loudest band %d (%.0f-%.0f Hz) does not contain 1000 Hz", loudest, low, low*ratio)
+	}
+	if levels[loudest] < 0.9 {
+		t.Errorf("full scale sine level, got %f, want > 0.9", levels[loudest])
+	}
+
+	silence := spectrumLevels(make([]float64, visualizerSamples), sampleRate, 16)
+	for i, v := range silence {
+		if v != 0 {
+			t.Errorf("silence level %d, got %f, want 0", i, v)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"os"
+	"strings"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+)
+
+// defaultAudioOutput is the output name used when output is not selected with environment variables.
+const defaultAudioOutput = "default"
+
+// audioOutput returns name of current audio output, that volume is remembered for.
+// Output is selected with PULSE_SINK for PulseAudio or AUDIODEV for ALSA and OSS.
+func audioOutput() string {
+	for _, key := range []string{"PULSE_SINK", "AUDIODEV"} {
+		if output := strings.TrimSpace(os.Getenv(key)); output != "" {
+			return outputKey(output)
+		}
+	}
+	return defaultAudioOutput
+}
+
+// outputKey converts output name to config key. Config keys are case-insensitive and
+// dots separate nested keys, so sink names like 'alsa_output.usb-1.analog-stereo' are mangled.
+func outputKey(output string) string {
+	output = strings.ToLower(output)
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == ' ' || r == '\t' {
+			return '_'
+		}
+		return r
+	}, output)
+}
+
+// restoreVolume sets volume to last volume of output, if there is one.
+func (a *Audio) restoreVolume(volumes map[string]int, output string) {
+	volume, ok := volumes[output]
+	if !ok {
+		return
+	}
+	a.SetVolume(interfaces.AudioVolume(volume))
+}
+
+// RememberVolume stores current volume of current audio output to config,
+// so that it is restored on next startup. Config needs to be saved separately.
+func (p *Player) RememberVolume() {
+	volume := int(p.Audio.getStatus().Volume)
+	conf := &config.AppConfig.Player
+	if conf.Volumes == nil {
+		conf.Volumes = map[string]int{}
+	}
+	conf.Volumes[audioOutput()] = volume
+}
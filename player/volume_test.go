@@ -0,0 +1,81 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package player
+
+import (
+	"os"
+	"testing"
+)
+
+func Test_audioOutput(t *testing.T) {
+	tests := []struct {
+		name      string
+		pulseSink string
+		audioDev  string
+		want      string
+	}{
+		{name: "default", want: "default"},
+		{name: "pulse sink", pulseSink: "alsa_output.usb-Schiit.analog-stereo", audioDev: "hw:1",
+			want: "alsa_output_usb-schiit_analog-stereo"},
+		{name: "audio device", audioDev: "hw:1,0", want: "hw:1,0"},
+		{name: "whitespace", pulseSink: "  ", want: "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setEnv("PULSE_SINK", tt.pulseSink)()
+			defer setEnv("AUDIODEV", tt.audioDev)()
+			if got := audioOutput(); got != tt.want {
+				t.Errorf("audioOutput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAudio_restoreVolume(t *testing.T) {
+	volumes := map[string]int{"default": 30, "headphones": 80}
+
+	a := newAudio()
+	a.restoreVolume(volumes, "headphones")
+	if a.status.Volume != 80 {
+		t.Errorf("restored volume = %d, want 80", a.status.Volume)
+	}
+
+	a = newAudio()
+	a.restoreVolume(volumes, "speakers")
+	if a.status.Volume != 50 {
+		t.Errorf("unknown output volume = %d, want 50", a.status.Volume)
+	}
+}
+
+// setEnv sets environment variable and returns function that restores it. Empty value unsets variable.
+func setEnv(key, value string) func() {
+	old, ok := os.LookupEnv(key)
+	if value == "" {
+		os.Unsetenv(key)
+	} else {
+		os.Setenv(key, value)
+	}
+	return func() {
+		if ok {
+			os.Setenv(key, old)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/history"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/jellycli/storage/migrations"
+)
+
+const historySchemaLevel = 1
+
+// History stores played songs and scrobbles that are waiting to be sent to server.
+// Unlike Db, history is not a cache of single server. It is kept across servers and is
+// never cleared by syncing.
+type History struct {
+	file   string
+	engine *sqlx.DB
+}
+
+// Scrobble is a play that could not be scrobbled to server yet.
+type Scrobble struct {
+	Id       int64
+	Song     models.Id
+	Played   time.Time
+	Attempts int
+}
+
+func newHistory(file string) (*History, error) {
+	var err error
+	logrus.Debugf("use play history: %s", file)
+	h := &History{file: file}
+	h.engine, err = sqlx.Connect("sqlite3", fmt.Sprintf("file:%s?_fk=true", file))
+	if err != nil {
+		return nil, err
+	}
+
+	level := -1
+	err = h.engine.Get(&level, "SELECT * FROM schema;")
+	if err != nil && strings.Contains(err.Error(), "no such table") {
+		err = h.initDb()
+	} else if err == nil && level != historySchemaLevel {
+		err = fmt.Errorf("history schema is invalid: supported %d, database: %d", historySchemaLevel, level)
+	}
+	if err != nil {
+		h.engine.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+// NewHistory opens play history in local cache dir.
+func NewHistory() (*History, error) {
+	err := os.Mkdir(config.AppConfig.Player.LocalCacheDir, 0760)
+	if err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("create cache dir: %v", err)
+	}
+	fileName := path.Join(config.AppConfig.Player.LocalCacheDir, "history.db")
+	return newHistory(fileName)
+}
+
+func (h *History) initDb() error {
+	tx, err := h.engine.Beginx()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(migrations.HistoryV1)
+	if err == nil {
+		_, err = tx.Exec("INSERT INTO schema VALUES (?)", historySchemaLevel)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (h *History) Close() error {
+	return h.engine.Close()
+}
+
+// AddPlay implements history.Store.
+func (h *History) AddPlay(play *history.Play) error {
+	sql := `INSERT INTO plays (song_id, title, artist, album, artist_id, album_id, started, duration, listened,
+completion) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+	_, err := h.engine.Exec(sql, play.Id.String(), play.Title, play.Artist, play.Album, play.ArtistId.String(),
+		play.AlbumId.String(), sqlTime{play.Started}, play.Duration, play.Listened, play.Completion)
+	return err
+}
+
+type playRow struct {
+	Id         string  `db:"song_id"`
+	Title      string  `db:"title"`
+	Artist     string  `db:"artist"`
+	Album      string  `db:"album"`
+	ArtistId   string  `db:"artist_id"`
+	AlbumId    string  `db:"album_id"`
+	Started    sqlTime `db:"started"`
+	Duration   int     `db:"duration"`
+	Listened   int     `db:"listened"`
+	Completion float64 `db:"completion"`
+}
+
+func (p *playRow) toPlay() *history.Play {
+	return &history.Play{
+		Id:         models.Id(p.Id),
+		Title:      p.Title,
+		Artist:     p.Artist,
+		Album:      p.Album,
+		ArtistId:   models.Id(p.ArtistId),
+		AlbumId:    models.Id(p.AlbumId),
+		Started:    p.Started.Time,
+		Duration:   p.Duration,
+		Listened:   p.Listened,
+		Completion: p.Completion,
+	}
+}
+
+func (h *History) selectPlays(sql string, args ...interface{}) ([]*history.Play, error) {
+	rows := []playRow{}
+	err := h.engine.Select(&rows, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	plays := make([]*history.Play, len(rows))
+	for i := range rows {
+		plays[i] = rows[i].toPlay()
+	}
+	return plays, nil
+}
+
+// GetPlays returns plays that started at or after since, oldest first.
+func (h *History) GetPlays(since time.Time) ([]*history.Play, error) {
+	sql := `SELECT song_id, title, artist, album, artist_id, album_id, started, duration, listened, completion
+FROM plays WHERE started >= ? ORDER BY started, id;`
+	return h.selectPlays(sql, sqlTime{since})
+}
+
+// GetRecentPlays returns page of plays, latest first, and total number of plays.
+func (h *History) GetRecentPlays(paging interfaces.Paging) ([]*history.Play, int, error) {
+	sql := `SELECT song_id, title, artist, album, artist_id, album_id, started, duration, listened, completion
+FROM plays ORDER BY started DESC, id DESC LIMIT ? OFFSET ?;`
+	plays, err := h.selectPlays(sql, paging.PageSize, paging.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	count := 0
+	err = h.engine.Get(&count, "SELECT COUNT(id) FROM plays;")
+	return plays, count, err
+}
+
+// GetPlayStats returns summary of all plays and pending scrobbles.
+func (h *History) GetPlayStats() (models.PlayStats, error) {
+	stats := models.PlayStats{}
+	sql := `SELECT COUNT(id), COUNT(DISTINCT song_id), COALESCE(SUM(listened), 0), COALESCE(AVG(completion), 0)
+FROM plays;`
+	err := h.engine.QueryRowx(sql).Scan(&stats.Plays, &stats.Songs, &stats.Listened, &stats.Completion)
+	if err != nil {
+		return stats, err
+	}
+	err = h.engine.Get(&stats.PendingScrobbles, "SELECT COUNT(id) FROM scrobbles;")
+	return stats, err
+}
+
+// QueueScrobble adds song played at given time to scrobbles waiting to be sent to server.
+func (h *History) QueueScrobble(server string, song models.Id, played time.Time) error {
+	_, err := h.engine.Exec("INSERT INTO scrobbles (server, song_id, played) VALUES (?, ?, ?);",
+		server, song.String(), sqlTime{played})
+	return err
+}
+
+// GetScrobbles returns at most limit scrobbles queued for server, oldest first.
+func (h *History) GetScrobbles(server string, limit int) ([]*Scrobble, error) {
+	rows := []struct {
+		Id       int64   `db:"id"`
+		Song     string  `db:"song_id"`
+		Played   sqlTime `db:"played"`
+		Attempts int     `db:"attempts"`
+	}{}
+	sql := "SELECT id, song_id, played, attempts FROM scrobbles WHERE server = ? ORDER BY played, id LIMIT ?;"
+	err := h.engine.Select(&rows, sql, server, limit)
+	if err != nil {
+		return nil, err
+	}
+	scrobbles := make([]*Scrobble, len(rows))
+	for i, v := range rows {
+		scrobbles[i] = &Scrobble{
+			Id:       v.Id,
+			Song:     models.Id(v.Song),
+			Played:   v.Played.Time,
+			Attempts: v.Attempts,
+		}
+	}
+	return scrobbles, nil
+}
+
+// RemoveScrobble removes scrobble from queue, after it has been sent or given up.
+func (h *History) RemoveScrobble(id int64) error {
+	_, err := h.engine.Exec("DELETE FROM scrobbles WHERE id = ?;", id)
+	return err
+}
+
+// ScrobbleFailed records failed attempt to send scrobble.
+func (h *History) ScrobbleFailed(id int64, cause error) error {
+	_, err := h.engine.Exec("UPDATE scrobbles SET attempts = attempts + 1, last_error = ? WHERE id = ?;",
+		cause.Error(), id)
+	return err
+}
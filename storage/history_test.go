@@ -0,0 +1,131 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package storage
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/history"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func testHistory(t *testing.T) *History {
+	h, err := newHistory(path.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("init history: %v", err)
+	}
+	return h
+}
+
+func TestHistory_Plays(t *testing.T) {
+	h := testHistory(t)
+	defer h.Close()
+
+	day := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	plays := []*history.Play{
+		{Id: "1", Title: "a", Artist: "artist", Album: "album", ArtistId: "10", AlbumId: "20", Started: day, Duration: 200, Listened: 200,
+			Completion: 1},
+		{Id: "2", Title: "b", Started: day.Add(time.Hour), Duration: 100, Listened: 50, Completion: 0.5},
+		{Id: "1", Title: "a", Started: day.Add(2 * time.Hour), Duration: 200, Listened: 0, Completion: 0},
+	}
+	for _, v := range plays {
+		if err := h.AddPlay(v); err != nil {
+			t.Fatalf("add play: %v", err)
+		}
+	}
+
+	got, err := h.GetPlays(day.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("get plays: %v", err)
+	}
+	for _, v := range got {
+		v.Started = v.Started.UTC()
+	}
+	if !reflect.DeepEqual(got, plays[1:]) {
+		t.Errorf("GetPlays() = %+v, want %+v", got, plays[1:])
+	}
+
+	paging := interfaces.Paging{PageSize: 2, CurrentPage: 0}
+	recent, total, err := h.GetRecentPlays(paging)
+	if err != nil {
+		t.Fatalf("get recent plays: %v", err)
+	}
+	if total != 3 || len(recent) != 2 || recent[0].Title != "a" || recent[1].Title != "b" {
+		t.Errorf("GetRecentPlays() = %+v, %d, want latest 2 of 3 plays", recent, total)
+	}
+
+	stats, err := h.GetPlayStats()
+	if err != nil {
+		t.Fatalf("get stats: %v", err)
+	}
+	want := models.PlayStats{Plays: 3, Songs: 2, Listened: 250, Completion: 0.5}
+	if stats != want {
+		t.Errorf("GetPlayStats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestHistory_Scrobbles(t *testing.T) {
+	h := testHistory(t)
+	defer h.Close()
+
+	day := time.Date(2020, 11, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []models.Id{"2", "1"} {
+		if err := h.QueueScrobble("server", id, day.Add(-time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("queue scrobble: %v", err)
+		}
+	}
+
+	if err := h.QueueScrobble("other", "3", day.Add(-2*time.Hour)); err != nil {
+		t.Fatalf("queue scrobble: %v", err)
+	}
+
+	scrobbles, err := h.GetScrobbles("server", 10)
+	if err != nil {
+		t.Fatalf("get scrobbles: %v", err)
+	}
+	if len(scrobbles) != 2 || scrobbles[0].Song != "1" || !scrobbles[0].Played.Equal(day.Add(-time.Hour)) {
+		t.Fatalf("GetScrobbles() = %+v, want oldest first", scrobbles)
+	}
+
+	if err := h.ScrobbleFailed(scrobbles[0].Id, errors.New("timeout")); err != nil {
+		t.Fatalf("scrobble failed: %v", err)
+	}
+	if err := h.RemoveScrobble(scrobbles[1].Id); err != nil {
+		t.Fatalf("remove scrobble: %v", err)
+	}
+	scrobbles, err = h.GetScrobbles("server", 10)
+	if err != nil {
+		t.Fatalf("get scrobbles: %v", err)
+	}
+	if len(scrobbles) != 1 || scrobbles[0].Song != "1" || scrobbles[0].Attempts != 1 {
+		t.Errorf("GetScrobbles() = %+v, want song 1 with 1 attempt", scrobbles)
+	}
+
+	stats, err := h.GetPlayStats()
+	if err != nil {
+		t.Fatalf("get stats: %v", err)
+	}
+	if stats.PendingScrobbles != 2 {
+		t.Errorf("pending scrobbles = %d, want 2", stats.PendingScrobbles)
+	}
+}
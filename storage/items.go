@@ -20,6 +20,7 @@ package storage
 
 import (
 	"fmt"
+	"github.com/Masterminds/squirrel"
 	"github.com/sirupsen/logrus"
 	"time"
 	"tryffel.net/go/jellycli/interfaces"
@@ -107,6 +108,7 @@ func (db *Db) GetArtists(query *interfaces.QueryOpts) (artists []*models.Artist,
 			stmt = stmt.Where("favorite = TRUE")
 		}
 	}
+	stmt = filterByName(stmt, query.Filter)
 
 	if query.Sort.Field != "" {
 		mode := query.Sort.Mode
@@ -143,8 +145,11 @@ func (db *Db) GetArtists(query *interfaces.QueryOpts) (artists []*models.Artist,
 		artists[i] = &(*a)[i]
 	}
 
-	sql = "SELECT COUNT(id) FROM ARTISTS"
-	err = db.engine.Get(&count, sql)
+	sql, args, err = filterByName(db.builder.Select("COUNT(id)").From("artists"), query.Filter).ToSql()
+	if err != nil {
+		return
+	}
+	err = db.engine.Get(&count, sql, args...)
 	return
 }
 
@@ -203,20 +208,15 @@ func (db *Db) UpdateAlbums(albums []*models.Album) error {
 }
 
 func (db *Db) GetAlbums(query *interfaces.QueryOpts) (albums []*models.Album, n int, err error) {
-	stmt := db.builder.
-		Select("*").From("albums")
-
-	if !query.Filter.Empty() {
-		if query.Filter.Favorite {
-			stmt = stmt.Where("favorite = TRUE")
-		}
-	}
+	stmt := filterAlbums(db.builder.Select("*").From("albums"), query.Filter)
 
 	if query.Sort.Field != "" {
 		mode := query.Sort.Mode
 		switch query.Sort.Field {
 		case interfaces.SortByName:
 			stmt = stmt.OrderBy("name " + mode)
+		case interfaces.SortByDate:
+			stmt = stmt.OrderBy("year "+mode, "name "+mode)
 		case interfaces.SortByRandom:
 			stmt = stmt.OrderBy("RANDOM()")
 		default:
@@ -247,8 +247,11 @@ func (db *Db) GetAlbums(query *interfaces.QueryOpts) (albums []*models.Album, n
 		albums[i] = &(*a)[i]
 	}
 
-	sql = "SELECT COUNT(id) FROM albums"
-	err = db.engine.Get(&n, sql)
+	sql, args, err = filterAlbums(db.builder.Select("COUNT(id)").From("albums"), query.Filter).ToSql()
+	if err != nil {
+		return
+	}
+	err = db.engine.Get(&n, sql, args...)
 	return
 }
 
@@ -302,13 +305,28 @@ func (db *Db) UpdateSongs(songs []*models.Song) error {
 	return nil
 }
 
-func (db *Db) GetSongs(page int, pageSize int) ([]*models.Song, int, error) {
+func (db *Db) GetSongs(query *interfaces.QueryOpts) ([]*models.Song, int, error) {
 	stmt := db.builder.
-		Select("*").From("songs")
+		Select("songs.*").From("songs").
+		LeftJoin("albums ON albums.id = songs.album").
+		LeftJoin("artists ON artists.id = albums.artist")
 
-	stmt = stmt.Offset(uint64(page * pageSize))
-	stmt = stmt.Limit(uint64(pageSize))
-	stmt = stmt.OrderBy("name")
+	stmt = stmt.Offset(uint64(query.Paging.Offset()))
+	stmt = stmt.Limit(uint64(query.Paging.PageSize))
+
+	mode := query.Sort.Mode
+	switch query.Sort.Field {
+	case interfaces.SortByRandom:
+		stmt = stmt.OrderBy("RANDOM()")
+	case interfaces.SortByAlbum:
+		stmt = stmt.OrderBy("albums.name "+mode, "songs.disc_number", "songs.song_index")
+	case interfaces.SortByArtist:
+		stmt = stmt.OrderBy("artists.name "+mode, "albums.name", "songs.disc_number", "songs.song_index")
+	case interfaces.SortByDate:
+		stmt = stmt.OrderBy("albums.year "+mode, "albums.name", "songs.disc_number", "songs.song_index")
+	default:
+		stmt = stmt.OrderBy("songs.name " + mode)
+	}
 
 	var sql string
 	var args []interface{}
@@ -443,3 +461,187 @@ func (db *Db) GetPlaylists() ([]*models.Playlist, error) {
 	}
 	return playlists, nil
 }
+
+// GetArtist returns single artist.
+func (db *Db) GetArtist(id models.Id) (*models.Artist, error) {
+	artist := &models.Artist{}
+	err := db.engine.Get(artist, "SELECT * FROM artists WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	return artist, nil
+}
+
+// GetAlbum returns single album.
+func (db *Db) GetAlbum(id models.Id) (*models.Album, error) {
+	album := &models.Album{}
+	err := db.engine.Get(album, "SELECT * FROM albums WHERE id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	return album, nil
+}
+
+// GetArtistAlbums returns albums where artist is the album artist, oldest first.
+func (db *Db) GetArtistAlbums(artist models.Id) ([]*models.Album, error) {
+	stmt := db.builder.Select("*").From("albums").
+		Where("artist = ?", artist).
+		OrderBy("year", "name")
+	return db.selectAlbums(stmt)
+}
+
+// GetAlbumSongs returns songs for album in album order.
+func (db *Db) GetAlbumSongs(album models.Id) ([]*models.Song, error) {
+	stmt := db.songs().
+		Where("s.album = ?", album).
+		OrderBy("s.disc_number", "s.song_index", "s.name")
+	return db.selectSongs(stmt)
+}
+
+// GetPlaylistSongs returns songs for playlist in playlist order.
+func (db *Db) GetPlaylistSongs(playlist models.Id) ([]*models.Song, error) {
+	stmt := db.songs().
+		Join("playlist_songs ps ON ps.song = s.id").
+		Where("ps.playlist = ?", playlist).
+		OrderBy("ps.playlist_index")
+	return db.selectSongs(stmt)
+}
+
+// Search returns items of itemType whose name contains query, max limit items.
+func (db *Db) Search(itemType models.ItemType, query string, limit int) ([]models.Item, error) {
+	like := "%" + query + "%"
+	items := make([]models.Item, 0)
+
+	switch itemType {
+	case models.TypeArtist:
+		artists, err := db.selectArtists(db.builder.Select("*").From("artists").
+			Where("name LIKE ?", like).OrderBy("name").Limit(uint64(limit)))
+		if err != nil {
+			return items, err
+		}
+		for _, v := range artists {
+			items = append(items, v)
+		}
+	case models.TypeAlbum:
+		albums, err := db.selectAlbums(db.builder.Select("*").From("albums").
+			Where("name LIKE ?", like).OrderBy("name").Limit(uint64(limit)))
+		if err != nil {
+			return items, err
+		}
+		for _, v := range albums {
+			items = append(items, v)
+		}
+	case models.TypeSong:
+		songs, err := db.selectSongs(db.songs().
+			Where("s.name LIKE ?", like).OrderBy("s.name").Limit(uint64(limit)))
+		if err != nil {
+			return items, err
+		}
+		for _, v := range songs {
+			items = append(items, v)
+		}
+	case models.TypePlaylist:
+		playlists := &[]models.Playlist{}
+		sql, args, err := db.builder.Select("pl.id AS id", "pl.name AS name", "COUNT(ps.song) AS song_count").
+			From("playlists pl").
+			LeftJoin("playlist_songs ps ON pl.id = ps.playlist").
+			Where("pl.name LIKE ?", like).
+			GroupBy("pl.id").OrderBy("pl.name").Limit(uint64(limit)).ToSql()
+		if err != nil {
+			return items, err
+		}
+		err = db.engine.Select(playlists, sql, args...)
+		if err != nil {
+			return items, err
+		}
+		for i, _ := range *playlists {
+			items = append(items, &(*playlists)[i])
+		}
+	default:
+		return items, fmt.Errorf("search %s not supported", itemType)
+	}
+	return items, nil
+}
+
+// songs returns statement for selecting songs as s. Songs table does not store artist,
+// so album artist is taken from album.
+func (db *Db) songs() squirrel.SelectBuilder {
+	return db.builder.
+		Select("s.id", "s.name", "s.duration", "s.song_index", "s.disc_number", "s.favorite", "s.album",
+			"COALESCE(a.artist, '') AS artist").
+		From("songs s").
+		LeftJoin("albums a ON s.album = a.id")
+}
+
+func (db *Db) selectArtists(stmt squirrel.SelectBuilder) ([]*models.Artist, error) {
+	sql, args, err := stmt.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	a := &[]models.Artist{}
+	err = db.engine.Select(a, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	artists := make([]*models.Artist, len(*a))
+	for i, _ := range *a {
+		artists[i] = &(*a)[i]
+	}
+	return artists, nil
+}
+
+func (db *Db) selectAlbums(stmt squirrel.SelectBuilder) ([]*models.Album, error) {
+	sql, args, err := stmt.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	a := &[]models.Album{}
+	err = db.engine.Select(a, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	albums := make([]*models.Album, len(*a))
+	for i, _ := range *a {
+		albums[i] = &(*a)[i]
+	}
+	return albums, nil
+}
+
+func (db *Db) selectSongs(stmt squirrel.SelectBuilder) ([]*models.Song, error) {
+	sql, args, err := stmt.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	s := &[]models.Song{}
+	err = db.engine.Select(s, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	songs := make([]*models.Song, len(*s))
+	for i, _ := range *s {
+		songs[i] = &(*s)[i]
+	}
+	return songs, nil
+}
+
+// filterAlbums adds favorite, year range and name conditions to statement.
+func filterAlbums(stmt squirrel.SelectBuilder, filter interfaces.Filter) squirrel.SelectBuilder {
+	if filter.Favorite {
+		stmt = stmt.Where("favorite = TRUE")
+	}
+	if filter.YearRangeValid() && filter.YearRange[0] > 0 {
+		stmt = stmt.Where("year BETWEEN ? AND ?", filter.YearRange[0], filter.YearRange[1])
+	}
+	return filterByName(stmt, filter)
+}
+
+// filterByName adds filter.NameStartsWith condition to statement, if set.
+func filterByName(stmt squirrel.SelectBuilder, filter interfaces.Filter) squirrel.SelectBuilder {
+	if filter.NameStartsWith == interfaces.NameStartsOther {
+		// same rule as interfaces.Filter.NameMatches
+		return stmt.Where("LOWER(SUBSTR(name, 1, 1)) < 'a'")
+	} else if filter.NameStartsWith != "" {
+		return stmt.Where("name LIKE ?", filter.NameStartsWith+"%")
+	}
+	return stmt
+}
@@ -20,9 +20,13 @@ package storage
 
 import (
 	"github.com/google/go-cmp/cmp"
+	"reflect"
+	"sort"
+	"strconv"
 	"testing"
 	"tryffel.net/go/jellycli/api"
 	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
 )
 
 func TestDb_UpdateArtists(t *testing.T) {
@@ -144,7 +148,9 @@ func TestDb_UpdateSongs(t *testing.T) {
 		t.Errorf("update songs: %v", err)
 	}
 
-	gotSongs, count, err := db.GetSongs(0, 10)
+	opts := interfaces.DefaultQueryOpts()
+	opts.Paging.PageSize = 10
+	gotSongs, count, err := db.GetSongs(opts)
 	if err != nil {
 		t.Errorf("get songs: %v", err)
 	}
@@ -204,3 +210,346 @@ func TestDb_UpdatePlaylists(t *testing.T) {
 		}
 	}
 }
+
+// TestDb_GetArtistsNameMatches checks that local cache filters names with same rule as
+// interfaces.Filter.NameMatches, which servers use.
+func TestDb_GetArtistsNameMatches(t *testing.T) {
+	names := []string{"Abba", "abba", "Ärsenik", "Élan", "Кино", "1999", "_under", "[bracket", "~tilde",
+		"Zed", "zz top", "!!!", ""}
+	artists := make([]*models.Artist, len(names))
+	for i, v := range names {
+		artists[i] = &models.Artist{Id: models.Id(strconv.Itoa(i)), Name: v}
+	}
+
+	db := testDb(t)
+	if db == nil {
+		return
+	}
+	defer closeDb(t, db)
+
+	err := db.UpdateArtists(artists)
+	if err != nil {
+		t.Fatalf("insert artists: %v", err)
+	}
+
+	for _, prefix := range []string{interfaces.NameStartsOther, "A", "a", "Z", "Ä"} {
+		opts := interfaces.DefaultQueryOpts()
+		opts.Paging.PageSize = len(names)
+		opts.Filter.NameStartsWith = prefix
+
+		want := []string{}
+		for _, v := range names {
+			if opts.Filter.NameMatches(v) {
+				want = append(want, v)
+			}
+		}
+		got := []string{}
+		gotArtists, _, err := db.GetArtists(opts)
+		if err != nil {
+			t.Fatalf("get artists: %v", err)
+		}
+		for _, v := range gotArtists {
+			got = append(got, v.Name)
+		}
+		sort.Strings(want)
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("prefix '%s': got %q, want %q", prefix, got, want)
+		}
+	}
+
+	filter := interfaces.Filter{NameStartsWith: interfaces.NameStartsOther}
+	for _, v := range []string{"1999", "_under", "[bracket", "!!!", ""} {
+		if !filter.NameMatches(v) {
+			t.Errorf("name '%s' does not match '#'", v)
+		}
+	}
+}
+
+func TestDb_GetArtistsNameStartsWith(t *testing.T) {
+	artists := api.MockArtists
+
+	for i, _ := range artists {
+		artists[i].Albums = nil
+	}
+
+	db := testDb(t)
+	if db == nil {
+		return
+	}
+
+	defer closeDb(t, db)
+
+	err := db.UpdateArtists(artists)
+	if err != nil {
+		t.Errorf("insert artists: %v", err)
+	}
+
+	tests := []struct {
+		prefix string
+		want   int
+	}{
+		{prefix: "", want: len(artists)},
+		{prefix: "a", want: len(artists)},
+		{prefix: "B", want: 0},
+		{prefix: interfaces.NameStartsOther, want: 0},
+	}
+
+	for _, tt := range tests {
+		opts := interfaces.DefaultQueryOpts()
+		opts.Filter.NameStartsWith = tt.prefix
+
+		gotArtists, count, err := db.GetArtists(opts)
+		if err != nil {
+			t.Errorf("get artists: %v", err)
+		}
+		if count != tt.want {
+			t.Errorf("prefix '%s': invalid artists count: %d, want: %d", tt.prefix, count, tt.want)
+		}
+		if len(gotArtists) != tt.want {
+			t.Errorf("prefix '%s': invalid artists: %d, want: %d", tt.prefix, len(gotArtists), tt.want)
+		}
+	}
+}
+
+func TestDb_GetAlbumsYearRange(t *testing.T) {
+	albums := api.MockAlbums
+
+	for i, _ := range albums {
+		albums[i].Songs = nil
+	}
+
+	db := testDb(t)
+	if db == nil {
+		return
+	}
+
+	defer closeDb(t, db)
+
+	err := db.UpdateAlbums(albums)
+	if err != nil {
+		t.Errorf("insert albums: %v", err)
+	}
+
+	opts := interfaces.DefaultQueryOpts()
+	opts.Filter.YearRange = [2]int{2019, 2020}
+
+	gotAlbums, count, err := db.GetAlbums(opts)
+	if err != nil {
+		t.Errorf("get albums: %v", err)
+	}
+
+	if count != 2 {
+		t.Errorf("invalid albums count: %d, want: %d", count, 2)
+	}
+
+	diff := cmp.Diff(albums[:2], gotAlbums)
+	if diff != "" {
+		t.Errorf("albums differ: %s", diff)
+	}
+}
+
+func TestDb_GetAlbumSongs(t *testing.T) {
+	db := testDb(t)
+	if db == nil {
+		return
+	}
+
+	defer closeDb(t, db)
+
+	err := db.UpdateAlbums(api.MockAlbums)
+	if err != nil {
+		t.Errorf("insert albums: %v", err)
+	}
+	err = db.UpdateSongs(api.MockSongs)
+	if err != nil {
+		t.Errorf("insert songs: %v", err)
+	}
+
+	songs, err := db.GetAlbumSongs("album-2")
+	if err != nil {
+		t.Errorf("get album songs: %v", err)
+	}
+
+	want := []models.Id{"song-3", "song-4"}
+	if len(songs) != len(want) {
+		t.Fatalf("invalid songs count: %d, want: %d", len(songs), len(want))
+	}
+	for i, v := range songs {
+		if v.Id != want[i] {
+			t.Errorf("song %d: got %s, want: %s", i, v.Id, want[i])
+		}
+		if v.AlbumArtist != "artist-1" {
+			t.Errorf("song %d: album artist %s, want: %s", i, v.AlbumArtist, "artist-1")
+		}
+	}
+}
+
+func TestDb_GetSongsSort(t *testing.T) {
+	artists := []*models.Artist{
+		{Id: "artist-1", Name: "b artist"},
+		{Id: "artist-2", Name: "a artist"},
+	}
+	albums := []*models.Album{
+		{Id: "album-1", Name: "z album", Year: 2001, Artist: "artist-1"},
+		{Id: "album-2", Name: "y album", Year: 2010, Artist: "artist-2"},
+		{Id: "album-3", Name: "x album", Year: 2005, Artist: "artist-1"},
+	}
+	songs := []*models.Song{
+		{Id: "song-1", Name: "c song", Index: 1, Album: "album-1"},
+		{Id: "song-2", Name: "a song", Index: 1, Album: "album-2"},
+		{Id: "song-3", Name: "b song", Index: 1, Album: "album-3"},
+		{Id: "song-4", Name: "d song", Index: 2, Album: "album-1"},
+	}
+
+	db := testDb(t)
+	if db == nil {
+		return
+	}
+	defer closeDb(t, db)
+
+	err := db.UpdateArtists(artists)
+	if err != nil {
+		t.Fatalf("insert artists: %v", err)
+	}
+	err = db.UpdateAlbums(albums)
+	if err != nil {
+		t.Fatalf("insert albums: %v", err)
+	}
+	err = db.UpdateSongs(songs)
+	if err != nil {
+		t.Fatalf("insert songs: %v", err)
+	}
+
+	tests := []struct {
+		field interfaces.SortField
+		mode  string
+		want  []models.Id
+	}{
+		{interfaces.SortByName, interfaces.SortAsc, []models.Id{"song-2", "song-3", "song-1", "song-4"}},
+		{interfaces.SortByName, interfaces.SortDesc, []models.Id{"song-4", "song-1", "song-3", "song-2"}},
+		{interfaces.SortByAlbum, interfaces.SortAsc, []models.Id{"song-3", "song-2", "song-1", "song-4"}},
+		{interfaces.SortByAlbum, interfaces.SortDesc, []models.Id{"song-1", "song-4", "song-2", "song-3"}},
+		{interfaces.SortByArtist, interfaces.SortAsc, []models.Id{"song-2", "song-3", "song-1", "song-4"}},
+		{interfaces.SortByArtist, interfaces.SortDesc, []models.Id{"song-3", "song-1", "song-4", "song-2"}},
+		{interfaces.SortByDate, interfaces.SortAsc, []models.Id{"song-1", "song-4", "song-3", "song-2"}},
+		{interfaces.SortByDate, interfaces.SortDesc, []models.Id{"song-2", "song-3", "song-1", "song-4"}},
+	}
+	for _, tt := range tests {
+		opts := interfaces.DefaultQueryOpts()
+		opts.Sort = interfaces.Sort{Field: tt.field, Mode: tt.mode}
+
+		got, count, err := db.GetSongs(opts)
+		if err != nil {
+			t.Fatalf("get songs, sort %s %s: %v", tt.field, tt.mode, err)
+		}
+		if count != len(songs) {
+			t.Errorf("sort %s %s: invalid songs count: %d, want: %d", tt.field, tt.mode, count, len(songs))
+		}
+		ids := make([]models.Id, len(got))
+		for i, v := range got {
+			ids[i] = v.Id
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("sort %s %s: got %v, want %v", tt.field, tt.mode, ids, tt.want)
+		}
+	}
+
+	opts := interfaces.DefaultQueryOpts()
+	opts.Sort = interfaces.NewSort(interfaces.SortByRandom)
+	got, _, err := db.GetSongs(opts)
+	if err != nil {
+		t.Fatalf("get songs, random sort: %v", err)
+	}
+	if len(got) != len(songs) {
+		t.Errorf("random sort: invalid songs count: %d, want: %d", len(got), len(songs))
+	}
+}
+
+func TestDb_GetPlaylistSongs(t *testing.T) {
+	db := testDb(t)
+	if db == nil {
+		return
+	}
+
+	defer closeDb(t, db)
+
+	err := db.UpdateSongs(api.MockSongs)
+	if err != nil {
+		t.Errorf("insert songs: %v", err)
+	}
+	err = db.UpdatePlaylists(api.MockPlaylists)
+	if err != nil {
+		t.Errorf("insert playlists: %v", err)
+	}
+
+	playlist := api.MockPlaylists[1]
+	songs, err := db.GetPlaylistSongs(playlist.Id)
+	if err != nil {
+		t.Errorf("get playlist songs: %v", err)
+	}
+
+	if len(songs) != len(playlist.Songs) {
+		t.Fatalf("invalid songs count: %d, want: %d", len(songs), len(playlist.Songs))
+	}
+	for i, v := range songs {
+		if v.Id != playlist.Songs[i].Id {
+			t.Errorf("song %d: got %s, want: %s", i, v.Id, playlist.Songs[i].Id)
+		}
+	}
+}
+
+func TestDb_Search(t *testing.T) {
+	db := testDb(t)
+	if db == nil {
+		return
+	}
+
+	defer closeDb(t, db)
+
+	err := db.UpdateArtists(api.MockArtists)
+	if err != nil {
+		t.Errorf("insert artists: %v", err)
+	}
+	err = db.UpdateAlbums(api.MockAlbums)
+	if err != nil {
+		t.Errorf("insert albums: %v", err)
+	}
+	err = db.UpdateSongs(api.MockSongs)
+	if err != nil {
+		t.Errorf("insert songs: %v", err)
+	}
+	err = db.UpdatePlaylists(api.MockPlaylists)
+	if err != nil {
+		t.Errorf("insert playlists: %v", err)
+	}
+
+	tests := []struct {
+		itemType models.ItemType
+		query    string
+		limit    int
+		want     []models.Id
+	}{
+		{itemType: models.TypeArtist, query: "ARTIST 2", limit: 10, want: []models.Id{"artist-2"}},
+		{itemType: models.TypeAlbum, query: "album", limit: 2, want: []models.Id{"album-1", "album-2"}},
+		{itemType: models.TypeSong, query: "5", limit: 10, want: []models.Id{"song-5"}},
+		{itemType: models.TypePlaylist, query: "playlist", limit: 10, want: []models.Id{"playlist-1", "playlist-2"}},
+		{itemType: models.TypeSong, query: "not found", limit: 10, want: []models.Id{}},
+	}
+
+	for _, tt := range tests {
+		items, err := db.Search(tt.itemType, tt.query, tt.limit)
+		if err != nil {
+			t.Errorf("search %s '%s': %v", tt.itemType, tt.query, err)
+		}
+		if len(items) != len(tt.want) {
+			t.Errorf("search %s '%s': got %d items, want: %d", tt.itemType, tt.query, len(items), len(tt.want))
+			continue
+		}
+		for i, v := range items {
+			if v.GetId() != tt.want[i] {
+				t.Errorf("search %s '%s': item %d: got %s, want: %s", tt.itemType, tt.query, i, v.GetId(), tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+// HistoryV1 is schema of local play history database.
+const HistoryV1 = `
+
+CREATE TABLE plays (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	song_id TEXT NOT NULL,
+	title TEXT NOT NULL,
+	artist TEXT NOT NULL,
+	album TEXT NOT NULL,
+	artist_id TEXT NOT NULL,
+	album_id TEXT NOT NULL,
+	started INTEGER NOT NULL,
+	duration INTEGER NOT NULL,
+	listened INTEGER NOT NULL,
+	completion REAL NOT NULL
+);
+
+CREATE INDEX plays_started ON plays(started);
+
+CREATE TABLE scrobbles (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	server TEXT NOT NULL,
+	song_id TEXT NOT NULL,
+	played INTEGER NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE schema (
+	level INTEGER PRIMARY KEY
+);
+`
@@ -0,0 +1,342 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"gitlab.com/tslocum/cview"
+	"time"
+	"tryffel.net/go/jellycli/api/jellyfin"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/jellycli/player"
+)
+
+// ErrSetupCancelled is returned when user quits setup before finishing it.
+var ErrSetupCancelled = errors.New("setup cancelled")
+
+const (
+	setupPageServer  = "server"
+	setupPageLogin   = "login"
+	setupPageLibrary = "library"
+	setupPageAudio   = "audio"
+	setupPageTheme   = "theme"
+
+	setupLibraryAll = "All libraries"
+)
+
+// setup is a first-run wizard that configures jellyfin server, authentication, music library,
+// audio output and color theme. Each step is a page. Requests to server and audio device are
+// made in background and ui is updated with app.QueueUpdateDraw.
+type setup struct {
+	app    *cview.Application
+	pages  *cview.Pages
+	status *cview.TextView
+
+	server    *jellyfin.Setup
+	libraries []*models.View
+	// busy is set while request is running, so that it is not started twice.
+	busy             bool
+	stopQuickConnect chan bool
+	err              error
+
+	url      *cview.InputField
+	username *cview.InputField
+	password *cview.InputField
+	library  *cview.DropDown
+	theme    *cview.DropDown
+}
+
+// RunSetup runs setup wizard in terminal and saves configuration once user finishes it.
+// It returns ErrSetupCancelled if user quits before that.
+func RunSetup() error {
+	bindDefaultTheme()
+	s := newSetup()
+	err := s.app.Run()
+	if err != nil {
+		return err
+	}
+	return s.err
+}
+
+func newSetup() *setup {
+	s := &setup{
+		app:      cview.NewApplication(),
+		pages:    cview.NewPages(),
+		status:   cview.NewTextView(),
+		err:      ErrSetupCancelled,
+		url:      cview.NewInputField(),
+		username: cview.NewInputField(),
+		password: cview.NewInputField(),
+		library:  cview.NewDropDown(),
+		theme:    cview.NewDropDown(),
+	}
+
+	s.status.SetDynamicColors(true)
+	s.status.SetBackgroundColor(config.Color.Background)
+	s.status.SetTextColor(config.Color.Text)
+
+	s.url.SetLabel("Jellyfin url")
+	s.url.SetFieldWidth(40)
+	s.url.SetText(config.AppConfig.Jellyfin.Url)
+	form := s.newForm("Jellyfin server", 1)
+	form.AddFormItem(s.url)
+	form.AddButton("Next", s.connect)
+	form.AddButton("Quit", s.quit)
+	s.pages.AddPage(setupPageServer, form, true, true)
+
+	s.username.SetLabel("Username")
+	s.username.SetFieldWidth(30)
+	s.password.SetLabel("Password")
+	s.password.SetFieldWidth(30)
+	s.password.SetMaskCharacter('*')
+
+	s.library.SetLabel("Music library")
+	s.library.SetFieldTextColor(config.Color.Text)
+	form = s.newForm("Music library", 3)
+	form.AddFormItem(s.library)
+	form.AddButton("Next", func() { s.show(setupPageAudio, "Play test tone to check that audio output works.") })
+	form.AddButton("Back", func() { s.show(setupPageLogin, "") })
+	s.pages.AddPage(setupPageLibrary, form, true, false)
+
+	form = s.newForm("Audio", 4)
+	form.AddButton("Play test tone", s.playTestTone)
+	form.AddButton("Next", func() { s.show(setupPageTheme, "Select color theme. It can be changed later with gui.theme.") })
+	form.AddButton("Back", func() { s.show(setupPageLibrary, "") })
+	s.pages.AddPage(setupPageAudio, form, true, false)
+
+	s.theme.SetLabel("Theme")
+	s.theme.SetFieldTextColor(config.Color.Text)
+	current := 0
+	for i, v := range config.Themes {
+		s.theme.AddOption(v, nil)
+		if v == config.AppConfig.Gui.Theme {
+			current = i
+		}
+	}
+	s.theme.SetCurrentOption(current)
+	form = s.newForm("Theme", 5)
+	form.AddFormItem(s.theme)
+	form.AddButton("Finish", s.finish)
+	form.AddButton("Back", func() { s.show(setupPageAudio, "") })
+	s.pages.AddPage(setupPageTheme, form, true, false)
+
+	content := cview.NewFlex()
+	content.SetDirection(cview.FlexRow)
+	content.AddItem(nil, 0, 1, false)
+	content.AddItem(s.pages, 11, 0, true)
+	content.AddItem(s.status, 4, 0, false)
+	content.AddItem(nil, 0, 1, false)
+
+	layout := cview.NewFlex()
+	layout.SetBackgroundColor(config.Color.Background)
+	layout.AddItem(nil, 0, 1, false)
+	layout.AddItem(content, 70, 0, true)
+	layout.AddItem(nil, 0, 1, false)
+
+	s.app.SetRoot(layout, true)
+	s.setStatus(fmt.Sprintf("Welcome to %s! Enter address of Jellyfin server, e.g. https://jellyfin.example.com",
+		config.AppName))
+	return s
+}
+
+func (s *setup) newForm(title string, step int) *cview.Form {
+	form := cview.NewForm()
+	form.SetTitle(fmt.Sprintf(" Setup: %s (%d/5) ", title, step))
+	form.SetBackgroundColor(config.Color.Modal.Background)
+	form.SetBorder(true)
+	form.SetCancelFunc(s.quit)
+	return form
+}
+
+// show switches to page and sets status message, if not empty.
+func (s *setup) show(page string, status string) {
+	s.pages.SwitchToPage(page)
+	s.app.SetFocus(s.pages)
+	if status != "" {
+		s.setStatus(status)
+	}
+}
+
+func (s *setup) setStatus(text string) {
+	s.status.SetText(text)
+}
+
+func (s *setup) setError(err error) {
+	s.status.SetText(fmt.Sprintf("[red::]Error: %v[-::]", cview.Escape(err.Error())))
+}
+
+// run runs f in background and shows its error, if any. Status is shown while f is running.
+func (s *setup) run(status string, f func() error) {
+	if s.busy {
+		return
+	}
+	s.busy = true
+	s.setStatus(status + "...")
+	go func() {
+		err := f()
+		s.app.QueueUpdateDraw(func() {
+			s.busy = false
+			if err != nil {
+				s.setError(err)
+			}
+		})
+	}()
+}
+
+func (s *setup) connect() {
+	url := s.url.GetText()
+	s.run("Connecting to "+url, func() error {
+		server, err := jellyfin.NewSetup(url)
+		if err != nil {
+			return err
+		}
+		quickConnect := server.QuickConnectEnabled()
+		s.app.QueueUpdateDraw(func() {
+			s.server = server
+			s.url.SetText(server.Url())
+			s.showLogin(quickConnect)
+		})
+		return nil
+	})
+}
+
+// showLogin creates login page. Quick connect is only shown if server has it enabled.
+func (s *setup) showLogin(quickConnect bool) {
+	form := s.newForm("Login", 2)
+	form.AddFormItem(s.username)
+	form.AddFormItem(s.password)
+	form.AddButton("Login", s.login)
+	status := "Log in with username and password"
+	if quickConnect {
+		form.AddButton("Quick Connect", s.quickConnect)
+		status += ", or with Quick Connect from another device that is logged in"
+	}
+	form.AddButton("Back", func() {
+		s.cancelQuickConnect()
+		s.show(setupPageServer, "")
+	})
+	s.pages.AddPage(setupPageLogin, form, true, false)
+	s.show(setupPageLogin, status+".")
+}
+
+func (s *setup) login() {
+	username := s.username.GetText()
+	password := s.password.GetText()
+	s.run("Logging in", func() error {
+		err := s.server.Login(username, password)
+		if err != nil {
+			return err
+		}
+		return s.loadLibraries()
+	})
+}
+
+func (s *setup) quickConnect() {
+	s.run("Requesting Quick Connect code", func() error {
+		qc, err := s.server.InitiateQuickConnect()
+		if err != nil {
+			return err
+		}
+		stop := make(chan bool)
+		s.app.QueueUpdateDraw(func() {
+			s.stopQuickConnect = stop
+			s.setStatus(fmt.Sprintf("Quick Connect code: [::b]%s[::-]\n"+
+				"Authorize it in Jellyfin from another device: user settings > Quick Connect. Waiting...",
+				qc.Code))
+		})
+		err = s.server.WaitQuickConnect(qc, stop)
+		if err != nil {
+			return err
+		}
+		return s.loadLibraries()
+	})
+}
+
+func (s *setup) cancelQuickConnect() {
+	if s.stopQuickConnect != nil {
+		close(s.stopQuickConnect)
+		s.stopQuickConnect = nil
+	}
+}
+
+// loadLibraries loads music libraries after logging in and shows library page.
+func (s *setup) loadLibraries() error {
+	libraries, err := s.server.GetLibraries()
+	if err != nil {
+		return fmt.Errorf("get music libraries: %v", err)
+	}
+	s.app.QueueUpdateDraw(func() {
+		s.stopQuickConnect = nil
+		s.libraries = libraries
+		s.library.SetOptions(nil, nil)
+		s.library.AddOption(setupLibraryAll, nil)
+		for _, v := range libraries {
+			s.library.AddOption(v.Name, nil)
+		}
+		s.library.SetCurrentOption(0)
+		s.show(setupPageLibrary, "Logged in. Select music library to browse, or use all of them.")
+	})
+	return nil
+}
+
+func (s *setup) playTestTone() {
+	s.run("Playing test tone", func() error {
+		err := player.PlayTestTone(time.Second)
+		if err != nil {
+			return err
+		}
+		s.app.QueueUpdateDraw(func() {
+			s.setStatus("Test tone played. If you did not hear it, check audio device and volume.")
+		})
+		return nil
+	})
+}
+
+// finish saves configuration and closes setup.
+func (s *setup) finish() {
+	if s.busy {
+		return
+	}
+	var library models.Id
+	index, _ := s.library.GetCurrentOption()
+	if index > 0 && index <= len(s.libraries) {
+		library = s.libraries[index-1].Id
+	}
+	_, theme := s.theme.GetCurrentOption()
+
+	config.AppConfig.Player.Server = "jellyfin"
+	config.SetBackendConfig(s.server.Config(library))
+	config.AppConfig.Gui.Theme = theme
+	config.SetTheme(theme, config.AppConfig.Gui.ColorMode)
+	err := config.SaveConfig()
+	if err != nil {
+		s.setError(err)
+		return
+	}
+	s.err = nil
+	s.app.Stop()
+}
+
+func (s *setup) quit() {
+	s.cancelQuickConnect()
+	s.err = ErrSetupCancelled
+	s.app.Stop()
+}
@@ -39,6 +39,9 @@ func NewUi(player *player2.Player) *Gui {
 		player: player,
 	}
 	bindDefaultTheme()
+	widgets.SetAsciiOnly(config.AppConfig.Gui.AsciiOnly)
+	widgets.SetAccessible(config.AppConfig.Gui.AccessibleMode)
+	widgets.SetSongColumns(config.AppConfig.Gui.SongColumns)
 	u.window = widgets.NewWindow(player, player, player)
 	u.Name = "Gui"
 	u.SetLoop(u.loop)
@@ -0,0 +1,97 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/interfaces"
+)
+
+// accessible is true when user interface is linearized for screen readers.
+var accessible bool
+
+// SetAccessible enables screen reader friendly mode: only focused view is shown, borders are not drawn
+// and state changes are announced as text on a dedicated line.
+// It must be called before creating widgets.
+func SetAccessible(enabled bool) {
+	accessible = enabled
+	if !enabled {
+		return
+	}
+	symbol = asciiSymbols
+	setBorders(' ', ' ', ' ', ' ')
+}
+
+// songDescription returns playing song as text, e.g. 'Song by Artist, album Album'.
+func songDescription(state interfaces.AudioStatus) string {
+	if state.Song == nil {
+		return ""
+	}
+	text := state.Song.Name
+	if state.Artist != nil {
+		text = i18n.Tf("%s by %s", text, state.Artist.Name)
+	}
+	if state.Album != nil {
+		text = i18n.Tf("%s, album %s", text, state.Album.Name)
+	}
+	return text
+}
+
+// accessibleStatus returns player state as single line of text. It only changes when state changes,
+// so song progress is not included.
+func accessibleStatus(state interfaces.AudioStatus) string {
+	if state.State == interfaces.AudioStateStopped || state.Song == nil {
+		return i18n.T("Stopped")
+	}
+	if state.Paused {
+		return i18n.Tf("Paused: %s", songDescription(state))
+	}
+	return i18n.Tf("Playing: %s", songDescription(state))
+}
+
+// statusAnnouncement returns text that describes change from previous to current state,
+// or empty string if there is nothing to announce.
+func statusAnnouncement(previous, current interfaces.AudioStatus) string {
+	previousStopped := previous.State == interfaces.AudioStateStopped || previous.Song == nil
+	currentStopped := current.State == interfaces.AudioStateStopped || current.Song == nil
+	switch {
+	case currentStopped && !previousStopped:
+		return i18n.T("Stopped")
+	case currentStopped:
+		return ""
+	case previousStopped || previous.Song.Id != current.Song.Id:
+		return accessibleStatus(current)
+	case current.Paused != previous.Paused:
+		if current.Paused {
+			return i18n.T("Paused")
+		}
+		return i18n.T("Resumed")
+	case current.Muted != previous.Muted:
+		if current.Muted {
+			return i18n.T("Muted")
+		}
+		return i18n.T("Unmuted")
+	case current.Shuffle != previous.Shuffle:
+		if current.Shuffle {
+			return i18n.T("Shuffle on")
+		}
+		return i18n.T("Shuffle off")
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"testing"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_statusAnnouncement(t *testing.T) {
+	playing := interfaces.AudioStatus{
+		State:  interfaces.AudioStatePlaying,
+		Song:   &models.Song{Id: "1", Name: "Fade to Black"},
+		Album:  &models.Album{Name: "Ride the Lightning"},
+		Artist: &models.Artist{Name: "Metallica"},
+	}
+	next := playing
+	next.Song = &models.Song{Id: "2", Name: "Creeping Death"}
+	paused := playing
+	paused.Paused = true
+	muted := playing
+	muted.Muted = true
+	progressed := playing
+	progressed.SongPast = 5000
+	stopped := interfaces.AudioStatus{State: interfaces.AudioStateStopped}
+
+	tests := []struct {
+		name     string
+		previous interfaces.AudioStatus
+		current  interfaces.AudioStatus
+		want     string
+	}{
+		{"start", stopped, playing, "Playing: Fade to Black by Metallica, album Ride the Lightning"},
+		{"next song", playing, next, "Playing: Creeping Death by Metallica, album Ride the Lightning"},
+		{"pause", playing, paused, "Paused"},
+		{"resume", paused, playing, "Resumed"},
+		{"mute", playing, muted, "Muted"},
+		{"stop", playing, stopped, "Stopped"},
+		{"progress", playing, progressed, ""},
+		{"still stopped", stopped, stopped, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statusAnnouncement(tt.previous, tt.current); got != tt.want {
+				t.Errorf("statusAnnouncement() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_accessibleStatus(t *testing.T) {
+	state := interfaces.AudioStatus{
+		State:  interfaces.AudioStatePlaying,
+		Song:   &models.Song{Id: "1", Name: "One"},
+		Paused: true,
+	}
+	if got := accessibleStatus(state); got != "Paused: One" {
+		t.Errorf("accessibleStatus() = %q, want %q", got, "Paused: One")
+	}
+	if got := accessibleStatus(interfaces.AudioStatus{}); got != "Stopped" {
+		t.Errorf("accessibleStatus() = %q, want Stopped", got)
+	}
+}
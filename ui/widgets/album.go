@@ -20,22 +20,24 @@ package widgets
 
 import (
 	"fmt"
-	"github.com/rivo/uniseg"
-	"gitlab.com/tslocum/cview"
+	"github.com/gdamore/tcell"
 	"strings"
+	"time"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/util"
 	"tryffel.net/go/twidgets"
 )
 
 type albumSong struct {
-	*cview.TextView
+	*highlightText
 	song        *models.Song
 	showDiscNum bool
 	index       int
 	// is song being played now
-	playing bool
+	playing  bool
+	selected bool
 
 	// allow overriding text input. If updateTextFunc != nil, use that to update, else use default album text format
 	updateTextFunc func(a *albumSong)
@@ -44,16 +46,26 @@ type albumSong struct {
 func (a *albumSong) SetSelected(selected twidgets.Selection) {
 	switch selected {
 	case twidgets.Selected:
+		a.selected = true
 		a.SetBackgroundColor(config.Color.BackgroundSelected)
 		a.SetTextColor(config.Color.TextSelected)
 	case twidgets.Blurred:
 		a.SetBackgroundColor(config.Color.TextDisabled)
 	case twidgets.Deselected:
+		a.selected = false
 		a.SetBackgroundColor(config.Color.Background)
-		a.SetTextColor(config.Color.Text)
+		a.SetTextColor(a.textColor())
 	}
 }
 
+// textColor returns text color for song that is not selected.
+func (a *albumSong) textColor() tcell.Color {
+	if a.playing {
+		return config.Color.TextSongPlaying
+	}
+	return config.Color.Text
+}
+
 func (a *albumSong) SetRect(x, y, w, h int) {
 	_, _, ch, cw := a.GetRect()
 	a.TextView.SetRect(x, y, w, h)
@@ -70,13 +82,13 @@ func (a *albumSong) setText() {
 	}
 	if a.updateTextFunc != nil {
 		a.updateTextFunc(a)
-	} else {
+	} else if !a.setColumnText(config.SongColumnViewAlbum) {
 		_, _, w, _ := a.GetRect()
 		var name string
 		if a.showDiscNum {
-			name = fmt.Sprintf("%d %d. %s", a.song.DiscNumber, a.song.Index, a.song.Name)
+			name = fmt.Sprintf("%d %d. %s%s", a.song.DiscNumber, a.song.Index, sourcesText(a.song.Sources), a.song.Name)
 		} else {
-			name = fmt.Sprintf("%d. %s", a.index, a.song.Name)
+			name = fmt.Sprintf("%d. %s%s", a.index, sourcesText(a.song.Sources), a.song.Name)
 		}
 
 		text := a.getAlignedDuration(name)
@@ -93,7 +105,7 @@ func (a *albumSong) setText() {
 				}
 				artists += v.Name
 			}
-			if len(artists) > w {
+			if stringWidth(artists) > w {
 				artists = space + fmt.Sprintf("%d artists", len(a.song.Artists))
 			} else {
 				text += artists
@@ -103,60 +115,90 @@ func (a *albumSong) setText() {
 				text += space + a.song.Artists[0].Name
 			}
 		}
+
+		// print composers on second line, after artists
+		if composers := songComposers(a.song); composers != "" {
+			if strings.Contains(text, "\n") {
+				text += "  "
+			} else {
+				text += "\n" + space
+			}
+			text += composers
+		}
 		a.SetText(text)
 	}
 }
 
-// add duration to text with space so that duration is aligned right
+// songComposers returns composers of song as text, or empty string if there are none.
+func songComposers(song *models.Song) string {
+	if len(song.Composers) == 0 {
+		return ""
+	}
+	names := make([]string, len(song.Composers))
+	for i, v := range song.Composers {
+		names[i] = v.Name
+	}
+	return i18n.T("Composer: ") + strings.Join(names, ", ")
+}
+
+// sourcesText returns servers that item is found from, e.g. '(jellyfin+subsonic) '.
+// If item has no sources, it returns empty string. Brackets are not used, as they are color tags.
+func sourcesText(sources []string) string {
+	if len(sources) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(sources, "+") + ") "
+}
+
+// add duration to text with space so that duration is aligned right.
+// Text is truncated by its display width, so that durations line up for wide characters too.
 func (a *albumSong) getAlignedDuration(text string) string {
 	_, _, w, _ := a.GetRect()
-	nameLen := uniseg.GraphemeClusterCount(text)
-
 	duration := util.SecToString(a.song.Duration)
-	durationLen := len(duration)
-	// width - duration - name - padding
-	spaces := w - durationLen - nameLen - 2
-	space := ""
-
-	// calculate space needed between name etc and duration
-	if spaces <= 0 {
-		lines := cview.WordWrap(a.song.Name, w-2)
-		if len(lines) >= 1 {
-			text = lines[0] + "… "
-		}
-	} else {
-		// add space as needed
-		for {
-			if len(space) == spaces {
-				break
-			}
-			if len(space) < spaces-10 {
-				space += "          "
-			} else if len(space) < spaces-5 {
-				space += "     "
-			} else if len(space) < spaces-3 {
-				space += "   "
-			} else {
-				space += " "
-			}
-		}
+	// width - duration - padding
+	nameWidth := w - len(duration) - 2
+	if nameWidth <= 1 {
+		return text + " " + duration
 	}
-	out := text + space + duration
-	return out
+	// leave at least one space before duration
+	return padWidth(truncateWidth(text, nameWidth-1), nameWidth) + duration
 }
 
 func (a *albumSong) SetPlaying(playing bool) {
 	a.playing = playing
+	if !a.selected {
+		a.SetTextColor(a.textColor())
+	}
+}
+
+// playingMarker is implemented by views that mark currently playing song.
+type playingMarker interface {
+	setPlayingSong(id models.Id)
+}
+
+// markPlayingSong marks song with id as playing and others as not playing.
+// Empty id marks every song as not playing.
+func markPlayingSong(songs []*albumSong, id models.Id) {
+	for _, v := range songs {
+		playing := id != "" && v.song.Id == id
+		if v.playing != playing {
+			v.SetPlaying(playing)
+		}
+	}
+}
+
+func (a *AlbumView) setPlayingSong(id models.Id) {
+	markPlayingSong(a.songs, id)
 }
 
 // showDiscNum: whether to print disc number.
 // overrideIndex: set -1 to use song index, else overrides index
 func newAlbumSong(s *models.Song, showDiscNum bool, overrideIndex int) *albumSong {
 	song := &albumSong{
-		TextView:    cview.NewTextView(),
-		song:        s,
-		showDiscNum: showDiscNum,
-		playing:     false,
+		highlightText: newHighlightText(),
+		song:          s,
+		showDiscNum:   showDiscNum,
+		playing:       false,
 	}
 
 	if overrideIndex == -1 {
@@ -173,12 +215,55 @@ func newAlbumSong(s *models.Song, showDiscNum bool, overrideIndex int) *albumSon
 	return song
 }
 
-// AlbumView shows user a header (album name, info, buttons) and list of songs
+// albumHeader is a row in album view that is not a song: album overview or disc title.
+type albumHeader struct {
+	*highlightText
+	overview bool
+}
+
+func newAlbumHeader(text string, overview bool) *albumHeader {
+	h := &albumHeader{
+		highlightText: newHighlightText(),
+		overview:      overview,
+	}
+	h.SetBackgroundColor(config.Color.Background)
+	h.SetTextColor(h.textColor())
+	h.SetBorderPadding(0, 0, 1, 1)
+	h.SetText(text)
+	return h
+}
+
+// disc titles are shown with secondary color
+func (h *albumHeader) textColor() tcell.Color {
+	if h.overview {
+		return config.Color.Text
+	}
+	return config.Color.TextSecondary
+}
+
+func (h *albumHeader) SetSelected(selected twidgets.Selection) {
+	switch selected {
+	case twidgets.Selected:
+		h.SetBackgroundColor(config.Color.BackgroundSelected)
+		h.SetTextColor(config.Color.TextSelected)
+	case twidgets.Blurred:
+		h.SetBackgroundColor(config.Color.TextDisabled)
+	case twidgets.Deselected:
+		h.SetBackgroundColor(config.Color.Background)
+		h.SetTextColor(h.textColor())
+	}
+}
+
+// AlbumView shows user a header (album name, info, buttons) and list of songs.
+// Album overview is shown as the first item and songs of multi-disc albums
+// are grouped under disc titles.
 type AlbumView struct {
 	*itemList
 	songs  []*albumSong
 	artist *models.Artist
 	album  *models.Album
+	// songIndices maps list index to index in songs, -1 if item is not a song.
+	songIndices []int
 
 	playSongFunc  func(song *models.Song)
 	playSongsFunc func(songs []*models.Song)
@@ -186,9 +271,12 @@ type AlbumView struct {
 	similarBtn *button
 	playBtn    *button
 	dropDown   *dropDown
+	genres     *dropDown
 
-	similarFunc func(album *models.Album)
-	context     contextOperator
+	similarFunc     func(album *models.Album)
+	selectGenreFunc func(genre models.IdName)
+	showTextFunc    func(title, text string)
+	context         contextOperator
 }
 
 //NewAlbumView initializes new album view
@@ -198,10 +286,11 @@ func NewAlbumview(playSong func(song *models.Song),
 		playSongFunc:  playSong,
 		playSongsFunc: playSongs,
 
-		similarBtn: newButton("Similar"),
-		playBtn:    newButton("Play all"),
+		similarBtn: newButton(i18n.T("Similar")),
+		playBtn:    newButton(i18n.T("Play all")),
 		context:    operator,
-		dropDown:   newDropDown("Options"),
+		dropDown:   newDropDown(i18n.T("Options")),
+		genres:     newDropDown(i18n.T("Genre")),
 	}
 
 	a.itemList = newItemList(a.playSong)
@@ -225,38 +314,76 @@ func NewAlbumview(playSong func(song *models.Song),
 	a.Banner.Grid.AddItem(a.dropDown, 3, 4, 1, 1, 1, 10, false)
 	a.Banner.Grid.AddItem(a.list, 4, 0, 4, 8, 4, 10, false)
 
-	selectables := []twidgets.Selectable{a.prevBtn, a.playBtn, a.dropDown, a.list}
 	a.similarBtn.SetSelectedFunc(a.showSimilar)
-	a.Banner.Selectable = selectables
+	a.setGenres(nil)
 
 	if a.context != nil {
-		a.list.AddContextItem("Play all from here", 0, func(index int) {
+		a.list.AddContextItem(i18n.T("Play all from here"), 0, func(index int) {
 			a.playFromSelected()
 		})
-		a.list.AddContextItem("View artist", 0, func(index int) {
-			if index < len(a.songs) && a.context != nil {
-				song := a.songs[0]
+		a.list.AddContextItem(i18n.T("View artist"), 0, func(index int) {
+			song := a.songAt(a.getSelectedIndex())
+			if song != nil && a.context != nil {
 				a.context.ViewSongArtist(song.song)
 			}
 		})
-		a.list.AddContextItem("Instant mix", 0, func(index int) {
-			if index < len(a.songs) && a.context != nil {
-				song := a.songs[0]
+		a.list.AddContextItem(i18n.T("Instant mix"), 0, func(index int) {
+			song := a.songAt(a.getSelectedIndex())
+			if song != nil && a.context != nil {
 				a.context.InstantMix(song.song)
 			}
 		})
+		a.list.AddContextItem(i18n.T("Open in browser"), 0, func(index int) {
+			song := a.songAt(a.getSelectedIndex())
+			if song != nil {
+				a.context.OpenInBrowser(song.song)
+			}
+		})
+		a.list.AddContextItem(i18n.T("Copy link"), 0, func(index int) {
+			song := a.songAt(a.getSelectedIndex())
+			if song != nil {
+				a.context.CopyLink(song.song)
+			}
+		})
+		a.list.AddContextItem(i18n.T("Copy info"), 0, func(index int) {
+			song := a.songAt(a.getSelectedIndex())
+			if song != nil {
+				a.context.CopyInfo(song.song)
+			}
+		})
+		a.list.AddContextItem(i18n.T("Volume offset"), 0, func(index int) {
+			song := a.songAt(a.getSelectedIndex())
+			if song != nil {
+				a.context.AdjustGain(song.song)
+			}
+		})
 	}
 
 	if a.context != nil {
-		a.dropDown.AddOption("Instant mix", func() {
+		a.dropDown.AddOption(i18n.T("Instant mix"), func() {
 			a.context.InstantMix(a.artist)
 		})
-		a.dropDown.AddOption("View similar", func() {
+		a.dropDown.AddOption(i18n.T("View similar"), func() {
 			a.showSimilar()
 		})
-		a.dropDown.AddOption("Open in browser", func() {
+		a.dropDown.AddOption(i18n.T("Open in browser"), func() {
 			a.context.OpenInBrowser(a.album)
 		})
+		a.dropDown.AddOption(i18n.T("Copy link"), func() {
+			a.context.CopyLink(a.album)
+		})
+		a.dropDown.AddOption(i18n.T("Copy info"), func() {
+			a.context.CopyInfo(a.album)
+		})
+		a.dropDown.AddOption(i18n.T("Volume offset"), func() {
+			a.context.AdjustGain(a.album)
+		})
+		a.dropDown.AddOption(i18n.T("Download"), func() {
+			a.context.Download(a.album)
+		})
+		a.dropDown.AddOption(i18n.T("Export M3U"), func() {
+			a.context.Export(a.album)
+		})
 	}
 
 	a.itemList.initContextMenuList()
@@ -267,19 +394,16 @@ func (a *AlbumView) SetAlbum(album *models.Album, songs []*models.Song) {
 	a.list.Clear()
 	a.resetReduce()
 	a.songs = make([]*albumSong, len(songs))
-	items := make([]twidgets.ListItem, len(songs))
-
-	itemTexts := make([]string, len(songs))
 
 	album.SongCount = len(a.songs)
 	a.album = album
 
 	text := ""
 	if album.Favorite {
-		text += charFavorite + " "
+		text += symbol.favorite + " "
 	}
 
-	text += album.Name
+	text += sourcesText(album.Sources) + album.Name
 	if len(a.album.AdditionalArtists) > 1 {
 		text += " ("
 		for i, v := range a.album.AdditionalArtists {
@@ -302,19 +426,48 @@ func (a *AlbumView) SetAlbum(album *models.Album, songs []*models.Song) {
 
 	text += fmt.Sprintf("\n%d tracks  %s  %d",
 		album.SongCount, util.SecToStringApproximate(album.Duration), album.Year)
+	if info := songsFileInfo(songs); info != "" {
+		text += "  " + info
+	}
+	if info := songsPlayInfo(songs); info != "" {
+		text += "  " + info
+	}
 
 	a.description.SetText(text)
+	a.setGenres(album.Genres)
 
 	discs := map[int]bool{}
 	for _, v := range songs {
 		discs[v.DiscNumber] = true
 	}
 	album.DiscCount = len(discs)
-	showDiscNum := album.DiscCount != 1
+	// disc titles replace disc number in songs
+	groupDiscs := album.DiscCount > 1
+
+	items := make([]twidgets.ListItem, 0, len(songs)+album.DiscCount+1)
+	itemTexts := make([]string, 0, len(songs)+album.DiscCount+1)
+	a.songIndices = make([]int, 0, len(songs)+album.DiscCount+1)
+	addHeader := func(header *albumHeader, searchText string) {
+		items = append(items, header)
+		itemTexts = append(itemTexts, strings.ToLower(searchText))
+		a.songIndices = append(a.songIndices, -1)
+	}
+
+	if album.Overview != "" {
+		addHeader(newAlbumHeader(limitNotification(album.Overview), true), "")
+	}
+
+	disc := -1
 	for i, v := range songs {
-		a.songs[i] = newAlbumSong(v, showDiscNum, -1)
-		items[i] = a.songs[i]
-		itemTexts[i] = strings.ToLower(v.Name)
+		if groupDiscs && v.DiscNumber != disc {
+			disc = v.DiscNumber
+			title := discTitle(disc, album.DiscTitles[disc])
+			addHeader(newAlbumHeader(title, false), title)
+		}
+		a.songs[i] = newAlbumSong(v, false, -1)
+		items = append(items, a.songs[i])
+		itemTexts = append(itemTexts, strings.ToLower(v.Name))
+		a.songIndices = append(a.songIndices, i)
 	}
 
 	a.list.AddItems(items...)
@@ -327,10 +480,26 @@ func (a *AlbumView) SetArtist(artist *models.Artist) {
 	a.artist = artist
 }
 
+// songAt returns song at list index, or nil if item is not a song.
+func (a *AlbumView) songAt(index int) *albumSong {
+	if index < 0 || index >= len(a.songIndices) || a.songIndices[index] < 0 {
+		return nil
+	}
+	return a.songs[a.songIndices[index]]
+}
+
 func (a *AlbumView) playSong(index int) {
+	song := a.songAt(index)
+	if song == nil {
+		if index < len(a.items) {
+			if header, ok := a.items[index].(*albumHeader); ok && header.overview {
+				a.showOverview()
+			}
+		}
+		return
+	}
 	if a.playSongFunc != nil {
-		song := a.songs[index].song
-		a.playSongFunc(song)
+		a.playSongFunc(song.song)
 	}
 }
 
@@ -346,12 +515,15 @@ func (a *AlbumView) playAlbum() {
 
 func (a *AlbumView) playFromSelected() {
 	if a.playSongsFunc != nil {
-		index := a.getSelectedIndex()
-		songs := make([]*models.Song, len(a.songs)-index)
-		for i, v := range a.songs[index:] {
-			songs[i] = v.song
+		// start from next song if header is selected
+		start := len(a.songs)
+		for _, v := range a.songIndices[a.getSelectedIndex():] {
+			if v >= 0 {
+				start = v
+				break
+			}
 		}
-		a.playSongsFunc(songs)
+		a.playSongsFunc(songsFrom(a.songs, start))
 	}
 }
 
@@ -361,6 +533,33 @@ func (a *AlbumView) showSimilar() {
 	}
 }
 
+func (a *AlbumView) showOverview() {
+	if a.showTextFunc != nil && a.album != nil {
+		a.showTextFunc(a.album.Name, a.album.Overview)
+	}
+}
+
+// setGenres sets genres that can be selected. Genre selection is hidden if there are no genres.
+func (a *AlbumView) setGenres(genres []models.IdName) {
+	a.Banner.Grid.RemoveItem(a.genres)
+	if len(genres) == 0 {
+		a.Banner.Selectable = []twidgets.Selectable{a.prevBtn, a.playBtn, a.dropDown, a.list}
+		return
+	}
+
+	names := make([]string, len(genres))
+	for i, v := range genres {
+		names[i] = v.Name
+	}
+	a.genres.SetOptions(names, func(text string, index int) {
+		if a.selectGenreFunc != nil && index >= 0 && index < len(genres) {
+			a.selectGenreFunc(genres[index])
+		}
+	})
+	a.Banner.Grid.AddItem(a.genres, 3, 6, 1, 1, 1, 10, false)
+	a.Banner.Selectable = []twidgets.Selectable{a.prevBtn, a.playBtn, a.dropDown, a.genres, a.list}
+}
+
 func (a *AlbumView) showReduceInput(visible bool) {
 	if visible {
 		a.Grid.AddItem(a.reduceInput, 5, 0, 1, 10, 1, 20, false)
@@ -373,3 +572,97 @@ func (a *AlbumView) showReduceInput(visible bool) {
 	}
 
 }
+
+// discTitle formats disc header: 'Disc 2' or 'Disc 2: subtitle'.
+func discTitle(disc int, subtitle string) string {
+	if subtitle == "" {
+		return i18n.Tf("Disc %d", disc)
+	}
+	return i18n.Tf("Disc %d: %s", disc, subtitle)
+}
+
+// songsPlayInfo returns total play count and latest play date of songs,
+// e.g. '12 plays, last 2021-03-04'. If songs have not been played, it returns empty string.
+func songsPlayInfo(songs []*models.Song) string {
+	plays := 0
+	var last time.Time
+	for _, v := range songs {
+		plays += v.PlayCount
+		if v.LastPlayed.After(last) {
+			last = v.LastPlayed
+		}
+	}
+	if plays == 0 && last.IsZero() {
+		return ""
+	}
+	info := i18n.Tf("%d plays", plays)
+	if plays == 1 {
+		info = i18n.T("1 play")
+	}
+	if !last.IsZero() {
+		info += ", " + i18n.Tf("last %s", last.Local().Format(lastPlayedFormat))
+	}
+	return info
+}
+
+// songsFileInfo returns total size, codecs and average bitrate of songs, e.g. '320.0 MB  FLAC  920 kbps'.
+// Unknown values are omitted.
+func songsFileInfo(songs []*models.Song) string {
+	var size int64
+	codecs := make([]string, 0, 1)
+	seen := map[string]bool{}
+	bitrate := 0
+	bitrates := 0
+	for _, v := range songs {
+		size += v.Size
+		codec := strings.ToUpper(v.Codec)
+		if codec != "" && !seen[codec] {
+			seen[codec] = true
+			codecs = append(codecs, codec)
+		}
+		if v.Bitrate > 0 {
+			bitrate += v.Bitrate
+			bitrates++
+		}
+	}
+
+	info := make([]string, 0, 3)
+	if size > 0 {
+		info = append(info, util.BytesToString(size))
+	}
+	if len(codecs) > 0 {
+		info = append(info, strings.Join(codecs, "/"))
+	}
+	if bitrates > 0 {
+		info = append(info, fmt.Sprintf("%d kbps", bitrate/bitrates))
+	}
+	return strings.Join(info, "  ")
+}
+
+// selectSong selects song with given id, if album contains it.
+func (a *AlbumView) selectSong(id models.Id) {
+	for i, v := range a.songIndices {
+		if v >= 0 && a.songs[v].song.Id == id {
+			a.selectIndex(i)
+			return
+		}
+	}
+}
+
+func (a *AlbumView) queueSource() string {
+	if a.album == nil {
+		return ""
+	}
+	return i18n.Tf("Album: %s", a.album.Name)
+}
+
+func (a *AlbumView) highlightedItem() models.Item {
+	song := a.songAt(a.getSelectedIndex())
+	if song == nil {
+		if a.album == nil {
+			return nil
+		}
+		return a.album
+	}
+	return song.song
+}
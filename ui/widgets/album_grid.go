@@ -0,0 +1,287 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strconv"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/models"
+)
+
+// Minimum size of album card in grid, including borders.
+const (
+	albumCardWidth  = 24
+	albumCardHeight = 5
+)
+
+// albumGrid shows albums as text cards in multiple columns, to better utilize wide terminals.
+// Cards are navigated with arrow keys or hjkl and album is opened with enter.
+type albumGrid struct {
+	*cview.Box
+	albums []*models.Album
+	// offset is number of albums before first album in grid.
+	offset   int
+	selected int
+	// firstRow is the first visible row.
+	firstRow int
+	// columns and rows that fit in grid, updated on draw.
+	columns int
+	rows    int
+
+	selectFunc func(index int)
+	blurFunc   func(key tcell.Key)
+	// toggleFunc is called with 'v' to switch back to list.
+	toggleFunc func(setFocus func(p cview.Primitive))
+	// loadMoreFunc is called when moving down from last row, if infinite scroll is enabled.
+	loadMoreFunc func()
+}
+
+func newAlbumGrid(selectFunc func(index int)) *albumGrid {
+	g := &albumGrid{
+		Box:        cview.NewBox(),
+		selectFunc: selectFunc,
+		columns:    1,
+		rows:       1,
+	}
+	g.SetBorder(true)
+	g.SetBorderColor(config.Color.Border)
+	g.SetBackgroundColor(config.Color.Background)
+	return g
+}
+
+func (g *albumGrid) SetBlurFunc(f func(key tcell.Key)) {
+	g.blurFunc = f
+}
+
+func (g *albumGrid) setAlbums(albums []*models.Album, offset int) {
+	g.albums = albums
+	g.offset = offset
+	g.selected = 0
+	g.firstRow = 0
+}
+
+func (g *albumGrid) getSelected() int {
+	return g.selected
+}
+
+func (g *albumGrid) setSelected(index int) {
+	g.selected = gridIndex(index, 0, len(g.albums))
+}
+
+// gridLayout returns number of cards that fit in width and height.
+func gridLayout(width, height int) (columns, rows int) {
+	columns = width / albumCardWidth
+	if columns < 1 {
+		columns = 1
+	}
+	rows = height / albumCardHeight
+	if rows < 1 {
+		rows = 1
+	}
+	return
+}
+
+// gridIndex moves index by delta and keeps it inside [0, count).
+func gridIndex(index, delta, count int) int {
+	index += delta
+	if index >= count {
+		index = count - 1
+	}
+	if index < 0 {
+		index = 0
+	}
+	return index
+}
+
+// albumCardLines returns text lines of album card: name, artist and year.
+func albumCardLines(album *models.Album, number int) []string {
+	artist := ""
+	if len(album.AdditionalArtists) > 0 {
+		artist = album.AdditionalArtists[0].Name
+	}
+	year := ""
+	if album.Year > 0 {
+		year = strconv.Itoa(album.Year)
+	}
+	return []string{fmt.Sprintf("%d. %s%s", number, sourcesText(album.Sources), album.Name), artist, year}
+}
+
+func (g *albumGrid) InputHandler() func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+	return g.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+		key := event.Key()
+		r := event.Rune()
+		page := g.columns * g.rows
+		switch {
+		case key == tcell.KeyEnter:
+			if g.selectFunc != nil && g.selected < len(g.albums) {
+				g.selectFunc(g.selected)
+			}
+		case key == tcell.KeyLeft || r == 'h':
+			g.selected = gridIndex(g.selected, -1, len(g.albums))
+		case key == tcell.KeyRight || r == 'l':
+			g.selected = gridIndex(g.selected, 1, len(g.albums))
+		case key == tcell.KeyUp || r == 'k':
+			if g.selected < g.columns {
+				if g.blurFunc != nil {
+					g.blurFunc(tcell.KeyBacktab)
+				}
+			} else {
+				g.selected -= g.columns
+			}
+		case key == tcell.KeyDown || r == 'j':
+			lastRow := (len(g.albums) - 1) / g.columns
+			if g.selected/g.columns == lastRow {
+				if g.loadMoreFunc != nil && config.AppConfig.Gui.InfiniteScroll {
+					g.loadMoreFunc()
+				}
+			} else {
+				g.selected = gridIndex(g.selected, g.columns, len(g.albums))
+			}
+		case key == tcell.KeyPgUp:
+			g.selected = gridIndex(g.selected, -page, len(g.albums))
+		case key == tcell.KeyPgDn:
+			g.selected = gridIndex(g.selected, page, len(g.albums))
+		case key == tcell.KeyHome || r == 'g':
+			g.selected = 0
+		case key == tcell.KeyEnd || r == 'G':
+			g.selected = gridIndex(len(g.albums), -1, len(g.albums))
+		case r == 'v':
+			if g.toggleFunc != nil {
+				g.toggleFunc(setFocus)
+			}
+		}
+	})
+}
+
+// MouseHandler selects clicked card and opens it with double click.
+func (g *albumGrid) MouseHandler() func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+	return g.WrapMouseHandler(func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+		if !g.InRect(event.Position()) {
+			return false, nil
+		}
+		switch action {
+		case cview.MouseLeftClick, cview.MouseLeftDoubleClick:
+			setFocus(g)
+			index := g.indexAt(event.Position())
+			if index < 0 {
+				return true, nil
+			}
+			g.selected = index
+			open := action == cview.MouseLeftDoubleClick || config.AppConfig.Gui.MouseSingleClickPlay
+			if open && g.selectFunc != nil {
+				g.selectFunc(index)
+			}
+			consumed = true
+		case cview.MouseScrollUp:
+			g.selected = gridIndex(g.selected, -g.columns*config.AppConfig.Gui.MouseScrollLines, len(g.albums))
+			consumed = true
+		case cview.MouseScrollDown:
+			g.selected = gridIndex(g.selected, g.columns*config.AppConfig.Gui.MouseScrollLines, len(g.albums))
+			consumed = true
+		}
+		return
+	})
+}
+
+// indexAt returns index of album at screen position, or -1 if there is none.
+func (g *albumGrid) indexAt(x, y int) int {
+	rectX, rectY, width, height := g.GetInnerRect()
+	if x < rectX || x >= rectX+width || y < rectY || y >= rectY+height {
+		return -1
+	}
+	cardWidth := width / g.columns
+	column := (x - rectX) / cardWidth
+	if column >= g.columns {
+		return -1
+	}
+	index := (g.firstRow+(y-rectY)/albumCardHeight)*g.columns + column
+	if index >= len(g.albums) {
+		return -1
+	}
+	return index
+}
+
+func (g *albumGrid) Draw(screen tcell.Screen) {
+	g.Box.Draw(screen)
+	x, y, width, height := g.GetInnerRect()
+	g.columns, g.rows = gridLayout(width, height)
+	cardWidth := width / g.columns
+
+	// keep selected card visible
+	row := g.selected / g.columns
+	if row < g.firstRow {
+		g.firstRow = row
+	} else if row >= g.firstRow+g.rows {
+		g.firstRow = row - g.rows + 1
+	}
+
+	first := g.firstRow * g.columns
+	for i := first; i < len(g.albums) && i < first+g.columns*g.rows; i++ {
+		cardX := x + (i-first)%g.columns*cardWidth
+		cardY := y + (i-first)/g.columns*albumCardHeight
+		g.drawCard(screen, i, cardX, cardY, cardWidth, albumCardHeight)
+	}
+}
+
+// drawCard draws bordered album card at given position.
+func (g *albumGrid) drawCard(screen tcell.Screen, index, x, y, width, height int) {
+	textColor := config.Color.Text
+	background := config.Color.Background
+	border := config.Color.Border
+	if index == g.selected {
+		textColor = config.Color.TextSelected
+		background = config.Color.BackgroundSelected
+		if g.HasFocus() {
+			border = config.Color.BorderFocus
+		}
+	}
+
+	style := tcell.StyleDefault.Foreground(border).Background(background)
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			char := ' '
+			switch {
+			case row == 0 && col == 0:
+				char = cview.Borders.TopLeft
+			case row == 0 && col == width-1:
+				char = cview.Borders.TopRight
+			case row == height-1 && col == 0:
+				char = cview.Borders.BottomLeft
+			case row == height-1 && col == width-1:
+				char = cview.Borders.BottomRight
+			case row == 0 || row == height-1:
+				char = cview.Borders.Horizontal
+			case col == 0 || col == width-1:
+				char = cview.Borders.Vertical
+			}
+			screen.SetContent(x+col, y+row, char, nil, style)
+		}
+	}
+
+	textWidth := width - 4
+	for i, v := range albumCardLines(g.albums[index], g.offset+index+1) {
+		if i >= height-2 {
+			break
+		}
+		cview.Print(screen, cview.Escape(truncateWidth(v, textWidth)), x+2, y+1+i, textWidth, cview.AlignLeft, textColor)
+	}
+}
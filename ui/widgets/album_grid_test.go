@@ -0,0 +1,88 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_gridLayout(t *testing.T) {
+	tests := []struct {
+		name        string
+		width       int
+		height      int
+		wantColumns int
+		wantRows    int
+	}{
+		{name: "narrow", width: 20, height: 4, wantColumns: 1, wantRows: 1},
+		{name: "single column", width: 40, height: 20, wantColumns: 1, wantRows: 4},
+		{name: "wide", width: 150, height: 40, wantColumns: 6, wantRows: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, rows := gridLayout(tt.width, tt.height)
+			if columns != tt.wantColumns || rows != tt.wantRows {
+				t.Errorf("gridLayout() = %d, %d, want %d, %d", columns, rows, tt.wantColumns, tt.wantRows)
+			}
+		})
+	}
+}
+
+func Test_gridIndex(t *testing.T) {
+	tests := []struct {
+		name  string
+		index int
+		delta int
+		count int
+		want  int
+	}{
+		{name: "next", index: 2, delta: 1, count: 10, want: 3},
+		{name: "next row", index: 2, delta: 4, count: 10, want: 6},
+		{name: "past end", index: 8, delta: 4, count: 10, want: 9},
+		{name: "before start", index: 2, delta: -4, count: 10, want: 0},
+		{name: "empty", index: 0, delta: 1, count: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gridIndex(tt.index, tt.delta, tt.count); got != tt.want {
+				t.Errorf("gridIndex() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_albumCardLines(t *testing.T) {
+	album := &models.Album{
+		Name:              "Nevermind",
+		Year:              1991,
+		AdditionalArtists: []models.IdName{{Name: "Nirvana"}},
+	}
+	want := []string{"3. Nevermind", "Nirvana", "1991"}
+	if got := albumCardLines(album, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("albumCardLines() = %v, want %v", got, want)
+	}
+
+	unknown := &models.Album{Name: "Demo", Sources: []string{"jellyfin", "subsonic"}}
+	want = []string{"1. (jellyfin+subsonic) Demo", "", ""}
+	if got := albumCardLines(unknown, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("albumCardLines() = %v, want %v", got, want)
+	}
+}
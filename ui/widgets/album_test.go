@@ -20,6 +20,7 @@ package widgets
 
 import (
 	"testing"
+	"time"
 	"tryffel.net/go/jellycli/models"
 )
 
@@ -119,6 +120,82 @@ func Test_albumSong_setText(t *testing.T) {
 			},
 			wantDescription: "2 3. A test song        3:01\n      Artist b, Artist c\n",
 		},
+		{
+			name: "composer",
+			fields: fields{
+				song: &models.Song{
+					Id:          "id",
+					Name:        "A test song",
+					Duration:    181,
+					Index:       3,
+					Album:       "An album",
+					DiscNumber:  2,
+					Artists:     nil,
+					AlbumArtist: "Artist",
+					Composers:   []models.IdName{{"", "J. S. Bach"}},
+				},
+				showDiscNum:   true,
+				overrideIndex: -1,
+				index:         0,
+				width:         30,
+			},
+			wantDescription: "2 3. A test song        3:01\n      Composer: J. S. Bach\n",
+		},
+		{
+			name: "sources",
+			fields: fields{
+				song: &models.Song{
+					Id:          "id",
+					Name:        "A test song",
+					Duration:    181,
+					Index:       3,
+					Album:       "An album",
+					DiscNumber:  1,
+					Artists:     nil,
+					AlbumArtist: "Artist",
+					Sources:     []string{"jellyfin", "subsonic"},
+				},
+				showDiscNum:   false,
+				overrideIndex: -1,
+				index:         0,
+				width:         42,
+			},
+			wantDescription: "3. (jellyfin+subsonic) A test song  3:01\n",
+		},
+		{
+			name: "wide characters",
+			fields: fields{
+				song: &models.Song{
+					Id:          "id",
+					Name:        "東京の夜",
+					Duration:    181,
+					Index:       3,
+					DiscNumber:  1,
+					AlbumArtist: "Artist",
+				},
+				showDiscNum:   false,
+				overrideIndex: -1,
+				width:         23,
+			},
+			wantDescription: "3. 東京の夜      3:01\n",
+		},
+		{
+			name: "truncate wide characters",
+			fields: fields{
+				song: &models.Song{
+					Id:          "id",
+					Name:        "東京の夜は長い",
+					Duration:    181,
+					Index:       3,
+					DiscNumber:  1,
+					AlbumArtist: "Artist",
+				},
+				showDiscNum:   false,
+				overrideIndex: -1,
+				width:         20,
+			},
+			wantDescription: "3. 東京の夜…  3:01\n",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -132,3 +209,97 @@ func Test_albumSong_setText(t *testing.T) {
 		})
 	}
 }
+
+func Test_songsFileInfo(t *testing.T) {
+	tests := []struct {
+		name  string
+		songs []*models.Song
+		want  string
+	}{
+		{
+			name:  "unknown info",
+			songs: []*models.Song{{Name: "a"}, {Name: "b"}},
+			want:  "",
+		},
+		{
+			name: "single codec",
+			songs: []*models.Song{
+				{Size: 1024 * 1024, Codec: "flac", Bitrate: 900},
+				{Size: 2 * 1024 * 1024, Codec: "flac", Bitrate: 1000},
+			},
+			want: "3.0 MB  FLAC  950 kbps",
+		},
+		{
+			name: "mixed codecs, unknown bitrate",
+			songs: []*models.Song{
+				{Size: 1024, Codec: "mp3"},
+				{Size: 1024, Codec: "flac"},
+			},
+			want: "2.0 KB  MP3/FLAC",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := songsFileInfo(tt.songs); got != tt.want {
+				t.Errorf("songsFileInfo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_songsPlayInfo(t *testing.T) {
+	first := time.Date(2021, 3, 4, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name  string
+		songs []*models.Song
+		want  string
+	}{
+		{
+			name:  "not played",
+			songs: []*models.Song{{Name: "a"}, {Name: "b"}},
+			want:  "",
+		},
+		{
+			name:  "single play",
+			songs: []*models.Song{{PlayCount: 1, LastPlayed: first}, {}},
+			want:  "1 play, last 2021-03-04",
+		},
+		{
+			name: "latest play",
+			songs: []*models.Song{
+				{PlayCount: 3, LastPlayed: first},
+				{PlayCount: 2, LastPlayed: first.AddDate(0, 1, 0)},
+			},
+			want: "5 plays, last 2021-04-04",
+		},
+		{
+			name:  "unknown date",
+			songs: []*models.Song{{PlayCount: 4}},
+			want:  "4 plays",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := songsPlayInfo(tt.songs); got != tt.want {
+				t.Errorf("songsPlayInfo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_markPlayingSong(t *testing.T) {
+	songs := []*albumSong{
+		newAlbumSong(&models.Song{Id: "a", Name: "a"}, false, -1),
+		newAlbumSong(&models.Song{Id: "b", Name: "b"}, false, -1),
+	}
+
+	markPlayingSong(songs, "b")
+	if songs[0].playing || !songs[1].playing {
+		t.Errorf("mark song b playing: got %v, %v", songs[0].playing, songs[1].playing)
+	}
+
+	markPlayingSong(songs, "")
+	if songs[0].playing || songs[1].playing {
+		t.Errorf("mark no song playing: got %v, %v", songs[0].playing, songs[1].playing)
+	}
+}
@@ -20,8 +20,11 @@ package widgets
 
 import (
 	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
 	"strings"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/twidgets"
@@ -37,6 +40,13 @@ type AlbumList struct {
 	page          interfaces.Paging
 	selectFunc    func(album *models.Album)
 	albumCovers   []*AlbumCover
+	// artColors loads art placeholders of albums, if enabled.
+	artColors *artColors
+	// offset is number of albums before first album in list.
+	offset int
+	// grid shows albums in multiple columns instead of list, if gridEnabled.
+	grid        *albumGrid
+	gridEnabled bool
 
 	infoBtn        *button
 	playBtn        *button
@@ -45,6 +55,7 @@ type AlbumList struct {
 	similarEnabled bool
 
 	sort          *sort
+	jump          *jump
 	filter        *filter
 	filterBtn     *button
 	filterEnabled bool
@@ -90,27 +101,33 @@ func (a *AlbumList) selectPage(n int) {
 
 // SetPlaylist sets albums
 func (a *AlbumList) SetAlbums(albums []*models.Album) {
+	offset := 0
+	if a.pagingEnabled {
+		offset = a.page.Offset()
+	}
+	a.setAlbums(albums, offset)
+}
+
+func (a *AlbumList) setAlbums(albums []*models.Album, offset int) {
 	a.list.Clear()
 	a.albumCovers = make([]*AlbumCover, 0)
 	a.resetReduce()
 	a.albumCovers = make([]*AlbumCover, len(albums))
 	a.itemsTexts = make([]string, len(albums))
-
-	offset := 0
-	if a.pagingEnabled {
-		offset = a.page.Offset()
-	}
+	a.offset = offset
 
 	items := make([]twidgets.ListItem, len(albums))
+	targets := make([]artTarget, len(albums))
 	for i, v := range albums {
 		cover := NewAlbumCover(offset+i+1, v)
 		items[i] = cover
 		a.albumCovers[i] = cover
+		targets[i] = artTarget{id: v.Id, itemType: models.TypeAlbum, item: cover}
 		var artist = ""
 		if len(v.AdditionalArtists) > 0 {
 			artist = v.AdditionalArtists[0].Name
 		}
-		text := fmt.Sprintf("%d. %s\n     %s - %d", offset+i+1, v.Name, artist, v.Year)
+		text := fmt.Sprintf("%d. %s%s\n     %s - %d", offset+i+1, sourcesText(v.Sources), v.Name, artist, v.Year)
 		cover.setText(text)
 
 		itemText := v.Name
@@ -124,6 +141,64 @@ func (a *AlbumList) SetAlbums(albums []*models.Album) {
 	a.list.AddItems(items...)
 	a.items = items
 	a.searchItemsSet()
+	a.grid.setAlbums(albums, offset)
+	a.artColors.load(targets)
+}
+
+// loadMore appends next page to albums, if there is one.
+func (a *AlbumList) loadMore() {
+	if !a.pagingEnabled || a.queryFunc == nil || a.page.CurrentPage >= a.page.TotalPages-1 {
+		return
+	}
+	loaded := make([]*models.Album, len(a.albumCovers))
+	for i, v := range a.albumCovers {
+		loaded[i] = v.album
+	}
+	offset := a.offset
+	page := a.page
+	selected := a.getSelectedIndex()
+	if a.gridEnabled {
+		selected = a.grid.getSelected()
+	}
+
+	a.albumCovers = nil
+	a.selectPage(page.CurrentPage + 1)
+	if len(a.albumCovers) == 0 {
+		a.SetPage(page)
+		a.queryOpts.Paging = page
+	} else {
+		for _, v := range a.albumCovers {
+			loaded = append(loaded, v.album)
+		}
+	}
+	a.setAlbums(loaded, offset)
+	a.list.SetSelected(selected)
+	a.grid.setSelected(selected)
+}
+
+// toggleGrid switches between showing albums in list and grid, keeping selected album.
+func (a *AlbumList) toggleGrid(setFocus func(p cview.Primitive)) {
+	if a.gridEnabled {
+		a.list.SetSelected(a.grid.getSelected())
+	} else {
+		selected := a.getSelectedIndex()
+		a.resetReduce()
+		a.grid.setSelected(selected)
+	}
+	a.gridEnabled = !a.gridEnabled
+	a.setButtons()
+	if a.gridEnabled {
+		setFocus(a.grid)
+	} else {
+		setFocus(a.list)
+	}
+}
+
+func (a *AlbumList) scrollList() *twidgets.ScrollList {
+	if a.gridEnabled {
+		return nil
+	}
+	return a.list
 }
 
 // EnablePaging enables paging and shows page on banner
@@ -171,7 +246,7 @@ func (a *AlbumList) setButtons() {
 	a.Grid.AddItem(a.playBtn, 3, 2, 1, 1, 1, 10, false)
 
 	if a.pagingEnabled {
-		selectables = append(selectables, a.paging.Previous, a.paging.Next)
+		selectables = append(selectables, a.paging.Selectables()...)
 		a.Grid.AddItem(a.paging, 3, 4, 1, 3, 1, 10, false)
 	}
 	if a.similarEnabled {
@@ -199,9 +274,32 @@ func (a *AlbumList) setButtons() {
 		}
 	}
 
-	selectables = append(selectables, a.list)
+	if a.pagingEnabled && a.jump != nil {
+		selectables = append(selectables, a.jump)
+		a.Grid.AddItem(a.jump, 3, 9, 1, 1, 1, 10, false)
+	}
+
+	if a.gridEnabled {
+		selectables = append(selectables, a.grid)
+		a.Grid.AddItem(a.grid, 4, 0, 2, 10, 6, 20, false)
+	} else {
+		selectables = append(selectables, a.list)
+		a.Grid.AddItem(a.list, 4, 0, 2, 10, 6, 20, false)
+	}
 	a.Banner.Selectable = selectables
-	a.Grid.AddItem(a.list, 4, 0, 2, 10, 6, 20, false)
+}
+
+// SetFilter sets filter for next queries and resets jump and paging, without querying albums.
+func (a *AlbumList) SetFilter(filter interfaces.Filter) {
+	if a.jump != nil {
+		a.jump.reset()
+	}
+	a.queryOpts.Filter = filter
+	a.queryOpts.Paging = interfaces.DefaultPaging()
+	a.filter.setComposers(filter.Composers)
+	if a.filterBtn != nil {
+		a.filterApplied(filter.Empty())
+	}
 }
 
 //NewAlbumList constructs new albumList view
@@ -210,39 +308,69 @@ func NewAlbumList(selectAlbum func(album *models.Album), context contextOperator
 	a := &AlbumList{
 		context:    context,
 		selectFunc: selectAlbum,
-		playBtn:    newButton("Play all"),
-		options:    newDropDown("Options"),
+		playBtn:    newButton(i18n.T("Play all")),
+		options:    newDropDown(i18n.T("Options")),
 
 		queryFunc: queryFunc,
 		queryOpts: interfaces.DefaultQueryOpts(),
 	}
 	a.itemList = newItemList(a.selectAlbum)
 	a.paging = NewPageSelector(a.selectPage)
+	a.loadMoreFunc = a.loadMore
 	a.list.ItemHeight = 3
 
+	a.grid = newAlbumGrid(a.selectAlbum)
+	a.grid.toggleFunc = a.toggleGrid
+	a.grid.loadMoreFunc = a.loadMore
+	a.gridEnabled = config.AppConfig.Gui.AlbumGrid
+	listInput := a.list.PreInputHandler
+	a.list.PreInputHandler = func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+		if event.Rune() == 'v' && !a.reduceVisible {
+			a.toggleGrid(setFocus)
+			return
+		}
+		listInput(event, setFocus)
+	}
+
 	a.list.Grid.SetColumns(-1, 5)
 
 	if queryFunc != nil && config.AppConfig.Gui.EnableSorting {
-		a.sort = newSort(a.setSorting,
-			interfaces.SortByName,
-			interfaces.SortByArtist,
-			interfaces.SortByDate,
-			interfaces.SortByRandom,
-			interfaces.SortByPlayCount,
-		)
+		if config.AppConfig.Player.EnableLocalCache {
+			// local cache has no play counts, ratings or date added
+			a.sort = newSort(a.setSorting,
+				interfaces.SortByName,
+				interfaces.SortByDate,
+				interfaces.SortByRandom,
+			)
+		} else {
+			a.sort = newSort(a.setSorting,
+				interfaces.SortByName,
+				interfaces.SortByArtist,
+				interfaces.SortByDate,
+				interfaces.SortByLatest,
+				interfaces.SortByPlayCount,
+				interfaces.SortByLastPlayed,
+				interfaces.SortByRandom,
+				interfaces.SortByRating,
+			)
+		}
+	}
+	if queryFunc != nil {
+		a.jump = newJump(a.jumpTo)
 	}
 
 	a.filter = newFilter("album", a.setFilter, a.filterApplied)
 	if filterFunc != nil && config.AppConfig.Gui.EnableFiltering {
 		a.filterEnabled = true
-		a.filterBtn = newButton("Filter")
+		a.filterBtn = newButton(i18n.T("Filter"))
 		a.filterBtn.SetSelectedFunc(func() {
 			filterFunc(a.filter, nil)
 		})
 	}
 
-	selectables := []twidgets.Selectable{a.prevBtn, a.playBtn, a.options,
-		a.paging.Previous, a.paging.Next, a.list}
+	selectables := []twidgets.Selectable{a.prevBtn, a.playBtn, a.options}
+	selectables = append(selectables, a.paging.Selectables()...)
+	selectables = append(selectables, a.list)
 	a.Banner.Selectable = selectables
 
 	a.Grid.SetRows(1, 1, 1, 1, -1, 3)
@@ -258,14 +386,33 @@ func NewAlbumList(selectAlbum func(album *models.Album), context contextOperator
 	a.reduceEnabled = true
 	a.setReducerVisible = a.showReduceInput
 	a.setButtons()
+
+	if a.context != nil {
+		a.list.AddContextItem(i18n.T("Instant mix"), 0, func(index int) {
+			if album := a.highlightedItem(); album != nil {
+				a.context.InstantMix(album)
+			}
+		})
+		a.list.AddContextItem(i18n.T("Download"), 0, func(index int) {
+			if album := a.highlightedItem(); album != nil {
+				a.context.Download(album)
+			}
+		})
+		a.list.AddContextItem(i18n.T("Volume offset"), 0, func(index int) {
+			if album := a.highlightedItem(); album != nil {
+				a.context.AdjustGain(album)
+			}
+		})
+		a.itemList.initContextMenuList()
+	}
 	return a
 }
 
 func (a *AlbumList) filterApplied(status bool) {
 	if status {
-		a.filterBtn.SetLabel("Filter *")
+		a.filterBtn.SetLabel(i18n.T("Filter *"))
 	} else {
-		a.filterBtn.SetLabel("Filter")
+		a.filterBtn.SetLabel(i18n.T("Filter"))
 	}
 }
 
@@ -288,8 +435,27 @@ func (a *AlbumList) setSorting(sort interfaces.Sort) {
 	}
 }
 
+// persistSorting restores sorting from setting and saves changes to it.
+func (a *AlbumList) persistSorting(setting *string) {
+	if a.sort != nil {
+		a.queryOpts.Sort = a.sort.persist(setting)
+	}
+}
+
 func (a *AlbumList) setFilter(filter interfaces.Filter) {
+	filter.NameStartsWith = a.queryOpts.Filter.NameStartsWith
 	a.queryOpts.Filter = filter
+	a.queryOpts.Paging.CurrentPage = 0
+	if a.queryFunc != nil {
+		a.queryFunc(a.queryOpts)
+		a.resetReduce()
+	}
+}
+
+// jumpTo shows albums whose name starts with prefix, starting from first page.
+func (a *AlbumList) jumpTo(prefix string) {
+	a.queryOpts.Filter.NameStartsWith = prefix
+	a.queryOpts.Paging.CurrentPage = 0
 	if a.queryFunc != nil {
 		a.queryFunc(a.queryOpts)
 		a.resetReduce()
@@ -321,3 +487,11 @@ func newFavoriteAlbums(selectAlbum func(album *models.Album), context contextOpe
 	a.EnableSimilar(false)
 	return a
 }
+
+func (a *AlbumList) highlightedItem() models.Item {
+	index := a.getSelectedIndex()
+	if index < 0 || index >= len(a.albumCovers) {
+		return nil
+	}
+	return a.albumCovers[index].album
+}
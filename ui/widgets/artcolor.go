@@ -0,0 +1,96 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"sync/atomic"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// artPlaceholderWidth is width of art placeholder including space after it.
+const artPlaceholderWidth = 3
+
+// artPlaceholder prefixes each line of escaped text with a block of color.
+// If color is empty, text is returned as it is.
+func artPlaceholder(text string, color string) string {
+	if color == "" {
+		return text
+	}
+	block := fmt.Sprintf("[%s]%s[-] ", color, symbol.artBlock)
+	return block + strings.Replace(text, "\n", "\n"+block, -1)
+}
+
+// artColorSetter is list item that shows art placeholder.
+type artColorSetter interface {
+	setArtColor(color string)
+}
+
+// artTarget is album or artist whose art color is loaded, and list item to show it in.
+type artTarget struct {
+	id       models.Id
+	itemType models.ItemType
+	item     artColorSetter
+}
+
+// artColors loads art colors of list items in background. Each list has its own artColors,
+// and loading new items cancels loading previous items of the list. Nil artColors loads nothing.
+type artColors struct {
+	provider  interfaces.ArtColorProvider
+	queueDraw func(f func())
+	// generation is incremented on each load.
+	generation int32
+}
+
+func newArtColors(provider interfaces.ArtColorProvider, queueDraw func(f func())) *artColors {
+	return &artColors{
+		provider:  provider,
+		queueDraw: queueDraw,
+	}
+}
+
+// load loads colors of targets one by one and sets them to list items.
+func (a *artColors) load(targets []artTarget) {
+	if a == nil {
+		return
+	}
+	generation := atomic.AddInt32(&a.generation, 1)
+	if len(targets) == 0 {
+		return
+	}
+	go func() {
+		for _, v := range targets {
+			if atomic.LoadInt32(&a.generation) != generation {
+				return
+			}
+			color, err := a.provider.GetArtColor(v.id, v.itemType)
+			if err != nil {
+				logrus.Debugf("get art color of %s: %v", v.id, err)
+				continue
+			}
+			item := v.item
+			a.queueDraw(func() {
+				item.setArtColor(color)
+			})
+		}
+	}()
+}
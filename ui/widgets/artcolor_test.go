@@ -0,0 +1,92 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"errors"
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_artPlaceholder(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		color string
+		want  string
+	}{
+		{name: "no color", text: "1. Album\n     Artist", color: "", want: "1. Album\n     Artist"},
+		{name: "single line", text: "Artist", color: "#102030", want: "[#102030]██[-] Artist"},
+		{name: "each line", text: "1. Album\n     Artist", color: "#102030",
+			want: "[#102030]██[-] 1. Album\n[#102030]██[-]      Artist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := artPlaceholder(tt.text, tt.color); got != tt.want {
+				t.Errorf("artPlaceholder() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// artProvider returns colors by item id.
+type artProvider map[models.Id]string
+
+func (a artProvider) GetArtColor(item models.Id, itemType models.ItemType) (string, error) {
+	if color, ok := a[item]; ok {
+		return color, nil
+	}
+	return "", errors.New("no image")
+}
+
+// colorItem records color set to it.
+type colorItem struct {
+	color string
+}
+
+func (c *colorItem) setArtColor(color string) {
+	c.color = color
+}
+
+func TestArtColors_load(t *testing.T) {
+	provider := artProvider{"album-1": "#ff0000", "artist-1": "#00ff00"}
+	done := make(chan bool)
+	drawn := 0
+	colors := newArtColors(provider, func(f func()) {
+		f()
+		drawn++
+		if drawn == 2 {
+			close(done)
+		}
+	})
+	album, missing, artist := &colorItem{}, &colorItem{}, &colorItem{}
+	colors.load([]artTarget{
+		{id: "album-1", itemType: models.TypeAlbum, item: album},
+		{id: "album-2", itemType: models.TypeAlbum, item: missing},
+		{id: "artist-1", itemType: models.TypeArtist, item: artist},
+	})
+	<-done
+	if album.color != "#ff0000" || artist.color != "#00ff00" || missing.color != "" {
+		t.Errorf("colors: album %q, artist %q, missing %q", album.color, artist.color, missing.color)
+	}
+
+	// nil loader, i.e. placeholders disabled, does nothing
+	var disabled *artColors
+	disabled.load([]artTarget{{id: "album-1", itemType: models.TypeAlbum, item: album}})
+}
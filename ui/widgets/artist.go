@@ -0,0 +1,462 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strings"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/jellycli/util"
+	"tryffel.net/go/twidgets"
+)
+
+// artistTopSongs is number of top songs to show in artist view
+const artistTopSongs = 5
+
+// ArtistView shows artist overview, top songs, albums and albums artist appears on.
+type ArtistView struct {
+	*itemList
+	context contextOperator
+	artist  *models.Artist
+
+	overview string
+	topSongs []*models.Song
+	items    []*artistViewItem
+	// similar is similar artists, nil until they are loaded.
+	similar         []*models.Artist
+	similarExpanded bool
+
+	playBtn *button
+	options *dropDown
+
+	selectAlbumFunc func(album *models.Album)
+	playSongFunc    func(song *models.Song)
+	playSongsFunc   func(songs []*models.Song)
+	similarFunc     func(id models.Id)
+	showTextFunc    func(title, text string)
+	// getSimilarFunc loads similar artists shown in similar artists section.
+	getSimilarFunc   func(id models.Id) ([]*models.Artist, error)
+	selectArtistFunc func(artist *models.Artist)
+	// artColors loads art placeholders of albums and similar artists, if enabled.
+	artColors *artColors
+}
+
+// NewArtistView constructs new artist view.
+func NewArtistView(selectAlbum func(album *models.Album), playSong func(song *models.Song),
+	playSongs func(songs []*models.Song), context contextOperator) *ArtistView {
+	a := &ArtistView{
+		context:         context,
+		playBtn:         newButton(i18n.T("Play top songs")),
+		options:         newDropDown(i18n.T("Options")),
+		selectAlbumFunc: selectAlbum,
+		playSongFunc:    playSong,
+		playSongsFunc:   playSongs,
+	}
+	a.itemList = newItemList(a.selectItem)
+	a.list.ItemHeight = 2
+	a.list.Padding = 1
+	a.list.Grid.SetColumns(1, -1)
+
+	a.playBtn.SetSelectedFunc(a.playTopSongs)
+
+	a.Banner.Grid.SetRows(1, 1, 1, 1, -1, 3)
+	a.Banner.Grid.SetColumns(6, 2, 10, -1, 10, -1, 10, -3)
+	a.Banner.Grid.SetMinSize(1, 6)
+
+	a.Banner.Grid.AddItem(a.prevBtn, 0, 0, 1, 1, 1, 5, false)
+	a.Banner.Grid.AddItem(a.description, 0, 2, 2, 6, 1, 10, false)
+	a.Banner.Grid.AddItem(a.playBtn, 3, 2, 1, 1, 1, 10, false)
+	a.Banner.Grid.AddItem(a.options, 3, 4, 1, 1, 1, 10, false)
+	a.Banner.Grid.AddItem(a.list, 4, 0, 2, 8, 4, 10, false)
+
+	a.Banner.Selectable = []twidgets.Selectable{a.prevBtn, a.playBtn, a.options, a.list}
+
+	a.options.AddOption(i18n.T("Show biography"), a.showOverview)
+	a.options.AddOption(i18n.T("Show similar"), func() {
+		if a.similarFunc != nil && a.artist != nil {
+			a.similarFunc(a.artist.Id)
+		}
+	})
+
+	if a.context != nil {
+		a.options.AddOption(i18n.T("Instant mix"), func() {
+			if a.artist != nil {
+				a.context.InstantMix(a.artist)
+			}
+		})
+		a.options.AddOption(i18n.T("Show in browser"), func() {
+			if a.artist != nil {
+				a.context.OpenInBrowser(a.artist)
+			}
+		})
+		a.options.AddOption(i18n.T("Copy link"), func() {
+			if a.artist != nil {
+				a.context.CopyLink(a.artist)
+			}
+		})
+		a.list.AddContextItem(i18n.T("Instant mix"), 0, func(index int) {
+			item := a.selectedItem()
+			if item == nil {
+				return
+			}
+			if item.song != nil {
+				a.context.InstantMix(item.song)
+			} else if item.album != nil {
+				a.context.InstantMix(item.album)
+			} else if item.artist != nil {
+				a.context.InstantMix(item.artist)
+			}
+		})
+		a.list.AddContextItem(i18n.T("View album"), 0, func(index int) {
+			item := a.selectedItem()
+			if item != nil && item.song != nil {
+				a.context.ViewSongAlbum(item.song)
+			}
+		})
+		a.list.AddContextItem(i18n.T("Select album"), 0, func(index int) {
+			a.toggleChecked(a.selectedItem())
+		})
+		a.options.AddOption(i18n.T("Select all albums"), func() { a.setAllChecked(true) })
+		a.options.AddOption(i18n.T("Clear selection"), func() { a.setAllChecked(false) })
+		a.options.AddOption(i18n.T("Favorite selected albums"), func() {
+			a.context.FavoriteAlbums(checkedAlbums(a.items), true)
+		})
+		a.options.AddOption(i18n.T("Unfavorite selected albums"), func() {
+			a.context.FavoriteAlbums(checkedAlbums(a.items), false)
+		})
+		a.options.AddOption(i18n.T("Download selected albums"), func() {
+			a.context.DownloadAlbums(checkedAlbums(a.items))
+		})
+		a.itemList.initContextMenuList()
+	}
+
+	a.reduceEnabled = true
+	a.setReducerVisible = a.showReduceInput
+	return a
+}
+
+// SetArtist sets artist and its contents. Overview, top songs and appearsOn can be empty.
+func (a *ArtistView) SetArtist(artist *models.Artist, overview string, topSongs []*models.Song,
+	albums []*models.Album, appearsOn []*models.Album) {
+	a.list.Clear()
+	a.resetReduce()
+	a.artist = artist
+	a.overview = overview
+	a.topSongs = topSongs
+	a.similar = nil
+	a.similarExpanded = false
+	a.items = make([]*artistViewItem, 0, len(topSongs)+len(albums)+len(appearsOn)+5)
+
+	favorite := ""
+	if artist.Favorite {
+		favorite = symbol.favorite + " "
+	}
+	a.description.SetText(i18n.Tf("%s%s\nAlbums: %d, Total: %s", favorite, cview.Escape(artist.Name),
+		len(albums), util.SecToStringApproximate(artist.TotalDuration)))
+
+	if overview != "" {
+		a.addItem(&artistViewItem{text: limitNotification(overview), overview: true})
+	}
+
+	if len(topSongs) > 0 {
+		a.addItem(&artistViewItem{text: i18n.T("Top songs"), header: true})
+		for i, v := range topSongs {
+			text := fmt.Sprintf("%d. %s\n     %s - %s", i+1, cview.Escape(v.Name),
+				cview.Escape(songAlbumName(v, albums, appearsOn)), util.SecToString(v.Duration))
+			a.addItem(&artistViewItem{text: text, song: v})
+		}
+	}
+
+	a.addAlbums(i18n.T("Albums"), albums)
+	a.addAlbums(i18n.T("Appears on"), appearsOn)
+	if a.getSimilarFunc != nil {
+		a.addItem(&artistViewItem{text: i18n.T("Similar artists: press Enter to show"), header: true,
+			similarHeader: true})
+	}
+	a.setListItems()
+}
+
+// songAlbumName returns name of song's album. If server did not include it with song,
+// it is looked up from artist's albums.
+func songAlbumName(song *models.Song, albums ...[]*models.Album) string {
+	if song.AlbumName != "" {
+		return song.AlbumName
+	}
+	for _, list := range albums {
+		for _, v := range list {
+			if v.Id == song.Album {
+				return v.Name
+			}
+		}
+	}
+	return ""
+}
+
+// setListItems shows items in list.
+func (a *ArtistView) setListItems() {
+	items := make([]twidgets.ListItem, len(a.items))
+	itemTexts := make([]string, len(a.items))
+	targets := make([]artTarget, 0, len(a.items))
+	for i, v := range a.items {
+		items[i] = v
+		itemTexts[i] = strings.ToLower(v.searchText())
+		if v.album != nil {
+			targets = append(targets, artTarget{id: v.album.Id, itemType: models.TypeAlbum, item: v})
+		} else if v.artist != nil {
+			targets = append(targets, artTarget{id: v.artist.Id, itemType: models.TypeArtist, item: v})
+		}
+	}
+	a.list.Clear()
+	a.list.AddItems(items...)
+	a.itemList.items = items
+	a.itemsTexts = itemTexts
+	a.searchItemsSet()
+	a.artColors.load(targets)
+}
+
+// toggleSimilar expands or collapses similar artists section, which is the last section in list.
+// Similar artists are loaded when section is expanded first time.
+func (a *ArtistView) toggleSimilar(header *artistViewItem) {
+	if a.artist == nil {
+		return
+	}
+	if !a.similarExpanded && a.similar == nil {
+		similar, err := a.getSimilarFunc(a.artist.Id)
+		if err != nil {
+			return
+		}
+		a.similar = similar
+	}
+
+	headerIndex := 0
+	for i, v := range a.items {
+		if v == header {
+			headerIndex = i
+		}
+	}
+	a.resetReduce()
+	a.items = a.items[:headerIndex+1]
+	a.similarExpanded = !a.similarExpanded
+	if a.similarExpanded {
+		header.text = i18n.Tf("Similar artists: %d", len(a.similar))
+		for i, v := range a.similar {
+			favorite := ""
+			if v.Favorite {
+				favorite = " " + symbol.favorite
+			}
+			a.addItem(&artistViewItem{text: fmt.Sprintf("%d. %s%s", i+1, v.Name, favorite), artist: v})
+		}
+	} else {
+		header.text = i18n.T("Similar artists: press Enter to show")
+	}
+	header.SetText(header.displayText())
+	a.setListItems()
+	a.list.SetSelected(headerIndex)
+}
+
+func (a *ArtistView) addAlbums(title string, albums []*models.Album) {
+	if len(albums) == 0 {
+		return
+	}
+	a.addItem(&artistViewItem{text: fmt.Sprintf("%s: %d", title, len(albums)), header: true})
+	for i, v := range albums {
+		artist := ""
+		if len(v.AdditionalArtists) > 0 {
+			artist = v.AdditionalArtists[0].Name
+		}
+		text := fmt.Sprintf("%d. %s\n     %s - %d", i+1, v.Name, artist, v.Year)
+		a.addItem(&artistViewItem{text: text, album: v})
+	}
+}
+
+func (a *ArtistView) addItem(item *artistViewItem) {
+	item.highlightText = newHighlightText()
+	item.SetBackgroundColor(config.Color.Background)
+	item.SetTextColor(item.textColor())
+	item.SetBorderPadding(0, 0, 1, 1)
+	item.SetText(item.displayText())
+	a.items = append(a.items, item)
+}
+
+// toggleChecked selects album for batch actions, or removes it from selection.
+func (a *ArtistView) toggleChecked(item *artistViewItem) {
+	if item == nil || item.album == nil {
+		return
+	}
+	item.checked = !item.checked
+	item.SetText(item.displayText())
+}
+
+// setAllChecked selects or unselects all albums.
+func (a *ArtistView) setAllChecked(checked bool) {
+	for _, v := range a.items {
+		if v.album != nil && v.checked != checked {
+			v.checked = checked
+			v.SetText(v.displayText())
+		}
+	}
+}
+
+// checkedAlbums returns selected albums. Album that is listed in multiple sections is only returned once.
+func checkedAlbums(items []*artistViewItem) []*models.Album {
+	albums := make([]*models.Album, 0)
+	found := map[models.Id]bool{}
+	for _, v := range items {
+		if v.album == nil || !v.checked || found[v.album.Id] {
+			continue
+		}
+		found[v.album.Id] = true
+		albums = append(albums, v.album)
+	}
+	return albums
+}
+
+func (a *ArtistView) selectItem(index int) {
+	if index >= len(a.items) {
+		return
+	}
+	item := a.items[index]
+	if item.album != nil && a.selectAlbumFunc != nil {
+		a.selectAlbumFunc(item.album)
+		a.resetReduce()
+	} else if item.song != nil && a.playSongFunc != nil {
+		a.playSongFunc(item.song)
+	} else if item.artist != nil && a.selectArtistFunc != nil {
+		a.selectArtistFunc(item.artist)
+		a.resetReduce()
+	} else if item.similarHeader {
+		a.toggleSimilar(item)
+	} else if item.overview {
+		a.showOverview()
+	}
+}
+
+func (a *ArtistView) selectedItem() *artistViewItem {
+	index := a.getSelectedIndex()
+	if index < 0 || index >= len(a.items) {
+		return nil
+	}
+	return a.items[index]
+}
+
+func (a *ArtistView) playTopSongs() {
+	if a.playSongsFunc != nil && len(a.topSongs) > 0 {
+		a.playSongsFunc(a.topSongs)
+	}
+}
+
+func (a *ArtistView) showOverview() {
+	if a.showTextFunc == nil || a.artist == nil {
+		return
+	}
+	if a.overview == "" {
+		a.showTextFunc(a.artist.Name, i18n.T("No biography"))
+	} else {
+		a.showTextFunc(a.artist.Name, a.overview)
+	}
+}
+
+func (a *ArtistView) showReduceInput(visible bool) {
+	if visible {
+		a.Banner.Grid.AddItem(a.reduceInput, 5, 0, 1, 10, 1, 10, false)
+		a.Banner.Grid.RemoveItem(a.list)
+		a.Banner.Grid.AddItem(a.list, 4, 0, 1, 10, 6, 20, false)
+	} else {
+		a.Banner.Grid.RemoveItem(a.reduceInput)
+		a.Banner.Grid.RemoveItem(a.list)
+		a.Banner.Grid.AddItem(a.list, 4, 0, 2, 10, 6, 20, false)
+	}
+}
+
+// artistViewItem is either a section header, overview, song or album.
+type artistViewItem struct {
+	*highlightText
+	text     string
+	header   bool
+	overview bool
+	song     *models.Song
+	album    *models.Album
+	artist   *models.Artist
+	// similarHeader is header of similar artists section, which expands or collapses the section.
+	similarHeader bool
+	// checked is set when album is selected for batch actions.
+	checked bool
+}
+
+// displayText returns text with selection mark for selected albums.
+func (a *artistViewItem) displayText() string {
+	if a.checked {
+		return symbol.checked + " " + a.text
+	}
+	return a.text
+}
+
+func (a *artistViewItem) searchText() string {
+	if a.song != nil {
+		return a.song.Name
+	} else if a.album != nil {
+		return a.album.Name
+	} else if a.artist != nil {
+		return a.artist.Name
+	}
+	return ""
+}
+
+// headers are shown with secondary color
+func (a *artistViewItem) textColor() tcell.Color {
+	if a.header {
+		return config.Color.TextSecondary
+	}
+	return config.Color.Text
+}
+
+func (a *artistViewItem) SetSelected(s twidgets.Selection) {
+	if s == twidgets.Selected {
+		a.SetTextColor(config.Color.TextSelected)
+		a.SetBackgroundColor(config.Color.BackgroundSelected)
+	} else if s == twidgets.Deselected {
+		a.SetTextColor(a.textColor())
+		a.SetBackgroundColor(config.Color.Background)
+	} else if s == twidgets.Blurred {
+		a.SetBackgroundColor(config.Color.TextDisabled)
+	}
+}
+
+func (a *ArtistView) queueSource() string {
+	if a.artist == nil {
+		return ""
+	}
+	return i18n.Tf("Artist: %s", a.artist.Name)
+}
+
+func (a *ArtistView) highlightedItem() models.Item {
+	item := a.selectedItem()
+	if item == nil {
+		return nil
+	} else if item.song != nil {
+		return item.song
+	} else if item.album != nil {
+		return item.album
+	}
+	return nil
+}
@@ -20,30 +20,30 @@ package widgets
 
 import (
 	"fmt"
-	"gitlab.com/tslocum/cview"
+	"strings"
 	"tryffel.net/go/jellycli/config"
-	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
-	"tryffel.net/go/jellycli/util"
 	"tryffel.net/go/twidgets"
 )
 
 //AlbumCover is a simple cover for album, it shows
 // album name, year and possible artists
 type AlbumCover struct {
-	*cview.TextView
+	*highlightText
 	album   *models.Album
 	index   int
 	name    string
 	year    int
 	artists []string
+	// fullText is text before truncating
+	fullText string
 }
 
 func NewAlbumCover(index int, album *models.Album) *AlbumCover {
 	a := &AlbumCover{
-		TextView: cview.NewTextView(),
-		album:    album,
-		index:    index,
+		highlightText: newHighlightText(),
+		album:         album,
+		index:         index,
 	}
 
 	a.SetBorder(false)
@@ -56,18 +56,16 @@ func NewAlbumCover(index int, album *models.Album) *AlbumCover {
 		text += "\n" + ar
 	}
 
-	a.TextView.SetText(text)
+	a.setText(text)
 	return a
 }
 
 func (a *AlbumCover) SetRect(x, y, w, h int) {
-	_, _, currentW, currentH := a.GetRect()
-	// todo: compact name & artists if necessary
-	if currentH != h {
-	}
+	_, _, currentW, _ := a.GetRect()
+	a.TextView.SetRect(x, y, w, h)
 	if currentW != w {
+		a.updateText()
 	}
-	a.TextView.SetRect(x, y, w, h)
 }
 
 func (a *AlbumCover) SetSelected(selected twidgets.Selection) {
@@ -84,7 +82,32 @@ func (a *AlbumCover) SetSelected(selected twidgets.Selection) {
 }
 
 func (a *AlbumCover) setText(text string) {
-	a.TextView.SetText(text)
+	a.fullText = text
+	a.updateText()
+}
+
+func (a *AlbumCover) setArtColor(color string) {
+	a.artColor = color
+	a.updateText()
+}
+
+// updateText truncates each line of text by its display width to fit in cover.
+func (a *AlbumCover) updateText() {
+	_, _, w, _ := a.GetRect()
+	// border padding
+	w -= 2
+	if a.artColor != "" {
+		w -= artPlaceholderWidth
+	}
+	if w <= 0 {
+		a.SetText(a.fullText)
+		return
+	}
+	lines := strings.Split(a.fullText, "\n")
+	for i, v := range lines {
+		lines[i] = truncateWidth(v, w)
+	}
+	a.SetText(strings.Join(lines, "\n"))
 }
 
 //print multiple artists
@@ -92,7 +115,7 @@ func printArtists(artists []string, maxWidth int) string {
 	var out string
 	need := 0
 	for i, v := range artists {
-		need += len(v)
+		need += stringWidth(v)
 		if i > 0 {
 			need += 2
 		}
@@ -100,7 +123,7 @@ func printArtists(artists []string, maxWidth int) string {
 
 	if need > maxWidth {
 		out = fmt.Sprintf("%d artists", len(artists))
-		if len(out) > maxWidth {
+		if stringWidth(out) > maxWidth {
 			return ""
 		} else {
 			return out
@@ -115,93 +138,3 @@ func printArtists(artists []string, maxWidth int) string {
 	}
 	return out
 }
-
-type ArtistAlbumList struct {
-	*AlbumList
-
-	artist *models.Artist
-}
-
-func NewArtistAlbumList(selectAlbum func(album *models.Album), context contextOperator,
-	queryFunc func(opts *interfaces.QueryOpts), filterFunc openFilterFunc) *ArtistAlbumList {
-
-	a := &ArtistAlbumList{
-		AlbumList: NewAlbumList(selectAlbum, context, queryFunc, filterFunc),
-		artist:    nil,
-	}
-
-	if a.context != nil {
-		a.list.AddContextItem("Instant mix", 0, func(index int) {
-			if index < len(a.albumCovers) && a.context != nil {
-				album := a.albumCovers[index]
-				a.context.InstantMix(album.album)
-			}
-		})
-		a.options.AddOption("Show similar", func() {
-			if a.similarEnabled {
-				a.showSimilar()
-			}
-		})
-		a.options.AddOption("Show in browser", func() {
-			a.context.OpenInBrowser(a.artist)
-		})
-	}
-	return a
-
-}
-
-// SetPlaylists sets albumList cover
-func (a *ArtistAlbumList) SetArtist(artist *models.Artist) {
-	a.artist = artist
-	if artist != nil {
-		favorite := ""
-		if artist.Favorite {
-			favorite = charFavorite + " "
-		}
-
-		a.description.SetText(fmt.Sprintf("%s%s\nAlbums: %d, Total: %s",
-			favorite, a.artist.Name, a.artist.AlbumCount, util.SecToStringApproximate(a.artist.TotalDuration)))
-	} else {
-		a.description.SetText("")
-	}
-
-}
-
-// SetPlaylist sets albums
-func (a *ArtistAlbumList) SetAlbums(albums []*models.Album) {
-	a.list.Clear()
-	a.resetReduce()
-	a.albumCovers = make([]*AlbumCover, len(albums))
-	itemsTexts := make([]string, len(albums))
-
-	offset := 0
-	if a.pagingEnabled {
-		offset = a.page.Offset()
-	}
-
-	items := make([]twidgets.ListItem, len(albums))
-	for i, v := range albums {
-		cover := NewAlbumCover(offset+i+1, v)
-		items[i] = cover
-		a.albumCovers[i] = cover
-
-		var artist = ""
-		if len(v.AdditionalArtists) > 0 {
-			artist = v.AdditionalArtists[0].Name
-		}
-		text := fmt.Sprintf("%d. %s\n     %s - %d", offset+i+1, v.Name, artist, v.Year)
-		cover.setText(text)
-
-		itemsTexts[i] = v.Name + " " + artist
-	}
-	a.list.AddItems(items...)
-	a.itemsTexts = itemsTexts
-	a.items = items
-	a.searchItemsSet()
-}
-
-func (a *ArtistAlbumList) showSimilar() {
-	if a.similarFunc != nil {
-		a.similarFunc(a.artist.Id)
-	}
-}
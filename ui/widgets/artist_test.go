@@ -0,0 +1,107 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_checkedAlbums(t *testing.T) {
+	first := &models.Album{Id: "1", Name: "first"}
+	second := &models.Album{Id: "2", Name: "second"}
+	items := []*artistViewItem{
+		{text: "Top songs", header: true},
+		{song: &models.Song{Id: "10"}, checked: true},
+		{album: first, checked: true},
+		{album: second},
+		{album: first, checked: true},
+	}
+	want := []*models.Album{first}
+	if got := checkedAlbums(items); !reflect.DeepEqual(got, want) {
+		t.Errorf("checkedAlbums() = %v, want %v", got, want)
+	}
+
+	items[3].checked = true
+	want = []*models.Album{first, second}
+	if got := checkedAlbums(items); !reflect.DeepEqual(got, want) {
+		t.Errorf("checkedAlbums() = %v, want %v", got, want)
+	}
+}
+
+func Test_artistViewItem_displayText(t *testing.T) {
+	item := &artistViewItem{text: "1. album", album: &models.Album{Id: "1"}}
+	if got := item.displayText(); got != "1. album" {
+		t.Errorf("displayText() = %v, want 1. album", got)
+	}
+	item.checked = true
+	if got := item.displayText(); got != symbol.checked+" 1. album" {
+		t.Errorf("displayText() = %v, want %v", got, symbol.checked+" 1. album")
+	}
+}
+
+func TestArtistView_toggleSimilar(t *testing.T) {
+	a := NewArtistView(nil, nil, nil, nil)
+	calls := 0
+	similar := []*models.Artist{{Id: "3", Name: "similar"}, {Id: "4", Name: "other"}}
+	a.getSimilarFunc = func(id models.Id) ([]*models.Artist, error) {
+		calls++
+		return similar, nil
+	}
+	a.SetArtist(&models.Artist{Id: "1", Name: "artist"}, "", nil, []*models.Album{{Id: "2", Name: "album"}}, nil)
+	if len(a.items) != 3 || !a.items[2].similarHeader {
+		t.Fatalf("similar artists header missing, got %d items", len(a.items))
+	}
+
+	a.selectItem(2)
+	if len(a.items) != 5 || a.items[3].artist != similar[0] || a.items[4].artist != similar[1] {
+		t.Errorf("similar artists not expanded, got %d items", len(a.items))
+	}
+	a.selectItem(2)
+	if len(a.items) != 3 {
+		t.Errorf("similar artists not collapsed, got %d items", len(a.items))
+	}
+	a.selectItem(2)
+	if len(a.items) != 5 {
+		t.Errorf("similar artists not expanded, got %d items", len(a.items))
+	}
+	if calls != 1 {
+		t.Errorf("similar artists loaded %d times, want 1", calls)
+	}
+}
+
+func Test_songAlbumName(t *testing.T) {
+	albums := []*models.Album{{Id: "2", Name: "album"}}
+	appearsOn := []*models.Album{{Id: "3", Name: "compilation"}}
+	tests := []struct {
+		song *models.Song
+		want string
+	}{
+		{song: &models.Song{Album: "2", AlbumName: "from server"}, want: "from server"},
+		{song: &models.Song{Album: "2"}, want: "album"},
+		{song: &models.Song{Album: "3"}, want: "compilation"},
+		{song: &models.Song{Album: "4"}, want: ""},
+	}
+	for _, tt := range tests {
+		if got := songAlbumName(tt.song, albums, appearsOn); got != tt.want {
+			t.Errorf("songAlbumName(%s) = %q, want %q", tt.song.Album, got, tt.want)
+		}
+	}
+}
@@ -20,9 +20,9 @@ package widgets
 
 import (
 	"fmt"
-	"gitlab.com/tslocum/cview"
 	"strings"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/util"
@@ -33,28 +33,44 @@ type ArtistList struct {
 	*itemList
 	paging         *PageSelector
 	sort           *sort
+	jump           *jump
 	selectFunc     func(artist *models.Artist)
 	selectPageFunc func(page interfaces.Paging)
 	artists        []*ArtistCover
+	context        contextOperator
+	// artColors loads art placeholders of artists, if enabled.
+	artColors *artColors
 
 	pagingEnabled bool
 	page          interfaces.Paging
+	// offset is number of artists before first artist in list.
+	offset int
 
 	queryOpts *interfaces.QueryOpts
 	queryFunc func(opts *interfaces.QueryOpts)
 }
 
-func NewArtistList(selectFunc func(artist *models.Artist), queryFunc func(opts *interfaces.QueryOpts)) *ArtistList {
+func NewArtistList(selectFunc func(artist *models.Artist), queryFunc func(opts *interfaces.QueryOpts),
+	context contextOperator) *ArtistList {
 	a := &ArtistList{
 		selectFunc: selectFunc,
 		artists:    make([]*ArtistCover, 0),
 		queryFunc:  queryFunc,
 		queryOpts:  interfaces.DefaultQueryOpts(),
+		context:    context,
 	}
 	a.itemList = newItemList(a.selectArtist)
 	a.paging = NewPageSelector(a.selectPage)
+	a.loadMoreFunc = a.loadMore
 
-	a.sort = newSort(a.setSorting, interfaces.SortByName, interfaces.SortByRandom)
+	if config.AppConfig.Player.EnableLocalCache {
+		// local cache has no play counts, ratings or date added
+		a.sort = newSort(a.setSorting, interfaces.SortByName, interfaces.SortByRandom)
+	} else {
+		a.sort = newSort(a.setSorting, interfaces.SortByName, interfaces.SortByLatest,
+			interfaces.SortByPlayCount, interfaces.SortByRandom, interfaces.SortByRating)
+	}
+	a.jump = newJump(a.jumpTo)
 
 	a.list.Padding = 1
 	a.list.ItemHeight = 2
@@ -64,15 +80,11 @@ func NewArtistList(selectFunc func(artist *models.Artist), queryFunc func(opts *
 	a.Banner.Grid.SetColumns(6, 2, 10, -1, 10, -1, 15, -3)
 	a.Banner.Grid.SetMinSize(1, 6)
 
-	var selectables []twidgets.Selectable
-
 	if config.AppConfig.Gui.EnableSorting {
-		selectables = []twidgets.Selectable{a.prevBtn, a.paging.Previous, a.paging.Next, a.sort, a.list}
 		a.Banner.Grid.AddItem(a.sort, 3, 6, 1, 1, 1, 10, false)
-	} else {
-		selectables = []twidgets.Selectable{a.prevBtn, a.paging.Previous, a.paging.Next, a.sort, a.list}
 	}
-	a.Banner.Selectable = selectables
+	a.Banner.Selectable = a.pagingSelectables()
+	a.Banner.Grid.AddItem(a.jump, 3, 7, 1, 1, 1, 10, false)
 
 	a.Banner.Grid.AddItem(a.prevBtn, 0, 0, 1, 1, 1, 5, false)
 	a.Banner.Grid.AddItem(a.description, 0, 2, 2, 6, 1, 10, false)
@@ -82,6 +94,14 @@ func NewArtistList(selectFunc func(artist *models.Artist), queryFunc func(opts *
 	a.reduceEnabled = true
 	a.setReducerVisible = a.showReducer
 
+	if a.context != nil {
+		a.list.AddContextItem(i18n.T("Instant mix"), 0, func(index int) {
+			if artist := a.selectedArtist(); artist != nil {
+				a.context.InstantMix(artist)
+			}
+		})
+		a.itemList.initContextMenuList()
+	}
 	return a
 }
 
@@ -99,17 +119,33 @@ func (a *ArtistList) EnablePaging(enabled bool) {
 	}
 	a.pagingEnabled = enabled
 	if enabled {
-		selectables := []twidgets.Selectable{a.prevBtn, a.paging.Previous, a.paging.Next, a.list}
-		a.Banner.Selectable = selectables
+		a.Banner.Selectable = a.pagingSelectables()
 		a.Banner.Grid.AddItem(a.paging, 3, 4, 1, 3, 1, 10, true)
+		a.Banner.Grid.AddItem(a.jump, 3, 7, 1, 1, 1, 10, false)
 	} else {
 		selectables := []twidgets.Selectable{a.prevBtn, a.list}
 		a.Banner.Selectable = selectables
 		a.Banner.Grid.RemoveItem(a.paging)
+		a.Banner.Grid.RemoveItem(a.jump)
 		a.page.CurrentPage = 0
 	}
 }
 
+func (a *ArtistList) pagingSelectables() []twidgets.Selectable {
+	selectables := append([]twidgets.Selectable{a.prevBtn}, a.paging.Selectables()...)
+	if config.AppConfig.Gui.EnableSorting {
+		selectables = append(selectables, a.sort)
+	}
+	return append(selectables, a.jump, a.list)
+}
+
+// SetFilter sets filter for following queries and resets jump and paging. It does not query artists.
+func (a *ArtistList) SetFilter(filter interfaces.Filter) {
+	a.jump.reset()
+	a.queryOpts.Filter = filter
+	a.queryOpts.Paging = interfaces.DefaultPaging()
+}
+
 func (a *ArtistList) Clear() {
 	a.resetReduce()
 	a.list.Clear()
@@ -123,23 +159,30 @@ func (a *ArtistList) SetPage(paging interfaces.Paging) {
 }
 
 func (a *ArtistList) SetArtists(artists []*models.Artist) {
-	a.Clear()
-	items := make([]twidgets.ListItem, len(artists))
-	itemTexts := make([]string, len(artists))
 	offset := 0
 	if a.pagingEnabled {
 		offset = a.page.Offset()
 	}
+	a.setArtists(artists, offset)
+}
+
+func (a *ArtistList) setArtists(artists []*models.Artist, offset int) {
+	a.Clear()
+	a.offset = offset
+	items := make([]twidgets.ListItem, len(artists))
+	itemTexts := make([]string, len(artists))
+	targets := make([]artTarget, len(artists))
 
 	for i, v := range artists {
 		cover := newArtistCover(v)
 		a.artists = append(a.artists, cover)
+		targets[i] = artTarget{id: v.Id, itemType: models.TypeArtist, item: cover}
 		if v.AlbumCount > 0 {
-			cover.SetText(fmt.Sprintf("%d. %s\n%d albums %s",
-				offset+i+1, v.Name, v.AlbumCount, util.SecToString(v.TotalDuration)))
+			cover.SetText(i18n.Tf("%d. %s%s\n%d albums %s",
+				offset+i+1, sourcesText(v.Sources), v.Name, v.AlbumCount, util.SecToString(v.TotalDuration)))
 		} else {
-			cover.SetText(fmt.Sprintf("%d. %s\n %s",
-				offset+i+1, v.Name, util.SecToString(v.TotalDuration)))
+			cover.SetText(fmt.Sprintf("%d. %s%s\n %s",
+				offset+i+1, sourcesText(v.Sources), v.Name, util.SecToString(v.TotalDuration)))
 		}
 		items[i] = cover
 		itemTexts[i] = strings.ToLower(cover.artist.Name)
@@ -149,6 +192,7 @@ func (a *ArtistList) SetArtists(artists []*models.Artist) {
 	a.items = items
 	a.itemsTexts = itemTexts
 	a.searchItemsSet()
+	a.artColors.load(targets)
 }
 
 func (a *ArtistList) selectArtist(index int) {
@@ -158,15 +202,62 @@ func (a *ArtistList) selectArtist(index int) {
 	}
 }
 
+// selectedArtist returns highlighted artist or nil, if there is none.
+func (a *ArtistList) selectedArtist() *models.Artist {
+	index := a.getSelectedIndex()
+	if index < 0 || index >= len(a.artists) {
+		return nil
+	}
+	return a.artists[index].artist
+}
+
 func (a *ArtistList) selectPage(n int) {
-	if a.selectPageFunc != nil {
-		a.paging.SetPage(n)
-		a.page.CurrentPage = n
+	a.paging.SetPage(n)
+	a.page.CurrentPage = n
+	if a.queryFunc != nil {
+		a.queryOpts.Paging = a.page
+		a.queryFunc(a.queryOpts)
+		a.resetReduce()
+	} else if a.selectPageFunc != nil {
 		a.selectPageFunc(a.page)
 		a.resetReduce()
 	}
 }
 
+// loadMore appends next page to artists, if there is one.
+func (a *ArtistList) loadMore() {
+	if !a.pagingEnabled || a.page.CurrentPage >= a.page.TotalPages-1 {
+		return
+	}
+	loaded := make([]*models.Artist, len(a.artists))
+	for i, v := range a.artists {
+		loaded[i] = v.artist
+	}
+	offset := a.offset
+	page := a.page
+	selected := a.getSelectedIndex()
+
+	a.artists = nil
+	a.selectPage(page.CurrentPage + 1)
+	if len(a.artists) == 0 {
+		a.SetPage(page)
+		a.queryOpts.Paging = page
+	} else {
+		for _, v := range a.artists {
+			loaded = append(loaded, v.artist)
+		}
+	}
+	a.setArtists(loaded, offset)
+	a.list.SetSelected(selected)
+}
+
+// persistSorting restores sorting from setting and saves changes to it.
+func (a *ArtistList) persistSorting(setting *string) {
+	if config.AppConfig.Gui.EnableSorting {
+		a.queryOpts.Sort = a.sort.persist(setting)
+	}
+}
+
 func (a *ArtistList) setSorting(sort interfaces.Sort) {
 	a.queryOpts.Sort = sort
 	if a.queryFunc != nil {
@@ -174,6 +265,16 @@ func (a *ArtistList) setSorting(sort interfaces.Sort) {
 	}
 }
 
+// jumpTo shows artists whose name starts with prefix, starting from first page.
+func (a *ArtistList) jumpTo(prefix string) {
+	a.queryOpts.Filter.NameStartsWith = prefix
+	a.queryOpts.Paging.CurrentPage = 0
+	if a.queryFunc != nil {
+		a.queryFunc(a.queryOpts)
+		a.resetReduce()
+	}
+}
+
 func (a *ArtistList) showReducer(visible bool) {
 	if visible {
 		a.Banner.Grid.AddItem(a.reduceInput, 5, 0, 1, 10, 1, 10, false)
@@ -187,14 +288,14 @@ func (a *ArtistList) showReducer(visible bool) {
 }
 
 type ArtistCover struct {
-	*cview.TextView
+	*highlightText
 	artist *models.Artist
 }
 
 func newArtistCover(artist *models.Artist) *ArtistCover {
 	a := &ArtistCover{
-		TextView: cview.NewTextView(),
-		artist:   artist,
+		highlightText: newHighlightText(),
+		artist:        artist,
 	}
 	a.SetBackgroundColor(config.Color.Background)
 	a.SetTextColor(config.Color.Text)
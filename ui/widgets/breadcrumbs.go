@@ -0,0 +1,196 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"regexp"
+	"strings"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+)
+
+const (
+	// maxBreadcrumbs is the maximum number of views to show in breadcrumbs.
+	maxBreadcrumbs = 9
+	// maxBreadcrumbLength is the maximum length of single breadcrumb title.
+	maxBreadcrumbLength = 24
+
+	breadcrumbSeparator = " > "
+)
+
+// matches item counts at the end of title, e.g. ': 12', ': total 12' or ': 12 songs'
+var breadcrumbCountRe = regexp.MustCompile(`:\s*(total\s+)?\d+(\s+\w+)?$`)
+
+// breadcrumbTitler is implemented by views that have a title to show in breadcrumbs.
+type breadcrumbTitler interface {
+	breadcrumbTitle() string
+}
+
+// breadcrumbs shows navigation history that leads to current view.
+// Clicking a breadcrumb goes back in history to that view.
+// If there are multiple tabs, tab numbers are shown before history.
+type breadcrumbs struct {
+	*cview.TextView
+	view Previous
+	// history are titles of previous views, starting from the oldest.
+	history []string
+	spans   [][2]int
+	tabs    string
+	// selectFunc goes back given number of views in history.
+	selectFunc func(steps int)
+}
+
+func newBreadcrumbs(selectFunc func(steps int)) *breadcrumbs {
+	b := &breadcrumbs{
+		TextView:   cview.NewTextView(),
+		selectFunc: selectFunc,
+	}
+	b.SetDynamicColors(true)
+	b.SetWrap(false)
+	b.SetBackgroundColor(config.Color.Background)
+	b.SetTextColor(config.Color.TextSecondary)
+	return b
+}
+
+// setView sets current view and titles of previous views.
+func (b *breadcrumbs) setView(p Previous, history []string) {
+	b.view = p
+	b.history = history
+	b.update()
+}
+
+// setTabs sets current tab and number of tabs.
+func (b *breadcrumbs) setTabs(current, count int) {
+	b.tabs = tabLabels(current, count)
+	b.update()
+}
+
+// update updates breadcrumbs from current view. Titles might change after
+// view has been set, so this is called every time breadcrumbs are drawn.
+func (b *breadcrumbs) update() {
+	titles := append([]string{}, b.history...)
+	if b.view != nil {
+		titles = append(titles, breadcrumbTitle(b.view))
+	}
+	b.spans = make([][2]int, len(titles))
+
+	text := b.tabs
+	x := cview.TaggedStringWidth(b.tabs)
+	for i, title := range titles {
+		if i > 0 {
+			text += breadcrumbSeparator
+			x += len(breadcrumbSeparator)
+		}
+		if i == len(titles)-1 {
+			text += "[::b]" + cview.Escape(title) + "[::-]"
+		} else {
+			text += cview.Escape(title)
+		}
+		width := cview.TaggedStringWidth(cview.Escape(title))
+		b.spans[i] = [2]int{x, x + width}
+		x += width
+	}
+	b.SetText(text)
+}
+
+// jump goes back to view at index, where 0 is the first view in breadcrumbs.
+func (b *breadcrumbs) jump(index int) {
+	if index < 0 || index >= len(b.history) {
+		return
+	}
+	if b.selectFunc != nil {
+		b.selectFunc(len(b.history) - index)
+	}
+}
+
+func (b *breadcrumbs) Draw(screen tcell.Screen) {
+	b.update()
+	b.TextView.Draw(screen)
+}
+
+// MouseHandler returns the mouse handler for this primitive.
+func (b *breadcrumbs) MouseHandler() func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+	return b.WrapMouseHandler(func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+		if !b.InRect(event.Position()) || action != cview.MouseLeftClick {
+			return false, nil
+		}
+		x, _ := event.Position()
+		rectX, _, _, _ := b.GetInnerRect()
+		for i, v := range b.spans {
+			if x-rectX >= v[0] && x-rectX < v[1] {
+				b.jump(i)
+				return true, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+func breadcrumbTitle(view Previous) string {
+	title := ""
+	if titler, ok := view.(breadcrumbTitler); ok {
+		title = titler.breadcrumbTitle()
+	}
+	if title == "" {
+		title = i18n.T("View")
+	}
+	return truncateWidth(title, maxBreadcrumbLength)
+}
+
+// descriptionTitle returns first line of description without item counts.
+func descriptionTitle(description string) string {
+	title := strings.Split(description, "\n")[0]
+	title = breadcrumbCountRe.ReplaceAllString(strings.TrimSpace(title), "")
+	return strings.TrimSpace(title)
+}
+
+func (i *itemList) breadcrumbTitle() string {
+	return descriptionTitle(i.description.GetText(true))
+}
+
+func (a *AlbumView) breadcrumbTitle() string {
+	if a.album == nil {
+		return ""
+	}
+	return a.album.Name
+}
+
+func (a *ArtistView) breadcrumbTitle() string {
+	if a.artist == nil {
+		return ""
+	}
+	return a.artist.Name
+}
+
+func (p *PlaylistView) breadcrumbTitle() string {
+	if p.playlist == nil {
+		return ""
+	}
+	return p.playlist.Name
+}
+
+func (g *GenreView) breadcrumbTitle() string {
+	return g.genre.Name
+}
+
+func (s *SearchTopList) breadcrumbTitle() string {
+	return i18n.T("Search")
+}
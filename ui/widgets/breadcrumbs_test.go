@@ -0,0 +1,60 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"gitlab.com/tslocum/cview"
+	"testing"
+)
+
+type testView struct {
+	*cview.Box
+	*previous
+	title string
+}
+
+func newTestView(title string) *testView {
+	return &testView{Box: cview.NewBox(), previous: &previous{}, title: title}
+}
+
+func (t *testView) breadcrumbTitle() string {
+	return t.title
+}
+
+func Test_descriptionTitle(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{"All Albums\nTotal 100", "All Albums"},
+		{"Genres: total 20", "Genres"},
+		{"Queue: 5 items\n20 min", "Queue"},
+		{"All songs: 500 songs", "All songs"},
+		{"Similar artists: 3", "Similar artists"},
+		{"Genre: Rock", "Genre: Rock"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := descriptionTitle(tt.description); got != tt.want {
+				t.Errorf("descriptionTitle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// castLocal is a device option for playing on this device
+var castLocal = i18n.N("This device")
+
+// cast provides a modal for selecting the device to play on.
+type cast struct {
+	*cview.Form
+	controller interfaces.SessionController
+	// selectFunc is called with selected session, or nil if playing on this device.
+	selectFunc func(session *models.Session)
+	errorFunc  func(action string, err error)
+
+	visible bool
+	closeCb func()
+
+	device   *cview.DropDown
+	sessions []*models.Session
+}
+
+func newCast(controller interfaces.SessionController, selectFunc func(session *models.Session),
+	errorFunc func(action string, err error)) *cast {
+	c := &cast{
+		Form:       cview.NewForm(),
+		controller: controller,
+		selectFunc: selectFunc,
+		errorFunc:  errorFunc,
+		device:     cview.NewDropDown(),
+	}
+
+	c.SetTitle(" Cast ")
+	c.SetBackgroundColor(config.Color.Modal.Background)
+	c.SetBorder(true)
+
+	c.device.SetLabel(i18n.T("Play on"))
+	c.device.SetFieldTextColor(config.Color.Text)
+	c.AddFormItem(c.device)
+	c.AddButton(i18n.T("Select"), c.ok)
+	c.AddButton(i18n.T("Refresh"), c.loadSessions)
+	c.AddButton(i18n.T("Cancel"), c.cancel)
+
+	for i := 0; i < c.GetButtonCount(); i++ {
+		c.GetButton(i).SetInputCapture(c.inputCapture)
+	}
+	c.device.SetInputCapture(c.inputCapture)
+	c.SetCancelFunc(c.cancel)
+	return c
+}
+
+func (c *cast) SetDoneFunc(doneFunc func()) {
+	c.closeCb = doneFunc
+}
+
+func (c *cast) View() cview.Primitive {
+	return c
+}
+
+func (c *cast) SetVisible(visible bool) {
+	c.visible = visible
+	if visible {
+		c.loadSessions()
+	}
+}
+
+// loadSessions refreshes sessions that can be controlled.
+func (c *cast) loadSessions() {
+	sessions, err := c.controller.GetSessions()
+	if err != nil {
+		c.errorFunc("get sessions", err)
+	}
+	c.sessions = sessions
+	c.device.SetOptions(nil, nil)
+	c.device.AddOption(i18n.T(castLocal), nil)
+	for _, v := range sessions {
+		c.device.AddOption(v.Label(), nil)
+	}
+	c.device.SetCurrentOption(0)
+}
+
+func (c *cast) ok() {
+	index, _ := c.device.GetCurrentOption()
+	var session *models.Session
+	if index > 0 && index <= len(c.sessions) {
+		session = c.sessions[index-1]
+	}
+	c.cancel()
+	c.selectFunc(session)
+}
+
+func (c *cast) cancel() {
+	if c.closeCb != nil {
+		c.closeCb()
+	}
+}
+
+func (c *cast) inputCapture(e *tcell.EventKey) *tcell.EventKey {
+	switch e.Key() {
+	case tcell.KeyUp:
+		return tcell.NewEventKey(tcell.KeyBacktab, e.Rune(), e.Modifiers())
+	case tcell.KeyDown:
+		return tcell.NewEventKey(tcell.KeyTab, e.Rune(), e.Modifiers())
+	}
+	return e
+}
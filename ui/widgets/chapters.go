@@ -0,0 +1,135 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/jellycli/util"
+)
+
+// chapters provides a modal for jumping to a chapter of playing song.
+type chapters struct {
+	*cview.Form
+	controller interfaces.ChapterController
+	// positionFunc returns position of playing song.
+	positionFunc func() interfaces.AudioTick
+
+	visible bool
+	closeCb func()
+
+	chapter  *cview.DropDown
+	chapters []models.Chapter
+}
+
+func newChapters(controller interfaces.ChapterController, positionFunc func() interfaces.AudioTick) *chapters {
+	c := &chapters{
+		Form:         cview.NewForm(),
+		controller:   controller,
+		positionFunc: positionFunc,
+		chapter:      cview.NewDropDown(),
+	}
+
+	c.SetTitle(" Chapters ")
+	c.SetBackgroundColor(config.Color.Modal.Background)
+	c.SetBorder(true)
+
+	c.chapter.SetLabel(i18n.T("Chapter"))
+	c.chapter.SetFieldTextColor(config.Color.Text)
+	c.AddFormItem(c.chapter)
+	c.AddButton(i18n.T("Play"), c.ok)
+	c.AddButton(i18n.T("Cancel"), c.cancel)
+
+	for i := 0; i < c.GetButtonCount(); i++ {
+		c.GetButton(i).SetInputCapture(c.inputCapture)
+	}
+	c.chapter.SetInputCapture(c.inputCapture)
+	c.SetCancelFunc(c.cancel)
+	return c
+}
+
+func (c *chapters) SetDoneFunc(doneFunc func()) {
+	c.closeCb = doneFunc
+}
+
+func (c *chapters) View() cview.Primitive {
+	return c
+}
+
+func (c *chapters) SetVisible(visible bool) {
+	c.visible = visible
+	if visible {
+		c.loadChapters()
+	}
+}
+
+// hasChapters returns true if playing song has chapters.
+func (c *chapters) hasChapters() bool {
+	return len(c.controller.GetPlayingChapters()) > 0
+}
+
+// loadChapters lists chapters of playing song and selects the playing chapter.
+func (c *chapters) loadChapters() {
+	c.chapters = c.controller.GetPlayingChapters()
+	position := c.positionFunc()
+	c.chapter.SetOptions(nil, nil)
+	selected := 0
+	for i, v := range c.chapters {
+		c.chapter.AddOption(chapterName(v), nil)
+		if interfaces.AudioTick(v.Start) <= position {
+			selected = i
+		}
+	}
+	if len(c.chapters) > 0 {
+		c.chapter.SetCurrentOption(selected)
+	}
+}
+
+func (c *chapters) ok() {
+	index, _ := c.chapter.GetCurrentOption()
+	c.cancel()
+	if index >= 0 && index < len(c.chapters) {
+		go c.controller.SeekChapter(index)
+	}
+}
+
+func (c *chapters) cancel() {
+	if c.closeCb != nil {
+		c.closeCb()
+	}
+}
+
+func (c *chapters) inputCapture(e *tcell.EventKey) *tcell.EventKey {
+	switch e.Key() {
+	case tcell.KeyUp:
+		return tcell.NewEventKey(tcell.KeyBacktab, e.Rune(), e.Modifiers())
+	case tcell.KeyDown:
+		return tcell.NewEventKey(tcell.KeyTab, e.Rune(), e.Modifiers())
+	}
+	return e
+}
+
+// chapterName returns chapter start and name.
+func chapterName(chapter models.Chapter) string {
+	return util.SecToString(chapter.Start/1000) + "  " + chapter.Name
+}
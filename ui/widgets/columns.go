@@ -0,0 +1,186 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/jellycli/util"
+)
+
+// lastPlayedFormat is the date format of last played column.
+const lastPlayedFormat = "2006-01-02"
+
+// songColumns are the configured columns of song lists per view. View without columns uses default layout.
+var songColumns map[string][]string
+
+// SetSongColumns sets columns of song lists per view, see config.SongColumnViews.
+func SetSongColumns(columns map[string][]string) {
+	songColumns = columns
+}
+
+// fixedColumnWidth returns width of column that has fixed width, or 0 if column shares free space.
+func fixedColumnWidth(column string, showDiscNum bool) int {
+	switch column {
+	case config.SongColumnTrack:
+		if showDiscNum {
+			return 6
+		}
+		return 4
+	case config.SongColumnDuration:
+		return 5
+	case config.SongColumnPlays:
+		return 5
+	case config.SongColumnYear:
+		return 4
+	case config.SongColumnRating:
+		return 5
+	case config.SongColumnLastPlayed:
+		return len(lastPlayedFormat)
+	}
+	return 0
+}
+
+// flexColumnWeight returns share of free space for column that has no fixed width.
+func flexColumnWeight(column string) int {
+	if column == config.SongColumnTitle {
+		return 2
+	}
+	return 1
+}
+
+// songColumn returns text of single column of song.
+func songColumn(song *models.Song, index int, showDiscNum bool, column string) string {
+	switch column {
+	case config.SongColumnTrack:
+		if showDiscNum {
+			return fmt.Sprintf("%d %d.", song.DiscNumber, song.Index)
+		}
+		return fmt.Sprintf("%d.", index)
+	case config.SongColumnTitle:
+		return sourcesText(song.Sources) + song.Name
+	case config.SongColumnArtist:
+		names := make([]string, len(song.Artists))
+		for i, v := range song.Artists {
+			names[i] = v.Name
+		}
+		return strings.Join(names, ", ")
+	case config.SongColumnAlbum:
+		return song.AlbumName
+	case config.SongColumnDuration:
+		return util.SecToString(song.Duration)
+	case config.SongColumnPlays:
+		if song.PlayCount == 0 {
+			return ""
+		}
+		return strconv.Itoa(song.PlayCount)
+	case config.SongColumnYear:
+		if song.Year == 0 {
+			return ""
+		}
+		return strconv.Itoa(song.Year)
+	case config.SongColumnRating:
+		if song.Rating <= 0 {
+			return ""
+		}
+		if song.Rating > 5 {
+			return strings.Repeat("*", 5)
+		}
+		return strings.Repeat("*", song.Rating)
+	case config.SongColumnLastPlayed:
+		if song.LastPlayed.IsZero() {
+			return ""
+		}
+		return song.LastPlayed.Local().Format(lastPlayedFormat)
+	}
+	return ""
+}
+
+// songColumnText formats song to single line of given columns that fits in width.
+// Fixed width columns are aligned right, except rating, and rest of the width is shared by
+// title, artist and album. If width is too narrow, columns are joined without aligning.
+func songColumnText(song *models.Song, index int, showDiscNum bool, columns []string, width int) string {
+	texts := make([]string, len(columns))
+	widths := make([]int, len(columns))
+	// leave space between columns and padding
+	free := width - len(columns) - 1
+	weights := 0
+	for i, v := range columns {
+		texts[i] = songColumn(song, index, showDiscNum, v)
+		widths[i] = fixedColumnWidth(v, showDiscNum)
+		if widths[i] == 0 {
+			weights += flexColumnWeight(v)
+		} else {
+			if w := stringWidth(texts[i]); w > widths[i] {
+				widths[i] = w
+			}
+			free -= widths[i]
+		}
+	}
+
+	if free < weights*3 {
+		parts := make([]string, 0, len(texts))
+		for _, v := range texts {
+			if v != "" {
+				parts = append(parts, v)
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+
+	// last flexible column gets remainder of free space
+	last := -1
+	shared := free
+	for i, v := range columns {
+		if widths[i] == 0 {
+			widths[i] = shared * flexColumnWeight(v) / weights
+			free -= widths[i]
+			last = i
+		}
+	}
+	if last >= 0 {
+		widths[last] += free
+	}
+
+	parts := make([]string, len(columns))
+	for i, v := range columns {
+		switch {
+		case fixedColumnWidth(v, showDiscNum) == 0, v == config.SongColumnRating:
+			parts[i] = padWidth(texts[i], widths[i])
+		default:
+			parts[i] = strings.Repeat(" ", widths[i]-stringWidth(texts[i])) + texts[i]
+		}
+	}
+	return strings.TrimRight(strings.Join(parts, " "), " ")
+}
+
+// setColumnText sets text of song from columns configured for view.
+// It returns false if view has no columns and default layout should be used instead.
+func (a *albumSong) setColumnText(view string) bool {
+	columns := songColumns[view]
+	if len(columns) == 0 {
+		return false
+	}
+	_, _, w, _ := a.GetRect()
+	a.SetText(songColumnText(a.song, a.index, a.showDiscNum, columns, w))
+	return true
+}
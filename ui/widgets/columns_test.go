@@ -0,0 +1,97 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_songColumnText(t *testing.T) {
+	song := &models.Song{
+		Name:      "Song",
+		Duration:  185,
+		Artists:   []models.IdName{{Name: "A"}, {Name: "B"}},
+		AlbumName: "Album",
+		Year:      2001,
+		Rating:    3,
+	}
+	long := &models.Song{Name: "A very long song title", Duration: 185}
+	played := &models.Song{Name: "Song", PlayCount: 12, LastPlayed: time.Date(2021, 3, 4, 12, 0, 0, 0, time.Local)}
+
+	tests := []struct {
+		name    string
+		song    *models.Song
+		columns []string
+		width   int
+		want    string
+	}{
+		{
+			name:    "default columns",
+			song:    song,
+			columns: []string{"track", "title", "duration"},
+			width:   30,
+			want:    "  3. Song" + strings.Repeat(" ", 13) + "  3:05",
+		},
+		{
+			name:    "too narrow",
+			song:    song,
+			columns: []string{"track", "title", "duration"},
+			width:   10,
+			want:    "3. Song 3:05",
+		},
+		{
+			name:    "shared width",
+			song:    song,
+			columns: []string{"title", "artist", "album", "year", "rating"},
+			width:   40,
+			want:    "Song" + strings.Repeat(" ", 9) + "A, B   Album   2001 ***",
+		},
+		{
+			name:    "truncate title",
+			song:    long,
+			columns: []string{"title", "duration"},
+			width:   20,
+			want:    "A very long" + symbol.ellipsis + "  3:05",
+		},
+		{
+			name:    "empty columns",
+			song:    long,
+			columns: []string{"plays", "year", "title"},
+			width:   40,
+			want:    strings.Repeat(" ", 11) + "A very long song title",
+		},
+		{
+			name:    "play count and last played",
+			song:    played,
+			columns: []string{"title", "plays", "last_played"},
+			width:   30,
+			want:    "Song" + strings.Repeat(" ", 8) + "   12 2021-03-04",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := songColumnText(tt.song, 3, false, tt.columns, tt.width); got != tt.want {
+				t.Errorf("songColumnText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -19,7 +19,13 @@
 package widgets
 
 import (
+	"context"
+	"fmt"
 	"github.com/sirupsen/logrus"
+	"strings"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/util"
 )
@@ -33,6 +39,14 @@ type contextOperator interface {
 	ViewArtist(artist *models.Artist)
 	InstantMix(item models.Item)
 	OpenInBrowser(item models.Item)
+	CopyLink(item models.Item)
+	CopyInfo(item models.Item)
+	AdjustGain(item models.Item)
+	Download(item models.Item)
+	FavoriteAlbums(albums []*models.Album, favorite bool)
+	DownloadAlbums(albums []*models.Album)
+	Export(item models.Item)
+	CopyPlaylist(playlist *models.Playlist)
 }
 
 func (w *Window) AddSongToPlaylist(song *models.Song) error {
@@ -48,21 +62,22 @@ func (w *Window) ViewArtist(artist *models.Artist) {
 }
 
 func (w *Window) ViewSongArtist(song *models.Song) {
-	_, artist, err := w.mediaItems.GetSongArtistAlbum(song)
-	if err != nil {
-		logrus.Errorf("View song album: %v", err)
-		return
-	}
-	w.selectArtist(artist)
+	w.requests.load("view song artist", func(ctx context.Context) (func(), error) {
+		_, artist, err := w.mediaItems.GetSongArtistAlbum(ctx, song)
+		return func() {
+			w.selectArtist(artist)
+		}, err
+	})
 }
 
 func (w *Window) ViewSongAlbum(song *models.Song) {
-	album, _, err := w.mediaItems.GetSongArtistAlbum(song)
-	if err != nil {
-		logrus.Errorf("View song album: %v", err)
-		return
-	}
-	w.selectAlbum(album)
+	w.requests.load("view song album", func(ctx context.Context) (func(), error) {
+		album, _, err := w.mediaItems.GetSongArtistAlbum(ctx, song)
+		if err != nil {
+			return nil, err
+		}
+		return w.loadAlbum(ctx, album)
+	})
 }
 
 func (w *Window) InstantMix(item models.Item) {
@@ -71,20 +86,257 @@ func (w *Window) InstantMix(item models.Item) {
 		return
 	}
 
-	songs, err := w.mediaItems.GetInstantMix(item)
+	songs, err := w.mediaItems.GetInstantMix(context.Background(), item)
 	if err != nil {
-		logrus.Errorf("get instant mix: %v", err)
+		w.notifyError("get instant mix", err)
+		return
+	}
+
+	if w.castTarget != nil {
+		w.castSongs(w.castTarget, songs, true)
 		return
 	}
 
 	w.mediaPlayer.StopMedia()
 	w.mediaQueue.ClearQueue(true)
-	w.mediaQueue.AddSongs(songs)
+	w.addToQueue(i18n.Tf("Instant mix: %s", item.GetName()), songs)
+	w.notifyInfo(i18n.Tf("Playing instant mix: %d songs", len(songs)))
 }
 
+// OpenInBrowser opens item in server's web interface. Over ssh link is copied to clipboard instead,
+// since browser would open on the remote machine.
 func (w *Window) OpenInBrowser(item models.Item) {
 	url := w.mediaItems.GetLink(item)
-	if url != "" {
-		util.OpenUrlInBrowser(url)
+	if url == "" {
+		w.notifyInfo(i18n.T("Server has no web link for item"))
+		return
+	}
+	if util.IsRemoteSession() {
+		w.copyToClipboard(url)
+		return
+	}
+	err := util.OpenUrlInBrowser(url)
+	if err != nil {
+		w.notifyError("open in browser", err)
+	}
+}
+
+// CopyLink copies link to item in server's web interface to clipboard.
+func (w *Window) CopyLink(item models.Item) {
+	url := w.mediaItems.GetLink(item)
+	if url == "" {
+		w.notifyInfo(i18n.T("Server has no web link for item"))
+		return
+	}
+	w.copyToClipboard(url)
+}
+
+// CopyInfo copies song or album formatted with gui.copy_format to clipboard.
+func (w *Window) CopyInfo(item models.Item) {
+	status := interfaces.AudioStatus{}
+	switch v := item.(type) {
+	case *models.Song:
+		status.Song = v
+		album, _, err := w.mediaItems.GetSongArtistAlbum(context.Background(), v)
+		if err != nil {
+			logrus.Warningf("get song album to copy: %v", err)
+		}
+		status.Album = album
+		if len(v.Artists) > 0 {
+			status.Artist = &models.Artist{Id: v.Artists[0].Id, Name: v.Artists[0].Name}
+		}
+	case *models.Album:
+		status.Song = &models.Song{Name: v.Name}
+		status.Album = v
+		if len(v.AdditionalArtists) > 0 {
+			status.Artist = &models.Artist{Id: v.AdditionalArtists[0].Id, Name: v.AdditionalArtists[0].Name}
+		}
+	default:
+		logrus.Warningf("cannot copy info of item type %v", item.GetType())
+		return
+	}
+	w.copyToClipboard(formatCopyText(config.AppConfig.Gui.CopyFormat, status))
+}
+
+// copyPlaying copies playing song formatted with gui.copy_format to clipboard.
+func (w *Window) copyPlaying() {
+	status, ok := w.status.playingStatus()
+	if !ok {
+		w.notifyInfo(i18n.T("Nothing is playing"))
+		return
+	}
+	w.copyToClipboard(formatCopyText(config.AppConfig.Gui.CopyFormat, status))
+}
+
+// formatCopyText replaces status tokens in format, see interfaces.StatusTokens.
+func formatCopyText(format string, status interfaces.AudioStatus) string {
+	text := strings.NewReplacer(interfaces.StatusTokens(status)...).Replace(format)
+	return strings.TrimSpace(text)
+}
+
+func (w *Window) copyToClipboard(text string) {
+	err := util.CopyToClipboard(text)
+	if err != nil {
+		w.notifyError("copy to clipboard", err)
+		return
+	}
+	w.notifyInfo(i18n.T("Copied to clipboard"))
+}
+
+// Download downloads song, album or playlist for offline playback.
+func (w *Window) Download(item models.Item) {
+	if w.downloadController == nil {
+		w.notifyError("download", interfaces.ErrDownloadsNotSupported)
+		return
+	}
+	var songs []*models.Song
+	var err error
+	switch v := item.(type) {
+	case *models.Song:
+		songs = []*models.Song{v}
+	case *models.Album:
+		songs, err = w.mediaItems.GetAlbumSongs(context.Background(), v.Id)
+	case *models.Playlist:
+		err = w.mediaItems.GetPlaylistSongs(context.Background(), v)
+		songs = v.Songs
+	default:
+		logrus.Warningf("cannot download item of type %v", item.GetType())
+		return
+	}
+	if err != nil {
+		w.notifyError("get songs to download", err)
+		return
+	}
+	w.downloadController.DownloadSongs(songs)
+	w.notifyInfo(i18n.Tf("Downloading %d songs", len(songs)))
+}
+
+// FavoriteAlbums adds albums to favorites or removes them from favorites in background.
+// Progress is shown in notification bar.
+func (w *Window) FavoriteAlbums(albums []*models.Album, favorite bool) {
+	controller, ok := w.mediaPlayer.(interfaces.FavoriteController)
+	if !ok {
+		w.notifyError("favorite albums", interfaces.ErrFavoritesNotSupported)
+		return
+	}
+	if len(albums) == 0 {
+		w.notifyInfo(i18n.T("No albums selected"))
+		return
+	}
+	go func() {
+		failed := 0
+		for i, v := range albums {
+			if favorite {
+				w.notifyInfo(i18n.Tf("Adding albums to favorites: %d / %d", i+1, len(albums)))
+			} else {
+				w.notifyInfo(i18n.Tf("Removing albums from favorites: %d / %d", i+1, len(albums)))
+			}
+			err := controller.SetFavorite(v, favorite)
+			if err != nil {
+				logrus.Errorf("set album %s favorite: %v", v.Id, err)
+				failed++
+				continue
+			}
+			v.Favorite = favorite
+		}
+		if failed > 0 {
+			w.notifyError("favorite albums",
+				fmt.Errorf("%d of %d albums failed, see log for details", failed, len(albums)))
+		} else if favorite {
+			w.notifyInfo(i18n.Tf("Added %d albums to favorites", len(albums)))
+		} else {
+			w.notifyInfo(i18n.Tf("Removed %d albums from favorites", len(albums)))
+		}
+	}()
+}
+
+// DownloadAlbums downloads albums for offline playback. Songs are queued for download album by album
+// in background, and progress is shown in notification bar.
+func (w *Window) DownloadAlbums(albums []*models.Album) {
+	if w.downloadController == nil {
+		w.notifyError("download albums", interfaces.ErrDownloadsNotSupported)
+		return
+	}
+	if len(albums) == 0 {
+		w.notifyInfo(i18n.T("No albums selected"))
+		return
+	}
+	go func() {
+		failed := 0
+		songs := 0
+		for i, v := range albums {
+			w.notifyInfo(i18n.Tf("Queueing albums for download: %d / %d", i+1, len(albums)))
+			albumSongs, err := w.mediaItems.GetAlbumSongs(context.Background(), v.Id)
+			if err != nil {
+				logrus.Errorf("get album %s songs to download: %v", v.Id, err)
+				failed++
+				continue
+			}
+			w.downloadController.DownloadSongs(albumSongs)
+			songs += len(albumSongs)
+		}
+		if failed > 0 {
+			w.notifyError("download albums",
+				fmt.Errorf("%d of %d albums failed, see log for details", failed, len(albums)))
+		} else {
+			w.notifyInfo(i18n.Tf("Downloading %d songs from %d albums", songs, len(albums)))
+		}
+	}()
+}
+
+// AdjustGain opens modal for setting volume offset of song or album.
+func (w *Window) AdjustGain(item models.Item) {
+	if w.gain == nil {
+		w.notifyError("set volume offset", interfaces.ErrGainNotSupported)
+		return
+	}
+	if item == nil {
+		return
+	}
+	w.gain.SetItem(item)
+	w.showModal(w.gain, 7, 50, false)
+}
+
+// Export exports album or playlist to playlist file.
+func (w *Window) Export(item models.Item) {
+	var songs []*models.Song
+	var err error
+	switch v := item.(type) {
+	case *models.Album:
+		songs, err = w.mediaItems.GetAlbumSongs(context.Background(), v.Id)
+	case *models.Playlist:
+		err = w.mediaItems.GetPlaylistSongs(context.Background(), v)
+		songs = v.Songs
+	default:
+		logrus.Warningf("cannot export item of type %v", item.GetType())
+		return
+	}
+	if err != nil {
+		w.notifyError("get songs to export", err)
+		return
+	}
+	w.showExport(item.GetName(), songs)
+}
+
+// CopyPlaylist copies playlist, e.g. one shared by another user, into user's own playlists in background.
+func (w *Window) CopyPlaylist(playlist *models.Playlist) {
+	copier, ok := w.mediaPlayer.(interfaces.PlaylistCopier)
+	if !ok {
+		w.notifyError("copy playlist", interfaces.ErrCopyNotSupported)
+		return
 	}
+	w.notifyInfo(i18n.Tf("Copying playlist %s", playlist.Name))
+	go func() {
+		copied, err := copier.CopyPlaylist(playlist, "")
+		w.app.QueueUpdateDraw(func() {
+			if err != nil {
+				w.notifyError("copy playlist", err)
+				return
+			}
+			w.notifyInfo(i18n.Tf("Created playlist '%s' with %d songs", copied.Name, copied.SongCount))
+			if w.mediaSelected && w.selectedMedia == MediaPlaylists {
+				w.selectMedia(MediaPlaylists)
+			}
+		})
+	}()
 }
@@ -0,0 +1,67 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"testing"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_formatCopyText(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		status interfaces.AudioStatus
+		want   string
+	}{
+		{
+			name:   "song",
+			format: "{artist} – {title}",
+			status: interfaces.AudioStatus{
+				Song:   &models.Song{Name: "Song"},
+				Artist: &models.Artist{Name: "Artist"},
+				Album:  &models.Album{Name: "Album", Year: 1999},
+			},
+			want: "Artist – Song",
+		},
+		{
+			name:   "album tokens",
+			format: "{title} ({album}, {year})",
+			status: interfaces.AudioStatus{
+				Song:  &models.Song{Name: "Song"},
+				Album: &models.Album{Name: "Album", Year: 1999},
+			},
+			want: "Song (Album, 1999)",
+		},
+		{
+			name:   "missing artist",
+			format: "{title} {artist}",
+			status: interfaces.AudioStatus{Song: &models.Song{Name: "Song"}},
+			want:   "Song",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatCopyText(tt.format, tt.status); got != tt.want {
+				t.Errorf("formatCopyText() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
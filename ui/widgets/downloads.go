@@ -0,0 +1,122 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/jellycli/util"
+	"tryffel.net/go/twidgets"
+)
+
+// Downloads shows songs downloaded for offline playback and progress of running downloads.
+type Downloads struct {
+	*Queue
+	downloads []*models.Download
+
+	removeFunc func(song *models.Song)
+}
+
+// NewDownloads initializes downloads view. RemoveFunc is called when user removes song with delete key.
+func NewDownloads(removeFunc func(song *models.Song)) *Downloads {
+	d := &Downloads{
+		Queue:      NewQueue(),
+		removeFunc: removeFunc,
+	}
+	d.Banner.Grid.RemoveItem(d.clearBtn)
+	d.Banner.Selectable = []twidgets.Selectable{d.prevBtn, d.exportBtn, d.list}
+	d.list.SetInputCapture(d.listHandler)
+	d.printDescription()
+	return d
+}
+
+// SetDownloads clears current downloads and sets new ones.
+func (d *Downloads) SetDownloads(downloads []*models.Download) {
+	d.downloads = downloads
+	songs := make([]*models.Song, len(downloads))
+	for i, v := range downloads {
+		songs[i] = v.Song
+	}
+	index := d.list.GetSelectedIndex()
+	d.Queue.SetSongs(songs)
+	for i := range d.songs {
+		d.songs[i].playing = false
+		d.songs[i].updateTextFunc = d.updateSongText
+	}
+	d.list.SetSelected(index)
+	d.printDescription()
+}
+
+func (d *Downloads) printDescription() {
+	text := i18n.T("Downloads")
+	if len(d.downloads) > 0 {
+		var size int64
+		for _, v := range d.downloads {
+			if v.State == models.DownloadDone {
+				size += v.Size
+			}
+		}
+		text += fmt.Sprintf(": %d songs\n%s downloaded", len(d.downloads), util.BytesToString(size))
+	}
+	d.description.SetText(text)
+}
+
+func (d *Downloads) listHandler(key *tcell.EventKey) *tcell.EventKey {
+	switch key.Key() {
+	case tcell.KeyEnter:
+		return nil
+	case tcell.KeyDEL, tcell.KeyDelete:
+		index := d.getSelectedIndex()
+		if d.removeFunc != nil && index >= 0 && index < len(d.downloads) {
+			d.removeFunc(d.downloads[index].Song)
+		}
+	}
+	return key
+}
+
+func (d *Downloads) updateSongText(song *albumSong) {
+	text := song.getAlignedDuration(fmt.Sprintf("%d. %s", song.index, song.song.Name))
+	text += "\n     "
+	if len(song.song.Artists) > 0 {
+		text += song.song.Artists[0].Name + "  "
+	}
+	if song.index > 0 && song.index <= len(d.downloads) {
+		text += downloadStatus(d.downloads[song.index-1])
+	}
+	song.SetText(text)
+}
+
+// downloadStatus returns state and progress of download.
+func downloadStatus(download *models.Download) string {
+	switch download.State {
+	case models.DownloadQueued:
+		return "queued"
+	case models.DownloadRunning:
+		if progress := download.Progress(); progress >= 0 {
+			return fmt.Sprintf("downloading %d%%", progress)
+		}
+		return "downloading " + util.BytesToString(download.Downloaded)
+	case models.DownloadFailed:
+		return "failed: " + download.Error
+	default:
+		return util.BytesToString(download.Size)
+	}
+}
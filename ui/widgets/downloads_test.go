@@ -0,0 +1,65 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_downloadStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		download *models.Download
+		want     string
+	}{
+		{
+			name:     "queued",
+			download: &models.Download{State: models.DownloadQueued},
+			want:     "queued",
+		},
+		{
+			name:     "running",
+			download: &models.Download{State: models.DownloadRunning, Size: 2000, Downloaded: 500},
+			want:     "downloading 25%",
+		},
+		{
+			name:     "running unknown size",
+			download: &models.Download{State: models.DownloadRunning, Downloaded: 2 * 1024 * 1024},
+			want:     "downloading 2.0 MB",
+		},
+		{
+			name:     "failed",
+			download: &models.Download{State: models.DownloadFailed, Error: "not found"},
+			want:     "failed: not found",
+		},
+		{
+			name:     "done",
+			download: &models.Download{State: models.DownloadDone, Size: 3 * 1024 * 1024},
+			want:     "3.0 MB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := downloadStatus(tt.download); got != tt.want {
+				t.Errorf("downloadStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"fmt"
+	"strings"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// Duplicates shows groups of songs that are probably duplicates. Songs are numbered by their group.
+type Duplicates struct {
+	*SongList
+	groups [][]*models.Song
+	// group is index of group for each song in list.
+	group []int
+
+	favoriteFunc func(song *models.Song) error
+	queueFunc    func(songs []*models.Song)
+}
+
+// NewDuplicates initializes duplicates view. FavoriteFunc marks song as favorite and queueFunc adds
+// songs to queue so that they can be compared.
+func NewDuplicates(playSong func(song *models.Song), playSongs func(songs []*models.Song),
+	operator contextOperator, favoriteFunc func(song *models.Song) error,
+	queueFunc func(songs []*models.Song)) *Duplicates {
+	d := &Duplicates{
+		SongList:     NewSongList(playSong, playSongs, operator),
+		favoriteFunc: favoriteFunc,
+		queueFunc:    queueFunc,
+	}
+	d.title = i18n.T("Duplicates")
+
+	d.list.AddContextItem(i18n.T("Favorite"), 0, func(index int) {
+		d.favorite()
+	})
+	d.list.AddContextItem(i18n.T("Queue duplicates to compare"), 0, func(index int) {
+		d.queueGroup()
+	})
+	d.printDescription()
+	return d
+}
+
+// SetGroups clears current songs and shows given duplicate groups.
+func (d *Duplicates) SetGroups(groups [][]*models.Song) {
+	d.groups = groups
+	d.group = d.group[:0]
+	songs := make([]*models.Song, 0, len(groups)*2)
+	for i, group := range groups {
+		for _, song := range group {
+			songs = append(songs, song)
+			d.group = append(d.group, i)
+		}
+	}
+
+	page := interfaces.DefaultPaging()
+	page.PageSize = len(songs)
+	if page.PageSize == 0 {
+		page.PageSize = 1
+	}
+	page.SetTotalItems(len(songs))
+	d.setSongs(songs, page, 0)
+	for i, v := range d.songs {
+		v.index = d.group[i] + 1
+		v.updateTextFunc = d.updateSongText
+		v.setText()
+	}
+	d.printDescription()
+}
+
+func (d *Duplicates) printDescription() {
+	d.description.SetText(duplicatesDescription(d.groups))
+}
+
+// duplicatesDescription returns number of groups and songs in them.
+func duplicatesDescription(groups [][]*models.Song) string {
+	songs := 0
+	for _, v := range groups {
+		songs += len(v)
+	}
+	return i18n.Tf("Duplicates: %d groups, %d songs", len(groups), songs)
+}
+
+func (d *Duplicates) favorite() {
+	selected := d.getSelectedIndex()
+	if d.favoriteFunc == nil || selected < 0 || selected >= len(d.songs) {
+		return
+	}
+	song := d.songs[selected]
+	if d.favoriteFunc(song.song) != nil {
+		return
+	}
+	song.song.Favorite = true
+	song.setText()
+}
+
+func (d *Duplicates) queueGroup() {
+	selected := d.getSelectedIndex()
+	if d.queueFunc == nil || selected < 0 || selected >= len(d.group) {
+		return
+	}
+	d.queueFunc(d.groups[d.group[selected]])
+}
+
+func (d *Duplicates) updateSongText(song *albumSong) {
+	name := fmt.Sprintf("%d. %s%s", song.index, sourcesText(song.song.Sources), song.song.Name)
+	text := song.getAlignedDuration(name)
+	text += "\n     " + duplicateInfo(song.song)
+	song.SetText(text)
+}
+
+// duplicateInfo returns details that help telling duplicate songs apart: artist, file format and favorite.
+func duplicateInfo(song *models.Song) string {
+	info := make([]string, 0, 3)
+	if len(song.Artists) > 0 {
+		info = append(info, song.Artists[0].Name)
+	}
+	if file := songsFileInfo([]*models.Song{song}); file != "" {
+		info = append(info, file)
+	}
+	if song.Favorite {
+		info = append(info, symbol.favorite)
+	}
+	return strings.Join(info, "  ")
+}
@@ -0,0 +1,61 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_duplicatesDescription(t *testing.T) {
+	groups := [][]*models.Song{
+		{{Id: "song-1"}, {Id: "song-2"}},
+		{{Id: "song-3"}, {Id: "song-4"}, {Id: "song-5"}},
+	}
+	want := "Duplicates: 2 groups, 5 songs"
+	if got := duplicatesDescription(groups); got != want {
+		t.Errorf("duplicatesDescription() = %v, want %v", got, want)
+	}
+}
+
+func Test_duplicateInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		song *models.Song
+		want string
+	}{
+		{
+			name: "artist and file",
+			song: &models.Song{Artists: []models.IdName{{Name: "Artist"}}, Codec: "flac", Bitrate: 900},
+			want: "Artist  FLAC  900 kbps",
+		},
+		{
+			name: "favorite",
+			song: &models.Song{Favorite: true},
+			want: symbol.favorite,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := duplicateInfo(tt.song); got != tt.want {
+				t.Errorf("duplicateInfo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package widgets
+
+import (
+	"errors"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"os"
+	"path/filepath"
+	"strings"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// export provides a modal for exporting songs to m3u playlist file.
+type export struct {
+	*cview.Form
+	exporter interfaces.PlaylistExporter
+	// exportedFunc is called after songs have been written to file.
+	exportedFunc func(file string, songs int)
+	errorFunc    func(action string, err error)
+
+	visible bool
+	closeCb func()
+
+	file        *cview.InputField
+	preferLocal *cview.Checkbox
+
+	name  string
+	songs []*models.Song
+}
+
+func newExport(exporter interfaces.PlaylistExporter, exportedFunc func(file string, songs int),
+	errorFunc func(action string, err error)) *export {
+	e := &export{
+		Form:         cview.NewForm(),
+		exporter:     exporter,
+		exportedFunc: exportedFunc,
+		errorFunc:    errorFunc,
+		file:         cview.NewInputField(),
+		preferLocal:  cview.NewCheckbox(),
+	}
+
+	e.SetTitle(" Export playlist ")
+	e.SetBackgroundColor(config.Color.Modal.Background)
+	e.SetBorder(true)
+
+	e.file.SetLabel(i18n.T("File"))
+	e.file.SetFieldWidth(40)
+	e.file.SetFieldTextColor(config.Color.Text)
+	e.AddFormItem(e.file)
+	e.preferLocal.SetLabel(i18n.T("Use downloaded files"))
+	e.preferLocal.SetChecked(true)
+	e.AddFormItem(e.preferLocal)
+	e.AddButton(i18n.T("Export"), e.ok)
+	e.AddButton(i18n.T("Cancel"), e.cancel)
+
+	for i := 0; i < e.GetButtonCount(); i++ {
+		e.GetButton(i).SetInputCapture(e.inputCapture)
+	}
+	e.file.SetInputCapture(e.inputCapture)
+	e.preferLocal.SetInputCapture(e.inputCapture)
+	e.SetCancelFunc(e.cancel)
+	return e
+}
+
+func (e *export) SetDoneFunc(doneFunc func()) {
+	e.closeCb = doneFunc
+}
+
+func (e *export) View() cview.Primitive {
+	return e
+}
+
+func (e *export) SetVisible(visible bool) {
+	e.visible = visible
+}
+
+// SetSongs sets songs to export. Name is playlist name, which is also the default file name.
+func (e *export) SetSongs(name string, songs []*models.Song) {
+	e.name = name
+	e.songs = songs
+	e.file.SetText(filepath.Join("~", playlistFileName(name)))
+}
+
+func (e *export) ok() {
+	file, err := expandHome(strings.TrimSpace(e.file.GetText()))
+	if err == nil && file == "" {
+		err = errors.New("file name is empty")
+	}
+	if err != nil {
+		e.errorFunc("export playlist", err)
+		return
+	}
+	count, err := e.exporter.ExportPlaylist(e.name, e.songs, file, e.preferLocal.IsChecked())
+	if err != nil {
+		e.errorFunc("export playlist", err)
+		return
+	}
+	e.cancel()
+	e.exportedFunc(file, count)
+}
+
+func (e *export) cancel() {
+	if e.closeCb != nil {
+		e.closeCb()
+	}
+}
+
+func (e *export) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyUp:
+		return tcell.NewEventKey(tcell.KeyBacktab, event.Rune(), event.Modifiers())
+	case tcell.KeyDown:
+		return tcell.NewEventKey(tcell.KeyTab, event.Rune(), event.Modifiers())
+	}
+	return event
+}
+
+// playlistFileName returns m3u8 file name for playlist name, without characters that are invalid in file names.
+func playlistFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		name = "playlist"
+	}
+	return name + ".m3u8"
+}
+
+// expandHome replaces leading ~ with user home directory.
+func expandHome(file string) (string, error) {
+	if file != "~" && !strings.HasPrefix(file, "~/") && !strings.HasPrefix(file, "~"+string(filepath.Separator)) {
+		return file, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, file[1:]), nil
+}
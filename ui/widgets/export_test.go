@@ -0,0 +1,69 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package widgets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_playlistFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Queue", want: "Queue.m3u8"},
+		{name: " AC/DC: Best? ", want: "AC_DC_ Best_.m3u8"},
+		{name: "", want: "playlist.m3u8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := playlistFileName(tt.name); got != tt.want {
+				t.Errorf("playlistFileName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_expandHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		file string
+		want string
+	}{
+		{file: "~/music/a.m3u", want: filepath.Join(home, "music/a.m3u")},
+		{file: "~", want: home},
+		{file: "/tmp/a.m3u", want: "/tmp/a.m3u"},
+		{file: "~user/a.m3u", want: "~user/a.m3u"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got, err := expandHome(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("expandHome() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
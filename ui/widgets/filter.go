@@ -26,8 +26,11 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/ui/widgets/modal"
 )
 
@@ -45,25 +48,34 @@ type sort struct {
 	*dropDown
 	currentIndex int
 	mode         interfaces.SortMode
+	options      []interfaces.SortField
 
 	sortFunc sortFunc
+	// setting, if set, is updated and saved to config file every time sorting changes.
+	setting *string
 }
 
 func newSort(sortFunc sortFunc, options ...interfaces.SortField) *sort {
 	s := &sort{
-		dropDown:     newDropDown("Sort"),
+		dropDown:     newDropDown(i18n.T("Sort")),
 		currentIndex: 0,
 		mode:         interfaces.SortAsc,
-		sortFunc:     sortFunc,
+		options:      options,
 	}
 
 	s.SetTextOptions("", "", sortAsc, "", "")
 
 	for i, v := range options {
+		index := i
+		field := v
 		s.AddOption(string(v), func() {
-			s.setSorting(i, v)
+			s.setSorting(index, field)
 		})
 	}
+	s.SetCurrentOption(0)
+	s.mode = interfaces.SortAsc
+	s.SetTextOptions("", "", sortAsc, "", "")
+	s.sortFunc = sortFunc
 	return s
 }
 
@@ -72,15 +84,52 @@ func (s *sort) setSorting(newIndex int, field interfaces.SortField) {
 		s.toggleMode()
 	} else {
 		s.SetTextOptions("", "", sortAsc, "", "")
+		s.mode = interfaces.SortAsc
 		s.currentIndex = newIndex
 	}
 
 	if s.sortFunc != nil {
 		sort := interfaces.Sort{Mode: string(s.mode), Field: field}
+		if s.setting != nil {
+			saveSorting(s.setting, sort)
+		}
 		s.sortFunc(sort)
 	}
 }
 
+// persist loads sorting from setting and saves every change to it. It returns loaded sorting,
+// or default sorting if setting is empty or contains a field that is not available.
+func (s *sort) persist(setting *string) interfaces.Sort {
+	s.setting = setting
+	sort := interfaces.SortFromString(*setting)
+	for i, v := range s.options {
+		if v != sort.Field {
+			continue
+		}
+		sortFunc := s.sortFunc
+		s.sortFunc = nil
+		s.SetCurrentOption(i)
+		s.sortFunc = sortFunc
+		s.currentIndex = i
+		s.mode = interfaces.SortMode(sort.Mode)
+		if s.mode == interfaces.SortDesc {
+			s.SetTextOptions("", "", sortDesc, "", "")
+		} else {
+			s.SetTextOptions("", "", sortAsc, "", "")
+		}
+		return sort
+	}
+	return interfaces.NewSort(s.options[0])
+}
+
+func saveSorting(setting *string, sort interfaces.Sort) {
+	*setting = sort.String()
+	err := config.SaveConfig()
+	if err != nil {
+		logrus.Errorf("save sorting: %v", err)
+	}
+}
+
 func (s *sort) toggleMode() {
 	if s.mode == interfaces.SortAsc {
 		s.mode = interfaces.SortDesc
@@ -91,6 +140,52 @@ func (s *sort) toggleMode() {
 	}
 }
 
+// jumpAll is a jump option that shows all items
+var jumpAll = i18n.N("All")
+
+type jumpFunc = func(prefix string)
+
+// jump provides dropdown for jumping to items that start with given letter.
+// Typing a letter while dropdown is open selects the letter.
+type jump struct {
+	*dropDown
+	jumpFunc jumpFunc
+}
+
+func newJump(jumpFunc jumpFunc) *jump {
+	j := &jump{
+		dropDown: newDropDown(i18n.T("Jump")),
+	}
+
+	options := []string{jumpAll, interfaces.NameStartsOther}
+	for r := 'A'; r <= 'Z'; r++ {
+		options = append(options, string(r))
+	}
+
+	for _, v := range options {
+		prefix := v
+		if prefix == jumpAll {
+			prefix = ""
+		}
+		j.AddOption(i18n.T(v), func() {
+			if j.jumpFunc != nil {
+				j.jumpFunc(prefix)
+			}
+		})
+	}
+	j.SetCurrentOption(0)
+	j.jumpFunc = jumpFunc
+	return j
+}
+
+// reset selects jumpAll without calling jumpFunc.
+func (j *jump) reset() {
+	jumpFunc := j.jumpFunc
+	j.jumpFunc = nil
+	j.SetCurrentOption(0)
+	j.jumpFunc = jumpFunc
+}
+
 // filter provides a modal for defining filters
 type filter struct {
 	*cview.Form
@@ -105,10 +200,26 @@ type filter struct {
 	itemFavorite *cview.Checkbox
 
 	yearRange *cview.InputField
+	decade    *cview.DropDown
+
+	// composer options are loaded with composersFunc when filter is shown first time.
+	composer        *cview.DropDown
+	composers       []*models.IdName
+	composersLoaded bool
+	composersFunc   func() ([]*models.IdName, error)
 
 	filterChangedFunc func(bool)
 }
 
+// decadeAny is a decade option that does not limit years
+var decadeAny = i18n.N("Any")
+
+// composerAny is a composer option that does not limit composers
+var composerAny = i18n.N("Any")
+
+// oldestDecade is the oldest decade to show in filter
+const oldestDecade = 1950
+
 func (f *filter) SetDoneFunc(doneFunc func()) {
 	f.closeCb = doneFunc
 }
@@ -119,6 +230,62 @@ func (f *filter) View() cview.Primitive {
 
 func (f *filter) SetVisible(visible bool) {
 	f.visible = visible
+	if visible {
+		f.loadComposers()
+	}
+}
+
+// enableComposers adds composer selection to filter. Composers are loaded with composersFunc.
+func (f *filter) enableComposers(composersFunc func() ([]*models.IdName, error)) {
+	f.composersFunc = composersFunc
+	f.AddFormItem(f.composer)
+}
+
+func (f *filter) loadComposers() {
+	if f.composersFunc == nil || f.composersLoaded {
+		return
+	}
+	composers, err := f.composersFunc()
+	if err != nil {
+		logrus.Errorf("load composers: %v", err)
+		return
+	}
+	f.composersLoaded = true
+	for _, v := range composers {
+		if f.composerIndex(v.Id) == -1 {
+			f.addComposer(v)
+		}
+	}
+}
+
+func (f *filter) addComposer(composer *models.IdName) {
+	f.composers = append(f.composers, composer)
+	f.composer.AddOption(composer.Name, nil)
+}
+
+// composerIndex returns index of composer in composers or -1 if not found.
+func (f *filter) composerIndex(id models.Id) int {
+	for i, v := range f.composers {
+		if v.Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// setComposers selects first composer, adding it to options if needed. Empty composers selects any composer.
+func (f *filter) setComposers(composers []models.IdName) {
+	if len(composers) == 0 {
+		f.composer.SetCurrentOption(0)
+		return
+	}
+	index := f.composerIndex(composers[0].Id)
+	if index == -1 {
+		composer := composers[0]
+		f.addComposer(&composer)
+		index = len(f.composers) - 1
+	}
+	f.composer.SetCurrentOption(index + 1)
 }
 
 func newFilter(itemType string, filterFunc func(f interfaces.Filter), filterChangedFunc func(bool)) *filter {
@@ -131,11 +298,13 @@ func newFilter(itemType string, filterFunc func(f interfaces.Filter), filterChan
 		itemNotPlayed: cview.NewCheckbox(),
 		itemFavorite:  cview.NewCheckbox(),
 		yearRange:     cview.NewInputField(),
+		decade:        cview.NewDropDown(),
+		composer:      cview.NewDropDown(),
 
 		filterChangedFunc: filterChangedFunc,
 	}
 
-	f.SetTitle(fmt.Sprintf(" Filter %ss ", itemType))
+	f.SetTitle(i18n.Tf(" Filter %ss ", itemType))
 	f.SetBackgroundColor(config.Color.Modal.Background)
 	f.SetBorder(true)
 	f.AddFormItem(f.itemPlayed)
@@ -146,23 +315,38 @@ func newFilter(itemType string, filterFunc func(f interfaces.Filter), filterChan
 
 	f.yearRange.SetAcceptanceFunc(validateYearRange)
 
-	f.itemPlayed.SetLabel("Played")
-	f.itemNotPlayed.SetLabel("Not played")
-	f.itemFavorite.SetLabel("Favorite")
-	f.yearRange.SetLabel("Year")
-	f.yearRange.SetPlaceholder("'2020' or '2000-2010'")
+	f.itemPlayed.SetLabel(i18n.T("Played"))
+	f.itemNotPlayed.SetLabel(i18n.T("Not played"))
+	f.itemFavorite.SetLabel(i18n.T("Favorite"))
+	f.yearRange.SetLabel(i18n.T("Year"))
+	f.yearRange.SetPlaceholder("'2020', '1990s' or '2000-2010'")
 	f.yearRange.SetPlaceholderTextColor(config.Color.TextDisabled)
 	f.yearRange.SetFieldTextColor(config.Color.Text)
 
+	// year field overrides decade, if both are set
+	f.decade.SetLabel(i18n.T("Decade"))
+	f.decade.SetFieldTextColor(config.Color.Text)
+	f.decade.AddOption(i18n.T(decadeAny), nil)
+	for decade := time.Now().Year() / 10 * 10; decade >= oldestDecade; decade -= 10 {
+		f.decade.AddOption(fmt.Sprintf("%ds", decade), nil)
+	}
+	f.decade.SetCurrentOption(0)
+
+	f.composer.SetLabel(i18n.T("Composer"))
+	f.composer.SetFieldTextColor(config.Color.Text)
+	f.composer.AddOption(i18n.T(composerAny), nil)
+	f.composer.SetCurrentOption(0)
+
 	f.AddFormItem(f.itemFavorite)
 	f.AddFormItem(f.yearRange)
+	f.AddFormItem(f.decade)
 
-	f.AddButton("Filter", f.ok)
-	f.AddButton("Clear", func() {
+	f.AddButton(i18n.T("Filter"), f.ok)
+	f.AddButton(i18n.T("Clear"), func() {
 		f.Clear()
 		f.ok()
 	})
-	f.AddButton("Cancel", f.cancel)
+	f.AddButton(i18n.T("Cancel"), f.cancel)
 
 	f.GetButton(0).SetInputCapture(f.inputCapture)
 	f.GetButton(1).SetInputCapture(f.inputCapture)
@@ -190,11 +374,13 @@ func excludeOther(c1, c2 *cview.Checkbox) func(key tcell.Key) {
 
 var validYearRangeRe = regexp.MustCompile("^([0-9]{1,4})$")
 
+var decadeRe = regexp.MustCompile("^([0-9]{3}0)s$")
+
 func validateYearRange(textToCheck string, lastChar rune) bool {
 	splitchar := "-"
 
 	if !strings.Contains(textToCheck, splitchar) {
-		return validYearRangeRe.MatchString(textToCheck)
+		return validYearRangeRe.MatchString(textToCheck) || decadeRe.MatchString(textToCheck)
 
 	}
 
@@ -227,31 +413,25 @@ func (f *filter) ok() {
 	}
 
 	yearRange := f.yearRange.GetText()
+	if yearRange == "" {
+		_, yearRange = f.decade.GetCurrentOption()
+		if yearRange == i18n.T(decadeAny) {
+			yearRange = ""
+		}
+	}
 	if yearRange != "" {
-		splits := strings.Split(yearRange, "-")
-		if len(splits) == 0 || len(splits) == 1 {
-			if len(splits) == 1 {
-				yearRange = splits[0]
-			}
-			firstYear, err := strconv.Atoi(yearRange)
-			if err == nil {
-				filt.YearRange[0] = firstYear
-				filt.YearRange[1] = firstYear
-			} else {
-				logrus.Debugf("invalid year filter '%s': %v", yearRange, err)
-			}
-		} else if len(splits) == 2 {
-			firstYear, err := strconv.Atoi(splits[0])
-			secondYear, err := strconv.Atoi(splits[1])
-			if err == nil {
-				filt.YearRange[0] = firstYear
-				filt.YearRange[1] = secondYear
-			} else {
-				logrus.Debugf("invalid year filter '%s': %v", yearRange, err)
-			}
+		years, err := parseYearRange(yearRange)
+		if err == nil {
+			filt.YearRange = years
+		} else {
+			logrus.Debugf("invalid year filter '%s': %v", yearRange, err)
 		}
 	}
 
+	if index, _ := f.composer.GetCurrentOption(); index > 0 && index <= len(f.composers) {
+		filt.Composers = []models.IdName{*f.composers[index-1]}
+	}
+
 	if f.itemPlayed.IsChecked() {
 		filt.FilterPlayed = interfaces.FilterIsPlayed
 	} else if f.itemNotPlayed.IsChecked() {
@@ -266,6 +446,38 @@ func (f *filter) ok() {
 	}
 }
 
+// parseYearRange parses single year '2020', decade '1990s' or range '2000-2010'.
+func parseYearRange(text string) ([2]int, error) {
+	years := [2]int{}
+	if match := decadeRe.FindStringSubmatch(text); match != nil {
+		decade, err := strconv.Atoi(match[1])
+		if err != nil {
+			return years, err
+		}
+		years[0] = decade
+		years[1] = decade + 9
+		return years, nil
+	}
+
+	splits := strings.Split(text, "-")
+	if len(splits) > 2 {
+		return years, fmt.Errorf("too many years")
+	}
+	firstYear, err := strconv.Atoi(splits[0])
+	if err != nil {
+		return years, err
+	}
+	years[0] = firstYear
+	years[1] = firstYear
+	if len(splits) == 2 && splits[1] != "" {
+		years[1], err = strconv.Atoi(splits[1])
+		if err != nil {
+			return years, err
+		}
+	}
+	return years, nil
+}
+
 func (f *filter) cancel() {
 	if f.closeCb != nil {
 		f.closeCb()
@@ -277,6 +489,8 @@ func (f *filter) Clear() {
 	f.itemNotPlayed.SetChecked(false)
 	f.itemFavorite.SetChecked(false)
 	f.yearRange.SetText("")
+	f.decade.SetCurrentOption(0)
+	f.composer.SetCurrentOption(0)
 	if f.filterChangedFunc != nil {
 		f.filterChangedFunc(false)
 	}
@@ -0,0 +1,71 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import "testing"
+
+func Test_parseYearRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    [2]int
+		wantErr bool
+	}{
+		{name: "single year", text: "2020", want: [2]int{2020, 2020}},
+		{name: "range", text: "2000-2010", want: [2]int{2000, 2010}},
+		{name: "open range", text: "2000-", want: [2]int{2000, 2000}},
+		{name: "decade", text: "1990s", want: [2]int{1990, 1999}},
+		{name: "invalid", text: "199x", wantErr: true},
+		{name: "too many years", text: "2000-2001-2002", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYearRange(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseYearRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseYearRange() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_validateYearRange(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{text: "2", want: true},
+		{text: "2020", want: true},
+		{text: "1990s", want: true},
+		{text: "2000-201", want: true},
+		{text: "20201", want: false},
+		{text: "1995s", want: false},
+		{text: "a", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := validateYearRange(tt.text, 0); got != tt.want {
+				t.Errorf("validateYearRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strconv"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+	"unicode"
+)
+
+// gain provides a modal for setting volume offset of song or album.
+type gain struct {
+	*cview.Form
+	controller interfaces.GainController
+	// savedFunc is called after offset has been saved.
+	savedFunc func(item models.Item, offset int)
+	errorFunc func(action string, err error)
+
+	visible bool
+	closeCb func()
+
+	offset *cview.InputField
+	item   models.Item
+}
+
+func newGain(controller interfaces.GainController, savedFunc func(item models.Item, offset int),
+	errorFunc func(action string, err error)) *gain {
+	g := &gain{
+		Form:       cview.NewForm(),
+		controller: controller,
+		savedFunc:  savedFunc,
+		errorFunc:  errorFunc,
+		offset:     cview.NewInputField(),
+	}
+
+	g.SetTitle(" Volume offset ")
+	g.SetBackgroundColor(config.Color.Modal.Background)
+	g.SetBorder(true)
+
+	g.offset.SetLabel(i18n.T("Offset (dB)"))
+	g.offset.SetFieldWidth(6)
+	g.offset.SetAcceptanceFunc(acceptSignedDigits)
+	g.offset.SetFieldTextColor(config.Color.Text)
+	g.AddFormItem(g.offset)
+	g.AddButton(i18n.T("Save"), g.ok)
+	g.AddButton(i18n.T("Reset"), g.reset)
+	g.AddButton(i18n.T("Cancel"), g.cancel)
+
+	for i := 0; i < g.GetButtonCount(); i++ {
+		g.GetButton(i).SetInputCapture(g.inputCapture)
+	}
+	g.offset.SetInputCapture(g.inputCapture)
+	g.SetCancelFunc(g.cancel)
+	return g
+}
+
+func (g *gain) SetDoneFunc(doneFunc func()) {
+	g.closeCb = doneFunc
+}
+
+func (g *gain) View() cview.Primitive {
+	return g
+}
+
+func (g *gain) SetVisible(visible bool) {
+	g.visible = visible
+}
+
+// SetItem sets song or album to adjust and shows its current offset.
+func (g *gain) SetItem(item models.Item) {
+	g.item = item
+	g.SetTitle(fmt.Sprintf(" %s: %s ", i18n.T("Volume offset"), item.GetName()))
+	g.offset.SetText(strconv.Itoa(g.controller.GetGainOffset(item)))
+}
+
+func (g *gain) ok() {
+	offset, err := parseSetting("volume offset", g.offset.GetText(),
+		-config.AudioMaxGainOffsetdB, config.AudioMaxGainOffsetdB)
+	if err != nil {
+		g.errorFunc("set volume offset", err)
+		return
+	}
+	g.save(offset)
+}
+
+func (g *gain) reset() {
+	g.save(0)
+}
+
+func (g *gain) save(offset int) {
+	err := g.controller.SetGainOffset(g.item, offset)
+	if err != nil {
+		g.errorFunc("set volume offset", err)
+		return
+	}
+	g.cancel()
+	g.savedFunc(g.item, offset)
+}
+
+func (g *gain) cancel() {
+	if g.closeCb != nil {
+		g.closeCb()
+	}
+}
+
+func (g *gain) inputCapture(e *tcell.EventKey) *tcell.EventKey {
+	switch e.Key() {
+	case tcell.KeyUp:
+		return tcell.NewEventKey(tcell.KeyBacktab, e.Rune(), e.Modifiers())
+	case tcell.KeyDown:
+		return tcell.NewEventKey(tcell.KeyTab, e.Rune(), e.Modifiers())
+	}
+	return e
+}
+
+// acceptSignedDigits accepts digits with optional leading minus sign.
+func acceptSignedDigits(textToCheck string, lastChar rune) bool {
+	if len(textToCheck) > 3 {
+		return false
+	}
+	if lastChar == '-' {
+		return len(textToCheck) == 1
+	}
+	return unicode.IsDigit(lastChar)
+}
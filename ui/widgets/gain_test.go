@@ -0,0 +1,43 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import "testing"
+
+func Test_acceptSignedDigits(t *testing.T) {
+	tests := []struct {
+		text     string
+		lastChar rune
+		want     bool
+	}{
+		{text: "5", lastChar: '5', want: true},
+		{text: "-", lastChar: '-', want: true},
+		{text: "-12", lastChar: '2', want: true},
+		{text: "5-", lastChar: '-', want: false},
+		{text: "a", lastChar: 'a', want: false},
+		{text: "-1234", lastChar: '4', want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := acceptSignedDigits(tt.text, tt.lastChar); got != tt.want {
+				t.Errorf("acceptSignedDigits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"strings"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/twidgets"
+)
+
+// genreSections are item types that can be browsed in genre view
+var genreSections = []struct {
+	label    string
+	itemType models.ItemType
+}{
+	{label: i18n.N("Albums"), itemType: models.TypeAlbum},
+	{label: i18n.N("Artists"), itemType: models.TypeArtist},
+	{label: i18n.N("Songs"), itemType: models.TypeSong},
+}
+
+// GenreView shows albums, artists and songs sections for single genre.
+type GenreView struct {
+	*itemList
+	genre    models.IdName
+	sections []*genreSection
+	playBtn  *button
+
+	selectFunc func(genre models.IdName, itemType models.ItemType)
+	playFunc   func(genre models.IdName)
+}
+
+// NewGenreView constructs new genre view. SelectFunc is called when section is selected and
+// playFunc when user wants to play instant mix of genre.
+func NewGenreView(selectFunc func(genre models.IdName, itemType models.ItemType),
+	playFunc func(genre models.IdName)) *GenreView {
+	g := &GenreView{
+		playBtn:    newButton(i18n.T("Play genre")),
+		selectFunc: selectFunc,
+		playFunc:   playFunc,
+	}
+	g.itemList = newItemList(g.selectSection)
+	g.list.ItemHeight = 2
+	g.list.Padding = 1
+	g.list.Grid.SetColumns(1, -1)
+
+	g.playBtn.SetSelectedFunc(g.playGenre)
+
+	g.Banner.Grid.SetRows(1, 1, 1, 1, -1, 3)
+	g.Banner.Grid.SetColumns(6, 2, 10, -1, 10, -1, 10, -3)
+	g.Banner.Grid.SetMinSize(1, 6)
+
+	g.Banner.Grid.AddItem(g.prevBtn, 0, 0, 1, 1, 1, 5, false)
+	g.Banner.Grid.AddItem(g.description, 0, 2, 2, 6, 1, 10, false)
+	g.Banner.Grid.AddItem(g.playBtn, 3, 2, 1, 1, 1, 10, false)
+	g.Banner.Grid.AddItem(g.list, 4, 0, 2, 8, 4, 10, false)
+
+	g.Banner.Selectable = []twidgets.Selectable{g.prevBtn, g.playBtn, g.list}
+
+	items := make([]twidgets.ListItem, len(genreSections))
+	itemTexts := make([]string, len(genreSections))
+	g.sections = make([]*genreSection, len(genreSections))
+	for i, v := range genreSections {
+		section := newGenreSection(i18n.T(v.label), v.itemType)
+		g.sections[i] = section
+		items[i] = section
+		itemTexts[i] = strings.ToLower(i18n.T(v.label))
+	}
+	g.list.AddItems(items...)
+	g.items = items
+	g.itemsTexts = itemTexts
+	g.searchItemsSet()
+	return g
+}
+
+// SetGenre sets genre to show.
+func (g *GenreView) SetGenre(genre models.IdName) {
+	g.genre = genre
+	g.description.SetText(i18n.Tf("Genre: %s", genre.Name))
+	g.resetReduce()
+}
+
+func (g *GenreView) selectSection(index int) {
+	if g.selectFunc != nil && index < len(g.sections) {
+		g.selectFunc(g.genre, g.sections[index].itemType)
+	}
+}
+
+func (g *GenreView) playGenre() {
+	if g.playFunc != nil {
+		g.playFunc(g.genre)
+	}
+}
+
+type genreSection struct {
+	*highlightText
+	itemType models.ItemType
+}
+
+func newGenreSection(label string, itemType models.ItemType) *genreSection {
+	g := &genreSection{
+		highlightText: newHighlightText(),
+		itemType:      itemType,
+	}
+	g.SetBackgroundColor(config.Color.Background)
+	g.SetTextColor(config.Color.Text)
+	g.SetText(label)
+	return g
+}
+
+func (g *genreSection) SetSelected(s twidgets.Selection) {
+	if s == twidgets.Selected {
+		g.SetTextColor(config.Color.TextSelected)
+		g.SetBackgroundColor(config.Color.BackgroundSelected)
+	} else if s == twidgets.Deselected {
+		g.SetTextColor(config.Color.Text)
+		g.SetBackgroundColor(config.Color.Background)
+	} else if s == twidgets.Blurred {
+		g.SetBackgroundColor(config.Color.TextDisabled)
+	}
+}
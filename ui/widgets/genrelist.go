@@ -20,9 +20,9 @@ package widgets
 
 import (
 	"fmt"
-	"gitlab.com/tslocum/cview"
 	"strings"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/twidgets"
@@ -33,14 +33,20 @@ type GenreList struct {
 	paging         *PageSelector
 	selectFunc     func(genre models.IdName)
 	selectPageFunc func(page interfaces.Paging)
+	playFunc       func(genre models.IdName)
+	context        contextOperator
 	genres         []*Genre
 
 	pagingEnabled bool
 	page          interfaces.Paging
 }
 
-func NewGenreList() *GenreList {
+// NewGenreList constructs new genre list. If playFunc is set, genres can be played as genre radio.
+// If operator is set, genres can be opened in browser.
+func NewGenreList(playFunc func(genre models.IdName), operator contextOperator) *GenreList {
 	g := &GenreList{
+		playFunc: playFunc,
+		context:  operator,
 
 		pagingEnabled: false,
 		page:          interfaces.Paging{},
@@ -53,7 +59,8 @@ func NewGenreList() *GenreList {
 	g.list.ItemHeight = 2
 
 	g.pagingEnabled = true
-	selectables := []twidgets.Selectable{g.prevBtn, g.paging.Previous, g.paging.Next, g.list}
+	selectables := append([]twidgets.Selectable{g.prevBtn}, g.paging.Selectables()...)
+	selectables = append(selectables, g.list)
 	g.Banner.Selectable = selectables
 	g.description.SetBackgroundColor(config.Color.Background)
 	g.description.SetTextColor(config.Color.Text)
@@ -69,9 +76,43 @@ func NewGenreList() *GenreList {
 	g.Banner.Grid.AddItem(g.description, 0, 2, 2, 6, 1, 10, false)
 	g.Banner.Grid.AddItem(g.paging, 3, 4, 1, 3, 1, 10, true)
 	g.Banner.Grid.AddItem(g.list, 4, 0, 2, 8, 4, 10, false)
+
+	if g.playFunc != nil {
+		g.list.AddContextItem(i18n.T("Genre radio"), 0, func(index int) {
+			index = g.getSelectedIndex()
+			if index >= 0 && index < len(g.genres) {
+				g.playFunc(*g.genres[index].genre)
+			}
+		})
+	}
+	if g.context != nil {
+		g.list.AddContextItem(i18n.T("Open in browser"), 0, func(index int) {
+			if genre := g.selectedGenre(); genre != nil {
+				g.context.OpenInBrowser(*genre)
+			}
+		})
+		g.list.AddContextItem(i18n.T("Copy link"), 0, func(index int) {
+			if genre := g.selectedGenre(); genre != nil {
+				g.context.CopyLink(*genre)
+			}
+		})
+	}
+	if g.playFunc != nil || g.context != nil {
+		g.itemList.initContextMenuList()
+	}
 	return g
 }
 
+// selectedGenre returns highlighted genre or nil.
+func (g *GenreList) selectedGenre() *models.Genre {
+	index := g.getSelectedIndex()
+	if index < 0 || index >= len(g.genres) {
+		return nil
+	}
+	genre := models.Genre(*g.genres[index].genre)
+	return &genre
+}
+
 func (g *GenreList) Clear() {
 	g.list.Clear()
 	g.genres = make([]*Genre, 0)
@@ -141,14 +182,14 @@ func (g *GenreList) showReduceInput(visible bool) {
 }
 
 type Genre struct {
-	*cview.TextView
+	*highlightText
 	genre *models.IdName
 }
 
 func newGenre(genre *models.IdName) *Genre {
 	g := &Genre{
-		TextView: cview.NewTextView(),
-		genre:    genre,
+		highlightText: newHighlightText(),
+		genre:         genre,
 	}
 	g.SetBackgroundColor(config.Color.Background)
 	g.SetTextColor(config.Color.Text)
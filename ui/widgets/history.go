@@ -20,6 +20,7 @@ package widgets
 
 import (
 	"fmt"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/util"
 	"tryffel.net/go/twidgets"
@@ -36,7 +37,7 @@ func NewHistory() *History {
 }
 
 func (h *History) printDescription() {
-	text := "History"
+	text := i18n.T("History")
 	if len(h.songs) > 0 {
 		duration := 0
 		for _, v := range h.songs {
@@ -51,6 +52,8 @@ func (h *History) printDescription() {
 func (h *History) Clear() {
 	h.list.Clear()
 	h.songs = []*albumSong{}
+	h.items = nil
+	h.itemsTexts = nil
 	h.printDescription()
 }
 
@@ -66,5 +69,6 @@ func (h *History) SetSongs(songs []*models.Song) {
 		items[i] = s
 	}
 	h.list.AddItems(items...)
+	h.setItems(items)
 	h.printDescription()
 }
@@ -0,0 +1,129 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package widgets
+
+import (
+	"errors"
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strings"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// playlistImport provides a modal for creating playlist from m3u file.
+type playlistImport struct {
+	*cview.Form
+	importer interfaces.PlaylistImporter
+	// startFunc is called when import starts and importedFunc after import is complete.
+	// Import runs in background and importedFunc is called from another goroutine.
+	startFunc    func(file string)
+	importedFunc func(result *models.PlaylistImport, err error)
+	errorFunc    func(action string, err error)
+
+	visible bool
+	closeCb func()
+
+	file *cview.InputField
+	name *cview.InputField
+}
+
+func newPlaylistImport(importer interfaces.PlaylistImporter, startFunc func(file string),
+	importedFunc func(result *models.PlaylistImport, err error),
+	errorFunc func(action string, err error)) *playlistImport {
+	p := &playlistImport{
+		Form:         cview.NewForm(),
+		importer:     importer,
+		startFunc:    startFunc,
+		importedFunc: importedFunc,
+		errorFunc:    errorFunc,
+		file:         cview.NewInputField(),
+		name:         cview.NewInputField(),
+	}
+
+	p.SetTitle(" Import playlist ")
+	p.SetBackgroundColor(config.Color.Modal.Background)
+	p.SetBorder(true)
+
+	p.file.SetLabel(i18n.T("File"))
+	p.file.SetPlaceholder("~/playlist.m3u8")
+	p.name.SetLabel(i18n.T("Name"))
+	p.name.SetPlaceholder("name in file or file name")
+	for _, v := range []*cview.InputField{p.file, p.name} {
+		v.SetFieldWidth(40)
+		v.SetFieldTextColor(config.Color.Text)
+		v.SetInputCapture(p.inputCapture)
+		p.AddFormItem(v)
+	}
+	p.AddButton(i18n.T("Import"), p.ok)
+	p.AddButton(i18n.T("Cancel"), p.cancel)
+
+	for i := 0; i < p.GetButtonCount(); i++ {
+		p.GetButton(i).SetInputCapture(p.inputCapture)
+	}
+	p.SetCancelFunc(p.cancel)
+	return p
+}
+
+func (p *playlistImport) SetDoneFunc(doneFunc func()) {
+	p.closeCb = doneFunc
+}
+
+func (p *playlistImport) View() cview.Primitive {
+	return p
+}
+
+func (p *playlistImport) SetVisible(visible bool) {
+	p.visible = visible
+}
+
+func (p *playlistImport) ok() {
+	file, err := expandHome(strings.TrimSpace(p.file.GetText()))
+	if err == nil && file == "" {
+		err = errors.New("file name is empty")
+	}
+	if err != nil {
+		p.errorFunc("import playlist", err)
+		return
+	}
+	name := strings.TrimSpace(p.name.GetText())
+	p.cancel()
+	p.startFunc(file)
+	go func() {
+		result, err := p.importer.ImportPlaylist(file, name)
+		p.importedFunc(result, err)
+	}()
+}
+
+func (p *playlistImport) cancel() {
+	if p.closeCb != nil {
+		p.closeCb()
+	}
+}
+
+func (p *playlistImport) inputCapture(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyUp:
+		return tcell.NewEventKey(tcell.KeyBacktab, event.Rune(), event.Modifiers())
+	case tcell.KeyDown:
+		return tcell.NewEventKey(tcell.KeyTab, event.Rune(), event.Modifiers())
+	}
+	return event
+}
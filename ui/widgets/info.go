@@ -0,0 +1,90 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"fmt"
+	"strings"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/jellycli/util"
+)
+
+// itemHighlighter is a view that can return currently highlighted song or album.
+type itemHighlighter interface {
+	// highlightedItem returns highlighted item or nil, if there is none.
+	highlightedItem() models.Item
+}
+
+// formatItemInfo formats item details, one value per line. Unknown values are omitted.
+func formatItemInfo(info *models.ItemInfo) string {
+	lines := []string{
+		i18n.T("Type") + ": " + string(info.Type),
+		i18n.T("Name") + ": " + info.Name,
+		i18n.T("Id") + ": " + info.Id.String(),
+	}
+	add := func(name, value string) {
+		if value != "" {
+			lines = append(lines, name+": "+value)
+		}
+	}
+
+	if info.Album.Id != "" {
+		add(i18n.T("Album"), fmt.Sprintf("%s (%s)", info.Album.Name, info.Album.Id))
+	}
+	add(i18n.T("Artists"), formatIdNames(info.Artists))
+	add(i18n.T("Genres"), formatIdNames(info.Genres))
+	add(i18n.T("Path"), info.Path)
+	add(i18n.T("Codec"), strings.ToUpper(info.Codec))
+	if info.Bitrate > 0 {
+		add(i18n.T("Bitrate"), fmt.Sprintf("%d kbps", info.Bitrate))
+	}
+	if info.SampleRate > 0 {
+		add(i18n.T("Sample rate"), fmt.Sprintf("%d Hz", info.SampleRate))
+	}
+	if info.Size > 0 {
+		add(i18n.T("Size"), util.BytesToString(info.Size))
+	}
+	add(i18n.T("Play count"), fmt.Sprint(info.PlayCount))
+	if !info.DateAdded.IsZero() {
+		add(i18n.T("Date added"), info.DateAdded.Local().Format("2006-01-02 15:04"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// format names with ids: 'name (id), name2 (id2)'
+func formatIdNames(items []models.IdName) string {
+	names := make([]string, 0, len(items))
+	for _, v := range items {
+		if v.Id == "" {
+			names = append(names, v.Name)
+		} else {
+			names = append(names, fmt.Sprintf("%s (%s)", v.Name, v.Id))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// songAtIndex returns song at index or nil, if index is out of bounds.
+func songAtIndex(songs []*albumSong, index int) models.Item {
+	if index < 0 || index >= len(songs) {
+		return nil
+	}
+	return songs[index].song
+}
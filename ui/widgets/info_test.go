@@ -0,0 +1,67 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_formatItemInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		info *models.ItemInfo
+		want string
+	}{
+		{
+			name: "minimal info",
+			info: &models.ItemInfo{Id: "a1", Type: models.TypeAlbum, Name: "Album"},
+			want: "Type: Album\nName: Album\nId: a1\nPlay count: 0",
+		},
+		{
+			name: "song",
+			info: &models.ItemInfo{
+				Id:         "s1",
+				Type:       models.TypeSong,
+				Name:       "Song",
+				Album:      models.IdName{Id: "a1", Name: "Album"},
+				Artists:    []models.IdName{{Id: "b1", Name: "Artist"}},
+				Genres:     []models.IdName{{Id: "Rock", Name: "Rock"}},
+				Path:       "/music/song.flac",
+				Codec:      "flac",
+				Bitrate:    900,
+				SampleRate: 44100,
+				Size:       2 * 1024 * 1024,
+				PlayCount:  3,
+				DateAdded:  time.Date(2020, 5, 4, 12, 30, 0, 0, time.Local),
+			},
+			want: "Type: Song\nName: Song\nId: s1\nAlbum: Album (a1)\nArtists: Artist (b1)\nGenres: Rock (Rock)\n" +
+				"Path: /music/song.flac\nCodec: FLAC\nBitrate: 900 kbps\nSample rate: 44100 Hz\nSize: 2.0 MB\n" +
+				"Play count: 3\nDate added: 2020-05-04 12:30",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatItemInfo(tt.info); got != tt.want {
+				t.Errorf("formatItemInfo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
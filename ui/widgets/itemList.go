@@ -24,16 +24,23 @@ import (
 	"gitlab.com/tslocum/cview"
 	"strings"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/twidgets"
 )
 
 // characters to strip from search texts and search input
 const stripCharacters = "-+_.:,;&#%!'"
 
+// style tags to highlight matches in filtered list
+const (
+	highlightStart = "[::bu]"
+	highlightEnd   = "[::-]"
+)
+
 // itemList shows Banner (title, buttons) and list below header.
 // It also features filtering list items.
 //
-// To enable filtering, set itemList.reduceEnabled = true.
+// To enable filtering, set itemList.reduceEnabled = true. Filter is opened with space or '/'.
 // Reduce filters texts in itemList.itemsText to find correct items.
 // Reduce also calls setReducerVisibile when reduce input should be shown/hidden
 // and it is users responsibility to add/remove it from grid. After this, itemList hands
@@ -42,6 +49,7 @@ const stripCharacters = "-+_.:,;&#%!'"
 // with items original index, not reduced index.
 // If there are external changes to item list (such as paging, refresh etc),
 // call itemList.resetReduce to reset reducer state.
+// List items that implement matchHighlighter get matching tokens highlighted.
 type itemList struct {
 	*twidgets.Banner
 	*previous
@@ -63,6 +71,8 @@ type itemList struct {
 	reduceIndices     []int
 
 	listSelectFunc func(index int)
+	// loadMoreFunc is called when scrolling down from last item, if infinite scroll is enabled.
+	loadMoreFunc func()
 }
 
 func newItemList(listSelectfunc func(index int)) *itemList {
@@ -71,7 +81,7 @@ func newItemList(listSelectfunc func(index int)) *itemList {
 		previous: &previous{},
 
 		description: cview.NewTextView(),
-		prevBtn:     newButton("Back"),
+		prevBtn:     newButton(i18n.T("Back")),
 		prevFunc:    nil,
 	}
 
@@ -85,7 +95,7 @@ func newItemList(listSelectfunc func(index int)) *itemList {
 	rInput.SetBorderColor(config.Color.Border)
 	rInput.SetChangedFunc(itemList.reduce)
 	// leave space for printing num of results
-	rInput.SetLabel("Filter ")
+	rInput.SetLabel(i18n.T("Filter "))
 	rInput.SetLabelWidth(13)
 	rInput.SetFieldBackgroundColor(config.Color.BackgroundSelected)
 	rInput.SetFieldTextColor(config.Color.TextSelected)
@@ -121,7 +131,15 @@ func (i *itemList) initContextMenuList() {
 func (i *itemList) InputHandler() func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
 	return func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
 		r := event.Rune()
-		if r == ' ' {
+		if i.loadMoreFunc != nil && config.AppConfig.Gui.InfiniteScroll && !i.reduceVisible {
+			key := event.Key()
+			if key == tcell.KeyDown || key == tcell.KeyPgDn || key == tcell.KeyEnd || r == 'j' {
+				if len(i.items) > 0 && i.list.GetSelectedIndex() == len(i.items)-1 {
+					i.loadMoreFunc()
+				}
+			}
+		}
+		if r == ' ' || r == '/' {
 			if i.reduceEnabled && config.AppConfig.Gui.EnableResultsFiltering {
 				if i.setReducerVisible != nil {
 					i.setReducerVisible(true)
@@ -195,8 +213,9 @@ func (i *itemList) reduce(input string) {
 	}
 	i.list.Clear()
 	i.list.AddItems(items...)
+	i.highlightItems(tokens)
 	i.reduceIndices = indices
-	i.reduceInput.SetLabel(fmt.Sprintf("Filter (%d)", len(items)))
+	i.reduceInput.SetLabel(i18n.Tf("Filter (%d)", len(items)))
 }
 
 func (i *itemList) searchItemsSet() {
@@ -223,9 +242,10 @@ func (i *itemList) resetReduce() {
 			i.items[selected].SetSelected(twidgets.Deselected)
 		}
 		i.reduceInput.SetText("")
-		i.reduceInput.SetLabel("Filter")
+		i.reduceInput.SetLabel(i18n.T("Filter"))
 		i.list.Clear()
 		i.list.AddItems(i.items...)
+		i.highlightItems(nil)
 		i.setReducerVisible(false)
 		i.reduceVisible = false
 	}
@@ -239,12 +259,111 @@ func (i *itemList) selectitem(index int) {
 	i.listSelectFunc(index)
 }
 
+func (i *itemList) scrollList() *twidgets.ScrollList {
+	return i.list
+}
+
 func (i *itemList) getSelectedIndex() int {
-	var index int
-	if i.reduceVisible {
+	index := i.list.GetSelectedIndex()
+	if i.reduceVisible && index < len(i.reduceIndices) {
 		index = i.reduceIndices[index]
-	} else {
-		index = i.list.GetSelectedIndex()
 	}
 	return index
 }
+
+// selectIndex resets filter and selects item at original index.
+func (i *itemList) selectIndex(index int) {
+	i.resetReduce()
+	if index >= 0 && index < len(i.items) {
+		i.list.SetSelected(index)
+	}
+}
+
+// highlight tokens in all items that support it. Empty tokens removes highlighting.
+func (i *itemList) highlightItems(tokens []string) {
+	for _, v := range i.items {
+		if h, ok := v.(matchHighlighter); ok {
+			h.setHighlight(tokens)
+		}
+	}
+}
+
+// matchHighlighter is a list item that can highlight filter matches.
+type matchHighlighter interface {
+	setHighlight(tokens []string)
+}
+
+// highlightText is a text view that highlights given tokens in its text.
+// Text is escaped, so any style tags in text are printed as they are.
+type highlightText struct {
+	*cview.TextView
+	text   string
+	tokens []string
+	// artColor is color of art placeholder drawn before each line. Empty color draws no placeholder.
+	artColor string
+}
+
+func newHighlightText() *highlightText {
+	h := &highlightText{
+		TextView: cview.NewTextView(),
+	}
+	h.SetDynamicColors(true)
+	return h
+}
+
+// SetText sets text and highlights current tokens in it.
+func (h *highlightText) SetText(text string) *cview.TextView {
+	h.text = text
+	return h.TextView.SetText(artPlaceholder(highlightMatches(text, h.tokens), h.artColor))
+}
+
+func (h *highlightText) setHighlight(tokens []string) {
+	h.tokens = tokens
+	h.TextView.SetText(artPlaceholder(highlightMatches(h.text, tokens), h.artColor))
+}
+
+// setArtColor sets color of art placeholder, e.g. '#rrggbb'.
+func (h *highlightText) setArtColor(color string) {
+	h.artColor = color
+	h.TextView.SetText(artPlaceholder(highlightMatches(h.text, h.tokens), color))
+}
+
+// highlightMatches escapes text and surrounds all case-insensitive matches of tokens with highlight tags.
+func highlightMatches(text string, tokens []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(tokens) == 0 || len(runes) != len(lower) {
+		return cview.Escape(text)
+	}
+
+	matches := make([]bool, len(runes))
+	for _, token := range tokens {
+		t := []rune(token)
+		if len(t) == 0 {
+			continue
+		}
+		for start := 0; start+len(t) <= len(lower); start++ {
+			if string(lower[start:start+len(t)]) == token {
+				for j := start; j < start+len(t); j++ {
+					matches[j] = true
+				}
+			}
+		}
+	}
+
+	out := ""
+	start := 0
+	for j := 1; j <= len(runes); j++ {
+		if j < len(runes) && matches[j] == matches[start] {
+			continue
+		}
+		part := cview.Escape(string(runes[start:j]))
+		if matches[start] {
+			out += highlightStart + part + highlightEnd
+		} else {
+			out += part
+		}
+		start = j
+	}
+	return out
+}
@@ -218,3 +218,44 @@ func Benchmark_itemList_reduce10000largeItems(b *testing.B) {
 		list.reduce(input)
 	}
 }
+
+func Test_highlightMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		tokens []string
+		want   string
+	}{
+		{
+			name:   "no tokens",
+			text:   "A test song",
+			tokens: nil,
+			want:   "A test song",
+		},
+		{
+			name:   "case insensitive",
+			text:   "A Test song",
+			tokens: []string{"test"},
+			want:   "A " + highlightStart + "Test" + highlightEnd + " song",
+		},
+		{
+			name:   "multiple tokens",
+			text:   "A test song",
+			tokens: []string{"a", "song"},
+			want:   highlightStart + "A" + highlightEnd + " test " + highlightStart + "song" + highlightEnd,
+		},
+		{
+			name:   "escape tags",
+			text:   "Song [Live]",
+			tokens: []string{"song"},
+			want:   highlightStart + "Song" + highlightEnd + " [Live[]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightMatches(tt.text, tt.tokens); got != tt.want {
+				t.Errorf("highlightMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
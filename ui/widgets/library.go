@@ -0,0 +1,143 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// libraryAll is a library option that uses all music libraries
+var libraryAll = i18n.N("All libraries")
+
+// library provides a modal for selecting active music library.
+type library struct {
+	*cview.Form
+	controller interfaces.LibraryController
+	// selectFunc is called after library has been changed.
+	selectFunc func(name string)
+	errorFunc  func(action string, err error)
+
+	visible bool
+	closeCb func()
+
+	library   *cview.DropDown
+	libraries []*models.View
+}
+
+func newLibrary(controller interfaces.LibraryController, selectFunc func(name string),
+	errorFunc func(action string, err error)) *library {
+	l := &library{
+		Form:       cview.NewForm(),
+		controller: controller,
+		selectFunc: selectFunc,
+		errorFunc:  errorFunc,
+		library:    cview.NewDropDown(),
+	}
+
+	l.SetTitle(" Music library ")
+	l.SetBackgroundColor(config.Color.Modal.Background)
+	l.SetBorder(true)
+
+	l.library.SetLabel(i18n.T("Library"))
+	l.library.SetFieldTextColor(config.Color.Text)
+	l.AddFormItem(l.library)
+	l.AddButton(i18n.T("Select"), l.ok)
+	l.AddButton(i18n.T("Cancel"), l.cancel)
+
+	for i := 0; i < l.GetButtonCount(); i++ {
+		l.GetButton(i).SetInputCapture(l.inputCapture)
+	}
+	l.library.SetInputCapture(l.inputCapture)
+	l.SetCancelFunc(l.cancel)
+	return l
+}
+
+func (l *library) SetDoneFunc(doneFunc func()) {
+	l.closeCb = doneFunc
+}
+
+func (l *library) View() cview.Primitive {
+	return l
+}
+
+func (l *library) SetVisible(visible bool) {
+	l.visible = visible
+	if visible {
+		l.loadLibraries()
+	}
+}
+
+// loadLibraries refreshes libraries and selects the active one.
+func (l *library) loadLibraries() {
+	libraries, err := l.controller.GetLibraries()
+	if err != nil {
+		l.errorFunc("get music libraries", err)
+	}
+	l.libraries = libraries
+	l.library.SetOptions(nil, nil)
+	l.library.AddOption(i18n.T(libraryAll), nil)
+	current := l.controller.GetLibrary()
+	selected := 0
+	for i, v := range libraries {
+		l.library.AddOption(v.Name, nil)
+		if v.Id == current {
+			selected = i + 1
+		}
+	}
+	l.library.SetCurrentOption(selected)
+}
+
+func (l *library) ok() {
+	index, name := l.library.GetCurrentOption()
+	var id models.Id
+	if index > 0 && index <= len(l.libraries) {
+		id = l.libraries[index-1].Id
+	}
+	l.cancel()
+	if id == l.controller.GetLibrary() {
+		return
+	}
+	err := l.controller.SetLibrary(id)
+	if err != nil {
+		l.errorFunc("select music library", err)
+		return
+	}
+	l.selectFunc(name)
+}
+
+func (l *library) cancel() {
+	if l.closeCb != nil {
+		l.closeCb()
+	}
+}
+
+func (l *library) inputCapture(e *tcell.EventKey) *tcell.EventKey {
+	switch e.Key() {
+	case tcell.KeyUp:
+		return tcell.NewEventKey(tcell.KeyBacktab, e.Rune(), e.Modifiers())
+	case tcell.KeyDown:
+		return tcell.NewEventKey(tcell.KeyTab, e.Rune(), e.Modifiers())
+	}
+	return e
+}
@@ -23,12 +23,14 @@ import (
 	"github.com/gdamore/tcell"
 	"gitlab.com/tslocum/cview"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 )
 
 type MediaSelect int
 
 const (
-	MediaLatestMusic MediaSelect = iota
+	MediaRecentlyAdded MediaSelect = iota
+	MediaRecentlyReleased
 	MediaRecent
 	MediaArtists
 	MediaAlbumArtists
@@ -38,19 +40,57 @@ const (
 	MediaFavoriteArtists
 	MediaFavoriteAlbums
 	MediaGenres
+	MediaComposers
+	MediaPodcasts
+	MediaDownloads
+	MediaDuplicates
 )
 
 var mediaSelections = map[MediaSelect]string{
-	MediaLatestMusic:     "Latest Music",
-	MediaRecent:          "Recently played",
-	MediaArtists:         "Artists",
-	MediaAlbumArtists:    "Album Artists",
-	MediaAlbums:          "Albums",
-	MediaSongs:           "Songs",
-	MediaPlaylists:       "Playlists",
-	MediaFavoriteArtists: "Favorite Artists",
-	MediaFavoriteAlbums:  "Favorite Albums",
-	MediaGenres:          "Genres",
+	MediaRecentlyAdded:    i18n.N("Recently added"),
+	MediaRecentlyReleased: i18n.N("Recently released"),
+	MediaRecent:           i18n.N("Recently played"),
+	MediaArtists:          i18n.N("Artists"),
+	MediaAlbumArtists:     i18n.N("Album Artists"),
+	MediaAlbums:           i18n.N("Albums"),
+	MediaSongs:            i18n.N("Songs"),
+	MediaPlaylists:        i18n.N("Playlists"),
+	MediaFavoriteArtists:  i18n.N("Favorite Artists"),
+	MediaFavoriteAlbums:   i18n.N("Favorite Albums"),
+	MediaGenres:           i18n.N("Genres"),
+	MediaComposers:        i18n.N("Composers"),
+	MediaPodcasts:         i18n.N("Podcasts"),
+	MediaDownloads:        i18n.N("Downloads"),
+	MediaDuplicates:       i18n.N("Duplicates"),
+}
+
+// mediaSelectionKeys are persisted names of selections, used for startup and last views.
+var mediaSelectionKeys = map[MediaSelect]string{
+	MediaRecentlyAdded:    "recently_added",
+	MediaRecentlyReleased: "recently_released",
+	MediaRecent:           "recently_played",
+	MediaArtists:          config.StartupViewArtists,
+	MediaAlbumArtists:     "album_artists",
+	MediaAlbums:           config.StartupViewAlbums,
+	MediaSongs:            "songs",
+	MediaPlaylists:        config.StartupViewPlaylists,
+	MediaFavoriteArtists:  "favorite_artists",
+	MediaFavoriteAlbums:   "favorite_albums",
+	MediaGenres:           "genres",
+	MediaComposers:        "composers",
+	MediaPodcasts:         "podcasts",
+	MediaDownloads:        "downloads",
+	MediaDuplicates:       "duplicates",
+}
+
+// mediaSelectionFromKey returns selection for persisted key. If key is not found, ok is false.
+func mediaSelectionFromKey(key string) (selection MediaSelect, ok bool) {
+	for i, v := range mediaSelectionKeys {
+		if v == key {
+			return i, true
+		}
+	}
+	return 0, false
 }
 
 //MediaNavigation provides access to artists, albums, playlists
@@ -75,7 +115,7 @@ func NewMediaNavigation(selectFunc func(selection MediaSelect)) *MediaNavigation
 	m.SetSelectedStyle(config.Color.TextSelected, config.Color.BackgroundSelected, 0)
 
 	for i, v := range mediaSelections {
-		cell := tableCell(v)
+		cell := tableCell(i18n.T(v))
 		m.Table.SetCell(int(i), 0, cell)
 	}
 
@@ -142,6 +182,11 @@ func (m *MediaNavigation) MouseHandler() func(action cview.MouseAction, event *t
 	})
 }
 
+// Select highlights selection without calling selectFunc.
+func (m *MediaNavigation) Select(selection MediaSelect) {
+	m.Table.Select(int(selection), 0)
+}
+
 func (m *MediaNavigation) SetCount(id MediaSelect, count int) {
 	m.Table.SetCellSimple(int(id), 1, fmt.Sprint(count))
 }
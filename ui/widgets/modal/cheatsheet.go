@@ -0,0 +1,115 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package modal
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+)
+
+// CheatSheet lists key bindings and filters them while typing.
+type CheatSheet struct {
+	*cview.TextView
+	visible bool
+	closeCb func()
+
+	query string
+}
+
+func NewCheatSheet() *CheatSheet {
+	c := &CheatSheet{TextView: cview.NewTextView()}
+
+	colors := config.Color.Modal
+	c.SetBackgroundColor(colors.Background)
+	c.SetBorder(true)
+	c.SetTitle(i18n.T("Key bindings"))
+	c.SetBorderColor(config.Color.Border)
+	c.SetTitleColor(config.Color.TextSecondary)
+	c.SetTextColor(colors.Text)
+	c.SetDynamicColors(true)
+	c.SetBorderPadding(0, 1, 2, 2)
+	c.SetWrap(true)
+	c.SetWordWrap(true)
+	c.setContent()
+	return c
+}
+
+func (c *CheatSheet) SetDoneFunc(doneFunc func()) {
+	c.closeCb = doneFunc
+}
+
+func (c *CheatSheet) View() cview.Primitive {
+	return c
+}
+
+func (c *CheatSheet) SetVisible(visible bool) {
+	c.visible = visible
+	if visible {
+		c.query = ""
+		c.setContent()
+	}
+}
+
+func (c *CheatSheet) Focus(delegate func(p cview.Primitive)) {
+	c.TextView.SetBorderColor(config.Color.BorderFocus)
+	c.TextView.Focus(delegate)
+}
+
+func (c *CheatSheet) Blur() {
+	c.TextView.SetBorderColor(config.Color.Border)
+	c.TextView.Blur()
+}
+
+func (c *CheatSheet) GetFocusable() cview.Focusable {
+	return c.TextView.GetFocusable()
+}
+
+func (c *CheatSheet) InputHandler() func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+	return func(event *tcell.EventKey, setFocus func(p cview.Primitive)) {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			c.closeCb()
+		case tcell.KeyRune:
+			c.query += string(event.Rune())
+			c.setContent()
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			if c.query != "" {
+				runes := []rune(c.query)
+				c.query = string(runes[:len(runes)-1])
+				c.setContent()
+			}
+		default:
+			c.TextView.InputHandler()(event, setFocus)
+		}
+	}
+}
+
+func (c *CheatSheet) setContent() {
+	text := i18n.T("Search: ") + cview.Escape(c.query) + "[::r] [::-]\n\n"
+	list := filterShortcuts(shortcuts(), c.query)
+	if len(list) == 0 {
+		text += i18n.T("No key bindings found")
+	} else {
+		text += formatShortcuts(list)
+	}
+	c.SetText(text)
+	c.ScrollToBeginning()
+}
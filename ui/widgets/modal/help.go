@@ -24,6 +24,7 @@ import (
 	"gitlab.com/tslocum/cview"
 	"strings"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/util"
 )
@@ -93,7 +94,7 @@ func NewHelp(doneCb func()) *Help {
 	colors := config.Color.Modal
 	h.SetBackgroundColor(colors.Background)
 	h.SetBorder(true)
-	h.SetTitle("Help")
+	h.SetTitle(i18n.T("Help"))
 	h.SetBorderColor(config.Color.Border)
 	h.SetTitleColor(config.Color.TextSecondary)
 	h.SetDynamicColors(true)
@@ -112,13 +113,13 @@ func (h *Help) setContent() {
 	switch h.page {
 	case 0:
 		got = h.mainPage()
-		title = "About"
+		title = i18n.T("About")
 	case 1:
 		got = h.shortcutsPage()
-		title = "Usage"
+		title = i18n.T("Usage")
 	case 2:
 		got = h.statsPage()
-		title = "Info"
+		title = i18n.T("Info")
 	default:
 	}
 
@@ -141,47 +142,43 @@ func (h *Help) SetStats(stats models.Stats) {
 
 func (h *Help) mainPage() string {
 	text := fmt.Sprintf("%s\n[yellow]v%s[-]\n\n", logo(), config.Version)
-	text += "License: GPL-v3, https://www.gnu.org/licenses/gpl-3.0.en.html"
+	text += i18n.T("License: GPL-v3, https://www.gnu.org/licenses/gpl-3.0.en.html")
 
 	text += "\n" + helpText()
 	return text
 }
 
 func (h *Help) shortcutsPage() string {
-	return fmt.Sprintf(`
-[yellow]Navigation[-]:
-* Up/Down: Key up / down
-* VIM-like keys: 
-	* Up / Down: J / K, 
-	* Top / Bottom of list: g / G 
-	* Page Up / Down: Ctrl+F / Ctrl+B
-* Switch between panels: Tab 
-* Select button or item: Enter
-* Open context menu: Alt+Enter
-* Close application: Ctrl-C
+	return i18n.Tf(`
+Press %s for searchable list of key bindings.
+
+%s
+[yellow]Usage[-]:
 * Filter list items: 
-	activate list with Key Up / Key Down, then press Whitespace ' ' 
-    to activate filter. Start typing and see list items reducing. Press enter to activate list again
+	activate list with Key Up / Key Down, then press Whitespace ' ' or '/'
+    to activate filter. Start typing and see list items reducing, matches are highlighted. Press enter to activate list again
 	and press ESC to cancel filter and return to original list.
-
-[yellow]Queue[-]:
-* Delete song: Del
-* Move up song: Ctrl-K
-* Move down song: Ctrl-J
+* Tabs: Alt+1 - Alt+9 switches to tab, or opens a new tab after the last one. Each tab has its own view
+	and history. Tab that has no view open is closed when switching to another tab.
+* Album grid: press 'v' in album list to show albums as cards in multiple columns, and again to return to list.
+* Jump to letter in artists / albums: select 'Jump' and a letter, or '#' for names
+	starting with a number or symbol. 'All' shows every item again.
+* Show details: codec, file path, ids etc. of highlighted song or album.
+* Jump to playing song: opens album of the song, or selects the song in queue.
+* Open in browser: opens item in server's web interface. Over ssh, link is copied to clipboard instead.
+	Copy link copies it to clipboard with OSC 52, which most terminals support.
 * Clear queue with 'clear'. This does not remove current song
-
+* Limit search to type: artist:, album:, song:, playlist: or genre:, e.g. 'artist:nirvana'
+* Limit albums to year, decade or range: 'year:1991', 'year:1990s' or 'year:1990..2000'
 
 [yellow]Mouse[-]:
 You can use mouse (if enabled) to navigate in application.
 * Select: Left click / double click
 * Open context menu: right click
-
-[yellow]Audio[-]:
-* Shuffle: %s
-* Mute: %s
-`, util.PackKeyBindingName(config.KeyBinds.Global.Shuffle, 20),
-		util.PackKeyBindingName(config.KeyBinds.Global.MuteUnmute, 20),
-	)
+* Go back to a view: click view in breadcrumbs
+* Seek: click progress bar
+* Change volume: scroll over status bar
+`, util.PackKeyBindingName(config.KeyBinds.NavigationBar.CheatSheet, 20), formatShortcuts(shortcuts()))
 }
 
 func formatBytes(bytes uint64) string {
@@ -201,9 +198,9 @@ func formatBytes(bytes uint64) string {
 }
 
 func (h *Help) statsPage() string {
-	text := "[yellow]Server Info[-]\n"
+	text := "[yellow]" + i18n.T("Server Info") + "[-]\n"
 	if h.stats.ServerInfo != nil {
-		text += fmt.Sprintf("Server type: %s\nName: %s\nVersion: %s\nId: %s\nMessage: %s",
+		text += i18n.Tf("Server type: %s\nName: %s\nVersion: %s\nId: %s\nMessage: %s",
 			h.stats.ServerInfo.ServerType,
 			h.stats.ServerInfo.Name,
 			h.stats.ServerInfo.Version,
@@ -219,20 +216,40 @@ func (h *Help) statsPage() string {
 		}
 	}
 
-	text += "\n\n[yellow]Configuration[-]\n"
-	text += fmt.Sprintf("Log file: %s\nConfig file: %s",
+	text += "\n\n[yellow]" + i18n.T("Configuration") + "[-]\n"
+	text += i18n.Tf("Log file: %s\nConfig file: %s",
 		h.stats.LogFile, h.stats.ConfigFile)
 
-	text += "\n\n[yellow]Statistics[-]\n"
-	text += fmt.Sprintf("Memory allocated: %s",
+	text += "\n\n[yellow]" + i18n.T("Statistics") + "[-]\n"
+	text += i18n.Tf("Memory allocated: %s",
 		h.stats.HeapString())
 
-	text += "\n\n[yellow]Local storage[-]\n"
-	text += fmt.Sprintf("Database file: %s\nDatabase size: %s\nLast updated: %s",
+	text += "\n\n[yellow]" + i18n.T("Network") + "[-]\n"
+	text += i18n.Tf("Requests: %d\nRetried: %d\nShared: %d\nCache hits: %s\n"+
+		"Received: %s\nUncompressed: %s\nServed from cache: %s",
+		h.stats.RequestStats.Requests,
+		h.stats.RequestStats.Retries,
+		h.stats.RequestStats.Shared,
+		h.stats.RequestStats.CacheHitString(),
+		h.stats.RequestStats.BytesReceivedString(),
+		h.stats.RequestStats.BytesDecodedString(),
+		h.stats.RequestStats.BytesCachedString())
+
+	text += "\n\n[yellow]" + i18n.T("Local storage") + "[-]\n"
+	text += i18n.Tf("Database file: %s\nDatabase size: %s\nLast updated: %s",
 		h.stats.StorageInfo.DbFile,
 		h.stats.StorageInfo.DbSizeString(),
 		h.stats.StorageInfo.LastUpdatedString())
 
+	if config.AppConfig.Player.EnableHistoryDb {
+		text += "\n\n[yellow]" + i18n.T("Play history") + "[-]\n"
+		text += i18n.Tf("Plays: %d\nSongs: %d\nListened: %s\nAverage completion: %s\nPending scrobbles: %d",
+			h.stats.PlayStats.Plays,
+			h.stats.PlayStats.Songs,
+			util.SecToStringApproximate(h.stats.PlayStats.Listened),
+			h.stats.PlayStats.CompletionString(),
+			h.stats.PlayStats.PendingScrobbles)
+	}
 	return text
 }
 
@@ -249,13 +266,14 @@ func logo() string {
 }
 
 func helpText() string {
-	return `
-[darkorange]Jellycli[-] is a terminal music player for Jellyfin and Subsonic-compatible media servers.
+	return i18n.T(`
+[darkorange]Jellycli[-] is a terminal music player for Jellyfin, Subsonic and Ampache-compatible media servers.
 Source code: https://github.com/tryffel/jellycli
 
 [yellow::b]Features [-:-:-]
-* View artists, songs, albums, playlists, favorite artists and albums, genres, similar albums and artists
+* View artists, songs, albums, playlists, favorite artists and albums, genres, composers, similar albums and artists
 * Queue: add songs and albums, reorder & delete songs, clear queue
+* Instant mix from any song, album, artist, playlist or genre (genre radio) from context menu
 * Control (and view) play state through Dbus integration
 * Remote control over Jellyfin server. Currently implemented:
     * [x] Play / pause / stop
@@ -306,5 +324,5 @@ edit that function as you like.
 
 Press Escape to return.
 
-`
+`)
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/gdamore/tcell"
 	"gitlab.com/tslocum/cview"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 )
 
 type Message struct {
@@ -37,13 +38,13 @@ func NewMessage() *Message {
 		TextView: cview.NewTextView(),
 		visible:  false,
 		closeCb:  nil,
-		okBtn:    cview.NewButton("Close"),
+		okBtn:    cview.NewButton(i18n.T("Close")),
 	}
 
 	colors := config.Color.Modal
 	m.SetBackgroundColor(colors.Background)
 	m.SetBorder(true)
-	m.SetTitle("Info")
+	m.SetTitle(i18n.T("Info"))
 	m.SetBorderColor(config.Color.Border)
 	m.SetTitleColor(config.Color.TextSecondary)
 	m.SetTextColor(colors.Text)
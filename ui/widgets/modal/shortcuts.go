@@ -0,0 +1,106 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package modal
+
+import (
+	"fmt"
+	"strings"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/util"
+)
+
+// shortcut is a key binding shown in help and cheat sheet.
+type shortcut struct {
+	group  string
+	action string
+	key    string
+}
+
+// fixedShortcuts are key bindings that cannot be configured.
+var fixedShortcuts = []shortcut{
+	{i18n.N("Navigation"), i18n.N("Switch between panels"), "Tab"},
+	{i18n.N("Navigation"), i18n.N("Select button or item"), "Enter"},
+	{i18n.N("Navigation"), i18n.N("Open context menu"), "Alt+Enter"},
+	{i18n.N("Navigation"), i18n.N("Close application"), "Ctrl-C"},
+	{i18n.N("Navigation"), i18n.N("Up / Down (vim)"), "J / K"},
+	{i18n.N("Navigation"), i18n.N("Top / Bottom of list"), "g / G"},
+	{i18n.N("Navigation"), i18n.N("Page up / down"), "Ctrl+F / Ctrl+B"},
+	{i18n.N("Navigation"), i18n.N("Filter list items"), "Space or /"},
+	{i18n.N("Navigation"), i18n.N("Switch to tab, or open a new tab"), "Alt+1 - Alt+9"},
+	{i18n.N("Navigation"), i18n.N("Resize navigation pane"), "Alt+Left / Alt+Right"},
+	{i18n.N("Queue"), i18n.N("Delete song"), "Del"},
+	{i18n.N("Queue"), i18n.N("Move song up"), "Ctrl-K"},
+	{i18n.N("Queue"), i18n.N("Move song down"), "Ctrl-J"},
+	{i18n.N("Search"), i18n.N("Open result category"), "1 - 9"},
+}
+
+// shortcuts returns current key bindings followed by fixed bindings, translated.
+func shortcuts() []shortcut {
+	bindings := config.KeyBinds.List()
+	list := make([]shortcut, 0, len(bindings)+len(fixedShortcuts))
+	for _, v := range bindings {
+		list = append(list, shortcut{group: i18n.T(v.Group), action: i18n.T(v.Action), key: util.KeyBindingName(v.Key)})
+	}
+	for _, v := range fixedShortcuts {
+		list = append(list, shortcut{group: i18n.T(v.group), action: i18n.T(v.action), key: v.key})
+	}
+	return list
+}
+
+// filterShortcuts returns shortcuts that contain all words in query, ignoring case.
+func filterShortcuts(list []shortcut, query string) []shortcut {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return list
+	}
+
+	filtered := make([]shortcut, 0, len(list))
+	for _, v := range list {
+		text := strings.ToLower(v.group + " " + v.action + " " + v.key)
+		match := true
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				match = false
+				break
+			}
+		}
+		if match {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// formatShortcuts formats shortcuts under group titles. Shortcuts must be ordered by group.
+func formatShortcuts(list []shortcut) string {
+	text := ""
+	group := ""
+	for _, v := range list {
+		if v.group != group {
+			if group != "" {
+				text += "\n"
+			}
+			group = v.group
+			text += fmt.Sprintf("[yellow]%s[-]:\n", group)
+		}
+		text += fmt.Sprintf("* %s: %s\n", v.action, v.key)
+	}
+	return text
+}
@@ -0,0 +1,64 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package modal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_filterShortcuts(t *testing.T) {
+	list := []shortcut{
+		{"Audio", "Volume up", "F10"},
+		{"Audio", "Volume down", "F9"},
+		{"Queue", "Delete song", "Del"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []shortcut
+	}{
+		{name: "empty", query: " ", want: list},
+		{name: "action", query: "volume", want: list[:2]},
+		{name: "multiple words", query: "DOWN volume", want: list[1:2]},
+		{name: "key", query: "f10", want: list[:1]},
+		{name: "group", query: "queue", want: list[2:]},
+		{name: "no match", query: "shuffle", want: []shortcut{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterShortcuts(list, tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterShortcuts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatShortcuts(t *testing.T) {
+	list := []shortcut{
+		{"Audio", "Volume up", "F10"},
+		{"Audio", "Volume down", "F9"},
+		{"Queue", "Delete song", "Del"},
+	}
+	want := "[yellow]Audio[-]:\n* Volume up: F10\n* Volume down: F9\n\n[yellow]Queue[-]:\n* Delete song: Del\n"
+	if got := formatShortcuts(list); got != want {
+		t.Errorf("formatShortcuts() = %q, want %q", got, want)
+	}
+}
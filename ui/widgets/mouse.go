@@ -0,0 +1,153 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/twidgets"
+)
+
+// listAction is done with keyboard on list after mouse event is handled.
+type listAction int
+
+const (
+	listActionNone listAction = iota
+	// listActionPlay plays or opens selected item, like enter.
+	listActionPlay
+	listActionScrollUp
+	listActionScrollDown
+)
+
+// mouseList is implemented by views that show items in a list. Nil list means list is not shown.
+type mouseList interface {
+	scrollList() *twidgets.ScrollList
+}
+
+// mouseAction maps mouse action over list items with mouse config. It returns action to pass to list,
+// false if event is not passed to list at all, and list action to do after event is handled.
+func mouseAction(action cview.MouseAction, gui *config.Gui) (cview.MouseAction, bool, listAction) {
+	switch action {
+	case cview.MouseLeftClick:
+		if gui.MouseSingleClickPlay {
+			return action, true, listActionPlay
+		}
+	case cview.MouseRightClick:
+		switch gui.MouseRightClick {
+		case config.MouseRightClickPlay:
+			// select clicked item first
+			return cview.MouseLeftClick, true, listActionPlay
+		case config.MouseRightClickNone:
+			return action, false, listActionNone
+		}
+	case cview.MouseScrollUp:
+		if gui.MouseScrollLines > 1 {
+			return action, false, listActionScrollUp
+		}
+	case cview.MouseScrollDown:
+		if gui.MouseScrollLines > 1 {
+			return action, false, listActionScrollDown
+		}
+	}
+	return action, true, listActionNone
+}
+
+// doListAction sends keys for action to list.
+func doListAction(list *twidgets.ScrollList, action listAction, lines int, setFocus func(p cview.Primitive)) {
+	key := tcell.KeyEnter
+	count := 1
+	switch action {
+	case listActionNone:
+		return
+	case listActionScrollUp:
+		key = tcell.KeyUp
+		count = lines
+	case listActionScrollDown:
+		key = tcell.KeyDown
+		count = lines
+	}
+	handler := list.InputHandler()
+	for i := 0; i < count; i++ {
+		handler(tcell.NewEventKey(key, 0, tcell.ModNone), setFocus)
+	}
+}
+
+// inRect returns true if position is inside primitive.
+func inRect(p cview.Primitive, x, y int) bool {
+	rectX, rectY, width, height := p.GetRect()
+	return x >= rectX && x < rectX+width && y >= rectY && y < rectY+height
+}
+
+// mouseCapture applies mouse configuration before widgets handle mouse events.
+// Events over ignored widgets are dropped.
+func (w *Window) mouseCapture(event *tcell.EventMouse, action cview.MouseAction) (*tcell.EventMouse, cview.MouseAction) {
+	if event == nil || w.hasModal || action == cview.MouseMove {
+		return event, action
+	}
+	gui := &config.AppConfig.Gui
+	x, y := event.Position()
+
+	// hidden widgets keep their last position, so widgets drawn on top of them are checked first
+	widgets := []struct {
+		name      string
+		primitive cview.Primitive
+		visible   bool
+	}{
+		{config.MouseWidgetStatus, w.status, true},
+		{config.MouseWidgetBreadcrumbs, w.breadcrumbs, w.mediaView != nil},
+		{config.MouseWidgetQueue, w.dockedQueue, gui.QueueDocked},
+		{config.MouseWidgetMedia, w.mediaArea, w.mediaView != nil},
+		{config.MouseWidgetNavigation, w.mediaNav, true},
+	}
+	for _, v := range widgets {
+		if v.visible && inRect(v.primitive, x, y) {
+			if gui.MouseIgnored(v.name) {
+				return nil, action
+			}
+			break
+		}
+	}
+
+	lists := []cview.Primitive{w.mediaView}
+	if gui.QueueDocked {
+		lists = append(lists, w.dockedQueue)
+	}
+	var list *twidgets.ScrollList
+	for _, v := range lists {
+		if l, ok := v.(mouseList); ok && l.scrollList() != nil && inRect(l.scrollList(), x, y) {
+			list = l.scrollList()
+			break
+		}
+	}
+	if list == nil {
+		return event, action
+	}
+
+	mapped, pass, after := mouseAction(action, gui)
+	if after != listActionNone {
+		w.app.QueueUpdateDraw(func() {
+			doListAction(list, after, gui.MouseScrollLines, func(p cview.Primitive) { w.app.SetFocus(p) })
+		})
+	}
+	if !pass {
+		return nil, action
+	}
+	return event, mapped
+}
@@ -0,0 +1,64 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"gitlab.com/tslocum/cview"
+	"testing"
+	"tryffel.net/go/jellycli/config"
+)
+
+func Test_mouseAction(t *testing.T) {
+	defaults := config.Gui{MouseRightClick: config.MouseRightClickMenu, MouseScrollLines: 1}
+	singleClick := defaults
+	singleClick.MouseSingleClickPlay = true
+	rightPlay := defaults
+	rightPlay.MouseRightClick = config.MouseRightClickPlay
+	rightNone := defaults
+	rightNone.MouseRightClick = config.MouseRightClickNone
+	fastScroll := defaults
+	fastScroll.MouseScrollLines = 3
+
+	tests := []struct {
+		name      string
+		gui       config.Gui
+		action    cview.MouseAction
+		want      cview.MouseAction
+		wantPass  bool
+		wantAfter listAction
+	}{
+		{"default click", defaults, cview.MouseLeftClick, cview.MouseLeftClick, true, listActionNone},
+		{"default right click", defaults, cview.MouseRightClick, cview.MouseRightClick, true, listActionNone},
+		{"default scroll", defaults, cview.MouseScrollDown, cview.MouseScrollDown, true, listActionNone},
+		{"single click play", singleClick, cview.MouseLeftClick, cview.MouseLeftClick, true, listActionPlay},
+		{"right click play", rightPlay, cview.MouseRightClick, cview.MouseLeftClick, true, listActionPlay},
+		{"right click none", rightNone, cview.MouseRightClick, cview.MouseRightClick, false, listActionNone},
+		{"scroll up", fastScroll, cview.MouseScrollUp, cview.MouseScrollUp, false, listActionScrollUp},
+		{"scroll down", fastScroll, cview.MouseScrollDown, cview.MouseScrollDown, false, listActionScrollDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, pass, after := mouseAction(tt.action, &tt.gui)
+			if got != tt.want || pass != tt.wantPass || after != tt.wantAfter {
+				t.Errorf("mouseAction() = %v, %v, %v, want %v, %v, %v", got, pass, after,
+					tt.want, tt.wantPass, tt.wantAfter)
+			}
+		})
+	}
+}
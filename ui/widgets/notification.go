@@ -0,0 +1,140 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"gitlab.com/tslocum/cview"
+	"strings"
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/config"
+)
+
+// how long notification is visible
+const notificationTimeout = time.Second * 6
+
+// max length for notification text
+const notificationMaxLength = 120
+
+type notificationLevel int
+
+const (
+	notificationInfo notificationLevel = iota
+	notificationError
+)
+
+// notification is a non-blocking single line text that shows latest message and clears it
+// after timeout.
+type notification struct {
+	*cview.TextView
+	lock    sync.Mutex
+	timer   *time.Timer
+	counter int
+	// banner is shown persistently whenever there is no other message, e.g. when server is offline
+	banner string
+
+	// redrawFunc gets called when text is cleared
+	redrawFunc func()
+}
+
+func newNotification(redraw func()) *notification {
+	n := &notification{
+		TextView:   cview.NewTextView(),
+		redrawFunc: redraw,
+	}
+
+	n.SetBackgroundColor(config.Color.Notification.Background)
+	n.SetTextAlign(cview.AlignRight)
+	if accessible {
+		n.SetTextAlign(cview.AlignLeft)
+	}
+	n.SetWordWrap(false)
+	return n
+}
+
+// show shows message. Latest message always overrides previous one.
+// In accessible mode message is shown until next message, so that it can be read at any time.
+func (n *notification) show(level notificationLevel, msg string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if level == notificationError {
+		n.SetTextColor(config.Color.Notification.Error)
+	} else {
+		n.SetTextColor(config.Color.Notification.Info)
+	}
+	n.SetText(msg)
+
+	n.counter += 1
+	counter := n.counter
+	if n.timer != nil {
+		n.timer.Stop()
+		n.timer = nil
+	}
+	if accessible {
+		return
+	}
+	n.timer = time.AfterFunc(notificationTimeout, func() {
+		n.clear(counter)
+	})
+}
+
+// clear text if no newer message has been shown
+func (n *notification) clear(counter int) {
+	n.lock.Lock()
+	if counter != n.counter {
+		n.lock.Unlock()
+		return
+	}
+	n.showBanner()
+	n.timer = nil
+	n.lock.Unlock()
+
+	if n.redrawFunc != nil {
+		n.redrawFunc()
+	}
+}
+
+// setBanner sets persistent message. Empty message removes banner.
+func (n *notification) setBanner(msg string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.banner = msg
+	if n.timer == nil {
+		n.showBanner()
+	}
+}
+
+// showBanner shows banner, or clears text if there is no banner. Lock must be held.
+func (n *notification) showBanner() {
+	n.SetTextColor(config.Color.Notification.Error)
+	n.SetText(n.banner)
+}
+
+// limitNotification cuts text to single line of notificationMaxLength characters.
+func limitNotification(text string) string {
+	if i := strings.IndexRune(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	runes := []rune(text)
+	if len(runes) > notificationMaxLength {
+		return string(runes[:notificationMaxLength-3]) + "..."
+	}
+	return text
+}
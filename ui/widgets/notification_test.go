@@ -0,0 +1,79 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_limitNotification(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "short",
+			text: "Added 12 songs to queue",
+			want: "Added 12 songs to queue",
+		},
+		{
+			name: "multiline",
+			text: "get songs: server error\n<html>body</html>",
+			want: "get songs: server error",
+		},
+		{
+			name: "too long",
+			text: strings.Repeat("a", 130),
+			want: strings.Repeat("a", 117) + "...",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limitNotification(tt.text); got != tt.want {
+				t.Errorf("limitNotification() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_notificationBanner(t *testing.T) {
+	n := newNotification(nil)
+	n.setBanner("Server offline")
+	if got := n.GetText(true); got != "Server offline" {
+		t.Errorf("banner: got %q", got)
+	}
+
+	n.show(notificationInfo, "Added 2 songs to queue")
+	if got := n.GetText(true); got != "Added 2 songs to queue" {
+		t.Errorf("message: got %q", got)
+	}
+
+	// banner is restored after message
+	n.clear(n.counter)
+	if got := n.GetText(true); got != "Server offline" {
+		t.Errorf("banner after message: got %q", got)
+	}
+
+	n.setBanner("")
+	if got := n.GetText(true); got != "" {
+		t.Errorf("removed banner: got %q", got)
+	}
+}
@@ -22,37 +22,59 @@ import (
 	"fmt"
 	"github.com/gdamore/tcell"
 	"gitlab.com/tslocum/cview"
+	"strconv"
+	"strings"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/twidgets"
 )
 
-// PageSelector shows current page and buttons for next and previous page. SelectFunc can be nil,
-// in which case buttons do nothing.
+// pageSelectorFullWidth is width needed for showing first / last page buttons and page input.
+const pageSelectorFullWidth = 28
+
+// PageSelector shows current page and buttons for first, previous, next and last page, and input for
+// jumping to given page. SelectFunc can be nil, in which case buttons do nothing.
 type PageSelector struct {
 	*cview.Box
+	First      *button
 	Next       *button
 	Previous   *button
+	Last       *button
+	Jump       *pageInput
 	PageNum    int
 	TotalPages int
 
 	SelectFunc func(page int)
 	visible    bool
+	// full is set when there is room for first / last buttons and page input.
+	full bool
 }
 
 func NewPageSelector(selectPage func(int)) *PageSelector {
 	p := &PageSelector{
 		Box:        cview.NewBox(),
+		First:      newButton("<<"),
 		Next:       newButton(" > "),
 		Previous:   newButton(" < "),
+		Last:       newButton(">>"),
 		SelectFunc: selectPage,
 	}
+	p.Jump = newPageInput(p.jumpTo)
 
 	p.Box.SetBackgroundColor(config.Color.Background)
 	p.PageNum = 1
+	p.First.SetSelectedFunc(p.first)
 	p.Next.SetSelectedFunc(p.next)
 	p.Previous.SetSelectedFunc(p.previous)
+	p.Last.SetSelectedFunc(p.last)
 	return p
 }
 
+// Selectables returns buttons and page input in order they are shown.
+func (p *PageSelector) Selectables() []twidgets.Selectable {
+	return []twidgets.Selectable{p.First, p.Previous, p.Next, p.Last, p.Jump}
+}
+
 // SetPage sets current page
 func (p *PageSelector) SetPage(n int) {
 	p.PageNum = n
@@ -75,10 +97,53 @@ func (p *PageSelector) previous() {
 	}
 }
 
+func (p *PageSelector) first() {
+	if p.PageNum > 0 && p.SelectFunc != nil {
+		p.SelectFunc(0)
+	}
+}
+
+func (p *PageSelector) last() {
+	if p.PageNum < p.TotalPages-1 && p.SelectFunc != nil {
+		p.SelectFunc(p.TotalPages - 1)
+	}
+}
+
+// jumpTo selects page number given by user, starting from 1.
+func (p *PageSelector) jumpTo(text string) {
+	page, ok := parsePage(text, p.TotalPages)
+	if ok && page != p.PageNum && p.SelectFunc != nil {
+		p.SelectFunc(page)
+	}
+}
+
+// parsePage parses page number starting from 1 and returns page index. Numbers past last page select
+// the last page.
+func parsePage(text string, totalPages int) (int, bool) {
+	page, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || page < 1 || totalPages < 1 {
+		return 0, false
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+	return page - 1, true
+}
+
 func (p *PageSelector) Draw(screen tcell.Screen) {
 	p.Box.Draw(screen)
 	if p.visible {
 		x, y, _, _ := p.GetRect()
+		if p.full {
+			p.First.Draw(screen)
+			p.Previous.Draw(screen)
+			cview.Print(screen, fmt.Sprintf("%d / %d", p.PageNum+1, p.TotalPages),
+				x+6, y, 9, cview.AlignCenter, config.Color.Text)
+			p.Next.Draw(screen)
+			p.Last.Draw(screen)
+			p.Jump.Draw(screen)
+			return
+		}
 		p.Next.Draw(screen)
 
 		cview.Print(screen, fmt.Sprintf("%d / %d", p.PageNum+1, p.TotalPages),
@@ -90,10 +155,84 @@ func (p *PageSelector) Draw(screen tcell.Screen) {
 func (p *PageSelector) SetRect(x, y, width, height int) {
 	if height < 1 || width < 18 {
 		p.visible = false
+	} else if width >= pageSelectorFullWidth {
+		p.visible = true
+		p.full = true
+		p.First.SetRect(x, y, 2, 1)
+		p.Previous.SetRect(x+3, y, 3, 1)
+		p.Next.SetRect(x+15, y, 3, 1)
+		p.Last.SetRect(x+19, y, 2, 1)
+		p.Jump.SetRect(x+22, y, 6, 1)
 	} else {
 		p.visible = true
+		p.full = false
 		p.Previous.SetRect(x, y, 3, 1)
 		p.Next.SetRect(x+14, y, 3, 1)
+		// first / last are not shown, but page input still works from keyboard
+		p.First.SetRect(x, y, 0, 0)
+		p.Last.SetRect(x, y, 0, 0)
+		p.Jump.SetRect(x, y, 0, 0)
 	}
 	p.Box.SetRect(x, y, width, height)
 }
+
+// pageInput is an input for jumping to page number.
+type pageInput struct {
+	*cview.InputField
+	jumpFunc func(text string)
+	blurFunc func(key tcell.Key)
+}
+
+func newPageInput(jumpFunc func(text string)) *pageInput {
+	p := &pageInput{
+		InputField: cview.NewInputField(),
+		jumpFunc:   jumpFunc,
+	}
+	colors := config.Color
+	p.InputField.SetBackgroundColor(colors.Background)
+	p.InputField.SetFieldTextColor(colors.Text)
+	p.InputField.SetFieldBackgroundColor(colors.Background)
+	p.InputField.SetPlaceholderTextColor(colors.TextDisabled)
+	p.InputField.SetPlaceholder(i18n.T("page"))
+	p.InputField.SetAcceptanceFunc(acceptDigits)
+	p.InputField.SetDoneFunc(p.done)
+	return p
+}
+
+func (p *pageInput) SetBlurFunc(f func(key tcell.Key)) {
+	p.blurFunc = f
+}
+
+func (p *pageInput) Blur() {
+	p.InputField.SetFieldBackgroundColor(config.Color.Background)
+	p.InputField.SetFieldTextColor(config.Color.Text)
+	p.InputField.SetPlaceholderTextColor(config.Color.TextDisabled)
+	p.InputField.Blur()
+}
+
+func (p *pageInput) Focus(delegate func(p cview.Primitive)) {
+	p.InputField.SetFieldBackgroundColor(config.Color.BackgroundSelected)
+	p.InputField.SetFieldTextColor(config.Color.TextSelected)
+	p.InputField.SetPlaceholderTextColor(config.Color.TextDisabled2)
+	p.InputField.Focus(delegate)
+}
+
+func (p *pageInput) done(key tcell.Key) {
+	switch key {
+	case tcell.KeyEnter:
+		text := p.InputField.GetText()
+		p.InputField.SetText("")
+		if p.blurFunc != nil {
+			p.blurFunc(key)
+		}
+		if p.jumpFunc != nil {
+			p.jumpFunc(text)
+		}
+	case tcell.KeyEsc:
+		p.InputField.SetText("")
+	case tcell.KeyTab, tcell.KeyBacktab:
+		if p.blurFunc != nil {
+			p.blurFunc(key)
+		}
+	}
+}
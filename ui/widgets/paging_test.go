@@ -0,0 +1,46 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import "testing"
+
+func Test_parsePage(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		totalPages int
+		want       int
+		wantOk     bool
+	}{
+		{name: "first", text: "1", totalPages: 5, want: 0, wantOk: true},
+		{name: "middle", text: " 3 ", totalPages: 5, want: 2, wantOk: true},
+		{name: "past last", text: "12", totalPages: 5, want: 4, wantOk: true},
+		{name: "zero", text: "0", totalPages: 5, want: 0, wantOk: false},
+		{name: "empty", text: "", totalPages: 5, want: 0, wantOk: false},
+		{name: "no pages", text: "1", totalPages: 0, want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parsePage(tt.text, tt.totalPages)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("parsePage() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
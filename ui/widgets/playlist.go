@@ -23,6 +23,7 @@ import (
 	"github.com/gdamore/tcell"
 	"strings"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/util"
 	"tryffel.net/go/twidgets"
@@ -50,9 +51,9 @@ func NewPlaylistView(playSong func(song *models.Song), playSongs func(songs []*m
 		playSongFunc:  playSong,
 		playSongsFunc: playSongs,
 
-		playBtn: newButton("Play all"),
+		playBtn: newButton(i18n.T("Play all")),
 		context: operator,
-		options: newDropDown("Options"),
+		options: newDropDown(i18n.T("Options")),
 	}
 
 	p.itemList = newItemList(p.playSong)
@@ -80,36 +81,68 @@ func NewPlaylistView(playSong func(song *models.Song), playSongs func(songs []*m
 	p.setReducerVisible = p.showReduceInput
 
 	if p.context != nil {
-		p.list.AddContextItem("Play all from here", 0, func(index int) {
+		p.list.AddContextItem(i18n.T("Play all from here"), 0, func(index int) {
 			p.playFromSelected()
 		})
-		p.list.AddContextItem("View album", 0, func(index int) {
+		p.list.AddContextItem(i18n.T("View album"), 0, func(index int) {
 			selected := p.getSelectedIndex()
 			song := p.songs[selected]
 			p.context.ViewSongAlbum(song.song)
 		})
-		p.list.AddContextItem("View artist", 0, func(index int) {
+		p.list.AddContextItem(i18n.T("View artist"), 0, func(index int) {
 			if index < len(p.songs) && p.context != nil {
 				index := p.getSelectedIndex()
 				song := p.songs[index]
 				p.context.ViewSongArtist(song.song)
 			}
 		})
-		p.list.AddContextItem("Instant mix", 0, func(index int) {
+		p.list.AddContextItem(i18n.T("Instant mix"), 0, func(index int) {
 			if index < len(p.songs) && p.context != nil {
 				index := p.getSelectedIndex()
 				song := p.songs[index]
 				p.context.InstantMix(song.song)
 			}
 		})
+		p.list.AddContextItem(i18n.T("Open in browser"), 0, func(index int) {
+			if index < len(p.songs) {
+				p.context.OpenInBrowser(p.songs[p.getSelectedIndex()].song)
+			}
+		})
+		p.list.AddContextItem(i18n.T("Copy link"), 0, func(index int) {
+			if index < len(p.songs) {
+				p.context.CopyLink(p.songs[p.getSelectedIndex()].song)
+			}
+		})
+		p.list.AddContextItem(i18n.T("Copy info"), 0, func(index int) {
+			if index < len(p.songs) {
+				p.context.CopyInfo(p.songs[p.getSelectedIndex()].song)
+			}
+		})
+		p.list.AddContextItem(i18n.T("Volume offset"), 0, func(index int) {
+			if index < len(p.songs) {
+				p.context.AdjustGain(p.songs[p.getSelectedIndex()].song)
+			}
+		})
 
-		p.options.AddOption("Instant mix", func() {
+		p.options.AddOption(i18n.T("Instant mix"), func() {
 			p.context.InstantMix(p.playlist)
 		})
 
-		p.options.AddOption("Open in browser", func() {
+		p.options.AddOption(i18n.T("Open in browser"), func() {
 			p.context.OpenInBrowser(p.playlist)
 		})
+		p.options.AddOption(i18n.T("Copy link"), func() {
+			p.context.CopyLink(p.playlist)
+		})
+		p.options.AddOption(i18n.T("Download"), func() {
+			p.context.Download(p.playlist)
+		})
+		p.options.AddOption(i18n.T("Export M3U"), func() {
+			p.context.Export(p.playlist)
+		})
+		p.options.AddOption(i18n.T("Copy to my playlists"), func() {
+			p.context.CopyPlaylist(p.playlist)
+		})
 	}
 
 	p.list.ContextMenuList().SetBorder(true)
@@ -133,6 +166,9 @@ func (p *PlaylistView) SetPlaylist(playlist *models.Playlist) {
 
 	text += fmt.Sprintf("\n%d tracks  %s",
 		len(playlist.Songs), util.SecToStringApproximate(playlist.Duration))
+	if sharing := playlistSharing(playlist); sharing != "" {
+		text += "  " + sharing
+	}
 
 	p.description.SetText(text)
 	itemTexts := make([]string, len(playlist.Songs))
@@ -174,12 +210,7 @@ func (p *PlaylistView) playAll() {
 
 func (p *PlaylistView) playFromSelected() {
 	if p.playSongsFunc != nil {
-		index := p.list.GetSelectedIndex()
-		songs := make([]*models.Song, len(p.songs)-index)
-		for i, v := range p.songs[index:] {
-			songs[i] = v.song
-		}
-		p.playSongsFunc(songs)
+		p.playSongsFunc(songsFrom(p.songs, p.getSelectedIndex()))
 	}
 }
 
@@ -193,6 +224,9 @@ func (p *PlaylistView) listHandler(key *tcell.EventKey) *tcell.EventKey {
 }
 
 func (p *PlaylistView) updateSongText(song *albumSong) {
+	if song.setColumnText(config.SongColumnViewPlaylist) {
+		return
+	}
 	var name string
 	if song.showDiscNum {
 		name = fmt.Sprintf("%d %d. %s", song.song.DiscNumber, song.song.Index, song.song.Name)
@@ -219,3 +253,18 @@ func (p *PlaylistView) showReduceInput(visible bool) {
 		p.Grid.AddItem(p.list, 4, 0, 2, 10, 6, 20, false)
 	}
 }
+
+func (p *PlaylistView) setPlayingSong(id models.Id) {
+	markPlayingSong(p.songs, id)
+}
+
+func (p *PlaylistView) queueSource() string {
+	if p.playlist == nil {
+		return ""
+	}
+	return i18n.Tf("Playlist: %s", p.playlist.Name)
+}
+
+func (p *PlaylistView) highlightedItem() models.Item {
+	return songAtIndex(p.songs, p.getSelectedIndex())
+}
@@ -24,6 +24,7 @@ import (
 	"gitlab.com/tslocum/cview"
 	"strings"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/util"
 	"tryffel.net/go/twidgets"
@@ -32,7 +33,7 @@ import (
 //AlbumCover is a simple cover for album, it shows
 // album name, year and possible artists
 type PlaylistCover struct {
-	*cview.TextView
+	*highlightText
 	album   *models.Playlist
 	index   int
 	name    string
@@ -42,9 +43,9 @@ type PlaylistCover struct {
 
 func NewPlaylistCover(index int, playlist *models.Playlist) *PlaylistCover {
 	a := &PlaylistCover{
-		TextView: cview.NewTextView(),
-		album:    playlist,
-		index:    index,
+		highlightText: newHighlightText(),
+		album:         playlist,
+		index:         index,
 	}
 
 	a.SetBorder(false)
@@ -54,14 +55,28 @@ func NewPlaylistCover(index int, playlist *models.Playlist) *PlaylistCover {
 	ar := printArtists(a.artists, 40)
 	text := fmt.Sprintf("%d. %s\n%d songs, %s", index, playlist.Name,
 		playlist.SongCount, util.SecToStringApproximate(playlist.Duration))
+	if sharing := playlistSharing(playlist); sharing != "" {
+		text += ", " + sharing
+	}
 	if ar != "" {
 		text += "\n" + ar
 	}
 
-	a.TextView.SetText(text)
+	a.SetText(text)
 	return a
 }
 
+// playlistSharing describes playlist that other user has shared, or returns empty string for own playlist.
+func playlistSharing(playlist *models.Playlist) string {
+	if !playlist.Shared {
+		return ""
+	}
+	if playlist.ReadOnly {
+		return i18n.T("shared, read-only")
+	}
+	return i18n.T("shared")
+}
+
 func (a *PlaylistCover) SetRect(x, y, w, h int) {
 	a.TextView.SetRect(x, y, w, h)
 }
@@ -85,6 +100,9 @@ type Playlists struct {
 	selectFunc     func(album *models.Playlist)
 	playlistCovers []*PlaylistCover
 	playBtn        *button
+	importBtn      *button
+	// importFunc shows playlist import.
+	importFunc func()
 }
 
 func (pl *Playlists) Clear() {
@@ -109,7 +127,7 @@ func (pl *Playlists) SetPlaylists(playlists []*models.Playlist) {
 		itemTexts[i] = strings.ToLower(v.Name)
 	}
 	pl.list.AddItems(items...)
-	pl.description.SetText(fmt.Sprintf("Playlists: %d", len(playlists)))
+	pl.description.SetText(i18n.Tf("Playlists: %d", len(playlists)))
 	pl.items = items
 	pl.itemsTexts = itemTexts
 	pl.searchItemsSet()
@@ -119,7 +137,8 @@ func (pl *Playlists) SetPlaylists(playlists []*models.Playlist) {
 func NewPlaylists(selectPlaylist func(playlist *models.Playlist)) *Playlists {
 	a := &Playlists{
 		selectFunc: selectPlaylist,
-		playBtn:    newButton("Play all"),
+		playBtn:    newButton(i18n.T("Play all")),
+		importBtn:  newButton(i18n.T("Import")),
 	}
 	a.itemList = newItemList(a.selectAlbum)
 	a.itemList.list.ItemHeight = 3
@@ -127,18 +146,24 @@ func NewPlaylists(selectPlaylist func(playlist *models.Playlist)) *Playlists {
 	a.itemList.setReducerVisible = a.showReduceInput
 	a.list.Grid.SetColumns(-1, 5)
 
-	selectables := []twidgets.Selectable{a.prevBtn, a.playBtn, a.list}
+	selectables := []twidgets.Selectable{a.prevBtn, a.playBtn, a.importBtn, a.list}
 	a.prevBtn.SetSelectedFunc(a.goBack)
+	a.importBtn.SetSelectedFunc(func() {
+		if a.importFunc != nil {
+			a.importFunc()
+		}
+	})
 	a.Banner.Selectable = selectables
 	a.Grid.SetRows(1, 1, 1, 1, -1, 3)
 	a.Grid.SetColumns(6, 2, 10, -1, 10, -1, 10, -3)
 	a.Grid.SetMinSize(1, 6)
 	a.Grid.SetBackgroundColor(config.Color.Background)
-	a.description.SetText("Playlists")
+	a.description.SetText(i18n.T("Playlists"))
 	a.list.Grid.SetColumns(1, -1)
 	a.Grid.AddItem(a.prevBtn, 0, 0, 1, 1, 1, 5, false)
 	a.Grid.AddItem(a.description, 0, 2, 2, 6, 1, 10, false)
 	a.Grid.AddItem(a.playBtn, 3, 2, 1, 1, 1, 10, false)
+	a.Grid.AddItem(a.importBtn, 3, 4, 1, 1, 1, 10, false)
 	a.Grid.AddItem(a.list, 4, 0, 2, 8, 6, 20, false)
 
 	a.listFocused = false
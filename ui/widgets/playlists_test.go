@@ -0,0 +1,44 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_playlistSharing(t *testing.T) {
+	tests := []struct {
+		name     string
+		playlist *models.Playlist
+		want     string
+	}{
+		{name: "own", playlist: &models.Playlist{}, want: ""},
+		{name: "own read only", playlist: &models.Playlist{ReadOnly: true}, want: ""},
+		{name: "shared", playlist: &models.Playlist{Shared: true}, want: "shared"},
+		{name: "shared read only", playlist: &models.Playlist{Shared: true, ReadOnly: true}, want: "shared, read-only"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := playlistSharing(tt.playlist); got != tt.want {
+				t.Errorf("playlistSharing() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
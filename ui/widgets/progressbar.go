@@ -18,17 +18,6 @@
 
 package widgets
 
-const (
-	fullBlock     = "█"
-	threeQuarters = "▊"
-	twoQuarters   = "▌"
-	oneQuarter    = "▎"
-
-	startChar = "┫"
-	stopChar  = "┣"
-	empty     = "╍"
-)
-
 type ProgressBar interface {
 	SetWidth(w int)
 	SetMaximum(m int)
@@ -61,7 +50,7 @@ func (p *progressBar) SetMaximum(max int) {
 }
 
 func (p *progressBar) Draw(currentValue int) string {
-	text := startChar
+	text := symbol.progressStart
 
 	// Progress as percent
 	progress := int(float32(currentValue) / float32(p.maximumValue) * 1000)
@@ -79,26 +68,26 @@ func (p *progressBar) Draw(currentValue int) string {
 		blocks = splits / 4
 		for i := 0; i < blocks; i++ {
 			filled += 1
-			text += fullBlock
+			text += symbol.progressFull
 		}
 		switch splits % 4 {
 		case 0:
 			break
 		case 1:
 			filled += 1
-			text += oneQuarter
+			text += symbol.progressQuarter
 		case 2:
 			filled += 1
-			text += twoQuarters
+			text += symbol.progressHalf
 		case 3:
 			filled += 1
-			text += threeQuarters
+			text += symbol.progressThreeQuarters
 		}
 	}
 
 	for i := 0; i < p.width-filled+2; i++ {
-		text += empty
+		text += symbol.progressEmpty
 	}
-	text += stopChar
+	text += symbol.progressStop
 	return text
 }
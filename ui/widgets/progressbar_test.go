@@ -114,3 +114,16 @@ func TestProgressBar_Draw(t *testing.T) {
 	}
 
 }
+
+func Test_progressBar_DrawAscii(t *testing.T) {
+	symbol = asciiSymbols
+	defer func() { symbol = unicodeSymbols }()
+
+	p := NewProgressBar(10, 100)
+	if got := p.Draw(25); got != "[##=.......]" {
+		t.Errorf("Draw() = %v, want [##=.......]", got)
+	}
+	if got := spectrumBars([]float64{0, 0.5, 1}); got != " =@" {
+		t.Errorf("spectrumBars() = %q, want %q", got, " =@")
+	}
+}
@@ -21,7 +21,10 @@ package widgets
 import (
 	"fmt"
 	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"strings"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/util"
@@ -33,6 +36,10 @@ import (
 type Queue struct {
 	*itemList
 	songs []*albumSong
+	// rows maps list items to songs and group headers. Nil if songs are not grouped.
+	rows []queueRow
+	// collapsed are group ids of collapsed groups.
+	collapsed map[int]bool
 
 	playSongFunc  func(song *models.Song)
 	playSongsFunc func(songs []*models.Song)
@@ -41,13 +48,19 @@ type Queue struct {
 
 	clearBtn  *button
 	clearFunc func()
+
+	exportBtn *button
+	// exportFunc exports songs in the list to playlist file.
+	exportFunc func(songs []*models.Song)
 }
 
 //NewQueue initializes new album view
 func NewQueue() *Queue {
 	q := &Queue{
-		itemList: newItemList(nil),
-		clearBtn: newButton("Clear"),
+		itemList:  newItemList(nil),
+		clearBtn:  newButton(i18n.T("Clear")),
+		exportBtn: newButton(i18n.T("Export")),
+		collapsed: map[int]bool{},
 	}
 
 	q.list.ItemHeight = 2
@@ -56,17 +69,21 @@ func NewQueue() *Queue {
 	q.list.Grid.SetColumns(1, -1)
 
 	q.clearBtn.SetSelectedFunc(q.clearQueue)
-	q.Banner.Grid.SetRows(1, 1, 1, 1, -1)
+	q.exportBtn.SetSelectedFunc(q.exportSongs)
+	q.Banner.Grid.SetRows(1, 1, 1, 1, -1, 3)
 	q.Banner.Grid.SetColumns(6, 2, 10, -1, 10, -1, 10, -3)
 	q.Banner.Grid.SetMinSize(1, 6)
 
 	q.Banner.Grid.AddItem(q.prevBtn, 0, 0, 1, 1, 1, 5, false)
 	q.Banner.Grid.AddItem(q.description, 0, 2, 2, 6, 1, 10, false)
 	q.Banner.Grid.AddItem(q.clearBtn, 3, 2, 1, 1, 1, 10, true)
-	q.Banner.Grid.AddItem(q.list, 4, 0, 1, 8, 4, 10, false)
+	q.Banner.Grid.AddItem(q.exportBtn, 3, 4, 1, 1, 1, 10, false)
+	q.Banner.Grid.AddItem(q.list, 4, 0, 2, 8, 4, 10, false)
 
-	selectables := []twidgets.Selectable{q.prevBtn, q.clearBtn, q.list}
+	selectables := []twidgets.Selectable{q.prevBtn, q.clearBtn, q.exportBtn, q.list}
 	q.Banner.Selectable = selectables
+	q.reduceEnabled = true
+	q.setReducerVisible = q.showReduceInput
 	q.printDescription()
 	return q
 }
@@ -82,34 +99,188 @@ func (q *Queue) AddSong(song *models.Song, index int) {
 	q.list.AddItem(s)
 }
 
+// EnableGroups adds context menu actions for groups. Groups are only shown if controller
+// implements interfaces.QueueGroupController.
+func (q *Queue) EnableGroups() {
+	q.list.AddContextItem(i18n.T("Collapse / expand group"), 0, func(index int) {
+		if row, ok := q.selectedRow(); ok && row.header != nil {
+			q.toggleGroup(row.header)
+		} else if ok && row.group != nil {
+			q.toggleGroup(q.headerOf(row.group))
+		}
+	})
+	q.list.AddContextItem(i18n.T("Remove group"), 0, func(index int) {
+		if row, ok := q.selectedRow(); ok {
+			q.removeGroup(row.group)
+		}
+	})
+	q.itemList.initContextMenuList()
+}
+
 // SetSongs clears current songs and sets new ones
 func (q *Queue) SetSongs(songs []*models.Song) {
 	q.Clear()
 	q.songs = make([]*albumSong, len(songs))
-	items := make([]twidgets.ListItem, len(songs))
 	for i, v := range songs {
 		s := newAlbumSong(v, false, i+1)
 		q.songs[i] = s
 		q.songs[i].updateTextFunc = q.updateSongText
-		items[i] = s
 	}
 	// colorize first item in queue
 	if len(q.songs) > 0 {
 		q.songs[0].playing = true
 	}
-	q.list.AddItems(items...)
+
+	var groups []*queueGroup
+	if controller, ok := q.controller.(interfaces.QueueGroupController); ok {
+		groups = groupQueue(songs, controller.GetQueueSources())
+	}
+	q.setRows(groups)
 	q.printDescription()
 }
 
+// setRows shows songs under headers of their groups. Songs of collapsed groups are hidden.
+// If there are less than two groups, songs are shown without headers.
+func (q *Queue) setRows(groups []*queueGroup) {
+	if len(groups) < 2 {
+		q.rows = nil
+		items := make([]twidgets.ListItem, len(q.songs))
+		for i, v := range q.songs {
+			items[i] = v
+		}
+		q.list.Clear()
+		q.list.AddItems(items...)
+		q.setItems(items)
+		return
+	}
+
+	q.rows = make([]queueRow, 0, len(q.songs)+len(groups))
+	items := make([]twidgets.ListItem, 0, len(q.songs)+len(groups))
+	for _, group := range groups {
+		header := newQueueHeader(group, q.collapsed[group.group])
+		q.rows = append(q.rows, queueRow{song: -1, group: group, header: header})
+		items = append(items, header)
+		if header.collapsed {
+			continue
+		}
+		for i := group.start; i < group.start+group.count; i++ {
+			q.rows = append(q.rows, queueRow{song: i, group: group})
+			items = append(items, q.songs[i])
+		}
+	}
+	q.list.Clear()
+	q.list.AddItems(items...)
+	q.setItems(items)
+}
+
 // Clear removes all songs
 func (q *Queue) Clear() {
 	q.list.Clear()
 	q.songs = []*albumSong{}
+	q.rows = nil
+	q.items = nil
+	q.itemsTexts = nil
 	q.printDescription()
 }
 
+// set filterable items. If filter is active, apply it to new items.
+func (q *Queue) setItems(items []twidgets.ListItem) {
+	q.items = items
+	q.itemsTexts = make([]string, len(items))
+	for i := range items {
+		index := q.songIndex(i)
+		if index < 0 {
+			q.itemsTexts[i] = strings.ToLower(q.rows[i].group.name)
+			continue
+		}
+		song := q.songs[index].song
+		text := song.Name
+		for _, artist := range song.Artists {
+			text += " " + artist.Name
+		}
+		q.itemsTexts[i] = strings.ToLower(text)
+	}
+	q.searchItemsSet()
+	if q.reduceVisible {
+		q.reduce(q.reduceInput.GetText())
+	}
+}
+
+// songIndex returns index of song in queue at list row, or -1 if row is a group header.
+func (q *Queue) songIndex(row int) int {
+	if q.rows == nil {
+		return row
+	}
+	if row < 0 || row >= len(q.rows) {
+		return -1
+	}
+	return q.rows[row].song
+}
+
+// selectedRow returns selected row, if songs are grouped.
+func (q *Queue) selectedRow() (queueRow, bool) {
+	index := q.getSelectedIndex()
+	if index < 0 || index >= len(q.rows) {
+		return queueRow{}, false
+	}
+	return q.rows[index], true
+}
+
+func (q *Queue) headerOf(group *queueGroup) *queueHeader {
+	for _, v := range q.rows {
+		if v.header != nil && v.group == group {
+			return v.header
+		}
+	}
+	return nil
+}
+
+// toggleGroup collapses or expands group and keeps its header selected.
+func (q *Queue) toggleGroup(header *queueHeader) {
+	if header == nil {
+		return
+	}
+	q.resetReduce()
+	group := header.group
+	q.collapsed[group.group] = !header.collapsed
+	groups := make([]*queueGroup, 0)
+	for _, v := range q.rows {
+		if v.header != nil {
+			groups = append(groups, v.group)
+		}
+	}
+	q.setRows(groups)
+	for i, v := range q.rows {
+		if v.header != nil && v.group == group {
+			q.list.SetSelected(i)
+		}
+	}
+}
+
+func (q *Queue) removeGroup(group *queueGroup) {
+	if group == nil {
+		return
+	}
+	if controller, ok := q.controller.(interfaces.QueueGroupController); ok {
+		delete(q.collapsed, group.group)
+		controller.RemoveGroup(group.group)
+	}
+}
+
+func (q *Queue) showReduceInput(visible bool) {
+	if visible {
+		q.Banner.Grid.AddItem(q.reduceInput, 5, 0, 1, 8, 1, 20, false)
+		q.Banner.Grid.RemoveItem(q.list)
+		q.Banner.Grid.AddItem(q.list, 4, 0, 1, 8, 4, 10, false)
+	} else {
+		q.Banner.Grid.RemoveItem(q.reduceInput)
+		q.Banner.Grid.RemoveItem(q.list)
+		q.Banner.Grid.AddItem(q.list, 4, 0, 2, 8, 4, 10, false)
+	}
+}
+
 func (q *Queue) printDescription() {
-	text := "Queue"
+	text := i18n.T("Queue")
 	if len(q.songs) > 0 {
 		duration := 0
 		for _, v := range q.songs {
@@ -121,22 +292,26 @@ func (q *Queue) printDescription() {
 }
 
 func (q *Queue) listHandler(key *tcell.EventKey) *tcell.EventKey {
+	index := q.songIndex(q.getSelectedIndex())
+	row, grouped := q.selectedRow()
 	switch key.Key() {
 	case tcell.KeyEnter:
+		if grouped && row.header != nil {
+			q.toggleGroup(row.header)
+		}
 		return nil
 	case tcell.KeyCtrlJ:
-		if q.controller != nil {
-			index := q.list.GetSelectedIndex()
+		if q.controller != nil && index >= 0 {
 			_ = q.controller.Reorder(index, false)
 		}
 	case tcell.KeyCtrlK:
-		if q.controller != nil {
-			index := q.list.GetSelectedIndex()
+		if q.controller != nil && index >= 0 {
 			_ = q.controller.Reorder(index, true)
 		}
 	case tcell.KeyDEL, tcell.KeyDelete:
-		if q.controller != nil {
-			index := q.list.GetSelectedIndex()
+		if grouped && row.header != nil {
+			q.removeGroup(row.group)
+		} else if q.controller != nil && index >= 0 {
 			q.controller.RemoveSong(index)
 		}
 	}
@@ -148,6 +323,9 @@ func (q *Queue) updateSongText(song *albumSong) {
 	if song.playing {
 		song.SetTextColor(config.Color.TextSongPlaying)
 	}
+	if song.setColumnText(config.SongColumnViewQueue) {
+		return
+	}
 
 	if song.showDiscNum {
 		name = fmt.Sprintf("%d %d. %s", song.song.DiscNumber, song.song.Index, song.song.Name)
@@ -169,3 +347,110 @@ func (q *Queue) clearQueue() {
 		q.clearFunc()
 	}
 }
+
+func (q *Queue) exportSongs() {
+	if q.exportFunc == nil {
+		return
+	}
+	songs := make([]*models.Song, len(q.songs))
+	for i, v := range q.songs {
+		songs[i] = v.song
+	}
+	q.exportFunc(songs)
+}
+
+// selectPlaying selects currently playing song, which is the first song in queue.
+// If group of playing song is collapsed, its header is selected.
+func (q *Queue) selectPlaying() {
+	if len(q.rows) > 1 && q.rows[1].song == 0 {
+		q.selectIndex(1)
+	} else {
+		q.selectIndex(0)
+	}
+}
+
+func (q *Queue) highlightedItem() models.Item {
+	return songAtIndex(q.songs, q.songIndex(q.getSelectedIndex()))
+}
+
+// queueGroup is consecutive songs in queue that were added from same source at the same time.
+type queueGroup struct {
+	group int
+	name  string
+	// start is index of first song in queue
+	start    int
+	count    int
+	duration int
+}
+
+// queueRow is a row in queue list, either a song or header of group.
+type queueRow struct {
+	// song is index of song in queue, -1 for header.
+	song   int
+	group  *queueGroup
+	header *queueHeader
+}
+
+// groupQueue groups consecutive songs by their source. If sources don't match songs,
+// e.g. queue has changed in between, return nil.
+func groupQueue(songs []*models.Song, sources []interfaces.QueueSource) []*queueGroup {
+	if len(sources) != len(songs) {
+		return nil
+	}
+	groups := make([]*queueGroup, 0)
+	var group *queueGroup
+	for i, v := range sources {
+		if group == nil || group.group != v.Group {
+			group = &queueGroup{group: v.Group, name: v.Name, start: i}
+			groups = append(groups, group)
+		}
+		group.count++
+		group.duration += songs[i].Duration
+	}
+	return groups
+}
+
+// queueHeader is header of group in queue.
+type queueHeader struct {
+	*cview.TextView
+	group     *queueGroup
+	collapsed bool
+}
+
+func newQueueHeader(group *queueGroup, collapsed bool) *queueHeader {
+	h := &queueHeader{
+		TextView:  cview.NewTextView(),
+		group:     group,
+		collapsed: collapsed,
+	}
+	h.SetBackgroundColor(config.Color.Background)
+	h.SetTextColor(config.Color.TextSecondary)
+	h.SetBorderPadding(0, 0, 1, 1)
+	h.SetText(h.text())
+	return h
+}
+
+func (h *queueHeader) text() string {
+	marker := symbol.expanded
+	if h.collapsed {
+		marker = symbol.collapsed
+	}
+	name := h.group.name
+	if name == "" {
+		name = i18n.T("Other songs")
+	}
+	return fmt.Sprintf("%s %s\n     %s", marker, cview.Escape(name),
+		i18n.Tf("%d songs, %s", h.group.count, util.SecToStringApproximate(h.group.duration)))
+}
+
+func (h *queueHeader) SetSelected(s twidgets.Selection) {
+	if s == twidgets.Selected {
+		h.SetTextColor(config.Color.TextSelected)
+		h.SetBackgroundColor(config.Color.BackgroundSelected)
+	} else if s == twidgets.Deselected {
+		h.SetTextColor(config.Color.TextSecondary)
+		h.SetBackgroundColor(config.Color.Background)
+	} else if s == twidgets.Blurred {
+		h.SetBackgroundColor(config.Color.TextDisabled)
+	}
+}
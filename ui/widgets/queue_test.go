@@ -0,0 +1,87 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_groupQueue(t *testing.T) {
+	songs := []*models.Song{{Id: "1", Duration: 10}, {Id: "2", Duration: 20}, {Id: "3", Duration: 30},
+		{Id: "4", Duration: 40}}
+	sources := []interfaces.QueueSource{{Group: 1, Name: "album"}, {Group: 1, Name: "album"},
+		{Group: 2, Name: "playlist"}, {Group: 1, Name: "album"}}
+
+	got := groupQueue(songs, sources)
+	want := []*queueGroup{
+		{group: 1, name: "album", start: 0, count: 2, duration: 30},
+		{group: 2, name: "playlist", start: 2, count: 1, duration: 30},
+		{group: 1, name: "album", start: 3, count: 1, duration: 40},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupQueue() = %v, want %v", got, want)
+	}
+
+	if got := groupQueue(songs, sources[:2]); got != nil {
+		t.Errorf("groupQueue() with outdated sources = %v, want nil", got)
+	}
+}
+
+func TestQueue_setRows(t *testing.T) {
+	q := NewQueue()
+	songs := []*models.Song{{Id: "1"}, {Id: "2"}, {Id: "3"}}
+	q.songs = make([]*albumSong, len(songs))
+	for i, v := range songs {
+		q.songs[i] = newAlbumSong(v, false, i+1)
+	}
+	groups := groupQueue(songs, []interfaces.QueueSource{{Group: 1}, {Group: 2}, {Group: 2}})
+
+	q.setRows(groups)
+	wantIndices := []int{-1, 0, -1, 1, 2}
+	for row, want := range wantIndices {
+		if got := q.songIndex(row); got != want {
+			t.Errorf("songIndex(%d) = %d, want %d", row, got, want)
+		}
+	}
+
+	q.collapsed[2] = true
+	q.setRows(groups)
+	if len(q.rows) != 3 || q.songIndex(2) != -1 {
+		t.Errorf("collapsed group is visible, got %d rows", len(q.rows))
+	}
+
+	q.setRows(groups[:1])
+	if q.rows != nil || q.songIndex(2) != 2 {
+		t.Errorf("single group should not have headers")
+	}
+}
+
+func Test_songsSource(t *testing.T) {
+	album := []*models.Song{{Id: "1", Album: "a", AlbumName: "album"}, {Id: "2", Album: "a", AlbumName: "album"}}
+	if got := songsSource(album); got != "Album: album" {
+		t.Errorf("songsSource() = %v, want Album: album", got)
+	}
+	mixed := append(album, &models.Song{Id: "3", Album: "b", AlbumName: "other"})
+	if got := songsSource(mixed); got != "" {
+		t.Errorf("songsSource() = %v, want empty", got)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/interfaces"
+)
+
+const (
+	// statusBatchInterval is the time to collect status updates before drawing the latest one.
+	statusBatchInterval = time.Millisecond * 100
+	// idleTimeout is the time without user input after which song progress is drawn less often.
+	idleTimeout = time.Minute
+	// idleRefreshInterval is the interval to draw song progress when idle.
+	idleRefreshInterval = time.Second * 5
+)
+
+// statusRefresher batches player status updates and draws status only when visible status changes.
+// When user has been idle, changes in song progress only are drawn less often.
+type statusRefresher struct {
+	lock      sync.Mutex
+	pending   *interfaces.AudioStatus
+	drawn     interfaces.AudioStatus
+	drawnAt   time.Time
+	lastInput time.Time
+
+	changed chan struct{}
+	stop    chan struct{}
+	draw    func(status interfaces.AudioStatus)
+}
+
+func newStatusRefresher(draw func(status interfaces.AudioStatus)) *statusRefresher {
+	return &statusRefresher{
+		lastInput: time.Now(),
+		changed:   make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		draw:      draw,
+	}
+}
+
+// update sets latest status to draw.
+func (s *statusRefresher) update(status interfaces.AudioStatus) {
+	s.lock.Lock()
+	s.pending = &status
+	s.lock.Unlock()
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// input marks user as active.
+func (s *statusRefresher) input() {
+	s.lock.Lock()
+	s.lastInput = time.Now()
+	s.lock.Unlock()
+}
+
+func (s *statusRefresher) run() {
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-s.changed:
+		}
+
+		timer := time.NewTimer(statusBatchInterval)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if status, ok := s.next(time.Now()); ok {
+			s.draw(status)
+		}
+	}
+}
+
+func (s *statusRefresher) close() {
+	close(s.stop)
+}
+
+// next returns pending status, if it needs to be drawn.
+func (s *statusRefresher) next(now time.Time) (interfaces.AudioStatus, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.pending == nil {
+		return interfaces.AudioStatus{}, false
+	}
+	status := *s.pending
+	current := visibleStatus(status)
+	drawn := visibleStatus(s.drawn)
+	if current == drawn && !s.drawnAt.IsZero() {
+		s.pending = nil
+		return status, false
+	}
+
+	current.SongPast = 0
+	drawn.SongPast = 0
+	idle := now.Sub(s.lastInput) > idleTimeout
+	if current == drawn && idle && now.Sub(s.drawnAt) < idleRefreshInterval {
+		// keep pending, it is drawn with later update
+		return status, false
+	}
+	s.pending = nil
+	s.drawn = status
+	s.drawnAt = now
+	return status, true
+}
+
+// visibleStatus returns status with only fields that are shown.
+// Song progress is shown in seconds.
+func visibleStatus(status interfaces.AudioStatus) interfaces.AudioStatus {
+	status.Action = 0
+	status.SongPast = interfaces.AudioTick(status.SongPast.Seconds() * 1000)
+	return status
+}
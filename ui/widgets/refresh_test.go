@@ -0,0 +1,77 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_statusRefresher_next(t *testing.T) {
+	s := newStatusRefresher(nil)
+	song := &models.Song{Id: "song-1", Duration: 300}
+	start := time.Now()
+
+	status := interfaces.AudioStatus{State: interfaces.AudioStatePlaying, Song: song, SongPast: 1000}
+	s.update(status)
+	if _, ok := s.next(start); !ok {
+		t.Fatalf("first status not drawn")
+	}
+
+	// same second and action only
+	status.SongPast = 1400
+	status.Action = interfaces.AudioActionTimeUpdate
+	s.update(status)
+	if _, ok := s.next(start); ok {
+		t.Errorf("status drawn without visible changes")
+	}
+
+	status.SongPast = 2000
+	s.update(status)
+	if _, ok := s.next(start.Add(time.Second)); !ok {
+		t.Errorf("progress not drawn")
+	}
+
+	// idle, progress is drawn less often
+	idle := start.Add(idleTimeout + time.Second)
+	status.SongPast = 3000
+	s.update(status)
+	if _, ok := s.next(idle); !ok {
+		t.Errorf("progress not drawn after idle interval")
+	}
+	status.SongPast = 4000
+	s.update(status)
+	if _, ok := s.next(idle.Add(time.Second)); ok {
+		t.Errorf("progress drawn before idle interval")
+	}
+	status.Paused = true
+	s.update(status)
+	if got, ok := s.next(idle.Add(time.Second * 2)); !ok || !got.Paused {
+		t.Errorf("pause not drawn when idle")
+	}
+
+	s.input()
+	status.SongPast = 5000
+	s.update(status)
+	if _, ok := s.next(time.Now()); !ok {
+		t.Errorf("progress not drawn after input")
+	}
+}
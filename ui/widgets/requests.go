@@ -0,0 +1,90 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"sync"
+)
+
+// viewRequests loads content of views in background. Only the latest request is current:
+// starting a new one cancels previous request, so that a slow response never replaces the view
+// user has navigated to after it.
+type viewRequests struct {
+	lock   sync.Mutex
+	cancel context.CancelFunc
+	// queueDraw runs function in ui thread.
+	queueDraw func(f func())
+	// onError is called with errors of current request.
+	onError func(action string, err error)
+}
+
+func newViewRequests(queueDraw func(f func()), onError func(action string, err error)) *viewRequests {
+	return &viewRequests{queueDraw: queueDraw, onError: onError}
+}
+
+// start cancels running request and returns context for new request.
+func (v *viewRequests) start() context.Context {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.cancel != nil {
+		v.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	v.cancel = cancel
+	return ctx
+}
+
+// stop cancels running request, e.g. when view changes.
+func (v *viewRequests) stop() {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if v.cancel != nil {
+		v.cancel()
+		v.cancel = nil
+	}
+}
+
+// load runs fetch in background as new request. Unless request is cancelled meanwhile,
+// show returned by fetch is called in ui thread, or error is reported with action.
+// Show may be nil, if there is nothing to show.
+func (v *viewRequests) load(action string, fetch func(ctx context.Context) (show func(), err error)) {
+	ctx := v.start()
+	go func() {
+		show, err := fetch(ctx)
+		if ctx.Err() != nil {
+			logrus.Debugf("%s: request cancelled", action)
+			return
+		}
+		if err != nil {
+			v.onError(action, err)
+			return
+		}
+		if show == nil {
+			return
+		}
+		v.queueDraw(func() {
+			// view may have changed after response
+			if ctx.Err() == nil {
+				show()
+			}
+		})
+	}()
+}
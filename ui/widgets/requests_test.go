@@ -0,0 +1,95 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestViewRequests_Load(t *testing.T) {
+	draws := make(chan func(), 4)
+	errs := make(chan error, 4)
+	requests := newViewRequests(func(f func()) { draws <- f }, func(action string, err error) { errs <- err })
+
+	release := make(chan struct{})
+	stale := make(chan context.Context, 1)
+	requests.load("stale", func(ctx context.Context) (func(), error) {
+		stale <- ctx
+		<-release
+		return func() { t.Error("stale request shown") }, nil
+	})
+	staleCtx := <-stale
+
+	shown := false
+	requests.load("current", func(ctx context.Context) (func(), error) {
+		return func() { shown = true }, nil
+	})
+	if staleCtx.Err() != context.Canceled {
+		t.Errorf("stale request not cancelled: %v", staleCtx.Err())
+	}
+	close(release)
+
+	select {
+	case f := <-draws:
+		f()
+	case <-time.After(time.Second):
+		t.Fatal("current request not shown")
+	}
+	if !shown {
+		t.Error("current request not shown")
+	}
+
+	select {
+	case f := <-draws:
+		f()
+		t.Error("stale request queued")
+	case err := <-errs:
+		t.Errorf("stale request error reported: %v", err)
+	case <-time.After(time.Millisecond * 50):
+	}
+}
+
+func TestViewRequests_Stop(t *testing.T) {
+	draws := make(chan func(), 1)
+	errs := make(chan error, 1)
+	requests := newViewRequests(func(f func()) { draws <- f }, func(action string, err error) { errs <- err })
+
+	requests.load("fail", func(ctx context.Context) (func(), error) {
+		return nil, errors.New("server error")
+	})
+	select {
+	case err := <-errs:
+		if err.Error() != "server error" {
+			t.Errorf("error = %v, want server error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("error not reported")
+	}
+
+	requests.load("view", func(ctx context.Context) (func(), error) {
+		return func() { t.Error("request shown after view changed") }, nil
+	})
+	// view changes before response is shown in ui thread
+	f := <-draws
+	requests.stop()
+	f()
+}
@@ -22,8 +22,10 @@ import (
 	"fmt"
 	"github.com/gdamore/tcell"
 	"gitlab.com/tslocum/cview"
+	"strings"
 	"sync"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/twidgets"
 )
@@ -72,10 +74,9 @@ func (s *searchListItem) Draw(screen tcell.Screen) {
 
 	var text string
 
-	if h <= 1 {
-		text = fmt.Sprintf("[yellow]%ss[-] (%d)\n", s.name, len(s.items))
-	} else {
-		text = fmt.Sprintf("[yellow]%ss[-]\n", s.name)
+	// number is shortcut for selecting item
+	text = fmt.Sprintf("[yellow]%d. %ss[-] (%d)\n", s.index+1, s.name, len(s.items))
+	if h > 1 {
 		for i, v := range s.items {
 			if i > 0 {
 				text += "\n"
@@ -125,7 +126,7 @@ func newSearchBox(label string, searchFunc func(string)) *searchBox {
 	s.InputField.SetFieldBackgroundColor(colors.Background)
 	s.InputField.SetPlaceholderTextColor(colors.TextDisabled)
 
-	s.InputField.SetPlaceholder("John Cage")
+	s.InputField.SetPlaceholder(i18n.T("John Cage, album:nevermind, year:1990..2000"))
 	s.InputField.SetLabel(label)
 	s.InputField.SetDoneFunc(s.done)
 	s.InputField.SetInputCapture(s.inputCapture)
@@ -205,14 +206,15 @@ func NewSearchTopList(searchFunc func(string), selectMediaFunc func(itemType mod
 		previous:      &previous{},
 		listFocused:   false,
 		selectFunc:    nil,
-		prevBtn:       newButton("Back"),
+		prevBtn:       newButton(i18n.T("Back")),
 		prevFunc:      nil,
 		results:       []*searchListItem{},
 		showMediafunc: selectMediaFunc,
 	}
 
-	stp.searchInput = newSearchBox("Search: ", searchFunc)
+	stp.searchInput = newSearchBox(i18n.T("Search: "), searchFunc)
 	stp.list = twidgets.NewScrollList(stp.selectItem)
+	stp.list.SetInputCapture(stp.listHandler)
 
 	stp.SetBorder(true)
 	stp.SetBorderColor(config.Color.Border)
@@ -271,6 +273,19 @@ func (s *SearchTopList) selectItem(index int) {
 	s.showMediafunc(itemType, items, s.searchInput.GetText())
 }
 
+// select result category with number keys
+func (s *SearchTopList) listHandler(event *tcell.EventKey) *tcell.EventKey {
+	r := event.Rune()
+	if r >= '1' && r <= '9' {
+		index := int(r - '1')
+		if index < len(s.results) {
+			s.selectItem(index)
+			return nil
+		}
+	}
+	return event
+}
+
 func (s *SearchTopList) ClearResults() {
 	if len(s.results) > 0 {
 		s.results = []*searchListItem{}
@@ -328,3 +343,90 @@ func (s *SearchTopList) ResultsReady() {
 	itemHeight := limit(avgSizeHint, minHeight, maxHeight)
 	s.list.ItemHeight = itemHeight
 }
+
+// searchKeys are query keys that limit search to given item type.
+var searchKeys = map[string]models.ItemType{
+	"artist":   models.TypeArtist,
+	"album":    models.TypeAlbum,
+	"song":     models.TypeSong,
+	"playlist": models.TypePlaylist,
+	"genre":    models.TypeGenre,
+}
+
+// searchQuery is parsed search input. Input is plain text, optionally with filters in form 'key:value':
+// 'artist:', 'album:', 'song:', 'playlist:' and 'genre:' limit search to given item types, and
+// 'year:' limits albums to given year, decade or range, e.g. 'year:1990s' or 'year:1990..2000'.
+// If year is given without item types, only albums are searched.
+type searchQuery struct {
+	text  string
+	types []models.ItemType
+	years [2]int
+}
+
+func parseSearchQuery(input string) (searchQuery, error) {
+	query := searchQuery{}
+	words := make([]string, 0, 2)
+	for _, token := range strings.Fields(input) {
+		i := strings.Index(token, ":")
+		if i <= 0 {
+			words = append(words, token)
+			continue
+		}
+		key := strings.ToLower(token[:i])
+		value := token[i+1:]
+		if key == "year" {
+			years, err := parseYearRange(strings.Replace(value, "..", "-", 1))
+			if err != nil {
+				return query, fmt.Errorf("invalid year '%s': %v", value, err)
+			}
+			query.years = years
+			continue
+		}
+
+		itemType, ok := searchKeys[key]
+		if !ok {
+			words = append(words, token)
+			continue
+		}
+		if !query.hasType(itemType) {
+			query.types = append(query.types, itemType)
+		}
+		if value != "" {
+			words = append(words, value)
+		}
+	}
+	query.text = strings.Join(words, " ")
+	if query.hasYears() && len(query.types) == 0 {
+		query.types = []models.ItemType{models.TypeAlbum}
+	}
+	return query, nil
+}
+
+func (q *searchQuery) hasType(itemType models.ItemType) bool {
+	for _, v := range q.types {
+		if v == itemType {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *searchQuery) hasYears() bool {
+	return q.years != [2]int{}
+}
+
+// filterYears removes albums that are not within year range. Other items are not filtered.
+func (q *searchQuery) filterYears(items []models.Item) []models.Item {
+	if !q.hasYears() {
+		return items
+	}
+	filtered := make([]models.Item, 0, len(items))
+	for _, v := range items {
+		album, ok := v.(*models.Album)
+		if ok && (album.Year < q.years[0] || album.Year > q.years[1]) {
+			continue
+		}
+		filtered = append(filtered, v)
+	}
+	return filtered
+}
@@ -0,0 +1,85 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"reflect"
+	"testing"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_parseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    searchQuery
+		wantErr bool
+	}{
+		{
+			name:  "plain text",
+			input: "john cage",
+			want:  searchQuery{text: "john cage"},
+		},
+		{
+			name:  "item type",
+			input: "artist:nirvana",
+			want:  searchQuery{text: "nirvana", types: []models.ItemType{models.TypeArtist}},
+		},
+		{
+			name:  "multiple words and types",
+			input: "Album:in utero song:",
+			want:  searchQuery{text: "in utero", types: []models.ItemType{models.TypeAlbum, models.TypeSong}},
+		},
+		{
+			name:  "year range",
+			input: "year:1990..2000 nevermind",
+			want:  searchQuery{text: "nevermind", types: []models.ItemType{models.TypeAlbum}, years: [2]int{1990, 2000}},
+		},
+		{
+			name:  "decade",
+			input: "year:1990s",
+			want:  searchQuery{text: "", types: []models.ItemType{models.TypeAlbum}, years: [2]int{1990, 1999}},
+		},
+		{
+			name:  "unknown key",
+			input: "foo:bar",
+			want:  searchQuery{text: "foo:bar"},
+		},
+		{
+			name:    "invalid year",
+			input:   "year:abc",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSearchQuery(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSearchQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSearchQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
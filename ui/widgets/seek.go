@@ -0,0 +1,94 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"sync"
+	"time"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+)
+
+// seekBatchInterval is the time to collect seek key presses after a seek before seeking again.
+const seekBatchInterval = time.Millisecond * 100
+
+// seekAccelerator grows seek step while seek key is held down. Terminals don't report key releases,
+// so key is considered held as long as presses repeat within config.Gui.SeekRepeatMs.
+type seekAccelerator struct {
+	forward   bool
+	heldSince time.Time
+	lastPress time.Time
+}
+
+// step returns seek step in seconds for seek key pressed at now.
+func (s *seekAccelerator) step(forward bool, now time.Time, gui *config.Gui) int {
+	repeat := time.Duration(gui.SeekRepeatMs) * time.Millisecond
+	if repeat <= 0 || forward != s.forward || s.lastPress.IsZero() || now.Sub(s.lastPress) > repeat {
+		s.heldSince = now
+	}
+	s.forward = forward
+	s.lastPress = now
+	return gui.SeekStep(now.Sub(s.heldSince))
+}
+
+// seeker seeks in background. Seeks requested while previous seek is in progress are combined,
+// so that holding seek key down does not queue seeks nor block user interface.
+type seeker struct {
+	lock    sync.Mutex
+	pending interfaces.AudioTick
+	running bool
+	seek    func(ticks interfaces.AudioTick)
+	// interval is minimum time between seeks.
+	interval time.Duration
+}
+
+func newSeeker(seek func(ticks interfaces.AudioTick)) *seeker {
+	return &seeker{
+		seek:     seek,
+		interval: seekBatchInterval,
+	}
+}
+
+// add seeks by ticks, relative to current position.
+func (s *seeker) add(ticks interfaces.AudioTick) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.pending += ticks
+	if s.running {
+		return
+	}
+	s.running = true
+	go s.run()
+}
+
+func (s *seeker) run() {
+	for {
+		s.lock.Lock()
+		ticks := s.pending
+		s.pending = 0
+		if ticks == 0 {
+			s.running = false
+			s.lock.Unlock()
+			return
+		}
+		s.lock.Unlock()
+		s.seek(ticks)
+		time.Sleep(s.interval)
+	}
+}
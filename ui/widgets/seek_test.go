@@ -0,0 +1,95 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/interfaces"
+)
+
+func TestSeekAccelerator_step(t *testing.T) {
+	gui := &config.Gui{SeekStepS: 5, SeekRepeatMs: 500, SeekAccelAfterMs: []int{1000, 3000},
+		SeekAccelStepsS: []int{30, 120}}
+	start := time.Now()
+	s := &seekAccelerator{}
+	// hold presses key every interval from fromMs to toMs and returns last step
+	hold := func(forward bool, fromMs, toMs, intervalMs int) int {
+		step := 0
+		for ms := fromMs; ms <= toMs; ms += intervalMs {
+			step = s.step(forward, start.Add(time.Duration(ms)*time.Millisecond), gui)
+		}
+		return step
+	}
+
+	tests := []struct {
+		name       string
+		forward    bool
+		fromMs     int
+		toMs       int
+		intervalMs int
+		want       int
+	}{
+		{name: "first press", forward: true, fromMs: 0, toMs: 0, intervalMs: 1, want: 5},
+		{name: "held", forward: true, fromMs: 30, toMs: 900, intervalMs: 30, want: 5},
+		{name: "held 1s", forward: true, fromMs: 930, toMs: 1020, intervalMs: 30, want: 30},
+		{name: "held 3s", forward: true, fromMs: 1050, toMs: 3000, intervalMs: 30, want: 120},
+		{name: "other direction", forward: false, fromMs: 3030, toMs: 3030, intervalMs: 1, want: 5},
+		{name: "released", forward: false, fromMs: 4000, toMs: 4000, intervalMs: 1, want: 5},
+		{name: "quick presses", forward: false, fromMs: 4400, toMs: 5200, intervalMs: 400, want: 30},
+		{name: "slow presses", forward: false, fromMs: 5800, toMs: 8800, intervalMs: 600, want: 5},
+	}
+	for _, tt := range tests {
+		if got := hold(tt.forward, tt.fromMs, tt.toMs, tt.intervalMs); got != tt.want {
+			t.Errorf("%s: step = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	// acceleration disabled
+	gui.SeekRepeatMs = -1
+	s = &seekAccelerator{}
+	if got := hold(true, 0, 5000, 30); got != 5 {
+		t.Errorf("disabled acceleration: step = %d, want 5", got)
+	}
+}
+
+func TestSeeker(t *testing.T) {
+	seeks := make(chan interfaces.AudioTick)
+	release := make(chan bool)
+	s := newSeeker(func(ticks interfaces.AudioTick) {
+		seeks <- ticks
+		<-release
+	})
+	s.interval = 0
+
+	s.add(5000)
+	if got := <-seeks; got != 5000 {
+		t.Errorf("first seek: %d, want 5000", got)
+	}
+	// seeks while seeking are combined
+	s.add(5000)
+	s.add(30000)
+	s.add(-5000)
+	release <- true
+	if got := <-seeks; got != 30000 {
+		t.Errorf("combined seek: %d, want 30000", got)
+	}
+	release <- true
+}
@@ -0,0 +1,238 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package widgets
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+)
+
+// bitrateOptions are selectable streaming bitrate limits in kbps, 0 means original quality.
+var bitrateOptions = []int{0, 320, 256, 192, 128, 96, 64}
+
+// settings provides a modal for changing common options while running.
+type settings struct {
+	*cview.Form
+	// applyFunc is called after settings have been changed. If save, settings should be
+	// written to config file. Theme is only applied after restart.
+	applyFunc func(save, themeChanged bool)
+	errorFunc func(action string, err error)
+
+	visible bool
+	closeCb func()
+
+	volumeStep *cview.InputField
+	pageSize   *cview.InputField
+	seekStep   *cview.InputField
+	mouse      *cview.Checkbox
+	theme      *cview.DropDown
+	bitrate    *cview.DropDown
+	bitrates   []int
+}
+
+func newSettings(applyFunc func(save, themeChanged bool), errorFunc func(action string, err error)) *settings {
+	s := &settings{
+		Form:       cview.NewForm(),
+		applyFunc:  applyFunc,
+		errorFunc:  errorFunc,
+		volumeStep: cview.NewInputField(),
+		pageSize:   cview.NewInputField(),
+		seekStep:   cview.NewInputField(),
+		mouse:      cview.NewCheckbox(),
+		theme:      cview.NewDropDown(),
+		bitrate:    cview.NewDropDown(),
+	}
+
+	s.SetTitle(" Settings ")
+	s.SetBackgroundColor(config.Color.Modal.Background)
+	s.SetBorder(true)
+
+	s.volumeStep.SetLabel(i18n.T("Volume step (%)"))
+	s.pageSize.SetLabel(i18n.T("Page size"))
+	s.seekStep.SetLabel(i18n.T("Seek step (s)"))
+	for _, v := range []*cview.InputField{s.volumeStep, s.pageSize, s.seekStep} {
+		v.SetFieldWidth(6)
+		v.SetAcceptanceFunc(acceptDigits)
+		v.SetFieldTextColor(config.Color.Text)
+		v.SetInputCapture(s.inputCapture)
+		s.AddFormItem(v)
+	}
+
+	s.mouse.SetLabel(i18n.T("Mouse"))
+	s.mouse.SetInputCapture(s.inputCapture)
+	s.AddFormItem(s.mouse)
+
+	s.theme.SetLabel(i18n.T("Theme (after restart)"))
+	s.theme.SetFieldTextColor(config.Color.Text)
+	s.theme.SetInputCapture(s.inputCapture)
+	s.AddFormItem(s.theme)
+
+	s.bitrate.SetLabel(i18n.T("Max bitrate"))
+	s.bitrate.SetFieldTextColor(config.Color.Text)
+	s.bitrate.SetInputCapture(s.inputCapture)
+	s.AddFormItem(s.bitrate)
+
+	s.AddButton(i18n.T("Apply"), func() { s.apply(false) })
+	s.AddButton(i18n.T("Save"), func() { s.apply(true) })
+	s.AddButton(i18n.T("Cancel"), s.cancel)
+
+	for i := 0; i < s.GetButtonCount(); i++ {
+		s.GetButton(i).SetInputCapture(s.inputCapture)
+	}
+	s.SetCancelFunc(s.cancel)
+	return s
+}
+
+func (s *settings) SetDoneFunc(doneFunc func()) {
+	s.closeCb = doneFunc
+}
+
+func (s *settings) View() cview.Primitive {
+	return s
+}
+
+func (s *settings) SetVisible(visible bool) {
+	s.visible = visible
+	if visible {
+		s.loadSettings()
+	}
+}
+
+// loadSettings fills form with current configuration.
+func (s *settings) loadSettings() {
+	gui := config.AppConfig.Gui
+	s.volumeStep.SetText(strconv.Itoa(gui.VolumeStepSize()))
+	s.pageSize.SetText(strconv.Itoa(gui.PageSize))
+	s.seekStep.SetText(strconv.Itoa(gui.SeekStepS))
+	s.mouse.SetChecked(gui.MouseEnabled)
+
+	s.theme.SetOptions(nil, nil)
+	for i, v := range config.Themes {
+		s.theme.AddOption(v, nil)
+		if v == gui.Theme {
+			s.theme.SetCurrentOption(i)
+		}
+	}
+
+	current := config.AppConfig.Player.MaxBitrateKbps
+	s.bitrates = bitrateOptions
+	if !containsInt(s.bitrates, current) {
+		s.bitrates = append(append([]int{}, bitrateOptions...), current)
+	}
+	s.bitrate.SetOptions(nil, nil)
+	for i, v := range s.bitrates {
+		s.bitrate.AddOption(bitrateName(v), nil)
+		if v == current {
+			s.bitrate.SetCurrentOption(i)
+		}
+	}
+}
+
+// apply validates and applies settings, and closes modal on success.
+func (s *settings) apply(save bool) {
+	volumeStep, err := parseSetting("volume step", s.volumeStep.GetText(), 1, 50)
+	if err != nil {
+		s.errorFunc("apply settings", err)
+		return
+	}
+	pageSize, err := parseSetting("page size", s.pageSize.GetText(), 1, 500)
+	if err != nil {
+		s.errorFunc("apply settings", err)
+		return
+	}
+	seekStep, err := parseSetting("seek step", s.seekStep.GetText(), 1, 600)
+	if err != nil {
+		s.errorFunc("apply settings", err)
+		return
+	}
+
+	gui := &config.AppConfig.Gui
+	gui.VolumeStep = volumeStep
+	gui.PageSize = pageSize
+	gui.SeekStepS = seekStep
+	gui.MouseEnabled = s.mouse.IsChecked()
+	themeChanged := false
+	if _, theme := s.theme.GetCurrentOption(); theme != "" && theme != gui.Theme {
+		gui.Theme = theme
+		themeChanged = true
+	}
+	if index, _ := s.bitrate.GetCurrentOption(); index >= 0 && index < len(s.bitrates) {
+		config.AppConfig.Player.MaxBitrateKbps = s.bitrates[index]
+	}
+	config.PageSize = pageSize
+	config.VolumeStepSize = volumeStep
+
+	// close before applying so that mouse is restored according to new setting.
+	s.cancel()
+	s.applyFunc(save, themeChanged)
+}
+
+func (s *settings) cancel() {
+	if s.closeCb != nil {
+		s.closeCb()
+	}
+}
+
+func (s *settings) inputCapture(e *tcell.EventKey) *tcell.EventKey {
+	switch e.Key() {
+	case tcell.KeyUp:
+		return tcell.NewEventKey(tcell.KeyBacktab, e.Rune(), e.Modifiers())
+	case tcell.KeyDown:
+		return tcell.NewEventKey(tcell.KeyTab, e.Rune(), e.Modifiers())
+	}
+	return e
+}
+
+// parseSetting parses integer value and ensures it is in range [min, max].
+func parseSetting(name, value string, min, max int) (int, error) {
+	number, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", name)
+	}
+	if number < min || number > max {
+		return 0, fmt.Errorf("%s must be between %d and %d", name, min, max)
+	}
+	return number, nil
+}
+
+func acceptDigits(textToCheck string, lastChar rune) bool {
+	return unicode.IsDigit(lastChar) && len(textToCheck) <= 4
+}
+
+func bitrateName(kbps int) string {
+	if kbps == 0 {
+		return i18n.T("Original")
+	}
+	return fmt.Sprintf("%d kbps", kbps)
+}
+
+func containsInt(list []int, value int) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
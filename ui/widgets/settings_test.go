@@ -0,0 +1,50 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package widgets
+
+import "testing"
+
+func Test_parseSetting(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "valid", value: "20", want: 20},
+		{name: "whitespace", value: " 5 ", want: 5},
+		{name: "min", value: "2", want: 2},
+		{name: "max", value: "50", want: 50},
+		{name: "too small", value: "1", wantErr: true},
+		{name: "too large", value: "51", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+		{name: "not a number", value: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSetting("volume steps", tt.value, 2, 50)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSetting() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseSetting() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -21,8 +21,12 @@ package widgets
 import (
 	"fmt"
 	"strings"
+	"time"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
+	"tryffel.net/go/jellycli/util"
 	"tryffel.net/go/twidgets"
 )
 
@@ -40,6 +44,18 @@ type SongList struct {
 	playBtn *button
 	context contextOperator
 	page    interfaces.Paging
+	// offset is number of songs before first song in list.
+	offset int
+
+	sort        *sort
+	sortEnabled bool
+	queryOpts   *interfaces.QueryOpts
+
+	// episodes shows songs as podcast episodes with publish date and played status.
+	episodes      bool
+	setPlayedFunc func(song *models.Song, played bool) error
+	// recent shows how long ago songs were played.
+	recent bool
 }
 
 // NewSongList initializes new song list
@@ -49,11 +65,13 @@ func NewSongList(playSong func(song *models.Song), playSongs func(songs []*model
 		playSongFunc:  playSong,
 		playSongsFunc: playSongs,
 		context:       operator,
-		playBtn:       newButton("Play all"),
+		playBtn:       newButton(i18n.T("Play all")),
+		queryOpts:     interfaces.DefaultQueryOpts(),
 	}
 
 	p.itemList = newItemList(p.selectSong)
 	p.paging = NewPageSelector(p.selectPage)
+	p.loadMoreFunc = p.loadMore
 
 	p.list.ItemHeight = 2
 	p.list.Padding = 1
@@ -70,27 +88,80 @@ func NewSongList(playSong func(song *models.Song), playSongs func(songs []*model
 	p.Banner.Grid.AddItem(p.paging, 3, 4, 1, 3, 1, 10, true)
 	p.Banner.Grid.AddItem(p.list, 4, 0, 2, 8, 4, 10, false)
 
-	selectables := []twidgets.Selectable{p.prevBtn, p.playBtn, p.paging.Previous, p.paging.Next, p.list}
+	if config.AppConfig.Gui.EnableSorting {
+		if config.AppConfig.Player.EnableLocalCache {
+			// local cache has no play counts, ratings or date added
+			p.sort = newSort(p.setSorting,
+				interfaces.SortByName,
+				interfaces.SortByAlbum,
+				interfaces.SortByArtist,
+				interfaces.SortByDate,
+				interfaces.SortByRandom,
+			)
+		} else {
+			p.sort = newSort(p.setSorting,
+				interfaces.SortByName,
+				interfaces.SortByAlbum,
+				interfaces.SortByArtist,
+				interfaces.SortByDate,
+				interfaces.SortByLatest,
+				interfaces.SortByPlayCount,
+				interfaces.SortByLastPlayed,
+				interfaces.SortByRandom,
+				interfaces.SortByRating,
+			)
+		}
+	}
+
+	selectables := append([]twidgets.Selectable{p.prevBtn, p.playBtn}, p.paging.Selectables()...)
+	selectables = append(selectables, p.list)
 	p.Banner.Selectable = selectables
-	p.title = "All songs"
+	p.title = i18n.T("All songs")
 
+	p.list.AddContextItem(i18n.T("Play all from here"), 0, func(index int) {
+		p.playFromSelected()
+	})
 	if p.context != nil {
-		p.list.AddContextItem("View album", 0, func(index int) {
+		p.list.AddContextItem(i18n.T("View album"), 0, func(index int) {
 			selected := p.getSelectedIndex()
 			song := p.songs[selected]
 			p.context.ViewSongAlbum(song.song)
 		})
-		p.list.AddContextItem("View artist", 0, func(index int) {
+		p.list.AddContextItem(i18n.T("View artist"), 0, func(index int) {
 			selected := p.getSelectedIndex()
 			song := p.songs[selected]
 			p.context.ViewSongArtist(song.song)
 		})
-		p.list.AddContextItem("Instant mix", 0, func(index int) {
+		p.list.AddContextItem(i18n.T("Instant mix"), 0, func(index int) {
 			selected := p.getSelectedIndex()
 			song := p.songs[selected]
 			p.context.InstantMix(song.song)
 		})
-
+		p.list.AddContextItem(i18n.T("Download"), 0, func(index int) {
+			selected := p.getSelectedIndex()
+			song := p.songs[selected]
+			p.context.Download(song.song)
+		})
+		p.list.AddContextItem(i18n.T("Open in browser"), 0, func(index int) {
+			selected := p.getSelectedIndex()
+			song := p.songs[selected]
+			p.context.OpenInBrowser(song.song)
+		})
+		p.list.AddContextItem(i18n.T("Copy link"), 0, func(index int) {
+			selected := p.getSelectedIndex()
+			song := p.songs[selected]
+			p.context.CopyLink(song.song)
+		})
+		p.list.AddContextItem(i18n.T("Copy info"), 0, func(index int) {
+			selected := p.getSelectedIndex()
+			song := p.songs[selected]
+			p.context.CopyInfo(song.song)
+		})
+		p.list.AddContextItem(i18n.T("Volume offset"), 0, func(index int) {
+			selected := p.getSelectedIndex()
+			song := p.songs[selected]
+			p.context.AdjustGain(song.song)
+		})
 	}
 
 	p.reduceEnabled = true
@@ -100,27 +171,101 @@ func NewSongList(playSong func(song *models.Song), playSongs func(songs []*model
 	return p
 }
 
+// newEpisodeList initializes song list for podcast episodes. SetPlayed marks episode played or not played.
+func newEpisodeList(playSong func(song *models.Song), playSongs func(songs []*models.Song),
+	setPlayed func(song *models.Song, played bool) error) *SongList {
+	s := NewSongList(playSong, playSongs, nil)
+	s.episodes = true
+	s.setPlayedFunc = setPlayed
+	s.title = i18n.T("Episodes")
+
+	s.list.AddContextItem(i18n.T("Mark played"), 0, func(index int) {
+		s.setPlayed(true)
+	})
+	s.list.AddContextItem(i18n.T("Mark not played"), 0, func(index int) {
+		s.setPlayed(false)
+	})
+	return s
+}
+
+func (s *SongList) setPlayed(played bool) {
+	selected := s.getSelectedIndex()
+	if s.setPlayedFunc == nil || selected < 0 || selected >= len(s.songs) {
+		return
+	}
+	song := s.songs[selected]
+	if s.setPlayedFunc(song.song, played) != nil {
+		return
+	}
+	song.song.Played = played
+	if played {
+		song.song.Position = 0
+	}
+	song.setText()
+}
+
 func (s *SongList) setTitle(title string) {
 	s.title = title
 }
 
+// EnableSorting shows sorting. Sorting is only available if it is enabled in config.
+func (s *SongList) EnableSorting(enabled bool) {
+	if s.sort == nil || s.sortEnabled == enabled {
+		return
+	}
+	s.sortEnabled = enabled
+	if enabled {
+		s.Banner.Grid.AddItem(s.sort, 3, 7, 1, 1, 1, 10, false)
+	} else {
+		s.Banner.Grid.RemoveItem(s.sort)
+	}
+	selectables := append([]twidgets.Selectable{s.prevBtn, s.playBtn}, s.paging.Selectables()...)
+	if enabled {
+		selectables = append(selectables, s.sort)
+	}
+	s.Banner.Selectable = append(selectables, s.list)
+}
+
+// persistSorting restores sorting from setting and saves changes to it.
+func (s *SongList) persistSorting(setting *string) {
+	if s.sort != nil {
+		s.queryOpts.Sort = s.sort.persist(setting)
+	}
+}
+
+func (s *SongList) setSorting(sort interfaces.Sort) {
+	s.queryOpts.Sort = sort
+	s.selectPage(0)
+}
+
 func (s *SongList) SetSongs(songs []*models.Song, page interfaces.Paging) {
+	s.setSongs(songs, page, page.CurrentPage*page.PageSize)
+}
+
+func (s *SongList) setSongs(songs []*models.Song, page interfaces.Paging, offset int) {
 	s.list.Clear()
 	s.resetReduce()
 	s.page = page
+	s.offset = offset
 	s.songs = make([]*albumSong, len(songs))
 	items := make([]twidgets.ListItem, len(songs))
 	itemTexts := make([]string, len(songs))
 
-	text := fmt.Sprintf("%s: %d songs", s.title, page.TotalItems)
+	unit := "songs"
+	if s.episodes {
+		unit = "episodes"
+	}
+	text := fmt.Sprintf("%s: %d %s", s.title, page.TotalItems, unit)
 
 	s.description.SetText(text)
 
-	offset := page.CurrentPage * page.PageSize
-
 	for i, v := range songs {
 		s.songs[i] = newAlbumSong(v, false, offset+i+1)
-		s.songs[i].updateTextFunc = s.updateSongText
+		if s.episodes {
+			s.songs[i].updateTextFunc = s.updateEpisodeText
+		} else {
+			s.songs[i].updateTextFunc = s.updateSongText
+		}
 		items[i] = s.songs[i]
 
 		itemText := songs[i].Name
@@ -150,20 +295,42 @@ func (s *SongList) selectSong(index int) {
 
 func (s *SongList) playAll() {
 	if s.playSongsFunc != nil {
-		songs := make([]*models.Song, len(s.songs))
-		for i, v := range s.songs {
-			songs[i] = v.song
-		}
-		s.playSongsFunc(songs)
+		s.playSongsFunc(songsFrom(s.songs, 0))
+	}
+}
+
+// playFromSelected plays highlighted song and songs after it.
+func (s *SongList) playFromSelected() {
+	if s.playSongsFunc != nil && len(s.songs) > 0 {
+		s.playSongsFunc(songsFrom(s.songs, s.getSelectedIndex()))
+	}
+}
+
+// songsFrom returns songs starting from index.
+func songsFrom(list []*albumSong, index int) []*models.Song {
+	if index < 0 {
+		index = 0
 	}
+	if index > len(list) {
+		index = len(list)
+	}
+	songs := make([]*models.Song, len(list)-index)
+	for i, v := range list[index:] {
+		songs[i] = v.song
+	}
+	return songs
 }
 
 func (s *SongList) updateSongText(song *albumSong) {
+	if song.setColumnText(config.SongColumnViewSongs) {
+		return
+	}
 	var name string
 	if song.showDiscNum {
-		name = fmt.Sprintf("%d %d. %s", song.song.DiscNumber, song.song.Index, song.song.Name)
+		name = fmt.Sprintf("%d %d. %s%s", song.song.DiscNumber, song.song.Index, sourcesText(song.song.Sources),
+			song.song.Name)
 	} else {
-		name = fmt.Sprintf("%d. %s", song.index, song.song.Name)
+		name = fmt.Sprintf("%d. %s%s", song.index, sourcesText(song.song.Sources), song.song.Name)
 	}
 
 	text := song.getAlignedDuration(name)
@@ -171,9 +338,64 @@ func (s *SongList) updateSongText(song *albumSong) {
 		text += "\n     " + song.song.Artists[0].Name
 
 	}
+	info := songComposers(song.song)
+	if s.recent && !song.song.LastPlayed.IsZero() {
+		if info != "" {
+			info += "  "
+		}
+		info += playedAgo(song.song.LastPlayed, time.Now())
+	}
+	if info != "" {
+		if len(song.song.Artists) > 0 {
+			text += "  " + info
+		} else {
+			text += "\n     " + info
+		}
+	}
 	song.SetText(text)
 }
 
+func (s *SongList) updateEpisodeText(song *albumSong) {
+	name := fmt.Sprintf("%d. %s", song.index, song.song.Name)
+	text := song.getAlignedDuration(name)
+	if status := episodeStatus(song.song); status != "" {
+		text += "\n     " + status
+	}
+	song.SetText(text)
+}
+
+// playedAgo returns approximate time since song was played, e.g. '2 h ago'.
+// Songs played over a week ago show date instead.
+func playedAgo(played, now time.Time) string {
+	ago := now.Sub(played)
+	switch {
+	case ago < time.Minute:
+		return i18n.T("just now")
+	case ago < time.Hour:
+		return i18n.Tf("%d min ago", int(ago/time.Minute))
+	case ago < time.Hour*24:
+		return i18n.Tf("%d h ago", int(ago/time.Hour))
+	case ago < time.Hour*24*7:
+		return i18n.Tf("%d d ago", int(ago/(time.Hour*24)))
+	default:
+		return played.Local().Format(lastPlayedFormat)
+	}
+}
+
+// episodeStatus returns publish date and played status of podcast episode.
+func episodeStatus(song *models.Song) string {
+	parts := make([]string, 0, 2)
+	if !song.Published.IsZero() {
+		parts = append(parts, song.Published.Format("2006-01-02"))
+	}
+	if song.Played {
+		parts = append(parts, "played")
+	} else if song.Position > 0 {
+		parts = append(parts, "resume at "+util.SecToString(song.Position/1000))
+	}
+	return strings.Join(parts, "  ")
+}
+
 func (s *SongList) selectPage(n int) {
 	s.paging.SetPage(n)
 
@@ -184,6 +406,31 @@ func (s *SongList) selectPage(n int) {
 	s.resetReduce()
 }
 
+// loadMore appends next page to songs, if there is one.
+func (s *SongList) loadMore() {
+	if s.showPage == nil || s.page.CurrentPage >= s.page.TotalPages-1 {
+		return
+	}
+	loaded := make([]*models.Song, len(s.songs))
+	for i, v := range s.songs {
+		loaded[i] = v.song
+	}
+	offset := s.offset
+	page := s.page
+	selected := s.getSelectedIndex()
+
+	s.songs = nil
+	s.selectPage(page.CurrentPage + 1)
+	if len(s.songs) > 0 {
+		page = s.page
+		for _, v := range s.songs {
+			loaded = append(loaded, v.song)
+		}
+	}
+	s.setSongs(loaded, page, offset)
+	s.list.SetSelected(selected)
+}
+
 func (s *SongList) showReduceInput(visible bool) {
 	if visible {
 		s.Banner.Grid.AddItem(s.reduceInput, 5, 0, 1, 10, 1, 10, false)
@@ -195,3 +442,11 @@ func (s *SongList) showReduceInput(visible bool) {
 		s.Banner.Grid.AddItem(s.list, 4, 0, 2, 10, 6, 20, false)
 	}
 }
+
+func (s *SongList) setPlayingSong(id models.Id) {
+	markPlayingSong(s.songs, id)
+}
+
+func (s *SongList) highlightedItem() models.Item {
+	return songAtIndex(s.songs, s.getSelectedIndex())
+}
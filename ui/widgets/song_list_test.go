@@ -0,0 +1,136 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_episodeStatus(t *testing.T) {
+	published := time.Date(2020, 11, 3, 6, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		song *models.Song
+		want string
+	}{
+		{
+			name: "no metadata",
+			song: &models.Song{Name: "Episode"},
+			want: "",
+		},
+		{
+			name: "not played",
+			song: &models.Song{Name: "Episode", Published: published},
+			want: "2020-11-03",
+		},
+		{
+			name: "partially played",
+			song: &models.Song{Name: "Episode", Published: published, Position: 754500},
+			want: "2020-11-03  resume at 12:34",
+		},
+		{
+			name: "played",
+			song: &models.Song{Name: "Episode", Published: published, Position: 754500, Played: true},
+			want: "2020-11-03  played",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := episodeStatus(tt.song); got != tt.want {
+				t.Errorf("episodeStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_playedAgo(t *testing.T) {
+	now := time.Date(2021, 4, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		played time.Time
+		want   string
+	}{
+		{
+			name:   "just now",
+			played: now.Add(-time.Second * 20),
+			want:   "just now",
+		},
+		{
+			name:   "minutes",
+			played: now.Add(-time.Minute * 5),
+			want:   "5 min ago",
+		},
+		{
+			name:   "hours",
+			played: now.Add(-time.Minute * 150),
+			want:   "2 h ago",
+		},
+		{
+			name:   "days",
+			played: now.Add(-time.Hour * 75),
+			want:   "3 d ago",
+		},
+		{
+			name:   "over a week",
+			played: now.Add(-time.Hour * 24 * 10),
+			want:   now.Add(-time.Hour * 24 * 10).Local().Format("2006-01-02"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := playedAgo(tt.played, now); got != tt.want {
+				t.Errorf("playedAgo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_songsFrom(t *testing.T) {
+	list := []*albumSong{
+		{song: &models.Song{Id: "a"}},
+		{song: &models.Song{Id: "b"}},
+		{song: &models.Song{Id: "c"}},
+	}
+	tests := []struct {
+		name  string
+		index int
+		want  []models.Id
+	}{
+		{name: "first", index: 0, want: []models.Id{"a", "b", "c"}},
+		{name: "middle", index: 1, want: []models.Id{"b", "c"}},
+		{name: "last", index: 2, want: []models.Id{"c"}},
+		{name: "past end", index: 3, want: []models.Id{}},
+		{name: "negative", index: -1, want: []models.Id{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := songsFrom(list, tt.index)
+			ids := make([]models.Id, len(got))
+			for i, v := range got {
+				ids[i] = v.Id
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("songsFrom() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -22,8 +22,12 @@ import (
 	"fmt"
 	"github.com/gdamore/tcell"
 	"gitlab.com/tslocum/cview"
+	"math"
+	"strings"
 	"sync"
+	"time"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/util"
@@ -33,19 +37,21 @@ import (
 const (
 	btnPrevious = "|<<"
 	btnPause    = " ||"
-	btnStop     = "■"
 	btnPlay     = ">"
 	btnNext     = ">>|"
 	btnForward  = ">>"
 	btnBackward = " <<"
 	btnQueue    = "☰"
-
-	// yellow heart, utf8. Not visible on all editors.
-	charFavorite = "💛"
-	btnShuffle   = "Shuffle"
+	btnShuffle  = "Shuffle"
 
 	btnStyleStart = "[white:red:b]"
 	btnStyleStop  = "[-:-:-]"
+
+	// statusClockFormat is the format of {clock} token in status format.
+	statusClockFormat = "15:04"
+
+	// upNextSeconds is how many seconds before end of song next song is shown.
+	upNextSeconds = 10
 )
 
 func btn(button string) string {
@@ -86,10 +92,172 @@ type Status struct {
 	actionCb func(state interfaces.AudioStatus)
 
 	player interfaces.Player
+
+	// spectrum contains visualizer levels, nil if visualizer is disabled.
+	spectrum []float64
+	// group is current SyncPlay group, nil if not in group.
+	group *models.SyncPlayGroup
+	// castTarget is name of the device playback is controlled on, empty if playing on this device.
+	castTarget string
+	// next is the song that plays after current song, nil if queue has no more songs.
+	next *models.Song
+
+	// position of progress bar fill area from last draw, used for seeking with mouse.
+	progressX     int
+	progressY     int
+	progressWidth int
 }
 
 func (s *Status) MouseHandler() func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
-	return s.layout.MouseHandler()
+	return func(action cview.MouseAction, event *tcell.EventMouse, setFocus func(p cview.Primitive)) (consumed bool, capture cview.Primitive) {
+		x, y := event.Position()
+		if !s.frame.InRect(x, y) {
+			return false, nil
+		}
+
+		switch action {
+		case cview.MouseLeftClick:
+			if y == s.progressY {
+				s.seekTo(x)
+				return true, nil
+			}
+		case cview.MouseScrollUp:
+			s.changeVolume(config.VolumeStepSize)
+			return true, nil
+		case cview.MouseScrollDown:
+			s.changeVolume(-config.VolumeStepSize)
+			return true, nil
+		}
+		return s.layout.MouseHandler()(action, event, setFocus)
+	}
+}
+
+// seekTo seeks to position on progress bar at column x, if song is playing.
+func (s *Status) seekTo(x int) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.state.State == interfaces.AudioStateStopped || s.state.Song == nil {
+		return
+	}
+
+	target, ok := progressPosition(x, s.progressX, s.progressWidth, s.state.Song.Duration)
+	if !ok {
+		return
+	}
+	go s.player.SeekTo(interfaces.AudioTick(target * 1000))
+}
+
+func (s *Status) changeVolume(step int) {
+	s.lock.RLock()
+	volume := s.state.Volume.Add(step)
+	s.lock.RUnlock()
+	go s.player.SetVolume(volume)
+}
+
+// setSpectrum sets visualizer levels. Nil levels hide visualizer.
+func (s *Status) setSpectrum(levels []float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.spectrum = levels
+}
+
+func (s *Status) setGroup(group *models.SyncPlayGroup) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.group = group
+}
+
+// setNextSong sets song that plays after current song. Nil hides 'Up next' text.
+func (s *Status) setNextSong(song *models.Song) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.next = song
+}
+
+func (s *Status) setCastTarget(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.castTarget = name
+}
+
+// groupText returns SyncPlay group name and state, or empty string if not in group.
+func groupText(group *models.SyncPlayGroup) string {
+	if group == nil {
+		return ""
+	}
+	text := i18n.Tf("SyncPlay: %s", group.Name)
+	details := []string{}
+	if group.State != "" {
+		details = append(details, group.State)
+	}
+	if n := len(group.Participants); n == 1 {
+		details = append(details, i18n.T("1 user"))
+	} else if n > 1 {
+		details = append(details, i18n.Tf("%d users", n))
+	}
+	if len(details) > 0 {
+		text += " (" + strings.Join(details, ", ") + ")"
+	}
+	return text
+}
+
+// spectrumBars returns visualizer bar for each level in range [0,1].
+func spectrumBars(levels []float64) string {
+	bars := []rune(symbol.visualizerBars)
+	text := make([]rune, len(levels))
+	for i, v := range levels {
+		index := int(math.Round(v * float64(len(bars)-1)))
+		if index < 0 {
+			index = 0
+		} else if index >= len(bars) {
+			index = len(bars) - 1
+		}
+		text[i] = bars[index]
+	}
+	return string(text)
+}
+
+// songRemaining returns remaining seconds of song.
+func songRemaining(past, duration int) int {
+	if past >= duration {
+		return 0
+	}
+	return duration - past
+}
+
+// bufferingText returns download progress of current song, or empty string if song is fully
+// downloaded or progress is not known.
+func bufferingText(state interfaces.AudioStatus) string {
+	if state.Song == nil || state.Buffered <= 0 || state.Buffered >= 100 {
+		return ""
+	}
+	return i18n.Tf("buffered %d%%", state.Buffered) + " "
+}
+
+// upNextText returns text that tells next song, when current song ends in upNextSeconds.
+// Otherwise it returns empty string.
+func upNextText(state interfaces.AudioStatus, next *models.Song) string {
+	if next == nil || state.Song == nil || state.State != interfaces.AudioStatePlaying || state.Paused {
+		return ""
+	}
+	remaining := songRemaining(state.SongPast.Seconds(), state.Song.Duration)
+	if remaining <= 0 || remaining > upNextSeconds {
+		return ""
+	}
+	name := next.Name
+	if len(next.Artists) > 0 {
+		name = next.Artists[0].Name + " " + symbol.dash + " " + next.Name
+	}
+	return i18n.Tf("Up next: %s", name)
+}
+
+// progressPosition returns position in seconds at column x, when progress bar fill area starts at column barX
+// and has given width. If x is outside progress bar, return false.
+func progressPosition(x, barX, width, duration int) (int, bool) {
+	if width <= 0 || x < barX || x >= barX+width {
+		return 0, false
+	}
+	return (x - barX) * duration / width, true
 }
 
 func newStatus(ctrl interfaces.Player) *Status {
@@ -120,8 +288,8 @@ func newStatus(ctrl interfaces.Player) *Status {
 	s.btnPrevious = cview.NewButton(btnPrevious)
 	s.btnForward = cview.NewButton(btnForward)
 	s.btnBackward = cview.NewButton(btnBackward)
-	s.btnStop = cview.NewButton(btnStop)
-	s.btnShuffle = cview.NewButton(btnShuffle)
+	s.btnStop = cview.NewButton(symbol.stop)
+	s.btnShuffle = cview.NewButton(i18n.T(btnShuffle))
 
 	s.progress = NewProgressBar(40, 100)
 	s.volume = NewProgressBar(10, 100)
@@ -165,17 +333,38 @@ func newStatus(ctrl interfaces.Player) *Status {
 func (s *Status) Draw(screen tcell.Screen) {
 	s.frame.Draw(screen)
 	x, y, w, _ := s.frame.GetInnerRect()
+	if accessible {
+		// single line that only changes with state, progress and volume are not drawn
+		s.lock.RLock()
+		text := accessibleStatus(s.state)
+		if upNext := upNextText(s.state, s.next); upNext != "" {
+			text += ". " + upNext
+		}
+		s.lock.RUnlock()
+		cview.Print(screen, cview.Escape(text), x+1, y, w-1, cview.AlignLeft, s.detailsMainColor)
+		return
+	}
 
 	songPast := util.SecToString(s.state.SongPast.Seconds())
-	songPast = " " + songPast + " "
 	var songDuration = " 0:00 "
+	endClock := ""
 	if s.state.Song != nil {
 		songDuration = util.SecToString(s.state.Song.Duration)
 		songDuration = " " + songDuration + " "
+		remaining := songRemaining(s.state.SongPast.Seconds(), s.state.Song.Duration)
+		if config.AppConfig.Gui.ShowRemainingTime {
+			songPast = "-" + util.SecToString(remaining)
+		}
+		if config.AppConfig.Gui.ShowEndClock && s.state.State == interfaces.AudioStatePlaying && !s.state.Paused {
+			endClock = "ends " + time.Now().Add(time.Duration(remaining)*time.Second).Format(statusClockFormat) + " "
+		}
 	}
+	songPast = " " + songPast + " "
+	buffering := bufferingText(s.state)
 
 	volume := " Volume " + s.volume.Draw(int(s.state.Volume))
-	topRowFree := w - len(songPast) - len(songDuration) - utf8.RuneCountInString(volume) - 5
+	topRowFree := w - len(songPast) - len(songDuration) - len(endClock) - utf8.RuneCountInString(buffering) -
+		utf8.RuneCountInString(volume) - 5
 
 	showShuffleBtn := false
 	showShuffleSmall := false
@@ -188,12 +377,15 @@ func (s *Status) Draw(screen tcell.Screen) {
 	}
 
 	s.progress.SetWidth(topRowFree * 10 / 11)
+	s.progressWidth = topRowFree * 10 / 11
+	s.progressX = x + 1 + len(songPast) + utf8.RuneCountInString(symbol.progressStart)
+	s.progressY = y - 1
 
 	s.lock.RLock()
 	defer s.lock.RUnlock()
 
 	progressBar := s.progress.Draw(s.state.SongPast.Seconds())
-	progress := songPast + progressBar + songDuration
+	progress := songPast + progressBar + songDuration + endClock + buffering
 	progressLen := utf8.RuneCountInString(progress)
 	topX := x + 1
 	colors := config.Color.Status
@@ -228,7 +420,7 @@ func (s *Status) Draw(screen tcell.Screen) {
 		}
 	}
 	if showShuffleBtn {
-		s.btnShuffle.SetLabel("Shuffle")
+		s.btnShuffle.SetLabel(i18n.T("Shuffle"))
 		shuffleX := x + w - volumeLen - 11
 		// draw two empty characters around the button the separate it from status box.
 		cview.Print(screen, "         ", shuffleX, btnY-2, 9, cview.AlignLeft, colors.Shortcuts)
@@ -246,6 +438,21 @@ func (s *Status) Draw(screen tcell.Screen) {
 		s.btnShuffle.Draw(screen)
 	}
 	s.WriteStatus(screen, x+30, y)
+
+	if s.spectrum != nil && s.state.State == interfaces.AudioStatePlaying {
+		cview.Print(screen, spectrumBars(s.spectrum), volumeX+2, y+1, volumeLen-2, cview.AlignLeft, colors.ProgressBar)
+	}
+	remote := groupText(s.group)
+	if s.castTarget != "" {
+		remote = strings.TrimPrefix(remote+" | Casting to "+s.castTarget, " | ")
+	}
+	if upNext := upNextText(s.state, s.next); upNext != "" {
+		remote = strings.TrimSuffix(cview.Escape(upNext)+" | "+remote, " | ")
+	}
+	if remote != "" {
+		remoteLen := utf8.RuneCountInString(remote)
+		cview.Print(screen, " "+remote+" ", volumeX-remoteLen-3, y+1, remoteLen+2, cview.AlignLeft, colors.Shortcuts)
+	}
 }
 
 func (s *Status) GetRect() (int, int, int, int) {
@@ -278,25 +485,76 @@ func (s *Status) GetFocusable() cview.Focusable {
 func (s *Status) WriteStatus(screen tcell.Screen, x, y int) {
 	if s.state.State != interfaces.AudioStateStopped &&
 		(s.state.Song != nil && s.state.Album != nil && s.state.Artist != nil) {
+		if format := config.AppConfig.Gui.StatusFormat; format != "" {
+			w, _ := screen.Size()
+			for i, line := range formatStatus(format, s.state, time.Now()) {
+				cview.Print(screen, line, x+2, y+i, w, cview.AlignLeft, s.detailsMainColor)
+			}
+			return
+		}
 		xi := x
 		x += 2
 		w, _ := screen.Size()
 		if s.state.Song.Favorite {
-			cview.Print(screen, charFavorite, x, y, 2, cview.AlignLeft, config.Color.TextSelected)
+			cview.Print(screen, symbol.favorite, x, y, 2, cview.AlignLeft, config.Color.TextSelected)
 			x += 3
 		}
 
 		cview.Print(screen, effect(s.state.Song.Name, "b")+" - ", x, y, w, cview.AlignLeft, s.detailsMainColor)
-		x += len(s.state.Song.Name) + 3
+		x += stringWidth(s.state.Song.Name) + 3
 		cview.Print(screen, effect(s.state.Artist.Name, "b")+" ", x, y, w, cview.AlignLeft, s.detailsMainColor)
-		x += len(s.state.Artist.Name) + 1
+		x += stringWidth(s.state.Artist.Name) + 1
 		x = xi + 4
 		cview.Print(screen, s.state.Album.Name+" ", x, y+1, w, cview.AlignLeft, s.detailsMainColor)
-		x += len(s.state.Album.Name) + 1
+		x += stringWidth(s.state.Album.Name) + 1
 		cview.Print(screen, fmt.Sprintf("(%d)", s.state.Album.Year), x, y+1, w, cview.AlignLeft, s.detailsMainColor)
 	}
 }
 
+// formatStatus fills status format tokens with current state and returns at most two lines.
+// Lines are separated with newline or literal '\n'. Unknown tokens are left as they are.
+func formatStatus(format string, state interfaces.AudioStatus, now time.Time) []string {
+	favorite := ""
+	if state.Song != nil && state.Song.Favorite {
+		favorite = symbol.favorite
+	}
+	tokens := append(interfaces.StatusTokens(state),
+		"{favorite}", favorite,
+		"{clock}", now.Format(statusClockFormat),
+	)
+	replacer := strings.NewReplacer(tokens...)
+
+	format = strings.ReplaceAll(format, `\n`, "\n")
+	lines := strings.SplitN(format, "\n", 3)
+	if len(lines) > 2 {
+		lines = lines[:2]
+	}
+	for i, v := range lines {
+		lines[i] = replacer.Replace(v)
+	}
+	return lines
+}
+
+// playingSong returns currently playing song or nil.
+// playingStatus returns status of playing song. It returns false if nothing is playing.
+func (s *Status) playingStatus() (interfaces.AudioStatus, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.state.State == interfaces.AudioStateStopped || s.state.Song == nil {
+		return interfaces.AudioStatus{}, false
+	}
+	return s.state, true
+}
+
+func (s *Status) playingSong() *models.Song {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.state.State == interfaces.AudioStateStopped {
+		return nil
+	}
+	return s.state.Song
+}
+
 func (s *Status) UpdateState(state interfaces.AudioStatus, song *models.SongInfo) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
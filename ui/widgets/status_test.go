@@ -0,0 +1,193 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+func Test_formatStatus(t *testing.T) {
+	state := interfaces.AudioStatus{
+		Song:   &models.Song{Name: "song", Codec: "flac", Bitrate: 900},
+		Album:  &models.Album{Name: "album", Year: 2001},
+		Artist: &models.Artist{Name: "artist"},
+		Volume: 40,
+	}
+	now := time.Date(2020, 1, 1, 21, 43, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		format string
+		muted  bool
+		want   []string
+	}{
+		{
+			name:   "single line",
+			format: "{title} - {artist} [{codec} {bitrate}]",
+			want:   []string{"song - artist [flac 900 kbps]"},
+		},
+		{
+			name:   "two lines",
+			format: `{album} ({year})\n{volume} {clock} {unknown}`,
+			want:   []string{"album (2001)", "40% 21:43 {unknown}"},
+		},
+		{
+			name:   "muted, extra lines",
+			format: "{volume}\n{shuffle}\nthird",
+			muted:  true,
+			want:   []string{"muted", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state.Muted = tt.muted
+			if got := formatStatus(tt.format, state, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("formatStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_progressPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		x      int
+		want   int
+		wantOk bool
+	}{
+		{name: "start", x: 10, want: 0, wantOk: true},
+		{name: "middle", x: 30, want: 100, wantOk: true},
+		{name: "last column", x: 49, want: 195, wantOk: true},
+		{name: "before bar", x: 9, want: 0, wantOk: false},
+		{name: "after bar", x: 50, want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := progressPosition(tt.x, 10, 40, 200)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("progressPosition() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_songRemaining(t *testing.T) {
+	if got := songRemaining(30, 200); got != 170 {
+		t.Errorf("songRemaining() = %d, want 170", got)
+	}
+	if got := songRemaining(210, 200); got != 0 {
+		t.Errorf("songRemaining() past duration = %d, want 0", got)
+	}
+}
+
+func Test_bufferingText(t *testing.T) {
+	song := &models.Song{Name: "song", Duration: 200}
+	tests := []struct {
+		name  string
+		state interfaces.AudioStatus
+		want  string
+	}{
+		{name: "downloading", state: interfaces.AudioStatus{Song: song, Buffered: 45}, want: "buffered 45% "},
+		{name: "downloaded", state: interfaces.AudioStatus{Song: song, Buffered: 100}, want: ""},
+		{name: "unknown", state: interfaces.AudioStatus{Song: song}, want: ""},
+		{name: "no song", state: interfaces.AudioStatus{Buffered: 45}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bufferingText(tt.state); got != tt.want {
+				t.Errorf("bufferingText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_spectrumBars(t *testing.T) {
+	got := spectrumBars([]float64{0, 0.5, 1, 1.5})
+	want := " ▄██"
+	if got != want {
+		t.Errorf("spectrumBars() = %q, want %q", got, want)
+	}
+}
+
+func Test_groupText(t *testing.T) {
+	tests := []struct {
+		name  string
+		group *models.SyncPlayGroup
+		want  string
+	}{
+		{name: "no group", group: nil, want: ""},
+		{
+			name:  "playing",
+			group: &models.SyncPlayGroup{Name: "Party", State: "Playing", Participants: []string{"a", "b"}},
+			want:  "SyncPlay: Party (Playing, 2 users)",
+		},
+		{
+			name:  "single user",
+			group: &models.SyncPlayGroup{Name: "Party", Participants: []string{"a"}},
+			want:  "SyncPlay: Party (1 user)",
+		},
+		{name: "no details", group: &models.SyncPlayGroup{Name: "Party"}, want: "SyncPlay: Party"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := groupText(tt.group); got != tt.want {
+				t.Errorf("groupText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_upNextText(t *testing.T) {
+	next := &models.Song{Name: "next", Artists: []models.IdName{{Name: "artist"}}}
+	playing := func(past int) interfaces.AudioStatus {
+		return interfaces.AudioStatus{
+			State:    interfaces.AudioStatePlaying,
+			Song:     &models.Song{Name: "current", Duration: 200},
+			SongPast: interfaces.AudioTick(past * 1000),
+		}
+	}
+	paused := playing(195)
+	paused.Paused = true
+
+	tests := []struct {
+		name  string
+		state interfaces.AudioStatus
+		next  *models.Song
+		want  string
+	}{
+		{name: "ending", state: playing(195), next: next, want: "Up next: artist – next"},
+		{name: "ten seconds left", state: playing(190), next: next, want: "Up next: artist – next"},
+		{name: "not ending", state: playing(100), next: next, want: ""},
+		{name: "no next song", state: playing(195), next: nil, want: ""},
+		{name: "paused", state: paused, next: next, want: ""},
+		{name: "stopped", state: interfaces.AudioStatus{State: interfaces.AudioStateStopped}, next: next, want: ""},
+		{name: "no artist", state: playing(195), next: &models.Song{Name: "next"}, want: "Up next: next"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := upNextText(tt.state, tt.next); got != tt.want {
+				t.Errorf("upNextText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+package widgets
+
+import "gitlab.com/tslocum/cview"
+
+// symbols are special characters drawn in user interface.
+type symbols struct {
+	stop     string
+	favorite string
+	checked  string
+	ellipsis string
+	dash     string
+	// artBlock is placeholder for album and artist images, drawn with color of image
+	artBlock string
+
+	// expanded and collapsed are shown before headers of sections that can be collapsed
+	expanded  string
+	collapsed string
+
+	// visualizerBars are visualizer bars from silence to full level
+	visualizerBars string
+
+	progressFull          string
+	progressThreeQuarters string
+	progressHalf          string
+	progressQuarter       string
+	progressStart         string
+	progressStop          string
+	progressEmpty         string
+}
+
+var unicodeSymbols = symbols{
+	stop: "■",
+	// yellow heart, utf8. Not visible on all editors.
+	favorite:       "💛",
+	checked:        "✔",
+	expanded:       "▾",
+	collapsed:      "▸",
+	ellipsis:       "…",
+	dash:           "–",
+	artBlock:       "██",
+	visualizerBars: " ▁▂▃▄▅▆▇█",
+
+	progressFull:          "█",
+	progressThreeQuarters: "▊",
+	progressHalf:          "▌",
+	progressQuarter:       "▎",
+	progressStart:         "┫",
+	progressStop:          "┣",
+	progressEmpty:         "╍",
+}
+
+// asciiSymbols are for terminals and fonts that don't render unicode symbols well, e.g. Windows console.
+var asciiSymbols = symbols{
+	stop:           "[]",
+	favorite:       "<3",
+	checked:        "*",
+	expanded:       "-",
+	collapsed:      "+",
+	ellipsis:       "...",
+	dash:           "-",
+	artBlock:       "##",
+	visualizerBars: " .:-=+*#@",
+
+	progressFull:          "#",
+	progressThreeQuarters: "#",
+	progressHalf:          "=",
+	progressQuarter:       "-",
+	progressStart:         "[",
+	progressStop:          "]",
+	progressEmpty:         ".",
+}
+
+// symbol is current symbol set.
+var symbol = unicodeSymbols
+
+// SetAsciiOnly selects ascii symbols and borders instead of unicode.
+// It must be called before creating widgets.
+func SetAsciiOnly(ascii bool) {
+	if !ascii {
+		symbol = unicodeSymbols
+		return
+	}
+	symbol = asciiSymbols
+	setBorders('-', '|', '+', '=')
+}
+
+// setBorders sets characters of all borders. Corner is used for corners and junctions.
+// Focused borders use horizontalFocus instead of horizontal.
+func setBorders(horizontal, vertical, corner, horizontalFocus rune) {
+	cview.Borders.Horizontal = horizontal
+	cview.Borders.Vertical = vertical
+	cview.Borders.TopLeft = corner
+	cview.Borders.TopRight = corner
+	cview.Borders.BottomLeft = corner
+	cview.Borders.BottomRight = corner
+	cview.Borders.LeftT = corner
+	cview.Borders.RightT = corner
+	cview.Borders.TopT = corner
+	cview.Borders.BottomT = corner
+	cview.Borders.Cross = corner
+	cview.Borders.HorizontalFocus = horizontalFocus
+	cview.Borders.VerticalFocus = vertical
+	cview.Borders.TopLeftFocus = corner
+	cview.Borders.TopRightFocus = corner
+	cview.Borders.BottomLeftFocus = corner
+	cview.Borders.BottomRightFocus = corner
+}
@@ -0,0 +1,169 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"github.com/gdamore/tcell"
+	"gitlab.com/tslocum/cview"
+	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
+	"tryffel.net/go/jellycli/interfaces"
+	"tryffel.net/go/jellycli/models"
+)
+
+// syncPlayNoGroups is a group option shown when there are no groups to join
+var syncPlayNoGroups = i18n.N("No groups")
+
+// syncPlay provides a modal for creating, joining and leaving SyncPlay groups.
+type syncPlay struct {
+	*cview.Form
+	controller interfaces.SyncPlayController
+	// errorFunc is called if any action fails.
+	errorFunc func(action string, err error)
+
+	visible bool
+	closeCb func()
+
+	name   *cview.InputField
+	group  *cview.DropDown
+	groups []*models.SyncPlayGroup
+}
+
+func newSyncPlay(controller interfaces.SyncPlayController, errorFunc func(action string, err error)) *syncPlay {
+	s := &syncPlay{
+		Form:       cview.NewForm(),
+		controller: controller,
+		errorFunc:  errorFunc,
+		name:       cview.NewInputField(),
+		group:      cview.NewDropDown(),
+	}
+
+	s.SetTitle(" SyncPlay ")
+	s.SetBackgroundColor(config.Color.Modal.Background)
+	s.SetBorder(true)
+
+	s.name.SetLabel(i18n.T("New group"))
+	s.name.SetPlaceholder(i18n.T("Group name"))
+	s.name.SetPlaceholderTextColor(config.Color.TextDisabled)
+	s.name.SetFieldTextColor(config.Color.Text)
+	s.group.SetLabel(i18n.T("Join group"))
+	s.group.SetFieldTextColor(config.Color.Text)
+
+	s.AddFormItem(s.name)
+	s.AddFormItem(s.group)
+	s.AddButton(i18n.T("Create"), s.create)
+	s.AddButton(i18n.T("Join"), s.join)
+	s.AddButton(i18n.T("Leave"), s.leave)
+	s.AddButton(i18n.T("Cancel"), s.cancel)
+
+	for i := 0; i < s.GetButtonCount(); i++ {
+		s.GetButton(i).SetInputCapture(s.inputCapture)
+	}
+	s.group.SetInputCapture(s.inputCapture)
+	s.SetCancelFunc(s.cancel)
+	return s
+}
+
+func (s *syncPlay) SetDoneFunc(doneFunc func()) {
+	s.closeCb = doneFunc
+}
+
+func (s *syncPlay) View() cview.Primitive {
+	return s
+}
+
+func (s *syncPlay) SetVisible(visible bool) {
+	s.visible = visible
+	if visible {
+		s.loadGroups()
+	}
+}
+
+// loadGroups refreshes groups that can be joined.
+func (s *syncPlay) loadGroups() {
+	groups, err := s.controller.GetSyncPlayGroups()
+	if err != nil {
+		s.errorFunc("get SyncPlay groups", err)
+	}
+	s.groups = groups
+	s.group.SetOptions(nil, nil)
+	if len(groups) == 0 {
+		s.group.AddOption(i18n.T(syncPlayNoGroups), nil)
+	}
+	for _, v := range groups {
+		s.group.AddOption(i18n.Tf("%s (%d users)", v.Name, len(v.Participants)), nil)
+	}
+	s.group.SetCurrentOption(0)
+}
+
+func (s *syncPlay) create() {
+	name := s.name.GetText()
+	if name == "" {
+		return
+	}
+	s.name.SetText("")
+	s.cancel()
+	go func() {
+		err := s.controller.NewSyncPlayGroup(name)
+		if err != nil {
+			s.errorFunc("create SyncPlay group", err)
+		}
+	}()
+}
+
+func (s *syncPlay) join() {
+	index, _ := s.group.GetCurrentOption()
+	if index < 0 || index >= len(s.groups) {
+		return
+	}
+	id := s.groups[index].Id
+	s.cancel()
+	go func() {
+		err := s.controller.JoinSyncPlayGroup(id)
+		if err != nil {
+			s.errorFunc("join SyncPlay group", err)
+		}
+	}()
+}
+
+func (s *syncPlay) leave() {
+	s.cancel()
+	go func() {
+		err := s.controller.LeaveSyncPlayGroup()
+		if err != nil {
+			s.errorFunc("leave SyncPlay group", err)
+		}
+	}()
+}
+
+func (s *syncPlay) cancel() {
+	if s.closeCb != nil {
+		s.closeCb()
+	}
+}
+
+func (s *syncPlay) inputCapture(e *tcell.EventKey) *tcell.EventKey {
+	switch e.Key() {
+	case tcell.KeyUp:
+		return tcell.NewEventKey(tcell.KeyBacktab, e.Rune(), e.Modifiers())
+	case tcell.KeyDown:
+		return tcell.NewEventKey(tcell.KeyTab, e.Rune(), e.Modifiers())
+	}
+	return e
+}
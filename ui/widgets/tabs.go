@@ -0,0 +1,118 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell"
+	"tryffel.net/go/jellycli/i18n"
+)
+
+// maxTabs is the maximum number of tabs, switched with Alt+1 - Alt+9.
+const maxTabs = 9
+
+// tab is an independent browsing workspace with its own view and navigation history.
+type tab struct {
+	// current is the view of tab when tab was left. View is nil if no view has been opened in tab.
+	current historyEntry
+	history viewHistory
+}
+
+func newTab() *tab {
+	return &tab{}
+}
+
+// tabLabels returns tab numbers with current tab highlighted, or empty text if there is only one tab.
+func tabLabels(current, count int) string {
+	if count <= 1 {
+		return ""
+	}
+	text := ""
+	for i := 0; i < count; i++ {
+		if i == current {
+			text += fmt.Sprintf("[::r] %d [::-]", i+1)
+		} else {
+			text += fmt.Sprintf(" %d ", i+1)
+		}
+	}
+	return text + "  "
+}
+
+// tabCtrl switches to tab n with Alt+n. Switching to a tab after the last one opens a new tab.
+func (w *Window) tabCtrl(event *tcell.EventKey) bool {
+	if w.hasModal || event.Key() != tcell.KeyRune || event.Modifiers()&tcell.ModAlt == 0 {
+		return false
+	}
+	r := event.Rune()
+	if r < '1' || r > '0'+maxTabs {
+		return false
+	}
+	w.switchTab(int(r - '1'))
+	return true
+}
+
+// tab returns current tab.
+func (w *Window) tab() *tab {
+	return w.tabs[w.currentTab]
+}
+
+// switchTab saves current view to current tab and shows tab at index.
+// Tab without any view is closed when switching to another tab.
+func (w *Window) switchTab(index int) {
+	current := w.tab()
+	current.current = w.currentEntry()
+	if index >= len(w.tabs) {
+		if current.current.view == nil {
+			// already in a new tab
+			return
+		}
+		w.tabs = append(w.tabs, newTab())
+		index = len(w.tabs) - 1
+	}
+	if index == w.currentTab {
+		return
+	}
+	if current.current.view == nil {
+		w.tabs = append(w.tabs[:w.currentTab], w.tabs[w.currentTab+1:]...)
+		if index > w.currentTab {
+			index -= 1
+		}
+	}
+	w.currentTab = index
+	w.showTab(w.tab())
+}
+
+// showTab shows view of tab and restores its content.
+func (w *Window) showTab(t *tab) {
+	w.breadcrumbs.setTabs(w.currentTab, len(w.tabs))
+	if t.current.view != nil {
+		w.showEntry(t.current)
+	} else if w.mediaView != nil {
+		// new tab starts from navigation pane
+		w.mediaArea.RemoveItem(w.mediaView)
+		w.mediaView = nil
+		w.mediaViewSelected = false
+		w.updateBreadcrumbs()
+		w.app.SetFocus(w.mediaNav)
+		w.updateLayout()
+	}
+	if accessible {
+		w.notifyInfo(i18n.Tf("Tab %d", w.currentTab+1))
+	}
+}
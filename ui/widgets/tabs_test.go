@@ -0,0 +1,31 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import "testing"
+
+func Test_tabLabels(t *testing.T) {
+	if got := tabLabels(0, 1); got != "" {
+		t.Errorf("tabLabels() with single tab = %q, want empty", got)
+	}
+	want := " 1 [::r] 2 [::-] 3   "
+	if got := tabLabels(1, 3); got != want {
+		t.Errorf("tabLabels() = %q, want %q", got, want)
+	}
+}
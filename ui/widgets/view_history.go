@@ -0,0 +1,153 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+// maxHistory is the maximum number of views to remember in back and forward history.
+const maxHistory = 50
+
+// viewContent restores content of a view that shows a single item, such as album.
+// Views are shared, so content has to be restored when going back to an earlier item.
+type viewContent struct {
+	id     int
+	reload func()
+}
+
+// historyEntry is a view in navigation history.
+type historyEntry struct {
+	view    Previous
+	content viewContent
+	// title of view when it was left, as view might show other content later.
+	title string
+}
+
+// viewHistory is a browser-like navigation history with back and forward stacks.
+type viewHistory struct {
+	back    []historyEntry
+	forward []historyEntry
+}
+
+// push adds entry to back history and clears forward history.
+func (h *viewHistory) push(entry historyEntry) {
+	h.back = append(h.back, entry)
+	if len(h.back) > maxHistory {
+		h.back = h.back[len(h.back)-maxHistory:]
+	}
+	h.forward = nil
+}
+
+// goBack moves steps back in history and returns entry to show. Current entry and entries
+// between are moved to forward history. It returns false if there are not enough entries.
+func (h *viewHistory) goBack(current historyEntry, steps int) (historyEntry, bool) {
+	if steps < 1 || steps > len(h.back) {
+		return historyEntry{}, false
+	}
+	for i := 0; i < steps; i++ {
+		h.forward = append(h.forward, current)
+		current = h.back[len(h.back)-1]
+		h.back = h.back[:len(h.back)-1]
+	}
+	return current, true
+}
+
+// goForward moves one step forward in history and returns entry to show.
+// It returns false if there is no forward history.
+func (h *viewHistory) goForward(current historyEntry) (historyEntry, bool) {
+	if len(h.forward) == 0 {
+		return historyEntry{}, false
+	}
+	h.back = append(h.back, current)
+	next := h.forward[len(h.forward)-1]
+	h.forward = h.forward[:len(h.forward)-1]
+	return next, true
+}
+
+// titles returns titles of at most max latest entries in back history, starting from the oldest.
+func (h *viewHistory) titles(max int) []string {
+	entries := h.back
+	if len(entries) > max {
+		entries = entries[len(entries)-max:]
+	}
+	titles := make([]string, len(entries))
+	for i, v := range entries {
+		titles[i] = v.title
+	}
+	return titles
+}
+
+// currentEntry returns current view as history entry.
+func (w *Window) currentEntry() historyEntry {
+	return historyEntry{view: w.mediaView, content: w.contents[w.mediaView], title: breadcrumbTitle(w.mediaView)}
+}
+
+// setViewContent sets content of view that shows a single item with reload. Reload is called again
+// when view is shown from history or another tab after its content has changed.
+func (w *Window) setViewContent(view Previous, reload func()) {
+	if view == w.mediaView {
+		// new item in current view, e.g. similar album from album
+		w.tab().history.push(w.currentEntry())
+	}
+	w.contentId++
+	w.contents[view] = viewContent{id: w.contentId, reload: reload}
+	reload()
+}
+
+// historyBack goes back steps views in history of current tab.
+func (w *Window) historyBack(steps int) {
+	if w.mediaView == nil {
+		return
+	}
+	if entry, ok := w.tab().history.goBack(w.currentEntry(), steps); ok {
+		w.showEntry(entry)
+	}
+}
+
+// historyForward goes forward to the view that was left with back.
+func (w *Window) historyForward() {
+	if w.mediaView == nil {
+		return
+	}
+	if entry, ok := w.tab().history.goForward(w.currentEntry()); ok {
+		w.showEntry(entry)
+	}
+}
+
+// showEntry shows view of history entry and restores its content, without adding to history.
+func (w *Window) showEntry(entry historyEntry) {
+	if entry.content.reload != nil && w.contents[entry.view].id != entry.content.id {
+		entry.content.reload()
+		w.contents[entry.view] = entry.content
+	}
+	w.setViewWidget(entry.view, false)
+	w.refreshRecentSongs()
+	w.refreshHistory()
+}
+
+// updateBreadcrumbs shows history of current tab in breadcrumbs, and links current view
+// to previous view for its back button.
+func (w *Window) updateBreadcrumbs() {
+	history := &w.tab().history
+	if w.mediaView != nil {
+		var last Previous
+		if n := len(history.back); n > 0 {
+			last = history.back[n-1].view
+		}
+		w.mediaView.SetLast(last)
+	}
+	w.breadcrumbs.setView(w.mediaView, history.titles(maxBreadcrumbs-1))
+}
@@ -0,0 +1,87 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testEntry(title string) historyEntry {
+	return historyEntry{view: newTestView(title), title: title}
+}
+
+func Test_viewHistory(t *testing.T) {
+	h := &viewHistory{}
+	artists := testEntry("Artists")
+	artist := testEntry("Metallica")
+	album := testEntry("Ride the Lightning")
+
+	if _, ok := h.goBack(artists, 1); ok {
+		t.Errorf("goBack() with empty history succeeded")
+	}
+
+	h.push(artists)
+	h.push(artist)
+	if got, want := h.titles(maxBreadcrumbs), []string{"Artists", "Metallica"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("titles() = %v, want %v", got, want)
+	}
+	if got, want := h.titles(1), []string{"Metallica"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("titles() with max = %v, want %v", got, want)
+	}
+
+	// album is current view
+	entry, ok := h.goBack(album, 1)
+	if !ok || entry.title != "Metallica" {
+		t.Fatalf("goBack() = %s, want Metallica", entry.title)
+	}
+	entry, ok = h.goForward(entry)
+	if !ok || entry.title != "Ride the Lightning" {
+		t.Fatalf("goForward() = %s, want Ride the Lightning", entry.title)
+	}
+	if _, ok := h.goForward(entry); ok {
+		t.Errorf("goForward() at the end of history succeeded")
+	}
+
+	// jump back two views, forward history has both views
+	entry, ok = h.goBack(entry, 2)
+	if !ok || entry.title != "Artists" || len(h.back) != 0 || len(h.forward) != 2 {
+		t.Fatalf("goBack() two steps = %s, %d back, %d forward", entry.title, len(h.back), len(h.forward))
+	}
+	entry, _ = h.goForward(entry)
+	if entry.title != "Metallica" {
+		t.Errorf("goForward() = %s, want Metallica", entry.title)
+	}
+
+	// new view clears forward history
+	h.push(entry)
+	if len(h.forward) != 0 {
+		t.Errorf("push() did not clear forward history")
+	}
+}
+
+func Test_viewHistory_limit(t *testing.T) {
+	h := &viewHistory{}
+	for i := 0; i < maxHistory+10; i++ {
+		h.push(testEntry("view"))
+	}
+	if len(h.back) != maxHistory {
+		t.Errorf("history has %d views, want %d", len(h.back), maxHistory)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import (
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+	"strings"
+)
+
+// graphemeWidth returns width of single grapheme cluster in terminal cells.
+// Like cview, cluster takes the width of its first rune that is not zero-width,
+// so combining characters are drawn in the same cell as the base character.
+func graphemeWidth(runes []rune) int {
+	for _, r := range runes {
+		if w := runewidth.RuneWidth(r); w > 0 {
+			return w
+		}
+	}
+	return 0
+}
+
+// stringWidth returns width of text in terminal cells. East Asian wide characters take two cells.
+func stringWidth(text string) int {
+	width := 0
+	g := uniseg.NewGraphemes(text)
+	for g.Next() {
+		width += graphemeWidth(g.Runes())
+	}
+	return width
+}
+
+// truncateWidth cuts text to fit in given width. If text is cut, last cells are replaced with ellipsis.
+// Grapheme clusters are never split.
+func truncateWidth(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if stringWidth(text) <= width {
+		return text
+	}
+	ellipsis := symbol.ellipsis
+	free := width - stringWidth(ellipsis)
+	if free < 0 {
+		ellipsis = ""
+		free = width
+	}
+
+	out := strings.Builder{}
+	used := 0
+	g := uniseg.NewGraphemes(text)
+	for g.Next() {
+		w := graphemeWidth(g.Runes())
+		if used+w > free {
+			break
+		}
+		used += w
+		out.WriteString(g.Str())
+	}
+	return out.String() + ellipsis
+}
+
+// padWidth pads text with spaces to given width. If text is wider, it is truncated.
+func padWidth(text string, width int) string {
+	text = truncateWidth(text, width)
+	if w := stringWidth(text); w < width {
+		text += strings.Repeat(" ", width-w)
+	}
+	return text
+}
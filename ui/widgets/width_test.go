@@ -0,0 +1,75 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package widgets
+
+import "testing"
+
+func Test_stringWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{name: "ascii", text: "Metallica", want: 9},
+		{name: "cjk", text: "東京事変", want: 8},
+		{name: "combining", text: "Sigur Ro\u0301s", want: 9},
+		{name: "mixed", text: "BTS 방탄소년단", want: 14},
+		{name: "empty", text: "", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stringWidth(tt.text); got != tt.want {
+				t.Errorf("stringWidth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_truncateWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{name: "fits", text: "Metallica", width: 9, want: "Metallica"},
+		{name: "ascii", text: "Metallica", width: 6, want: "Metal…"},
+		{name: "cjk", text: "東京事変", width: 7, want: "東京事…"},
+		// wide character does not fit in the last free cell
+		{name: "cjk odd width", text: "東京事変", width: 6, want: "東京…"},
+		{name: "combining", text: "Ro\u0301s Ro\u0301s", width: 4, want: "Ro\u0301s…"},
+		{name: "zero width", text: "Metallica", width: 0, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateWidth(tt.text, tt.width); got != tt.want {
+				t.Errorf("truncateWidth() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_padWidth(t *testing.T) {
+	if got := padWidth("東京", 6); got != "東京  " {
+		t.Errorf("padWidth() = %q, want %q", got, "東京  ")
+	}
+	if got := padWidth("東京事変", 5); got != "東京…" {
+		t.Errorf("padWidth() = %q, want %q", got, "東京…")
+	}
+}
@@ -21,12 +21,15 @@
 package widgets
 
 import (
+	"context"
 	"fmt"
 	"github.com/gdamore/tcell"
 	"github.com/sirupsen/logrus"
 	"gitlab.com/tslocum/cview"
+	"strings"
 	"time"
 	"tryffel.net/go/jellycli/config"
+	"tryffel.net/go/jellycli/i18n"
 	"tryffel.net/go/jellycli/interfaces"
 	"tryffel.net/go/jellycli/models"
 	"tryffel.net/go/jellycli/ui/widgets/modal"
@@ -39,25 +42,41 @@ type Window struct {
 	layout *twidgets.ModalLayout
 
 	// Widgets
-	navBar   *twidgets.NavBar
-	status   *Status
-	mediaNav *MediaNavigation
-	help     *modal.Help
-	message  *modal.Message
-	queue    *Queue
-	history  *History
-
-	artistAlbumList *ArtistAlbumList
-	albumList       *AlbumList
-	similarAlbums   *AlbumList
-	album           *AlbumView
-	latestAlbums    *AlbumList
-	favoriteAlbums  *AlbumList
-	artistList      *ArtistList
-	playlists       *Playlists
-	playlist        *PlaylistView
-	songs           *SongList
-	genres          *GenreList
+	navBar       *twidgets.NavBar
+	status       *Status
+	mediaNav     *MediaNavigation
+	help         *modal.Help
+	cheatSheet   *modal.CheatSheet
+	syncPlay     *syncPlay
+	cast         *cast
+	library      *library
+	settings     *settings
+	export       *export
+	gain         *gain
+	chapters     *chapters
+	importer     *playlistImport
+	message      *modal.Message
+	notification *notification
+	queue        *Queue
+	history      *History
+
+	artistView     *ArtistView
+	albumList      *AlbumList
+	similarAlbums  *AlbumList
+	album          *AlbumView
+	latestAlbums   *AlbumList
+	favoriteAlbums *AlbumList
+	artistList     *ArtistList
+	playlists      *Playlists
+	playlist       *PlaylistView
+	songs          *SongList
+	genres         *GenreList
+	genre          *GenreView
+	composers      *GenreList
+	podcasts       *AlbumList
+	episodes       *SongList
+	downloads      *Downloads
+	duplicates     *Duplicates
 
 	searchResultsTop *SearchTopList
 
@@ -68,10 +87,49 @@ type Window struct {
 
 	mediaView         Previous
 	mediaViewSelected bool
+	// selectedMedia is the last selection from media navigation, if mediaSelected is true.
+	selectedMedia MediaSelect
+	mediaSelected bool
+	// mediaArea contains breadcrumbs and media view
+	mediaArea   *cview.Flex
+	breadcrumbs *breadcrumbs
+	// tabs are independent browsing workspaces, currentTab is the index of visible tab.
+	tabs       []*tab
+	currentTab int
+	// contents is the current content of each single item view, contentId is the latest content id.
+	contents  map[Previous]viewContent
+	contentId int
+	// dockedQueue is shown beside media view, if enabled
+	dockedQueue   *Queue
+	queueSelected bool
+	// playingSong is the id of playing song, or empty if nothing is playing.
+	// It must only be accessed from ui goroutine.
+	playingSong models.Id
+	// castTarget is the session songs and playback commands are sent to, nil if playing on this device.
+	castTarget *models.Session
+	// visualizerStop stops updating visualizer, nil if visualizer is not running.
+	visualizerStop chan bool
+	// statusRefresh batches status updates from player.
+	statusRefresh *statusRefresher
+	// announcedStatus is the last status announced in accessible mode.
+	// It is only accessed from statusRefresh.
+	announcedStatus interfaces.AudioStatus
+	// seekAccel grows seek step while seek key is held down. It must only be accessed from ui goroutine.
+	seekAccel seekAccelerator
+	// seeker applies seeks in background.
+	seeker *seeker
+	// requests loads views in background and cancels stale requests.
+	requests *viewRequests
 
 	mediaPlayer interfaces.Player
 	mediaItems  interfaces.ItemController
 	mediaQueue  interfaces.QueueController
+	// podcastController is nil if player does not support podcasts.
+	podcastController interfaces.PodcastController
+	// downloadController is nil if player does not support downloading songs.
+	downloadController interfaces.DownloadController
+	// prefetcher is nil if player does not prefetch pages.
+	prefetcher interfaces.PagePrefetcher
 
 	hasModal  bool
 	lastFocus cview.Primitive
@@ -79,20 +137,35 @@ type Window struct {
 
 func NewWindow(p interfaces.Player, i interfaces.ItemController, q interfaces.QueueController) Window {
 	w := Window{
-		app:    cview.NewApplication(),
-		status: newStatus(p),
-		layout: twidgets.NewModalLayout(),
+		app:      cview.NewApplication(),
+		status:   newStatus(p),
+		layout:   twidgets.NewModalLayout(),
+		tabs:     []*tab{newTab()},
+		contents: map[Previous]viewContent{},
 	}
 
 	previousWidgets := make([]Previous, 0, 5)
 
-	w.artistList = NewArtistList(w.selectArtist, w.queryArtists)
-	w.artistList.selectPageFunc = w.showArtistPage
-	w.artistAlbumList = NewArtistAlbumList(w.selectAlbum, &w, w.showAlbumPage, w.openFilterModal)
+	w.breadcrumbs = newBreadcrumbs(w.historyBack)
+	w.mediaArea = cview.NewFlex()
+	w.mediaArea.SetDirection(cview.FlexRow)
+	w.mediaArea.SetBackgroundColor(config.Color.Background)
+	w.mediaArea.AddItem(w.breadcrumbs, 1, 0, false)
 
-	previousWidgets = append(previousWidgets, w.artistList, w.artistAlbumList)
+	w.artistList = NewArtistList(w.selectArtist, w.queryArtists, &w)
+	w.artistList.selectPageFunc = w.showArtistPage
+	w.artistList.persistSorting(&config.AppConfig.Gui.SortArtists)
+	w.artistView = NewArtistView(w.selectAlbum, w.playSong, w.playSongs, &w)
+	w.artistView.similarFunc = w.showSimilarArtists
+	w.artistView.getSimilarFunc = w.getSimilarArtists
+	w.artistView.selectArtistFunc = w.selectArtist
+	w.artistView.showTextFunc = w.showText
+
+	previousWidgets = append(previousWidgets, w.artistList, w.artistView)
 	w.albumList = NewAlbumList(w.selectAlbum, &w, w.showAlbumPage, w.openFilterModal)
 	w.albumList.similarFunc = w.showSimilarArtists
+	w.albumList.persistSorting(&config.AppConfig.Gui.SortAlbums)
+	w.albumList.filter.enableComposers(w.getFilterComposers)
 
 	previousWidgets = append(previousWidgets, w.albumList)
 	w.latestAlbums = newLatestAlbums(w.selectAlbum, &w)
@@ -107,29 +180,54 @@ func NewWindow(p interfaces.Player, i interfaces.ItemController, q interfaces.Qu
 
 	w.album = NewAlbumview(w.playSong, w.playSongs, &w)
 	w.album.similarFunc = w.showSimilarAlbums
+	w.album.selectGenreFunc = w.selectGenre
+	w.album.showTextFunc = w.showText
 	previousWidgets = append(previousWidgets, w.album)
 	w.mediaNav = NewMediaNavigation(w.selectMedia)
 	w.navBar = twidgets.NewNavBar(config.Color.NavBar.ToWidgetsNavBar(), w.navBarHandler)
 
 	w.playlists = NewPlaylists(w.selectPlaylist)
+	w.playlists.importFunc = w.showImport
 	w.playlist = NewPlaylistView(w.playSong, w.playSongs, &w)
 	previousWidgets = append(previousWidgets, w.playlists, w.playlist)
 
-	w.genres = NewGenreList()
+	w.genres = NewGenreList(w.playGenre, &w)
 	w.genres.selectFunc = w.selectGenre
 	w.genres.selectPageFunc = w.showGenrePage
-	previousWidgets = append(previousWidgets, w.genres)
+	w.genre = NewGenreView(w.showGenreItems, w.playGenre)
+	previousWidgets = append(previousWidgets, w.genres, w.genre)
+
+	w.composers = NewGenreList(nil, nil)
+	w.composers.selectFunc = w.showComposerAlbums
+	w.composers.selectPageFunc = w.showComposerPage
+	previousWidgets = append(previousWidgets, w.composers)
 
 	w.songs = NewSongList(w.playSong, w.playSongs, &w)
 	w.songs.showPage = w.selectSongs
+	w.songs.persistSorting(&config.AppConfig.Gui.SortSongs)
 	previousWidgets = append(previousWidgets, w.songs)
 
+	w.podcasts = NewAlbumList(w.selectPodcast, &w, nil, nil)
+	w.podcasts.EnablePaging(false)
+	w.podcasts.EnableSimilar(false)
+	w.episodes = newEpisodeList(w.playSong, w.playSongs, w.setEpisodePlayed)
+	previousWidgets = append(previousWidgets, w.podcasts, w.episodes)
+
+	w.downloads = NewDownloads(w.removeDownload)
+	w.downloads.exportFunc = func(songs []*models.Song) { w.showExport(i18n.T("Downloads"), songs) }
+	previousWidgets = append(previousWidgets, w.downloads)
+
+	w.duplicates = NewDuplicates(w.playSong, w.playSongs, &w, w.favoriteSong, w.playSongs)
+	previousWidgets = append(previousWidgets, w.duplicates)
+
 	w.searchResultsTop = NewSearchTopList(w.searchCb, w.showSearchResults)
 	previousWidgets = append(previousWidgets, w.searchResultsTop)
 
 	w.mediaPlayer = p
 	w.mediaItems = i
 	w.mediaQueue = q
+	w.seeker = newSeeker(w.mediaPlayer.Seek)
+	w.requests = newViewRequests(func(f func()) { w.app.QueueUpdateDraw(f) }, w.notifyError)
 
 	w.setLayout()
 	w.app.SetRoot(w.layout, true)
@@ -137,6 +235,7 @@ func NewWindow(p interfaces.Player, i interfaces.ItemController, q interfaces.Qu
 		w.app.EnableMouse(true)
 		interval := time.Millisecond * time.Duration(config.AppConfig.Gui.DoubleClickMs)
 		w.app.SetDoubleClickInterval(interval)
+		w.app.SetMouseCapture(w.mouseCapture)
 	}
 
 	w.app.SetFocus(w.mediaNav)
@@ -145,33 +244,119 @@ func NewWindow(p interfaces.Player, i interfaces.ItemController, q interfaces.Qu
 	//w.window.SetInputCapture(w.eventHandler)
 	w.help = modal.NewHelp(w.closeHelp)
 	w.help.SetDoneFunc(w.wrapCloseModal(w.help))
+	w.cheatSheet = modal.NewCheatSheet()
+	w.cheatSheet.SetDoneFunc(w.wrapCloseModal(w.cheatSheet))
+	w.settings = newSettings(w.settingsChanged, w.notifyError)
+	w.settings.SetDoneFunc(w.wrapCloseModal(w.settings))
 	w.message = modal.NewMessage()
 	w.message.SetDoneFunc(w.closeMessage)
+	w.notification = newNotification(func() {
+		w.app.QueueUpdateDraw(func() {})
+	})
 
 	w.queue = NewQueue()
 	previousWidgets = append(previousWidgets, w.queue)
 	w.queue.clearFunc = w.clearQueue
+	w.queue.exportFunc = func(songs []*models.Song) { w.showExport(i18n.T("Queue"), songs) }
 	w.queue.controller = w.mediaQueue
+	w.queue.EnableGroups()
+	w.dockedQueue = NewQueue()
+	w.dockedQueue.clearFunc = w.clearQueue
+	w.dockedQueue.exportFunc = w.queue.exportFunc
+	w.dockedQueue.controller = w.mediaQueue
+	w.dockedQueue.EnableGroups()
+	w.updateLayout()
 	w.mediaQueue.AddQueueChangedCallback(func(songs []*models.Song) {
 		w.app.QueueUpdateDraw(func() {
-			index := w.queue.list.GetSelectedIndex()
-			w.queue.SetSongs(songs)
-			w.queue.list.SetSelected(index)
+			for _, queue := range []*Queue{w.queue, w.dockedQueue} {
+				index := queue.list.GetSelectedIndex()
+				queue.SetSongs(songs)
+				queue.list.SetSelected(index)
+			}
+			var next *models.Song
+			if len(songs) > 1 {
+				next = songs[1]
+			}
+			w.status.setNextSong(next)
 		})
 	})
 
 	w.history = NewHistory()
+	w.history.exportFunc = func(songs []*models.Song) { w.showExport(i18n.T("History"), songs) }
 	previousWidgets = append(previousWidgets, w.history)
 
-	w.mediaQueue.SetHistoryChangedCallback(func(songs []*models.Song) {
-		w.app.QueueUpdateDraw(func() {
-			w.history.SetSongs(songs)
+	if !config.AppConfig.Player.EnableHistoryDb {
+		// local history is refreshed when song changes
+		w.mediaQueue.SetHistoryChangedCallback(func(songs []*models.Song) {
+			w.app.QueueUpdateDraw(func() {
+				w.history.SetSongs(songs)
+			})
 		})
-	})
+	}
 
 	w.layout.Grid().SetBackgroundColor(config.Color.Background)
+	w.statusRefresh = newStatusRefresher(w.drawStatus)
+	go w.statusRefresh.run()
 	w.mediaPlayer.AddStatusCallback(w.statusCb)
-	navBarLabels := []string{"Help", "Queue", "History", "Search"}
+	w.mediaPlayer.AddErrorCallback(w.playerErrorCb)
+	if controller, ok := w.mediaPlayer.(interfaces.SyncPlayController); ok {
+		w.syncPlay = newSyncPlay(controller, w.notifyError)
+		w.syncPlay.SetDoneFunc(w.wrapCloseModal(w.syncPlay))
+		controller.AddSyncPlayCallback(w.syncPlayCb)
+	}
+	if controller, ok := w.mediaPlayer.(interfaces.SessionController); ok {
+		w.cast = newCast(controller, w.setCastTarget, w.notifyError)
+		w.cast.SetDoneFunc(w.wrapCloseModal(w.cast))
+	}
+	if controller, ok := w.mediaPlayer.(interfaces.LibraryController); ok {
+		w.library = newLibrary(controller, w.libraryChanged, w.notifyError)
+		w.library.SetDoneFunc(w.wrapCloseModal(w.library))
+	}
+	if controller, ok := w.mediaPlayer.(interfaces.PodcastController); ok {
+		w.podcastController = controller
+	}
+	if controller, ok := w.mediaPlayer.(interfaces.DownloadController); ok {
+		w.downloadController = controller
+		controller.AddDownloadCallback(w.downloadsChanged)
+	}
+	if importer, ok := w.mediaPlayer.(interfaces.PlaylistImporter); ok {
+		w.importer = newPlaylistImport(importer, w.playlistImportStarted, w.playlistImported, w.notifyError)
+		w.importer.SetDoneFunc(w.wrapCloseModal(w.importer))
+	}
+	if exporter, ok := w.mediaPlayer.(interfaces.PlaylistExporter); ok {
+		w.export = newExport(exporter, w.playlistExported, w.notifyError)
+		w.export.SetDoneFunc(w.wrapCloseModal(w.export))
+	}
+	if controller, ok := w.mediaPlayer.(interfaces.GainController); ok {
+		w.gain = newGain(controller, w.gainSaved, w.notifyError)
+		w.gain.SetDoneFunc(w.wrapCloseModal(w.gain))
+	}
+	if controller, ok := w.mediaPlayer.(interfaces.ChapterController); ok {
+		w.chapters = newChapters(controller, func() interfaces.AudioTick {
+			status, _ := w.status.playingStatus()
+			return status.SongPast
+		})
+		w.chapters.SetDoneFunc(w.wrapCloseModal(w.chapters))
+	}
+	if prefetcher, ok := w.mediaPlayer.(interfaces.PagePrefetcher); ok {
+		w.prefetcher = prefetcher
+	}
+	if provider, ok := w.mediaPlayer.(interfaces.ArtColorProvider); ok && config.AppConfig.Gui.ArtPlaceholders {
+		queueDraw := func(f func()) { w.app.QueueUpdateDraw(f) }
+		w.albumList.artColors = newArtColors(provider, queueDraw)
+		w.latestAlbums.artColors = newArtColors(provider, queueDraw)
+		w.favoriteAlbums.artColors = newArtColors(provider, queueDraw)
+		w.similarAlbums.artColors = newArtColors(provider, queueDraw)
+		w.artistList.artColors = newArtColors(provider, queueDraw)
+		w.artistView.artColors = newArtColors(provider, queueDraw)
+	}
+	if notifier, ok := w.mediaPlayer.(interfaces.LibraryChangeNotifier); ok {
+		notifier.AddLibraryChangedCallback(w.libraryContentChanged)
+	}
+	if notifier, ok := w.mediaPlayer.(interfaces.ConnectionNotifier); ok {
+		notifier.AddConnectionCallback(w.connectionCb)
+	}
+	navBarLabels := []string{i18n.T("Help"), i18n.T("Queue"), i18n.T("History"), i18n.T("Search")}
 
 	sc := config.KeyBinds.NavigationBar
 	navBarShortucts := []tcell.Key{sc.Help, sc.Queue, sc.History, sc.Search}
@@ -190,14 +375,54 @@ func NewWindow(p interfaces.Player, i interfaces.ItemController, q interfaces.Qu
 		v.SetBackCallback(w.goBack)
 	}
 
+	// startup view makes requests to server, open it once application runs
+	w.app.QueueUpdateDraw(w.showStartupView)
+	if config.AppConfig.Gui.EnableVisualizer {
+		w.setVisualizer(true)
+	}
 	return w
 }
 
+// showStartupView opens view set in config, if any.
+func (w *Window) showStartupView() {
+	view := config.AppConfig.Gui.StartupView
+	if view == config.StartupViewLast {
+		view = config.AppConfig.Gui.LastView
+	}
+	if view == "" {
+		return
+	}
+	selection, ok := mediaSelectionFromKey(view)
+	if !ok {
+		logrus.Warningf("unknown startup view '%s'", view)
+		return
+	}
+	w.mediaNav.Select(selection)
+	w.selectMedia(selection)
+}
+
+// saveLastView persists selection as last view, if startup view is set to last view.
+func (w *Window) saveLastView(selection MediaSelect) {
+	if config.AppConfig.Gui.StartupView != config.StartupViewLast {
+		return
+	}
+	key := mediaSelectionKeys[selection]
+	if key == config.AppConfig.Gui.LastView {
+		return
+	}
+	config.AppConfig.Gui.LastView = key
+	err := config.SaveConfig()
+	if err != nil {
+		logrus.Errorf("save last view: %v", err)
+	}
+}
+
 func (w *Window) Run() error {
 	return w.app.Run()
 }
 
 func (w *Window) Stop() {
+	w.statusRefresh.close()
 	w.app.Stop()
 }
 
@@ -208,37 +433,255 @@ func (w *Window) setLayout() {
 	w.layout.SetGridXSize([]int{10, -1, -1, -1, -1, -1, -1, -1, -1, 10})
 	w.layout.SetGridYSize([]int{1, -1, -1, -1, -1, -1, -1, -1, -1, 5})
 
-	w.layout.Grid().AddItem(w.navBar, 0, 0, 1, 10, 1, 30, false)
-	w.layout.Grid().AddItem(w.mediaNav, 1, 0, 8, 2, 5, 10, false)
+	if accessible {
+		// announcements on first line, status on single line at bottom
+		w.layout.SetGridYSize([]int{1, -1, -1, -1, -1, -1, -1, -1, -1, 3})
+		w.layout.Grid().AddItem(w.notification, 0, 0, 1, 10, 1, 10, false)
+	} else {
+		w.layout.Grid().AddItem(w.navBar, 0, 0, 1, 6, 1, 30, false)
+		w.layout.Grid().AddItem(w.notification, 0, 6, 1, 4, 1, 10, false)
+	}
 	w.layout.Grid().AddItem(w.status, 9, 0, 1, 10, 3, 10, false)
+	w.updateLayout()
 
 	//w.setViewWidget(w.artistList)
 }
 
-// go back to previous primitive
+// updateLayout places navigation pane, media view and docked queue according to config.
+// Navigation pane is always shown if there is no media view.
+func (w *Window) updateLayout() {
+	gui := config.AppConfig.Gui
+	grid := w.layout.Grid()
+	grid.RemoveItem(w.mediaNav)
+	grid.RemoveItem(w.mediaArea)
+	if w.dockedQueue != nil {
+		grid.RemoveItem(w.dockedQueue)
+	}
+
+	xSize := []int{10, -1, -1, -1, -1, -1, -1, -1, -1, 10}
+	if accessible {
+		// only one of navigation pane and media view is shown, whichever has focus
+		w.layout.SetGridXSize(xSize)
+		if w.mediaView == nil || w.mediaNav.HasFocus() {
+			grid.AddItem(w.mediaNav, 1, 0, 8, 10, 5, 10, false)
+		} else {
+			grid.AddItem(w.mediaArea, 1, 0, 8, 10, 15, 10, false)
+		}
+		return
+	}
+	// first column of media view
+	mediaColumn := 2
+	if gui.NavigationHidden && w.mediaView != nil {
+		mediaColumn = 0
+		if w.mediaNav.HasFocus() {
+			w.app.SetFocus(w.mediaView)
+			w.mediaViewSelected = true
+		}
+	} else if gui.NavigationWidth > 0 {
+		xSize[0] = gui.NavigationWidth
+		mediaColumn = 1
+		grid.AddItem(w.mediaNav, 1, 0, 8, 1, 5, 10, false)
+	} else {
+		grid.AddItem(w.mediaNav, 1, 0, 8, 2, 5, 10, false)
+	}
+	w.layout.SetGridXSize(xSize)
+
+	// docked queue takes three last columns
+	mediaColumns := 10 - mediaColumn
+	if gui.QueueDocked && w.dockedQueue != nil {
+		mediaColumns -= 3
+		grid.AddItem(w.dockedQueue, 1, 7, 8, 3, 15, 10, false)
+	} else if w.queueSelected {
+		w.queueSelected = false
+		if w.mediaView != nil {
+			w.app.SetFocus(w.mediaView)
+			w.mediaViewSelected = true
+		} else {
+			w.app.SetFocus(w.mediaNav)
+		}
+	}
+	grid.AddItem(w.mediaArea, 1, mediaColumn, 8, mediaColumns, 15, 10, false)
+}
+
+// jumpToPlaying selects playing song in queue, if queue is open.
+// Else it opens album of playing song and selects the song.
+func (w *Window) jumpToPlaying() {
+	song := w.status.playingSong()
+	if song == nil {
+		w.notifyInfo(i18n.T("Nothing is playing"))
+		return
+	}
+
+	if w.queueSelected {
+		w.dockedQueue.selectPlaying()
+		return
+	}
+	if w.mediaView == w.queue {
+		w.queue.selectPlaying()
+		return
+	}
+
+	w.requests.load("get playing album", func(ctx context.Context) (func(), error) {
+		album, _, err := w.mediaItems.GetSongArtistAlbum(ctx, song)
+		if err != nil {
+			return nil, err
+		}
+		show, err := w.loadAlbum(ctx, album)
+		return func() {
+			show()
+			w.album.selectSong(song.Id)
+		}, err
+	})
+}
+
+// toggleQueue shows or hides queue beside media view.
+func (w *Window) toggleQueue() {
+	config.AppConfig.Gui.QueueDocked = !config.AppConfig.Gui.QueueDocked
+	w.updateLayout()
+	w.saveLayout()
+}
+
+// toggleNavigation shows or hides navigation pane.
+func (w *Window) toggleNavigation() {
+	config.AppConfig.Gui.NavigationHidden = !config.AppConfig.Gui.NavigationHidden
+	w.updateLayout()
+	w.saveLayout()
+}
+
+// resizeNavigation changes navigation pane width by delta columns.
+func (w *Window) resizeNavigation(delta int) {
+	if config.AppConfig.Gui.NavigationHidden {
+		return
+	}
+	width := config.AppConfig.Gui.NavigationWidth
+	if width == 0 {
+		_, _, width, _ = w.mediaNav.GetRect()
+	}
+	width += delta
+	if width < config.MinNavigationWidth {
+		width = config.MinNavigationWidth
+	} else if width > config.MaxNavigationWidth {
+		width = config.MaxNavigationWidth
+	}
+	config.AppConfig.Gui.NavigationWidth = width
+	w.updateLayout()
+	w.saveLayout()
+}
+
+func (w *Window) saveLayout() {
+	err := config.SaveConfig()
+	if err != nil {
+		logrus.Errorf("save layout: %v", err)
+	}
+}
+
+// layoutCtrl resizes navigation pane with Alt+Left and Alt+Right.
+func (w *Window) layoutCtrl(event *tcell.EventKey) bool {
+	if w.hasModal || event.Modifiers()&tcell.ModAlt == 0 {
+		return false
+	}
+	switch event.Key() {
+	case tcell.KeyLeft:
+		w.resizeNavigation(-navigationResizeStep)
+	case tcell.KeyRight:
+		w.resizeNavigation(navigationResizeStep)
+	default:
+		return false
+	}
+	return true
+}
+
+// navigationResizeStep is the number of columns to resize navigation pane at once.
+const navigationResizeStep = 2
+
+const (
+	visualizerBands = 16
+	// visualizerInterval is the refresh interval of visualizer.
+	visualizerInterval = time.Second / 15
+)
+
+// setVisualizer starts or stops updating visualizer, if player supports it.
+func (w *Window) setVisualizer(enabled bool) {
+	analyzer, ok := w.mediaPlayer.(interfaces.SpectrumAnalyzer)
+	if !ok {
+		logrus.Warning("player does not support visualizer")
+		return
+	}
+	if w.visualizerStop != nil {
+		close(w.visualizerStop)
+		w.visualizerStop = nil
+	}
+
+	analyzer.SetSpectrumEnabled(enabled)
+	if !enabled {
+		w.status.setSpectrum(nil)
+		return
+	}
+	w.visualizerStop = make(chan bool)
+	go w.updateVisualizer(analyzer, w.visualizerStop)
+}
+
+func (w *Window) updateVisualizer(analyzer interfaces.SpectrumAnalyzer, stop chan bool) {
+	ticker := time.NewTicker(visualizerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if w.status.playingSong() == nil {
+				continue
+			}
+			levels := analyzer.Spectrum(visualizerBands)
+			w.app.QueueUpdateDraw(func() {
+				w.status.setSpectrum(levels)
+			})
+		}
+	}
+}
+
+// go back to previous view in history. Views' back buttons call this with their previous view.
 func (w *Window) goBack(p Previous) {
-	w.setViewWidget(p, false)
+	w.historyBack(1)
 }
 
-// set central widget. If updatePrevious, set update previous primitive's last primitive
+// set central widget. If updatePrevious, current view is added to navigation history.
 func (w *Window) setViewWidget(p Previous, updatePrevious bool) {
+	// view might have new songs
+	if marker, ok := p.(playingMarker); ok {
+		marker.setPlayingSong(w.playingSong)
+	}
 	if p == w.mediaView {
+		w.updateBreadcrumbs()
 		return
 	}
+	w.requests.stop()
+	if w.prefetcher != nil {
+		w.prefetcher.CancelPrefetch()
+	}
 
 	last := w.mediaView
+	if updatePrevious && last != nil {
+		w.tab().history.push(w.currentEntry())
+	}
 	w.lastFocus = w.app.GetFocus()
-	w.layout.Grid().RemoveItem(w.mediaView)
-	w.layout.Grid().AddItem(p, 1, 2, 8, 8, 15, 10, false)
+	if w.mediaView != nil {
+		w.mediaArea.RemoveItem(w.mediaView)
+	}
+	w.mediaArea.AddItem(p, 0, 1, false)
 	w.app.SetFocus(p)
 	w.mediaView = p
-	if updatePrevious {
-		p.SetLast(last)
+	if accessible || (last == nil && config.AppConfig.Gui.NavigationHidden) {
+		w.updateLayout()
+	}
+	w.updateBreadcrumbs()
+	if accessible {
+		w.notifyInfo(breadcrumbTitle(p))
 	}
 }
 
 func (w *Window) eventHandler(event *tcell.EventKey) *tcell.EventKey {
-
+	w.statusRefresh.input()
 	out := w.keyHandler(event)
 	if out == nil {
 		return nil
@@ -260,6 +703,12 @@ func (w *Window) keyHandler(event *tcell.EventKey) *tcell.Key {
 	if w.navBarCtrl(key) {
 		return nil
 	}
+	if w.tabCtrl(event) {
+		return nil
+	}
+	if w.layoutCtrl(event) {
+		return nil
+	}
 	if w.moveCtrl(key) {
 		return nil
 	}
@@ -270,6 +719,12 @@ func (w *Window) keyHandler(event *tcell.EventKey) *tcell.Key {
 func (w *Window) mediaCtrl(event *tcell.EventKey) bool {
 	ctrls := config.KeyBinds.Global
 	key := event.Key()
+	if w.castTarget != nil {
+		if command, ok := castCommand(key); ok {
+			go w.sendCastCommand(w.castTarget, command)
+			return true
+		}
+	}
 	switch key {
 	case ctrls.Stop:
 		w.mediaPlayer.StopMedia()
@@ -287,15 +742,22 @@ func (w *Window) mediaCtrl(event *tcell.EventKey) bool {
 	case ctrls.Previous:
 		w.mediaPlayer.Previous()
 	case ctrls.Forward:
-		w.mediaPlayer.Seek(interfaces.AudioTick(3000))
+		w.seek(true)
 	case ctrls.Backward:
-		w.mediaPlayer.Seek(interfaces.AudioTick(-3000))
+		w.seek(false)
+	case ctrls.NextChapter:
+		go w.changeChapter(true)
+	case ctrls.PreviousChapter:
+		go w.changeChapter(false)
 	case ctrls.Shuffle:
 		shuffle := !w.status.state.Shuffle
 		go w.mediaPlayer.SetShuffle(shuffle)
 	case ctrls.MuteUnmute:
 		mute := !w.status.state.Muted
 		go w.mediaPlayer.SetMute(mute)
+	case ctrls.ToggleRemaining:
+		config.AppConfig.Gui.ShowRemainingTime = !config.AppConfig.Gui.ShowRemainingTime
+		w.saveLayout()
 
 	default:
 		return false
@@ -303,6 +765,15 @@ func (w *Window) mediaCtrl(event *tcell.EventKey) bool {
 	return true
 }
 
+// seek seeks forward or backward. Step grows while seek key is held down.
+func (w *Window) seek(forward bool) {
+	step := w.seekAccel.step(forward, time.Now(), &config.AppConfig.Gui)
+	if !forward {
+		step = -step
+	}
+	w.seeker.add(interfaces.AudioTick(step * 1000))
+}
+
 func (w *Window) navBarCtrl(key tcell.Key) bool {
 	navBar := config.KeyBinds.NavigationBar
 	switch key {
@@ -313,6 +784,51 @@ func (w *Window) navBarCtrl(key tcell.Key) bool {
 		stats := w.mediaItems.GetStatistics()
 		w.help.SetStats(stats)
 		w.showModal(w.help, 25, 50, true)
+	case navBar.CheatSheet:
+		if w.help.HasFocus() {
+			w.closeModal(w.help)
+		}
+		w.showModal(w.cheatSheet, 25, 60, true)
+	case navBar.ToggleNavigation:
+		w.toggleNavigation()
+	case navBar.DockQueue:
+		w.toggleQueue()
+	case navBar.JumpToPlaying:
+		w.jumpToPlaying()
+	case navBar.OpenPlaying:
+		if song := w.status.playingSong(); song != nil {
+			w.OpenInBrowser(song)
+		} else {
+			w.notifyInfo(i18n.T("Nothing is playing"))
+		}
+	case navBar.CopyPlaying:
+		w.copyPlaying()
+	case navBar.SyncPlay:
+		if w.syncPlay == nil {
+			w.notifyError("SyncPlay", interfaces.ErrSyncPlayNotSupported)
+		} else {
+			w.showModal(w.syncPlay, 12, 50, false)
+		}
+	case navBar.Cast:
+		if w.cast == nil {
+			w.notifyError("cast", interfaces.ErrSessionsNotSupported)
+		} else {
+			w.showModal(w.cast, 9, 60, false)
+		}
+	case navBar.Library:
+		if w.library == nil {
+			w.notifyError("select music library", interfaces.ErrLibrariesNotSupported)
+		} else {
+			w.showModal(w.library, 8, 50, false)
+		}
+	case navBar.Settings:
+		w.showModal(w.settings, 16, 60, false)
+	case navBar.Chapters:
+		w.showChapters()
+	case navBar.ToggleVisualizer:
+		config.AppConfig.Gui.EnableVisualizer = !config.AppConfig.Gui.EnableVisualizer
+		w.setVisualizer(config.AppConfig.Gui.EnableVisualizer)
+		w.saveLayout()
 	case navBar.Search:
 		w.searchResultsTop.Clear()
 		w.setViewWidget(w.searchResultsTop, true)
@@ -325,14 +841,25 @@ func (w *Window) navBarCtrl(key tcell.Key) bool {
 		if w.help.HasFocus() {
 			w.closeModal(w.help)
 		}
-		w.setViewWidget(w.history, true)
-		items := w.mediaQueue.GetHistory(100)
-		duration := 0
-		for _, v := range items {
-			duration += v.Duration
+		if !config.AppConfig.Player.EnableHistoryDb {
+			w.setViewWidget(w.history, true)
+			break
 		}
+		w.requests.load("get history", func(ctx context.Context) (func(), error) {
+			songs, _, err := w.mediaItems.GetPlayHistory(ctx, historyPaging())
+			return func() {
+				w.history.SetSongs(songs)
+				w.setViewWidget(w.history, true)
+			}, err
+		})
+	case navBar.HistoryBack:
+		w.historyBack(1)
+	case navBar.HistoryForward:
+		w.historyForward()
 	case navBar.Dump:
 		w.debugDump()
+	case navBar.Info:
+		w.showItemInfo()
 	default:
 		return false
 	}
@@ -344,8 +871,36 @@ func (w *Window) moveCtrl(key tcell.Key) bool {
 		if w.hasModal {
 			return false
 		}
-
-		if w.mediaViewSelected {
+		if accessible {
+			w.switchAccessibleFocus()
+			return true
+		}
+		navigationHidden := config.AppConfig.Gui.NavigationHidden && w.mediaView != nil
+		queueDocked := config.AppConfig.Gui.QueueDocked
+
+		if w.queueSelected {
+			// from docked queue to navigation, or media view if navigation is hidden
+			w.lastFocus = w.dockedQueue
+			w.queueSelected = false
+			if navigationHidden {
+				w.app.SetFocus(w.mediaView)
+				w.mediaViewSelected = true
+			} else {
+				w.app.SetFocus(w.mediaNav)
+			}
+			w.lastFocus.Blur()
+		} else if (w.mediaViewSelected || navigationHidden) && queueDocked {
+			w.lastFocus = w.mediaView
+			w.app.SetFocus(w.dockedQueue)
+			w.queueSelected = true
+			w.mediaViewSelected = false
+			if w.lastFocus != nil {
+				w.lastFocus.Blur()
+			}
+		} else if navigationHidden {
+			// navigation is hidden, keep focus in media view
+			return true
+		} else if w.mediaViewSelected {
 			w.lastFocus = w.mediaView
 			w.app.SetFocus(w.mediaNav)
 			if w.lastFocus != nil {
@@ -365,21 +920,75 @@ func (w *Window) moveCtrl(key tcell.Key) bool {
 	return false
 }
 
-func (w *Window) searchCb(query string) {
+// switchAccessibleFocus moves focus between navigation pane and media view in accessible mode.
+// Only the focused one is shown.
+func (w *Window) switchAccessibleFocus() {
+	if w.mediaView == nil {
+		return
+	}
+	if w.mediaNav.HasFocus() {
+		w.app.SetFocus(w.mediaView)
+		w.mediaViewSelected = true
+		w.notifyInfo(breadcrumbTitle(w.mediaView))
+	} else {
+		w.app.SetFocus(w.mediaNav)
+		w.mediaViewSelected = false
+		w.notifyInfo(i18n.T("Navigation"))
+	}
+	w.updateLayout()
+}
+
+func (w *Window) searchCb(input string) {
 	logrus.Debug("In search callback")
 	w.searchResultsTop.ClearResults()
 
-	for _, itemType := range config.AppConfig.Gui.SearchTypes {
-		items, err := w.mediaItems.Search(itemType, query)
-		if err == nil {
-			if len(items) > 0 {
-				w.searchResultsTop.addItems(itemType, items)
+	query, err := parseSearchQuery(input)
+	if err != nil {
+		w.notifyError("search", err)
+		return
+	}
+
+	types := query.types
+	if len(types) == 0 {
+		types = config.AppConfig.Gui.SearchTypes
+	}
+
+	w.requests.load("search", func(ctx context.Context) (func(), error) {
+		results := map[models.ItemType][]models.Item{}
+		for _, itemType := range types {
+			var items []models.Item
+			var err error
+			if query.text != "" {
+				items, err = w.mediaItems.Search(ctx, itemType, query.text)
+				items = query.filterYears(items)
+			} else if itemType == models.TypeAlbum && query.hasYears() {
+				// list all albums within years
+				opts := interfaces.DefaultQueryOpts()
+				opts.Filter.YearRange = query.years
+				var albums []*models.Album
+				albums, _, err = w.mediaItems.GetAlbums(ctx, opts)
+				items = models.AlbumsToItems(albums)
+			} else {
+				continue
+			}
+
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			} else if err != nil {
+				w.notifyError(fmt.Sprintf("search items of type %s", itemType), err)
+			} else {
+				results[itemType] = items
 			}
-		} else {
-			logrus.Errorf("search items of type %s: %v", itemType, err)
 		}
-	}
-	w.searchResultsTop.ResultsReady()
+		return func() {
+			for _, itemType := range types {
+				if items := results[itemType]; len(items) > 0 {
+					w.searchResultsTop.addItems(itemType, items)
+				}
+			}
+			w.searchResultsTop.ResultsReady()
+		}, nil
+	})
 }
 
 func (w *Window) showSearchResults(itemType models.ItemType, results []models.Item, query string) {
@@ -397,7 +1006,7 @@ func (w *Window) showSearchResults(itemType models.ItemType, results []models.It
 		w.albumList.EnablePaging(false)
 		w.albumList.EnableFilter(false)
 		w.albumList.EnableSorting(false)
-		w.albumList.SetLabel(fmt.Sprintf("[yellow::]Search results for '%s'[-::]\n%d albums", query, len(results)))
+		w.albumList.SetLabel(i18n.Tf("[yellow::]Search results for '%s'[-::]\n%d albums", query, len(results)))
 		w.albumList.SetAlbums(albums)
 		w.albumList.EnableSimilar(false)
 	case models.TypeArtist:
@@ -407,7 +1016,7 @@ func (w *Window) showSearchResults(itemType models.ItemType, results []models.It
 		for i, v := range results {
 			artists[i], _ = v.(*models.Artist)
 		}
-		w.artistList.SetText(fmt.Sprintf("[yellow::]Search results: for '%s'[-::]\n%d artists", query, len(artists)))
+		w.artistList.SetText(i18n.Tf("[yellow::]Search results: for '%s'[-::]\n%d artists", query, len(artists)))
 		w.artistList.Clear()
 		w.artistList.EnablePaging(false)
 		w.artistList.SetArtists(artists)
@@ -419,8 +1028,9 @@ func (w *Window) showSearchResults(itemType models.ItemType, results []models.It
 			songs[i], _ = v.(*models.Song)
 		}
 
+		w.songs.EnableSorting(false)
 		w.songs.SetSongs(songs, interfaces.DefaultPaging())
-		w.songs.description.SetText(fmt.Sprintf("[yellow::]Search results for '%s'[-::]\n%d songs", query, len(songs)))
+		w.songs.description.SetText(i18n.Tf("[yellow::]Search results for '%s'[-::]\n%d songs", query, len(songs)))
 	case models.TypePlaylist:
 		view = w.playlists
 		playlists := make([]*models.Playlist, len(results))
@@ -429,11 +1039,11 @@ func (w *Window) showSearchResults(itemType models.ItemType, results []models.It
 			playlists[i], _ = v.(*models.Playlist)
 		}
 		w.playlists.SetPlaylists(playlists)
-		w.playlists.description.SetText(fmt.Sprintf("[yellow::]Search results for '%s'[-::]\n%d playlists", query, len(playlists)))
+		w.playlists.description.SetText(i18n.Tf("[yellow::]Search results for '%s'[-::]\n%d playlists", query, len(playlists)))
 	case models.TypeGenre:
 		view = w.genres
 		genres := make([]*models.IdName, len(results))
-		w.genres.description.SetText(fmt.Sprintf("[yellow::]Search results for '%s'[-::]\n%d genres", query, len(genres)))
+		w.genres.description.SetText(i18n.Tf("[yellow::]Search results for '%s'[-::]\n%d genres", query, len(genres)))
 		w.genres.setGenres(genres)
 	}
 
@@ -495,268 +1105,796 @@ func (w *Window) showModal(modal modal.Modal, height, width uint, lockSize bool)
 	}
 }
 
+// statusCb receives player status. Updates are batched and drawn only if visible status changes.
 func (w *Window) statusCb(state interfaces.AudioStatus) {
+	w.statusRefresh.update(state)
+}
+
+func (w *Window) drawStatus(state interfaces.AudioStatus) {
 	w.status.UpdateState(state, nil)
+	if accessible {
+		if text := statusAnnouncement(w.announcedStatus, state); text != "" {
+			w.notification.show(notificationInfo, text)
+		}
+		w.announcedStatus = state
+	}
+	var id models.Id
+	if state.Song != nil && state.State != interfaces.AudioStateStopped {
+		id = state.Song.Id
+	}
+	w.app.QueueUpdateDraw(func() {
+		w.setPlayingSong(id)
+	})
+}
+
+// setPlayingSong marks song as playing in views that show songs.
+func (w *Window) setPlayingSong(id models.Id) {
+	if id == w.playingSong {
+		return
+	}
+	w.playingSong = id
+	for _, v := range []playingMarker{w.album, w.playlist, w.songs} {
+		v.setPlayingSong(id)
+	}
+	if id != "" {
+		// previous song is now played
+		w.refreshRecentSongs()
+		w.refreshHistory()
+	}
+}
+
+func (w *Window) playerErrorCb(err error) {
+	w.notification.show(notificationError, limitNotification(err.Error()))
 	w.app.QueueUpdateDraw(func() {})
 }
 
-func (w *Window) InitBrowser(items []models.Item) {
-	w.app.Draw()
+// notifyInfo shows info message in notification bar.
+func (w *Window) notifyInfo(msg string) {
+	w.notification.show(notificationInfo, msg)
+	w.app.QueueUpdateDraw(func() {})
 }
 
-func (w *Window) selectMedia(m MediaSelect) {
-	switch m {
-	case MediaLatestMusic:
-		albums, err := w.mediaItems.GetLatestAlbums()
-		if err != nil {
-			logrus.Errorf("get favorite artists: %v", err)
-		} else {
-			duration := 0
-			for _, v := range albums {
-				duration += v.Duration
-			}
-			w.mediaNav.SetCount(MediaLatestMusic, len(albums))
-			w.latestAlbums.description.SetText(fmt.Sprintf("Latest albums\nCount: %d", len(albums)))
+// notifyError logs error and shows it in notification bar.
+// showExport shows modal for exporting songs to playlist file with given name.
+func (w *Window) showExport(name string, songs []*models.Song) {
+	if w.export == nil {
+		w.notifyError("export playlist", interfaces.ErrExportNotSupported)
+		return
+	}
+	if len(songs) == 0 {
+		w.notifyInfo(i18n.T("No songs to export"))
+		return
+	}
+	w.export.SetSongs(name, songs)
+	w.showModal(w.export, 9, 60, false)
+}
 
-			w.latestAlbums.EnableFilter(false)
-			w.latestAlbums.EnableSorting(false)
-			w.latestAlbums.EnablePaging(false)
+func (w *Window) playlistExported(file string, songs int) {
+	w.notifyInfo(i18n.Tf("Exported %d songs to %s", songs, file))
+}
 
-			w.latestAlbums.Clear()
-			w.latestAlbums.SetAlbums(albums)
-			w.setViewWidget(w.latestAlbums, true)
-		}
-	case MediaFavoriteArtists:
-		artists, err := w.mediaItems.GetFavoriteArtists()
-		if err != nil {
-			logrus.Errorf("get favorite artists: %v", err)
-		} else {
-			w.artistList.Clear()
-			w.artistList.SetText("Favorite artists")
-			w.artistList.EnablePaging(false)
-			w.mediaNav.SetCount(MediaFavoriteArtists, len(artists))
-			w.artistList.SetArtists(artists)
-			w.setViewWidget(w.artistList, true)
+// showChapters shows modal for jumping to a chapter of playing song.
+func (w *Window) showChapters() {
+	if w.chapters == nil {
+		w.notifyError("list chapters", interfaces.ErrChaptersNotSupported)
+		return
+	}
+	if !w.chapters.hasChapters() {
+		w.notifyInfo(i18n.T("Playing song has no chapters"))
+		return
+	}
+	w.showModal(w.chapters, 7, 60, false)
+}
+
+// changeChapter moves to next or previous chapter of playing song.
+func (w *Window) changeChapter(next bool) {
+	if w.chapters == nil {
+		w.notifyError("change chapter", interfaces.ErrChaptersNotSupported)
+		return
+	}
+	var chapter *models.Chapter
+	if next {
+		chapter = w.chapters.controller.NextChapter()
+	} else {
+		chapter = w.chapters.controller.PreviousChapter()
+	}
+	if chapter == nil {
+		if !w.chapters.hasChapters() {
+			w.notifyInfo(i18n.T("Playing song has no chapters"))
 		}
-	case MediaPlaylists:
-		playlists, err := w.mediaItems.GetPlaylists()
+		return
+	}
+	w.notifyInfo(i18n.Tf("Chapter: %s", chapter.Name))
+}
+
+func (w *Window) gainSaved(item models.Item, offset int) {
+	if offset == 0 {
+		w.notifyInfo(i18n.Tf("Removed volume offset of %s", item.GetName()))
+		return
+	}
+	w.notifyInfo(i18n.Tf("Volume offset of %s set to %+d dB", item.GetName(), offset))
+}
+
+// showImport shows modal for creating playlist from file.
+func (w *Window) showImport() {
+	if w.importer == nil {
+		w.notifyError("import playlist", interfaces.ErrImportNotSupported)
+		return
+	}
+	w.showModal(w.importer, 9, 60, false)
+}
+
+func (w *Window) playlistImportStarted(file string) {
+	w.notifyInfo(i18n.Tf("Importing %s", file))
+}
+
+// playlistImported shows songs that were not found and refreshes playlists.
+func (w *Window) playlistImported(result *models.PlaylistImport, err error) {
+	w.app.QueueUpdateDraw(func() {
 		if err != nil {
-			logrus.Errorf("get playlists: %v", err)
-		} else {
-			w.mediaNav.SetCount(MediaPlaylists, len(playlists))
-			w.playlists.SetPlaylists(playlists)
-			w.setViewWidget(w.playlists, true)
-		}
-	case MediaSongs, MediaRecent:
-		page := interfaces.DefaultPaging()
-		var songs []*models.Song
-		var count int
-		var err error
-
-		if m == MediaSongs {
-			songs, count, err = w.mediaItems.GetSongs(0, page.PageSize)
-			if err != nil {
-				logrus.Errorf("get songs: %v", err)
-			} else {
-				w.songs.showPage = w.selectSongs
-				w.mediaNav.SetCount(m, count)
-				w.songs.setTitle("All songs")
-			}
+			w.notifyError("import playlist", err)
 		} else {
-			songs, count, err = w.mediaItems.GetRecentlyPlayed(page)
-			if err != nil {
-				logrus.Errorf("get songs: %v", err)
-			} else {
-				w.songs.showPage = w.showRecentSongsPage
-				w.songs.setTitle("Recently played")
-				if !config.LimitRecentlyPlayed {
-					w.mediaNav.SetCount(m, count)
-				}
+			w.notifyInfo(i18n.Tf("Created playlist '%s' with %d songs", result.Playlist.Name,
+				result.Playlist.SongCount))
+			if w.mediaSelected && w.selectedMedia == MediaPlaylists {
+				w.selectMedia(MediaPlaylists)
 			}
 		}
-		page.SetTotalItems(count)
-		w.songs.SetSongs(songs, page)
-
-		w.setViewWidget(w.songs, true)
-	case MediaArtists, MediaAlbumArtists:
-		paging := interfaces.DefaultPaging()
-		opts := interfaces.DefaultQueryOpts()
-		var artists []*models.Artist
-		var err error
-		var total int
-		var title string
-		if m == MediaArtists {
-			title = "All artists"
-			artists, total, err = w.mediaItems.GetArtists(opts)
-		} else if m == MediaAlbumArtists {
-			title = "All album artists"
-			artists, total, err = w.mediaItems.GetAlbumArtists(paging)
-		}
-		if err != nil {
-			logrus.Errorf("get all artists: %v", err)
-			return
+		if result != nil && len(result.Unmatched) > 0 {
+			text := i18n.Tf("%d songs were not found:\n\n%s", len(result.Unmatched),
+				strings.Join(result.Unmatched, "\n"))
+			w.showText(i18n.T("Import playlist"), text)
 		}
-		paging.SetTotalItems(total)
-		w.mediaNav.SetCount(m, total)
-
-		w.artistList.Clear()
-		w.artistList.EnablePaging(true)
-		w.artistList.SetPage(paging)
+	})
+}
 
-		w.artistList.SetArtists(artists)
-		w.setViewWidget(w.artistList, true)
-		w.artistList.SetText(fmt.Sprintf("%s: %d", title, paging.TotalItems))
-	case MediaAlbums, MediaFavoriteAlbums:
-		paging := interfaces.DefaultPaging()
-		opts := interfaces.DefaultQueryOpts()
-		var albums []*models.Album
-		var err error
-		var total int
-		var title string
+// settingsChanged notifies user after settings have been applied and saves them if requested.
+func (w *Window) settingsChanged(save, themeChanged bool) {
+	msg := i18n.T("Settings applied")
+	if save {
+		w.saveLayout()
+		msg = i18n.T("Settings saved")
+	}
+	if themeChanged {
+		msg += i18n.T(", theme changes after restart")
+	}
+	w.notifyInfo(msg)
+}
 
-		list := w.albumList
+// libraryChanged reloads current view from the new library.
+func (w *Window) libraryChanged(name string) {
+	w.notifyInfo(i18n.Tf("Using %s", name))
+	if w.mediaSelected {
+		w.selectMedia(w.selectedMedia)
+	}
+}
 
-		if m == MediaAlbums {
-			albums, total, err = w.mediaItems.GetAlbums(opts)
-			title = "All Albums"
-			w.albumList.EnablePaging(true)
-			w.albumList.EnableFilter(true)
-			w.albumList.EnableSorting(true)
-		} else if m == MediaFavoriteAlbums {
-			paging.PageSize = 200
-			albums, total, err = w.mediaItems.GetFavoriteAlbums(paging)
-			title = "Favorite albums"
-			w.albumList.EnablePaging(false)
-			w.albumList.EnableFilter(false)
-			w.albumList.EnableSorting(false)
-			list = w.favoriteAlbums
+// setCastTarget sets session to play on. Nil session plays on this device.
+func (w *Window) setCastTarget(session *models.Session) {
+	w.castTarget = session
+	if session == nil {
+		w.status.setCastTarget("")
+		w.notifyInfo(i18n.T("Playing on this device"))
+	} else {
+		w.status.setCastTarget(session.DeviceName)
+		w.notifyInfo(i18n.Tf("Casting to %s", session.DeviceName))
+	}
+}
+
+// castSongs sends songs to target session. If playNow, target starts playing songs immediately,
+// else they are added to target's queue.
+func (w *Window) castSongs(target *models.Session, songs []*models.Song, playNow bool) {
+	controller, ok := w.mediaPlayer.(interfaces.SessionController)
+	if !ok {
+		return
+	}
+	ids := make([]models.Id, len(songs))
+	for i, v := range songs {
+		ids[i] = v.Id
+	}
+	err := controller.PlaySessionSongs(target.Id, ids, playNow)
+	if err != nil {
+		w.notifyError("cast songs", err)
+		return
+	}
+	w.notifyInfo(i18n.Tf("Sent %d songs to %s", len(songs), target.DeviceName))
+}
+
+func (w *Window) sendCastCommand(target *models.Session, command interfaces.SessionCommand) {
+	controller, ok := w.mediaPlayer.(interfaces.SessionController)
+	if !ok {
+		return
+	}
+	err := controller.SendSessionCommand(target.Id, command)
+	if err != nil {
+		w.notifyError("cast command", err)
+	}
+}
+
+// castCommand returns session command for global key binding, if there is one.
+func castCommand(key tcell.Key) (interfaces.SessionCommand, bool) {
+	ctrls := config.KeyBinds.Global
+	switch key {
+	case ctrls.PlayPause:
+		return interfaces.SessionPlayPause, true
+	case ctrls.Stop:
+		return interfaces.SessionStop, true
+	case ctrls.Next:
+		return interfaces.SessionNext, true
+	case ctrls.Previous:
+		return interfaces.SessionPrevious, true
+	case ctrls.VolumeUp:
+		return interfaces.SessionVolumeUp, true
+	case ctrls.VolumeDown:
+		return interfaces.SessionVolumeDown, true
+	case ctrls.MuteUnmute:
+		return interfaces.SessionToggleMute, true
+	}
+	return "", false
+}
+
+// syncPlayCb updates SyncPlay group to status bar and notifies when group is joined or left.
+func (w *Window) syncPlayCb(group *models.SyncPlayGroup) {
+	w.app.QueueUpdateDraw(func() {
+		previous := w.status.group
+		w.status.setGroup(group)
+		if group != nil && (previous == nil || previous.Id != group.Id) {
+			w.notifyInfo(i18n.Tf("Joined SyncPlay group '%s'", group.Name))
+		} else if group == nil && previous != nil {
+			w.notifyInfo(i18n.Tf("Left SyncPlay group '%s'", previous.Name))
 		}
+	})
+}
 
-		if err != nil {
-			logrus.Errorf("get %s: %v", title, err)
-			return
+// libraryWidget returns widget that shows media selection m, if m depends on library contents.
+func (w *Window) libraryWidget(m MediaSelect) Previous {
+	switch m {
+	case MediaRecentlyAdded, MediaRecentlyReleased:
+		return w.latestAlbums
+	case MediaArtists, MediaAlbumArtists:
+		return w.artistList
+	case MediaAlbums:
+		return w.albumList
+	case MediaSongs:
+		return w.songs
+	}
+	return nil
+}
+
+// libraryContentChanged reloads visible library view and recently added count after server
+// has reported new or removed items.
+func (w *Window) libraryContentChanged() {
+	w.app.QueueUpdateDraw(func() {
+		visible := w.mediaSelected && w.mediaView != nil && w.mediaView == w.libraryWidget(w.selectedMedia)
+		if visible {
+			w.selectMedia(w.selectedMedia)
+		}
+		if !visible || w.selectedMedia != MediaRecentlyAdded {
+			albums, err := w.mediaItems.GetLatestAlbums(context.Background())
+			if err != nil {
+				logrus.Errorf("get latest albums: %v", err)
+				return
+			}
+			w.mediaNav.SetCount(MediaRecentlyAdded, len(albums))
 		}
-		paging.SetTotalItems(total)
-		w.mediaNav.SetCount(m, total)
+	})
+}
+
+// connectionCb shows banner while server is offline.
+func (w *Window) connectionCb(online bool) {
+	if online {
+		w.notification.setBanner("")
+		w.notifyInfo(i18n.T("Connection to server restored"))
+	} else {
+		w.notification.setBanner(i18n.T("Server offline, reconnecting..."))
+		w.app.QueueUpdateDraw(func() {})
+	}
+}
 
-		list.SetPage(paging)
-		list.Clear()
-		list.EnableSimilar(false)
+func (w *Window) notifyError(action string, err error) {
+	logrus.Errorf("%s: %v", action, err)
+	w.notification.show(notificationError, limitNotification(fmt.Sprintf("%s: %v", action, err)))
+	w.app.QueueUpdateDraw(func() {})
+}
 
-		list.SetText(fmt.Sprintf("%s\nTotal %v", title, paging.TotalItems))
-		list.SetAlbums(albums)
-		w.setViewWidget(list, true)
+func (w *Window) InitBrowser(items []models.Item) {
+	w.app.Draw()
+}
+
+func (w *Window) selectMedia(m MediaSelect) {
+	w.selectedMedia = m
+	w.mediaSelected = true
+	w.saveLastView(m)
+	switch m {
+	case MediaRecentlyAdded, MediaRecentlyReleased:
+		w.requests.load("get latest albums", func(ctx context.Context) (func(), error) {
+			var albums []*models.Album
+			var err error
+			title := i18n.T("Recently added albums")
+			if m == MediaRecentlyReleased {
+				title = i18n.T("Recently released albums")
+				albums, err = w.mediaItems.GetRecentlyReleasedAlbums(ctx)
+			} else {
+				albums, err = w.mediaItems.GetLatestAlbums(ctx)
+			}
+			return func() {
+				w.mediaNav.SetCount(m, len(albums))
+				w.latestAlbums.description.SetText(i18n.Tf("%s\nCount: %d", title, len(albums)))
+
+				w.latestAlbums.EnableFilter(false)
+				w.latestAlbums.EnableSorting(false)
+				w.latestAlbums.EnablePaging(false)
+
+				w.latestAlbums.Clear()
+				w.latestAlbums.SetAlbums(albums)
+				w.setViewWidget(w.latestAlbums, true)
+			}, err
+		})
+	case MediaFavoriteArtists:
+		w.requests.load("get favorite artists", func(ctx context.Context) (func(), error) {
+			artists, err := w.mediaItems.GetFavoriteArtists(ctx)
+			return func() {
+				w.artistList.Clear()
+				w.artistList.SetText(i18n.T("Favorite artists"))
+				w.artistList.EnablePaging(false)
+				w.mediaNav.SetCount(MediaFavoriteArtists, len(artists))
+				w.artistList.SetArtists(artists)
+				w.setViewWidget(w.artistList, true)
+			}, err
+		})
+	case MediaPlaylists:
+		w.requests.load("get playlists", func(ctx context.Context) (func(), error) {
+			playlists, err := w.mediaItems.GetPlaylists(ctx)
+			return func() {
+				w.mediaNav.SetCount(MediaPlaylists, len(playlists))
+				w.playlists.SetPlaylists(playlists)
+				w.setViewWidget(w.playlists, true)
+			}, err
+		})
+	case MediaSongs:
+		w.songs.queryOpts.Filter = interfaces.Filter{}
+		opts := interfaces.DefaultQueryOpts()
+		opts.Sort = w.songs.queryOpts.Sort
+		w.requests.load("get songs", func(ctx context.Context) (func(), error) {
+			songs, count, err := w.mediaItems.GetSongs(ctx, opts)
+			return func() {
+				page := interfaces.DefaultPaging()
+				page.SetTotalItems(count)
+				w.songs.showPage = w.selectSongs
+				w.songs.recent = false
+				w.songs.EnableSorting(true)
+				w.mediaNav.SetCount(m, count)
+				w.songs.setTitle(i18n.T("All songs"))
+				w.songs.SetSongs(songs, page)
+				w.setViewWidget(w.songs, true)
+			}, err
+		})
+	case MediaRecent:
+		w.requests.load("get songs", func(ctx context.Context) (func(), error) {
+			page := interfaces.DefaultPaging()
+			songs, count, err := w.mediaItems.GetRecentlyPlayed(ctx, page)
+			return func() {
+				page.SetTotalItems(count)
+				w.songs.showPage = w.showRecentSongsPage
+				w.songs.recent = true
+				w.songs.EnableSorting(false)
+				w.songs.setTitle(i18n.T("Recently played"))
+				if !config.LimitRecentlyPlayed {
+					w.mediaNav.SetCount(m, count)
+				}
+				w.songs.SetSongs(songs, page)
+				w.setViewWidget(w.songs, true)
+			}, err
+		})
+	case MediaArtists, MediaAlbumArtists:
+		opts := interfaces.DefaultQueryOpts()
+		opts.Sort = w.artistList.queryOpts.Sort
+		w.requests.load("get all artists", func(ctx context.Context) (func(), error) {
+			paging := interfaces.DefaultPaging()
+			var artists []*models.Artist
+			var err error
+			var total int
+			var title string
+			if m == MediaArtists {
+				title = i18n.T("All artists")
+				artists, total, err = w.mediaItems.GetArtists(ctx, opts)
+			} else if m == MediaAlbumArtists {
+				title = i18n.T("All album artists")
+				artists, total, err = w.mediaItems.GetAlbumArtists(ctx, paging)
+			}
+			return func() {
+				paging.SetTotalItems(total)
+				w.mediaNav.SetCount(m, total)
+
+				w.artistList.Clear()
+				w.artistList.SetFilter(interfaces.Filter{})
+				w.artistList.EnablePaging(true)
+				w.artistList.SetPage(paging)
+
+				w.artistList.SetArtists(artists)
+				w.setViewWidget(w.artistList, true)
+				w.artistList.SetText(fmt.Sprintf("%s: %d", title, paging.TotalItems))
+			}, err
+		})
+	case MediaAlbums, MediaFavoriteAlbums:
+		opts := interfaces.DefaultQueryOpts()
+		opts.Sort = w.albumList.queryOpts.Sort
+		title := i18n.N("All Albums")
+		if m == MediaFavoriteAlbums {
+			title = i18n.N("Favorite albums")
+		}
+		w.requests.load("get "+title, func(ctx context.Context) (func(), error) {
+			paging := interfaces.DefaultPaging()
+			var albums []*models.Album
+			var err error
+			var total int
+			if m == MediaAlbums {
+				albums, total, err = w.mediaItems.GetAlbums(ctx, opts)
+			} else if m == MediaFavoriteAlbums {
+				paging.PageSize = 200
+				albums, total, err = w.mediaItems.GetFavoriteAlbums(ctx, paging)
+			}
+			return func() {
+				list := w.albumList
+				if m == MediaAlbums {
+					w.albumList.SetFilter(interfaces.Filter{})
+					w.albumList.EnablePaging(true)
+					w.albumList.EnableFilter(true)
+					w.albumList.EnableSorting(true)
+				} else if m == MediaFavoriteAlbums {
+					w.albumList.EnablePaging(false)
+					w.albumList.EnableFilter(false)
+					w.albumList.EnableSorting(false)
+					list = w.favoriteAlbums
+				}
+
+				paging.SetTotalItems(total)
+				w.mediaNav.SetCount(m, total)
+
+				list.SetPage(paging)
+				list.Clear()
+				list.EnableSimilar(false)
+
+				list.SetText(i18n.Tf("%s\nTotal %v", i18n.T(title), paging.TotalItems))
+				list.SetAlbums(albums)
+				w.setViewWidget(list, true)
+			}, err
+		})
 	case MediaGenres:
 		paging := interfaces.DefaultPaging()
 		w.showGenrePage(paging)
+	case MediaComposers:
+		paging := interfaces.DefaultPaging()
+		w.showComposerPage(paging)
+	case MediaPodcasts:
+		w.showPodcasts()
+	case MediaDownloads:
+		w.showDownloads()
+	case MediaDuplicates:
+		w.showDuplicates()
 	}
 }
 
-func (w *Window) selectArtist(artist *models.Artist) {
-	albums, err := w.mediaItems.GetArtistAlbums(artist.Id)
+// showDuplicates searches duplicate songs in background, since it needs to go through all songs.
+func (w *Window) showDuplicates() {
+	w.notifyInfo(i18n.T("Searching duplicate songs..."))
+	go func() {
+		groups, err := w.mediaItems.GetDuplicateSongs(context.Background())
+		w.app.QueueUpdateDraw(func() {
+			if err != nil {
+				w.notifyError("get duplicates", err)
+				return
+			}
+			count := 0
+			for _, v := range groups {
+				count += len(v)
+			}
+			w.mediaNav.SetCount(MediaDuplicates, count)
+			w.duplicates.SetGroups(groups)
+			if w.mediaSelected && w.selectedMedia == MediaDuplicates {
+				w.setViewWidget(w.duplicates, true)
+			}
+		})
+	}()
+}
+
+func (w *Window) favoriteSong(song *models.Song) error {
+	controller, ok := w.mediaPlayer.(interfaces.FavoriteController)
+	if !ok {
+		w.notifyError("favorite", interfaces.ErrFavoritesNotSupported)
+		return interfaces.ErrFavoritesNotSupported
+	}
+	err := controller.SetFavorite(song, true)
 	if err != nil {
-		logrus.Errorf("get albumList albums: %v", err)
-	} else {
-		artist.AlbumCount = len(albums)
-		w.artistAlbumList.Clear()
-		w.artistAlbumList.EnablePaging(false)
-		w.artistAlbumList.EnableSimilar(true)
-		w.artistAlbumList.SetArtist(artist)
-		w.artistAlbumList.SetAlbums(albums)
-		w.setViewWidget(w.artistAlbumList, true)
+		w.notifyError("favorite", err)
+		return err
 	}
+	w.notifyInfo(i18n.Tf("Added '%s' to favorites", song.Name))
+	return nil
 }
 
-func (w *Window) selectAlbum(album *models.Album) {
-	songs, err := w.mediaItems.GetAlbumSongs(album.Id)
+func (w *Window) showDownloads() {
+	if w.downloadController == nil {
+		w.notifyError("get downloads", interfaces.ErrDownloadsNotSupported)
+		return
+	}
+	downloads := w.downloadController.GetDownloads()
+	w.mediaNav.SetCount(MediaDownloads, len(downloads))
+	w.downloads.SetDownloads(downloads)
+	w.setViewWidget(w.downloads, true)
+}
+
+// downloadsChanged refreshes downloads view, if it's visible. It is called from player goroutine.
+func (w *Window) downloadsChanged() {
+	w.app.QueueUpdateDraw(func() {
+		downloads := w.downloadController.GetDownloads()
+		w.mediaNav.SetCount(MediaDownloads, len(downloads))
+		if w.mediaView == w.downloads {
+			w.downloads.SetDownloads(downloads)
+		}
+	})
+}
+
+func (w *Window) removeDownload(song *models.Song) {
+	err := w.downloadController.RemoveDownload(song.Id)
 	if err != nil {
-		logrus.Errorf("get album songs: %v", err)
-	} else {
-		for _, v := range songs {
-			v.AlbumArtist = album.Artist
+		w.notifyError("remove download", err)
+		return
+	}
+	w.notifyInfo(i18n.Tf("Removed '%s' from downloads", song.Name))
+}
+
+func (w *Window) showPodcasts() {
+	if w.podcastController == nil {
+		w.notifyError("get podcasts", interfaces.ErrPodcastsNotSupported)
+		return
+	}
+	podcasts, err := w.podcastController.GetPodcasts()
+	if err != nil {
+		w.notifyError("get podcasts", err)
+		return
+	}
+	w.mediaNav.SetCount(MediaPodcasts, len(podcasts))
+	w.podcasts.Clear()
+	w.podcasts.EnableFilter(false)
+	w.podcasts.EnableSorting(false)
+	w.podcasts.SetText(i18n.Tf("Podcasts\nTotal %d", len(podcasts)))
+	w.podcasts.SetAlbums(podcasts)
+	w.setViewWidget(w.podcasts, true)
+}
+
+func (w *Window) selectPodcast(podcast *models.Album) {
+	episodes, err := w.podcastController.GetEpisodes(podcast.Id)
+	if err != nil {
+		w.notifyError("get podcast episodes", err)
+		return
+	}
+	// episodes are not paged
+	page := interfaces.DefaultPaging()
+	page.TotalItems = len(episodes)
+	page.TotalPages = 1
+	w.episodes.setTitle(podcast.Name)
+	w.episodes.SetSongs(episodes, page)
+	w.setViewWidget(w.episodes, true)
+}
+
+func (w *Window) setEpisodePlayed(episode *models.Song, played bool) error {
+	err := w.podcastController.SetPlayed(episode.Id, played)
+	if err != nil {
+		w.notifyError("mark episode played", err)
+	}
+	return err
+}
+
+func (w *Window) selectArtist(artist *models.Artist) {
+	w.requests.load("get artist albums", func(ctx context.Context) (func(), error) {
+		albums, err := w.mediaItems.GetArtistAlbums(ctx, artist.Id)
+		if err != nil {
+			return nil, err
 		}
 
-		artist, err := w.mediaItems.GetAlbumArtist(album)
+		// rest of the artist page is optional, show artist even if it fails
+		appearsOn, err := w.mediaItems.GetArtistAppearsOn(ctx, artist.Id)
 		if err != nil {
-			logrus.Errorf("get album artist: %v", err)
-		} else {
-			w.album.SetArtist(artist)
+			logrus.Errorf("get artist appears on: %v", err)
+		}
+		topSongs, err := w.mediaItems.GetArtistTopSongs(ctx, artist, artistTopSongs)
+		if err != nil {
+			logrus.Errorf("get artist top songs: %v", err)
+		}
+		overview, err := w.mediaItems.GetArtistOverview(ctx, artist)
+		if err != nil {
+			logrus.Errorf("get artist overview: %v", err)
 		}
 
-		w.album.SetAlbum(album, songs)
-		w.setViewWidget(w.album, true)
-	}
+		return func() {
+			artist.AlbumCount = len(albums)
+			w.setViewContent(w.artistView, func() {
+				w.artistView.SetArtist(artist, overview, topSongs, albums, appearsOn)
+			})
+			w.setViewWidget(w.artistView, true)
+		}, nil
+	})
 }
 
-func (w *Window) selectPlaylist(playlist *models.Playlist) {
-	err := w.mediaItems.GetPlaylistSongs(playlist)
+// showItemInfo shows details of highlighted song or album in current view.
+func (w *Window) showItemInfo() {
+	view, ok := w.mediaView.(itemHighlighter)
+	if !ok {
+		return
+	}
+	item := view.highlightedItem()
+	if item == nil {
+		return
+	}
+
+	info, err := w.mediaItems.GetItemInfo(context.Background(), item)
 	if err != nil {
-		logrus.Warningf("did not get playlist songs: %v", err)
+		w.notifyError("get item info", err)
 		return
 	}
+	w.showText(info.Name, formatItemInfo(info))
+}
 
-	w.playlist.SetPlaylist(playlist)
-	w.setViewWidget(w.playlist, true)
+// showText shows text with title in a message window.
+func (w *Window) showText(title, text string) {
+	w.showMessage(text, 25, 80, false)
+	w.message.SetTitle(title)
 }
 
-func (w *Window) selectSongs(page interfaces.Paging) {
-	songs, _, err := w.mediaItems.GetSongs(page.CurrentPage, page.PageSize)
+func (w *Window) selectAlbum(album *models.Album) {
+	w.requests.load("get album songs", func(ctx context.Context) (func(), error) {
+		return w.loadAlbum(ctx, album)
+	})
+}
+
+// loadAlbum requests songs and details of album, and returns function that shows album.
+func (w *Window) loadAlbum(ctx context.Context, album *models.Album) (func(), error) {
+	songs, err := w.mediaItems.GetAlbumSongs(ctx, album.Id)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range songs {
+		v.AlbumArtist = album.Artist
+	}
+
+	// overview, genres and disc titles are optional
+	details, err := w.mediaItems.GetAlbum(ctx, album.Id)
 	if err != nil {
-		logrus.Errorf("get songs: %v", err)
+		logrus.Errorf("get album details: %v", err)
 	}
 
-	w.songs.SetSongs(songs, page)
-	w.setViewWidget(w.songs, true)
+	artist, err := w.mediaItems.GetAlbumArtist(ctx, album)
+	if err != nil && ctx.Err() == nil {
+		w.notifyError("get album artist", err)
+	}
+
+	return func() {
+		if details != nil {
+			album.Overview = details.Overview
+			album.Genres = details.Genres
+			album.DiscTitles = details.DiscTitles
+		}
+		w.setViewContent(w.album, func() {
+			if artist != nil {
+				w.album.SetArtist(artist)
+			}
+			w.album.SetAlbum(album, songs)
+		})
+		w.setViewWidget(w.album, true)
+	}, nil
+}
+
+func (w *Window) selectPlaylist(playlist *models.Playlist) {
+	w.requests.load("get playlist songs", func(ctx context.Context) (func(), error) {
+		// playlist is shown in ui, songs are set to it in ui thread
+		loaded := *playlist
+		err := w.mediaItems.GetPlaylistSongs(ctx, &loaded)
+		return func() {
+			playlist.Songs = loaded.Songs
+			w.setViewContent(w.playlist, func() {
+				w.playlist.SetPlaylist(playlist)
+			})
+			w.setViewWidget(w.playlist, true)
+		}, err
+	})
+}
+
+func (w *Window) selectSongs(page interfaces.Paging) {
+	opts := *w.songs.queryOpts
+	opts.Paging = page
+	w.requests.load("get songs", func(ctx context.Context) (func(), error) {
+		songs, total, err := w.mediaItems.GetSongs(ctx, &opts)
+		return func() {
+			page.SetTotalItems(total)
+			w.songs.SetSongs(songs, page)
+			w.setViewWidget(w.songs, true)
+		}, err
+	})
 }
 
 func (w *Window) showRecentSongsPage(page interfaces.Paging) {
-	songs, _, err := w.mediaItems.GetRecentlyPlayed(page)
+	w.requests.load("get songs", func(ctx context.Context) (func(), error) {
+		songs, _, err := w.mediaItems.GetRecentlyPlayed(ctx, page)
+		return func() {
+			w.songs.SetSongs(songs, page)
+			w.setViewWidget(w.songs, true)
+		}, err
+	})
+}
+
+// refreshRecentSongs reloads recently played songs if they are visible, keeping selection.
+func (w *Window) refreshRecentSongs() {
+	if w.mediaView != w.songs || !w.songs.recent {
+		return
+	}
+	index := w.songs.list.GetSelectedIndex()
+	songs, _, err := w.mediaItems.GetRecentlyPlayed(context.Background(), w.songs.page)
 	if err != nil {
-		logrus.Errorf("get songs: %v", err)
+		w.notifyError("get songs", err)
 	}
+	w.songs.SetSongs(songs, w.songs.page)
+	w.songs.list.SetSelected(index)
+}
 
-	w.songs.SetSongs(songs, page)
-	w.setViewWidget(w.songs, true)
+// historyPaging returns paging for latest plays shown in history.
+func historyPaging() interfaces.Paging {
+	return interfaces.Paging{PageSize: 100}
 }
 
-func (w *Window) showArtistPage(page interfaces.Paging) {
-	opts := interfaces.DefaultQueryOpts()
-	opts.Paging = page
-	artists, _, err := w.mediaItems.GetArtists(opts)
-	if err != nil {
-		logrus.Errorf("get albumList page: %v", err)
+// refreshHistory reloads history from local play history if it is visible, keeping selection.
+func (w *Window) refreshHistory() {
+	if w.mediaView != w.history || !config.AppConfig.Player.EnableHistoryDb {
 		return
 	}
+	index := w.history.list.GetSelectedIndex()
+	songs, _, err := w.mediaItems.GetPlayHistory(context.Background(), historyPaging())
+	if err != nil {
+		w.notifyError("get history", err)
+	}
+	w.history.SetSongs(songs)
+	w.history.list.SetSelected(index)
+}
 
-	w.artistList.Clear()
-	w.artistList.SetArtists(artists)
-	w.artistList.EnablePaging(true)
-	w.setViewWidget(w.artistList, false)
+func (w *Window) showArtistPage(page interfaces.Paging) {
+	opts := interfaces.DefaultQueryOpts()
+	opts.Paging = page
+	w.requests.load("get artists page", func(ctx context.Context) (func(), error) {
+		artists, _, err := w.mediaItems.GetArtists(ctx, opts)
+		return func() {
+			w.artistList.Clear()
+			w.artistList.SetArtists(artists)
+			w.artistList.EnablePaging(true)
+			w.setViewWidget(w.artistList, false)
+		}, err
+	})
 }
 
 func (w *Window) queryArtists(opts *interfaces.QueryOpts) {
-	artists, _, err := w.mediaItems.GetArtists(opts)
-	if err != nil {
-		logrus.Errorf("get albumList page: %v", err)
-		return
-	}
+	w.requests.load("get artists page", func(ctx context.Context) (func(), error) {
+		artists, total, err := w.mediaItems.GetArtists(ctx, opts)
+		return func() {
+			opts.Paging.SetTotalItems(total)
 
-	w.artistList.Clear()
-	w.artistList.SetArtists(artists)
-	w.artistList.EnablePaging(true)
-	w.setViewWidget(w.artistList, false)
+			w.artistList.Clear()
+			w.artistList.SetPage(opts.Paging)
+			w.artistList.SetArtists(artists)
+			w.artistList.EnablePaging(true)
+			w.setViewWidget(w.artistList, false)
+		}, err
+	})
 }
 
 func (w *Window) showAlbumPage(opts *interfaces.QueryOpts) {
-	albums, total, err := w.mediaItems.GetAlbums(opts)
-	if err != nil {
-		logrus.Errorf("get all albums: %v", err)
-		return
-	}
-	opts.Paging.SetTotalItems(total)
-	w.mediaNav.SetCount(MediaAlbums, total)
-	w.albumList.SetPage(opts.Paging)
-	w.albumList.SetAlbums(albums)
+	w.requests.load("get all albums", func(ctx context.Context) (func(), error) {
+		albums, total, err := w.mediaItems.GetAlbums(ctx, opts)
+		return func() {
+			opts.Paging.SetTotalItems(total)
+			w.mediaNav.SetCount(MediaAlbums, total)
+			w.albumList.SetPage(opts.Paging)
+			w.albumList.SetAlbums(albums)
+		}, err
+	})
 }
 
 func (w *Window) openFilterModal(m modal.Modal, doneFunc func()) {
@@ -768,7 +1906,7 @@ func (w *Window) openFilterModal(m modal.Modal, doneFunc func()) {
 	}
 
 	m.SetDoneFunc(closeFunc)
-	w.showModal(m, 16, 40, false)
+	w.showModal(m, 18, 40, false)
 }
 
 func (w *Window) playSong(song *models.Song) {
@@ -776,42 +1914,102 @@ func (w *Window) playSong(song *models.Song) {
 }
 
 func (w *Window) playSongs(songs []*models.Song) {
-	w.mediaQueue.AddSongs(songs)
+	if w.castTarget != nil {
+		go w.castSongs(w.castTarget, songs, false)
+		return
+	}
+	w.addToQueue(w.queueSource(songs), songs)
+	if len(songs) == 1 {
+		w.notifyInfo(i18n.Tf("Added '%s' to queue", songs[0].Name))
+	} else {
+		w.notifyInfo(i18n.Tf("Added %d songs to queue", len(songs)))
+	}
+}
+
+// addToQueue adds songs to queue as a group with given source, if queue supports groups.
+func (w *Window) addToQueue(source string, songs []*models.Song) {
+	if groups, ok := w.mediaQueue.(interfaces.QueueGroupController); ok {
+		groups.AddSongsFrom(source, songs)
+	} else {
+		w.mediaQueue.AddSongs(songs)
+	}
+}
+
+// queueSourceView is a view that names songs queued from it, e.g. album view with album name.
+type queueSourceView interface {
+	queueSource() string
+}
+
+// queueSource returns where songs are queued from: current view or album that all songs belong to.
+func (w *Window) queueSource(songs []*models.Song) string {
+	if view, ok := w.mediaView.(queueSourceView); ok {
+		if source := view.queueSource(); source != "" {
+			return source
+		}
+	}
+	return songsSource(songs)
+}
+
+// songsSource returns album name of songs, if all songs are from same album.
+func songsSource(songs []*models.Song) string {
+	if len(songs) == 0 || songs[0].AlbumName == "" {
+		return ""
+	}
+	for _, v := range songs[1:] {
+		if v.Album != songs[0].Album {
+			return ""
+		}
+	}
+	return i18n.Tf("Album: %s", songs[0].AlbumName)
 }
 
 func (w *Window) clearQueue() {
 	w.mediaQueue.ClearQueue(false)
+	w.notifyInfo(i18n.T("Queue cleared"))
 }
 
 func (w *Window) showSimilarArtists(artist models.Id) {
-	artists, err := w.mediaItems.GetSimilarArtists(artist)
+	w.requests.load("get similar artists", func(ctx context.Context) (func(), error) {
+		artists, err := w.mediaItems.GetSimilarArtists(ctx, artist)
+		return func() {
+			if len(artists) == 0 {
+				w.showMessage(i18n.T("No similar artists"), 3, -1, false)
+				return
+			}
+			w.artistList.Clear()
+			w.artistList.SetArtists(artists)
+			w.artistList.SetText(i18n.Tf("Similar artists: %d", len(artists)))
+			w.setViewWidget(w.artistList, true)
+		}, err
+	})
+}
+
+// getSimilarArtists returns similar artists and shows error, if there is one.
+func (w *Window) getSimilarArtists(artist models.Id) ([]*models.Artist, error) {
+	artists, err := w.mediaItems.GetSimilarArtists(context.Background(), artist)
 	if err != nil {
-		logrus.Errorf("get similar artists: %v", err)
-	} else if len(artists) > 0 {
-		w.artistList.Clear()
-		w.artistList.SetArtists(artists)
-		w.artistList.SetText(fmt.Sprintf("Similar artists: %d", len(artists)))
-		w.setViewWidget(w.artistList, true)
-	} else {
-		w.showMessage("No similar artists", 3, -1, false)
+		w.notifyError("get similar artists", err)
 	}
+	return artists, err
 }
 
 func (w *Window) showSimilarAlbums(album *models.Album) {
-	albums, err := w.mediaItems.GetSimilarAlbums(album.Id)
-	if err != nil {
-		logrus.Errorf("get similar artists: %v", err)
-	} else if len(albums) > 0 {
-		w.similarAlbums.Clear()
-		w.similarAlbums.EnableSimilar(false)
-		w.similarAlbums.EnablePaging(false)
-		w.similarAlbums.EnableFilter(false)
-		w.similarAlbums.SetAlbums(albums)
-		w.similarAlbums.SetText(fmt.Sprintf("Similar albums: %d", len(albums)))
-		w.setViewWidget(w.similarAlbums, true)
-	} else {
-		w.showMessage("No similar albums", 3, -1, false)
-	}
+	w.requests.load("get similar albums", func(ctx context.Context) (func(), error) {
+		albums, err := w.mediaItems.GetSimilarAlbums(ctx, album.Id)
+		return func() {
+			if len(albums) == 0 {
+				w.showMessage(i18n.T("No similar albums"), 3, -1, false)
+				return
+			}
+			w.similarAlbums.Clear()
+			w.similarAlbums.EnableSimilar(false)
+			w.similarAlbums.EnablePaging(false)
+			w.similarAlbums.EnableFilter(false)
+			w.similarAlbums.SetAlbums(albums)
+			w.similarAlbums.SetText(i18n.Tf("Similar albums: %d", len(albums)))
+			w.setViewWidget(w.similarAlbums, true)
+		}, err
+	})
 }
 
 func (w *Window) closeMessage() {
@@ -819,6 +2017,7 @@ func (w *Window) closeMessage() {
 }
 
 func (w *Window) showMessage(msg string, height, width int, lockSize bool) {
+	w.message.SetTitle(i18n.T("Info"))
 	w.message.SetText(msg)
 	if height == -1 {
 		height = 25
@@ -830,40 +2029,143 @@ func (w *Window) showMessage(msg string, height, width int, lockSize bool) {
 }
 
 func (w *Window) selectGenre(id models.IdName) {
+	w.setViewContent(w.genre, func() {
+		w.genre.SetGenre(id)
+	})
+	w.setViewWidget(w.genre, true)
+}
 
-	albums, err := w.mediaItems.GetGenreAlbums(id)
-	if err != nil {
-		logrus.Errorf("get genre albums: %v", err)
-		return
+// showGenreItems shows albums, artists or songs that belong to genre.
+func (w *Window) showGenreItems(genre models.IdName, itemType models.ItemType) {
+	switch itemType {
+	case models.TypeAlbum:
+		w.showGenreAlbums(genre)
+	case models.TypeArtist:
+		w.showGenreArtists(genre)
+	case models.TypeSong:
+		w.showGenreSongs(genre)
 	}
+}
+
+func (w *Window) playGenre(genre models.IdName) {
+	w.InstantMix(models.Genre(genre))
+}
+
+func (w *Window) showGenreArtists(genre models.IdName) {
+	w.artistList.SetFilter(interfaces.Filter{Genres: []models.IdName{genre}})
+	opts := w.artistList.queryOpts
+	w.requests.load("get genre artists", func(ctx context.Context) (func(), error) {
+		artists, total, err := w.mediaItems.GetArtists(ctx, opts)
+		return func() {
+			opts.Paging.SetTotalItems(total)
+
+			w.artistList.Clear()
+			w.artistList.EnablePaging(true)
+			w.artistList.SetPage(opts.Paging)
+			w.artistList.SetArtists(artists)
+			w.artistList.SetText(i18n.Tf("Genre %s: %d artists", genre.Name, total))
+			w.setViewWidget(w.artistList, true)
+		}, err
+	})
+}
 
-	w.albumList.Clear()
-	w.albumList.EnablePaging(false)
-	w.albumList.EnableSimilar(false)
-	w.albumList.EnableFilter(false)
-	w.albumList.EnableSorting(false)
-	w.albumList.SetAlbums(albums)
-	w.albumList.SetText("Genre " + id.Name)
-	w.setViewWidget(w.albumList, true)
+func (w *Window) showGenreSongs(genre models.IdName) {
+	w.songs.queryOpts.Filter = interfaces.Filter{Genres: []models.IdName{genre}}
+	opts := *w.songs.queryOpts
+	opts.Paging = interfaces.DefaultPaging()
+	w.requests.load("get genre songs", func(ctx context.Context) (func(), error) {
+		songs, total, err := w.mediaItems.GetSongs(ctx, &opts)
+		return func() {
+			opts.Paging.SetTotalItems(total)
+
+			w.songs.showPage = w.selectSongs
+			w.songs.setTitle(i18n.Tf("Genre %s", genre.Name))
+			w.songs.EnableSorting(true)
+			w.songs.SetSongs(songs, opts.Paging)
+			w.setViewWidget(w.songs, true)
+		}, err
+	})
+}
+
+func (w *Window) showGenreAlbums(id models.IdName) {
+	w.requests.load("get genre albums", func(ctx context.Context) (func(), error) {
+		albums, err := w.mediaItems.GetGenreAlbums(ctx, id)
+		return func() {
+			w.albumList.Clear()
+			w.albumList.EnablePaging(false)
+			w.albumList.EnableSimilar(false)
+			w.albumList.EnableFilter(false)
+			w.albumList.EnableSorting(false)
+			w.albumList.SetAlbums(albums)
+			w.albumList.SetText(i18n.Tf("Genre %s", id.Name))
+			w.setViewWidget(w.albumList, true)
+		}, err
+	})
 }
 
 func (w *Window) showGenrePage(paging interfaces.Paging) {
-	genres, n, err := w.mediaItems.GetGenres(paging)
-	if err != nil {
-		logrus.Errorf("get genres: %v", err)
-		return
-	}
-	paging.SetTotalItems(n)
-	w.genres.SetPage(paging)
-	w.genres.setGenres(genres)
-	w.genres.description.SetText(fmt.Sprintf("Genres: total %d", n))
-	w.setViewWidget(w.genres, true)
+	w.requests.load("get genres", func(ctx context.Context) (func(), error) {
+		genres, n, err := w.mediaItems.GetGenres(ctx, paging)
+		return func() {
+			paging.SetTotalItems(n)
+			w.genres.SetPage(paging)
+			w.genres.setGenres(genres)
+			w.genres.description.SetText(i18n.Tf("Genres: total %d", n))
+			w.setViewWidget(w.genres, true)
+		}, err
+	})
+}
+
+func (w *Window) showComposerPage(paging interfaces.Paging) {
+	w.requests.load("get composers", func(ctx context.Context) (func(), error) {
+		composers, n, err := w.mediaItems.GetComposers(ctx, paging)
+		return func() {
+			paging.SetTotalItems(n)
+			w.mediaNav.SetCount(MediaComposers, n)
+			w.composers.SetPage(paging)
+			w.composers.setGenres(composers)
+			w.composers.description.SetText(i18n.Tf("Composers: total %d", n))
+			w.setViewWidget(w.composers, true)
+		}, err
+	})
+}
+
+// showComposerAlbums shows albums of composer. Composer is set as album list filter,
+// so that it can be combined with other filters and sorting.
+func (w *Window) showComposerAlbums(composer models.IdName) {
+	opts := interfaces.DefaultQueryOpts()
+	opts.Sort = w.albumList.queryOpts.Sort
+	opts.Filter.Composers = []models.IdName{composer}
+	w.requests.load("get composer albums", func(ctx context.Context) (func(), error) {
+		albums, total, err := w.mediaItems.GetAlbums(ctx, opts)
+		return func() {
+			opts.Paging.SetTotalItems(total)
+			w.albumList.Clear()
+			w.albumList.SetFilter(opts.Filter)
+			w.albumList.EnablePaging(true)
+			w.albumList.EnableSimilar(false)
+			w.albumList.EnableFilter(true)
+			w.albumList.EnableSorting(true)
+			w.albumList.SetPage(opts.Paging)
+			w.albumList.SetText(i18n.Tf("Composer %s\nTotal %d", composer.Name, total))
+			w.albumList.SetAlbums(albums)
+			w.setViewWidget(w.albumList, true)
+		}, err
+	})
+}
+
+// maxFilterComposers is the maximum number of composers to show in album filter.
+const maxFilterComposers = 500
+
+func (w *Window) getFilterComposers() ([]*models.IdName, error) {
+	composers, _, err := w.mediaItems.GetComposers(context.Background(), interfaces.Paging{PageSize: maxFilterComposers})
+	return composers, err
 }
 
 func (w *Window) debugDump() {
 	logrus.Info("Dump goroutines")
 	err := util.DumpGoroutines()
 	if err != nil {
-		logrus.Errorf("Write debug dump: %v", err)
+		w.notifyError("write debug dump", err)
 	}
 }
@@ -0,0 +1,46 @@
+/*
+ * Jellycli is a terminal music player for Jellyfin.
+ * Copyright (C) 2020 Tero Vierimaa
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package util
+
+import (
+	"encoding/base64"
+	"os"
+)
+
+// Osc52 returns terminal escape sequence that sets system clipboard to text. OSC 52 is supported by most
+// terminal emulators and works over ssh too. Inside tmux, sequence is wrapped so that tmux passes it
+// to the outer terminal.
+func Osc52(text string, tmux bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	if tmux {
+		return "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	return seq
+}
+
+// CopyToClipboard copies text to clipboard of the terminal that jellycli runs in.
+func CopyToClipboard(text string) error {
+	_, err := os.Stdout.WriteString(Osc52(text, os.Getenv("TMUX") != ""))
+	return err
+}
+
+// IsRemoteSession returns true when running over ssh, where browser would not open on user's machine.
+func IsRemoteSession() bool {
+	return os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != ""
+}
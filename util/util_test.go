@@ -94,3 +94,44 @@ func TestSecToStringLong(t *testing.T) {
 		})
 	}
 }
+
+func TestBytesToString(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes int64
+		want  string
+	}{
+		{
+			bytes: 500,
+			want:  "500 B",
+		},
+		{
+			bytes: 1536,
+			want:  "1.5 KB",
+		},
+		{
+			bytes: 320 * 1024 * 1024,
+			want:  "320.0 MB",
+		},
+		{
+			bytes: 1288490189,
+			want:  "1.2 GB",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BytesToString(tt.bytes); got != tt.want {
+				t.Errorf("BytesToString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOsc52(t *testing.T) {
+	if got, want := Osc52("hello", false), "\x1b]52;c;aGVsbG8=\x07"; got != want {
+		t.Errorf("Osc52() = %q, want %q", got, want)
+	}
+	if got, want := Osc52("hello", true), "\x1bPtmux;\x1b\x1b]52;c;aGVsbG8=\x07\x1b\\"; got != want {
+		t.Errorf("Osc52() in tmux = %q, want %q", got, want)
+	}
+}